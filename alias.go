@@ -0,0 +1,245 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// AliasDirection 别名的读取回退方向
+type AliasDirection int
+
+const (
+	// AliasToNew 读取旧键未命中时回退到新键，用于旧版本的代码读取新版本写入的值
+	AliasToNew AliasDirection = 1 << iota
+	// AliasToOld 读取新键未命中时回退到旧键，用于新版本的代码读取迁移前写入的值
+	AliasToOld
+	// AliasBoth 双向回退，用于新旧版本同时运行的滚动发布
+	AliasBoth = AliasToNew | AliasToOld
+)
+
+// KeyAlias 键前缀的别名，键名以 Old 开头的键与将 Old 替换为 New 得到的键互为别名
+type KeyAlias struct {
+	Old       string
+	New       string
+	Direction AliasDirection // 默认 AliasBoth
+	Until     time.Time      // 别名的失效时间，零值表示一直有效
+}
+
+// AliasUsage 一个别名的使用统计
+type AliasUsage struct {
+	Old       string
+	New       string
+	Fallbacks uint64    // 由别名提供的读取次数
+	LastUsed  time.Time // 最近一次由别名提供读取的时间，零值表示从未使用
+}
+
+// AliasHook 读取由别名提供时调用，key 为调用方读取的键，alias 为实际命中的键
+type AliasHook func(key, alias string)
+
+// AliasedCacheOption 别名缓存选项
+type AliasedCacheOption func(*AliasedCache)
+
+// WithAliasHook 设置读取由别名提供时的回调，可用于记录仍在使用旧键名的调用方
+func WithAliasHook(hook AliasHook) AliasedCacheOption {
+	return func(a *AliasedCache) {
+		a.onAlias = hook
+	}
+}
+
+// WithAliasClock 设置判断别名是否失效所用的时钟，默认使用系统时间
+func WithAliasClock(clock Clock) AliasedCacheOption {
+	return func(a *AliasedCache) {
+		a.clock = clock
+	}
+}
+
+// aliasEntry 别名与其使用统计
+type aliasEntry struct {
+	alias KeyAlias
+	usage AliasUsage
+}
+
+// AliasedCache 支持键别名的缓存，用于不停机地迁移键的命名规则
+// 读取（Get、GetSet 的读取、Exists）未命中时按别名的方向尝试另一个键名；
+// 写入与设置过期时间只作用于调用方给出的键，Set、GetSet 的写入与 Del 同时删除有效的别名，
+// 避免另一个键名的读取回退到旧值或已删除的值。
+// 迁移完成（AliasUsage 中不再出现回退）后移除别名或等待其失效即可
+type AliasedCache struct {
+	cache   gsr.Cacher
+	clock   Clock
+	onAlias AliasHook
+
+	mu      sync.Mutex
+	aliases []*aliasEntry
+}
+
+// NewAliased 创建支持键别名的缓存
+func NewAliased(cache gsr.Cacher, aliases []KeyAlias, opts ...AliasedCacheOption) *AliasedCache {
+	a := &AliasedCache{
+		cache: cache,
+		clock: realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	for _, alias := range aliases {
+		a.AddAlias(alias)
+	}
+	return a
+}
+
+// AddAlias 添加别名
+func (a *AliasedCache) AddAlias(alias KeyAlias) {
+	if alias.Direction == 0 {
+		alias.Direction = AliasBoth
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.aliases = append(a.aliases, &aliasEntry{
+		alias: alias,
+		usage: AliasUsage{Old: alias.Old, New: alias.New},
+	})
+}
+
+// RemoveAlias 移除旧前缀为old的别名
+func (a *AliasedCache) RemoveAlias(old string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	kept := a.aliases[:0]
+	for _, entry := range a.aliases {
+		if entry.alias.Old != old {
+			kept = append(kept, entry)
+		}
+	}
+	a.aliases = kept
+}
+
+// Usage 返回各别名的使用统计，包括已失效的别名
+func (a *AliasedCache) Usage() []AliasUsage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	usage := make([]AliasUsage, len(a.aliases))
+	for i, entry := range a.aliases {
+		usage[i] = entry.usage
+	}
+	return usage
+}
+
+// aliasTarget 键的一个别名与其所属的别名配置
+type aliasTarget struct {
+	key   string
+	entry *aliasEntry
+}
+
+// alternates 返回键的有效别名，read 为true时只返回允许读取回退的方向
+func (a *AliasedCache) alternates(key string, read bool) []aliasTarget {
+	now := a.clock.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	var targets []aliasTarget
+	for _, entry := range a.aliases {
+		alias := entry.alias
+		if !alias.Until.IsZero() && !now.Before(alias.Until) {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(key, alias.Old); ok && (!read || alias.Direction&AliasToNew != 0) {
+			targets = append(targets, aliasTarget{key: alias.New + rest, entry: entry})
+		}
+		if rest, ok := strings.CutPrefix(key, alias.New); ok && (!read || alias.Direction&AliasToOld != 0) {
+			targets = append(targets, aliasTarget{key: alias.Old + rest, entry: entry})
+		}
+	}
+	return targets
+}
+
+// record 记录一次由别名提供的读取
+func (a *AliasedCache) record(key string, target aliasTarget) {
+	now := a.clock.Now()
+	a.mu.Lock()
+	target.entry.usage.Fallbacks++
+	target.entry.usage.LastUsed = now
+	a.mu.Unlock()
+
+	if a.onAlias != nil {
+		a.onAlias(key, target.key)
+	}
+}
+
+func (a *AliasedCache) Exists(ctx context.Context, key string) bool {
+	if a.cache.Exists(ctx, key) {
+		return true
+	}
+	for _, target := range a.alternates(key, true) {
+		if a.cache.Exists(ctx, target.key) {
+			a.record(key, target)
+			return true
+		}
+	}
+	return false
+}
+
+func (a *AliasedCache) Get(ctx context.Context, key string, obj any) error {
+	err := a.cache.Get(ctx, key, obj)
+	if err == nil || !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	for _, target := range a.alternates(key, true) {
+		if a.cache.Get(ctx, target.key, obj) == nil {
+			a.record(key, target)
+			return nil
+		}
+	}
+	return err
+}
+
+func (a *AliasedCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := a.cache.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return a.delAliases(ctx, key)
+}
+
+func (a *AliasedCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	// 先尝试从缓存与别名获取，WithForceRefresh 时直接交给底层缓存
+	if !forceRefresh(ctx) && a.Get(ctx, key, obj) == nil {
+		// 缓存命中，直接返回
+		return nil
+	}
+	if err := a.cache.GetSet(ctx, key, ttl, obj, fun); err != nil {
+		return err
+	}
+	return a.delAliases(ctx, key)
+}
+
+func (a *AliasedCache) Del(ctx context.Context, key string) error {
+	if err := a.cache.Del(ctx, key); err != nil {
+		return err
+	}
+	return a.delAliases(ctx, key)
+}
+
+// delAliases 删除键的有效别名，键被写入或删除后别名中的值已过时
+func (a *AliasedCache) delAliases(ctx context.Context, key string) error {
+	for _, target := range a.alternates(key, false) {
+		if err := a.cache.Del(ctx, target.key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *AliasedCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return a.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (a *AliasedCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return a.cache.ExpiresIn(ctx, key, ttl)
+}
@@ -0,0 +1,382 @@
+package go_cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ArtifactSpec 派生产物的来源与转换参数，如 {Source: "img/1.png", Params: {"w": "200", "fmt": "webp"}}
+type ArtifactSpec struct {
+	Source string
+	Params map[string]string
+}
+
+// ID 返回来源与参数的摘要，参数的顺序不影响结果
+func (s ArtifactSpec) ID() string {
+	names := make([]string, 0, len(s.Params))
+	for name := range s.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	h := sha256.New()
+	// 写入长度，避免不同的来源与参数拼接后相同
+	fmt.Fprintf(h, "%d:%s", len(s.Source), s.Source)
+	for _, name := range names {
+		value := s.Params[name]
+		fmt.Fprintf(h, "%d:%s%d:%s", len(name), name, len(value), value)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// ArtifactInfo 产物的元数据
+type ArtifactInfo struct {
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+	Offloaded   bool // 内容保存在对象存储中
+}
+
+// ArtifactFunc 生成产物，将内容写入w并返回内容类型
+type ArtifactFunc func(ctx context.Context, w io.Writer) (contentType string, err error)
+
+// artifactEntry 缓存中保存的产物，Blob 不为空时内容保存在对象存储中，否则为 Data
+type artifactEntry struct {
+	ContentType string
+	Size        int64
+	CreatedAt   time.Time
+	Blob        string
+	Data        []byte
+}
+
+func (e *artifactEntry) info() ArtifactInfo {
+	return ArtifactInfo{ContentType: e.ContentType, Size: e.Size, CreatedAt: e.CreatedAt, Offloaded: e.Blob != ""}
+}
+
+// ArtifactCacheOption 产物缓存选项
+type ArtifactCacheOption func(*ArtifactCache)
+
+// WithArtifactInlineLimit 设置直接保存在缓存中的最大内容大小（字节），默认64KiB，更大的内容保存到对象存储
+func WithArtifactInlineLimit(n int) ArtifactCacheOption {
+	return func(a *ArtifactCache) {
+		if n >= 0 {
+			a.inlineLimit = n
+		}
+	}
+}
+
+// WithArtifactPrefix 设置元数据键的前缀，默认 "artifact:"
+func WithArtifactPrefix(prefix string) ArtifactCacheOption {
+	return func(a *ArtifactCache) {
+		a.prefix = prefix
+	}
+}
+
+// WithArtifactClock 设置记录生成时间所用的时钟，默认使用系统时间
+func WithArtifactClock(clock Clock) ArtifactCacheOption {
+	return func(a *ArtifactCache) {
+		if clock != nil {
+			a.clock = clock
+		}
+	}
+}
+
+// ArtifactCache 缩略图、PDF等派生二进制产物的缓存
+// 产物按来源与转换参数（ArtifactSpec）定位，元数据保存在缓存中；内容不超过内联上限时与元数据一起保存，
+// 否则写入对象存储，缓存中只保存对象名称。写入与读取都以流的方式进行，生成超过上限的内容时先写入临时文件。
+// 元数据过期后对象不会立即删除，需要定期调用 Sweep 清理
+type ArtifactCache struct {
+	cache       gsr.Cacher
+	blobs       BlobStore
+	inlineLimit int
+	prefix      string
+	clock       Clock
+}
+
+// NewArtifactCache 创建产物缓存，blobs 为nil时所有内容都保存在缓存中
+func NewArtifactCache(cache gsr.Cacher, blobs BlobStore, opts ...ArtifactCacheOption) *ArtifactCache {
+	a := &ArtifactCache{
+		cache:       cache,
+		blobs:       blobs,
+		inlineLimit: 64 << 10,
+		prefix:      "artifact:",
+		clock:       realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
+}
+
+// key 返回产物元数据的键
+func (a *ArtifactCache) key(id string) string {
+	return a.prefix + id
+}
+
+// load 读取产物的元数据
+func (a *ArtifactCache) load(ctx context.Context, id string) (*artifactEntry, error) {
+	var entry artifactEntry
+	if err := a.cache.Get(ctx, a.key(id), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// Stat 返回产物的元数据，产物不存在时返回 ErrKeyNotFound
+func (a *ArtifactCache) Stat(ctx context.Context, spec ArtifactSpec) (ArtifactInfo, error) {
+	entry, err := a.load(ctx, spec.ID())
+	if err != nil {
+		return ArtifactInfo{}, err
+	}
+	return entry.info(), nil
+}
+
+// Open 打开产物的内容，调用方负责关闭返回的 io.ReadCloser；产物不存在时返回 ErrKeyNotFound
+// 对象存储中的内容已被删除时删除元数据并返回 ErrKeyNotFound
+func (a *ArtifactCache) Open(ctx context.Context, spec ArtifactSpec) (io.ReadCloser, ArtifactInfo, error) {
+	id := spec.ID()
+	entry, err := a.load(ctx, id)
+	if err != nil {
+		return nil, ArtifactInfo{}, err
+	}
+	if entry.Blob == "" {
+		return io.NopCloser(bytes.NewReader(entry.Data)), entry.info(), nil
+	}
+	if a.blobs == nil {
+		return nil, ArtifactInfo{}, fmt.Errorf("artifact %s: %w: no blob store", id, ErrKeyNotFound)
+	}
+	r, err := a.blobs.GetBlob(ctx, entry.Blob)
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			_ = a.cache.Del(ctx, a.key(id))
+		}
+		return nil, ArtifactInfo{}, err
+	}
+	return r, entry.info(), nil
+}
+
+// Put 保存从r读取的产物，size 为内容大小，未知时传入-1
+func (a *ArtifactCache) Put(ctx context.Context, spec ArtifactSpec, contentType string, r io.Reader, size int64, ttl time.Duration) (ArtifactInfo, error) {
+	if size >= 0 || a.blobs == nil {
+		return a.store(ctx, spec.ID(), contentType, r, size, ttl)
+	}
+
+	// 大小未知时先写入缓冲区，超过内联上限的内容需要已知大小才能写入对象存储
+	spool := newArtifactSpool(a.inlineLimit)
+	defer spool.Close()
+	if _, err := io.Copy(spool, r); err != nil {
+		return ArtifactInfo{}, err
+	}
+	content, err := spool.Reader()
+	if err != nil {
+		return ArtifactInfo{}, err
+	}
+	return a.store(ctx, spec.ID(), contentType, content, spool.size, ttl)
+}
+
+// GetOrCreate 打开产物的内容，产物不存在时调用fn生成并保存
+// fn 的输出超过内联上限时写入临时文件，保存后从对象存储读取
+func (a *ArtifactCache) GetOrCreate(ctx context.Context, spec ArtifactSpec, ttl time.Duration, fn ArtifactFunc) (io.ReadCloser, ArtifactInfo, error) {
+	if !forceRefresh(ctx) {
+		r, info, err := a.Open(ctx, spec)
+		if err == nil {
+			return r, info, nil
+		}
+		if !errors.Is(err, ErrKeyNotFound) {
+			return nil, ArtifactInfo{}, err
+		}
+	}
+
+	spool := newArtifactSpool(a.inlineLimit)
+	defer spool.Close()
+	contentType, err := fn(ctx, spool)
+	if err != nil {
+		return nil, ArtifactInfo{}, err
+	}
+	if spool.file == nil {
+		// 内容在内存中，直接返回，不再读取对象存储
+		data := spool.buf.Bytes()
+		info, err := a.store(ctx, spec.ID(), contentType, bytes.NewReader(data), int64(len(data)), ttl)
+		if err != nil {
+			return nil, ArtifactInfo{}, err
+		}
+		return io.NopCloser(bytes.NewReader(data)), info, nil
+	}
+	content, err := spool.Reader()
+	if err != nil {
+		return nil, ArtifactInfo{}, err
+	}
+	if _, err := a.store(ctx, spec.ID(), contentType, content, spool.size, ttl); err != nil {
+		return nil, ArtifactInfo{}, err
+	}
+	return a.Open(ctx, spec)
+}
+
+// store 保存内容与元数据，超过内联上限的内容写入对象存储，并删除被替换的对象
+func (a *ArtifactCache) store(ctx context.Context, id, contentType string, r io.Reader, size int64, ttl time.Duration) (ArtifactInfo, error) {
+	entry := &artifactEntry{ContentType: contentType, Size: size, CreatedAt: a.clock.Now()}
+	if a.blobs != nil && size > int64(a.inlineLimit) {
+		name, err := artifactBlobName(id)
+		if err != nil {
+			return ArtifactInfo{}, err
+		}
+		if err := a.blobs.PutBlob(ctx, name, r, size); err != nil {
+			return ArtifactInfo{}, err
+		}
+		entry.Blob = name
+	} else {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return ArtifactInfo{}, err
+		}
+		entry.Data, entry.Size = data, int64(len(data))
+	}
+
+	old, _ := a.load(ctx, id)
+	if err := a.cache.Set(ctx, a.key(id), *entry, ttl); err != nil {
+		if entry.Blob != "" {
+			_ = a.blobs.DeleteBlob(ctx, entry.Blob)
+		}
+		return ArtifactInfo{}, err
+	}
+	if old != nil && old.Blob != "" {
+		_ = a.blobs.DeleteBlob(ctx, old.Blob)
+	}
+	return entry.info(), nil
+}
+
+// Delete 删除产物的元数据与内容
+func (a *ArtifactCache) Delete(ctx context.Context, spec ArtifactSpec) error {
+	id := spec.ID()
+	entry, err := a.load(ctx, id)
+	if err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	if err := a.cache.Del(ctx, a.key(id)); err != nil {
+		return err
+	}
+	if entry != nil && entry.Blob != "" {
+		return a.blobs.DeleteBlob(ctx, entry.Blob)
+	}
+	return nil
+}
+
+// Sweep 删除元数据已过期或已被替换的对象，返回删除的数量
+// 只检查修改时间早于minAge的对象，避免删除正在保存的产物；对象存储需实现 BlobLister，否则返回 ErrNotSupported。
+// 对象存储应只用于该产物缓存，名称不符合产物格式的对象会被跳过
+func (a *ArtifactCache) Sweep(ctx context.Context, minAge time.Duration) (int, error) {
+	lister, ok := a.blobs.(BlobLister)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	cutoff := time.Now().Add(-minAge)
+
+	var candidates []string
+	err := lister.ListBlobs(ctx, "", func(info BlobInfo) error {
+		if info.ModTime.Before(cutoff) {
+			candidates = append(candidates, info.Name)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for _, name := range candidates {
+		id, _, ok := strings.Cut(name, ".")
+		if !ok || len(id) != sha256.Size*2 || strings.Trim(id, "0123456789abcdef") != "" {
+			continue
+		}
+		entry, err := a.load(ctx, id)
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return deleted, err
+		}
+		if entry != nil && entry.Blob == name {
+			continue
+		}
+		if err := a.blobs.DeleteBlob(ctx, name); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// artifactBlobName 生成产物内容的对象名称：产物标识加上随机后缀，同一产物的每次保存使用不同的对象
+func artifactBlobName(id string) (string, error) {
+	nonce, err := newLeaseToken()
+	if err != nil {
+		return "", err
+	}
+	return id + "." + nonce[:16], nil
+}
+
+// artifactSpool 先写入内存、超过上限后转为写入临时文件的缓冲区
+type artifactSpool struct {
+	limit int
+	buf   bytes.Buffer
+	file  *os.File
+	size  int64
+}
+
+func newArtifactSpool(limit int) *artifactSpool {
+	return &artifactSpool{limit: limit}
+}
+
+func (s *artifactSpool) Write(p []byte) (int, error) {
+	if s.file == nil && s.buf.Len()+len(p) > s.limit {
+		file, err := os.CreateTemp("", "go-cache-artifact-*")
+		if err != nil {
+			return 0, err
+		}
+		s.file = file
+		if _, err := file.Write(s.buf.Bytes()); err != nil {
+			return 0, err
+		}
+		s.buf.Reset()
+	}
+	var n int
+	var err error
+	if s.file != nil {
+		n, err = s.file.Write(p)
+	} else {
+		n, err = s.buf.Write(p)
+	}
+	s.size += int64(n)
+	return n, err
+}
+
+// Reader 返回已写入内容的读取器
+func (s *artifactSpool) Reader() (io.Reader, error) {
+	if s.file == nil {
+		return bytes.NewReader(s.buf.Bytes()), nil
+	}
+	if _, err := s.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return s.file, nil
+}
+
+// Close 删除临时文件
+func (s *artifactSpool) Close() error {
+	if s.file == nil {
+		return nil
+	}
+	s.file.Close()
+	return os.Remove(s.file.Name())
+}
@@ -0,0 +1,119 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ErrAccessDenied 操作被授权钩子拒绝
+// 授权钩子可以返回此错误或任意自定义错误
+var ErrAccessDenied = errors.New("cache access denied")
+
+// Authorizer 授权钩子，在每个操作执行前调用，返回非nil错误时拒绝操作
+// 对于按模式的操作，key为模式字符串
+type Authorizer func(ctx context.Context, op Operation, key string) error
+
+// AuthorizedCache 在每个操作前调用授权钩子的缓存
+// 用于集中实现访问控制，例如请求作用域的context只能读取属于当前认证用户的键
+type AuthorizedCache struct {
+	cache     gsr.Cacher
+	authorize Authorizer
+}
+
+// NewAuthorized 创建带授权钩子的缓存
+func NewAuthorized(cache gsr.Cacher, authorize Authorizer) *AuthorizedCache {
+	return &AuthorizedCache{cache: cache, authorize: authorize}
+}
+
+// Exists 授权被拒绝时返回false
+func (a *AuthorizedCache) Exists(ctx context.Context, key string) bool {
+	if a.authorize(ctx, OpExists, key) != nil {
+		return false
+	}
+	return a.cache.Exists(ctx, key)
+}
+
+func (a *AuthorizedCache) Get(ctx context.Context, key string, obj any) error {
+	if err := a.authorize(ctx, OpGet, key); err != nil {
+		return err
+	}
+	return a.cache.Get(ctx, key, obj)
+}
+
+func (a *AuthorizedCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := a.authorize(ctx, OpSet, key); err != nil {
+		return err
+	}
+	return a.cache.Set(ctx, key, value, ttl)
+}
+
+// SetWithPriority 按优先级写入，底层缓存不支持优先级时退化为 Set
+func (a *AuthorizedCache) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	if err := a.authorize(ctx, OpSet, key); err != nil {
+		return err
+	}
+	if ps, ok := a.cache.(PrioritySetter); ok {
+		return ps.SetWithPriority(ctx, key, value, ttl, priority)
+	}
+	return a.cache.Set(ctx, key, value, ttl)
+}
+
+func (a *AuthorizedCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	if err := a.authorize(ctx, OpGetSet, key); err != nil {
+		return err
+	}
+	return a.cache.GetSet(ctx, key, ttl, obj, fun)
+}
+
+func (a *AuthorizedCache) Del(ctx context.Context, key string) error {
+	if err := a.authorize(ctx, OpDel, key); err != nil {
+		return err
+	}
+	return a.cache.Del(ctx, key)
+}
+
+func (a *AuthorizedCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	if err := a.authorize(ctx, OpExpire, key); err != nil {
+		return err
+	}
+	return a.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (a *AuthorizedCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	if err := a.authorize(ctx, OpExpire, key); err != nil {
+		return err
+	}
+	return a.cache.ExpiresIn(ctx, key, ttl)
+}
+
+// Keys 按模式列出键，底层缓存需实现 PatternCache
+func (a *AuthorizedCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	if err := a.authorize(ctx, OpKeys, pattern); err != nil {
+		return nil, err
+	}
+	pc, ok := a.cache.(PatternCache)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	return pc.Keys(ctx, pattern)
+}
+
+// DelByPattern 按模式删除键，底层缓存需实现 PatternCache
+func (a *AuthorizedCache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	if err := a.authorize(ctx, OpDelByPattern, pattern); err != nil {
+		return 0, err
+	}
+	pc, ok := a.cache.(PatternCache)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	return pc.DelByPattern(ctx, pattern)
+}
+
+// Capabilities 返回支持的能力，底层缓存实现 PatternCache 时才支持按模式操作
+func (a *AuthorizedCache) Capabilities() []Capability {
+	return wrapperCapabilities(a.cache, []Capability{CapabilityPriority}, CapabilityPattern)
+}
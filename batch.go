@@ -0,0 +1,86 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// BatchCache 支持批量检查与删除键的缓存，一次往返处理多个键
+type BatchCache interface {
+	// ExistsMulti 返回每个键是否存在
+	ExistsMulti(ctx context.Context, keys []string) (map[string]bool, error)
+	// DelMulti 删除多个键，不存在的键会被忽略
+	DelMulti(ctx context.Context, keys ...string) error
+}
+
+// MultiGetCache 支持一次往返读取多个键的缓存
+type MultiGetCache interface {
+	// MGet 读取多个键，objs 为键到接收值的指针
+	// 成功的键的值写入对应的指针，失败的键记录在返回的映射中（未命中为 ErrKeyNotFound，
+	// 解码失败为 ErrSerialization），一个键失败不影响其他键；
+	// 只有整个请求失败（如连接错误或 WithNoCache）时才返回error
+	MGet(ctx context.Context, objs map[string]any) (map[string]error, error)
+}
+
+// MGet 读取多个键，语义见 MultiGetCache
+// 缓存实现 MultiGetCache 时一次往返读取，否则逐个调用 Get
+func MGet(ctx context.Context, cache gsr.Cacher, objs map[string]any) (map[string]error, error) {
+	if c, ok := cache.(MultiGetCache); ok {
+		return c.MGet(ctx, objs)
+	}
+	if noCache(ctx) {
+		return nil, ErrCacheBypassed
+	}
+
+	errs := make(map[string]error)
+	for key, obj := range objs {
+		if err := cache.Get(ctx, key, obj); err != nil {
+			errs[key] = err
+		}
+	}
+	return errs, nil
+}
+
+// GetSetMulti 批量版本的 GetSet，先用 MGet 读取全部键，
+// 未命中或读取失败（包括解码失败）的键逐个调用回调函数加载并以ttl写入缓存。
+// 回调函数或写入失败的键记录在返回的映射中，其他键的值照常写入对应的指针；
+// 返回的映射为空时全部键都已成功
+func GetSetMulti(ctx context.Context, cache gsr.Cacher, ttl time.Duration, objs map[string]any, fun gsr.CacheCallback) map[string]error {
+	// 先尝试从缓存获取，WithForceRefresh 时直接调用回调函数
+	missing := make([]string, 0, len(objs))
+	if forceRefresh(ctx) {
+		for key := range objs {
+			missing = append(missing, key)
+		}
+	} else {
+		// 整个请求失败时与 GetSet 一样调用回调函数加载全部键
+		errs, err := MGet(ctx, cache, objs)
+		for key := range objs {
+			if _, failed := errs[key]; failed || err != nil {
+				missing = append(missing, key)
+			}
+		}
+	}
+
+	// 缓存未命中，调用回调函数
+	errs := make(map[string]error)
+	for _, key := range missing {
+		obj := objs[key]
+		if err := loaderOf(cache).call(ctx, key, obj, fun); err != nil {
+			errs[key] = err
+			continue
+		}
+
+		// 获取obj指向的实际值并存入缓存
+		value, err := pointee(obj)
+		if err == nil {
+			err = cache.Set(ctx, key, value, ttl)
+		}
+		if err != nil {
+			errs[key] = err
+		}
+	}
+	return errs
+}
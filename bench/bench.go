@@ -0,0 +1,184 @@
+// Package bench 提供对比不同缓存后端与序列化器的基准测试工具
+// 使用标准化的工作负载（读多写少、写多读少、读写混合、大值）运行并输出对比表格
+package bench
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// Workload 标准化工作负载
+type Workload struct {
+	Name      string
+	ReadRatio float64 // 读操作占比，0~1
+	ValueSize int     // 值大小（字节）
+	Keys      int     // 键空间大小
+}
+
+// DefaultWorkloads 默认工作负载
+var DefaultWorkloads = []Workload{
+	{Name: "read-heavy", ReadRatio: 0.9, ValueSize: 256, Keys: 1000},
+	{Name: "write-heavy", ReadRatio: 0.1, ValueSize: 256, Keys: 1000},
+	{Name: "mixed", ReadRatio: 0.5, ValueSize: 256, Keys: 1000},
+	{Name: "large-values", ReadRatio: 0.5, ValueSize: 256 * 1024, Keys: 100},
+}
+
+// Target 被测目标（后端与序列化器的组合）
+type Target struct {
+	Name string
+	// New 为每个工作负载创建一个新的缓存实例，cleanup可为nil
+	New func() (cache gsr.Cacher, cleanup func(), err error)
+}
+
+// Options 运行选项
+type Options struct {
+	Ops         int           // 每个工作负载执行的操作数，默认10000
+	Concurrency int           // 并发数，默认4
+	TTL         time.Duration // 写入的TTL，默认1分钟
+}
+
+// Result 单个目标在单个工作负载下的结果
+type Result struct {
+	Target    string
+	Workload  string
+	Ops       int
+	Errors    int
+	Duration  time.Duration
+	OpsPerSec float64
+	P50       time.Duration
+	P99       time.Duration
+}
+
+// Run 对每个目标运行每个工作负载
+// 某个目标创建失败时返回错误，单次操作的错误计入 Result.Errors
+func Run(ctx context.Context, targets []Target, workloads []Workload, opts Options) ([]Result, error) {
+	if opts.Ops <= 0 {
+		opts.Ops = 10000
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.TTL <= 0 {
+		opts.TTL = time.Minute
+	}
+
+	var results []Result
+	for _, target := range targets {
+		for _, workload := range workloads {
+			result, err := runOne(ctx, target, workload, opts)
+			if err != nil {
+				return results, fmt.Errorf("%s/%s: %w", target.Name, workload.Name, err)
+			}
+			results = append(results, result)
+		}
+	}
+	return results, nil
+}
+
+// runOne 运行单个目标的单个工作负载
+func runOne(ctx context.Context, target Target, workload Workload, opts Options) (Result, error) {
+	cache, cleanup, err := target.New()
+	if err != nil {
+		return Result{}, err
+	}
+	if cleanup != nil {
+		defer cleanup()
+	}
+
+	keys := max(workload.Keys, 1)
+	value := strings.Repeat("x", workload.ValueSize)
+
+	// 预热：写入全部键，使读操作有机会命中
+	for i := 0; i < keys; i++ {
+		if err := cache.Set(ctx, benchKey(i), value, opts.TTL); err != nil {
+			return Result{}, fmt.Errorf("warmup: %w", err)
+		}
+	}
+
+	latencies := make([]time.Duration, opts.Ops)
+	var errCount int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	perWorker := opts.Ops / opts.Concurrency
+	start := time.Now()
+	for w := 0; w < opts.Concurrency; w++ {
+		from := w * perWorker
+		to := from + perWorker
+		if w == opts.Concurrency-1 {
+			to = opts.Ops
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rng := rand.New(rand.NewPCG(uint64(from), uint64(to)))
+			errs := 0
+			for i := from; i < to; i++ {
+				key := benchKey(rng.IntN(keys))
+				opStart := time.Now()
+				var err error
+				if rng.Float64() < workload.ReadRatio {
+					var out string
+					err = cache.Get(ctx, key, &out)
+				} else {
+					err = cache.Set(ctx, key, value, opts.TTL)
+				}
+				latencies[i] = time.Since(opStart)
+				if err != nil {
+					errs++
+				}
+			}
+			mu.Lock()
+			errCount += errs
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return Result{
+		Target:    target.Name,
+		Workload:  workload.Name,
+		Ops:       opts.Ops,
+		Errors:    errCount,
+		Duration:  elapsed,
+		OpsPerSec: float64(opts.Ops) / elapsed.Seconds(),
+		P50:       percentile(latencies, 0.50),
+		P99:       percentile(latencies, 0.99),
+	}, nil
+}
+
+// WriteTable 以表格形式输出结果
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "target\tworkload\tops\terrors\tops/sec\tp50\tp99\t")
+	for _, r := range results {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%.0f\t%s\t%s\t\n",
+			r.Target, r.Workload, r.Ops, r.Errors, r.OpsPerSec, r.P50, r.P99)
+	}
+	return tw.Flush()
+}
+
+// percentile 返回已排序延迟的分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
+
+func benchKey(i int) string {
+	return fmt.Sprintf("bench:%d", i)
+}
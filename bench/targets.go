@@ -0,0 +1,65 @@
+package bench
+
+import (
+	"context"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+	"github.com/redis/go-redis/v9"
+)
+
+// MemoryTarget 内存缓存目标
+// 内存缓存不经过序列化器，因此只有一个目标
+func MemoryTarget() Target {
+	return Target{
+		Name: "memory",
+		New: func() (gsr.Cacher, func(), error) {
+			return go_cache.NewMemory(time.Minute, time.Minute), nil, nil
+		},
+	}
+}
+
+// RedisTargets 返回每个序列化器对应的Redis缓存目标
+// 每次运行前清空opts指定的数据库，请勿指向生产数据库
+func RedisTargets(opts *redis.Options) []Target {
+	serializers := []serializer.Serializer{serializer.NewGob(), serializer.NewJson()}
+
+	targets := make([]Target, 0, len(serializers))
+	for _, s := range serializers {
+		targets = append(targets, Target{
+			Name: "redis/" + s.Name(),
+			New: func() (gsr.Cacher, func(), error) {
+				client := redis.NewClient(opts)
+				ctx := context.Background()
+				if err := client.FlushDB(ctx).Err(); err != nil {
+					client.Close()
+					return nil, nil, err
+				}
+				cleanup := func() {
+					client.FlushDB(ctx)
+					client.Close()
+				}
+				return go_cache.NewRedis(client, go_cache.WithRedisSerializer(s)), cleanup, nil
+			},
+		})
+	}
+	return targets
+}
+
+// TieredTarget 两级缓存目标，L1为内存缓存，L2为使用gob序列化器的Redis缓存
+// 每次运行前清空opts指定的数据库，请勿指向生产数据库
+func TieredTarget(opts *redis.Options) Target {
+	l2 := RedisTargets(opts)[0]
+	return Target{
+		Name: "tiered/" + serializer.NewGob().Name(),
+		New: func() (gsr.Cacher, func(), error) {
+			redisCache, cleanup, err := l2.New()
+			if err != nil {
+				return nil, nil, err
+			}
+			return go_cache.NewTiered(go_cache.NewMemory(time.Minute, time.Minute), redisCache), cleanup, nil
+		},
+	}
+}
@@ -0,0 +1,64 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"math/bits"
+)
+
+// BitmapCache 支持位图操作的缓存，用于按日期记录用户是否活跃等标记
+// 位的顺序与Redis相同：偏移量0为第一个字节的最高位。写入的键没有过期时间，需要时使用 ExpiresIn 设置
+type BitmapCache interface {
+	// SetBit 设置key在offset处的位，返回原来的值；键不存在时创建
+	SetBit(ctx context.Context, key string, offset int64, value bool) (bool, error)
+	// GetBit 返回key在offset处的位，键不存在或超出长度时返回false
+	GetBit(ctx context.Context, key string, offset int64) (bool, error)
+	// BitCount 返回key中值为1的位数，键不存在时返回0
+	BitCount(ctx context.Context, key string) (int64, error)
+}
+
+// maxBitOffset 位偏移量的上限，与Redis的512MB字符串上限一致
+const maxBitOffset = 1<<32 - 1
+
+// bitmap 内存缓存保存的位图
+// 保存在缓存中的值不会被修改，写入时复制后替换
+type bitmap []byte
+
+// checkBitOffset 检查位偏移量
+func checkBitOffset(offset int64) error {
+	if offset < 0 || offset > maxBitOffset {
+		return fmt.Errorf("bitmap: offset %d out of range", offset)
+	}
+	return nil
+}
+
+// get 返回offset处的位
+func (b bitmap) get(offset int64) bool {
+	i := offset / 8
+	if i >= int64(len(b)) {
+		return false
+	}
+	return b[i]&(0x80>>(offset%8)) != 0
+}
+
+// set 返回设置offset处的位后的位图，位图长度不足时扩展
+func (b bitmap) set(offset int64, value bool) bitmap {
+	i := offset / 8
+	next := make(bitmap, max(int64(len(b)), i+1))
+	copy(next, b)
+	if value {
+		next[i] |= 0x80 >> (offset % 8)
+	} else {
+		next[i] &^= 0x80 >> (offset % 8)
+	}
+	return next
+}
+
+// count 返回值为1的位数
+func (b bitmap) count() int64 {
+	var n int
+	for _, c := range b {
+		n += bits.OnesCount8(c)
+	}
+	return int64(n)
+}
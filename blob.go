@@ -0,0 +1,210 @@
+package go_cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BlobStore 对象存储，用于保存超过大小阈值的缓存值（见 WithRedisBlobOffload）
+// 名称只包含字母、数字与 "-_."，不存在的对象在 GetBlob 时返回 ErrKeyNotFound，在 DeleteBlob 时不返回错误
+type BlobStore interface {
+	// PutBlob 写入对象，size 为r中的字节数，同名对象被覆盖
+	PutBlob(ctx context.Context, name string, r io.Reader, size int64) error
+	// GetBlob 读取对象，调用方负责关闭返回的 io.ReadCloser
+	GetBlob(ctx context.Context, name string) (io.ReadCloser, error)
+	// DeleteBlob 删除对象
+	DeleteBlob(ctx context.Context, name string) error
+}
+
+// BlobInfo 对象的元数据
+type BlobInfo struct {
+	Name    string
+	Size    int64
+	ModTime time.Time
+}
+
+// BlobLister 能够列出对象的存储，Redis的 SweepBlobs 需要存储实现该接口
+type BlobLister interface {
+	// ListBlobs 按名称顺序对每个名称以prefix开头的对象调用fn，fn 返回错误时停止并返回该错误
+	ListBlobs(ctx context.Context, prefix string, fn func(BlobInfo) error) error
+}
+
+// readBlob 读取整个对象
+func readBlob(ctx context.Context, store BlobStore, name string) ([]byte, error) {
+	r, err := store.GetBlob(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// MemoryBlobStore 保存在进程内存中的对象存储，用于测试与单机开发环境
+type MemoryBlobStore struct {
+	mu    sync.RWMutex
+	blobs map[string]memoryBlob
+}
+
+// memoryBlob 内存中的对象
+type memoryBlob struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewMemoryBlobStore 创建内存对象存储
+func NewMemoryBlobStore() *MemoryBlobStore {
+	return &MemoryBlobStore{blobs: make(map[string]memoryBlob)}
+}
+
+// Len 返回对象数量
+func (s *MemoryBlobStore) Len() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.blobs)
+}
+
+func (s *MemoryBlobStore) PutBlob(ctx context.Context, name string, r io.Reader, size int64) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[name] = memoryBlob{data: data, modTime: time.Now()}
+	return nil
+}
+
+func (s *MemoryBlobStore) GetBlob(ctx context.Context, name string) (io.ReadCloser, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	blob, ok := s.blobs[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: blob %q", ErrKeyNotFound, name)
+	}
+	return io.NopCloser(bytes.NewReader(blob.data)), nil
+}
+
+func (s *MemoryBlobStore) DeleteBlob(ctx context.Context, name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, name)
+	return nil
+}
+
+func (s *MemoryBlobStore) ListBlobs(ctx context.Context, prefix string, fn func(BlobInfo) error) error {
+	s.mu.RLock()
+	infos := make([]BlobInfo, 0, len(s.blobs))
+	for name, blob := range s.blobs {
+		if strings.HasPrefix(name, prefix) {
+			infos = append(infos, BlobInfo{Name: name, Size: int64(len(blob.data)), ModTime: blob.modTime})
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FileBlobStore 以目录中的文件保存对象的存储，适用于单机部署或挂载的共享文件系统
+// 写入先写临时文件再重命名，读取方不会看到写了一半的对象
+type FileBlobStore struct {
+	dir string
+}
+
+// NewFileBlobStore 创建保存在dir中的对象存储，目录不存在时创建
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+// path 返回对象的文件路径，拒绝包含路径分隔符的名称
+func (s *FileBlobStore) path(name string) (string, error) {
+	if name == "" || name != filepath.Base(name) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("invalid blob name %q", name)
+	}
+	return filepath.Join(s.dir, name), nil
+}
+
+func (s *FileBlobStore) PutBlob(ctx context.Context, name string, r io.Reader, size int64) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(s.dir, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (s *FileBlobStore) GetBlob(ctx context.Context, name string) (io.ReadCloser, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: blob %q", ErrKeyNotFound, name)
+	}
+	return f, err
+}
+
+func (s *FileBlobStore) DeleteBlob(ctx context.Context, name string) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *FileBlobStore) ListBlobs(ctx context.Context, prefix string, fn func(BlobInfo) error) error {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasPrefix(name, ".") || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if errors.Is(err, os.ErrNotExist) {
+			// 列出之后被删除
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(BlobInfo{Name: name, Size: info.Size(), ModTime: info.ModTime()}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
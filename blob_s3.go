@@ -0,0 +1,229 @@
+package go_cache
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// s3UnsignedPayload 不对请求体计算哈希，上传时不需要先读取整个对象
+const s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+
+// S3BlobConfig S3兼容对象存储的配置
+type S3BlobConfig struct {
+	Endpoint    string                  // 服务地址，如 MinIO 的 "http://minio:9000"，为空时使用 "https://s3.<Region>.amazonaws.com"
+	Region      string                  // 签名使用的区域，为空时使用 "us-east-1"
+	Bucket      string                  // 存储桶
+	Prefix      string                  // 对象键前缀，如 "cache/"
+	Credentials aws.CredentialsProvider // 访问凭证
+	PathStyle   bool                    // 使用 <Endpoint>/<Bucket>/<key> 形式的地址，MinIO 等自建服务通常需要开启
+	HTTPClient  *http.Client            // 为nil时使用 http.DefaultClient
+}
+
+// S3BlobStore 基于S3 REST接口的对象存储，同时适用于 Amazon S3 与 MinIO 等兼容服务
+// 请求使用SigV4签名，请求体不参与签名（UNSIGNED-PAYLOAD），因此 Endpoint 应使用HTTPS
+type S3BlobStore struct {
+	cfg    S3BlobConfig
+	base   *url.URL
+	signer *v4.Signer
+	client *http.Client
+}
+
+// NewS3BlobStore 创建S3对象存储
+func NewS3BlobStore(cfg S3BlobConfig) (*S3BlobStore, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("s3 blob store: empty bucket")
+	}
+	if cfg.Credentials == nil {
+		return nil, fmt.Errorf("s3 blob store: nil credentials")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://s3." + cfg.Region + ".amazonaws.com"
+	}
+	base, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("s3 blob store: %w", err)
+	}
+	if !cfg.PathStyle {
+		base.Host = cfg.Bucket + "." + base.Host
+	}
+	client := cfg.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &S3BlobStore{cfg: cfg, base: base, signer: v4.NewSigner(func(o *v4.SignerOptions) {
+		// S3 的签名使用请求中已转义的路径，不再次转义
+		o.DisableURIPathEscaping = true
+	}), client: client}, nil
+}
+
+// NewMinIOBlobStore 创建MinIO对象存储，使用路径形式的地址与 us-east-1 区域
+func NewMinIOBlobStore(endpoint, bucket string, credentials aws.CredentialsProvider) (*S3BlobStore, error) {
+	return NewS3BlobStore(S3BlobConfig{
+		Endpoint:    endpoint,
+		Bucket:      bucket,
+		Credentials: credentials,
+		PathStyle:   true,
+	})
+}
+
+// objectURL 返回对象键key的地址，key 为空时返回存储桶的地址
+func (s *S3BlobStore) objectURL(key string) *url.URL {
+	u := *s.base
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.cfg.PathStyle {
+		path += "/" + url.PathEscape(s.cfg.Bucket)
+	}
+	path += "/"
+	if key != "" {
+		segments := strings.Split(key, "/")
+		for i, segment := range segments {
+			segments[i] = url.PathEscape(segment)
+		}
+		path += strings.Join(segments, "/")
+	}
+	u.RawPath = path
+	u.Path, _ = url.PathUnescape(path)
+	return &u
+}
+
+// do 签名并发送请求
+func (s *S3BlobStore) do(ctx context.Context, method string, u *url.URL, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+	}
+	req.Header.Set("X-Amz-Content-Sha256", s3UnsignedPayload)
+
+	creds, err := s.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("s3 blob store: retrieve credentials: %w", err)
+	}
+	if err := s.signer.SignHTTP(ctx, creds, req, s3UnsignedPayload, "s3", s.cfg.Region, time.Now()); err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return resp, nil
+}
+
+// s3Error 将失败的响应转换为错误，5xx 与 429 视为暂时不可用
+func s3Error(resp *http.Response, name string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	err := fmt.Errorf("s3 blob %q: %s: %s", name, resp.Status, strings.TrimSpace(string(body)))
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return &Error{Kind: ErrUnavailable, Err: err}
+	}
+	return &Error{Kind: ErrBackend, Err: err}
+}
+
+func (s *S3BlobStore) PutBlob(ctx context.Context, name string, r io.Reader, size int64) error {
+	resp, err := s.do(ctx, http.MethodPut, s.objectURL(s.cfg.Prefix+name), r, size)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		return s3Error(resp, name)
+	}
+	return nil
+}
+
+func (s *S3BlobStore) GetBlob(ctx context.Context, name string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.objectURL(s.cfg.Prefix+name), nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: blob %q", ErrKeyNotFound, name)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		return nil, s3Error(resp, name)
+	}
+	return resp.Body, nil
+}
+
+func (s *S3BlobStore) DeleteBlob(ctx context.Context, name string) error {
+	resp, err := s.do(ctx, http.MethodDelete, s.objectURL(s.cfg.Prefix+name), nil, 0)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+		return s3Error(resp, name)
+	}
+	return nil
+}
+
+// s3ListResult ListObjectsV2 的响应
+type s3ListResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// ListBlobs 使用 ListObjectsV2 分页列出对象
+func (s *S3BlobStore) ListBlobs(ctx context.Context, prefix string, fn func(BlobInfo) error) error {
+	token := ""
+	for {
+		u := s.objectURL("")
+		query := url.Values{"list-type": {"2"}, "prefix": {s.cfg.Prefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		u.RawQuery = query.Encode()
+
+		resp, err := s.do(ctx, http.MethodGet, u, nil, 0)
+		if err != nil {
+			return err
+		}
+		if resp.StatusCode/100 != 2 {
+			err := s3Error(resp, prefix)
+			resp.Body.Close()
+			return err
+		}
+		var result s3ListResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("s3 blob store: decode list response: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			info := BlobInfo{
+				Name:    strings.TrimPrefix(object.Key, s.cfg.Prefix),
+				Size:    object.Size,
+				ModTime: object.LastModified,
+			}
+			if err := fn(info); err != nil {
+				return err
+			}
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return nil
+		}
+		token = result.NextContinuationToken
+	}
+}
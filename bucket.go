@@ -0,0 +1,232 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultBucketKeyPrefix 令牌桶与漏桶状态在缓存中的默认键前缀
+const DefaultBucketKeyPrefix = "bucket:"
+
+// ErrBucketExceeded 一次请求的数量超过桶的容量，永远不会被允许
+var ErrBucketExceeded = errors.New("bucket: request exceeds capacity")
+
+// BucketResult 令牌桶与漏桶的判定结果，可直接用于 HTTP 429 响应
+type BucketResult struct {
+	Allowed    bool
+	Remaining  int           // 令牌桶为剩余令牌数，漏桶为剩余容量
+	RetryAfter time.Duration // 未被允许时距离可以再次请求的时间（Retry-After），允许时为0
+	Delay      time.Duration // 漏桶中请求按恒定速率流出前需要等待的时间，令牌桶总是0
+}
+
+// bucketAlgo 桶的算法
+type bucketAlgo string
+
+const (
+	bucketToken bucketAlgo = "token"
+	bucketLeaky bucketAlgo = "leaky"
+)
+
+// bucketState 缓存中保存的桶状态
+// 令牌桶的 Value 为剩余令牌数，漏桶为桶内的水位
+type bucketState struct {
+	Value   float64
+	Updated int64 // Unix毫秒
+}
+
+// bucketTaker 由能够在服务端原子执行判定的缓存实现（如Redis使用Lua脚本）
+type bucketTaker interface {
+	takeBucket(ctx context.Context, key string, algo bucketAlgo, capacity int, interval time.Duration, n int) (BucketResult, error)
+}
+
+// BucketOption 令牌桶与漏桶选项
+type BucketOption func(*bucket)
+
+// WithBucketKeyPrefix 设置桶状态在缓存中的键前缀，默认 DefaultBucketKeyPrefix
+func WithBucketKeyPrefix(prefix string) BucketOption {
+	return func(b *bucket) {
+		b.prefix = prefix
+	}
+}
+
+// WithBucketClock 设置计算补充与流出所用的时钟，默认使用系统时间
+// Redis缓存在服务端使用Redis的时间，不受该选项影响
+func WithBucketClock(clock Clock) BucketOption {
+	return func(b *bucket) {
+		if clock != nil {
+			b.clock = clock
+		}
+	}
+}
+
+// bucket 令牌桶与漏桶的公共实现
+type bucket struct {
+	cache    gsr.Cacher
+	algo     bucketAlgo
+	capacity int
+	interval time.Duration
+	prefix   string
+	clock    Clock
+
+	mu sync.Mutex // 底层缓存不支持事务时保护状态的读-改-写
+}
+
+func newBucket(cache gsr.Cacher, algo bucketAlgo, capacity int, interval time.Duration, opts []BucketOption) *bucket {
+	b := &bucket{
+		cache:    cache,
+		algo:     algo,
+		capacity: max(capacity, 1),
+		interval: max(interval, time.Millisecond),
+		prefix:   DefaultBucketKeyPrefix,
+		clock:    realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(b)
+	}
+
+	return b
+}
+
+// take 对key执行一次数量为n的判定
+func (b *bucket) take(ctx context.Context, key string, n int) (BucketResult, error) {
+	if n > b.capacity {
+		return BucketResult{}, fmt.Errorf("%w: %d > %d", ErrBucketExceeded, n, b.capacity)
+	}
+	key, n = b.prefix+key, max(n, 0)
+
+	if t, ok := b.cache.(bucketTaker); ok {
+		return t.takeBucket(ctx, key, b.algo, b.capacity, b.interval, n)
+	}
+
+	var res BucketResult
+	err := update(ctx, b.cache, &b.mu, key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+		state, err := b.load(ctx, get, key)
+		if err != nil {
+			return nil, 0, err
+		}
+		var next bucketState
+		var ttl time.Duration
+		res, next, ttl = b.apply(state, n)
+		if ttl <= 0 {
+			return nil, 0, nil
+		}
+		return next, ttl, nil
+	})
+	if err != nil {
+		return BucketResult{}, err
+	}
+	return res, nil
+}
+
+// load 读取桶状态，不存在时返回nil
+func (b *bucket) load(ctx context.Context, get func(context.Context, string, any) error, key string) (*bucketState, error) {
+	var state bucketState
+	if err := get(ctx, key, &state); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
+
+// apply 按当前时间计算判定结果与新的状态，ttl 为状态恢复到空闲所需的时间，<= 0 时不需要保存
+// 与 bucketScript 的计算保持一致
+func (b *bucket) apply(state *bucketState, n int) (BucketResult, bucketState, time.Duration) {
+	now := b.clock.Now().UnixMilli()
+	interval := float64(b.interval) / float64(time.Millisecond)
+	capacity, need := float64(b.capacity), float64(n)
+
+	var res BucketResult
+	var value float64
+	var idleMs float64
+	switch b.algo {
+	case bucketToken:
+		value = capacity
+		if state != nil {
+			value = math.Min(capacity, state.Value+float64(max(now-state.Updated, 0))/interval)
+		}
+		if value >= need {
+			value -= need
+			res.Allowed = true
+		} else {
+			res.RetryAfter = bucketDuration((need - value) * interval)
+		}
+		res.Remaining = int(math.Floor(value))
+		idleMs = (capacity - value) * interval
+	default:
+		if state != nil {
+			value = math.Max(0, state.Value-float64(max(now-state.Updated, 0))/interval)
+		}
+		if value+need <= capacity {
+			res.Allowed = true
+			res.Delay = bucketDuration(value * interval)
+			value += need
+		} else {
+			res.RetryAfter = bucketDuration((value + need - capacity) * interval)
+		}
+		res.Remaining = int(math.Floor(capacity - value))
+		idleMs = value * interval
+	}
+	return res, bucketState{Value: value, Updated: now}, bucketDuration(idleMs)
+}
+
+// bucketDuration 将毫秒向上取整为时长
+func bucketDuration(ms float64) time.Duration {
+	return time.Duration(math.Ceil(ms)) * time.Millisecond
+}
+
+// TokenBucket 令牌桶，允许突发请求
+// 每个键的桶最多保存 capacity 个令牌，每隔 interval 补充一个，请求消耗令牌；
+// 状态保存在缓存中，多个实例共享同一个缓存时共享配额。
+// Redis缓存在服务端用Lua脚本原子执行，实现 TxnCache 的缓存在事务中执行，
+// 其他缓存只在本实例内串行
+type TokenBucket struct {
+	b *bucket
+}
+
+// NewTokenBucket 创建容量为capacity、每隔interval补充一个令牌的令牌桶
+func NewTokenBucket(cache gsr.Cacher, capacity int, interval time.Duration, opts ...BucketOption) *TokenBucket {
+	return &TokenBucket{b: newBucket(cache, bucketToken, capacity, interval, opts)}
+}
+
+// Allow 消耗key的一个令牌
+func (t *TokenBucket) Allow(ctx context.Context, key string) (BucketResult, error) {
+	return t.b.take(ctx, key, 1)
+}
+
+// Take 消耗key的n个令牌，令牌不足时不消耗；n 超过容量时返回 ErrBucketExceeded
+func (t *TokenBucket) Take(ctx context.Context, key string, n int) (BucketResult, error) {
+	return t.b.take(ctx, key, n)
+}
+
+// LeakyBucket 漏桶，使请求以恒定速率流出
+// 每个键的桶最多容纳 capacity 个请求，每隔 interval 流出一个，桶满时拒绝请求；
+// 被允许的请求通过 BucketResult.Delay 得到按恒定速率处理前需要等待的时间。
+// 原子性与 TokenBucket 相同
+type LeakyBucket struct {
+	b *bucket
+}
+
+// NewLeakyBucket 创建容量为capacity、每隔interval流出一个请求的漏桶
+func NewLeakyBucket(cache gsr.Cacher, capacity int, interval time.Duration, opts ...BucketOption) *LeakyBucket {
+	return &LeakyBucket{b: newBucket(cache, bucketLeaky, capacity, interval, opts)}
+}
+
+// Allow 向key的桶加入一个请求
+func (l *LeakyBucket) Allow(ctx context.Context, key string) (BucketResult, error) {
+	return l.b.take(ctx, key, 1)
+}
+
+// Take 向key的桶加入n个请求，容量不足时不加入；n 超过容量时返回 ErrBucketExceeded
+func (l *LeakyBucket) Take(ctx context.Context, key string, n int) (BucketResult, error) {
+	return l.b.take(ctx, key, n)
+}
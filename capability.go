@@ -0,0 +1,159 @@
+package go_cache
+
+import (
+	"sort"
+
+	"github.com/muleiwu/gsr"
+)
+
+// Capability 缓存可选能力，对应一个可选接口
+type Capability string
+
+const (
+	// CapabilityBatch 批量操作，见 BatchCache
+	CapabilityBatch Capability = "batch"
+	// CapabilityMultiGet 一次往返读取多个键，见 MultiGetCache
+	CapabilityMultiGet Capability = "mget"
+	// CapabilityPattern 按模式列出与删除键，见 PatternCache
+	CapabilityPattern Capability = "pattern"
+	// CapabilityExpiry 移除与刷新过期时间，见 ExpiryCache
+	CapabilityExpiry Capability = "expiry"
+	// CapabilityConditionalExpiry 按条件设置过期时间，见 ConditionalExpiryCache
+	CapabilityConditionalExpiry Capability = "conditional_expiry"
+	// CapabilityTTL 查询剩余有效期，见 TTLCache
+	CapabilityTTL Capability = "ttl"
+	// CapabilityTTLSampling 采样剩余有效期，见 TTLSampler
+	CapabilityTTLSampling Capability = "ttl_sampling"
+	// CapabilityRename 重命名与复制键，见 RenameCache
+	CapabilityRename Capability = "rename"
+	// CapabilityTxn 事务，见 TxnCache
+	CapabilityTxn Capability = "txn"
+	// CapabilityPatch 按字段读写结构体，见 PatchCache
+	CapabilityPatch Capability = "patch"
+	// CapabilityPriority 按优先级写入，见 PrioritySetter
+	CapabilityPriority Capability = "priority"
+	// CapabilitySetNX 仅在键不存在时写入，见 SetNXCache
+	CapabilitySetNX Capability = "setnx"
+	// CapabilityPubSub 发布订阅，见 PubSubCache
+	CapabilityPubSub Capability = "pubsub"
+	// CapabilityUnique HyperLogLog不重复计数，见 UniqueCache
+	CapabilityUnique Capability = "unique"
+	// CapabilityBitmap 位图操作，见 BitmapCache
+	CapabilityBitmap Capability = "bitmap"
+)
+
+// capabilityChecks 每种能力对应的接口断言
+var capabilityChecks = map[Capability]func(gsr.Cacher) bool{
+	CapabilityBatch: func(c gsr.Cacher) bool {
+		_, ok := c.(BatchCache)
+		return ok
+	},
+	CapabilityMultiGet: func(c gsr.Cacher) bool {
+		_, ok := c.(MultiGetCache)
+		return ok
+	},
+	CapabilityPattern: func(c gsr.Cacher) bool {
+		_, ok := c.(PatternCache)
+		return ok
+	},
+	CapabilityExpiry: func(c gsr.Cacher) bool {
+		_, ok := c.(ExpiryCache)
+		return ok
+	},
+	CapabilityConditionalExpiry: func(c gsr.Cacher) bool {
+		_, ok := c.(ConditionalExpiryCache)
+		return ok
+	},
+	CapabilityTTL: func(c gsr.Cacher) bool {
+		_, ok := c.(TTLCache)
+		return ok
+	},
+	CapabilityTTLSampling: func(c gsr.Cacher) bool {
+		_, ok := c.(TTLSampler)
+		return ok
+	},
+	CapabilityRename: func(c gsr.Cacher) bool {
+		_, ok := c.(RenameCache)
+		return ok
+	},
+	CapabilityTxn: func(c gsr.Cacher) bool {
+		_, ok := c.(TxnCache)
+		return ok
+	},
+	CapabilityPatch: func(c gsr.Cacher) bool {
+		_, ok := c.(PatchCache)
+		return ok
+	},
+	CapabilityPriority: func(c gsr.Cacher) bool {
+		_, ok := c.(PrioritySetter)
+		return ok
+	},
+	CapabilitySetNX: func(c gsr.Cacher) bool {
+		_, ok := c.(SetNXCache)
+		return ok
+	},
+	CapabilityPubSub: func(c gsr.Cacher) bool {
+		_, ok := c.(PubSubCache)
+		return ok
+	},
+	CapabilityUnique: func(c gsr.Cacher) bool {
+		_, ok := c.(UniqueCache)
+		return ok
+	},
+	CapabilityBitmap: func(c gsr.Cacher) bool {
+		_, ok := c.(BitmapCache)
+		return ok
+	},
+}
+
+// CapabilityProvider 由能力取决于底层缓存的包装类型实现
+// 包装类型即使实现了某个可选接口，底层缓存不支持时调用也会返回 ErrNotSupported，
+// 实现该接口后 Supports 以其返回的能力为准
+type CapabilityProvider interface {
+	Capabilities() []Capability
+}
+
+// Supports 判断缓存是否支持能力c
+// 缓存实现 CapabilityProvider 时以其返回的能力为准，否则按对应的可选接口判断
+func Supports(cache gsr.Cacher, c Capability) bool {
+	if p, ok := cache.(CapabilityProvider); ok {
+		for _, capability := range p.Capabilities() {
+			if capability == c {
+				return true
+			}
+		}
+		return false
+	}
+	check, ok := capabilityChecks[c]
+	return ok && check(cache)
+}
+
+// Capabilities 返回缓存支持的全部能力，按名称排序
+func Capabilities(cache gsr.Cacher) []Capability {
+	if p, ok := cache.(CapabilityProvider); ok {
+		caps := append([]Capability(nil), p.Capabilities()...)
+		sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+		return caps
+	}
+
+	var caps []Capability
+	for c, check := range capabilityChecks {
+		if check(cache) {
+			caps = append(caps, c)
+		}
+	}
+	sort.Slice(caps, func(i, j int) bool { return caps[i] < caps[j] })
+	return caps
+}
+
+// wrapperCapabilities 返回包装类型的能力：own 为包装类型自身提供的能力，
+// forwarded 中的能力只有底层缓存同样支持时才会返回
+func wrapperCapabilities(inner gsr.Cacher, own []Capability, forwarded ...Capability) []Capability {
+	caps := append([]Capability(nil), own...)
+	for _, c := range forwarded {
+		if Supports(inner, c) {
+			caps = append(caps, c)
+		}
+	}
+	return caps
+}
@@ -0,0 +1,60 @@
+package go_cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock 时钟接口，缓存通过它获取当前时间来判断过期
+// 测试中可使用 FakeClock 手动推进时间，避免依赖 time.Sleep
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock 使用系统时间的时钟
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+// isRealClock 判断是否为系统时钟
+func isRealClock(c Clock) bool {
+	_, ok := c.(realClock)
+	return ok
+}
+
+// FakeClock 可手动推进的时钟，并发安全
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock 创建从now开始的时钟，now为零值时使用当前系统时间
+func NewFakeClock(now time.Time) *FakeClock {
+	if now.IsZero() {
+		now = time.Now()
+	}
+	return &FakeClock{now: now}
+}
+
+// Now 返回时钟的当前时间
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance 将时钟向前推进d
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set 将时钟设置为指定时间
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
@@ -0,0 +1,45 @@
+// cachebench 对比不同缓存后端与序列化器的性能
+//
+// 用法：
+//
+//	go run ./cmd/cachebench -redis localhost:6379 -db 15 -ops 20000
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/muleiwu/go-cache/bench"
+	"github.com/redis/go-redis/v9"
+)
+
+func main() {
+	redisAddr := flag.String("redis", "", "Redis地址，为空时只测试内存缓存")
+	redisDB := flag.Int("db", 15, "Redis数据库编号（运行前会被清空）")
+	ops := flag.Int("ops", 10000, "每个工作负载的操作数")
+	concurrency := flag.Int("concurrency", 4, "并发数")
+	flag.Parse()
+
+	targets := []bench.Target{bench.MemoryTarget()}
+	if *redisAddr != "" {
+		opts := &redis.Options{Addr: *redisAddr, DB: *redisDB}
+		targets = append(targets, bench.RedisTargets(opts)...)
+		targets = append(targets, bench.TieredTarget(opts))
+	}
+
+	results, err := bench.Run(context.Background(), targets, bench.DefaultWorkloads, bench.Options{
+		Ops:         *ops,
+		Concurrency: *concurrency,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "cachebench:", err)
+		os.Exit(1)
+	}
+
+	if err := bench.WriteTable(os.Stdout, results); err != nil {
+		fmt.Fprintln(os.Stderr, "cachebench:", err)
+		os.Exit(1)
+	}
+}
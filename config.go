@@ -0,0 +1,319 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultConfigKeyPrefix 配置文档在缓存中的默认键前缀
+const DefaultConfigKeyPrefix = "config:"
+
+// ConfigUpdate 配置文档的更新通知
+type ConfigUpdate struct {
+	Key     string
+	Version int64
+}
+
+// ConfigNotifier 在多个实例之间传递配置更新的通知
+// 通知中的键为缓存中的实际键（包含前缀）
+type ConfigNotifier interface {
+	// Notify 通知所有订阅者key已更新
+	Notify(ctx context.Context, key string) error
+	// Subscribe 订阅更新通知，每次有键更新时调用fn，返回取消订阅的函数
+	Subscribe(fn func(key string)) (cancel func(), err error)
+}
+
+// localConfigNotifier 只在本进程内传递通知的默认实现
+type localConfigNotifier struct {
+	mu   sync.Mutex
+	subs map[int]func(string)
+	next int
+}
+
+func (n *localConfigNotifier) Notify(ctx context.Context, key string) error {
+	n.mu.Lock()
+	subs := make([]func(string), 0, len(n.subs))
+	for _, fn := range n.subs {
+		subs = append(subs, fn)
+	}
+	n.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(key)
+	}
+	return nil
+}
+
+func (n *localConfigNotifier) Subscribe(fn func(key string)) (func(), error) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	id := n.next
+	n.next++
+	n.subs[id] = fn
+	return func() {
+		n.mu.Lock()
+		delete(n.subs, id)
+		n.mu.Unlock()
+	}, nil
+}
+
+// configEntry 缓存中保存的配置文档
+type configEntry struct {
+	Version int64
+	Data    []byte
+}
+
+// ConfigOption 配置缓存选项
+type ConfigOption func(*Config)
+
+// WithConfigNotifier 设置更新通知的传递方式，默认只通知本进程内的 Watch
+// 多个实例需要互相通知时使用 NewRedisConfigNotifier
+func WithConfigNotifier(n ConfigNotifier) ConfigOption {
+	return func(c *Config) {
+		if n != nil {
+			c.notifier = n
+		}
+	}
+}
+
+// WithConfigSerializer 设置配置文档的序列化器
+func WithConfigSerializer(s serializer.Serializer) ConfigOption {
+	return func(c *Config) {
+		c.serializer = s
+	}
+}
+
+// WithConfigKeyPrefix 设置配置文档在缓存中的键前缀，默认 DefaultConfigKeyPrefix
+func WithConfigKeyPrefix(prefix string) ConfigOption {
+	return func(c *Config) {
+		c.prefix = prefix
+	}
+}
+
+// Config 带版本号的配置文档缓存
+// 每次 Set 都会递增文档的版本号并发送更新通知，Watch 返回的通道在版本号变大时收到更新，
+// 服务可以据此热加载配置。底层缓存实现 TxnCache 时版本号在事务中递增，
+// 否则只在本实例内串行，多个进程同时写入同一个文档时版本号可能重复
+type Config struct {
+	cache      gsr.Cacher
+	notifier   ConfigNotifier
+	serializer serializer.Serializer
+	prefix     string
+
+	writeMu sync.Mutex // 底层缓存不支持事务时保护版本号的读-改-写
+
+	mu          sync.Mutex // 保护以下字段
+	watchers    map[string]map[*configWatcher]struct{}
+	unsubscribe func()
+	closed      bool
+	done        chan struct{} // Close 时关闭，结束等待ctx的goroutine
+}
+
+// configWatcher 一个 Watch 调用
+type configWatcher struct {
+	ch   chan ConfigUpdate
+	last int64 // 已发送的最大版本号
+}
+
+// NewConfig 创建使用cache保存配置文档的配置缓存
+// 默认使用gob序列化器，使用完毕后需要调用 Close 关闭所有 Watch 通道
+func NewConfig(cache gsr.Cacher, opts ...ConfigOption) *Config {
+	c := &Config{
+		cache:      cache,
+		notifier:   &localConfigNotifier{subs: make(map[int]func(string))},
+		serializer: cache_value.GetDefaultSerializer(), // 默认使用gob
+		prefix:     DefaultConfigKeyPrefix,
+		watchers:   make(map[string]map[*configWatcher]struct{}),
+		done:       make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// Get 读取配置文档到obj，返回文档的版本号；文档不存在时返回 ErrKeyNotFound
+func (c *Config) Get(ctx context.Context, key string, obj any) (int64, error) {
+	entry, err := c.load(ctx, c.cache.Get, key)
+	if err != nil {
+		return 0, err
+	}
+	if err := c.serializer.Decode(entry.Data, obj); err != nil {
+		return 0, serializationError(fmt.Errorf("config %s: %w", key, err))
+	}
+	return entry.Version, nil
+}
+
+// Version 返回配置文档的版本号，文档不存在时返回 ErrKeyNotFound
+func (c *Config) Version(ctx context.Context, key string) (int64, error) {
+	entry, err := c.load(ctx, c.cache.Get, key)
+	if err != nil {
+		return 0, err
+	}
+	return entry.Version, nil
+}
+
+// Set 写入配置文档并通知订阅者，返回新的版本号
+// 配置文档不会过期；写入成功但通知失败时返回新的版本号与通知的错误
+func (c *Config) Set(ctx context.Context, key string, value any) (int64, error) {
+	data, err := c.serializer.Encode(value)
+	if err != nil {
+		return 0, serializationError(fmt.Errorf("config %s: %w", key, err))
+	}
+
+	version, err := c.store(ctx, key, data)
+	if err != nil {
+		return 0, err
+	}
+	return version, c.notifier.Notify(ctx, c.prefix+key)
+}
+
+// Del 删除配置文档，Watch 不会收到删除的通知
+func (c *Config) Del(ctx context.Context, key string) error {
+	return c.cache.Del(ctx, c.prefix+key)
+}
+
+// Watch 返回在配置文档版本号变大时收到更新的通道，ctx 取消或 Close 后通道被关闭
+// 只会收到调用之后的更新；接收方处理不及时时多次更新合并为最新的一次
+func (c *Config) Watch(ctx context.Context, key string) (<-chan ConfigUpdate, error) {
+	w := &configWatcher{ch: make(chan ConfigUpdate, 1)}
+	if entry, err := c.load(ctx, c.cache.Get, key); err == nil {
+		w.last = entry.Version
+	}
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil, errors.New("config: closed")
+	}
+	if c.unsubscribe == nil {
+		cancel, err := c.notifier.Subscribe(c.notified)
+		if err != nil {
+			c.mu.Unlock()
+			return nil, err
+		}
+		c.unsubscribe = cancel
+	}
+	if c.watchers[key] == nil {
+		c.watchers[key] = make(map[*configWatcher]struct{})
+	}
+	c.watchers[key][w] = struct{}{}
+	c.mu.Unlock()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-c.done:
+			return
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.watchers[key][w]; ok {
+			delete(c.watchers[key], w)
+			if len(c.watchers[key]) == 0 {
+				delete(c.watchers, key)
+			}
+			close(w.ch)
+		}
+	}()
+
+	return w.ch, nil
+}
+
+// Close 取消订阅并关闭所有 Watch 通道
+func (c *Config) Close() error {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return nil
+	}
+	c.closed = true
+	close(c.done)
+	for key, watchers := range c.watchers {
+		for w := range watchers {
+			close(w.ch)
+		}
+		delete(c.watchers, key)
+	}
+	unsubscribe := c.unsubscribe
+	c.mu.Unlock()
+
+	// 取消订阅可能需要等待正在处理的通知，不能持有锁
+	if unsubscribe != nil {
+		unsubscribe()
+	}
+	return nil
+}
+
+// notified 处理更新通知，读取文档的当前版本号并发送给该键的 Watch
+func (c *Config) notified(cacheKey string) {
+	key, ok := strings.CutPrefix(cacheKey, c.prefix)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	watching := len(c.watchers[key]) > 0
+	c.mu.Unlock()
+	if !watching {
+		return
+	}
+
+	version, err := c.Version(context.Background(), key)
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for w := range c.watchers[key] {
+		if version <= w.last {
+			continue
+		}
+		w.last = version
+		// 通道已满时丢弃未读取的旧更新，只保留最新的一次
+		select {
+		case <-w.ch:
+		default:
+		}
+		w.ch <- ConfigUpdate{Key: key, Version: version}
+	}
+}
+
+// load 读取配置文档
+func (c *Config) load(ctx context.Context, get func(context.Context, string, any) error, key string) (configEntry, error) {
+	var entry configEntry
+	if err := get(ctx, c.prefix+key, &entry); err != nil {
+		return configEntry{}, err
+	}
+	return entry, nil
+}
+
+// store 以递增的版本号写入文档，返回新的版本号
+func (c *Config) store(ctx context.Context, key string, data []byte) (int64, error) {
+	var version int64
+	err := update(ctx, c.cache, &c.writeMu, c.prefix+key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+		entry, err := c.load(ctx, get, key)
+		if err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return nil, 0, err
+		}
+		version = entry.Version + 1
+		return configEntry{Version: version, Data: data}, 0, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
+}
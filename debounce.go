@@ -0,0 +1,275 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DebounceOption 合并写入选项
+type DebounceOption func(*DebouncedWriter)
+
+// WithDebounceErrorHook 设置后台刷新写入失败时的回调，默认忽略错误
+func WithDebounceErrorHook(hook func(key string, err error)) DebounceOption {
+	return func(d *DebouncedWriter) {
+		d.onError = hook
+	}
+}
+
+// WithDebounceMaxPending 设置等待刷新的键数上限，超过时 Set 会立即刷新，n <= 0 表示不限制
+func WithDebounceMaxPending(n int) DebounceOption {
+	return func(d *DebouncedWriter) {
+		d.maxPending = n
+	}
+}
+
+// pendingWrite 等待刷新的写入
+type pendingWrite struct {
+	value     any
+	expiresAt time.Time // 零值表示不过期
+}
+
+// newPendingWrite 创建写入，ttl从 Set 时开始计算
+func newPendingWrite(value any, ttl time.Duration) pendingWrite {
+	w := pendingWrite{value: value}
+	if ttl > 0 {
+		w.expiresAt = time.Now().Add(ttl)
+	}
+	return w
+}
+
+// remaining 返回写入剩余的有效期（0表示不过期）以及是否已过期
+func (w pendingWrite) remaining() (time.Duration, bool) {
+	if w.expiresAt.IsZero() {
+		return 0, false
+	}
+	ttl := time.Until(w.expiresAt)
+	return ttl, ttl <= 0
+}
+
+// DebouncedWriter 合并高频写入的缓存
+// Set 只在进程内记录每个键最新的值，每隔 interval 把最新值写入底层缓存一次，
+// 适用于每秒写入上百次的实时计数、在线状态等键，大幅减少对Redis的写入量。
+// 读取优先返回尚未刷新的值；TTL从 Set 时开始计算，刷新时只写入剩余的有效期。
+// 进程退出前需要调用 Close 刷新剩余的写入，Close 之后的 Set 直接写入底层缓存
+type DebouncedWriter struct {
+	cache      gsr.Cacher
+	interval   time.Duration
+	maxPending int
+	onError    func(key string, err error)
+
+	mu       sync.Mutex
+	pending  map[string]pendingWrite
+	flushing map[string]pendingWrite // 正在写入底层缓存的值，写入完成前仍可读取
+	closed   bool                    // Close 之后不再合并写入
+
+	// flushMu 保证刷新串行执行，删除与修改过期时间时等待正在进行的刷新，避免旧值覆盖
+	flushMu sync.Mutex
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewDebouncedWriter 创建合并写入的缓存，每隔interval把最新值写入cache，interval <= 0 时使用100ms
+func NewDebouncedWriter(cache gsr.Cacher, interval time.Duration, opts ...DebounceOption) *DebouncedWriter {
+	if interval <= 0 {
+		interval = 100 * time.Millisecond
+	}
+	d := &DebouncedWriter{
+		cache:    cache,
+		interval: interval,
+		pending:  make(map[string]pendingWrite),
+		stop:     make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	d.wg.Add(1)
+	go d.flushLoop()
+
+	return d
+}
+
+// Pending 返回等待刷新的键数
+func (d *DebouncedWriter) Pending() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return len(d.pending)
+}
+
+// Flush 立即把所有等待刷新的值写入底层缓存，返回所有写入错误
+func (d *DebouncedWriter) Flush(ctx context.Context) error {
+	d.flushMu.Lock()
+	defer d.flushMu.Unlock()
+
+	d.mu.Lock()
+	batch := d.pending
+	d.pending = make(map[string]pendingWrite)
+	d.flushing = batch
+	d.mu.Unlock()
+
+	var errs []error
+	for key, w := range batch {
+		if err := d.write(ctx, key, w); err != nil {
+			errs = append(errs, err)
+			if d.onError != nil {
+				d.onError(key, err)
+			}
+		}
+	}
+
+	d.mu.Lock()
+	d.flushing = nil
+	d.mu.Unlock()
+
+	return errors.Join(errs...)
+}
+
+// Close 停止后台刷新并写入剩余的值，不会关闭底层缓存
+func (d *DebouncedWriter) Close() error {
+	d.stopOnce.Do(func() {
+		close(d.stop)
+	})
+	d.wg.Wait()
+
+	d.mu.Lock()
+	d.closed = true
+	d.mu.Unlock()
+	return d.Flush(context.Background())
+}
+
+// write 把一个写入刷新到底层缓存
+// 已过期的写入删除底层缓存中的键，避免更早写入的值在最新值过期后重新可读
+func (d *DebouncedWriter) write(ctx context.Context, key string, w pendingWrite) error {
+	ttl, expired := w.remaining()
+	if expired {
+		return d.cache.Del(ctx, key)
+	}
+	return d.cache.Set(ctx, key, w.value, ttl)
+}
+
+// flushLoop 定期刷新
+func (d *DebouncedWriter) flushLoop() {
+	defer d.wg.Done()
+
+	ticker := time.NewTicker(d.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			_ = d.Flush(context.Background())
+		}
+	}
+}
+
+// unflushed 返回尚未写入底层缓存的值，值已过期时仍返回true，由调用方视为未命中
+func (d *DebouncedWriter) unflushed(key string) (pendingWrite, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if w, ok := d.pending[key]; ok {
+		return w, true
+	}
+	w, ok := d.flushing[key]
+	return w, ok
+}
+
+func (d *DebouncedWriter) Exists(ctx context.Context, key string) bool {
+	if w, ok := d.unflushed(key); ok {
+		_, expired := w.remaining()
+		return !expired
+	}
+	return d.cache.Exists(ctx, key)
+}
+
+func (d *DebouncedWriter) Get(ctx context.Context, key string, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	if w, ok := d.unflushed(key); ok {
+		// 最新的值已过期时不能回退到底层缓存中更早写入的值
+		if _, expired := w.remaining(); expired {
+			return ErrKeyNotFound
+		}
+		return assignValue(obj, w.value)
+	}
+	return d.cache.Get(ctx, key, obj)
+}
+
+// Set 记录键的最新值，在下一次刷新时写入底层缓存；Close 之后直接写入底层缓存
+func (d *DebouncedWriter) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return d.cache.Set(ctx, key, value, ttl)
+	}
+	d.pending[key] = newPendingWrite(value, ttl)
+	full := d.maxPending > 0 && len(d.pending) >= d.maxPending
+	d.mu.Unlock()
+
+	if full {
+		return d.Flush(ctx)
+	}
+	return nil
+}
+
+func (d *DebouncedWriter) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	return loaderOf(d.cache).getSet(ctx, d, key, ttl, obj, fun)
+}
+
+// Del 丢弃尚未刷新的值并删除底层缓存中的键
+func (d *DebouncedWriter) Del(ctx context.Context, key string) error {
+	d.flushMu.Lock()
+	defer d.flushMu.Unlock()
+
+	d.mu.Lock()
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	return d.cache.Del(ctx, key)
+}
+
+// ExpiresAt 先写入键尚未刷新的值，再设置过期时间
+func (d *DebouncedWriter) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	if err := d.flushKey(ctx, key); err != nil {
+		return err
+	}
+	return d.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+// ExpiresIn 先写入键尚未刷新的值，再设置剩余有效期
+func (d *DebouncedWriter) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	if err := d.flushKey(ctx, key); err != nil {
+		return err
+	}
+	return d.cache.ExpiresIn(ctx, key, ttl)
+}
+
+// flushKey 立即写入单个键尚未刷新的值
+func (d *DebouncedWriter) flushKey(ctx context.Context, key string) error {
+	d.flushMu.Lock()
+	defer d.flushMu.Unlock()
+
+	d.mu.Lock()
+	w, ok := d.pending[key]
+	delete(d.pending, key)
+	d.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return d.write(ctx, key, w)
+}
@@ -0,0 +1,26 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// Dedup 原子记录事件标识key并报告是否第一次出现，用于webhook与消息的去重
+// 第一次出现时记录保留window，期间相同的key返回 firstSeen 为false；
+// 窗口从第一次出现开始计算，重复出现不会延长窗口。
+// Redis 使用 SET NX PX，内存缓存在锁内检查并写入；
+// cache 需实现 SetNXCache，否则返回 ErrNotSupported；window <= 0 时返回 ErrInvalidTTL
+func Dedup(ctx context.Context, cache gsr.Cacher, key string, window time.Duration) (firstSeen bool, err error) {
+	if window <= 0 {
+		return false, fmt.Errorf("%w: dedup window %v", ErrInvalidTTL, window)
+	}
+	nx, ok := cache.(SetNXCache)
+	if !ok {
+		return false, ErrNotSupported
+	}
+	// 值为第一次出现的时间（Unix毫秒），便于排查
+	return nx.SetNX(ctx, key, time.Now().UnixMilli(), window)
+}
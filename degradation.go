@@ -0,0 +1,231 @@
+package go_cache
+
+import (
+	"sync"
+	"time"
+)
+
+// DegradationReason 降级的原因
+type DegradationReason string
+
+const (
+	// DegradationShedRead 负载保护期间读操作被跳过
+	DegradationShedRead DegradationReason = "shed_read"
+	// DegradationShedWrite 负载保护期间写操作被丢弃
+	DegradationShedWrite DegradationReason = "shed_write"
+	// DegradationCircuitOpen 熔断器打开，请求没有访问后端，供调用方的熔断器记录
+	DegradationCircuitOpen DegradationReason = "circuit_open"
+	// DegradationFallback 主后端不可用，请求由备用后端处理，供调用方的故障转移记录
+	DegradationFallback DegradationReason = "fallback"
+)
+
+// DegradationReport 一个时间窗口内的降级统计
+type DegradationReport struct {
+	Window   time.Duration
+	Requests uint64                       // 窗口内的请求数，包括降级的请求
+	Degraded uint64                       // 窗口内降级的请求数
+	Reasons  map[DegradationReason]uint64 // 按原因统计的降级请求数
+}
+
+// Ratio 返回降级请求的比例，没有请求时返回0
+func (r DegradationReport) Ratio() float64 {
+	if r.Requests == 0 {
+		return 0
+	}
+	return float64(r.Degraded) / float64(r.Requests)
+}
+
+// DegradationSLO 降级的SLO：Window 内降级请求的比例不超过 MaxRatio
+type DegradationSLO struct {
+	Window      time.Duration
+	MaxRatio    float64
+	MinRequests uint64 // 窗口内的请求数少于该值时不判断，避免流量很小时误报
+}
+
+// SLOHook SLO状态变化时的回调，breached 为true时表示超过阈值，false 时表示已恢复
+type SLOHook func(report DegradationReport, breached bool)
+
+// DegradationOption 降级统计选项
+type DegradationOption func(*DegradationTracker)
+
+// WithDegradationResolution 设置统计的时间粒度，默认10秒
+func WithDegradationResolution(d time.Duration) DegradationOption {
+	return func(t *DegradationTracker) {
+		if d > 0 {
+			t.resolution = d
+		}
+	}
+}
+
+// WithDegradationRetention 设置保留统计的时长，即 Report 可查询的最大窗口，默认1小时
+func WithDegradationRetention(d time.Duration) DegradationOption {
+	return func(t *DegradationTracker) {
+		if d > 0 {
+			t.retention = d
+		}
+	}
+}
+
+// WithDegradationClock 设置统计所用的时钟，默认使用系统时间
+func WithDegradationClock(clock Clock) DegradationOption {
+	return func(t *DegradationTracker) {
+		if clock != nil {
+			t.clock = clock
+		}
+	}
+}
+
+// WithDegradationSLO 设置降级的SLO，每个时间粒度最多判断一次，
+// 状态在达标与超标之间变化时调用hook
+func WithDegradationSLO(slo DegradationSLO, hook SLOHook) DegradationOption {
+	return func(t *DegradationTracker) {
+		t.slo = &slo
+		t.onSLO = hook
+	}
+}
+
+// degradationBucket 一个时间粒度内的统计
+type degradationBucket struct {
+	index    int64 // 时间粒度的序号，用于判断槽位中的统计是否已过期
+	requests uint64
+	reasons  map[DegradationReason]uint64
+}
+
+// DegradationTracker 按滚动时间窗口统计缓存降级（负载保护、熔断、备用后端）的次数，
+// 用于在降级比例超过SLO时告警。
+// 各组件通过选项（如 WithLoadShedDegradation）接入，也可以直接调用 RecordRequest 与 RecordDegraded。
+// 方法可以在nil上调用，此时不做任何统计
+type DegradationTracker struct {
+	resolution time.Duration
+	retention  time.Duration
+	clock      Clock
+	slo        *DegradationSLO
+	onSLO      SLOHook
+
+	mu        sync.Mutex
+	buckets   []degradationBucket
+	evaluated int64 // 最近一次判断SLO的时间粒度序号
+	breached  bool
+}
+
+// NewDegradationTracker 创建降级统计
+func NewDegradationTracker(opts ...DegradationOption) *DegradationTracker {
+	t := &DegradationTracker{
+		resolution: 10 * time.Second,
+		retention:  time.Hour,
+		clock:      realClock{},
+		evaluated:  -1,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	n := int((t.retention + t.resolution - 1) / t.resolution)
+	t.buckets = make([]degradationBucket, max(n, 1))
+	for i := range t.buckets {
+		t.buckets[i].index = -1
+	}
+	return t
+}
+
+// RecordRequest 记录一次正常处理的请求
+func (t *DegradationTracker) RecordRequest() {
+	t.record("")
+}
+
+// RecordDegraded 记录一次因reason降级的请求，同时计入请求数
+func (t *DegradationTracker) RecordDegraded(reason DegradationReason) {
+	t.record(reason)
+}
+
+// Report 返回最近window内的统计，window 超过保留时长时按保留时长计算
+func (t *DegradationTracker) Report(window time.Duration) DegradationReport {
+	if t == nil {
+		return DegradationReport{Window: window, Reasons: map[DegradationReason]uint64{}}
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.reportLocked(t.indexOf(t.clock.Now()), window)
+}
+
+// SLOBreached 返回最近一次判断时是否超过SLO，没有设置SLO时返回false
+func (t *DegradationTracker) SLOBreached() bool {
+	if t == nil {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.breached
+}
+
+// record 记录一次请求，reason 为空时表示没有降级
+func (t *DegradationTracker) record(reason DegradationReason) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	index := t.indexOf(t.clock.Now())
+	b := &t.buckets[index%int64(len(t.buckets))]
+	if b.index != index {
+		*b = degradationBucket{index: index}
+	}
+	b.requests++
+	if reason != "" {
+		if b.reasons == nil {
+			b.reasons = make(map[DegradationReason]uint64)
+		}
+		b.reasons[reason]++
+	}
+
+	report, changed := t.evaluateLocked(index)
+	breached := t.breached
+	t.mu.Unlock()
+
+	if changed && t.onSLO != nil {
+		t.onSLO(report, breached)
+	}
+}
+
+// evaluateLocked 每个时间粒度最多判断一次SLO，返回状态是否变化
+func (t *DegradationTracker) evaluateLocked(index int64) (DegradationReport, bool) {
+	if t.slo == nil || index == t.evaluated {
+		return DegradationReport{}, false
+	}
+	t.evaluated = index
+
+	report := t.reportLocked(index, t.slo.Window)
+	breached := t.breached
+	if report.Requests >= t.slo.MinRequests {
+		breached = report.Ratio() > t.slo.MaxRatio
+	}
+	if breached == t.breached {
+		return report, false
+	}
+	t.breached = breached
+	return report, true
+}
+
+// reportLocked 汇总序号不早于 index-窗口粒度数+1 的统计
+func (t *DegradationTracker) reportLocked(index int64, window time.Duration) DegradationReport {
+	window = min(window, t.retention)
+	n := int64((window + t.resolution - 1) / t.resolution)
+	report := DegradationReport{Window: window, Reasons: make(map[DegradationReason]uint64)}
+	for _, b := range t.buckets {
+		if b.index < 0 || b.index > index || b.index <= index-n {
+			continue
+		}
+		report.Requests += b.requests
+		for reason, count := range b.reasons {
+			report.Degraded += count
+			report.Reasons[reason] += count
+		}
+	}
+	return report
+}
+
+// indexOf 返回时间所在的时间粒度序号
+func (t *DegradationTracker) indexOf(now time.Time) int64 {
+	return now.UnixNano() / int64(t.resolution)
+}
@@ -0,0 +1,243 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultDependencyKeyPrefix 依赖索引在缓存中的默认键前缀
+const DefaultDependencyKeyPrefix = "deps:"
+
+// dependencyBackend 由能够在服务端保存依赖索引的缓存实现（如Redis使用集合）
+type dependencyBackend interface {
+	addDependents(ctx context.Context, index, child string, ttl time.Duration) error
+	// popDependents 返回每个索引中的键并删除这些索引
+	popDependents(ctx context.Context, indexes []string) ([][]string, error)
+}
+
+// DependencyOption 依赖关系选项
+type DependencyOption func(*DependencyGraph)
+
+// WithDependencyKeyPrefix 设置依赖索引在缓存中的键前缀，默认 DefaultDependencyKeyPrefix
+func WithDependencyKeyPrefix(prefix string) DependencyOption {
+	return func(g *DependencyGraph) {
+		g.prefix = prefix
+	}
+}
+
+// WithDependencyIndexTTL 设置Redis中依赖索引的有效期，默认24小时，每次 DependsOn 时刷新
+// 依赖的键在该时间内没有被声明依赖也没有失效时索引过期，避免索引无限增长
+func WithDependencyIndexTTL(d time.Duration) DependencyOption {
+	return func(g *DependencyGraph) {
+		if d > 0 {
+			g.indexTTL = d
+		}
+	}
+}
+
+// DependencyGraph 维护键之间依赖关系的缓存包装
+// 通过 DependsOn 声明子键依赖父键后，经由本包装写入或删除父键时，
+// 依赖它的子键（以及子键的子键）会被删除，适合缓存由其他缓存条目派生的数据。
+// Redis缓存的依赖关系保存为集合索引，多个实例共享；其他缓存保存在本实例内存中的有向图里。
+// 失效的父键与子键的依赖索引随之删除，重新生成的子键需要再次调用 DependsOn；
+// 子键对其他未变化的父键的依赖仍然保留。
+// 父键自然过期不会使子键失效，直到父键经由 GetSet 重新加载或被写入
+type DependencyGraph struct {
+	cache    gsr.Cacher
+	prefix   string
+	indexTTL time.Duration
+
+	mu         sync.Mutex
+	dependents map[string]map[string]struct{} // 父键到直接依赖它的子键，缓存不支持依赖索引时使用
+	watchers   map[string]func(ctx context.Context)
+}
+
+// NewDependencyGraph 创建维护键依赖关系的缓存包装
+func NewDependencyGraph(cache gsr.Cacher, opts ...DependencyOption) *DependencyGraph {
+	g := &DependencyGraph{
+		cache:      cache,
+		prefix:     DefaultDependencyKeyPrefix,
+		indexTTL:   24 * time.Hour,
+		dependents: make(map[string]map[string]struct{}),
+		watchers:   make(map[string]func(ctx context.Context)),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	return g
+}
+
+// DependsOn 声明child依赖parents，任一父键失效时child随之失效
+func (g *DependencyGraph) DependsOn(ctx context.Context, child string, parents ...string) error {
+	if b, ok := g.cache.(dependencyBackend); ok {
+		for _, parent := range parents {
+			if err := b.addDependents(ctx, g.prefix+parent, child, g.indexTTL); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, parent := range parents {
+		children, ok := g.dependents[parent]
+		if !ok {
+			children = make(map[string]struct{})
+			g.dependents[parent] = children
+		}
+		children[child] = struct{}{}
+	}
+	return nil
+}
+
+// Invalidate 删除所有直接或间接依赖key的键，不删除key本身，返回删除的键
+// 依赖关系中存在环时每个键只处理一次
+func (g *DependencyGraph) Invalidate(ctx context.Context, key string) ([]string, error) {
+	visited := map[string]bool{key: true}
+	var invalidated []string
+	level := []string{key}
+	for len(level) > 0 {
+		children, err := g.popDependents(ctx, level)
+		if err != nil {
+			return invalidated, err
+		}
+
+		level = level[:0:0]
+		for _, child := range children {
+			if !visited[child] {
+				visited[child] = true
+				level = append(level, child)
+			}
+		}
+		if err := g.delete(ctx, level); err != nil {
+			return invalidated, err
+		}
+		invalidated = append(invalidated, level...)
+	}
+	g.notify(ctx, invalidated)
+	return invalidated, nil
+}
+
+// watch 注册key被 Invalidate 删除后的回调，用于物化视图的立即重新计算
+func (g *DependencyGraph) watch(key string, fn func(ctx context.Context)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.watchers[key] = fn
+}
+
+// notify 调用被删除的键的回调
+func (g *DependencyGraph) notify(ctx context.Context, keys []string) {
+	g.mu.Lock()
+	fns := make([]func(ctx context.Context), 0, len(keys))
+	for _, key := range keys {
+		if fn, ok := g.watchers[key]; ok {
+			fns = append(fns, fn)
+		}
+	}
+	g.mu.Unlock()
+
+	for _, fn := range fns {
+		fn(ctx)
+	}
+}
+
+// popDependents 返回直接依赖keys的子键并删除这些依赖关系
+func (g *DependencyGraph) popDependents(ctx context.Context, keys []string) ([]string, error) {
+	if b, ok := g.cache.(dependencyBackend); ok {
+		indexes := make([]string, len(keys))
+		for i, key := range keys {
+			indexes[i] = g.prefix + key
+		}
+		groups, err := b.popDependents(ctx, indexes)
+		if err != nil {
+			return nil, err
+		}
+		var children []string
+		for _, group := range groups {
+			children = append(children, group...)
+		}
+		return children, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var children []string
+	for _, key := range keys {
+		for child := range g.dependents[key] {
+			children = append(children, child)
+		}
+		delete(g.dependents, key)
+	}
+	return children, nil
+}
+
+// delete 删除失效的键，缓存实现 BatchCache 时一次删除
+func (g *DependencyGraph) delete(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	if b, ok := g.cache.(BatchCache); ok {
+		return b.DelMulti(ctx, keys...)
+	}
+	for _, key := range keys {
+		if err := g.cache.Del(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (g *DependencyGraph) Exists(ctx context.Context, key string) bool {
+	return g.cache.Exists(ctx, key)
+}
+
+func (g *DependencyGraph) Get(ctx context.Context, key string, obj any) error {
+	return g.cache.Get(ctx, key, obj)
+}
+
+// Set 写入key并使依赖它的键失效
+func (g *DependencyGraph) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := g.cache.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	_, err := g.Invalidate(ctx, key)
+	return err
+}
+
+// GetSet 未命中时调用回调函数加载并写入，加载后使依赖key的键失效
+func (g *DependencyGraph) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	var loaded bool
+	err := g.cache.GetSet(ctx, key, ttl, obj, func(key string, obj any) error {
+		loaded = true
+		return fun(key, obj)
+	})
+	if err != nil || !loaded {
+		return err
+	}
+	_, err = g.Invalidate(ctx, key)
+	return err
+}
+
+// Del 删除key并使依赖它的键失效
+func (g *DependencyGraph) Del(ctx context.Context, key string) error {
+	if err := g.cache.Del(ctx, key); err != nil {
+		return err
+	}
+	_, err := g.Invalidate(ctx, key)
+	return err
+}
+
+func (g *DependencyGraph) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return g.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (g *DependencyGraph) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return g.cache.ExpiresIn(ctx, key, ttl)
+}
@@ -0,0 +1,51 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+)
+
+// ErrCacheBypassed 读操作被 WithNoCache 跳过，按未命中处理，满足 errors.Is(err, ErrKeyNotFound)
+var ErrCacheBypassed = fmt.Errorf("%w: bypassed by context", ErrKeyNotFound)
+
+// 缓存控制指令的context键
+type (
+	noCacheKey      struct{}
+	noStoreKey      struct{}
+	forceRefreshKey struct{}
+)
+
+// WithNoCache 标记context中的请求不读取缓存：Get 返回 ErrCacheBypassed，GetSet 直接调用回调函数
+// 回调函数的结果仍会写入缓存，需要同时跳过写入时再使用 WithNoStore
+func WithNoCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// WithNoStore 标记context中的请求不写入缓存：Set 与 GetSet 的回填被跳过，返回nil
+func WithNoStore(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noStoreKey{}, true)
+}
+
+// WithForceRefresh 标记context中的请求强制刷新：GetSet 不读取缓存，调用回调函数并写入结果，
+// 用于管理后台的手动刷新等场景；Get 不受影响
+func WithForceRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, forceRefreshKey{}, true)
+}
+
+// noCache 判断context是否要求跳过读取
+func noCache(ctx context.Context) bool {
+	v, _ := ctx.Value(noCacheKey{}).(bool)
+	return v
+}
+
+// noStore 判断context是否要求跳过写入
+func noStore(ctx context.Context) bool {
+	v, _ := ctx.Value(noStoreKey{}).(bool)
+	return v
+}
+
+// forceRefresh 判断 GetSet 是否需要跳过读取直接调用回调函数
+func forceRefresh(ctx context.Context) bool {
+	v, _ := ctx.Value(forceRefreshKey{}).(bool)
+	return v || noCache(ctx)
+}
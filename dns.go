@@ -0,0 +1,349 @@
+package go_cache
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DNS 资源记录类型
+const (
+	dnsTypeA     = 1
+	dnsTypeCNAME = 5
+	dnsTypeSOA   = 6
+	dnsTypeAAAA  = 28
+)
+
+// dnsEntry 缓存的解析结果，NotFound 为true表示域名不存在（NXDOMAIN）
+type dnsEntry struct {
+	Addrs    []string
+	NotFound bool
+}
+
+// DNSResolverOption DNS解析缓存选项
+type DNSResolverOption func(*DNSResolver)
+
+// WithDNSDial 设置连接DNS服务器的函数，可用于指定服务器，默认使用 net.Dialer
+func WithDNSDial(dial func(ctx context.Context, network, address string) (net.Conn, error)) DNSResolverOption {
+	return func(r *DNSResolver) {
+		r.dial = dial
+	}
+}
+
+// WithDNSMinTTL 设置缓存有效期的下限，默认1秒，避免TTL为0的记录使每次解析都请求服务器
+func WithDNSMinTTL(d time.Duration) DNSResolverOption {
+	return func(r *DNSResolver) {
+		r.minTTL = d
+	}
+}
+
+// WithDNSMaxTTL 设置缓存有效期的上限，默认1小时
+func WithDNSMaxTTL(d time.Duration) DNSResolverOption {
+	return func(r *DNSResolver) {
+		if d > 0 {
+			r.maxTTL = d
+		}
+	}
+}
+
+// WithDNSNegativeTTL 设置域名不存在的结果的缓存有效期上限，默认30秒；
+// 响应中有SOA记录时使用其给出的否定缓存时间（RFC 2308），0表示不缓存不存在的结果
+func WithDNSNegativeTTL(d time.Duration) DNSResolverOption {
+	return func(r *DNSResolver) {
+		if d >= 0 {
+			r.negativeTTL = d
+		}
+	}
+}
+
+// WithDNSPrefix 设置缓存键的前缀，默认 "dns:"
+func WithDNSPrefix(prefix string) DNSResolverOption {
+	return func(r *DNSResolver) {
+		r.prefix = prefix
+	}
+}
+
+// DNSResolver 把解析结果保存在缓存中的DNS解析器，用于大量解析相同域名的服务
+// 解析使用Go内置的解析器（读取 /etc/resolv.conf），从DNS服务器的响应中读取记录的TTL作为缓存有效期；
+// 域名不存在（NXDOMAIN）的结果按SOA记录的否定缓存时间缓存。
+// 只缓存经由DNS服务器得到的结果，hosts 文件中的域名与IP地址字面量每次直接解析
+type DNSResolver struct {
+	cache       gsr.Cacher
+	resolver    *net.Resolver
+	dial        func(ctx context.Context, network, address string) (net.Conn, error)
+	minTTL      time.Duration
+	maxTTL      time.Duration
+	negativeTTL time.Duration
+	prefix      string
+}
+
+// NewDNSResolver 创建使用cache保存解析结果的解析器
+func NewDNSResolver(cache gsr.Cacher, opts ...DNSResolverOption) *DNSResolver {
+	var dialer net.Dialer
+	r := &DNSResolver{
+		cache:       cache,
+		dial:        dialer.DialContext,
+		minTTL:      time.Second,
+		maxTTL:      time.Hour,
+		negativeTTL: 30 * time.Second,
+		prefix:      "dns:",
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.resolver = &net.Resolver{PreferGo: true, Dial: r.dialObserved}
+	return r
+}
+
+// LookupHost 返回域名的IP地址
+func (r *DNSResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	addrs, err := r.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+	hosts := make([]string, len(addrs))
+	for i, addr := range addrs {
+		hosts[i] = addr.String()
+	}
+	return hosts, nil
+}
+
+// LookupIPAddr 返回域名的IP地址，缓存命中时不请求DNS服务器
+// 域名不存在时返回 IsNotFound 为true的 *net.DNSError
+func (r *DNSResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return []net.IPAddr{{IP: ip}}, nil
+	}
+
+	key := r.prefix + host
+	var entry dnsEntry
+	if err := r.cache.Get(ctx, key, &entry); err == nil {
+		if entry.NotFound {
+			return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+		}
+		addrs := make([]net.IPAddr, 0, len(entry.Addrs))
+		for _, s := range entry.Addrs {
+			if addr, err := net.ResolveIPAddr("ip", s); err == nil {
+				addrs = append(addrs, *addr)
+			}
+		}
+		return addrs, nil
+	}
+
+	obs := &dnsObservation{}
+	addrs, err := r.resolver.LookupIPAddr(context.WithValue(ctx, dnsObservationKey{}, obs), host)
+	if err != nil {
+		if dnsErr, ok := err.(*net.DNSError); ok && dnsErr.IsNotFound {
+			if ttl, ok := obs.negative(); ok && r.negativeTTL > 0 {
+				_ = r.cache.Set(ctx, key, dnsEntry{NotFound: true}, r.clampTTL(min(ttl, r.negativeTTL)))
+			}
+		}
+		return nil, err
+	}
+	if ttl, ok := obs.answer(); ok {
+		entry := dnsEntry{Addrs: make([]string, len(addrs))}
+		for i, addr := range addrs {
+			entry.Addrs[i] = addr.String()
+		}
+		_ = r.cache.Set(ctx, key, entry, r.clampTTL(ttl))
+	}
+	return addrs, nil
+}
+
+// clampTTL 把有效期限制在 [minTTL, maxTTL] 内
+func (r *DNSResolver) clampTTL(ttl time.Duration) time.Duration {
+	return min(max(ttl, r.minTTL), r.maxTTL)
+}
+
+// Forget 删除域名的缓存结果
+func (r *DNSResolver) Forget(ctx context.Context, host string) error {
+	return r.cache.Del(ctx, r.prefix+host)
+}
+
+// dnsObservationKey 解析过程中记录服务器响应的context键
+type dnsObservationKey struct{}
+
+// dnsObservation 一次解析中服务器响应里的TTL
+type dnsObservation struct {
+	mu          sync.Mutex
+	answerTTL   time.Duration
+	hasAnswer   bool
+	negativeTTL time.Duration
+	hasNegative bool
+}
+
+// observe 记录一个DNS响应
+func (o *dnsObservation) observe(msg []byte) {
+	answerTTL, hasAnswer, negativeTTL, hasNegative := parseDNSTTL(msg)
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if hasAnswer && (!o.hasAnswer || answerTTL < o.answerTTL) {
+		o.answerTTL, o.hasAnswer = answerTTL, true
+	}
+	if hasNegative && (!o.hasNegative || negativeTTL < o.negativeTTL) {
+		o.negativeTTL, o.hasNegative = negativeTTL, true
+	}
+}
+
+// answer 返回应答记录的最小TTL
+func (o *dnsObservation) answer() (time.Duration, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.answerTTL, o.hasAnswer
+}
+
+// negative 返回否定应答的缓存时间
+func (o *dnsObservation) negative() (time.Duration, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.negativeTTL, o.hasNegative
+}
+
+// dialObserved 连接DNS服务器，解析的context中有 dnsObservation 时记录连接上读到的响应
+func (r *DNSResolver) dialObserved(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := r.dial(ctx, network, address)
+	if err != nil {
+		return nil, err
+	}
+	obs, _ := ctx.Value(dnsObservationKey{}).(*dnsObservation)
+	if obs == nil {
+		return conn, nil
+	}
+	// Go的解析器按连接是否实现 net.PacketConn 区分UDP与TCP的报文格式
+	if pc, ok := conn.(net.PacketConn); ok {
+		return &observedPacketConn{Conn: conn, pc: pc, obs: obs}, nil
+	}
+	return &observedStreamConn{Conn: conn, obs: obs}, nil
+}
+
+// observedPacketConn 记录UDP响应的连接，每次读取得到一个完整的报文
+type observedPacketConn struct {
+	net.Conn
+	pc  net.PacketConn
+	obs *dnsObservation
+}
+
+func (c *observedPacketConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.obs.observe(b[:n])
+	}
+	return n, err
+}
+
+func (c *observedPacketConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	n, addr, err := c.pc.ReadFrom(b)
+	if n > 0 {
+		c.obs.observe(b[:n])
+	}
+	return n, addr, err
+}
+
+func (c *observedPacketConn) WriteTo(b []byte, addr net.Addr) (int, error) {
+	return c.pc.WriteTo(b, addr)
+}
+
+// observedStreamConn 记录TCP响应的连接，报文前有2字节的长度
+type observedStreamConn struct {
+	net.Conn
+	obs *dnsObservation
+	buf []byte
+}
+
+func (c *observedStreamConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.buf = append(c.buf, b[:n]...)
+	for len(c.buf) >= 2 {
+		l := int(binary.BigEndian.Uint16(c.buf))
+		if len(c.buf) < 2+l {
+			break
+		}
+		c.obs.observe(c.buf[2 : 2+l])
+		c.buf = c.buf[2+l:]
+	}
+	return n, err
+}
+
+// skipDNSName 跳过报文中off处的域名，返回域名之后的位置
+func skipDNSName(msg []byte, off int) (int, bool) {
+	for off < len(msg) {
+		l := int(msg[off])
+		switch {
+		case l == 0:
+			return off + 1, true
+		case l&0xC0 == 0xC0:
+			// 压缩指针
+			return off + 2, off+2 <= len(msg)
+		default:
+			off += 1 + l
+		}
+	}
+	return 0, false
+}
+
+// parseDNSTTL 返回DNS响应中地址与别名记录的最小TTL，以及权威部分SOA记录给出的否定缓存时间
+// 无法解析的报文不返回结果
+func parseDNSTTL(msg []byte) (answerTTL time.Duration, hasAnswer bool, negativeTTL time.Duration, hasNegative bool) {
+	if len(msg) < 12 {
+		return
+	}
+	qdCount := int(binary.BigEndian.Uint16(msg[4:]))
+	anCount := int(binary.BigEndian.Uint16(msg[6:]))
+	nsCount := int(binary.BigEndian.Uint16(msg[8:]))
+
+	off := 12
+	for i := 0; i < qdCount; i++ {
+		var ok bool
+		if off, ok = skipDNSName(msg, off); !ok || off+4 > len(msg) {
+			return
+		}
+		off += 4
+	}
+
+	for i := 0; i < anCount+nsCount; i++ {
+		var ok bool
+		if off, ok = skipDNSName(msg, off); !ok || off+10 > len(msg) {
+			return
+		}
+		typ := binary.BigEndian.Uint16(msg[off:])
+		ttl := time.Duration(binary.BigEndian.Uint32(msg[off+4:])) * time.Second
+		rdata := off + 10
+		off = rdata + int(binary.BigEndian.Uint16(msg[off+8:]))
+		if off > len(msg) {
+			return
+		}
+
+		if i < anCount {
+			if typ == dnsTypeA || typ == dnsTypeAAAA || typ == dnsTypeCNAME {
+				if !hasAnswer || ttl < answerTTL {
+					answerTTL, hasAnswer = ttl, true
+				}
+			}
+			continue
+		}
+		if typ != dnsTypeSOA {
+			continue
+		}
+		// SOA的数据：主服务器与管理员邮箱两个域名，之后是5个32位整数，最后一个为否定缓存时间
+		p, ok := skipDNSName(msg, rdata)
+		if !ok {
+			continue
+		}
+		if p, ok = skipDNSName(msg, p); !ok || p+20 > off {
+			continue
+		}
+		minimum := time.Duration(binary.BigEndian.Uint32(msg[p+16:])) * time.Second
+		negative := min(ttl, minimum)
+		if !hasNegative || negative < negativeTTL {
+			negativeTTL, hasNegative = negative, true
+		}
+	}
+	return
+}
@@ -0,0 +1,365 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// DynamoDB表默认的属性名
+const (
+	DefaultDynamoDBKeyAttribute   = "key"
+	DefaultDynamoDBValueAttribute = "value"
+	DefaultDynamoDBTTLAttribute   = "expires_at"
+)
+
+// dynamoDBExpiresMsAttr 毫秒精度的过期时间
+// DynamoDB的TTL属性只支持秒，且过期删除可能延迟数十小时，读取时以该属性判断是否过期
+const dynamoDBExpiresMsAttr = "expires_at_ms"
+
+// DynamoDBAPI DynamoDB缓存使用的客户端方法，*dynamodb.Client 满足该接口
+type DynamoDBAPI interface {
+	GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error)
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+	UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error)
+}
+
+// DynamoDB 基于DynamoDB的缓存
+// 表的分区键为字符串类型的键属性，值以二进制属性保存，
+// 过期时间写入TTL属性（Unix秒），需要在表上对该属性开启TTL以便DynamoDB自动删除过期条目
+type DynamoDB struct {
+	client         DynamoDBAPI
+	table          string
+	keyAttr        string
+	valueAttr      string
+	ttlAttr        string
+	consistentRead bool
+	serializer     serializer.Serializer
+	stats          *StatsCollector
+	clock          Clock
+	loader         loaderConfig
+}
+
+// DynamoDBOption DynamoDB缓存选项
+type DynamoDBOption func(*DynamoDB)
+
+// WithDynamoDBSerializer 设置DynamoDB缓存的序列化器
+func WithDynamoDBSerializer(s serializer.Serializer) DynamoDBOption {
+	return func(d *DynamoDB) {
+		d.serializer = s
+	}
+}
+
+// WithDynamoDBStats 设置DynamoDB缓存的统计收集器
+// 可用于多个缓存实例共享同一个收集器
+func WithDynamoDBStats(s *StatsCollector) DynamoDBOption {
+	return func(d *DynamoDB) {
+		d.stats = s
+	}
+}
+
+// WithDynamoDBClock 设置判断过期所用的时钟，默认使用系统时间
+func WithDynamoDBClock(clock Clock) DynamoDBOption {
+	return func(d *DynamoDB) {
+		if clock != nil {
+			d.clock = clock
+		}
+	}
+}
+
+// WithDynamoDBAttributes 设置键、值与TTL的属性名，为空的参数保持默认值
+func WithDynamoDBAttributes(key, value, ttl string) DynamoDBOption {
+	return func(d *DynamoDB) {
+		if key != "" {
+			d.keyAttr = key
+		}
+		if value != "" {
+			d.valueAttr = value
+		}
+		if ttl != "" {
+			d.ttlAttr = ttl
+		}
+	}
+}
+
+// WithDynamoDBConsistentRead 设置读取时是否使用强一致性读，默认为最终一致性读
+func WithDynamoDBConsistentRead(consistent bool) DynamoDBOption {
+	return func(d *DynamoDB) {
+		d.consistentRead = consistent
+	}
+}
+
+// WithDynamoDBLoaderTimeout 设置 GetSet 等待回调函数的超时时间，超时返回 ErrLoaderTimeout，timeout <= 0 表示不限制
+func WithDynamoDBLoaderTimeout(timeout time.Duration) DynamoDBOption {
+	return func(d *DynamoDB) {
+		d.loader.timeout = timeout
+	}
+}
+
+// WithDynamoDBLoaderHook 设置 GetSet 回调函数失败（返回错误、panic或超时）时的回调
+func WithDynamoDBLoaderHook(hook LoaderFailureHook) DynamoDBOption {
+	return func(d *DynamoDB) {
+		d.loader.onFailure = hook
+	}
+}
+
+// NewDynamoDB 创建DynamoDB缓存实例
+// 默认使用gob序列化器
+func NewDynamoDB(client DynamoDBAPI, table string, opts ...DynamoDBOption) *DynamoDB {
+	d := &DynamoDB{
+		client:     client,
+		table:      table,
+		keyAttr:    DefaultDynamoDBKeyAttribute,
+		valueAttr:  DefaultDynamoDBValueAttribute,
+		ttlAttr:    DefaultDynamoDBTTLAttribute,
+		serializer: cache_value.GetDefaultSerializer(), // 默认使用gob
+		stats:      NewStatsCollector(),
+		clock:      realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(d)
+	}
+
+	return d
+}
+
+// Stats 返回缓存统计快照
+func (c *DynamoDB) Stats() Stats {
+	return c.stats.Snapshot()
+}
+
+func (c *DynamoDB) Exists(ctx context.Context, key string) bool {
+	item, err := c.getItem(ctx, key, true)
+	return err == nil && item != nil
+}
+
+func (c *DynamoDB) Get(ctx context.Context, key string, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	item, err := c.getItem(ctx, key, false)
+	if err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	if item == nil {
+		c.stats.RecordMiss(key)
+		return ErrKeyNotFound
+	}
+
+	value, ok := item[c.valueAttr].(*types.AttributeValueMemberB)
+	if !ok {
+		c.stats.RecordError(key)
+		return serializationError(errors.New("dynamodb: value attribute is not binary"))
+	}
+	if err := c.serializer.Decode(value.Value, obj); err != nil {
+		c.stats.RecordError(key)
+		return serializationError(err)
+	}
+
+	c.stats.RecordHit(key, len(value.Value))
+	return nil
+}
+
+func (c *DynamoDB) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	encode, rawSize, err := encodeSized(c.serializer, value)
+	if err != nil {
+		return err
+	}
+
+	item := map[string]types.AttributeValue{
+		c.keyAttr:   &types.AttributeValueMemberS{Value: key},
+		c.valueAttr: &types.AttributeValueMemberB{Value: encode},
+	}
+	if ttl > 0 {
+		item[c.ttlAttr], item[dynamoDBExpiresMsAttr] = expiryAttrs(c.clock.Now().Add(ttl))
+	}
+
+	_, err = c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(c.table),
+		Item:      item,
+	})
+	if err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordSet(key, len(encode))
+	c.stats.RecordPayload(key, len(encode), rawSize)
+	return nil
+}
+
+// SetWithPriority 按优先级写入缓存
+// DynamoDB没有容量淘汰，优先级不会产生影响
+func (c *DynamoDB) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	return c.Set(ctx, key, value, ttl)
+}
+
+// SetNX 使用条件写入在键不存在或已过期时写入，返回是否写入
+func (c *DynamoDB) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	encode, rawSize, err := encodeSized(c.serializer, value)
+	if err != nil {
+		return false, err
+	}
+
+	item := map[string]types.AttributeValue{
+		c.keyAttr:   &types.AttributeValueMemberS{Value: key},
+		c.valueAttr: &types.AttributeValueMemberB{Value: encode},
+	}
+	if ttl > 0 {
+		item[c.ttlAttr], item[dynamoDBExpiresMsAttr] = expiryAttrs(c.clock.Now().Add(ttl))
+	}
+
+	_, err = c.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(c.table),
+		Item:      item,
+		// 已过期但尚未被DynamoDB删除的条目视为不存在
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR #ms <= :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#key": c.keyAttr,
+			"#ms":  dynamoDBExpiresMsAttr,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":now": numberAttr(c.clock.Now().UnixMilli()),
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	c.stats.RecordSet(key, len(encode))
+	c.stats.RecordPayload(key, len(encode), rawSize)
+	return true, nil
+}
+
+func (c *DynamoDB) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	return c.loader.getSet(ctx, c, key, ttl, obj, fun)
+}
+
+// loaderSettings 返回 GetSet 调用回调函数的方式，包装器沿用该设置
+func (c *DynamoDB) loaderSettings() loaderConfig {
+	return c.loader
+}
+
+func (c *DynamoDB) Del(ctx context.Context, key string) error {
+	_, err := c.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(c.table),
+		Key:       c.itemKey(key),
+	})
+	if err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordDelete(key)
+	return nil
+}
+
+// ExpiresAt 设置过期时间，键不存在或已过期时返回 ErrKeyNotFound
+func (c *DynamoDB) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	ttl, ms := expiryAttrs(expiresAt)
+	_, err := c.client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String(c.table),
+		Key:              c.itemKey(key),
+		UpdateExpression: aws.String("SET #ttl = :ttl, #ms = :ms"),
+		// 已过期但尚未被DynamoDB删除的条目视为不存在
+		ConditionExpression: aws.String("attribute_exists(#key) AND (attribute_not_exists(#ms) OR #ms > :now)"),
+		ExpressionAttributeNames: map[string]string{
+			"#key": c.keyAttr,
+			"#ttl": c.ttlAttr,
+			"#ms":  dynamoDBExpiresMsAttr,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":ttl": ttl,
+			":ms":  ms,
+			":now": numberAttr(c.clock.Now().UnixMilli()),
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrKeyNotFound
+	}
+	return classifyError(err)
+}
+
+// ExpiresIn 设置剩余有效期，键不存在或已过期时返回 ErrKeyNotFound
+func (c *DynamoDB) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return c.ExpiresAt(ctx, key, c.clock.Now().Add(ttl))
+}
+
+// getItem 读取未过期的条目，条目不存在或已过期时返回nil
+// keysOnly 为true时只读取键与过期时间
+func (c *DynamoDB) getItem(ctx context.Context, key string, keysOnly bool) (map[string]types.AttributeValue, error) {
+	input := &dynamodb.GetItemInput{
+		TableName:      aws.String(c.table),
+		Key:            c.itemKey(key),
+		ConsistentRead: aws.Bool(c.consistentRead),
+	}
+	if keysOnly {
+		input.ProjectionExpression = aws.String("#key, #ms")
+		input.ExpressionAttributeNames = map[string]string{
+			"#key": c.keyAttr,
+			"#ms":  dynamoDBExpiresMsAttr,
+		}
+	}
+
+	out, err := c.client.GetItem(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	if len(out.Item) == 0 || c.expired(out.Item) {
+		return nil, nil
+	}
+	return out.Item, nil
+}
+
+// expired 判断条目是否已过期
+func (c *DynamoDB) expired(item map[string]types.AttributeValue) bool {
+	attr, ok := item[dynamoDBExpiresMsAttr].(*types.AttributeValueMemberN)
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(attr.Value, 10, 64)
+	if err != nil {
+		return false
+	}
+	return c.clock.Now().UnixMilli() >= expiresAt
+}
+
+// expiryAttrs 返回TTL属性（秒）与毫秒精度的过期时间属性
+// TTL属性向上取整到秒，保证DynamoDB不会早于过期时间删除条目
+func expiryAttrs(expiresAt time.Time) (ttl, ms types.AttributeValue) {
+	seconds := expiresAt.Unix()
+	if expiresAt.Nanosecond() > 0 {
+		seconds++
+	}
+	return numberAttr(seconds), numberAttr(expiresAt.UnixMilli())
+}
+
+// itemKey 返回条目的主键
+func (c *DynamoDB) itemKey(key string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		c.keyAttr: &types.AttributeValueMemberS{Value: key},
+	}
+}
+
+// numberAttr 返回数字类型的属性值
+func numberAttr(n int64) *types.AttributeValueMemberN {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(n, 10)}
+}
@@ -0,0 +1,372 @@
+package go_cache
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+	bolt "go.etcd.io/bbolt"
+)
+
+// DefaultEmbeddedBucket 默认的bucket名称
+const DefaultEmbeddedBucket = "go_cache"
+
+// embeddedHeaderSize 条目头部长度，保存过期时间（Unix毫秒，0表示不过期）
+const embeddedHeaderSize = 8
+
+// Embedded 基于单个bbolt文件的持久化缓存，适用于CLI工具与桌面应用
+// 文件在打开期间被独占锁定，只能由一个进程使用；
+// 打开时与之后每隔 cleanupInterval 清理一次过期条目
+type Embedded struct {
+	db         *bolt.DB
+	bucket     []byte
+	serializer serializer.Serializer
+	stats      *StatsCollector
+	clock      Clock
+	loader     loaderConfig
+
+	cleanupInterval time.Duration
+	openTimeout     time.Duration
+	stop            chan struct{}
+	stopOnce        sync.Once
+	wg              sync.WaitGroup
+}
+
+// EmbeddedOption 嵌入式缓存选项
+type EmbeddedOption func(*Embedded)
+
+// WithEmbeddedSerializer 设置嵌入式缓存的序列化器
+func WithEmbeddedSerializer(s serializer.Serializer) EmbeddedOption {
+	return func(e *Embedded) {
+		e.serializer = s
+	}
+}
+
+// WithEmbeddedStats 设置嵌入式缓存的统计收集器
+// 可用于多个缓存实例共享同一个收集器
+func WithEmbeddedStats(s *StatsCollector) EmbeddedOption {
+	return func(e *Embedded) {
+		e.stats = s
+	}
+}
+
+// WithEmbeddedClock 设置判断过期所用的时钟，默认使用系统时间
+func WithEmbeddedClock(clock Clock) EmbeddedOption {
+	return func(e *Embedded) {
+		if clock != nil {
+			e.clock = clock
+		}
+	}
+}
+
+// WithEmbeddedCleanupInterval 设置清理过期条目的间隔，默认10分钟，d <= 0 表示只在打开时清理
+func WithEmbeddedCleanupInterval(d time.Duration) EmbeddedOption {
+	return func(e *Embedded) {
+		e.cleanupInterval = d
+	}
+}
+
+// WithEmbeddedBucket 设置保存条目的bucket名称，默认 DefaultEmbeddedBucket
+func WithEmbeddedBucket(name string) EmbeddedOption {
+	return func(e *Embedded) {
+		if name != "" {
+			e.bucket = []byte(name)
+		}
+	}
+}
+
+// WithEmbeddedOpenTimeout 设置等待文件锁的超时时间，默认1秒
+// 文件已被其他进程打开时，超时后 NewEmbedded 返回错误
+func WithEmbeddedOpenTimeout(d time.Duration) EmbeddedOption {
+	return func(e *Embedded) {
+		e.openTimeout = d
+	}
+}
+
+// WithEmbeddedLoaderTimeout 设置 GetSet 等待回调函数的超时时间，超时返回 ErrLoaderTimeout，d <= 0 表示不限制
+func WithEmbeddedLoaderTimeout(d time.Duration) EmbeddedOption {
+	return func(e *Embedded) {
+		e.loader.timeout = d
+	}
+}
+
+// WithEmbeddedLoaderHook 设置 GetSet 回调函数失败（返回错误、panic或超时）时的回调
+func WithEmbeddedLoaderHook(hook LoaderFailureHook) EmbeddedOption {
+	return func(e *Embedded) {
+		e.loader.onFailure = hook
+	}
+}
+
+// NewEmbedded 打开（不存在时创建）path处的缓存文件
+// 默认使用gob序列化器，使用完毕后需要调用 Close 释放文件
+func NewEmbedded(path string, opts ...EmbeddedOption) (*Embedded, error) {
+	e := &Embedded{
+		bucket:          []byte(DefaultEmbeddedBucket),
+		serializer:      cache_value.GetDefaultSerializer(), // 默认使用gob
+		stats:           NewStatsCollector(),
+		clock:           realClock{},
+		cleanupInterval: 10 * time.Minute,
+		openTimeout:     time.Second,
+		stop:            make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(e)
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: e.openTimeout})
+	if err != nil {
+		return nil, err
+	}
+	e.db = db
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(e.bucket)
+		return err
+	})
+	if err == nil {
+		_, err = e.DeleteExpired()
+	}
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if e.cleanupInterval > 0 {
+		e.wg.Add(1)
+		go e.cleanupLoop()
+	}
+
+	return e, nil
+}
+
+// Close 停止后台清理并关闭缓存文件
+func (c *Embedded) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	c.wg.Wait()
+	return c.db.Close()
+}
+
+// Stats 返回缓存统计快照
+func (c *Embedded) Stats() Stats {
+	return c.stats.Snapshot()
+}
+
+func (c *Embedded) Exists(ctx context.Context, key string) bool {
+	found := false
+	_ = c.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(c.bucket).Get([]byte(key))
+		found = raw != nil && !c.expired(raw)
+		return nil
+	})
+	return found
+}
+
+func (c *Embedded) Get(ctx context.Context, key string, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	var data []byte
+	err := c.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(c.bucket).Get([]byte(key))
+		if raw == nil || c.expired(raw) {
+			return ErrKeyNotFound
+		}
+		// raw 只在事务内有效，需要复制
+		data = append([]byte(nil), raw[embeddedHeaderSize:]...)
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			c.stats.RecordMiss(key)
+			return err
+		}
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+
+	if err := c.serializer.Decode(data, obj); err != nil {
+		c.stats.RecordError(key)
+		return serializationError(err)
+	}
+
+	c.stats.RecordHit(key, len(data))
+	return nil
+}
+
+func (c *Embedded) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	encode, rawSize, err := encodeSized(c.serializer, value)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl).UnixMilli()
+	}
+	raw := make([]byte, embeddedHeaderSize+len(encode))
+	binary.BigEndian.PutUint64(raw, uint64(expiresAt))
+	copy(raw[embeddedHeaderSize:], encode)
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(c.bucket).Put([]byte(key), raw)
+	})
+	if err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordSet(key, len(encode))
+	c.stats.RecordPayload(key, len(encode), rawSize)
+	return nil
+}
+
+// SetWithPriority 按优先级写入缓存
+// 嵌入式缓存没有容量淘汰，优先级不会产生影响
+func (c *Embedded) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	return c.Set(ctx, key, value, ttl)
+}
+
+// SetNX 键不存在或已过期时写入，返回是否写入
+func (c *Embedded) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	encode, rawSize, err := encodeSized(c.serializer, value)
+	if err != nil {
+		return false, err
+	}
+
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl).UnixMilli()
+	}
+	raw := make([]byte, embeddedHeaderSize+len(encode))
+	binary.BigEndian.PutUint64(raw, uint64(expiresAt))
+	copy(raw[embeddedHeaderSize:], encode)
+
+	stored := false
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		if old := b.Get([]byte(key)); old != nil && !c.expired(old) {
+			return nil
+		}
+		stored = true
+		return b.Put([]byte(key), raw)
+	})
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	if stored {
+		c.stats.RecordSet(key, len(encode))
+		c.stats.RecordPayload(key, len(encode), rawSize)
+	}
+	return stored, nil
+}
+
+func (c *Embedded) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	return c.loader.getSet(ctx, c, key, ttl, obj, fun)
+}
+
+// loaderSettings 返回 GetSet 调用回调函数的方式，包装器沿用该设置
+func (c *Embedded) loaderSettings() loaderConfig {
+	return c.loader
+}
+
+func (c *Embedded) Del(ctx context.Context, key string) error {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(c.bucket).Delete([]byte(key))
+	})
+	if err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordDelete(key)
+	return nil
+}
+
+// ExpiresAt 设置过期时间，键不存在或已过期时返回 ErrKeyNotFound
+func (c *Embedded) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+		raw := b.Get([]byte(key))
+		if raw == nil || c.expired(raw) {
+			return ErrKeyNotFound
+		}
+
+		updated := append([]byte(nil), raw...)
+		binary.BigEndian.PutUint64(updated, uint64(expiresAt.UnixMilli()))
+		return b.Put([]byte(key), updated)
+	})
+	return classifyError(err)
+}
+
+// ExpiresIn 设置剩余有效期，键不存在或已过期时返回 ErrKeyNotFound
+func (c *Embedded) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return c.ExpiresAt(ctx, key, c.clock.Now().Add(ttl))
+}
+
+// DeleteExpired 删除所有过期条目，返回删除的条目数
+func (c *Embedded) DeleteExpired() (int, error) {
+	deleted := 0
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(c.bucket)
+
+		// 遍历时删除会使游标跳过条目，先收集再删除
+		var keys [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			if c.expired(v) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		deleted = len(keys)
+		return nil
+	})
+	return deleted, err
+}
+
+// cleanupLoop 定期清理过期条目
+func (c *Embedded) cleanupLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if _, err := c.DeleteExpired(); err != nil {
+				c.stats.RecordError("")
+			}
+		}
+	}
+}
+
+// expired 判断条目是否已过期，格式错误的条目视为过期
+func (c *Embedded) expired(raw []byte) bool {
+	if len(raw) < embeddedHeaderSize {
+		return true
+	}
+	expiresAt := int64(binary.BigEndian.Uint64(raw))
+	return expiresAt > 0 && c.clock.Now().UnixMilli() >= expiresAt
+}
@@ -0,0 +1,128 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrKeyNotFound 键不存在或已过期
+// 所有后端在未命中时返回的错误都满足 errors.Is(err, ErrKeyNotFound)
+var ErrKeyNotFound = errors.New("key not exists")
+
+// ErrInvalidSignature 值的签名校验失败，值不是由持有签名密钥的一方写入的
+var ErrInvalidSignature = serializer.ErrInvalidSignature
+
+// ErrCorruptData 缓存中的值已损坏或被截断，无法解码
+var ErrCorruptData = serializer.ErrCorruptData
+
+// 错误分类，后端返回的错误满足 errors.Is(err, 分类) 中的一个，可以用于决定重试或降级，
+// 不需要匹配错误信息；原始错误仍可通过 errors.Is / errors.As 取得
+var (
+	// ErrTimeout 操作超时（包括上下文超时、网络超时、连接池等待超时与回调函数超时），可以重试
+	ErrTimeout = errors.New("cache timeout")
+	// ErrUnavailable 后端暂时不可用（连接被拒绝或断开、服务端正在加载数据或主从切换等），可以重试
+	ErrUnavailable = errors.New("cache unavailable")
+	// ErrSerialization 值的序列化或反序列化失败，重试不会成功
+	ErrSerialization = errors.New("cache serialization failed")
+	// ErrBackend 其他后端错误，重试通常不会成功
+	ErrBackend = errors.New("cache backend error")
+)
+
+// Error 带有分类的错误
+type Error struct {
+	Kind error // ErrTimeout、ErrUnavailable、ErrSerialization 或 ErrBackend
+	Err  error // 原始错误
+}
+
+func (e *Error) Error() string {
+	return e.Kind.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap 同时返回分类与原始错误
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// IsRetryable 判断错误是否值得重试（超时或后端暂时不可用）
+// 未经分类的错误（如直接从客户端返回的错误）也会按相同的规则判断
+func IsRetryable(err error) bool {
+	kind := errorKind(err)
+	return kind == ErrTimeout || kind == ErrUnavailable
+}
+
+// classifyError 为后端返回的错误加上分类
+// nil、未命中与已分类的错误原样返回
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, ErrKeyNotFound) || classified(err) {
+		return err
+	}
+	if errors.Is(err, context.Canceled) {
+		// 调用方主动取消，不属于后端错误
+		return err
+	}
+	return &Error{Kind: errorKind(err), Err: err}
+}
+
+// serializationError 为序列化器返回的错误加上分类
+func serializationError(err error) error {
+	if err == nil || classified(err) {
+		return err
+	}
+	return &Error{Kind: ErrSerialization, Err: err}
+}
+
+// classified 判断错误是否已经带有分类
+func classified(err error) bool {
+	return errors.Is(err, ErrTimeout) || errors.Is(err, ErrUnavailable) ||
+		errors.Is(err, ErrSerialization) || errors.Is(err, ErrBackend)
+}
+
+// errorKind 返回错误所属的分类，nil、未命中与取消返回nil
+func errorKind(err error) error {
+	switch {
+	case err == nil, errors.Is(err, ErrKeyNotFound), errors.Is(err, context.Canceled):
+		return nil
+	case errors.Is(err, ErrTimeout):
+		return ErrTimeout
+	case errors.Is(err, ErrUnavailable):
+		return ErrUnavailable
+	case errors.Is(err, ErrSerialization):
+		return ErrSerialization
+	case errors.Is(err, ErrBackend):
+		return ErrBackend
+	}
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(err, os.ErrDeadlineExceeded),
+		errors.Is(err, redis.ErrPoolTimeout):
+		return ErrTimeout
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrTimeout
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.EPIPE), errors.Is(err, net.ErrClosed),
+		errors.Is(err, redis.ErrPoolExhausted):
+		return ErrUnavailable
+	}
+
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return ErrUnavailable
+	}
+
+	// 服务端返回的暂时性错误
+	for _, prefix := range []string{"LOADING ", "READONLY ", "MASTERDOWN ", "TRYAGAIN ", "CLUSTERDOWN ", "BUSY "} {
+		if redis.HasErrorPrefix(err, prefix) {
+			return ErrUnavailable
+		}
+	}
+	return ErrBackend
+}
@@ -0,0 +1,131 @@
+package go_cache
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"time"
+
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// ErrNotModified 值的内容摘要与调用方已知的相同，obj 没有被写入（如返回 HTTP 304）
+var ErrNotModified = errors.New("cache value not modified")
+
+// etagBackend 由能够在服务端比较内容摘要的缓存实现（如Redis使用Lua脚本）
+// 摘要为保存的数据的SHA1，内容未变化时不需要传输值
+type etagBackend interface {
+	getIfChanged(ctx context.Context, key, known string, obj any) (string, error)
+}
+
+// ETagOption 内容摘要选项
+type ETagOption func(*ETagCache)
+
+// WithETagSerializer 设置计算内容摘要时使用的序列化器
+// 序列化结果必须是确定的：gob以随机顺序写入map，同一个值每次得到不同的摘要。
+// Redis缓存在服务端对保存的数据计算摘要，不受该选项影响
+func WithETagSerializer(s serializer.Serializer) ETagOption {
+	return func(c *ETagCache) {
+		c.serializer = s
+	}
+}
+
+// ETagCache 支持按内容摘要条件读取的缓存包装
+// 客户端或内部服务轮询体积较大的值时，通过 GetIfChanged 携带上次得到的摘要，内容未变化时返回 ErrNotModified。
+// 摘要在读取时按当前保存的值计算，不单独保存，绕过本包装写入的值同样得到正确的摘要。
+// Redis缓存在服务端用Lua脚本计算并比较，内容未变化时不传输值；其他缓存读取值后在本地计算
+type ETagCache struct {
+	cache      gsr.Cacher
+	serializer serializer.Serializer
+}
+
+// NewETagCache 创建支持条件读取的缓存包装，默认使用JSON序列化器计算摘要
+func NewETagCache(cache gsr.Cacher, opts ...ETagOption) *ETagCache {
+	c := &ETagCache{
+		cache:      cache,
+		serializer: serializer.NewJson(), // JSON按键排序写入map，摘要与map的遍历顺序无关
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// GetIfChanged 值的内容摘要与knownETag不同时将值写入obj并返回新的摘要
+// 摘要相同时返回 knownETag 与 ErrNotModified，obj 不会被写入；键不存在时返回 ErrKeyNotFound
+func (c *ETagCache) GetIfChanged(ctx context.Context, key, knownETag string, obj any) (string, error) {
+	if noCache(ctx) {
+		return "", ErrCacheBypassed
+	}
+	if b, ok := c.cache.(etagBackend); ok {
+		return b.getIfChanged(ctx, key, knownETag, obj)
+	}
+
+	// 读取到临时对象，内容未变化时不写入obj
+	target, err := targetValue(obj)
+	if err != nil {
+		return "", err
+	}
+	tmp := reflect.New(target.Type())
+	if err := c.cache.Get(ctx, key, tmp.Interface()); err != nil {
+		return "", err
+	}
+	etag, err := c.etag(tmp.Elem().Interface())
+	if err != nil {
+		return "", err
+	}
+	if etag == knownETag {
+		return knownETag, ErrNotModified
+	}
+	target.Set(tmp.Elem())
+	return etag, nil
+}
+
+// etag 计算值的内容摘要
+func (c *ETagCache) etag(value any) (string, error) {
+	data, err := c.serializer.Encode(value)
+	if err != nil {
+		return "", serializationError(err)
+	}
+	return etagOf(data), nil
+}
+
+// etagOf 计算序列化后数据的内容摘要
+func etagOf(data []byte) string {
+	sum := sha1.Sum(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *ETagCache) Exists(ctx context.Context, key string) bool {
+	return c.cache.Exists(ctx, key)
+}
+
+func (c *ETagCache) Get(ctx context.Context, key string, obj any) error {
+	return c.cache.Get(ctx, key, obj)
+}
+
+func (c *ETagCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return c.cache.Set(ctx, key, value, ttl)
+}
+
+func (c *ETagCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	return c.cache.GetSet(ctx, key, ttl, obj, fun)
+}
+
+func (c *ETagCache) Del(ctx context.Context, key string) error {
+	return c.cache.Del(ctx, key)
+}
+
+func (c *ETagCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return c.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (c *ETagCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return c.cache.ExpiresIn(ctx, key, ttl)
+}
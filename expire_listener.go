@@ -0,0 +1,172 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"runtime/debug"
+	"sync"
+)
+
+// ExpireListener 键过期后的回调
+type ExpireListener func(key string)
+
+// ExpiryListenerPanicError 过期监听器发生panic时传给错误回调的错误
+type ExpiryListenerPanicError struct {
+	Pattern string
+	Value   any    // recover() 得到的值
+	Stack   []byte // 发生panic时的调用栈
+}
+
+func (e *ExpiryListenerPanicError) Error() string {
+	return fmt.Sprintf("expiry listener panic for pattern %q: %v", e.Pattern, e.Value)
+}
+
+// ExpiryListenersOption 过期监听选项
+type ExpiryListenersOption func(*ExpiryListeners)
+
+// WithExpiryWorkers 设置执行监听器的goroutine数，默认4
+func WithExpiryWorkers(n int) ExpiryListenersOption {
+	return func(l *ExpiryListeners) {
+		if n > 0 {
+			l.pool.Size = n
+		}
+	}
+}
+
+// WithExpiryQueueSize 设置等待执行的过期事件的队列长度，默认1024，队列已满时丢弃新的事件
+func WithExpiryQueueSize(n int) ExpiryListenersOption {
+	return func(l *ExpiryListeners) {
+		if n > 0 {
+			l.pool.Queue.Size = n
+		}
+	}
+}
+
+// WithExpiryQueue 设置过期事件队列的长度与队列已满时的策略
+// QueueBlock 策略下 Notify 会阻塞报告过期的内存缓存操作或Redis订阅，直到队列有空位
+func WithExpiryQueue(cfg QueueConfig) ExpiryListenersOption {
+	return func(l *ExpiryListeners) {
+		l.pool.Queue = cfg
+	}
+}
+
+// WithExpiryWorkerPool 设置执行监听器的goroutine数、事件队列与panic回调，零值字段不覆盖
+// 监听器的panic由 WithExpiryPanicHook 单独恢复，PanicHandler 只处理其他代码中的panic
+func WithExpiryWorkerPool(pool WorkerPool) ExpiryListenersOption {
+	return func(l *ExpiryListeners) {
+		l.pool = l.pool.merge(pool)
+	}
+}
+
+// WithExpiryPanicHook 设置监听器panic时的回调，err 为 *ExpiryListenerPanicError
+func WithExpiryPanicHook(hook LoaderFailureHook) ExpiryListenersOption {
+	return func(l *ExpiryListeners) {
+		l.onPanic = hook
+	}
+}
+
+// expiryListener 一个已注册的监听器
+type expiryListener struct {
+	pattern string
+	fn      ExpireListener
+}
+
+// ExpiryListeners 按键模式注册的过期监听器
+// 过期事件来自内存缓存的移除回调（见 WithMemoryExpiryListeners）与Redis的键空间通知（见 WatchRedisExpiry），
+// 由最多 WithExpiryWorkers 个goroutine执行匹配的监听器；每个监听器的panic单独恢复，不影响其他监听器与缓存。
+// 不同键的事件并发执行，不保证顺序
+type ExpiryListeners struct {
+	pool    WorkerPool
+	onPanic LoaderFailureHook
+
+	mu        sync.RWMutex
+	nextID    uint64
+	listeners map[uint64]expiryListener
+
+	events *asyncQueue[string]
+}
+
+// NewExpiryListeners 创建过期监听器注册表
+// 执行监听器的goroutine在有事件时启动、队列为空时退出，不再使用时调用 Close 等待剩余的事件
+func NewExpiryListeners(opts ...ExpiryListenersOption) *ExpiryListeners {
+	l := &ExpiryListeners{
+		listeners: make(map[uint64]expiryListener),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	pool := resolveWorkerPool(WorkerPool{Size: 4, Queue: QueueConfig{Size: 1024}}, l.pool)
+	l.events = newAsyncQueue("expiry", pool, l.dispatch, nil)
+	return l
+}
+
+// OnExpire 注册匹配pattern的键过期后调用的监听器，返回取消注册的函数
+// 模式语法与 PatternCache 相同
+func (l *ExpiryListeners) OnExpire(pattern string, fn ExpireListener) func() {
+	l.mu.Lock()
+	l.nextID++
+	id := l.nextID
+	l.listeners[id] = expiryListener{pattern: pattern, fn: fn}
+	l.mu.Unlock()
+
+	return func() {
+		l.mu.Lock()
+		delete(l.listeners, id)
+		l.mu.Unlock()
+	}
+}
+
+// Notify 报告key已过期，监听器异步执行；队列已满时按队列策略处理，已关闭时丢弃
+func (l *ExpiryListeners) Notify(key string) {
+	_ = l.events.push(context.Background(), key)
+}
+
+// Dropped 返回因队列已满或已关闭而丢弃的事件数
+func (l *ExpiryListeners) Dropped() uint64 {
+	return l.events.stats().Dropped
+}
+
+// QueueStats 返回过期事件队列的统计快照
+func (l *ExpiryListeners) QueueStats() QueueStats {
+	return l.events.stats()
+}
+
+// Drain 等待已报告的事件全部执行完毕或ctx结束
+func (l *ExpiryListeners) Drain(ctx context.Context) error {
+	return l.events.drain(ctx)
+}
+
+// Close 停止接收事件，等待队列中的事件执行完毕
+func (l *ExpiryListeners) Close() {
+	l.events.close()
+	_ = l.events.drain(context.Background())
+}
+
+// dispatch 执行匹配key的监听器
+func (l *ExpiryListeners) dispatch(key string) {
+	l.mu.RLock()
+	matched := make([]expiryListener, 0, len(l.listeners))
+	for _, listener := range l.listeners {
+		if MatchPattern(listener.pattern, key) {
+			matched = append(matched, listener)
+		}
+	}
+	l.mu.RUnlock()
+
+	for _, listener := range matched {
+		l.call(listener, key)
+	}
+}
+
+// call 执行监听器并恢复panic
+func (l *ExpiryListeners) call(listener expiryListener, key string) {
+	defer func() {
+		if r := recover(); r != nil && l.onPanic != nil {
+			l.onPanic(key, &ExpiryListenerPanicError{Pattern: listener.pattern, Value: r, Stack: debug.Stack()})
+		}
+	}()
+	listener.fn(key)
+}
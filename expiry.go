@@ -0,0 +1,59 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+)
+
+// ExpiryCache 支持移除与刷新过期时间的缓存
+type ExpiryCache interface {
+	// Persist 移除键的过期时间，键不存在时返回 ErrKeyNotFound
+	Persist(ctx context.Context, key string) error
+	// Touch 键存在时将过期时间刷新为ttl，返回键是否存在；ttl <= 0 表示移除过期时间
+	Touch(ctx context.Context, key string, ttl time.Duration) (bool, error)
+}
+
+// GetTouchCache 支持读取的同时刷新过期时间的缓存
+type GetTouchCache interface {
+	// GetTouch 读取键到obj并将过期时间刷新为ttl，键不存在时返回 ErrKeyNotFound；ttl <= 0 表示移除过期时间
+	GetTouch(ctx context.Context, key string, ttl time.Duration, obj any) error
+}
+
+// ExpireCondition 设置过期时间的条件，与 Redis 7 EXPIRE 的 NX/XX/GT/LT 选项一致
+// 没有过期时间的键视为有效期无限长
+type ExpireCondition string
+
+const (
+	// ExpireAlways 无条件设置
+	ExpireAlways ExpireCondition = ""
+	// ExpireNX 仅当键没有过期时间时设置
+	ExpireNX ExpireCondition = "NX"
+	// ExpireXX 仅当键已有过期时间时设置
+	ExpireXX ExpireCondition = "XX"
+	// ExpireGT 仅当新的过期时间晚于当前过期时间时设置，可用于"只延长、不缩短"
+	ExpireGT ExpireCondition = "GT"
+	// ExpireLT 仅当新的过期时间早于当前过期时间时设置
+	ExpireLT ExpireCondition = "LT"
+)
+
+// ConditionalExpiryCache 支持按条件设置过期时间的缓存
+// 返回是否设置成功，键不存在或条件不满足时返回false
+type ConditionalExpiryCache interface {
+	ExpiresInIf(ctx context.Context, key string, ttl time.Duration, cond ExpireCondition) (bool, error)
+	ExpiresAtIf(ctx context.Context, key string, expiresAt time.Time, cond ExpireCondition) (bool, error)
+}
+
+// allowExpire 判断条件是否允许将过期时间从current改为next（UnixNano，0表示没有过期时间）
+func allowExpire(cond ExpireCondition, current, next int64) bool {
+	switch cond {
+	case ExpireNX:
+		return current == 0
+	case ExpireXX:
+		return current != 0
+	case ExpireGT:
+		return current != 0 && next > current
+	case ExpireLT:
+		return current == 0 || next < current
+	}
+	return true
+}
@@ -0,0 +1,327 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"hash/crc32"
+	"runtime/debug"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultFlagsKeyPrefix 特性开关在缓存中的默认键前缀
+const DefaultFlagsKeyPrefix = "flag:"
+
+// Flag 特性开关的定义
+type Flag struct {
+	Enabled  bool          // 总开关，关闭时对所有对象都关闭
+	Rollout  int           // 开启的对象比例（1-99），按开关名与对象ID哈希分桶；<= 0 或 >= 100 表示全部开启
+	Allow    []string      // 始终开启的对象ID（总开关关闭时除外）
+	Deny     []string      // 始终关闭的对象ID，优先于 Allow
+	Variants []FlagVariant // 开启时按权重分配的变体，为空时 Variant 返回空字符串
+}
+
+// FlagVariant 特性开关的一个变体
+type FlagVariant struct {
+	Name   string
+	Weight int // 权重，<= 0 的变体不会被分配
+}
+
+// enabledFor 判断开关对subjectID是否开启
+func (f Flag) enabledFor(name, subjectID string) bool {
+	if !f.Enabled || slices.Contains(f.Deny, subjectID) {
+		return false
+	}
+	if slices.Contains(f.Allow, subjectID) || f.Rollout <= 0 || f.Rollout >= 100 {
+		return true
+	}
+	return flagBucket(name+":"+subjectID, 100) < uint32(f.Rollout)
+}
+
+// variantFor 返回分配给subjectID的变体，开关关闭或没有变体时返回空字符串
+func (f Flag) variantFor(name, subjectID string) string {
+	if !f.enabledFor(name, subjectID) {
+		return ""
+	}
+	total := 0
+	for _, v := range f.Variants {
+		total += max(v.Weight, 0)
+	}
+	if total == 0 {
+		return ""
+	}
+	// 与开启判断使用不同的哈希，变体分配与灰度比例互不影响
+	bucket := int(flagBucket(name+":variant:"+subjectID, uint32(total)))
+	for _, v := range f.Variants {
+		if bucket < max(v.Weight, 0) {
+			return v.Name
+		}
+		bucket -= max(v.Weight, 0)
+	}
+	return ""
+}
+
+// flagBucket 把s哈希到 [0, n) 中
+func flagBucket(s string, n uint32) uint32 {
+	return crc32.ChecksumIEEE([]byte(s)) % n
+}
+
+// FlagSource 特性开关的数据源，开关不存在时返回 ErrKeyNotFound
+type FlagSource interface {
+	LoadFlag(ctx context.Context, name string) (Flag, error)
+}
+
+// FlagSourceFunc 将函数适配为 FlagSource
+type FlagSourceFunc func(ctx context.Context, name string) (Flag, error)
+
+// LoadFlag 调用f
+func (f FlagSourceFunc) LoadFlag(ctx context.Context, name string) (Flag, error) {
+	return f(ctx, name)
+}
+
+// flagEntry 缓存中保存的特性开关与加载时间
+type flagEntry struct {
+	Flag     Flag
+	LoadedAt int64 // Unix纳秒
+	Missing  bool  // 数据源报告开关不存在，读取时返回 ErrKeyNotFound
+}
+
+// flagCall 正在进行的一次开关加载
+type flagCall struct {
+	wg   sync.WaitGroup
+	flag Flag
+	err  error
+}
+
+// FlagsOption 特性开关选项
+type FlagsOption func(*Flags)
+
+// WithFlagsTTL 设置开关在缓存中的有效期，默认30秒
+func WithFlagsTTL(d time.Duration) FlagsOption {
+	return func(f *Flags) {
+		if d > 0 {
+			f.ttl = d
+		}
+	}
+}
+
+// WithFlagsRefreshAhead 设置提前刷新的时间，默认10秒
+// 开关在缓存中剩余的有效期不足d时，读取会返回缓存中的值并在后台从数据源重新加载；
+// d <= 0 表示不提前刷新，过期后同步加载
+func WithFlagsRefreshAhead(d time.Duration) FlagsOption {
+	return func(f *Flags) {
+		f.refreshAhead = d
+	}
+}
+
+// WithFlagsNotFoundTTL 设置数据源报告开关不存在（ErrKeyNotFound）的结果在缓存中的有效期，默认5秒
+// 有效期内读取不存在的开关不再访问数据源；d <= 0 表示不缓存不存在的结果
+func WithFlagsNotFoundTTL(d time.Duration) FlagsOption {
+	return func(f *Flags) {
+		f.notFoundTTL = d
+	}
+}
+
+// WithFlagsKeyPrefix 设置开关在缓存中的键前缀，默认 DefaultFlagsKeyPrefix
+func WithFlagsKeyPrefix(prefix string) FlagsOption {
+	return func(f *Flags) {
+		f.prefix = prefix
+	}
+}
+
+// WithFlagsClock 设置判断是否需要刷新所用的时钟，默认使用系统时间
+func WithFlagsClock(clock Clock) FlagsOption {
+	return func(f *Flags) {
+		if clock != nil {
+			f.clock = clock
+		}
+	}
+}
+
+// WithFlagsFailureHook 设置数据源加载失败时的回调，可用于记录日志
+// IsEnabled 与 Variant 在加载失败时返回关闭，错误只能通过该回调或 Lookup 获得
+func WithFlagsFailureHook(hook LoaderFailureHook) FlagsOption {
+	return func(f *Flags) {
+		f.onFailure = hook
+	}
+}
+
+// WithFlagsWorkerPool 设置后台刷新开关的goroutine数（默认4）、刷新队列（默认长度1024）与panic回调，零值字段不覆盖
+// 队列已满时的刷新按策略丢弃，开关在缓存过期后由读取同步加载
+func WithFlagsWorkerPool(pool WorkerPool) FlagsOption {
+	return func(f *Flags) {
+		f.pool = f.pool.merge(pool)
+	}
+}
+
+// flagRefresh 等待执行的一次后台刷新
+type flagRefresh struct {
+	ctx  context.Context
+	flag string
+}
+
+// Flags 基于缓存的特性开关
+// 开关定义从 FlagSource 加载后保存在缓存中，多个实例共享同一个缓存时只需加载一次；
+// 临近过期时在后台提前刷新，热点开关的读取不会因为过期而等待数据源；
+// 缓存未命中时同一个开关的并发读取合并为一次加载，不存在的开关短时间缓存
+type Flags struct {
+	cache        gsr.Cacher
+	source       FlagSource
+	ttl          time.Duration
+	refreshAhead time.Duration
+	notFoundTTL  time.Duration
+	prefix       string
+	clock        Clock
+	onFailure    LoaderFailureHook
+	pool         WorkerPool
+
+	refreshing sync.Map // 正在后台刷新与等待刷新的开关名
+	refreshes  *asyncQueue[flagRefresh]
+
+	mu      sync.Mutex
+	loading map[string]*flagCall // 缓存未命中时正在进行的加载
+}
+
+// NewFlags 创建使用cache保存、从source加载开关定义的特性开关
+func NewFlags(cache gsr.Cacher, source FlagSource, opts ...FlagsOption) *Flags {
+	f := &Flags{
+		cache:        cache,
+		source:       source,
+		ttl:          30 * time.Second,
+		refreshAhead: 10 * time.Second,
+		notFoundTTL:  5 * time.Second,
+		prefix:       DefaultFlagsKeyPrefix,
+		clock:        realClock{},
+		loading:      make(map[string]*flagCall),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(f)
+	}
+
+	pool := resolveWorkerPool(WorkerPool{Size: 4, Queue: QueueConfig{Size: 1024}}, f.pool)
+	f.refreshes = newAsyncQueue("flags", pool, f.refresh, func(job flagRefresh) {
+		f.refreshing.Delete(job.flag)
+	})
+	return f
+}
+
+// IsEnabled 判断开关对subjectID是否开启，开关不存在或加载失败时返回false
+func (f *Flags) IsEnabled(ctx context.Context, flag, subjectID string) bool {
+	def, err := f.Lookup(ctx, flag)
+	if err != nil {
+		return false
+	}
+	return def.enabledFor(flag, subjectID)
+}
+
+// Variant 返回分配给subjectID的变体，开关关闭、没有变体、不存在或加载失败时返回空字符串
+// 同一个对象在开关定义不变时总是得到相同的变体
+func (f *Flags) Variant(ctx context.Context, flag, subjectID string) string {
+	def, err := f.Lookup(ctx, flag)
+	if err != nil {
+		return ""
+	}
+	return def.variantFor(flag, subjectID)
+}
+
+// Lookup 返回开关定义，缓存未命中时从数据源加载，同一个开关的并发加载合并为一次
+// 开关不存在时返回 ErrKeyNotFound；数据源panic时返回 *LoaderPanicError
+func (f *Flags) Lookup(ctx context.Context, flag string) (Flag, error) {
+	var entry flagEntry
+	if err := f.cache.Get(ctx, f.prefix+flag, &entry); err != nil {
+		return f.load(ctx, flag)
+	}
+	if entry.Missing {
+		return Flag{}, ErrKeyNotFound
+	}
+
+	if f.refreshAhead > 0 {
+		age := f.clock.Now().Sub(time.Unix(0, entry.LoadedAt))
+		if age >= f.ttl-f.refreshAhead {
+			f.refreshAsync(ctx, flag)
+		}
+	}
+	return entry.Flag, nil
+}
+
+// Refresh 立即从数据源重新加载开关并写入缓存
+// 数据源返回 ErrKeyNotFound 时按 WithFlagsNotFoundTTL 缓存不存在的结果
+func (f *Flags) Refresh(ctx context.Context, flag string) (Flag, error) {
+	def, err := f.source.LoadFlag(ctx, flag)
+	if err != nil {
+		if f.onFailure != nil {
+			f.onFailure(flag, err)
+		}
+		if errors.Is(err, ErrKeyNotFound) && f.notFoundTTL > 0 {
+			entry := flagEntry{LoadedAt: f.clock.Now().UnixNano(), Missing: true}
+			if setErr := f.cache.Set(ctx, f.prefix+flag, entry, f.notFoundTTL); setErr != nil && f.onFailure != nil {
+				f.onFailure(flag, setErr)
+			}
+		}
+		return Flag{}, err
+	}
+
+	entry := flagEntry{Flag: def, LoadedAt: f.clock.Now().UnixNano()}
+	if err := f.cache.Set(ctx, f.prefix+flag, entry, f.ttl); err != nil && f.onFailure != nil {
+		f.onFailure(flag, err)
+	}
+	return def, nil
+}
+
+// load 缓存未命中时加载开关，同一个开关的并发加载合并为一次
+func (f *Flags) load(ctx context.Context, flag string) (Flag, error) {
+	f.mu.Lock()
+	if call, ok := f.loading[flag]; ok {
+		f.mu.Unlock()
+		call.wg.Wait()
+		return call.flag, call.err
+	}
+	call := &flagCall{}
+	call.wg.Add(1)
+	f.loading[flag] = call
+	f.mu.Unlock()
+
+	defer func() {
+		f.mu.Lock()
+		delete(f.loading, flag)
+		f.mu.Unlock()
+		call.wg.Done()
+	}()
+	call.flag, call.err = f.refreshSafe(ctx, flag)
+	return call.flag, call.err
+}
+
+// refreshSafe 调用 Refresh 并将数据源的panic转换为错误，等待同一次加载的调用方不会一直阻塞
+func (f *Flags) refreshSafe(ctx context.Context, flag string) (_ Flag, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &LoaderPanicError{Key: flag, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return f.Refresh(ctx, flag)
+}
+
+// QueueStats 返回后台刷新队列的统计快照
+func (f *Flags) QueueStats() QueueStats {
+	return f.refreshes.stats()
+}
+
+// refreshAsync 把开关的刷新提交到后台队列，同一个开关同时只有一个刷新
+func (f *Flags) refreshAsync(ctx context.Context, flag string) {
+	if _, loaded := f.refreshing.LoadOrStore(flag, struct{}{}); loaded {
+		return
+	}
+	// 请求结束后刷新仍需继续，ctx 只用于 QueueBlock 策略下等待队列出现空位
+	_ = f.refreshes.push(ctx, flagRefresh{ctx: context.WithoutCancel(ctx), flag: flag})
+}
+
+// refresh 执行一次后台刷新
+func (f *Flags) refresh(job flagRefresh) {
+	defer f.refreshing.Delete(job.flag)
+	_, _ = f.Refresh(job.ctx, job.flag)
+}
@@ -0,0 +1,97 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultExpiryBuckets 默认的过期预测时间段
+var DefaultExpiryBuckets = []time.Duration{time.Second, time.Minute, 10 * time.Minute, time.Hour}
+
+// TTLSampler 支持采样键剩余TTL的缓存
+type TTLSampler interface {
+	// SampleTTLs 采样最多n个键的剩余TTL，没有过期时间的键返回负值
+	SampleTTLs(ctx context.Context, n int) ([]time.Duration, error)
+}
+
+// ExpiryBucket 一个时间段内将要过期的条目数
+type ExpiryBucket struct {
+	Within time.Duration // 时间段上界，统计 (上一个时间段, Within] 内过期的条目
+	Count  int
+}
+
+// ExpiryForecast 过期预测结果
+type ExpiryForecast struct {
+	Sampled  int            // 采样的条目数
+	NoExpiry int            // 没有过期时间的条目数
+	Buckets  []ExpiryBucket // 各时间段内将要过期的条目数
+	Beyond   int            // 在最后一个时间段之后才过期的条目数
+}
+
+// ForecastExpiry 采样最多sampleSize个键，统计它们将在哪个时间段内过期
+// 用于在集中过期（缓存雪崩）发生前发现问题，buckets为空时使用 DefaultExpiryBuckets
+func ForecastExpiry(ctx context.Context, cache any, sampleSize int, buckets ...time.Duration) (ExpiryForecast, error) {
+	sampler, ok := cache.(TTLSampler)
+	if !ok {
+		return ExpiryForecast{}, ErrNotSupported
+	}
+	if len(buckets) == 0 {
+		buckets = DefaultExpiryBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+
+	ttls, err := sampler.SampleTTLs(ctx, sampleSize)
+	if err != nil {
+		return ExpiryForecast{}, err
+	}
+
+	forecast := ExpiryForecast{
+		Sampled: len(ttls),
+		Buckets: make([]ExpiryBucket, len(buckets)),
+	}
+	for i, b := range buckets {
+		forecast.Buckets[i].Within = b
+	}
+
+	for _, ttl := range ttls {
+		if ttl < 0 {
+			forecast.NoExpiry++
+			continue
+		}
+		i, _ := slices.BinarySearch(buckets, ttl)
+		if i == len(buckets) {
+			forecast.Beyond++
+			continue
+		}
+		forecast.Buckets[i].Count++
+	}
+	return forecast, nil
+}
+
+// String 以文本直方图形式输出预测结果
+func (f ExpiryForecast) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "sampled %d keys\n", f.Sampled)
+
+	peak := max(f.NoExpiry, f.Beyond)
+	for _, bucket := range f.Buckets {
+		peak = max(peak, bucket.Count)
+	}
+	bar := func(n int) string {
+		if peak == 0 {
+			return ""
+		}
+		return strings.Repeat("#", n*40/peak)
+	}
+
+	for _, bucket := range f.Buckets {
+		fmt.Fprintf(&b, "<= %-8s %6d %s\n", bucket.Within, bucket.Count, bar(bucket.Count))
+	}
+	fmt.Fprintf(&b, "%-11s %6d %s\n", "later", f.Beyond, bar(f.Beyond))
+	fmt.Fprintf(&b, "%-11s %6d %s\n", "no expiry", f.NoExpiry, bar(f.NoExpiry))
+	return b.String()
+}
@@ -0,0 +1,60 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ValueSource 值的来源
+type ValueSource string
+
+const (
+	// SourceCache 值来自缓存
+	SourceCache ValueSource = "cache"
+	// SourceLoader 值由回调函数加载
+	SourceLoader ValueSource = "loader"
+)
+
+// GetInfo GetOrSet 的结果信息，可用于按调用点记录缓存命中情况
+type GetInfo struct {
+	Hit    bool        // 是否命中缓存
+	Source ValueSource // 值的来源
+	// TTL 值的剩余有效期，没有过期时间时为-1；
+	// 命中时只有缓存实现 TTLCache 才能获得，否则为0
+	TTL time.Duration
+}
+
+// TTLCache 支持查询剩余有效期的缓存
+type TTLCache interface {
+	// TTL 返回键的剩余有效期，没有过期时间时返回-1，键不存在时返回 ErrKeyNotFound
+	TTL(ctx context.Context, key string) (time.Duration, error)
+}
+
+// GetOrSet 与 GetSet 行为一致，同时返回值来自缓存还是回调函数
+func GetOrSet(ctx context.Context, cache gsr.Cacher, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) (GetInfo, error) {
+	// 先尝试从缓存获取，WithForceRefresh 时直接调用回调函数
+	if !forceRefresh(ctx) && cache.Get(ctx, key, obj) == nil {
+		info := GetInfo{Hit: true, Source: SourceCache}
+		if c, ok := cache.(TTLCache); ok {
+			info.TTL, _ = c.TTL(ctx, key)
+		}
+		return info, nil
+	}
+
+	// 缓存未命中，调用回调函数
+	info := GetInfo{Source: SourceLoader, TTL: ttl}
+	if ttl <= 0 {
+		info.TTL = -1
+	}
+	if err := loaderOf(cache).call(ctx, key, obj, fun); err != nil {
+		return info, err
+	}
+
+	value, err := pointee(obj)
+	if err != nil {
+		return info, err
+	}
+	return info, cache.Set(ctx, key, value, ttl)
+}
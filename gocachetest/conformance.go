@@ -0,0 +1,301 @@
+// Package gocachetest 提供缓存后端的一致性测试套件
+//
+// 实现新后端时调用 RunConformance 验证其行为与内置后端一致，
+// 覆盖读写、nil值、TTL、GetSet 回调与错误类型等 gsr.Cacher 的完整语义：
+//
+//	func TestMyBackend(t *testing.T) {
+//		gocachetest.RunConformance(t, func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+//			clock := go_cache.NewFakeClock(time.Time{})
+//			return mybackend.New(clock), clock.Advance
+//		})
+//	}
+package gocachetest
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// Factory 为每个用例创建一个空缓存
+// advance 用于让缓存的时间前进d，为nil时套件使用 time.Sleep 等待真实时间流逝
+type Factory func(t *testing.T) (cache gsr.Cacher, advance func(d time.Duration))
+
+// Value 一致性测试使用的结构体
+// 使用gob等需要注册类型的序列化器时，后端需要支持该类型
+type Value struct {
+	ID   int
+	Name string
+	Tags []string
+}
+
+// shortTTL 过期用例使用的TTL，足够小以便使用真实时间时快速完成
+const shortTTL = 50 * time.Millisecond
+
+// RunConformance 对factory创建的缓存运行完整的一致性测试
+func RunConformance(t *testing.T, factory Factory) {
+	t.Helper()
+
+	cases := []struct {
+		name string
+		fn   func(t *testing.T, c *subject)
+	}{
+		{"SetGet", testSetGet},
+		{"GetMissing", testGetMissing},
+		{"Exists", testExists},
+		{"Overwrite", testOverwrite},
+		{"Del", testDel},
+		{"NilValues", testNilValues},
+		{"TTL", testTTL},
+		{"ZeroTTL", testZeroTTL},
+		{"ExpiresIn", testExpiresIn},
+		{"ExpiresAtPast", testExpiresAtPast},
+		{"GetSetMiss", testGetSetMiss},
+		{"GetSetHit", testGetSetHit},
+		{"GetSetCallbackError", testGetSetCallbackError},
+		{"SetNX", testSetNX},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cache, advance := factory(t)
+			if advance == nil {
+				advance = time.Sleep
+			}
+			tc.fn(t, &subject{Cacher: cache, advance: advance, ctx: context.Background()})
+		})
+	}
+}
+
+// subject 被测缓存
+type subject struct {
+	gsr.Cacher
+	advance func(time.Duration)
+	ctx     context.Context
+}
+
+func (s *subject) mustSet(t *testing.T, key string, value any, ttl time.Duration) {
+	t.Helper()
+	if err := s.Set(s.ctx, key, value, ttl); err != nil {
+		t.Fatalf("Set(%q) error = %v", key, err)
+	}
+}
+
+func (s *subject) assertMissing(t *testing.T, key string) {
+	t.Helper()
+	if s.Exists(s.ctx, key) {
+		t.Errorf("Exists(%q) = true, want false", key)
+	}
+	var v string
+	if err := s.Get(s.ctx, key, &v); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("Get(%q) error = %v, want ErrKeyNotFound", key, err)
+	}
+}
+
+func testSetGet(t *testing.T, s *subject) {
+	tests := []struct {
+		name  string
+		value any
+		obj   any
+	}{
+		{"string", "hello", new(string)},
+		{"int", 42, new(int)},
+		{"float", 3.5, new(float64)},
+		{"bool", true, new(bool)},
+		{"bytes", []byte("raw"), new([]byte)},
+		{"struct", Value{ID: 1, Name: "n", Tags: []string{"a"}}, new(Value)},
+		{"pointer", &Value{ID: 2, Name: "p"}, new(*Value)},
+		{"map", map[string]int{"a": 1}, new(map[string]int)},
+	}
+
+	for _, tt := range tests {
+		key := "conformance:setget:" + tt.name
+		s.mustSet(t, key, tt.value, time.Minute)
+		if err := s.Get(s.ctx, key, tt.obj); err != nil {
+			t.Errorf("%s: Get() error = %v", tt.name, err)
+			continue
+		}
+		if got := reflect.ValueOf(tt.obj).Elem().Interface(); !reflect.DeepEqual(got, tt.value) {
+			t.Errorf("%s: Get() = %#v, want %#v", tt.name, got, tt.value)
+		}
+	}
+}
+
+func testGetMissing(t *testing.T, s *subject) {
+	s.assertMissing(t, "conformance:missing")
+}
+
+func testExists(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:exists", "v", time.Minute)
+	if !s.Exists(s.ctx, "conformance:exists") {
+		t.Error("Exists() = false, want true")
+	}
+}
+
+func testOverwrite(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:overwrite", "old", time.Minute)
+	s.mustSet(t, "conformance:overwrite", "new", time.Minute)
+
+	var v string
+	if err := s.Get(s.ctx, "conformance:overwrite", &v); err != nil || v != "new" {
+		t.Errorf("Get() = %q, %v, want %q", v, err, "new")
+	}
+}
+
+func testDel(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:del", "v", time.Minute)
+	if err := s.Del(s.ctx, "conformance:del"); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	s.assertMissing(t, "conformance:del")
+
+	// 删除不存在的键不是错误
+	if err := s.Del(s.ctx, "conformance:del"); err != nil {
+		t.Errorf("Del() 不存在的键 error = %v", err)
+	}
+}
+
+func testNilValues(t *testing.T, s *subject) {
+	var nilPtr *Value
+	var nilSlice []string
+	var nilMap map[string]int
+
+	s.mustSet(t, "conformance:nil:ptr", nilPtr, time.Minute)
+	s.mustSet(t, "conformance:nil:slice", nilSlice, time.Minute)
+	s.mustSet(t, "conformance:nil:map", nilMap, time.Minute)
+
+	ptr := &Value{}
+	if err := s.Get(s.ctx, "conformance:nil:ptr", &ptr); err != nil || ptr != nil {
+		t.Errorf("nil指针: Get() = %v, %v", ptr, err)
+	}
+	slice := []string{"x"}
+	if err := s.Get(s.ctx, "conformance:nil:slice", &slice); err != nil || slice != nil {
+		t.Errorf("nil切片: Get() = %v, %v", slice, err)
+	}
+	m := map[string]int{"x": 1}
+	if err := s.Get(s.ctx, "conformance:nil:map", &m); err != nil || m != nil {
+		t.Errorf("nil map: Get() = %v, %v", m, err)
+	}
+
+	// nil值是存在的键，而不是未命中
+	if !s.Exists(s.ctx, "conformance:nil:ptr") {
+		t.Error("nil值的键应存在")
+	}
+}
+
+func testTTL(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:ttl", "v", shortTTL)
+	if !s.Exists(s.ctx, "conformance:ttl") {
+		t.Fatal("TTL到期前键应存在")
+	}
+	s.advance(2 * shortTTL)
+	s.assertMissing(t, "conformance:ttl")
+}
+
+func testZeroTTL(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:zero", "v", 0)
+	s.advance(2 * shortTTL)
+	if !s.Exists(s.ctx, "conformance:zero") {
+		t.Error("TTL为0的键不应过期")
+	}
+}
+
+func testExpiresIn(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:expiresin", "v", time.Hour)
+	if err := s.ExpiresIn(s.ctx, "conformance:expiresin", shortTTL); err != nil {
+		t.Fatalf("ExpiresIn() error = %v", err)
+	}
+	s.advance(2 * shortTTL)
+	s.assertMissing(t, "conformance:expiresin")
+
+	if err := s.ExpiresIn(s.ctx, "conformance:expiresin", time.Minute); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("ExpiresIn() 不存在的键 error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+func testExpiresAtPast(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:expiresat", "v", time.Hour)
+	if err := s.ExpiresAt(s.ctx, "conformance:expiresat", time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("ExpiresAt() error = %v", err)
+	}
+	s.assertMissing(t, "conformance:expiresat")
+}
+
+func testGetSetMiss(t *testing.T, s *subject) {
+	calls := 0
+	var v Value
+	err := s.GetSet(s.ctx, "conformance:getset", time.Minute, &v, func(key string, obj any) error {
+		calls++
+		*obj.(*Value) = Value{ID: 7, Name: key}
+		return nil
+	})
+	if err != nil || calls != 1 || v.ID != 7 {
+		t.Fatalf("GetSet() = %+v, %v, 回调次数 %d", v, err, calls)
+	}
+
+	var stored Value
+	if err := s.Get(s.ctx, "conformance:getset", &stored); err != nil || stored.ID != 7 {
+		t.Errorf("GetSet 应写入回调的结果: %+v, %v", stored, err)
+	}
+}
+
+func testGetSetHit(t *testing.T, s *subject) {
+	s.mustSet(t, "conformance:getset:hit", "cached", time.Minute)
+
+	var v string
+	err := s.GetSet(s.ctx, "conformance:getset:hit", time.Minute, &v, func(key string, obj any) error {
+		t.Error("命中时不应调用回调")
+		return nil
+	})
+	if err != nil || v != "cached" {
+		t.Errorf("GetSet() = %q, %v", v, err)
+	}
+}
+
+func testGetSetCallbackError(t *testing.T, s *subject) {
+	boom := errors.New("loader failed")
+	var v string
+	err := s.GetSet(s.ctx, "conformance:getset:err", time.Minute, &v, func(key string, obj any) error {
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("GetSet() error = %v, want %v", err, boom)
+	}
+	s.assertMissing(t, "conformance:getset:err")
+}
+
+// testSetNX 后端实现 go_cache.SetNXCache 时验证仅在键不存在时写入
+func testSetNX(t *testing.T, s *subject) {
+	nx, ok := s.Cacher.(go_cache.SetNXCache)
+	if !ok {
+		t.Skip("后端未实现 SetNXCache")
+	}
+
+	ok, err := nx.SetNX(s.ctx, "conformance:setnx", "first", shortTTL)
+	if err != nil || !ok {
+		t.Fatalf("SetNX() 不存在的键 = %v, %v, want true, nil", ok, err)
+	}
+	ok, err = nx.SetNX(s.ctx, "conformance:setnx", "second", time.Hour)
+	if err != nil || ok {
+		t.Fatalf("SetNX() 已存在的键 = %v, %v, want false, nil", ok, err)
+	}
+	var v string
+	if err := s.Get(s.ctx, "conformance:setnx", &v); err != nil || v != "first" {
+		t.Errorf("Get() = %q, %v, want first", v, err)
+	}
+
+	// 过期的键视为不存在
+	s.advance(2 * shortTTL)
+	ok, err = nx.SetNX(s.ctx, "conformance:setnx", "third", time.Hour)
+	if err != nil || !ok {
+		t.Fatalf("SetNX() 过期的键 = %v, %v, want true, nil", ok, err)
+	}
+	if err := s.Get(s.ctx, "conformance:setnx", &v); err != nil || v != "third" {
+		t.Errorf("Get() = %q, %v, want third", v, err)
+	}
+}
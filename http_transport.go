@@ -0,0 +1,519 @@
+package go_cache
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// HTTPCacheStatusHeader CachingTransport 在响应中添加的头，值为 HTTPCacheHit、HTTPCacheRevalidated 或 HTTPCacheMiss
+const HTTPCacheStatusHeader = "X-Cache-Status"
+
+const (
+	// HTTPCacheHit 响应直接由缓存提供
+	HTTPCacheHit = "HIT"
+	// HTTPCacheRevalidated 缓存的响应经源站确认（304）后提供
+	HTTPCacheRevalidated = "REVALIDATED"
+	// HTTPCacheMiss 响应来自源站
+	HTTPCacheMiss = "MISS"
+)
+
+// heuristicFreshnessMax 启发式新鲜期的上限
+const heuristicFreshnessMax = 24 * time.Hour
+
+// cacheableByDefault 没有显式过期时间时可以按启发式新鲜期缓存的状态码（RFC 7231 6.1）
+var cacheableByDefault = map[int]bool{
+	http.StatusOK:                   true,
+	http.StatusNonAuthoritativeInfo: true,
+	http.StatusNoContent:            true,
+	http.StatusMultipleChoices:      true,
+	http.StatusMovedPermanently:     true,
+	http.StatusNotFound:             true,
+	http.StatusMethodNotAllowed:     true,
+	http.StatusGone:                 true,
+	http.StatusRequestURITooLong:    true,
+	http.StatusNotImplemented:       true,
+}
+
+// httpVaryIndex 一个地址的变体索引，Gen 在地址被失效后变化，使旧的变体不再可达
+type httpVaryIndex struct {
+	Gen  string
+	Vary []string
+}
+
+// httpCacheEntry 缓存的响应
+type httpCacheEntry struct {
+	Status       int
+	Header       map[string][]string
+	Body         []byte
+	RequestTime  time.Time // 发出请求的时间
+	ResponseTime time.Time // 收到响应的时间
+}
+
+// CachingTransportOption 缓存 RoundTripper 选项
+type CachingTransportOption func(*CachingTransport)
+
+// WithTransportBase 设置实际发送请求的 RoundTripper，默认 http.DefaultTransport
+func WithTransportBase(rt http.RoundTripper) CachingTransportOption {
+	return func(t *CachingTransport) {
+		t.base = rt
+	}
+}
+
+// WithTransportShared 作为共享缓存工作：不保存 private 的响应，优先使用 s-maxage，
+// 带 Authorization 的请求只保存显式允许（public、s-maxage 或 must-revalidate）的响应
+func WithTransportShared() CachingTransportOption {
+	return func(t *CachingTransport) {
+		t.shared = true
+	}
+}
+
+// WithTransportPrefix 设置缓存键的前缀，默认 "httpcache:"
+func WithTransportPrefix(prefix string) CachingTransportOption {
+	return func(t *CachingTransport) {
+		t.prefix = prefix
+	}
+}
+
+// WithTransportMaxBodySize 设置保存的响应体的最大字节数，默认10MiB，更大的响应不缓存
+func WithTransportMaxBodySize(n int64) CachingTransportOption {
+	return func(t *CachingTransport) {
+		if n > 0 {
+			t.maxBodySize = n
+		}
+	}
+}
+
+// WithTransportStaleRetention 设置带校验器（ETag、Last-Modified）的响应过期后继续保存以便再验证的时间，默认24小时
+func WithTransportStaleRetention(d time.Duration) CachingTransportOption {
+	return func(t *CachingTransport) {
+		if d >= 0 {
+			t.staleRetention = d
+		}
+	}
+}
+
+// WithTransportClock 设置计算新鲜度所用的时钟，默认使用系统时间
+func WithTransportClock(clock Clock) CachingTransportOption {
+	return func(t *CachingTransport) {
+		if clock != nil {
+			t.clock = clock
+		}
+	}
+}
+
+// CachingTransport 按 RFC 7234 缓存响应的 http.RoundTripper，用于缓存调用第三方接口的结果
+// 只缓存 GET 请求：按 Cache-Control、Expires 与启发式规则计算新鲜期，过期的响应使用 ETag 与
+// Last-Modified 向源站再验证，按响应的 Vary 为同一地址保存多个变体；请求的 Cache-Control
+// （no-store、no-cache、max-age、min-fresh、max-stale、only-if-cached）同样生效。
+// POST 等不安全方法的成功响应使对应地址的缓存失效。缓存读写失败时直接请求源站
+type CachingTransport struct {
+	cache          gsr.Cacher
+	base           http.RoundTripper
+	shared         bool
+	prefix         string
+	maxBodySize    int64
+	staleRetention time.Duration
+	clock          Clock
+}
+
+// NewCachingTransport 创建使用cache保存响应的 RoundTripper
+func NewCachingTransport(cache gsr.Cacher, opts ...CachingTransportOption) *CachingTransport {
+	t := &CachingTransport{
+		cache:          cache,
+		base:           http.DefaultTransport,
+		prefix:         "httpcache:",
+		maxBodySize:    10 << 20,
+		staleRetention: 24 * time.Hour,
+		clock:          realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Client 返回使用该 RoundTripper 的 http.Client
+func (t *CachingTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// parseCacheControl 解析 Cache-Control 头，指令名转为小写，值去掉引号
+func parseCacheControl(header http.Header) map[string]string {
+	directives := make(map[string]string)
+	for _, line := range header.Values("Cache-Control") {
+		for _, part := range strings.Split(line, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+			if name == "" {
+				continue
+			}
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
+
+// directiveSeconds 返回以秒为单位的指令值
+func directiveSeconds(directives map[string]string, name string) (time.Duration, bool) {
+	value, ok := directives[name]
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	return time.Duration(n) * time.Second, true
+}
+
+// varyHeaders 返回响应的 Vary 头中的字段名，含 "*" 时返回false
+func varyHeaders(header http.Header) ([]string, bool) {
+	var names []string
+	for _, line := range header.Values("Vary") {
+		for _, name := range strings.Split(line, ",") {
+			name = strings.TrimSpace(name)
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				names = append(names, http.CanonicalHeaderKey(name))
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, true
+}
+
+// indexKey 返回请求地址的变体索引的键
+func (t *CachingTransport) indexKey(req *http.Request) string {
+	return t.prefix + req.URL.String()
+}
+
+// entryKey 返回请求在变体索引下对应的响应的键
+func (t *CachingTransport) entryKey(req *http.Request, index *httpVaryIndex) string {
+	h := sha256.New()
+	for _, name := range index.Vary {
+		value := strings.Join(req.Header.Values(name), ",")
+		io.WriteString(h, name+":"+strconv.Itoa(len(value))+":"+value+"\n")
+	}
+	return t.prefix + index.Gen + ":" + hex.EncodeToString(h.Sum(nil)[:16]) + ":" + req.URL.String()
+}
+
+// lookup 读取请求对应的缓存响应，不存在时返回nil
+func (t *CachingTransport) lookup(ctx context.Context, req *http.Request) (*httpVaryIndex, *httpCacheEntry) {
+	var index httpVaryIndex
+	if err := t.cache.Get(ctx, t.indexKey(req), &index); err != nil {
+		return nil, nil
+	}
+	var entry httpCacheEntry
+	if err := t.cache.Get(ctx, t.entryKey(req, &index), &entry); err != nil {
+		return &index, nil
+	}
+	return &index, &entry
+}
+
+// freshness 返回响应的新鲜期
+// 响应没有有效的 Date 时以收到响应的时间代替（RFC 7234 4.2.1）
+func (t *CachingTransport) freshness(entry *httpCacheEntry) time.Duration {
+	header := http.Header(entry.Header)
+	directives := parseCacheControl(header)
+	if t.shared {
+		if d, ok := directiveSeconds(directives, "s-maxage"); ok {
+			return d
+		}
+	}
+	if d, ok := directiveSeconds(directives, "max-age"); ok {
+		return d
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = entry.ResponseTime
+	}
+	if expires := header.Get("Expires"); expires != "" {
+		at, err := http.ParseTime(expires)
+		if err != nil {
+			// 无效的 Expires 视为已过期
+			return 0
+		}
+		return max(at.Sub(date), 0)
+	}
+	// 启发式新鲜期：距最后修改时间的10%
+	if cacheableByDefault[entry.Status] {
+		if modified, err := http.ParseTime(header.Get("Last-Modified")); err == nil && modified.Before(date) {
+			return min(date.Sub(modified)/10, heuristicFreshnessMax)
+		}
+	}
+	return 0
+}
+
+// age 返回缓存响应的当前年龄（RFC 7234 4.2.3）
+func (t *CachingTransport) age(entry *httpCacheEntry) time.Duration {
+	header := http.Header(entry.Header)
+	var apparent time.Duration
+	if date, err := http.ParseTime(header.Get("Date")); err == nil {
+		apparent = max(entry.ResponseTime.Sub(date), 0)
+	}
+	var ageValue time.Duration
+	if seconds, err := strconv.ParseInt(header.Get("Age"), 10, 64); err == nil && seconds > 0 {
+		ageValue = time.Duration(seconds) * time.Second
+	}
+	corrected := ageValue + entry.ResponseTime.Sub(entry.RequestTime)
+	return max(apparent, corrected) + t.clock.Now().Sub(entry.ResponseTime)
+}
+
+// storable 判断响应能否保存
+func (t *CachingTransport) storable(req *http.Request, resp *http.Response) bool {
+	reqDirectives := parseCacheControl(req.Header)
+	directives := parseCacheControl(resp.Header)
+	if _, ok := reqDirectives["no-store"]; ok {
+		return false
+	}
+	if _, ok := directives["no-store"]; ok {
+		return false
+	}
+	if _, ok := varyHeaders(resp.Header); !ok {
+		return false
+	}
+	if t.shared {
+		if _, ok := directives["private"]; ok {
+			return false
+		}
+		if req.Header.Get("Authorization") != "" {
+			_, public := directives["public"]
+			_, sMaxAge := directives["s-maxage"]
+			_, mustRevalidate := directives["must-revalidate"]
+			if !public && !sMaxAge && !mustRevalidate {
+				return false
+			}
+		}
+	}
+	if resp.Header.Get("Expires") != "" || cacheableByDefault[resp.StatusCode] {
+		return true
+	}
+	_, maxAge := directives["max-age"]
+	_, sMaxAge := directives["s-maxage"]
+	_, public := directives["public"]
+	return maxAge || (t.shared && sMaxAge) || public
+}
+
+// storageTTL 返回响应在缓存中的保存时间，带校验器的响应在过期后继续保存以便再验证
+func (t *CachingTransport) storageTTL(entry *httpCacheEntry) time.Duration {
+	header := http.Header(entry.Header)
+	remaining := t.freshness(entry) - t.age(entry)
+	if header.Get("ETag") != "" || header.Get("Last-Modified") != "" {
+		return max(remaining, 0) + t.staleRetention
+	}
+	return remaining
+}
+
+// store 保存响应，返回是否保存
+func (t *CachingTransport) store(ctx context.Context, req *http.Request, index *httpVaryIndex, entry *httpCacheEntry) bool {
+	ttl := t.storageTTL(entry)
+	if ttl <= 0 {
+		return false
+	}
+	vary, _ := varyHeaders(http.Header(entry.Header))
+	if index == nil {
+		gen, err := newLeaseToken()
+		if err != nil {
+			return false
+		}
+		index = &httpVaryIndex{Gen: gen[:8]}
+	}
+	index.Vary = vary
+	if err := t.cache.Set(ctx, t.indexKey(req), *index, ttl); err != nil {
+		return false
+	}
+	return t.cache.Set(ctx, t.entryKey(req, index), *entry, ttl) == nil
+}
+
+// response 由缓存的响应构造返回给调用方的响应
+func (t *CachingTransport) response(req *http.Request, entry *httpCacheEntry, status string) *http.Response {
+	header := http.Header(entry.Header).Clone()
+	header.Set("Age", strconv.FormatInt(int64(t.age(entry)/time.Second), 10))
+	header.Set(HTTPCacheStatusHeader, status)
+	return &http.Response{
+		Status:        strconv.Itoa(entry.Status) + " " + http.StatusText(entry.Status),
+		StatusCode:    entry.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(entry.Body)),
+		ContentLength: int64(len(entry.Body)),
+		Request:       req,
+	}
+}
+
+// usable 判断缓存的响应能否不经再验证直接使用
+func (t *CachingTransport) usable(req *http.Request, entry *httpCacheEntry) bool {
+	reqDirectives := parseCacheControl(req.Header)
+	directives := parseCacheControl(http.Header(entry.Header))
+	if _, ok := reqDirectives["no-cache"]; ok {
+		return false
+	}
+	if req.Header.Get("Pragma") == "no-cache" && req.Header.Get("Cache-Control") == "" {
+		return false
+	}
+	if _, ok := directives["no-cache"]; ok {
+		return false
+	}
+
+	age := t.age(entry)
+	if maxAge, ok := directiveSeconds(reqDirectives, "max-age"); ok && age > maxAge {
+		return false
+	}
+	lifetime := t.freshness(entry)
+	if minFresh, ok := directiveSeconds(reqDirectives, "min-fresh"); ok {
+		lifetime -= minFresh
+	}
+	if age < lifetime {
+		return true
+	}
+
+	// 过期的响应只在请求允许（max-stale）且响应没有要求再验证时使用
+	_, mustRevalidate := directives["must-revalidate"]
+	_, proxyRevalidate := directives["proxy-revalidate"]
+	if mustRevalidate || (t.shared && proxyRevalidate) {
+		return false
+	}
+	value, ok := reqDirectives["max-stale"]
+	if !ok {
+		return false
+	}
+	if value == "" {
+		return true
+	}
+	maxStale, ok := directiveSeconds(reqDirectives, "max-stale")
+	return ok && age-lifetime <= maxStale
+}
+
+// invalidate 使请求地址的缓存失效
+func (t *CachingTransport) invalidate(ctx context.Context, req *http.Request) {
+	_ = t.cache.Del(ctx, t.indexKey(req))
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *CachingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := t.base.RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 {
+			t.invalidate(ctx, req)
+		}
+		return resp, err
+	}
+	// HEAD、范围请求与调用方自己的条件请求不经过缓存
+	if req.Method == http.MethodHead || req.Header.Get("Range") != "" ||
+		req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		return t.base.RoundTrip(req)
+	}
+	reqDirectives := parseCacheControl(req.Header)
+	if _, ok := reqDirectives["no-store"]; ok {
+		return t.base.RoundTrip(req)
+	}
+
+	index, entry := t.lookup(ctx, req)
+	if entry != nil && t.usable(req, entry) {
+		return t.response(req, entry, HTTPCacheHit), nil
+	}
+	if _, ok := reqDirectives["only-if-cached"]; ok {
+		return &http.Response{
+			Status:     "504 Gateway Timeout",
+			StatusCode: http.StatusGatewayTimeout,
+			Proto:      "HTTP/1.1",
+			ProtoMajor: 1,
+			ProtoMinor: 1,
+			Header:     http.Header{HTTPCacheStatusHeader: {HTTPCacheMiss}},
+			Body:       http.NoBody,
+			Request:    req,
+		}, nil
+	}
+
+	outgoing := req
+	if entry != nil {
+		// 使用校验器向源站再验证
+		header := http.Header(entry.Header)
+		etag, modified := header.Get("ETag"), header.Get("Last-Modified")
+		if etag != "" || modified != "" {
+			outgoing = req.Clone(ctx)
+			if etag != "" {
+				outgoing.Header.Set("If-None-Match", etag)
+			}
+			if modified != "" {
+				outgoing.Header.Set("If-Modified-Since", modified)
+			}
+		}
+	}
+
+	requestTime := t.clock.Now()
+	resp, err := t.base.RoundTrip(outgoing)
+	if err != nil {
+		return nil, err
+	}
+	responseTime := t.clock.Now()
+
+	if resp.StatusCode == http.StatusNotModified && outgoing != req {
+		resp.Body.Close()
+		// 用304响应的头更新缓存的响应（RFC 7234 4.3.4）
+		entry.Header = http.Header(entry.Header).Clone()
+		for name, values := range resp.Header {
+			if name == "Content-Length" {
+				continue
+			}
+			entry.Header[name] = values
+		}
+		entry.RequestTime, entry.ResponseTime = requestTime, responseTime
+		t.store(ctx, req, index, entry)
+		return t.response(req, entry, HTTPCacheRevalidated), nil
+	}
+
+	resp.Header.Set(HTTPCacheStatusHeader, HTTPCacheMiss)
+	if !t.storable(req, resp) {
+		if entry != nil {
+			// 源站返回了不可缓存的新响应，旧响应不再有效
+			t.invalidate(ctx, req)
+		}
+		return resp, nil
+	}
+
+	// 读取不超过上限的响应体，超过时不缓存，原样返回
+	body, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if int64(len(body)) > t.maxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	header := resp.Header.Clone()
+	header.Del(HTTPCacheStatusHeader)
+	stored := &httpCacheEntry{
+		Status:       resp.StatusCode,
+		Header:       header,
+		Body:         body,
+		RequestTime:  requestTime,
+		ResponseTime: responseTime,
+	}
+	t.store(ctx, req, index, stored)
+	return resp, nil
+}
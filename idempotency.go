@@ -0,0 +1,142 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultIdempotencyKeyPrefix 幂等记录在缓存中的默认键前缀
+const DefaultIdempotencyKeyPrefix = "idempotency:"
+
+// ErrRequestInProgress 相同幂等键的请求仍在执行，调用方可稍后重试（如返回 HTTP 409）
+var ErrRequestInProgress = errors.New("idempotent request in progress")
+
+// idempotencyRecord 缓存中保存的幂等记录
+// Done 为false时表示请求正在执行，Data 为空
+type idempotencyRecord struct {
+	Done bool
+	Data []byte
+}
+
+// IdempotencyOption 幂等选项
+type IdempotencyOption func(*Idempotency)
+
+// WithIdempotencyLockTTL 设置执行中标记的有效期，默认1分钟
+// 执行超过该时间（如进程崩溃）后标记过期，相同幂等键的请求会再次执行
+func WithIdempotencyLockTTL(d time.Duration) IdempotencyOption {
+	return func(i *Idempotency) {
+		if d > 0 {
+			i.lockTTL = d
+		}
+	}
+}
+
+// WithIdempotencySerializer 设置结果的序列化器
+func WithIdempotencySerializer(s serializer.Serializer) IdempotencyOption {
+	return func(i *Idempotency) {
+		i.serializer = s
+	}
+}
+
+// WithIdempotencyKeyPrefix 设置幂等记录在缓存中的键前缀，默认 DefaultIdempotencyKeyPrefix
+func WithIdempotencyKeyPrefix(prefix string) IdempotencyOption {
+	return func(i *Idempotency) {
+		i.prefix = prefix
+	}
+}
+
+// Idempotency 基于 SetNX 的幂等执行，用于API请求的重试
+// 第一个请求写入执行中标记后执行回调函数并保存结果，
+// 之后相同幂等键的请求直接返回保存的结果而不会重复产生副作用
+type Idempotency struct {
+	cache      gsr.Cacher
+	lockTTL    time.Duration
+	serializer serializer.Serializer
+	prefix     string
+}
+
+// NewIdempotency 创建使用cache保存幂等记录的幂等执行，cache 需实现 SetNXCache
+// 默认使用gob序列化器
+func NewIdempotency(cache gsr.Cacher, opts ...IdempotencyOption) *Idempotency {
+	i := &Idempotency{
+		cache:      cache,
+		lockTTL:    time.Minute,
+		serializer: cache_value.GetDefaultSerializer(), // 默认使用gob
+		prefix:     DefaultIdempotencyKeyPrefix,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(i)
+	}
+
+	return i
+}
+
+// Do 以幂等键key执行fun，结果保存在obj中并在缓存中保留ttl
+// 已有保存的结果时解码到obj并返回 replayed 为true，不调用fun；
+// 相同幂等键的请求正在执行时返回 ErrRequestInProgress；
+// fun 返回错误时删除执行中标记，之后的重试会再次执行。
+// 底层缓存不支持 SetNX 时返回 ErrNotSupported
+func (i *Idempotency) Do(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) (replayed bool, err error) {
+	nx, ok := i.cache.(SetNXCache)
+	if !ok {
+		return false, ErrNotSupported
+	}
+	cacheKey := i.prefix + key
+
+	// 读取记录与写入标记之间记录可能过期，此时重新尝试写入
+	for attempt := 0; attempt < 2; attempt++ {
+		acquired, err := nx.SetNX(ctx, cacheKey, idempotencyRecord{}, i.lockTTL)
+		if err != nil {
+			return false, err
+		}
+		if acquired {
+			return false, i.run(ctx, key, ttl, obj, fun)
+		}
+
+		var record idempotencyRecord
+		if err := i.cache.Get(ctx, cacheKey, &record); err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				continue
+			}
+			return false, err
+		}
+		if !record.Done {
+			return false, ErrRequestInProgress
+		}
+		if err := i.serializer.Decode(record.Data, obj); err != nil {
+			return false, serializationError(fmt.Errorf("idempotency %s: %w", key, err))
+		}
+		return true, nil
+	}
+	return false, ErrRequestInProgress
+}
+
+// run 执行回调函数并保存结果
+func (i *Idempotency) run(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	cacheKey := i.prefix + key
+	if err := loaderOf(i.cache).call(ctx, key, obj, fun); err != nil {
+		// 失败的请求允许重试，删除标记时的错误不影响返回的结果
+		_ = i.cache.Del(ctx, cacheKey)
+		return err
+	}
+
+	value, err := pointee(obj)
+	if err != nil {
+		_ = i.cache.Del(ctx, cacheKey)
+		return err
+	}
+	data, err := i.serializer.Encode(value)
+	if err != nil {
+		_ = i.cache.Del(ctx, cacheKey)
+		return serializationError(err)
+	}
+	return i.cache.Set(ctx, cacheKey, idempotencyRecord{Done: true, Data: data}, ttl)
+}
@@ -0,0 +1,171 @@
+package go_cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// ErrKeyCollision 哈希后的键保存的是另一个原始键的值
+// 同时满足 errors.Is(err, ErrKeyNotFound)，调用方可以按未命中处理
+var ErrKeyCollision = errors.New("hashed key collision")
+
+// KeyHashMode 键的哈希方式
+type KeyHashMode int
+
+const (
+	// KeyHashLong 只哈希长度超过上限的键
+	KeyHashLong KeyHashMode = iota
+	// KeyHashAlways 哈希所有键，用于键由用户输入组成、基数不可控的场景
+	KeyHashAlways
+)
+
+// hashedEntry 哈希后的键中保存的值，Key 为原始键，用于检测冲突
+type hashedEntry struct {
+	Key  string
+	Data []byte
+}
+
+// KeyHashingOption 键哈希选项
+type KeyHashingOption func(*KeyHashingCache)
+
+// WithKeyHashingMaxLength 设置 KeyHashLong 模式下不哈希的最大键长度（字节），默认200
+func WithKeyHashingMaxLength(n int) KeyHashingOption {
+	return func(h *KeyHashingCache) {
+		if n > 0 {
+			h.maxLength = n
+		}
+	}
+}
+
+// WithKeyHashingPrefixLength 设置哈希后的键保留的原始键前缀长度（字节），默认32，0表示不保留
+func WithKeyHashingPrefixLength(n int) KeyHashingOption {
+	return func(h *KeyHashingCache) {
+		if n >= 0 {
+			h.prefixLength = n
+		}
+	}
+}
+
+// WithKeyHashingSerializer 设置哈希后的键中值的序列化器
+func WithKeyHashingSerializer(s serializer.Serializer) KeyHashingOption {
+	return func(h *KeyHashingCache) {
+		h.serializer = s
+	}
+}
+
+// KeyHashingCache 在写入底层缓存之前哈希过长或基数过高的键
+// 哈希后的键为 "可读前缀#SHA-256前128位"，便于在后端按前缀排查；
+// 哈希后的键中同时保存原始键，读取时发现原始键不一致返回 ErrKeyCollision。
+// 未哈希的键原样读写，哈希后的键中的值使用序列化器编码
+type KeyHashingCache struct {
+	cache        gsr.Cacher
+	mode         KeyHashMode
+	maxLength    int
+	prefixLength int
+	serializer   serializer.Serializer
+}
+
+// NewKeyHashing 返回按mode哈希键的缓存，默认使用gob序列化器
+func NewKeyHashing(cache gsr.Cacher, mode KeyHashMode, opts ...KeyHashingOption) *KeyHashingCache {
+	h := &KeyHashingCache{
+		cache:        cache,
+		mode:         mode,
+		maxLength:    200,
+		prefixLength: 32,
+		serializer:   cache_value.GetDefaultSerializer(), // 默认使用gob
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	return h
+}
+
+// Key 返回键在底层缓存中的实际键，以及是否经过哈希
+func (h *KeyHashingCache) Key(key string) (string, bool) {
+	if h.mode != KeyHashAlways && len(key) <= h.maxLength {
+		return key, false
+	}
+	sum := sha256.Sum256([]byte(key))
+	prefix := key[:min(len(key), h.prefixLength)]
+	// 不截断多字节字符
+	for len(prefix) > 0 && !utf8.ValidString(prefix) {
+		prefix = prefix[:len(prefix)-1]
+	}
+	return prefix + "#" + hex.EncodeToString(sum[:16]), true
+}
+
+// Exists 判断键是否存在，哈希后的键不检查冲突
+func (h *KeyHashingCache) Exists(ctx context.Context, key string) bool {
+	k, _ := h.Key(key)
+	return h.cache.Exists(ctx, k)
+}
+
+func (h *KeyHashingCache) Get(ctx context.Context, key string, obj any) error {
+	k, hashed := h.Key(key)
+	if !hashed {
+		return h.cache.Get(ctx, k, obj)
+	}
+
+	var entry hashedEntry
+	if err := h.cache.Get(ctx, k, &entry); err != nil {
+		return err
+	}
+	if entry.Key != key {
+		return fmt.Errorf("%w: %w: %q and %q both hash to %q", ErrKeyNotFound, ErrKeyCollision, key, entry.Key, k)
+	}
+	if err := h.serializer.Decode(entry.Data, obj); err != nil {
+		return serializationError(err)
+	}
+	return nil
+}
+
+func (h *KeyHashingCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	k, hashed := h.Key(key)
+	if !hashed {
+		return h.cache.Set(ctx, k, value, ttl)
+	}
+	data, err := h.serializer.Encode(value)
+	if err != nil {
+		return serializationError(err)
+	}
+	return h.cache.Set(ctx, k, hashedEntry{Key: key, Data: data}, ttl)
+}
+
+// GetSet 获取缓存，未命中（包括键冲突）时调用回调函数并写入，冲突时覆盖另一个原始键的值
+func (h *KeyHashingCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	k, hashed := h.Key(key)
+	if !hashed {
+		// 使用内层缓存传入的obj，设置了超时时该obj为临时对象
+		return h.cache.GetSet(ctx, k, ttl, obj, func(_ string, obj any) error {
+			return fun(key, obj)
+		})
+	}
+	return loaderOf(h.cache).getSet(ctx, h, key, ttl, obj, fun)
+}
+
+func (h *KeyHashingCache) Del(ctx context.Context, key string) error {
+	k, _ := h.Key(key)
+	return h.cache.Del(ctx, k)
+}
+
+func (h *KeyHashingCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	k, _ := h.Key(key)
+	return h.cache.ExpiresAt(ctx, k, expiresAt)
+}
+
+func (h *KeyHashingCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	k, _ := h.Key(key)
+	return h.cache.ExpiresIn(ctx, k, ttl)
+}
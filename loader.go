@@ -0,0 +1,128 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ErrLoaderTimeout 回调函数在超时时间内没有返回
+var ErrLoaderTimeout = fmt.Errorf("%w: loader timed out", ErrTimeout)
+
+// LoaderPanicError 回调函数发生panic时 GetSet 返回的错误
+type LoaderPanicError struct {
+	Key   string
+	Value any    // recover() 得到的值
+	Stack []byte // 发生panic时的调用栈
+}
+
+func (e *LoaderPanicError) Error() string {
+	return fmt.Sprintf("loader panic for key %q: %v", e.Key, e.Value)
+}
+
+// LoaderFailureHook 回调函数返回错误、panic或超时时调用，可用于记录日志与指标
+type LoaderFailureHook func(key string, err error)
+
+// loaderConfig GetSet 调用回调函数的方式
+type loaderConfig struct {
+	timeout   time.Duration
+	onFailure LoaderFailureHook
+}
+
+// loaderSource 由配置了回调函数调用方式的缓存实现，包装器据此沿用内层缓存的超时与失败回调
+type loaderSource interface {
+	loaderSettings() loaderConfig
+}
+
+// loaderOf 返回cache的回调函数调用方式，cache 没有配置时只把panic转换为错误
+func loaderOf(cache gsr.Cacher) loaderConfig {
+	if s, ok := cache.(loaderSource); ok {
+		return s.loaderSettings()
+	}
+	return loaderConfig{}
+}
+
+// getSet 各缓存 GetSet 的共同实现：先从cache读取，未命中或 WithForceRefresh 时调用回调函数并把结果写入cache
+// 读写经由cache自身的 Get 与 Set，包装器的键转换、统计等照常生效
+func (l loaderConfig) getSet(ctx context.Context, cache gsr.Cacher, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	// 先尝试从缓存获取，WithForceRefresh 时直接调用回调函数
+	if !forceRefresh(ctx) && cache.Get(ctx, key, obj) == nil {
+		// 缓存命中，直接返回
+		return nil
+	}
+
+	// 缓存未命中，调用回调函数（panic会被转换为错误）
+	if err := l.call(ctx, key, obj, fun); err != nil {
+		return err
+	}
+
+	// 获取obj指向的实际值并存入缓存
+	value, err := pointee(obj)
+	if err != nil {
+		return err
+	}
+	return cache.Set(ctx, key, value, ttl)
+}
+
+// call 调用回调函数，panic会被转换为 *LoaderPanicError
+// 设置了超时时回调函数在单独的goroutine中写入临时对象，成功后才复制到obj，
+// 超时或ctx取消后返回的回调函数不会再修改obj
+func (l loaderConfig) call(ctx context.Context, key string, obj any, fun gsr.CacheCallback) error {
+	var err error
+	if l.timeout <= 0 {
+		err = safeLoad(key, obj, fun)
+	} else {
+		err = l.callWithTimeout(ctx, key, obj, fun)
+	}
+	if err != nil && l.onFailure != nil {
+		l.onFailure(key, err)
+	}
+	return err
+}
+
+// callWithTimeout 在超时时间内等待回调函数返回
+func (l loaderConfig) callWithTimeout(ctx context.Context, key string, obj any, fun gsr.CacheCallback) error {
+	objValue := reflect.ValueOf(obj)
+	if objValue.Kind() != reflect.Ptr || objValue.IsNil() {
+		// 无法创建临时对象，退化为直接调用
+		return safeLoad(key, obj, fun)
+	}
+
+	tmp := reflect.New(objValue.Elem().Type())
+	tmp.Elem().Set(objValue.Elem())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- safeLoad(key, tmp.Interface(), fun)
+	}()
+
+	timer := time.NewTimer(l.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			return err
+		}
+		objValue.Elem().Set(tmp.Elem())
+		return nil
+	case <-timer.C:
+		return fmt.Errorf("%w: key %q after %v", ErrLoaderTimeout, key, l.timeout)
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// safeLoad 调用回调函数并将panic转换为错误
+func safeLoad(key string, obj any, fun gsr.CacheCallback) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &LoaderPanicError{Key: key, Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return fun(key, obj)
+}
@@ -0,0 +1,300 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ErrLoadShed 负载保护期间读操作被跳过，按未命中处理，满足 errors.Is(err, ErrKeyNotFound)
+var ErrLoadShed = fmt.Errorf("%w: load shedding", ErrKeyNotFound)
+
+// criticalWriteKey 标记关键写入的context键
+type criticalWriteKey struct{}
+
+// WithCriticalWrite 标记context中的写入为关键写入，负载保护期间不会被丢弃
+func WithCriticalWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, criticalWriteKey{}, true)
+}
+
+// isCriticalWrite 判断context中的写入是否为关键写入
+func isCriticalWrite(ctx context.Context) bool {
+	critical, _ := ctx.Value(criticalWriteKey{}).(bool)
+	return critical
+}
+
+// LoadShedOption 负载保护选项
+type LoadShedOption func(*LoadShedder)
+
+// WithLoadShedWindow 设置计算p99所用的最近样本数，默认1000
+func WithLoadShedWindow(n int) LoadShedOption {
+	return func(l *LoadShedder) {
+		if n > 0 {
+			l.window = n
+		}
+	}
+}
+
+// WithLoadShedRecoverThreshold 设置恢复阈值，p99低于该值时退出负载保护
+// 默认为触发阈值的80%，用于避免在阈值附近反复切换
+func WithLoadShedRecoverThreshold(d time.Duration) LoadShedOption {
+	return func(l *LoadShedder) {
+		l.recoverThreshold = d
+	}
+}
+
+// WithLoadShedProbeInterval 设置负载保护期间放行探测请求的间隔，默认100ms
+// 探测请求用于采集后端延迟，判断是否可以恢复
+func WithLoadShedProbeInterval(d time.Duration) LoadShedOption {
+	return func(l *LoadShedder) {
+		l.probeInterval = d
+	}
+}
+
+// WithLoadShedMinSamples 设置计算p99所需的最少样本数，默认20
+func WithLoadShedMinSamples(n int) LoadShedOption {
+	return func(l *LoadShedder) {
+		if n > 0 {
+			l.minSamples = n
+		}
+	}
+}
+
+// WithLoadShedDegradation 将读操作与被跳过、被丢弃的操作记录到降级统计
+func WithLoadShedDegradation(tracker *DegradationTracker) LoadShedOption {
+	return func(l *LoadShedder) {
+		l.degradation = tracker
+	}
+}
+
+// LoadShedStats 负载保护统计
+type LoadShedStats struct {
+	Shedding   bool          // 当前是否处于负载保护
+	P99        time.Duration // 最近一次计算的p99延迟
+	ShedReads  uint64        // 被跳过的读操作数
+	ShedWrites uint64        // 被丢弃的写操作数
+}
+
+// LoadShedder 自适应负载保护缓存
+// 跟踪后端操作的p99延迟，超过阈值后将读操作视为未命中（不访问后端），
+// 并丢弃非关键写入；延迟恢复后回到正常模式。删除与过期时间操作始终会执行
+type LoadShedder struct {
+	cache gsr.Cacher
+
+	threshold        time.Duration
+	recoverThreshold time.Duration
+	window           int
+	minSamples       int
+	probeInterval    time.Duration
+	degradation      *DegradationTracker
+
+	mu      sync.Mutex
+	samples []time.Duration
+	next    int
+	count   int
+	pending int
+	p99     time.Duration
+
+	shedding   atomic.Bool
+	lastProbe  atomic.Int64
+	shedReads  atomic.Uint64
+	shedWrites atomic.Uint64
+}
+
+// NewLoadShedder 创建负载保护缓存，p99延迟超过threshold时开始负载保护
+func NewLoadShedder(cache gsr.Cacher, threshold time.Duration, opts ...LoadShedOption) *LoadShedder {
+	l := &LoadShedder{
+		cache:         cache,
+		threshold:     threshold,
+		window:        1000,
+		minSamples:    20,
+		probeInterval: 100 * time.Millisecond,
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	if l.recoverThreshold <= 0 || l.recoverThreshold > l.threshold {
+		l.recoverThreshold = l.threshold * 8 / 10
+	}
+	l.samples = make([]time.Duration, l.window)
+
+	return l
+}
+
+// ShedStats 返回负载保护统计
+func (l *LoadShedder) ShedStats() LoadShedStats {
+	l.mu.Lock()
+	p99 := l.p99
+	l.mu.Unlock()
+
+	return LoadShedStats{
+		Shedding:   l.shedding.Load(),
+		P99:        p99,
+		ShedReads:  l.shedReads.Load(),
+		ShedWrites: l.shedWrites.Load(),
+	}
+}
+
+// Shedding 返回当前是否处于负载保护
+func (l *LoadShedder) Shedding() bool {
+	return l.shedding.Load()
+}
+
+func (l *LoadShedder) Exists(ctx context.Context, key string) bool {
+	if !l.allowRead() {
+		return false
+	}
+	start := time.Now()
+	exists := l.cache.Exists(ctx, key)
+	l.observe(time.Since(start))
+	return exists
+}
+
+func (l *LoadShedder) Get(ctx context.Context, key string, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	if !l.allowRead() {
+		return ErrLoadShed
+	}
+	start := time.Now()
+	err := l.cache.Get(ctx, key, obj)
+	l.observe(time.Since(start))
+	return err
+}
+
+func (l *LoadShedder) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	if !l.allowWrite(ctx) {
+		return nil
+	}
+	start := time.Now()
+	err := l.cache.Set(ctx, key, value, ttl)
+	l.observe(time.Since(start))
+	return err
+}
+
+// SetWithPriority 按优先级写入缓存，PriorityHigh 及以上的写入视为关键写入
+// 底层缓存实现了 PrioritySetter 时会透传优先级
+func (l *LoadShedder) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	if noStore(ctx) {
+		return nil
+	}
+	if priority >= PriorityHigh {
+		ctx = WithCriticalWrite(ctx)
+	}
+	if !l.allowWrite(ctx) {
+		return nil
+	}
+	start := time.Now()
+	var err error
+	if ps, ok := l.cache.(PrioritySetter); ok {
+		err = ps.SetWithPriority(ctx, key, value, ttl, priority)
+	} else {
+		err = l.cache.Set(ctx, key, value, ttl)
+	}
+	l.observe(time.Since(start))
+	return err
+}
+
+func (l *LoadShedder) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	return loaderOf(l.cache).getSet(ctx, l, key, ttl, obj, fun)
+}
+
+func (l *LoadShedder) Del(ctx context.Context, key string) error {
+	// 删除用于失效数据，负载保护期间也必须执行
+	start := time.Now()
+	err := l.cache.Del(ctx, key)
+	l.observe(time.Since(start))
+	return err
+}
+
+func (l *LoadShedder) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return l.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (l *LoadShedder) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return l.cache.ExpiresIn(ctx, key, ttl)
+}
+
+// allowRead 判断读操作是否访问后端，负载保护期间只放行探测请求
+func (l *LoadShedder) allowRead() bool {
+	if !l.shedding.Load() || l.probe() {
+		l.degradation.RecordRequest()
+		return true
+	}
+	l.shedReads.Add(1)
+	l.degradation.RecordDegraded(DegradationShedRead)
+	return false
+}
+
+// allowWrite 判断写操作是否执行，负载保护期间只执行关键写入与探测请求
+func (l *LoadShedder) allowWrite(ctx context.Context) bool {
+	if !l.shedding.Load() || isCriticalWrite(ctx) || l.probe() {
+		l.degradation.RecordRequest()
+		return true
+	}
+	l.shedWrites.Add(1)
+	l.degradation.RecordDegraded(DegradationShedWrite)
+	return false
+}
+
+// probe 每个探测间隔最多放行一个请求
+func (l *LoadShedder) probe() bool {
+	now := time.Now().UnixNano()
+	last := l.lastProbe.Load()
+	if now-last < int64(l.probeInterval) {
+		return false
+	}
+	return l.lastProbe.CompareAndSwap(last, now)
+}
+
+// observe 记录一次后端操作延迟，并按需重新计算p99与切换模式
+func (l *LoadShedder) observe(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.samples[l.next] = d
+	l.next = (l.next + 1) % len(l.samples)
+	l.count = min(l.count+1, len(l.samples))
+	l.pending++
+
+	// 正常模式下每累积十分之一窗口的新样本才重新计算，负载保护期间每个探测样本都重新计算
+	shedding := l.shedding.Load()
+	if l.count < l.minSamples || (!shedding && l.pending < max(len(l.samples)/10, 1)) {
+		return
+	}
+	l.pending = 0
+
+	recent := make([]time.Duration, l.count)
+	copy(recent, l.samples[:l.count])
+	slices.Sort(recent)
+	l.p99 = recent[(len(recent)-1)*99/100]
+
+	switch {
+	case !shedding && l.p99 > l.threshold:
+		// 进入负载保护后等待一个探测间隔再放行探测请求
+		l.lastProbe.Store(time.Now().UnixNano())
+		l.shedding.Store(true)
+		l.resetSamplesLocked()
+	case shedding && l.p99 < l.recoverThreshold:
+		l.shedding.Store(false)
+		l.resetSamplesLocked()
+	}
+}
+
+// resetSamplesLocked 切换模式时清空样本，使新模式只根据切换后的延迟做判断
+func (l *LoadShedder) resetSamplesLocked() {
+	l.next = 0
+	l.count = 0
+	l.pending = 0
+}
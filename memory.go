@@ -5,115 +5,910 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/muleiwu/gsr"
 	"github.com/patrickmn/go-cache"
 )
 
+// ErrEntryTooLarge 单个条目的大小超过了内存上限
+var ErrEntryTooLarge = errors.New("entry exceeds memory limit")
+
 type Memory struct {
-	cache *cache.Cache
+	cache             *cache.Cache
+	stats             *StatsCollector
+	sizer             Sizer
+	clock             Clock
+	defaultExpiration time.Duration
+	loader            loaderConfig
+	strict            bool
+	schemas           *SchemaRegistry
+
+	// mu 保护以下内存占用统计字段
+	mu          sync.Mutex
+	entries     map[string]*memoryEntry
+	policies    [priorityLevels]EvictionPolicy // 各优先级的淘汰策略，超出内存上限时从低优先级开始淘汰
+	newPolicy   func() EvictionPolicy
+	cost        CostFunc
+	admission   AdmissionPolicy
+	candidate   *memoryEntry // 最近写入的新键，evictLocked 时由接纳策略决定是否保留
+	trackAccess bool         // 淘汰策略需要在 Get 命中时更新
+	used        int64
+	maxBytes    int64
+
+	expiredCount atomic.Uint64 // 过期清理的条目数
+	evictedCount atomic.Uint64 // 因超出内存上限被淘汰的条目数
+
+	// evictedMu 保护janitor过期清理后待同步的条目与待回调的移除
+	evictedMu        sync.Mutex
+	evicted          []*memoryEntry
+	pendingEvictions []eviction
+
+	// 软引用值的大小下限与堆占用上限
+	softMinSize   int64
+	softHeapLimit uint64
+
+	bus memoryBus // Publish 与 Subscribe 使用的进程内消息总线
+
+	onEvict           EvictionCallback
+	expiry            *ExpiryListeners // 过期移除的键报告给该注册表
+	dispatching       atomic.Bool      // 正在执行移除回调
+	dispatchScheduled atomic.Bool      // 已启动执行janitor回调的goroutine
+}
+
+// memoryEntry 内存缓存中实际存储的条目
+type memoryEntry struct {
+	key      string
+	value    any
+	size     int64
+	cost     int64
+	priority Priority
+	soft     *softValue // 以软引用方式保存时value为nil
+	stored   bool       // 是否计入内存占用统计与淘汰策略，持有mu时访问
+	removed  atomic.Bool
+
+	// expiresAt 按缓存时钟计算的过期时间（UnixNano），0表示不过期
+	expiresAt atomic.Int64
 }
 
-func NewMemory(defaultExpiration, cleanupInterval time.Duration) *Memory {
-	return &Memory{cache: cache.New(defaultExpiration, cleanupInterval)}
+// expired 判断条目在now时是否已过期
+func (e *memoryEntry) expired(now time.Time) bool {
+	expiresAt := e.expiresAt.Load()
+	return expiresAt > 0 && now.UnixNano() >= expiresAt
+}
+
+// MemoryOption 内存缓存选项
+type MemoryOption func(*Memory)
+
+// WithMemoryStats 设置内存缓存的统计收集器
+// 可用于多个缓存实例共享同一个收集器
+func WithMemoryStats(s *StatsCollector) MemoryOption {
+	return func(m *Memory) {
+		m.stats = s
+	}
+}
+
+// WithMemorySizer 设置计算条目大小的方法，默认使用 EstimateSize
+func WithMemorySizer(s Sizer) MemoryOption {
+	return func(m *Memory) {
+		m.sizer = s
+	}
+}
+
+// WithMemoryMaxBytes 设置内存占用上限（字节）
+// 超出上限时先清理过期条目，再从低优先级开始按淘汰策略（默认按写入顺序）淘汰条目，n <= 0 表示不限制
+func WithMemoryMaxBytes(n int64) MemoryOption {
+	return func(m *Memory) {
+		m.maxBytes = n
+	}
+}
+
+// WithMemoryClock 设置判断过期所用的时钟，默认使用系统时间
+// 使用自定义时钟时过期完全由该时钟决定，janitor不会清理过期条目，需要手动调用 DeleteExpired
+func WithMemoryClock(clock Clock) MemoryOption {
+	return func(m *Memory) {
+		if clock != nil {
+			m.clock = clock
+		}
+	}
+}
+
+// WithMemoryLoaderTimeout 设置 GetSet 等待回调函数的超时时间，超时返回 ErrLoaderTimeout，d <= 0 表示不限制
+func WithMemoryLoaderTimeout(d time.Duration) MemoryOption {
+	return func(m *Memory) {
+		m.loader.timeout = d
+	}
+}
+
+// WithMemoryLoaderHook 设置 GetSet 回调函数失败（返回错误、panic或超时）时的回调
+func WithMemoryLoaderHook(hook LoaderFailureHook) MemoryOption {
+	return func(m *Memory) {
+		m.loader.onFailure = hook
+	}
+}
+
+// WithMemoryStrictMode 开启严格模式，ttl <= 0 的写入与设置过期时间返回 ErrInvalidTTL
+// 默认情况下 ttl <= 0 的写入视为永不过期
+func WithMemoryStrictMode() MemoryOption {
+	return func(m *Memory) {
+		m.strict = true
+	}
+}
+
+// WithMemorySchemas 设置键模式注册表，严格模式下的写入与设置过期时间按注册表检查
+func WithMemorySchemas(registry *SchemaRegistry) MemoryOption {
+	return func(m *Memory) {
+		m.schemas = registry
+	}
+}
+
+// NewMemory 创建内存缓存实例
+// 内存占用统计依赖 cleanupInterval 定期清理过期条目；cleanupInterval <= 0 时
+// 过期条目会一直计入统计，需要手动调用 DeleteExpired
+func NewMemory(defaultExpiration, cleanupInterval time.Duration, opts ...MemoryOption) *Memory {
+	m := &Memory{
+		cache:             cache.New(defaultExpiration, cleanupInterval),
+		stats:             NewStatsCollector(),
+		sizer:             EstimateSize,
+		clock:             realClock{},
+		defaultExpiration: defaultExpiration,
+		entries:           make(map[string]*memoryEntry),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.newPolicy == nil {
+		m.newPolicy = NewFIFOPolicy
+		if m.cost != nil {
+			m.newPolicy = NewGreedyDualPolicy
+		}
+	}
+
+	for i := range m.policies {
+		m.policies[i] = m.newPolicy()
+	}
+	_, fifo := m.policies[0].(*fifoPolicy)
+	m.trackAccess = m.maxBytes > 0 && !fifo
+
+	m.cache.OnEvicted(m.onEvicted)
+
+	return m
+}
+
+// Stats 返回缓存统计快照，包含条目数与近似内存占用
+func (c *Memory) Stats() Stats {
+	stats := c.stats.Snapshot()
+
+	c.mu.Lock()
+	c.syncEvictedLocked()
+	stats.Entries = uint64(len(c.entries))
+	stats.MemoryBytes = uint64(c.used)
+	stats.MaxMemoryBytes = uint64(max(c.maxBytes, 0))
+	c.unlock()
+
+	stats.Expired = c.expiredCount.Load()
+	stats.Evicted = c.evictedCount.Load()
+
+	return stats
+}
+
+// EntrySize 返回单个条目的近似内存占用
+func (c *Memory) EntrySize(key string) (int64, bool) {
+	entry, found := c.lookup(key)
+	if !found {
+		return 0, false
+	}
+	return entry.size, true
 }
 
 func (c *Memory) Exists(ctx context.Context, key string) bool {
-	_, b := c.cache.Get(key)
+	_, b := c.lookup(key)
 	return b
 }
 
 func (c *Memory) Get(ctx context.Context, key string, obj any) error {
-	val, b := c.cache.Get(key)
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	if c.admission != nil {
+		c.admission.Record(key)
+	}
+	entry, b := c.lookup(key)
 	if !b {
-		return errors.New("key not exists")
+		c.stats.RecordMiss(key)
+		return ErrKeyNotFound
+	}
+	value, ok := entry.load()
+	if !ok {
+		// 软引用值在读取期间被GC回收
+		c.stats.RecordMiss(key)
+		return ErrKeyNotFound
+	}
+	if err := assignValue(obj, value); err != nil {
+		c.stats.RecordError(key)
+		return err
+	}
+	if c.trackAccess {
+		c.mu.Lock()
+		if entry.stored {
+			c.policies[entry.priority].Access(key)
+		}
+		c.mu.Unlock()
 	}
-	return c.assignValue(obj, val)
+	c.stats.RecordHit(key, int(entry.size))
+	return nil
 }
 
 func (c *Memory) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return c.SetWithPriority(ctx, key, value, ttl, PriorityNormal)
+}
+
+// SetWithPriority 按优先级写入缓存，超出内存上限时优先淘汰低优先级的条目
+func (c *Memory) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	if noStore(ctx) {
+		return nil
+	}
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return err
+	}
+	if err := c.schemas.checkSchema(c.strict, key, value, ttl); err != nil {
+		return err
+	}
 	if ttl <= 0 {
 		ttl = -1
 	}
-	c.cache.Set(key, value, ttl)
+
+	entry, err := c.newEntry(key, value, priority)
+	if err != nil {
+		c.stats.RecordError(key)
+		return err
+	}
+
+	c.mu.Lock()
+	c.syncEvictedLocked()
+	c.storeLocked(entry, ttl)
+	c.evictLocked()
+	if entry.soft != nil {
+		c.softenLocked()
+	}
+	c.unlock()
+
+	c.stats.RecordSet(key, int(entry.size))
 	return nil
 }
 
-func (c *Memory) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
-	// 先尝试从缓存获取
-	err := c.Get(ctx, key, obj)
-	if err == nil {
-		// 缓存命中，直接返回
-		return nil
+// SetNX 键不存在或已过期时写入，返回是否写入
+func (c *Memory) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return false, err
+	}
+	if err := c.schemas.checkSchema(c.strict, key, value, ttl); err != nil {
+		return false, err
+	}
+	if ttl <= 0 {
+		ttl = -1
 	}
 
-	// 缓存未命中，调用回调函数
-	err = fun(key, obj)
+	entry, err := c.newEntry(key, value, PriorityNormal)
 	if err != nil {
-		return err
+		c.stats.RecordError(key)
+		return false, err
+	}
+
+	c.mu.Lock()
+	if _, found := c.lookup(key); found {
+		c.unlock()
+		return false, nil
+	}
+	c.syncEvictedLocked()
+	c.storeLocked(entry, ttl)
+	c.evictLocked()
+	if entry.soft != nil {
+		c.softenLocked()
+	}
+	c.unlock()
+
+	c.stats.RecordSet(key, int(entry.size))
+	return true, nil
+}
+
+// newEntry 创建条目，条目超过内存上限时返回 ErrEntryTooLarge
+func (c *Memory) newEntry(key string, value any, priority Priority) (*memoryEntry, error) {
+	entry := &memoryEntry{key: key, value: value, size: c.sizer(value), cost: 1, priority: clampPriority(priority)}
+	if c.cost != nil {
+		entry.cost = max(c.cost(key, value), 1)
+	}
+	if c.maxBytes > 0 && entry.size > c.maxBytes {
+		return nil, fmt.Errorf("%w: key %s size %d, limit %d", ErrEntryTooLarge, key, entry.size, c.maxBytes)
+	}
+	c.makeSoft(entry)
+	return entry, nil
+}
+
+// storeLocked 写入条目并更新内存占用统计，ttl <= 0 表示不过期
+func (c *Memory) storeLocked(entry *memoryEntry, ttl time.Duration) {
+	c.setExpiry(entry, ttl)
+	old, replaced := c.entries[entry.key]
+	// 覆盖同一优先级中未过期的键时保留淘汰策略中的状态（如LFU的访问次数、ARC的t2），按一次访问处理
+	retained := false
+	if replaced {
+		reason := EvictionReplaced
+		if old.expired(c.clock.Now()) {
+			reason = EvictionExpired
+		}
+		if retained = reason == EvictionReplaced && old.priority == entry.priority && old.stored; retained {
+			c.replaceLocked(old)
+		} else {
+			c.dropLocked(old, reason)
+		}
+	}
+	if c.admission != nil {
+		c.admission.Record(entry.key)
+		// 覆盖已有的键不经过接纳策略
+		if c.candidate = entry; replaced {
+			c.candidate = nil
+		}
+	}
+	entry.stored = true
+	if p, ok := c.policies[entry.priority].(CostPolicy); ok {
+		// 已有的键按新的成本与大小重新计算并计为一次访问
+		p.AddWithCost(entry.key, entry.cost, entry.size)
+	} else if retained {
+		c.policies[entry.priority].Access(entry.key)
+	} else {
+		c.policies[entry.priority].Add(entry.key)
 	}
+	c.entries[entry.key] = entry
+	c.used += entry.size
+	c.cache.Set(entry.key, entry, c.cacheTTL(ttl))
+}
 
-	// 获取obj指向的实际值并存入缓存
-	// obj是一个指针，我们需要存储它指向的值
-	objValue := reflect.ValueOf(obj)
-	if objValue.Kind() == reflect.Ptr {
-		objValue = objValue.Elem()
+func (c *Memory) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	// 严格模式下在调用回调函数之前检查ttl
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return err
 	}
-	return c.Set(ctx, key, objValue.Interface(), ttl)
+	return c.loader.getSet(ctx, c, key, ttl, obj, fun)
+}
+
+// loaderSettings 返回 GetSet 调用回调函数的方式，包装器沿用该设置
+func (c *Memory) loaderSettings() loaderConfig {
+	return c.loader
 }
 
 func (c *Memory) Del(ctx context.Context, key string) error {
-	c.cache.Delete(key)
+	c.delete(key, EvictionDeleted)
+	c.stats.RecordDelete(key)
 	return nil
 }
 
-func (c *Memory) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
-	// 检查键是否存在
-	val, found := c.cache.Get(key)
-	if !found {
-		return errors.New("key not exists")
+// ExistsMulti 返回每个键是否存在
+func (c *Memory) ExistsMulti(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		_, result[key] = c.lookup(key)
+	}
+	return result, nil
+}
+
+// MGet 读取多个键，失败的键记录在返回的映射中
+func (c *Memory) MGet(ctx context.Context, objs map[string]any) (map[string]error, error) {
+	if noCache(ctx) {
+		return nil, ErrCacheBypassed
+	}
+	errs := make(map[string]error)
+	for key, obj := range objs {
+		if err := c.Get(ctx, key, obj); err != nil {
+			errs[key] = err
+		}
+	}
+	return errs, nil
+}
+
+// DelMulti 删除多个键
+func (c *Memory) DelMulti(ctx context.Context, keys ...string) error {
+	c.mu.Lock()
+	c.syncEvictedLocked()
+	for _, key := range keys {
+		c.deleteLocked(key, EvictionDeleted)
+	}
+	c.unlock()
+
+	for _, key := range keys {
+		c.stats.RecordDelete(key)
 	}
+	return nil
+}
 
+func (c *Memory) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
 	// 计算正确的TTL（过期时间 - 当前时间）
-	ttl := time.Until(expiresAt)
+	ttl := expiresAt.Sub(c.clock.Now())
 	if ttl < 0 {
+		// 检查键是否存在
+		if _, found := c.lookup(key); !found {
+			return ErrKeyNotFound
+		}
 		// 如果已经过期，删除键
-		c.cache.Delete(key)
+		c.delete(key, EvictionExpired)
 		return nil
 	}
 
-	// 重新设置带新TTL的值
-	c.cache.Set(key, val, ttl)
+	return c.ExpiresIn(ctx, key, ttl)
+}
+
+func (c *Memory) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return err
+	}
+	if err := c.schemas.checkExpiry(c.strict, key, ttl); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.unlock()
 
+	if !c.expireLocked(key, ttl) {
+		return ErrKeyNotFound
+	}
 	return nil
 }
 
-func (c *Memory) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+// Rename 将键重命名为newKey，保留过期时间
+func (c *Memory) Rename(ctx context.Context, key, newKey string) error {
+	c.mu.Lock()
+	defer c.unlock()
+
+	entry, found := c.lookup(key)
+	if !found {
+		return ErrKeyNotFound
+	}
+	if key == newKey {
+		return nil
+	}
+
+	// 值转移到新键，原键的移除不触发回调
+	c.syncEvictedLocked()
+	c.removeLocked(entry)
+	c.cache.Delete(key)
+	c.storeLocked(&memoryEntry{key: newKey, value: entry.value, soft: entry.soft, size: entry.size, cost: entry.cost, priority: entry.priority}, c.remainingTTL(entry))
+	return nil
+}
+
+// Copy 将src的值复制到dst并设置过期时间ttl，ttl <= 0 表示不过期
+// 与 Get 一致，值不会被深拷贝，两个键共享同一个值
+func (c *Memory) Copy(ctx context.Context, src, dst string, ttl time.Duration) error {
+	if ttl <= 0 {
+		ttl = -1
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	entry, found := c.lookup(src)
+	if !found {
+		return ErrKeyNotFound
+	}
+
+	c.syncEvictedLocked()
+	c.storeLocked(&memoryEntry{key: dst, value: entry.value, soft: entry.soft, size: entry.size, cost: entry.cost, priority: entry.priority}, ttl)
+	c.evictLocked()
+	c.stats.RecordSet(dst, int(entry.size))
+	return nil
+}
+
+// SetFields 保存结构体，内存缓存直接保存整个值
+func (c *Memory) SetFields(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if _, err := structFields(value); err != nil {
+		return err
+	}
+	return c.Set(ctx, key, reflect.Indirect(reflect.ValueOf(value)).Interface(), ttl)
+}
+
+// GetFields 读取结构体到obj
+func (c *Memory) GetFields(ctx context.Context, key string, obj any) error {
+	return c.Get(ctx, key, obj)
+}
+
+// Patch 键存在时保存new，返回与old相比变化的字段数
+// 内存缓存没有传输开销，直接替换整个值
+func (c *Memory) Patch(ctx context.Context, key string, old, new any, ttl time.Duration) (int, error) {
+	oldFields, err := structFields(old)
+	if err != nil {
+		return 0, err
+	}
+	newFields, err := structFields(new)
+	if err != nil {
+		return 0, err
+	}
+	if valueType(old) != valueType(new) {
+		return 0, fmt.Errorf("patch: type mismatch: %T and %T", old, new)
+	}
+
+	changed := 0
+	for i := range newFields {
+		if !reflect.DeepEqual(oldFields[i].value.Interface(), newFields[i].value.Interface()) {
+			changed++
+		}
+	}
+
+	entry, err := c.newEntry(key, reflect.Indirect(reflect.ValueOf(new)).Interface(), PriorityNormal)
+	if err != nil {
+		return 0, err
+	}
+
+	c.mu.Lock()
+	current, found := c.lookup(key)
+	if !found {
+		c.unlock()
+		return 0, ErrKeyNotFound
+	}
+	if ttl <= 0 {
+		// 保留原有的过期时间
+		ttl = c.remainingTTL(current)
+	}
+	c.syncEvictedLocked()
+	c.storeLocked(entry, ttl)
+	c.evictLocked()
+	c.unlock()
+
+	c.stats.RecordSet(key, int(entry.size))
+	return changed, nil
+}
+
+// TTL 返回键的剩余有效期，没有过期时间时返回-1
+func (c *Memory) TTL(ctx context.Context, key string) (time.Duration, error) {
+	entry, found := c.lookup(key)
+	if !found {
+		return 0, ErrKeyNotFound
+	}
+	return c.remainingTTL(entry), nil
+}
+
+// remainingTTL 返回条目的剩余有效期，不过期的条目返回-1
+func (c *Memory) remainingTTL(entry *memoryEntry) time.Duration {
+	expiresAt := entry.expiresAt.Load()
+	if expiresAt == 0 {
+		return -1
+	}
+	return time.Duration(expiresAt - c.clock.Now().UnixNano())
+}
+
+// Persist 移除键的过期时间，键不存在时返回 ErrKeyNotFound
+func (c *Memory) Persist(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.unlock()
+
+	if !c.expireLocked(key, -1) {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Touch 键存在时将过期时间刷新为ttl，返回键是否存在；ttl <= 0 表示移除过期时间
+func (c *Memory) Touch(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		ttl = -1
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.expireLocked(key, ttl), nil
+}
+
+// ExpiresInIf 按条件设置剩余有效期
+func (c *Memory) ExpiresInIf(ctx context.Context, key string, ttl time.Duration, cond ExpireCondition) (bool, error) {
+	return c.ExpiresAtIf(ctx, key, c.clock.Now().Add(ttl), cond)
+}
+
+// ExpiresAtIf 按条件设置过期时间，过期时间早于当前时间时删除键
+func (c *Memory) ExpiresAtIf(ctx context.Context, key string, expiresAt time.Time, cond ExpireCondition) (bool, error) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	entry, found := c.lookup(key)
+	if !found || !allowExpire(cond, entry.expiresAt.Load(), expiresAt.UnixNano()) {
+		return false, nil
+	}
+
+	ttl := expiresAt.Sub(c.clock.Now())
+	if ttl <= 0 {
+		c.syncEvictedLocked()
+		c.deleteLocked(key, EvictionExpired)
+		return true, nil
+	}
+	c.setExpiry(entry, ttl)
+	c.cache.Set(key, entry, c.cacheTTL(ttl))
+	return true, nil
+}
+
+// expireLocked 重新设置条目的TTL，键不存在时返回false
+// ttl 为0时使用默认过期时间，小于0表示不过期
+func (c *Memory) expireLocked(key string, ttl time.Duration) bool {
 	// 检查键是否存在
-	val, found := c.cache.Get(key)
+	entry, found := c.lookup(key)
 	if !found {
-		return errors.New("key not exists")
+		return false
+	}
+
+	// 与go-cache一致，0表示使用默认过期时间
+	if ttl == 0 {
+		ttl = c.defaultExpiration
 	}
 
 	// 重新设置带新TTL的值
-	c.cache.Set(key, val, ttl)
+	c.setExpiry(entry, ttl)
+	c.cache.Set(key, entry, c.cacheTTL(ttl))
+	return true
+}
+
+// lookup 返回未过期的条目
+func (c *Memory) lookup(key string) (*memoryEntry, bool) {
+	val, found := c.cache.Get(key)
+	if !found {
+		return nil, false
+	}
+	entry := val.(*memoryEntry)
+	if entry.expired(c.clock.Now()) || entry.collected() {
+		return nil, false
+	}
+	return entry, true
+}
 
+// setExpiry 按缓存时钟记录条目的过期时间，ttl <= 0 表示不过期
+func (c *Memory) setExpiry(entry *memoryEntry, ttl time.Duration) {
+	if ttl <= 0 {
+		entry.expiresAt.Store(0)
+		return
+	}
+	entry.expiresAt.Store(c.clock.Now().Add(ttl).UnixNano())
+}
+
+// cacheTTL 返回交给go-cache的TTL
+// 使用自定义时钟时过期由条目自身的过期时间判断，go-cache中的条目不过期
+func (c *Memory) cacheTTL(ttl time.Duration) time.Duration {
+	if ttl <= 0 || !isRealClock(c.clock) {
+		return cache.NoExpiration
+	}
+	return ttl
+}
+
+// delete 删除条目并更新内存占用统计
+func (c *Memory) delete(key string, reason EvictionReason) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.syncEvictedLocked()
+	c.deleteLocked(key, reason)
+}
+
+// deleteLocked 删除条目并更新内存占用统计，已过期的条目按过期回调
+func (c *Memory) deleteLocked(key string, reason EvictionReason) {
+	if entry, ok := c.entries[key]; ok {
+		if entry.expired(c.clock.Now()) {
+			reason = EvictionExpired
+		}
+		c.dropLocked(entry, reason)
+	}
+	c.cache.Delete(key)
+}
+
+// DeleteExpired 立即清理所有已过期的条目并同步内存占用统计
+// cleanupInterval <= 0 时不会自动清理过期条目，它们会一直计入 Stats 的条目数与内存占用，
+// 此时需要定期调用本方法；设置了软引用值时还会检查内存压力并清理已被GC回收的条目
+func (c *Memory) DeleteExpired() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.deleteExpiredLocked()
+	c.softenLocked()
+}
+
+// Flush 删除所有条目
+func (c *Memory) Flush() {
+	c.mu.Lock()
+	defer c.unlock()
+
+	c.syncEvictedLocked()
+	for _, entry := range c.entries {
+		c.dropLocked(entry, EvictionDeleted)
+	}
+	c.cache.Flush()
+}
+
+// deleteExpiredLocked 清理过期条目，go-cache的回调会把它们放入待同步列表
+func (c *Memory) deleteExpiredLocked() {
+	c.cache.DeleteExpired()
+	if !isRealClock(c.clock) {
+		// 自定义时钟下go-cache中的条目不过期，按条目自身的过期时间清理
+		now := c.clock.Now()
+		for key, entry := range c.entries {
+			if entry.expired(now) {
+				c.cache.Delete(key)
+			}
+		}
+	}
+	c.syncEvictedLocked()
+}
+
+// evictLocked 超出内存上限时按淘汰策略淘汰条目，设置了接纳策略时先判断是否接纳新写入的条目
+// 淘汰存活条目之前先清理已过期但尚未被janitor清理的条目
+func (c *Memory) evictLocked() {
+	candidate := c.candidate
+	c.candidate = nil
+	if c.maxBytes <= 0 || c.used <= c.maxBytes {
+		return
+	}
+	c.deleteExpiredLocked()
+
+	for c.used > c.maxBytes {
+		victim := c.nextVictimLocked()
+		if victim == nil {
+			return
+		}
+		reason := EvictionCapacity
+		// 接纳策略认为新条目不如被淘汰的条目常用时丢弃新条目
+		if candidate != nil && candidate.stored && victim != candidate && !c.admission.Admit(candidate.key, victim.key) {
+			victim, reason = candidate, EvictionRejected
+		}
+		candidate = nil
+		c.dropLocked(victim, reason)
+		c.cache.Delete(victim.key)
+		c.evictedCount.Add(1)
+	}
+}
+
+// nextVictimLocked 返回最低优先级的淘汰策略选出的条目
+func (c *Memory) nextVictimLocked() *memoryEntry {
+	for _, policy := range c.policies {
+		for {
+			key, ok := policy.Victim()
+			if !ok {
+				break
+			}
+			if entry, ok := c.entries[key]; ok {
+				return entry
+			}
+			// 策略返回了不在缓存中的键，移除后继续选择
+			policy.Remove(key)
+		}
+	}
 	return nil
 }
 
-// assignValue 使用反射将值赋给目标对象
-func (c *Memory) assignValue(obj any, value interface{}) error {
-	if obj == nil {
-		return fmt.Errorf("obj cannot be nil")
+// removeLocked 从内存占用统计中移除条目
+// 可重复调用，已移除的条目会被忽略
+func (c *Memory) removeLocked(entry *memoryEntry) {
+	entry.removed.Store(true)
+	if !entry.stored {
+		return
+	}
+	if c.entries[entry.key] == entry {
+		delete(c.entries, entry.key)
+	}
+	c.policies[entry.priority].Remove(entry.key)
+	entry.stored = false
+	c.used -= entry.size
+}
+
+// onEvicted go-cache删除条目时的回调
+// 主动删除的条目已在持有mu时移除，这里只需处理janitor清理的过期条目；
+// 回调可能在持有mu时被同步调用，因此只记录待同步条目而不获取mu
+func (c *Memory) onEvicted(key string, val any) {
+	entry, ok := val.(*memoryEntry)
+	if !ok || !entry.removed.CompareAndSwap(false, true) {
+		return
+	}
+	c.expiredCount.Add(1)
+	c.queueEviction(entry, EvictionExpired)
+	c.evictedMu.Lock()
+	c.evicted = append(c.evicted, entry)
+	c.evictedMu.Unlock()
+
+	// janitor清理时没有后续的解锁来执行回调，在单独的goroutine中执行
+	if c.hasEvictionHooks() && c.dispatchScheduled.CompareAndSwap(false, true) {
+		go func() {
+			c.dispatchScheduled.Store(false)
+			c.dispatchEvictions()
+		}()
+	}
+}
+
+// syncEvictedLocked 将janitor清理的过期条目同步到内存占用统计
+func (c *Memory) syncEvictedLocked() {
+	c.evictedMu.Lock()
+	evicted := c.evicted
+	c.evicted = nil
+	c.evictedMu.Unlock()
+
+	for _, entry := range evicted {
+		c.removeLocked(entry)
+	}
+}
+
+// Txn 原子执行事务中的写操作
+// 写操作在持有锁时一次性执行，其他读写看不到中间状态；
+// watch 中的键在事务函数执行期间被写入、删除或修改过期时间时返回 ErrTxnConflict
+func (c *Memory) Txn(ctx context.Context, fn func(tx Txn) error, watch ...string) error {
+	// 记录监视键当前的条目与过期时间，提交时比较
+	type watched struct {
+		entry     *memoryEntry
+		expiresAt int64
+	}
+	snapshot := make(map[string]watched, len(watch))
+	for _, key := range watch {
+		var w watched
+		if entry, ok := c.lookup(key); ok {
+			w = watched{entry: entry, expiresAt: entry.expiresAt.Load()}
+		}
+		snapshot[key] = w
+	}
+
+	buf := &txnBuffer{get: c.Get}
+	if err := fn(buf); err != nil {
+		return err
 	}
 
-	objValue := reflect.ValueOf(obj)
-	if objValue.Kind() != reflect.Ptr {
-		return fmt.Errorf("obj must be a pointer")
+	// 提前创建条目，超过内存上限时整个事务失败
+	entries := make([]*memoryEntry, len(buf.ops))
+	for i, op := range buf.ops {
+		if op.kind != OpSet {
+			continue
+		}
+		entry, err := c.newEntry(op.key, op.value, PriorityNormal)
+		if err != nil {
+			return err
+		}
+		entries[i] = entry
 	}
 
-	objElem := objValue.Elem()
+	c.mu.Lock()
+	c.syncEvictedLocked()
+	for key, w := range snapshot {
+		entry, _ := c.lookup(key)
+		if entry != w.entry || (entry != nil && entry.expiresAt.Load() != w.expiresAt) {
+			c.unlock()
+			return ErrTxnConflict
+		}
+	}
+	for i, op := range buf.ops {
+		switch op.kind {
+		case OpSet:
+			ttl := op.ttl
+			if ttl <= 0 {
+				ttl = -1
+			}
+			c.storeLocked(entries[i], ttl)
+		case OpDel:
+			c.deleteLocked(op.key, EvictionDeleted)
+		case OpExpire:
+			c.expireLocked(op.key, op.ttl)
+		}
+	}
+	c.evictLocked()
+	c.unlock()
+
+	for i, op := range buf.ops {
+		switch op.kind {
+		case OpSet:
+			c.stats.RecordSet(op.key, int(entries[i].size))
+		case OpDel:
+			c.stats.RecordDelete(op.key)
+		}
+	}
+	return nil
+}
+
+// assignValue 使用反射将值赋给目标对象
+func assignValue(obj any, value interface{}) error {
+	objElem, err := targetValue(obj)
+	if err != nil {
+		return err
+	}
 	if !objElem.CanSet() {
 		return fmt.Errorf("obj cannot be set")
 	}
@@ -130,7 +925,7 @@ func (c *Memory) assignValue(obj any, value interface{}) error {
 			objElem.Set(reflect.Zero(objElem.Type()))
 			return nil
 		}
-		return fmt.Errorf("cannot assign nil to non-pointer type %s", objElem.Type())
+		return fmt.Errorf("%w: cannot assign nil to non-pointer type %s", ErrTypeMismatch, objElem.Type())
 	}
 
 	valueReflect := reflect.ValueOf(value)
@@ -140,9 +935,61 @@ func (c *Memory) assignValue(obj any, value interface{}) error {
 
 	// 确保类型匹配
 	if objElem.Type() != valueReflect.Type() {
-		return fmt.Errorf("type mismatch: expected %s, got %s", objElem.Type(), valueReflect.Type())
+		return fmt.Errorf("%w: expected %s, got %s", ErrTypeMismatch, objElem.Type(), valueReflect.Type())
 	}
 
 	objElem.Set(valueReflect)
 	return nil
 }
+
+// Keys 返回匹配模式的所有未过期的键，限制见 WithScanOptions
+func (c *Memory) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	now := c.clock.Now()
+	for key, item := range c.cache.Items() {
+		if entry := item.Object.(*memoryEntry); entry.expired(now) || entry.collected() {
+			continue
+		}
+		if MatchPattern(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	keys, err := newScanLimiter(ctx).take(keys)
+	return keys, err
+}
+
+// DelByPattern 删除匹配模式的所有键，返回删除的数量，限制见 WithScanOptions
+// DryRun 时只统计匹配的键数，不删除
+func (c *Memory) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	keys, err := c.Keys(ctx, pattern)
+	if ScanOptionsFromContext(ctx).DryRun {
+		return int64(len(keys)), err
+	}
+	for _, key := range keys {
+		c.delete(key, EvictionDeleted)
+		c.stats.RecordDelete(key)
+	}
+	return int64(len(keys)), err
+}
+
+// SampleTTLs 采样最多n个未过期键的剩余TTL，没有过期时间的键返回-1
+func (c *Memory) SampleTTLs(ctx context.Context, n int) ([]time.Duration, error) {
+	now := c.clock.Now()
+	ttls := make([]time.Duration, 0, n)
+	for _, item := range c.cache.Items() {
+		if len(ttls) >= n {
+			break
+		}
+		entry := item.Object.(*memoryEntry)
+		if entry.expired(now) || entry.collected() {
+			continue
+		}
+		expiresAt := entry.expiresAt.Load()
+		if expiresAt == 0 {
+			ttls = append(ttls, -1)
+			continue
+		}
+		ttls = append(ttls, time.Duration(expiresAt-now.UnixNano()))
+	}
+	return ttls, nil
+}
@@ -0,0 +1,162 @@
+package go_cache
+
+import (
+	"math/bits"
+	"sync"
+)
+
+// AdmissionPolicy 接纳策略，决定超出内存上限时新写入的条目能否替换淘汰策略选出的条目
+// 方法可能被并发调用，实现需要自己保证并发安全
+type AdmissionPolicy interface {
+	// Record 记录一次对key的访问，Get（包括未命中）与写入时调用
+	Record(key string)
+	// Admit 判断是否用新写入的candidate替换victim，返回false时丢弃candidate并保留victim
+	Admit(candidate, victim string) bool
+}
+
+// WithMemoryAdmission 设置接纳策略，如 NewTinyLFU
+// 写入新的键导致超出内存上限时，淘汰策略选出的条目与新条目由接纳策略比较，
+// 新条目不被接纳时立即以 EvictionRejected 移除，保护缓存不被扫描类的一次性访问冲刷
+func WithMemoryAdmission(policy AdmissionPolicy) MemoryOption {
+	return func(m *Memory) {
+		m.admission = policy
+	}
+}
+
+// TinyLFUOption TinyLFU选项
+type TinyLFUOption func(*TinyLFU)
+
+// WithTinyLFUSampleSize 设置计数衰减的周期，记录该次数后所有计数减半，默认为计数器数量的10倍
+func WithTinyLFUSampleSize(n int) TinyLFUOption {
+	return func(t *TinyLFU) {
+		if n > 0 {
+			t.sampleSize = n
+		}
+	}
+}
+
+// TinyLFU 基于近期访问频率的接纳策略
+// 使用4位计数的Count-Min Sketch近似统计访问次数，并用门卫布隆过滤器过滤只访问过一次的键；
+// 计数周期性减半，使频率反映近期的访问。新条目的访问频率高于被淘汰的条目时才被接纳
+type TinyLFU struct {
+	mu         sync.Mutex
+	counters   []uint64 // 每个uint64保存16个4位计数
+	doorkeeper []uint64 // 门卫布隆过滤器的位
+	mask       uint64   // 计数器数量-1
+	sampleSize int
+	additions  int
+}
+
+// NewTinyLFU 创建TinyLFU，size 为预计缓存的条目数，用于确定计数器数量
+func NewTinyLFU(size int, opts ...TinyLFUOption) *TinyLFU {
+	n := uint64(1) << bits.Len64(uint64(max(size, 16)-1)) // 向上取整为2的幂
+	t := &TinyLFU{
+		counters:   make([]uint64, n/16+1),
+		doorkeeper: make([]uint64, n/64+1),
+		mask:       n - 1,
+		sampleSize: int(n) * 10,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// Record 记录一次对key的访问
+func (t *TinyLFU) Record(key string) {
+	h := hllHash(key)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 第一次访问只记入门卫，避免只访问一次的键占用计数
+	if !t.admitDoorkeeper(h) {
+		return
+	}
+	for i := 0; i < 4; i++ {
+		t.increment(t.index(h, i))
+	}
+	if t.additions++; t.additions >= t.sampleSize {
+		t.reset()
+	}
+}
+
+// Estimate 返回key近期的访问次数估计
+func (t *TinyLFU) Estimate(key string) int {
+	h := hllHash(key)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.estimate(h)
+}
+
+// Admit 新条目的访问频率高于被淘汰的条目时接纳
+func (t *TinyLFU) Admit(candidate, victim string) bool {
+	hc, hv := hllHash(candidate), hllHash(victim)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.estimate(hc) > t.estimate(hv)
+}
+
+// estimate 返回四个计数中的最小值，加上门卫中的一次
+func (t *TinyLFU) estimate(h uint64) int {
+	n := uint64(15)
+	for i := 0; i < 4; i++ {
+		n = min(n, t.counter(t.index(h, i)))
+	}
+	if t.inDoorkeeper(h) {
+		n++
+	}
+	return int(n)
+}
+
+// index 返回第i个哈希对应的计数器序号
+func (t *TinyLFU) index(h uint64, i int) uint64 {
+	h1, h2 := h, h>>32|h<<32
+	return (h1 + uint64(i)*h2) & t.mask
+}
+
+func (t *TinyLFU) counter(i uint64) uint64 {
+	return t.counters[i/16] >> (i % 16 * 4) & 0xf
+}
+
+// increment 计数加一，最大为15
+func (t *TinyLFU) increment(i uint64) {
+	shift := i % 16 * 4
+	if t.counters[i/16]>>shift&0xf < 15 {
+		t.counters[i/16] += 1 << shift
+	}
+}
+
+// admitDoorkeeper 返回键是否已在门卫中，不在时加入
+func (t *TinyLFU) admitDoorkeeper(h uint64) bool {
+	if t.inDoorkeeper(h) {
+		return true
+	}
+	for i := 0; i < 2; i++ {
+		bit := t.index(h, i+4)
+		t.doorkeeper[bit/64] |= 1 << (bit % 64)
+	}
+	return false
+}
+
+func (t *TinyLFU) inDoorkeeper(h uint64) bool {
+	for i := 0; i < 2; i++ {
+		bit := t.index(h, i+4)
+		if t.doorkeeper[bit/64]&(1<<(bit%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// reset 所有计数减半并清空门卫
+func (t *TinyLFU) reset() {
+	for i := range t.counters {
+		// 每个4位计数右移一位，屏蔽从高位计数移入的位
+		t.counters[i] = t.counters[i] >> 1 & 0x7777777777777777
+	}
+	clear(t.doorkeeper)
+	t.additions = 0
+}
@@ -0,0 +1,82 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// SetBit 设置key在offset处的位，返回原来的值
+// 键已有过期时间时保留原有的过期时间；键保存的不是位图时返回 ErrTypeMismatch
+func (c *Memory) SetBit(ctx context.Context, key string, offset int64, value bool) (bool, error) {
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+
+	c.mu.Lock()
+	defer c.unlock()
+
+	current, found := c.lookup(key)
+	b, err := c.bitmap(current, found, key)
+	if err != nil {
+		return false, err
+	}
+	old := b.get(offset)
+	if found && old == value && offset/8 < int64(len(b)) {
+		return old, nil
+	}
+
+	entry, err := c.newEntry(key, b.set(offset, value), PriorityNormal)
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, err
+	}
+	ttl := time.Duration(-1)
+	if found {
+		ttl = c.remainingTTL(current)
+	}
+	c.syncEvictedLocked()
+	c.storeLocked(entry, ttl)
+	c.evictLocked()
+	c.stats.RecordSet(key, int(entry.size))
+	return old, nil
+}
+
+// GetBit 返回key在offset处的位
+func (c *Memory) GetBit(ctx context.Context, key string, offset int64) (bool, error) {
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+	entry, found := c.lookup(key)
+	b, err := c.bitmap(entry, found, key)
+	if err != nil {
+		return false, err
+	}
+	return b.get(offset), nil
+}
+
+// BitCount 返回key中值为1的位数
+func (c *Memory) BitCount(ctx context.Context, key string) (int64, error) {
+	entry, found := c.lookup(key)
+	b, err := c.bitmap(entry, found, key)
+	if err != nil {
+		return 0, err
+	}
+	return b.count(), nil
+}
+
+// bitmap 返回条目保存的位图，条目不存在时返回nil
+func (c *Memory) bitmap(entry *memoryEntry, found bool, key string) (bitmap, error) {
+	if !found {
+		return nil, nil
+	}
+	value, ok := entry.load()
+	if !ok {
+		return nil, nil
+	}
+	b, ok := value.(bitmap)
+	if !ok {
+		return nil, fmt.Errorf("%w: key %s does not hold a bitmap", ErrTypeMismatch, key)
+	}
+	return b, nil
+}
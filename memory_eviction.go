@@ -0,0 +1,140 @@
+package go_cache
+
+// EvictionReason 条目从内存缓存中移除的原因
+type EvictionReason int
+
+const (
+	// EvictionExpired 条目过期，或通过 ExpiresAt 等方法设置了已经过去的过期时间
+	EvictionExpired EvictionReason = iota
+	// EvictionDeleted 条目被 Del、DelMulti、DelByPattern、Flush 或事务主动删除
+	EvictionDeleted
+	// EvictionCapacity 超出内存上限被淘汰
+	EvictionCapacity
+	// EvictionReplaced 条目被同一个键的新值覆盖
+	EvictionReplaced
+	// EvictionRejected 新写入的条目没有被接纳策略接纳，见 WithMemoryAdmission
+	EvictionRejected
+)
+
+// String 返回移除原因的名称
+func (r EvictionReason) String() string {
+	switch r {
+	case EvictionExpired:
+		return "expired"
+	case EvictionDeleted:
+		return "deleted"
+	case EvictionCapacity:
+		return "capacity"
+	case EvictionReplaced:
+		return "replaced"
+	case EvictionRejected:
+		return "rejected"
+	}
+	return "unknown"
+}
+
+// EvictionCallback 条目被移除后的回调，可用于释放与缓存值关联的资源（文件句柄、临时文件等）
+type EvictionCallback func(key string, value any, reason EvictionReason)
+
+// WithEvictionCallback 设置条目被移除后的回调
+// 回调在释放缓存内部锁之后调用，可以在回调中访问缓存；同一个缓存的回调按移除顺序串行执行。
+// 主动删除与淘汰的回调通常在对应方法返回前完成，其他goroutine正在执行回调时改由其执行；
+// janitor清理过期条目的回调在单独的goroutine中执行。
+// Rename 只改变键名，不会触发回调；Copy 产生的两个键共享同一个值，各自移除时都会触发回调
+func WithEvictionCallback(fn EvictionCallback) MemoryOption {
+	return func(m *Memory) {
+		m.onEvict = fn
+	}
+}
+
+// WithMemoryExpiryListeners 将过期移除的键报告给listeners，与 WithEvictionCallback 可以同时使用
+// 使用自定义时钟时过期条目在 DeleteExpired 或被覆盖时才会报告
+func WithMemoryExpiryListeners(listeners *ExpiryListeners) MemoryOption {
+	return func(m *Memory) {
+		m.expiry = listeners
+	}
+}
+
+// hasEvictionHooks 判断是否需要记录移除
+func (c *Memory) hasEvictionHooks() bool {
+	return c.onEvict != nil || c.expiry != nil
+}
+
+// eviction 等待回调的移除记录
+type eviction struct {
+	key    string
+	value  any
+	reason EvictionReason
+}
+
+// dropLocked 移除条目并记录回调，条目已被移除时忽略
+func (c *Memory) dropLocked(entry *memoryEntry, reason EvictionReason) {
+	if entry.removed.CompareAndSwap(false, true) {
+		c.queueEviction(entry, reason)
+	}
+	c.removeLocked(entry)
+}
+
+// replaceLocked 移除被同一优先级的新条目覆盖的条目，键保留在淘汰策略中
+func (c *Memory) replaceLocked(entry *memoryEntry) {
+	if entry.removed.CompareAndSwap(false, true) {
+		c.queueEviction(entry, EvictionReplaced)
+	}
+	entry.stored = false
+	c.used -= entry.size
+}
+
+// queueEviction 记录待回调的移除，可能在持有mu时被调用
+func (c *Memory) queueEviction(entry *memoryEntry, reason EvictionReason) {
+	if !c.hasEvictionHooks() {
+		return
+	}
+	value, _ := entry.load()
+	c.evictedMu.Lock()
+	c.pendingEvictions = append(c.pendingEvictions, eviction{key: entry.key, value: value, reason: reason})
+	c.evictedMu.Unlock()
+}
+
+// unlock 释放mu并执行期间产生的移除回调
+func (c *Memory) unlock() {
+	c.mu.Unlock()
+	c.dispatchEvictions()
+}
+
+// dispatchEvictions 执行所有待回调的移除，不能在持有mu时调用
+// 同一时间只有一个goroutine执行回调，其他goroutine（包括回调中再次访问缓存）记录的移除由它一并执行
+func (c *Memory) dispatchEvictions() {
+	if !c.hasEvictionHooks() {
+		return
+	}
+
+	for c.dispatching.CompareAndSwap(false, true) {
+		for {
+			c.evictedMu.Lock()
+			pending := c.pendingEvictions
+			c.pendingEvictions = nil
+			c.evictedMu.Unlock()
+
+			if len(pending) == 0 {
+				break
+			}
+			for _, e := range pending {
+				if c.onEvict != nil {
+					c.onEvict(e.key, e.value, e.reason)
+				}
+				if c.expiry != nil && e.reason == EvictionExpired {
+					c.expiry.Notify(e.key)
+				}
+			}
+		}
+		c.dispatching.Store(false)
+
+		// 释放标记前其他goroutine记录的移除需要重新检查
+		c.evictedMu.Lock()
+		empty := len(c.pendingEvictions) == 0
+		c.evictedMu.Unlock()
+		if empty {
+			return
+		}
+	}
+}
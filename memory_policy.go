@@ -0,0 +1,401 @@
+package go_cache
+
+import (
+	"container/heap"
+	"container/list"
+)
+
+// EvictionPolicy 内存缓存的淘汰策略，决定超出内存上限时先淘汰哪个键
+// 内存缓存为每个优先级创建一个策略实例，总是先从低优先级的实例中淘汰。
+// 所有方法都在持有缓存的锁时调用，实现不需要自己加锁，也不能调用缓存的方法
+type EvictionPolicy interface {
+	// Add 加入新写入的键；覆盖同一优先级中已有的键时调用 Access 而不是 Add，优先级改变时先对旧的条目调用 Remove
+	Add(key string)
+	// Access 已加入的键被 Get 命中或被覆盖
+	Access(key string)
+	// Remove 键被删除、过期或淘汰后调用
+	Remove(key string)
+	// Victim 返回下一个淘汰的键，没有键时返回false
+	// 接纳策略可能拒绝新条目而保留该键，Victim 不应改变状态，淘汰发生在随后对该键调用 Remove 时
+	Victim() (string, bool)
+}
+
+// WithEvictionPolicy 设置超出内存上限时的淘汰策略，newPolicy 为每个优先级创建一个策略实例
+// 内置 NewFIFOPolicy（默认）、NewLRUPolicy、NewLFUPolicy、NewARCPolicy 与 NewGreedyDualPolicy。
+// FIFO 以外的策略需要在 Get 命中时更新状态，读操作会短暂获取缓存的锁
+func WithEvictionPolicy(newPolicy func() EvictionPolicy) MemoryOption {
+	return func(m *Memory) {
+		if newPolicy != nil {
+			m.newPolicy = newPolicy
+		}
+	}
+}
+
+// CostFunc 计算条目的成本，如重新生成该值所需的计算量或回源耗时，返回值 <= 0 时按1计算
+type CostFunc func(key string, value any) int64
+
+// CostPolicy 由考虑条目成本的淘汰策略实现，内存缓存写入时以 AddWithCost 代替 Add
+type CostPolicy interface {
+	EvictionPolicy
+	// AddWithCost 加入新写入的键，cost 为条目的成本，size 为条目的大小（字节）；
+	// 覆盖同一优先级中已有的键时同样调用，实现应更新成本并计为一次访问
+	AddWithCost(key string, cost, size int64)
+}
+
+// WithMemoryCostFunc 设置条目的成本，淘汰时优先保留单位大小成本高的条目
+// 没有通过 WithEvictionPolicy 设置淘汰策略时使用 NewGreedyDualPolicy；
+// 成本低、体积大的新条目可能在写入后立即被淘汰，即不被接纳，而不是挤掉多个成本高的小条目
+func WithMemoryCostFunc(fn CostFunc) MemoryOption {
+	return func(m *Memory) {
+		m.cost = fn
+	}
+}
+
+// keyList 按顺序保存键的链表，支持按键O(1)移动与删除
+type keyList struct {
+	order *list.List
+	elems map[string]*list.Element
+}
+
+func newKeyList() *keyList {
+	return &keyList{order: list.New(), elems: make(map[string]*list.Element)}
+}
+
+func (l *keyList) len() int {
+	return l.order.Len()
+}
+
+func (l *keyList) contains(key string) bool {
+	_, ok := l.elems[key]
+	return ok
+}
+
+// pushBack 将键加入末尾，已存在时移动到末尾
+func (l *keyList) pushBack(key string) {
+	if elem, ok := l.elems[key]; ok {
+		l.order.MoveToBack(elem)
+		return
+	}
+	l.elems[key] = l.order.PushBack(key)
+}
+
+func (l *keyList) remove(key string) bool {
+	elem, ok := l.elems[key]
+	if !ok {
+		return false
+	}
+	l.order.Remove(elem)
+	delete(l.elems, key)
+	return true
+}
+
+// front 返回最早加入（或最久未移动）的键
+func (l *keyList) front() (string, bool) {
+	if elem := l.order.Front(); elem != nil {
+		return elem.Value.(string), true
+	}
+	return "", false
+}
+
+// popFront 移除并返回最前面的键
+func (l *keyList) popFront() (string, bool) {
+	key, ok := l.front()
+	if ok {
+		l.remove(key)
+	}
+	return key, ok
+}
+
+// fifoPolicy 按写入顺序淘汰
+type fifoPolicy struct {
+	keys *keyList
+}
+
+// NewFIFOPolicy 创建按写入顺序淘汰最早写入的键的策略，读取不影响顺序
+func NewFIFOPolicy() EvictionPolicy {
+	return &fifoPolicy{keys: newKeyList()}
+}
+
+func (p *fifoPolicy) Add(key string)         { p.keys.pushBack(key) }
+func (p *fifoPolicy) Access(key string)      {}
+func (p *fifoPolicy) Remove(key string)      { p.keys.remove(key) }
+func (p *fifoPolicy) Victim() (string, bool) { return p.keys.front() }
+
+// lruPolicy 淘汰最久未使用的键
+type lruPolicy struct {
+	keys *keyList
+}
+
+// NewLRUPolicy 创建淘汰最久未读写的键的策略
+func NewLRUPolicy() EvictionPolicy {
+	return &lruPolicy{keys: newKeyList()}
+}
+
+func (p *lruPolicy) Add(key string) { p.keys.pushBack(key) }
+
+func (p *lruPolicy) Access(key string) {
+	if p.keys.contains(key) {
+		p.keys.pushBack(key)
+	}
+}
+
+func (p *lruPolicy) Remove(key string)      { p.keys.remove(key) }
+func (p *lruPolicy) Victim() (string, bool) { return p.keys.front() }
+
+// lfuBucket 访问次数相同的键，按最近访问的顺序排列
+type lfuBucket struct {
+	freq int
+	keys *keyList
+}
+
+// lfuPolicy 按访问次数淘汰，访问次数的桶按升序排列，淘汰与更新都是O(1)
+type lfuPolicy struct {
+	buckets *list.List               // *lfuBucket，按访问次数升序
+	nodes   map[string]*list.Element // 键所在的桶
+}
+
+// NewLFUPolicy 创建淘汰访问次数最少的键的策略，次数相同时淘汰最久未访问的键
+// 写入计为一次访问，覆盖已有的键时保留访问次数并计为一次访问
+func NewLFUPolicy() EvictionPolicy {
+	return &lfuPolicy{buckets: list.New(), nodes: make(map[string]*list.Element)}
+}
+
+func (p *lfuPolicy) Add(key string) {
+	if _, ok := p.nodes[key]; ok {
+		p.Access(key)
+		return
+	}
+	front := p.buckets.Front()
+	if front == nil || front.Value.(*lfuBucket).freq != 1 {
+		front = p.buckets.PushFront(&lfuBucket{freq: 1, keys: newKeyList()})
+	}
+	front.Value.(*lfuBucket).keys.pushBack(key)
+	p.nodes[key] = front
+}
+
+func (p *lfuPolicy) Access(key string) {
+	elem, ok := p.nodes[key]
+	if !ok {
+		return
+	}
+	bucket := elem.Value.(*lfuBucket)
+	next := elem.Next()
+	if next == nil || next.Value.(*lfuBucket).freq != bucket.freq+1 {
+		next = p.buckets.InsertAfter(&lfuBucket{freq: bucket.freq + 1, keys: newKeyList()}, elem)
+	}
+	next.Value.(*lfuBucket).keys.pushBack(key)
+	p.nodes[key] = next
+	p.removeFrom(elem, key)
+}
+
+func (p *lfuPolicy) Remove(key string) {
+	if elem, ok := p.nodes[key]; ok {
+		delete(p.nodes, key)
+		p.removeFrom(elem, key)
+	}
+}
+
+func (p *lfuPolicy) Victim() (string, bool) {
+	if front := p.buckets.Front(); front != nil {
+		return front.Value.(*lfuBucket).keys.front()
+	}
+	return "", false
+}
+
+// removeFrom 从桶中移除键，桶为空时删除桶
+func (p *lfuPolicy) removeFrom(elem *list.Element, key string) {
+	bucket := elem.Value.(*lfuBucket)
+	bucket.keys.remove(key)
+	if bucket.keys.len() == 0 {
+		p.buckets.Remove(elem)
+	}
+}
+
+// arcPolicy 自适应替换缓存（ARC）
+// t1 保存只访问过一次的键，t2 保存访问过多次的键，b1、b2 为从t1、t2淘汰的键（只保存键）；
+// 淘汰的键再次写入时命中b1说明t1过小，命中b2说明t2过小，据此调整t1的目标大小p。
+// 内存缓存按字节而不是条目数限制，这里以当前的条目数作为ARC的容量
+type arcPolicy struct {
+	t1, t2, b1, b2 *keyList
+	p              int
+	victim         string // 最近一次 Victim 返回的键，被 Remove 时移入b1或b2
+}
+
+// NewARCPolicy 创建自适应替换缓存（ARC）策略，在最近使用与访问频率之间自动平衡，
+// 能抵抗一次性的大范围扫描冲掉热点数据
+func NewARCPolicy() EvictionPolicy {
+	return &arcPolicy{t1: newKeyList(), t2: newKeyList(), b1: newKeyList(), b2: newKeyList()}
+}
+
+func (p *arcPolicy) Add(key string) {
+	switch {
+	case p.t1.contains(key) || p.t2.contains(key):
+		p.Access(key)
+		return
+	case p.b1.contains(key):
+		// 最近淘汰的只访问过一次的键再次写入，增大t1的目标大小
+		p.p = min(p.p+max(p.b2.len()/max(p.b1.len(), 1), 1), p.capacity())
+		p.b1.remove(key)
+		p.t2.pushBack(key)
+	case p.b2.contains(key):
+		p.p = max(p.p-max(p.b1.len()/max(p.b2.len(), 1), 1), 0)
+		p.b2.remove(key)
+		p.t2.pushBack(key)
+	default:
+		p.t1.pushBack(key)
+	}
+	p.trimGhosts()
+}
+
+func (p *arcPolicy) Access(key string) {
+	if p.t1.remove(key) || p.t2.contains(key) {
+		p.t2.pushBack(key)
+	}
+}
+
+// Remove 移除键，Victim 选出的键被淘汰时记入b1或b2，其他删除不影响自适应
+func (p *arcPolicy) Remove(key string) {
+	evicted := key == p.victim
+	if evicted {
+		p.victim = ""
+	}
+	switch {
+	case p.t1.remove(key):
+		if evicted {
+			p.b1.pushBack(key)
+		}
+	case p.t2.remove(key):
+		if evicted {
+			p.b2.pushBack(key)
+		}
+	}
+	p.trimGhosts()
+}
+
+func (p *arcPolicy) Victim() (string, bool) {
+	key, ok := p.t2.front()
+	if p.t1.len() > 0 && (p.t1.len() > p.p || !ok) {
+		key, ok = p.t1.front()
+	}
+	if ok {
+		p.victim = key
+	}
+	return key, ok
+}
+
+// capacity 返回当前的条目数，至少为1
+func (p *arcPolicy) capacity() int {
+	return max(p.t1.len()+p.t2.len(), 1)
+}
+
+// trimGhosts 将b1、b2各自限制在容量以内
+func (p *arcPolicy) trimGhosts() {
+	c := p.capacity()
+	for p.b1.len() > c {
+		p.b1.popFront()
+	}
+	for p.b2.len() > c {
+		p.b2.popFront()
+	}
+}
+
+// gdItem 贪心对偶策略中的一个键
+type gdItem struct {
+	key      string
+	value    float64 // 单位大小的成本
+	freq     int
+	priority float64
+	index    int
+}
+
+// gdHeap 按优先级排序的最小堆
+type gdHeap []*gdItem
+
+func (h gdHeap) Len() int { return len(h) }
+func (h gdHeap) Less(i, j int) bool {
+	return h[i].priority < h[j].priority
+}
+func (h gdHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+func (h *gdHeap) Push(x any) {
+	item := x.(*gdItem)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+func (h *gdHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	old[len(old)-1] = nil
+	*h = old[:len(old)-1]
+	return item
+}
+
+// greedyDualPolicy 贪心对偶（GreedyDual-Size-Frequency）策略
+// 键的优先级为 L + 访问次数 × 成本 / 大小，淘汰优先级最低的键并把L提高到它的优先级，
+// 使长时间未访问的键的优先级相对降低（老化）
+type greedyDualPolicy struct {
+	items   gdHeap
+	keys    map[string]*gdItem
+	inflate float64 // L
+	victim  string  // 最近一次 Victim 返回的键，被 Remove 时将L提高到它的优先级
+}
+
+// NewGreedyDualPolicy 创建按单位大小成本与访问次数淘汰的贪心对偶策略
+// 与 WithMemoryCostFunc 一起使用；没有设置成本时每个条目的成本为1，优先淘汰大的条目
+func NewGreedyDualPolicy() EvictionPolicy {
+	return &greedyDualPolicy{keys: make(map[string]*gdItem)}
+}
+
+func (p *greedyDualPolicy) Add(key string) {
+	p.AddWithCost(key, 1, 1)
+}
+
+func (p *greedyDualPolicy) AddWithCost(key string, cost, size int64) {
+	value := float64(max(cost, 1)) / float64(max(size, 1))
+	if item, ok := p.keys[key]; ok {
+		item.value = value
+		p.touch(item)
+		return
+	}
+	item := &gdItem{key: key, value: value, freq: 1}
+	item.priority = p.inflate + item.value
+	p.keys[key] = item
+	heap.Push(&p.items, item)
+}
+
+func (p *greedyDualPolicy) Access(key string) {
+	if item, ok := p.keys[key]; ok {
+		p.touch(item)
+	}
+}
+
+func (p *greedyDualPolicy) Remove(key string) {
+	item, ok := p.keys[key]
+	if !ok {
+		return
+	}
+	if key == p.victim {
+		p.inflate = max(p.inflate, item.priority)
+		p.victim = ""
+	}
+	heap.Remove(&p.items, item.index)
+	delete(p.keys, key)
+}
+
+func (p *greedyDualPolicy) Victim() (string, bool) {
+	if len(p.items) == 0 {
+		return "", false
+	}
+	p.victim = p.items[0].key
+	return p.victim, true
+}
+
+// touch 增加访问次数并重新计算优先级
+func (p *greedyDualPolicy) touch(item *gdItem) {
+	item.freq++
+	item.priority = p.inflate + float64(item.freq)*item.value
+	heap.Fix(&p.items, item.index)
+}
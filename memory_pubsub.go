@@ -0,0 +1,130 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+)
+
+// memoryBus 内存缓存的进程内消息总线
+type memoryBus struct {
+	mu     sync.Mutex
+	nextID uint64
+	subs   map[string]map[uint64]*memorySubscriber // 频道到订阅者
+}
+
+// memorySubscriber 进程内的一个订阅者
+// 消息先放入队列再由单独的goroutine按顺序投递，发布者不会因处理函数阻塞
+type memorySubscriber struct {
+	handler MessageHandler
+
+	mu     sync.Mutex
+	queue  []*Message
+	notify chan struct{}
+	done   chan struct{}
+}
+
+// Publish 向进程内订阅了channel的订阅者投递msg
+// 订阅者收到的是发布的值本身，与读取内存缓存相同，修改引用类型的字段会影响其他订阅者
+func (c *Memory) Publish(ctx context.Context, channel string, msg any) error {
+	c.bus.mu.Lock()
+	subs := make([]*memorySubscriber, 0, len(c.bus.subs[channel]))
+	for _, sub := range c.bus.subs[channel] {
+		subs = append(subs, sub)
+	}
+	c.bus.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.push(&Message{
+			Channel: channel,
+			decode: func(obj any) error {
+				return assignValue(obj, msg)
+			},
+		})
+	}
+	return nil
+}
+
+// Subscribe 订阅进程内的频道channel
+func (c *Memory) Subscribe(ctx context.Context, channel string, handler MessageHandler) (func(), error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sub := &memorySubscriber{
+		handler: handler,
+		notify:  make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	c.bus.mu.Lock()
+	if c.bus.subs == nil {
+		c.bus.subs = make(map[string]map[uint64]*memorySubscriber)
+	}
+	if c.bus.subs[channel] == nil {
+		c.bus.subs[channel] = make(map[uint64]*memorySubscriber)
+	}
+	c.bus.nextID++
+	id := c.bus.nextID
+	c.bus.subs[channel][id] = sub
+	c.bus.mu.Unlock()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		sub.run()
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			c.bus.mu.Lock()
+			delete(c.bus.subs[channel], id)
+			if len(c.bus.subs[channel]) == 0 {
+				delete(c.bus.subs, channel)
+			}
+			c.bus.mu.Unlock()
+
+			close(sub.done)
+			wg.Wait()
+		})
+	}
+	context.AfterFunc(ctx, cancel)
+	return cancel, nil
+}
+
+// push 将消息加入队列
+func (s *memorySubscriber) push(msg *Message) {
+	s.mu.Lock()
+	s.queue = append(s.queue, msg)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run 按顺序投递队列中的消息，直到取消订阅
+func (s *memorySubscriber) run() {
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-s.notify:
+		}
+
+		s.mu.Lock()
+		queue := s.queue
+		s.queue = nil
+		s.mu.Unlock()
+
+		for _, msg := range queue {
+			select {
+			case <-s.done:
+				return
+			default:
+			}
+			s.handler(msg)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package go_cache
+
+import (
+	"reflect"
+
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// maxEstimateDepth EstimateSize 递归的最大深度
+const maxEstimateDepth = 8
+
+// Sizer 计算缓存值的近似内存占用（字节）
+type Sizer func(value any) int64
+
+// SerializerSizer 返回使用序列化结果长度作为值大小的Sizer
+// 比 EstimateSize 更贴近写入Redis后的大小，但每次写入都需要额外序列化一次
+func SerializerSizer(s serializer.Serializer) Sizer {
+	return func(value any) int64 {
+		data, err := s.Encode(value)
+		if err != nil {
+			return EstimateSize(value)
+		}
+		return int64(len(data))
+	}
+}
+
+// EstimateSize 基于反射估算值的近似内存占用（字节）
+// 计算类型本身的大小（等同于 unsafe.Sizeof）以及字符串、切片、map、指针指向的数据
+// 结果仅为近似值：不包含map的桶开销与内存对齐，超过一定深度的嵌套数据会被忽略
+func EstimateSize(value any) int64 {
+	if value == nil {
+		return 0
+	}
+	v := reflect.ValueOf(value)
+	return int64(v.Type().Size()) + estimateIndirect(v, 0, make(map[uintptr]struct{}))
+}
+
+// estimateIndirect 估算值间接引用的数据大小（不含值本身）
+func estimateIndirect(v reflect.Value, depth int, seen map[uintptr]struct{}) int64 {
+	if depth > maxEstimateDepth || !v.IsValid() {
+		return 0
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return int64(v.Len())
+
+	case reflect.Ptr:
+		if v.IsNil() || markSeen(v.Pointer(), seen) {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + estimateIndirect(elem, depth+1, seen)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return 0
+		}
+		elem := v.Elem()
+		return int64(elem.Type().Size()) + estimateIndirect(elem, depth+1, seen)
+
+	case reflect.Slice:
+		if v.IsNil() || markSeen(v.Pointer(), seen) {
+			return 0
+		}
+		size := int64(v.Cap()) * int64(v.Type().Elem().Size())
+		if hasIndirect(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				size += estimateIndirect(v.Index(i), depth+1, seen)
+			}
+		}
+		return size
+
+	case reflect.Array:
+		var size int64
+		if hasIndirect(v.Type().Elem()) {
+			for i := 0; i < v.Len(); i++ {
+				size += estimateIndirect(v.Index(i), depth+1, seen)
+			}
+		}
+		return size
+
+	case reflect.Map:
+		if v.IsNil() || markSeen(v.Pointer(), seen) {
+			return 0
+		}
+		keyType, elemType := v.Type().Key(), v.Type().Elem()
+		size := int64(v.Len()) * int64(keyType.Size()+elemType.Size())
+		if hasIndirect(keyType) || hasIndirect(elemType) {
+			iter := v.MapRange()
+			for iter.Next() {
+				size += estimateIndirect(iter.Key(), depth+1, seen)
+				size += estimateIndirect(iter.Value(), depth+1, seen)
+			}
+		}
+		return size
+
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += estimateIndirect(v.Field(i), depth+1, seen)
+		}
+		return size
+	}
+
+	return 0
+}
+
+// hasIndirect 判断类型是否可能引用额外的内存
+func hasIndirect(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.String, reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return true
+	case reflect.Array:
+		return hasIndirect(t.Elem())
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			if hasIndirect(t.Field(i).Type) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// markSeen 记录已统计的指针，防止循环引用与重复计算
+func markSeen(ptr uintptr, seen map[uintptr]struct{}) bool {
+	if _, ok := seen[ptr]; ok {
+		return true
+	}
+	seen[ptr] = struct{}{}
+	return false
+}
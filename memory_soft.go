@@ -0,0 +1,108 @@
+package go_cache
+
+import (
+	"runtime/metrics"
+	"sync/atomic"
+	"weak"
+)
+
+// heapObjectsMetric 堆上存活与尚未回收对象占用的字节数
+const heapObjectsMetric = "/memory/classes/heap/objects:bytes"
+
+// WithMemorySoftValues 让大小不小于minSize的值以软引用方式保存，用于避免突发流量下的OOM
+// 堆占用超过heapLimit（字节）时，缓存释放对这些值的强引用，GC可以随时回收它们；
+// 被回收的条目视为未命中，GetSet 会重新调用回调函数，Tiered 会回退到下一层缓存。
+// Go没有真正的软引用，释放强引用后值在下一次GC时即可能被回收，heapLimit 应留有余量。
+// 压力检查在写入软引用值与 DeleteExpired 时进行；被回收的条目不会触发移除回调
+func WithMemorySoftValues(minSize int64, heapLimit uint64) MemoryOption {
+	return func(m *Memory) {
+		m.softMinSize = minSize
+		m.softHeapLimit = heapLimit
+	}
+}
+
+// softValue 软引用方式保存的值
+type softValue struct {
+	strong atomic.Pointer[any] // 释放后为nil
+	weak   weak.Pointer[any]
+}
+
+// newSoftValue 创建持有强引用的软引用值
+func newSoftValue(value any) *softValue {
+	box := &value
+	s := &softValue{weak: weak.Make(box)}
+	s.strong.Store(box)
+	return s
+}
+
+// load 返回值，已被GC回收时返回false
+func (s *softValue) load() (any, bool) {
+	if box := s.strong.Load(); box != nil {
+		return *box, true
+	}
+	if box := s.weak.Value(); box != nil {
+		return *box, true
+	}
+	return nil, false
+}
+
+// soften 释放强引用，之后GC可以回收值
+func (s *softValue) soften() {
+	s.strong.Store(nil)
+}
+
+// load 返回条目的值，软引用值已被GC回收时返回false
+func (e *memoryEntry) load() (any, bool) {
+	if e.soft == nil {
+		return e.value, true
+	}
+	return e.soft.load()
+}
+
+// collected 判断条目的软引用值是否已被GC回收
+func (e *memoryEntry) collected() bool {
+	if e.soft == nil {
+		return false
+	}
+	_, ok := e.soft.load()
+	return !ok
+}
+
+// makeSoft 条目大小达到阈值时改为软引用方式保存
+func (c *Memory) makeSoft(entry *memoryEntry) {
+	if c.softMinSize <= 0 || entry.size < c.softMinSize {
+		return
+	}
+	entry.soft = newSoftValue(entry.value)
+	entry.value = nil
+}
+
+// underPressure 判断堆占用是否超过软引用的上限
+func (c *Memory) underPressure() bool {
+	sample := []metrics.Sample{{Name: heapObjectsMetric}}
+	metrics.Read(sample)
+	if sample[0].Value.Kind() != metrics.KindUint64 {
+		return false
+	}
+	return sample[0].Value.Uint64() > c.softHeapLimit
+}
+
+// softenLocked 存在内存压力时释放所有软引用值的强引用，并清理已被回收的条目
+func (c *Memory) softenLocked() {
+	if c.softMinSize <= 0 {
+		return
+	}
+	pressure := c.underPressure()
+	for key, entry := range c.entries {
+		if entry.soft == nil {
+			continue
+		}
+		if pressure {
+			entry.soft.soften()
+		}
+		if entry.collected() {
+			c.removeLocked(entry)
+			c.cache.Delete(key)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// UniqueAdd 将ids加入key的HyperLogLog，返回估计值是否变化
+// 键已有过期时间时保留原有的过期时间；键保存的不是HyperLogLog时返回 ErrTypeMismatch
+func (c *Memory) UniqueAdd(ctx context.Context, key string, ids ...string) (bool, error) {
+	c.mu.Lock()
+	defer c.unlock()
+
+	current, found := c.lookup(key)
+	hll, err := c.hyperLogLog(current, found, key)
+	if err != nil {
+		return false, err
+	}
+
+	next := hll.clone()
+	changed := !found
+	for _, id := range ids {
+		if next.add(hllHash(id)) {
+			changed = true
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+
+	entry, err := c.newEntry(key, next, PriorityNormal)
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, err
+	}
+	ttl := time.Duration(-1)
+	if found {
+		ttl = c.remainingTTL(current)
+	}
+	c.syncEvictedLocked()
+	c.storeLocked(entry, ttl)
+	c.evictLocked()
+	c.stats.RecordSet(key, int(entry.size))
+	return true, nil
+}
+
+// UniqueCount 返回keys并集中不重复元素数的估计值
+func (c *Memory) UniqueCount(ctx context.Context, keys ...string) (int64, error) {
+	union := newHyperLogLog()
+	for _, key := range keys {
+		entry, found := c.lookup(key)
+		hll, err := c.hyperLogLog(entry, found, key)
+		if err != nil {
+			return 0, err
+		}
+		if hll != nil {
+			union.merge(hll)
+		}
+	}
+	return union.count(), nil
+}
+
+// hyperLogLog 返回条目保存的HyperLogLog，条目不存在时返回nil
+func (c *Memory) hyperLogLog(entry *memoryEntry, found bool, key string) (*hyperLogLog, error) {
+	if !found {
+		return nil, nil
+	}
+	value, ok := entry.load()
+	if !ok {
+		return nil, nil
+	}
+	hll, ok := value.(*hyperLogLog)
+	if !ok {
+		return nil, fmt.Errorf("%w: key %s does not hold a HyperLogLog", ErrTypeMismatch, key)
+	}
+	return hll, nil
+}
@@ -8,15 +8,72 @@ import (
 	"github.com/muleiwu/gsr"
 )
 
+// ErrNotImplemented None 缓存在 NoneStrict 模式下 Get 返回的错误
+var ErrNotImplemented = errors.New("not implemented")
+
+// NoneMode None缓存的行为模式
+type NoneMode int
+
+const (
+	// NoneStrict Get 返回 ErrNotImplemented（默认）
+	NoneStrict NoneMode = iota
+	// NonePassThrough 相当于始终未命中的缓存：Get 返回 ErrKeyNotFound，
+	// GetSet 调用回调函数并返回其结果，不保存。通过配置关闭缓存时调用方无需修改代码
+	NonePassThrough
+)
+
+// String 返回模式名称
+func (m NoneMode) String() string {
+	switch m {
+	case NoneStrict:
+		return "strict"
+	case NonePassThrough:
+		return "passthrough"
+	}
+	return "unknown"
+}
+
+// NoneOption None缓存选项
+type NoneOption func(*None)
+
+// WithNoneMode 设置None缓存的行为模式，默认 NoneStrict
+func WithNoneMode(mode NoneMode) NoneOption {
+	return func(n *None) {
+		n.mode = mode
+	}
+}
+
+// WithNoneStrictGetSet 恢复 GetSet 的旧行为：返回 ErrNotImplemented，不调用回调函数
+// 默认情况下 GetSet 在任何模式下都调用回调函数并返回其结果，不保存
+func WithNoneStrictGetSet() NoneOption {
+	return func(n *None) {
+		n.strictGetSet = true
+	}
+}
+
 type None struct {
+	mode         NoneMode
+	strictGetSet bool
 }
 
-func NewCacheNone() *None {
-	return NewNone()
+func NewCacheNone(opts ...NoneOption) *None {
+	return NewNone(opts...)
 }
 
-func NewNone() *None {
-	return &None{}
+func NewNone(opts ...NoneOption) *None {
+	n := &None{}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Mode 返回行为模式
+func (c *None) Mode() NoneMode {
+	return c.mode
 }
 
 func (c *None) Exists(ctx context.Context, key string) bool {
@@ -24,15 +81,27 @@ func (c *None) Exists(ctx context.Context, key string) bool {
 }
 
 func (c *None) Get(ctx context.Context, key string, obj any) error {
-	return errors.New("not implemented")
+	if c.mode == NonePassThrough {
+		return ErrKeyNotFound
+	}
+	return ErrNotImplemented
 }
 
 func (c *None) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
 	return nil
 }
 
+func (c *None) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	return nil
+}
+
+// GetSet 调用回调函数并返回其结果，不保存；使用 WithNoneStrictGetSet 时返回 ErrNotImplemented
 func (c *None) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
-	return errors.New("not implemented")
+	if c.strictGetSet {
+		return ErrNotImplemented
+	}
+	// 始终未命中，调用回调函数但不保存结果（panic会被转换为错误）
+	return loaderConfig{}.call(ctx, key, obj, fun)
 }
 
 func (c *None) Del(ctx context.Context, key string) error {
@@ -0,0 +1,24 @@
+package go_cache
+
+// Operation 缓存操作类型
+type Operation string
+
+const (
+	OpExists       Operation = "exists"
+	OpGet          Operation = "get"
+	OpSet          Operation = "set"
+	OpGetSet       Operation = "getset"
+	OpDel          Operation = "del"
+	OpExpire       Operation = "expire"
+	OpKeys         Operation = "keys"
+	OpDelByPattern Operation = "del_by_pattern"
+)
+
+// IsWrite 判断操作是否会修改缓存
+func (op Operation) IsWrite() bool {
+	switch op {
+	case OpSet, OpGetSet, OpDel, OpExpire, OpDelByPattern:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package go_cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultOTPKeyPrefix 一次性验证码在缓存中的默认键前缀
+const DefaultOTPKeyPrefix = "otp:"
+
+var (
+	// ErrOTPPending 标识下已有未过期的验证码，需等待其过期、验证或调用 RevokeOTP 后才能重新下发
+	ErrOTPPending = errors.New("otp already pending")
+	// ErrOTPMismatch 验证码不正确，验证码仍然有效
+	ErrOTPMismatch = errors.New("otp mismatch")
+	// ErrOTPExhausted 失败次数达到上限，验证码已作废
+	ErrOTPExhausted = errors.New("otp attempts exhausted")
+)
+
+// otpBackend 由能够在服务端原子校验验证码的缓存实现（如Redis使用 SET NX 与Lua脚本中的 INCR）
+type otpBackend interface {
+	storeOTP(ctx context.Context, key, hash string, ttl time.Duration) (bool, error)
+	verifyOTP(ctx context.Context, key, hash string, maxAttempts int) (int, error)
+	revokeOTP(ctx context.Context, key string) error
+}
+
+// otpState 不支持服务端校验的缓存中保存的验证码，ExpiresAt 为过期时间（Unix毫秒）
+type otpState struct {
+	Hash      string
+	Attempts  int
+	ExpiresAt int64
+}
+
+// OTPStoreOption 验证码存储选项
+type OTPStoreOption func(*OTPStore)
+
+// WithOTPMaxAttempts 设置验证码作废前允许的失败次数，默认5
+func WithOTPMaxAttempts(n int) OTPStoreOption {
+	return func(s *OTPStore) {
+		if n > 0 {
+			s.maxAttempts = n
+		}
+	}
+}
+
+// WithOTPKeyPrefix 设置验证码在缓存中的键前缀，默认 DefaultOTPKeyPrefix
+func WithOTPKeyPrefix(prefix string) OTPStoreOption {
+	return func(s *OTPStore) {
+		s.prefix = prefix
+	}
+}
+
+// WithOTPClock 设置判断验证码过期所用的时钟，默认使用系统时间
+// Redis缓存由服务端的过期时间决定，不受该选项影响
+func WithOTPClock(clock Clock) OTPStoreOption {
+	return func(s *OTPStore) {
+		if clock != nil {
+			s.clock = clock
+		}
+	}
+}
+
+// OTPStore 短信验证码、图形验证码等一次性验证码的存储
+// 缓存中只保存验证码的摘要；验证成功后验证码立即作废，失败次数达到上限后同样作废，防止暴力猜测。
+// Redis缓存下发时使用 SET NX，验证时在Lua脚本中原子比较并用 INCR 计数，多个实例并发验证也不会超过次数上限；
+// 实现 TxnCache 的缓存在事务中执行，其他缓存只在本实例内串行
+type OTPStore struct {
+	cache       gsr.Cacher
+	maxAttempts int
+	prefix      string
+	clock       Clock
+
+	mu sync.Mutex // 底层缓存不支持事务时保护状态的读-改-写
+}
+
+// NewOTPStore 创建验证码存储
+func NewOTPStore(cache gsr.Cacher, opts ...OTPStoreOption) *OTPStore {
+	s := &OTPStore{
+		cache:       cache,
+		maxAttempts: 5,
+		prefix:      DefaultOTPKeyPrefix,
+		clock:       realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// key 返回标识的键，使用哈希标签使Redis集群中计数键与验证码位于同一个槽
+func (s *OTPStore) key(id string) string {
+	return s.prefix + "{" + id + "}"
+}
+
+// hash 返回验证码的摘要，摘要包含标识，相同的验证码在不同标识下摘要不同
+func (s *OTPStore) hash(id, code string) string {
+	sum := sha256.Sum256([]byte(id + "\x00" + code))
+	return hex.EncodeToString(sum[:])
+}
+
+// StoreOTP 为标识id（如手机号、会话）保存有效期为ttl的验证码
+// 已有未过期的验证码时返回 ErrOTPPending，避免重复下发覆盖用户正在输入的验证码
+func (s *OTPStore) StoreOTP(ctx context.Context, id, code string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return fmt.Errorf("%w: otp ttl %v", ErrInvalidTTL, ttl)
+	}
+	key, hash := s.key(id), s.hash(id, code)
+
+	var stored bool
+	var err error
+	if b, ok := s.cache.(otpBackend); ok {
+		stored, err = b.storeOTP(ctx, key, hash, ttl)
+	} else {
+		err = update(ctx, s.cache, &s.mu, key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+			state, err := s.load(ctx, get, key)
+			if err != nil {
+				return nil, 0, err
+			}
+			now := s.clock.Now()
+			if stored = state == nil || state.ExpiresAt <= now.UnixMilli(); !stored {
+				return nil, 0, errNoUpdate
+			}
+			return otpState{Hash: hash, ExpiresAt: now.Add(ttl).UnixMilli()}, ttl, nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if !stored {
+		return ErrOTPPending
+	}
+	return nil
+}
+
+// VerifyOTP 验证标识id的验证码
+// 正确时返回nil并作废验证码；不正确时返回 ErrOTPMismatch，失败次数达到上限时返回 ErrOTPExhausted 并作废验证码；
+// 没有验证码或已过期时返回 ErrKeyNotFound
+func (s *OTPStore) VerifyOTP(ctx context.Context, id, code string) error {
+	key, hash := s.key(id), s.hash(id, code)
+
+	var remaining int
+	var err error
+	if b, ok := s.cache.(otpBackend); ok {
+		remaining, err = b.verifyOTP(ctx, key, hash, s.maxAttempts)
+	} else {
+		err = update(ctx, s.cache, &s.mu, key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+			state, err := s.load(ctx, get, key)
+			if err != nil {
+				return nil, 0, err
+			}
+			now := s.clock.Now().UnixMilli()
+			if state == nil || state.ExpiresAt <= now {
+				remaining = -1
+				return nil, 0, errNoUpdate
+			}
+			if subtle.ConstantTimeCompare([]byte(state.Hash), []byte(hash)) == 1 {
+				remaining = 0
+				return nil, 0, nil
+			}
+			attempts := state.Attempts + 1
+			if attempts >= s.maxAttempts {
+				remaining = -2
+				return nil, 0, nil
+			}
+			remaining = s.maxAttempts - attempts
+			return otpState{Hash: state.Hash, Attempts: attempts, ExpiresAt: state.ExpiresAt}, time.Duration(state.ExpiresAt-now) * time.Millisecond, nil
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case remaining == 0:
+		return nil
+	case remaining == -1:
+		return fmt.Errorf("%w: otp %s", ErrKeyNotFound, id)
+	case remaining == -2:
+		return ErrOTPExhausted
+	default:
+		return fmt.Errorf("%w: %d attempts left", ErrOTPMismatch, remaining)
+	}
+}
+
+// RevokeOTP 作废标识id的验证码
+func (s *OTPStore) RevokeOTP(ctx context.Context, id string) error {
+	if b, ok := s.cache.(otpBackend); ok {
+		return b.revokeOTP(ctx, s.key(id))
+	}
+	return s.cache.Del(ctx, s.key(id))
+}
+
+// load 读取验证码，不存在时返回nil
+func (s *OTPStore) load(ctx context.Context, get func(context.Context, string, any) error, key string) (*otpState, error) {
+	var state otpState
+	if err := get(ctx, key, &state); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &state, nil
+}
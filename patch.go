@@ -0,0 +1,161 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// PatchCache 支持按字段更新结构体的缓存
+// 结构体的每个导出字段单独保存（Redis中为哈希的一个字段），
+// Patch 只发送发生变化的字段，适用于频繁更新的大结构体。
+// 字段名默认为Go字段名，可通过 `cache:"name"` 标签修改，`cache:"-"` 表示跳过该字段；
+// 标签中逗号后的选项（如 `cache:"email,redact"` 中的 redact）不影响字段名，两个字段的字段名相同时返回错误
+type PatchCache interface {
+	// SetFields 保存结构体的全部字段并设置过期时间ttl，ttl <= 0 表示不过期
+	SetFields(ctx context.Context, key string, value any, ttl time.Duration) error
+	// GetFields 读取结构体到obj，obj必须是结构体指针；键不存在时返回 ErrKeyNotFound
+	GetFields(ctx context.Context, key string, obj any) error
+	// Patch 比较old与new，只写入发生变化的字段，返回写入的字段数；
+	// ttl > 0 时刷新过期时间，否则保留原有的过期时间；键不存在时返回 ErrKeyNotFound
+	Patch(ctx context.Context, key string, old, new any, ttl time.Duration) (int, error)
+}
+
+// structField 结构体中参与按字段保存的字段
+type structField struct {
+	name  string
+	value reflect.Value
+}
+
+// cacheTag 解析后的 `cache` 结构体标签
+type cacheTag struct {
+	name   string // 字段名，为空时使用Go字段名
+	skip   bool   // `cache:"-"`
+	redact bool   // 带有 redact 选项
+}
+
+// parseCacheTag 解析 `cache` 标签，格式为逗号分隔的字段名与选项，如 "email,redact"
+// 只有选项时（如 "redact"）不修改字段名
+func parseCacheTag(tag reflect.StructTag) cacheTag {
+	value := tag.Get("cache")
+	if value == "-" {
+		return cacheTag{skip: true}
+	}
+	var t cacheTag
+	for i, part := range strings.Split(value, ",") {
+		switch {
+		case part == redactTag:
+			t.redact = true
+		case i == 0:
+			t.name = part
+		}
+	}
+	return t
+}
+
+// structFields 返回结构体（或结构体指针）的导出字段，两个字段的字段名相同时返回错误
+func structFields(v any) ([]structField, error) {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, fmt.Errorf("patch: nil %s", rv.Type())
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("patch: %T is not a struct", v)
+	}
+
+	rt := rv.Type()
+	fields := make([]structField, 0, rt.NumField())
+	owners := make(map[string]string, rt.NumField())
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		tag := parseCacheTag(f.Tag)
+		if tag.skip {
+			continue
+		}
+		name := f.Name
+		if tag.name != "" {
+			name = tag.name
+		}
+		if owner, ok := owners[name]; ok {
+			return nil, fmt.Errorf("patch: %s fields %s and %s share field name %q", rt, owner, f.Name, name)
+		}
+		owners[name] = f.Name
+		fields = append(fields, structField{name: name, value: rv.Field(i)})
+	}
+	return fields, nil
+}
+
+// fieldCodec 返回序列化字段使用的序列化器，Redis启用签名时签名绑定字段名
+type fieldCodec func(field string) serializer.Serializer
+
+// encodeFields 序列化全部字段，返回 HSET 使用的字段名与值交替的参数
+func encodeFields(codec fieldCodec, v any) ([]any, error) {
+	fields, err := structFields(v)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, 0, 2*len(fields))
+	for _, f := range fields {
+		data, err := codec(f.name).Encode(f.value.Interface())
+		if err != nil {
+			return nil, fmt.Errorf("patch: encode field %s: %w", f.name, err)
+		}
+		args = append(args, f.name, data)
+	}
+	return args, nil
+}
+
+// diffFields 序列化new中与old不同的字段，返回字段名与值交替的参数
+func diffFields(codec fieldCodec, old, new any) ([]any, error) {
+	if valueType(old) != valueType(new) {
+		return nil, fmt.Errorf("patch: type mismatch: %T and %T", old, new)
+	}
+	oldArgs, err := encodeFields(codec, old)
+	if err != nil {
+		return nil, err
+	}
+	newArgs, err := encodeFields(codec, new)
+	if err != nil {
+		return nil, err
+	}
+
+	var args []any
+	for i := 0; i < len(newArgs); i += 2 {
+		if string(oldArgs[i+1].([]byte)) != string(newArgs[i+1].([]byte)) {
+			args = append(args, newArgs[i], newArgs[i+1])
+		}
+	}
+	return args, nil
+}
+
+// decodeFields 把字段名到序列化数据的映射解码到结构体指针obj，缺少的字段保持不变
+func decodeFields(codec fieldCodec, data map[string]string, obj any) error {
+	rv := reflect.ValueOf(obj)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("patch: obj must be a non-nil pointer")
+	}
+	fields, err := structFields(obj)
+	if err != nil {
+		return err
+	}
+	for _, f := range fields {
+		raw, ok := data[f.name]
+		if !ok {
+			continue
+		}
+		if err := codec(f.name).Decode([]byte(raw), f.value.Addr().Interface()); err != nil {
+			return fmt.Errorf("patch: decode field %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
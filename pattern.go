@@ -0,0 +1,182 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PatternCache 支持按模式列出与删除键的缓存
+// 模式语法与Redis的KEYS/SCAN MATCH一致：* 匹配任意字符串，? 匹配单个字符，
+// [abc]/[^abc]/[a-z] 匹配字符集合，\ 转义下一个字符
+type PatternCache interface {
+	Keys(ctx context.Context, pattern string) ([]string, error)
+	DelByPattern(ctx context.Context, pattern string) (int64, error)
+}
+
+// ErrScanLimitReached 按模式操作达到 ScanOptions 的键数或时长上限而提前停止，已返回的结果不完整
+var ErrScanLimitReached = errors.New("pattern scan limit reached")
+
+// ScanOptions 按模式操作（Keys、DelByPattern 及基于它们的 Clear 等）的限制
+// 通过 WithScanOptions 放入context，经过 TenantCache 等包装后同样生效
+type ScanOptions struct {
+	Count       int           // Redis每次SCAN的COUNT提示，<= 0 时使用500
+	MaxKeys     int           // 最多返回或删除的键数，<= 0 表示不限制
+	MaxDuration time.Duration // 最长执行时间，<= 0 表示不限制；在两批键之间检查
+	// DryRun 为true时 DelByPattern 不删除任何键，只返回将被删除的数量；
+	// 使用相同选项调用 Keys 可以得到将被删除的键，用于在执行破坏性的批量操作前预览
+	DryRun bool
+}
+
+// scanOptionsKey 按模式操作限制的context键
+type scanOptionsKey struct{}
+
+// WithScanOptions 在context中设置按模式操作的限制
+// 达到键数或时长上限时操作停止，返回已处理的部分结果与 ErrScanLimitReached
+func WithScanOptions(ctx context.Context, opts ScanOptions) context.Context {
+	return context.WithValue(ctx, scanOptionsKey{}, opts)
+}
+
+// ScanOptionsFromContext 返回context中按模式操作的限制，供自定义的 PatternCache 实现使用
+func ScanOptionsFromContext(ctx context.Context) ScanOptions {
+	opts, _ := ctx.Value(scanOptionsKey{}).(ScanOptions)
+	return opts
+}
+
+// scanLimiter 在一次按模式操作中累计键数与时长
+type scanLimiter struct {
+	opts  ScanOptions
+	start time.Time
+	taken int
+}
+
+func newScanLimiter(ctx context.Context) *scanLimiter {
+	return &scanLimiter{opts: ScanOptionsFromContext(ctx), start: time.Now()}
+}
+
+// check 检查是否已超过最长执行时间
+func (l *scanLimiter) check() error {
+	if l.opts.MaxDuration > 0 && time.Since(l.start) > l.opts.MaxDuration {
+		return fmt.Errorf("%w: exceeded %v", ErrScanLimitReached, l.opts.MaxDuration)
+	}
+	return nil
+}
+
+// take 返回batch中在键数上限内可以处理的部分，达到上限时同时返回 ErrScanLimitReached
+func (l *scanLimiter) take(batch []string) ([]string, error) {
+	if err := l.check(); err != nil {
+		return nil, err
+	}
+	if l.opts.MaxKeys <= 0 || len(batch) == 0 {
+		return batch, nil
+	}
+	remaining := l.opts.MaxKeys - l.taken
+	if len(batch) <= remaining {
+		l.taken += len(batch)
+		return batch, nil
+	}
+	l.taken = l.opts.MaxKeys
+	return batch[:remaining], fmt.Errorf("%w: more than %d keys", ErrScanLimitReached, l.opts.MaxKeys)
+}
+
+// EscapePattern 转义字符串中的模式特殊字符，使其在模式中按字面匹配
+func EscapePattern(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch r {
+		case '*', '?', '[', ']', '\\':
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// MatchPattern 判断字符串是否匹配Redis风格的glob模式
+func MatchPattern(pattern, s string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case '*':
+			// 合并连续的*
+			for len(pattern) > 0 && pattern[0] == '*' {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if MatchPattern(pattern, s[i:]) {
+					return true
+				}
+			}
+			return false
+
+		case '?':
+			if len(s) == 0 {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+
+		case '[':
+			if len(s) == 0 {
+				return false
+			}
+			matched, rest, ok := matchClass(pattern[1:], s[0])
+			if !ok || !matched {
+				return false
+			}
+			pattern, s = rest, s[1:]
+
+		case '\\':
+			if len(pattern) >= 2 {
+				pattern = pattern[1:]
+			}
+			fallthrough
+
+		default:
+			if len(s) == 0 || pattern[0] != s[0] {
+				return false
+			}
+			pattern, s = pattern[1:], s[1:]
+		}
+	}
+	return len(s) == 0
+}
+
+// matchClass 匹配字符集合，pattern为'['之后的部分
+// 返回是否匹配、集合之后剩余的模式，以及集合是否闭合
+func matchClass(pattern string, c byte) (matched bool, rest string, ok bool) {
+	negate := false
+	if len(pattern) > 0 && pattern[0] == '^' {
+		negate = true
+		pattern = pattern[1:]
+	}
+
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case pattern[i] == ']':
+			return matched != negate, pattern[i+1:], true
+		case pattern[i] == '\\' && i+1 < len(pattern):
+			i++
+			if pattern[i] == c {
+				matched = true
+			}
+		case i+2 < len(pattern) && pattern[i+1] == '-' && pattern[i+2] != ']':
+			lo, hi := pattern[i], pattern[i+2]
+			if lo > hi {
+				lo, hi = hi, lo
+			}
+			if c >= lo && c <= hi {
+				matched = true
+			}
+			i += 2
+		default:
+			if pattern[i] == c {
+				matched = true
+			}
+		}
+	}
+	return false, "", false
+}
@@ -0,0 +1,326 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultPeerBasePath 节点间请求的默认路径前缀
+const DefaultPeerBasePath = "/_gocache/"
+
+// PeerGroupOption 节点组选项
+type PeerGroupOption func(*PeerGroup)
+
+// WithPeerGroupTTL 设置负责节点在本地缓存中保存值的有效期，默认10分钟
+func WithPeerGroupTTL(d time.Duration) PeerGroupOption {
+	return func(g *PeerGroup) {
+		g.ttl = d
+	}
+}
+
+// WithPeerGroupHotTTL 设置从其他节点取回的热点值在本地保存的有效期，默认1分钟
+// 节点组用于不可变内容，热点值不会被主动失效
+func WithPeerGroupHotTTL(d time.Duration) PeerGroupOption {
+	return func(g *PeerGroup) {
+		g.hotTTL = d
+	}
+}
+
+// WithPeerGroupHotCache 设置保存热点值的缓存，默认使用内存缓存
+func WithPeerGroupHotCache(c gsr.Cacher) PeerGroupOption {
+	return func(g *PeerGroup) {
+		g.hot = c
+	}
+}
+
+// WithPeerGroupReplicas 设置一致性哈希中每个节点的虚拟节点数，默认50
+func WithPeerGroupReplicas(n int) PeerGroupOption {
+	return func(g *PeerGroup) {
+		if n > 0 {
+			g.replicas = n
+		}
+	}
+}
+
+// WithPeerGroupHTTPClient 设置请求其他节点所用的HTTP客户端
+func WithPeerGroupHTTPClient(c *http.Client) PeerGroupOption {
+	return func(g *PeerGroup) {
+		g.client = c
+	}
+}
+
+// WithPeerGroupSerializer 设置节点间传输值所用的序列化器，所有节点必须一致
+func WithPeerGroupSerializer(s serializer.Serializer) PeerGroupOption {
+	return func(g *PeerGroup) {
+		g.serializer = s
+	}
+}
+
+// WithPeerGroupBasePath 设置节点间请求的路径前缀，默认 DefaultPeerBasePath
+func WithPeerGroupBasePath(p string) PeerGroupOption {
+	return func(g *PeerGroup) {
+		if p != "" {
+			g.basePath = p
+		}
+	}
+}
+
+// PeerGroup 节点间共享只读值的分布式读缓存（groupcache风格）
+// 每个键通过一致性哈希归属于一个节点，只有负责节点会调用loader加载并写入本地缓存，
+// 其他节点通过HTTP向负责节点获取，并在本地保存一份热点副本。
+// 负责节点不可用时回退为本节点直接加载，适用于不可变内容以减轻共享后端的读压力
+type PeerGroup struct {
+	name      string
+	self      string
+	local     gsr.Cacher
+	loader    gsr.CacheCallback
+	valueType reflect.Type
+
+	ttl        time.Duration
+	hotTTL     time.Duration
+	hot        gsr.Cacher
+	replicas   int
+	client     *http.Client
+	serializer serializer.Serializer
+	basePath   string
+
+	mu   sync.RWMutex
+	ring *hashRing
+
+	flightMu sync.Mutex
+	flights  map[string]*peerCall
+}
+
+// peerCall 正在进行的加载，同一个键的并发请求共享结果
+type peerCall struct {
+	wg   sync.WaitGroup
+	data []byte
+	err  error
+}
+
+// NewPeerGroup 创建节点组
+// self 为本节点的地址（如 "http://10.0.0.1:8080"），需与 SetPeers 中的地址一致；
+// local 为负责节点保存值的缓存，loader 在未命中时加载值；
+// prototype 为值类型的零值（如 Article{}），用于在响应其他节点时创建 loader 的目标对象
+func NewPeerGroup(name, self string, local gsr.Cacher, loader gsr.CacheCallback, prototype any, opts ...PeerGroupOption) *PeerGroup {
+	g := &PeerGroup{
+		name:       name,
+		self:       self,
+		local:      local,
+		loader:     loader,
+		valueType:  reflect.TypeOf(prototype),
+		ttl:        10 * time.Minute,
+		hotTTL:     time.Minute,
+		replicas:   50,
+		client:     http.DefaultClient,
+		serializer: cache_value.GetDefaultSerializer(), // 默认使用gob
+		basePath:   DefaultPeerBasePath,
+		flights:    make(map[string]*peerCall),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(g)
+	}
+
+	if g.hot == nil {
+		g.hot = NewMemory(g.hotTTL, g.hotTTL)
+	}
+	g.ring = newHashRing(g.replicas, []string{self})
+
+	return g
+}
+
+// Name 返回节点组名称
+func (g *PeerGroup) Name() string {
+	return g.name
+}
+
+// SetPeers 设置所有节点的地址（包含本节点），节点变化时重新调用
+func (g *PeerGroup) SetPeers(peers ...string) {
+	ring := newHashRing(g.replicas, peers)
+
+	g.mu.Lock()
+	g.ring = ring
+	g.mu.Unlock()
+}
+
+// Owner 返回负责key的节点地址
+func (g *PeerGroup) Owner(key string) string {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.ring.get(key)
+}
+
+// Get 读取key的值到obj，obj必须是指针
+func (g *PeerGroup) Get(ctx context.Context, key string, obj any) error {
+	owner := g.Owner(key)
+	if owner == "" || owner == g.self {
+		return g.local.GetSet(ctx, key, g.ttl, obj, g.loader)
+	}
+
+	if err := g.hot.Get(ctx, key, obj); err == nil {
+		return nil
+	}
+
+	data, err := g.flight(key, func() ([]byte, error) {
+		return g.fetch(ctx, owner, key)
+	})
+	if err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return err
+		}
+		// 负责节点不可用，本节点直接加载
+		if err := g.loader(key, obj); err != nil {
+			return err
+		}
+		return g.setHot(ctx, key, obj)
+	}
+
+	if err := g.serializer.Decode(data, obj); err != nil {
+		return err
+	}
+	return g.setHot(ctx, key, obj)
+}
+
+// setHot 把obj指向的值保存为热点副本
+func (g *PeerGroup) setHot(ctx context.Context, key string, obj any) error {
+	value, err := pointee(obj)
+	if err != nil {
+		return err
+	}
+	return g.hot.Set(ctx, key, value, g.hotTTL)
+}
+
+// ServeHTTP 响应其他节点的请求，路径为 basePath + 组名 + "/" + 键
+func (g *PeerGroup) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rest, ok := strings.CutPrefix(r.URL.EscapedPath(), g.basePath)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	group, escapedKey, ok := strings.Cut(rest, "/")
+	if !ok || group != url.PathEscape(g.name) {
+		http.NotFound(w, r)
+		return
+	}
+	key, err := url.PathUnescape(escapedKey)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	data, err := g.flight(key, func() ([]byte, error) {
+		obj := reflect.New(g.valueType)
+		if err := g.local.GetSet(r.Context(), key, g.ttl, obj.Interface(), g.loader); err != nil {
+			return nil, err
+		}
+		return g.serializer.Encode(obj.Elem().Interface())
+	})
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, ErrKeyNotFound) {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	_, _ = w.Write(data)
+}
+
+// fetch 向负责节点请求key的值
+func (g *PeerGroup) fetch(ctx context.Context, owner, key string) ([]byte, error) {
+	u := strings.TrimSuffix(owner, "/") + g.basePath + url.PathEscape(g.name) + "/" + url.PathEscape(key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return io.ReadAll(resp.Body)
+	case http.StatusNotFound:
+		return nil, ErrKeyNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	return nil, fmt.Errorf("peer %s: %s: %s", owner, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+// flight 合并同一个键的并发加载
+func (g *PeerGroup) flight(key string, fn func() ([]byte, error)) ([]byte, error) {
+	g.flightMu.Lock()
+	if call, ok := g.flights[key]; ok {
+		g.flightMu.Unlock()
+		call.wg.Wait()
+		return call.data, call.err
+	}
+	call := &peerCall{}
+	call.wg.Add(1)
+	g.flights[key] = call
+	g.flightMu.Unlock()
+
+	call.data, call.err = fn()
+	call.wg.Done()
+
+	g.flightMu.Lock()
+	delete(g.flights, key)
+	g.flightMu.Unlock()
+
+	return call.data, call.err
+}
+
+// hashRing 一致性哈希环
+type hashRing struct {
+	hashes []uint32
+	nodes  map[uint32]string
+}
+
+// newHashRing 创建包含nodes的哈希环，每个节点有replicas个虚拟节点
+func newHashRing(replicas int, nodes []string) *hashRing {
+	r := &hashRing{nodes: make(map[uint32]string, replicas*len(nodes))}
+	for _, node := range nodes {
+		for i := 0; i < replicas; i++ {
+			h := crc32.ChecksumIEEE([]byte(strconv.Itoa(i) + node))
+			r.hashes = append(r.hashes, h)
+			r.nodes[h] = node
+		}
+	}
+	sort.Slice(r.hashes, func(i, j int) bool { return r.hashes[i] < r.hashes[j] })
+	return r
+}
+
+// get 返回负责key的节点，环为空时返回空字符串
+func (r *hashRing) get(key string) string {
+	if len(r.hashes) == 0 {
+		return ""
+	}
+	h := crc32.ChecksumIEEE([]byte(key))
+	i := sort.Search(len(r.hashes), func(i int) bool { return r.hashes[i] >= h })
+	if i == len(r.hashes) {
+		i = 0
+	}
+	return r.nodes[r.hashes[i]]
+}
@@ -0,0 +1,310 @@
+package go_cache
+
+import (
+	"context"
+	"runtime/debug"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// PrefetchLoader 批量加载预取的键，返回键到值的映射，映射中缺少的键不会写入缓存
+type PrefetchLoader func(ctx context.Context, keys []string) (map[string]any, error)
+
+// PrefetchOption 预取选项
+type PrefetchOption func(*Prefetcher)
+
+// WithPrefetchTimeout 设置一次后台预取的超时时间，默认5秒
+func WithPrefetchTimeout(d time.Duration) PrefetchOption {
+	return func(p *Prefetcher) {
+		if d > 0 {
+			p.timeout = d
+		}
+	}
+}
+
+// WithPrefetchMaxHints 设置最多记录关联关系的键数，默认10000，超出后忽略新键的 Hint
+func WithPrefetchMaxHints(n int) PrefetchOption {
+	return func(p *Prefetcher) {
+		if n > 0 {
+			p.maxHints = n
+		}
+	}
+}
+
+// WithPrefetchErrorHook 设置后台预取失败时的回调，key 为失败的一批键中的第一个
+func WithPrefetchErrorHook(hook LoaderFailureHook) PrefetchOption {
+	return func(p *Prefetcher) {
+		p.onError = hook
+	}
+}
+
+// WithPrefetchQueue 设置等待执行的预取队列的长度与队列已满时的策略，默认长度1024，丢弃新的预取
+// QueueBlock 策略下 Prefetch（以及触发预取的 Get、GetSet）会按调用方的ctx等待队列出现空位
+func WithPrefetchQueue(cfg QueueConfig) PrefetchOption {
+	return func(p *Prefetcher) {
+		p.pool.Queue = cfg
+	}
+}
+
+// WithPrefetchWorkerPool 设置执行预取的goroutine数（默认4）、预取队列与panic回调，零值字段不覆盖
+// 批量加载函数的panic总是转换为错误交给 WithPrefetchErrorHook，PanicHandler 只处理其他代码中的panic
+func WithPrefetchWorkerPool(pool WorkerPool) PrefetchOption {
+	return func(p *Prefetcher) {
+		p.pool = p.pool.merge(pool)
+	}
+}
+
+// prefetchJob 等待执行的一次预取
+type prefetchJob struct {
+	ctx      context.Context
+	keys     []string
+	priority Priority // Prefetch 提交的为 PriorityNormal，由 Hint 关联触发的为 PriorityLow
+}
+
+// Prefetcher 在后台批量预取相关联的条目
+// 应用通过 Hint 声明一组通常一起访问的键（如用户的资料、设置与权限），
+// 之后其中任意一个键经由 Get 或 GetSet 被访问时，同组其他未缓存的键在后台由一次批量加载写入缓存；
+// 也可以直接调用 Prefetch 预取指定的键。预取不阻塞调用方，失败只通过错误回调报告
+type Prefetcher struct {
+	cache    gsr.Cacher
+	loader   PrefetchLoader
+	ttl      time.Duration
+	timeout  time.Duration
+	maxHints int
+	onError  LoaderFailureHook
+	pool     WorkerPool
+
+	mu       sync.Mutex
+	hints    map[string]map[string]struct{} // 键到与其关联的键
+	inflight map[string]struct{}            // 正在预取与等待预取的键，避免重复加载
+	jobs     *asyncQueue[prefetchJob]
+}
+
+// NewPrefetcher 创建预取包装，预取的值由loader批量加载并以ttl写入cache
+func NewPrefetcher(cache gsr.Cacher, loader PrefetchLoader, ttl time.Duration, opts ...PrefetchOption) *Prefetcher {
+	p := &Prefetcher{
+		cache:    cache,
+		loader:   loader,
+		ttl:      ttl,
+		timeout:  5 * time.Second,
+		maxHints: 10000,
+		hints:    make(map[string]map[string]struct{}),
+		inflight: make(map[string]struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	pool := resolveWorkerPool(WorkerPool{Size: 4, Queue: QueueConfig{Size: 1024}}, p.pool)
+	p.jobs = newAsyncQueue("prefetch", pool, p.run, func(job prefetchJob) {
+		p.release(job.keys)
+	})
+	p.jobs.priority = func(job prefetchJob) Priority { return job.priority }
+	return p
+}
+
+// Hint 声明keys通常一起访问，之后访问其中任意一个键时预取其他键
+// 多次调用的关联关系会合并
+func (p *Prefetcher) Hint(ctx context.Context, keys ...string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, key := range keys {
+		related, ok := p.hints[key]
+		if !ok {
+			if len(p.hints) >= p.maxHints {
+				continue
+			}
+			related = make(map[string]struct{})
+			p.hints[key] = related
+		}
+		for _, other := range keys {
+			if other != key {
+				related[other] = struct{}{}
+			}
+		}
+	}
+}
+
+// Forget 删除key的关联关系
+func (p *Prefetcher) Forget(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	delete(p.hints, key)
+}
+
+// Prefetch 在后台预取keys中尚未缓存的键，不等待预取完成
+// 后台预取不受ctx取消的影响，超时时间见 WithPrefetchTimeout；队列已满时按 WithPrefetchQueue 的策略处理，
+// 丢弃策略下优先丢弃由 Hint 关联触发的预取
+func (p *Prefetcher) Prefetch(ctx context.Context, keys ...string) {
+	p.enqueue(ctx, PriorityNormal, keys)
+}
+
+// enqueue 以priority提交keys中尚未在预取的键
+func (p *Prefetcher) enqueue(ctx context.Context, priority Priority, keys []string) {
+	p.mu.Lock()
+	batch := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if _, ok := p.inflight[key]; ok {
+			continue
+		}
+		p.inflight[key] = struct{}{}
+		batch = append(batch, key)
+	}
+	p.mu.Unlock()
+	if len(batch) == 0 {
+		return
+	}
+
+	_ = p.jobs.push(ctx, prefetchJob{ctx: context.WithoutCancel(ctx), keys: batch, priority: priority})
+}
+
+// Wait 等待所有后台预取结束
+func (p *Prefetcher) Wait() {
+	_ = p.jobs.drain(context.Background())
+}
+
+// Drain 等待已提交的预取全部结束或ctx结束
+func (p *Prefetcher) Drain(ctx context.Context) error {
+	return p.jobs.drain(ctx)
+}
+
+// QueueStats 返回预取队列的统计快照
+func (p *Prefetcher) QueueStats() QueueStats {
+	return p.jobs.stats()
+}
+
+// run 执行一次预取
+func (p *Prefetcher) run(job prefetchJob) {
+	defer p.release(job.keys)
+
+	ctx, cancel := context.WithTimeout(job.ctx, p.timeout)
+	defer cancel()
+	p.fetch(ctx, job.keys)
+}
+
+// fetch 加载尚未缓存的键并写入缓存
+func (p *Prefetcher) fetch(ctx context.Context, keys []string) {
+	missing := p.missing(ctx, keys)
+	if len(missing) == 0 {
+		return
+	}
+	sort.Strings(missing)
+
+	values, err := p.load(ctx, missing)
+	if err != nil {
+		p.reportError(missing[0], err)
+		return
+	}
+	for _, key := range missing {
+		value, ok := values[key]
+		if !ok {
+			continue
+		}
+		if err := p.cache.Set(ctx, key, value, p.ttl); err != nil {
+			p.reportError(key, err)
+		}
+	}
+}
+
+// missing 返回尚未缓存的键，缓存实现 BatchCache 时一次检查全部键
+func (p *Prefetcher) missing(ctx context.Context, keys []string) []string {
+	exists := make(map[string]bool, len(keys))
+	if b, ok := p.cache.(BatchCache); ok {
+		var err error
+		if exists, err = b.ExistsMulti(ctx, keys); err != nil {
+			// 检查失败时按全部未缓存处理
+			exists = nil
+		}
+	} else {
+		for _, key := range keys {
+			exists[key] = p.cache.Exists(ctx, key)
+		}
+	}
+
+	var missing []string
+	for _, key := range keys {
+		if !exists[key] {
+			missing = append(missing, key)
+		}
+	}
+	return missing
+}
+
+// load 调用批量加载函数并将panic转换为错误
+func (p *Prefetcher) load(ctx context.Context, keys []string) (values map[string]any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &LoaderPanicError{Key: keys[0], Value: r, Stack: debug.Stack()}
+		}
+	}()
+	return p.loader(ctx, keys)
+}
+
+// release 预取结束后移除正在预取的标记
+func (p *Prefetcher) release(keys []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, key := range keys {
+		delete(p.inflight, key)
+	}
+}
+
+// reportError 调用错误回调
+func (p *Prefetcher) reportError(key string, err error) {
+	if p.onError != nil {
+		p.onError(key, err)
+	}
+}
+
+// prefetchRelated 以低优先级预取与key关联的键
+func (p *Prefetcher) prefetchRelated(ctx context.Context, key string) {
+	p.mu.Lock()
+	related := make([]string, 0, len(p.hints[key]))
+	for other := range p.hints[key] {
+		related = append(related, other)
+	}
+	p.mu.Unlock()
+
+	if len(related) > 0 {
+		p.enqueue(ctx, PriorityLow, related)
+	}
+}
+
+func (p *Prefetcher) Exists(ctx context.Context, key string) bool {
+	return p.cache.Exists(ctx, key)
+}
+
+// Get 读取缓存并预取与key关联的键
+func (p *Prefetcher) Get(ctx context.Context, key string, obj any) error {
+	err := p.cache.Get(ctx, key, obj)
+	p.prefetchRelated(ctx, key)
+	return err
+}
+
+func (p *Prefetcher) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return p.cache.Set(ctx, key, value, ttl)
+}
+
+// GetSet 调用底层缓存的 GetSet 并预取与key关联的键
+func (p *Prefetcher) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	p.prefetchRelated(ctx, key)
+	return p.cache.GetSet(ctx, key, ttl, obj, fun)
+}
+
+func (p *Prefetcher) Del(ctx context.Context, key string) error {
+	return p.cache.Del(ctx, key)
+}
+
+func (p *Prefetcher) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return p.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (p *Prefetcher) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return p.cache.ExpiresIn(ctx, key, ttl)
+}
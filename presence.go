@@ -0,0 +1,111 @@
+package go_cache
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultPresenceKeyPrefix 在线状态在缓存中的默认键前缀
+const DefaultPresenceKeyPrefix = "presence:"
+
+// presenceBackend 由能够维护在线成员索引的缓存实现（如Redis使用有序集合与Lua脚本）
+type presenceBackend interface {
+	heartbeatPresence(ctx context.Context, key, id string, ttl time.Duration) error
+	listPresence(ctx context.Context, key, prefix string) ([]string, error)
+	leavePresence(ctx context.Context, key, id string) error
+}
+
+// PresenceOption 在线状态选项
+type PresenceOption func(*Presence)
+
+// WithPresenceKeyPrefix 设置在线状态在缓存中的键前缀，默认 DefaultPresenceKeyPrefix
+func WithPresenceKeyPrefix(prefix string) PresenceOption {
+	return func(p *Presence) {
+		p.prefix = prefix
+	}
+}
+
+// Presence 基于缓存的在线状态，用于跟踪在线的用户或工作进程
+// 成员定期调用 Heartbeat，超过ttl没有心跳的成员视为离线。
+// Redis缓存使用有序集合作为索引（分数为过期时间），列出成员不需要扫描键空间；
+// 其他缓存为每个成员写入带ttl的键，列出时按模式扫描，需要实现 PatternCache
+type Presence struct {
+	cache  gsr.Cacher
+	key    string
+	prefix string
+}
+
+// NewPresence 创建名为name的在线状态
+func NewPresence(cache gsr.Cacher, name string, opts ...PresenceOption) *Presence {
+	p := &Presence{
+		cache:  cache,
+		prefix: DefaultPresenceKeyPrefix,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	p.key = p.prefix + name
+	return p
+}
+
+// Heartbeat 记录成员id在线，ttl 内没有再次心跳时视为离线
+// ttl <= 0 时返回 ErrInvalidTTL
+func (p *Presence) Heartbeat(ctx context.Context, id string, ttl time.Duration) error {
+	if ttl <= 0 {
+		return ErrInvalidTTL
+	}
+	if b, ok := p.cache.(presenceBackend); ok {
+		return b.heartbeatPresence(ctx, p.key, id, ttl)
+	}
+	if _, ok := p.cache.(PatternCache); !ok {
+		return ErrNotSupported
+	}
+	return p.cache.Set(ctx, p.memberKey(id), true, ttl)
+}
+
+// Leave 立即将成员id标记为离线
+func (p *Presence) Leave(ctx context.Context, id string) error {
+	if b, ok := p.cache.(presenceBackend); ok {
+		return b.leavePresence(ctx, p.key, id)
+	}
+	return p.cache.Del(ctx, p.memberKey(id))
+}
+
+// ListAlive 返回以prefix开头的在线成员，按id排序；prefix 为空时返回全部在线成员
+// 底层缓存既不维护索引也不支持 PatternCache 时返回 ErrNotSupported
+func (p *Presence) ListAlive(ctx context.Context, prefix string) ([]string, error) {
+	var ids []string
+	if b, ok := p.cache.(presenceBackend); ok {
+		var err error
+		if ids, err = b.listPresence(ctx, p.key, prefix); err != nil {
+			return nil, err
+		}
+	} else {
+		pc, ok := p.cache.(PatternCache)
+		if !ok {
+			return nil, ErrNotSupported
+		}
+		keys, err := pc.Keys(ctx, EscapePattern(p.memberKey(prefix))+"*")
+		if err != nil {
+			return nil, err
+		}
+		ids = make([]string, 0, len(keys))
+		for _, key := range keys {
+			ids = append(ids, strings.TrimPrefix(key, p.memberKey("")))
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// memberKey 返回成员id的键
+func (p *Presence) memberKey(id string) string {
+	return p.key + ":" + id
+}
@@ -0,0 +1,30 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+)
+
+// Priority 缓存条目优先级
+// 内存压力或负载保护时优先淘汰/丢弃低优先级的条目
+type Priority int
+
+const (
+	PriorityLow      Priority = iota // 低优先级，最先被淘汰
+	PriorityNormal                   // 默认优先级
+	PriorityHigh                     // 高优先级，负载保护期间视为关键写入
+	PriorityCritical                 // 关键优先级，最后被淘汰
+
+	// priorityLevels 优先级数量
+	priorityLevels = int(PriorityCritical) + 1
+)
+
+// PrioritySetter 支持按优先级写入的缓存
+type PrioritySetter interface {
+	SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error
+}
+
+// clampPriority 将优先级限制在有效范围内
+func clampPriority(p Priority) Priority {
+	return min(max(p, PriorityLow), PriorityCritical)
+}
@@ -0,0 +1,29 @@
+package go_cache
+
+import "context"
+
+// PubSubCache 支持发布订阅的缓存
+// Redis缓存使用Redis的发布订阅，在多个实例之间传递消息；内存缓存使用进程内的消息总线。
+// 消息的投递语义与Redis的发布订阅相同：只有发布时已订阅的订阅者能收到，不保存历史消息
+type PubSubCache interface {
+	// Publish 向频道channel发布消息msg，Redis缓存使用缓存的序列化器编码
+	Publish(ctx context.Context, channel string, msg any) error
+	// Subscribe 订阅频道channel，在单独的goroutine中按发布顺序调用handler
+	// 调用返回的函数或ctx结束时取消订阅，返回的函数等待正在执行的handler结束，不能在handler中调用
+	Subscribe(ctx context.Context, channel string, handler MessageHandler) (func(), error)
+}
+
+// MessageHandler 订阅消息的处理函数
+type MessageHandler func(msg *Message)
+
+// Message 订阅收到的消息
+type Message struct {
+	Channel string
+
+	decode func(obj any) error
+}
+
+// Decode 将消息解码到obj，obj 为发布时的值类型的指针
+func (m *Message) Decode(obj any) error {
+	return m.decode(obj)
+}
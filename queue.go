@@ -0,0 +1,294 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrQueueFull 异步队列已满，任务按 QueueDropNewest 策略被丢弃
+var ErrQueueFull = errors.New("async queue full")
+
+// ErrQueueClosed 异步队列已关闭，不再接收任务
+var ErrQueueClosed = errors.New("async queue closed")
+
+// QueuePolicy 异步队列已满时的处理策略
+type QueuePolicy int
+
+// 任务带有优先级时（如预取），两种丢弃策略都先丢弃优先级最低的任务：
+// 新任务的优先级高于队列中最低的优先级时丢弃队列中的任务为其腾出位置，低于时丢弃新任务，
+// 相同时按策略丢弃最新或最早的任务
+const (
+	// QueueDropNewest 丢弃新提交的任务，提交方不会被阻塞（默认）
+	QueueDropNewest QueuePolicy = iota
+	// QueueDropOldest 丢弃队列中最早的任务为新任务腾出位置，适合只关心最新状态的任务
+	QueueDropOldest
+	// QueueBlock 阻塞提交方直到队列有空位或ctx结束，不丢弃任务但会把压力传递给调用方，不考虑优先级
+	QueueBlock
+)
+
+// String 返回策略名称
+func (p QueuePolicy) String() string {
+	switch p {
+	case QueueDropOldest:
+		return "drop_oldest"
+	case QueueBlock:
+		return "block"
+	default:
+		return "drop_newest"
+	}
+}
+
+// QueueConfig 异步队列配置
+type QueueConfig struct {
+	Size   int         // 等待执行的任务数上限，<= 0 时使用功能的默认值
+	Policy QueuePolicy // 队列已满时的处理策略
+}
+
+// QueueStats 异步队列的统计快照
+type QueueStats struct {
+	Depth     int    // 等待执行的任务数
+	Capacity  int    // 队列长度上限
+	Active    int    // 正在运行的执行goroutine数
+	Enqueued  uint64 // 累计接收的任务数
+	Processed uint64 // 累计执行完成的任务数
+	Dropped   uint64 // 累计丢弃的任务数（队列已满、已关闭或等待时ctx结束）
+}
+
+// asyncQueue 后台功能共用的有界队列
+// 最多 workers 个goroutine按提交顺序取出任务执行，队列为空时goroutine退出，
+// 因此不使用时不需要关闭；被丢弃的任务交给 onDrop 做清理
+type asyncQueue[T any] struct {
+	feature string
+	size    int
+	policy  QueuePolicy
+	workers int
+	onPanic WorkerPanicHandler
+	handle  func(T)
+	onDrop  func(T)
+
+	// priority 返回任务的优先级，为nil时所有任务视为 PriorityNormal
+	priority func(T) Priority
+
+	mu      sync.Mutex
+	items   []T
+	active  int
+	pending int // 等待与正在执行的任务数
+	closed  bool
+	notFull chan struct{} // 队列出现空位时关闭，唤醒 QueueBlock 策略下等待的提交方
+	idle    chan struct{} // pending 降为0时关闭，唤醒 drain
+
+	enqueued  atomic.Uint64
+	processed atomic.Uint64
+	dropped   atomic.Uint64
+}
+
+// newAsyncQueue 按执行配置创建功能feature的异步队列
+func newAsyncQueue[T any](feature string, pool WorkerPool, handle func(T), onDrop func(T)) *asyncQueue[T] {
+	return &asyncQueue[T]{
+		feature: feature,
+		size:    max(pool.Queue.Size, 1),
+		policy:  pool.Queue.Policy,
+		workers: max(pool.Size, 1),
+		onPanic: pool.PanicHandler,
+		handle:  handle,
+		onDrop:  onDrop,
+	}
+}
+
+// push 提交任务，被丢弃时返回 ErrQueueFull、ErrQueueClosed 或ctx的错误
+func (q *asyncQueue[T]) push(ctx context.Context, item T) error {
+	q.mu.Lock()
+	for {
+		if q.closed {
+			q.mu.Unlock()
+			q.drop(item)
+			return ErrQueueClosed
+		}
+		if len(q.items) < q.size {
+			break
+		}
+
+		switch q.policy {
+		case QueueDropNewest, QueueDropOldest:
+			victim := q.victimLocked()
+			if q.priorityOf(item) < q.priorityOf(q.items[victim]) ||
+				(q.policy == QueueDropNewest && q.priorityOf(item) == q.priorityOf(q.items[victim])) {
+				q.mu.Unlock()
+				q.drop(item)
+				return ErrQueueFull
+			}
+			evicted := q.items[victim]
+			q.removeLocked(victim)
+			q.pending--
+			q.mu.Unlock()
+			q.drop(evicted)
+			q.mu.Lock()
+		case QueueBlock:
+			if q.notFull == nil {
+				q.notFull = make(chan struct{})
+			}
+			wait := q.notFull
+			q.mu.Unlock()
+			select {
+			case <-wait:
+			case <-ctx.Done():
+				q.drop(item)
+				return ctx.Err()
+			}
+			q.mu.Lock()
+		default:
+			q.mu.Unlock()
+			q.drop(item)
+			return ErrQueueFull
+		}
+	}
+
+	q.items = append(q.items, item)
+	q.pending++
+	q.enqueued.Add(1)
+	if q.active < q.workers {
+		q.active++
+		go q.run()
+	}
+	q.mu.Unlock()
+	return nil
+}
+
+// run 执行任务直到队列为空
+func (q *asyncQueue[T]) run() {
+	q.mu.Lock()
+	for len(q.items) > 0 {
+		item := q.items[0]
+		q.popLocked()
+		q.mu.Unlock()
+
+		q.exec(item)
+
+		q.mu.Lock()
+	}
+	q.active--
+	q.mu.Unlock()
+}
+
+// exec 执行一个任务，任务panic时同样完成计数
+// 设置了panic回调时恢复panic并交给回调，否则panic照常向上传播
+func (q *asyncQueue[T]) exec(item T) {
+	defer func() {
+		if q.onPanic != nil {
+			if r := recover(); r != nil {
+				q.onPanic(&WorkerPanicError{Feature: q.feature, Value: r, Stack: debug.Stack()})
+			}
+		}
+		q.processed.Add(1)
+		q.mu.Lock()
+		q.pending--
+		if q.pending == 0 && q.idle != nil {
+			close(q.idle)
+			q.idle = nil
+		}
+		q.mu.Unlock()
+	}()
+	q.handle(item)
+}
+
+// popLocked 移除队首的任务并唤醒等待空位的提交方
+func (q *asyncQueue[T]) popLocked() {
+	q.removeLocked(0)
+}
+
+// removeLocked 移除队列中第i个任务并唤醒等待空位的提交方
+func (q *asyncQueue[T]) removeLocked(i int) {
+	var zero T
+	if i == 0 {
+		q.items[0] = zero
+		q.items = q.items[1:]
+	} else {
+		last := len(q.items) - 1
+		copy(q.items[i:], q.items[i+1:])
+		q.items[last] = zero
+		q.items = q.items[:last]
+	}
+	if q.notFull != nil {
+		close(q.notFull)
+		q.notFull = nil
+	}
+}
+
+// victimLocked 返回队列已满时最先丢弃的队列中任务的位置：优先级最低的任务中，
+// QueueDropOldest 策略下取最早的一个，QueueDropNewest 策略下取最新的一个
+func (q *asyncQueue[T]) victimLocked() int {
+	victim := 0
+	for i := 1; i < len(q.items); i++ {
+		p, lowest := q.priorityOf(q.items[i]), q.priorityOf(q.items[victim])
+		if p < lowest || (p == lowest && q.policy == QueueDropNewest) {
+			victim = i
+		}
+	}
+	return victim
+}
+
+// priorityOf 返回任务的优先级
+func (q *asyncQueue[T]) priorityOf(item T) Priority {
+	if q.priority == nil {
+		return PriorityNormal
+	}
+	return q.priority(item)
+}
+
+// drop 记录并清理被丢弃的任务
+func (q *asyncQueue[T]) drop(item T) {
+	q.dropped.Add(1)
+	if q.onDrop != nil {
+		q.onDrop(item)
+	}
+}
+
+// drain 等待已提交的任务全部执行完毕或ctx结束
+func (q *asyncQueue[T]) drain(ctx context.Context) error {
+	q.mu.Lock()
+	if q.pending == 0 {
+		q.mu.Unlock()
+		return nil
+	}
+	if q.idle == nil {
+		q.idle = make(chan struct{})
+	}
+	idle := q.idle
+	q.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// close 停止接收任务，已提交的任务继续执行；等待空位的提交方返回 ErrQueueClosed
+func (q *asyncQueue[T]) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	if q.notFull != nil {
+		close(q.notFull)
+		q.notFull = nil
+	}
+}
+
+// stats 返回统计快照
+func (q *asyncQueue[T]) stats() QueueStats {
+	q.mu.Lock()
+	depth, active := len(q.items), q.active
+	q.mu.Unlock()
+	return QueueStats{
+		Depth:     depth,
+		Capacity:  q.size,
+		Active:    active,
+		Enqueued:  q.enqueued.Load(),
+		Processed: q.processed.Load(),
+		Dropped:   q.dropped.Load(),
+	}
+}
@@ -0,0 +1,209 @@
+package go_cache
+
+import (
+	"reflect"
+	"sync"
+)
+
+const (
+	// RedactedValue 字符串字段脱敏后的值
+	RedactedValue = "[REDACTED]"
+
+	// redactTag 标记需要脱敏字段的 `cache` 标签选项，例如 `cache:"redact"` 或 `cache:"email,redact"`
+	redactTag = "redact"
+
+	// maxRedactDepth Redact 递归的最大深度
+	maxRedactDepth = 16
+)
+
+// redactionRules 按类型注册的脱敏规则
+var redactionRules sync.Map // reflect.Type -> redactionRule
+
+// redactionRule 单个类型的脱敏规则
+type redactionRule struct {
+	fields   map[string]struct{}
+	redactor func(value any) any
+}
+
+// RegisterRedactedFields 为类型注册需要脱敏的字段，适用于无法添加结构体标签的第三方类型
+// sample 为该类型的任意值（结构体或结构体指针）
+func RegisterRedactedFields(sample any, fields ...string) {
+	t := indirectType(reflect.TypeOf(sample))
+	rule := loadRedactionRule(t)
+	merged := make(map[string]struct{}, len(rule.fields)+len(fields))
+	for f := range rule.fields {
+		merged[f] = struct{}{}
+	}
+	for _, f := range fields {
+		merged[f] = struct{}{}
+	}
+	rule.fields = merged
+	redactionRules.Store(t, rule)
+}
+
+// RegisterRedactor 为类型注册自定义脱敏函数，例如只保留邮箱域名
+// 返回值会替换原值出现在诊断输出中
+func RegisterRedactor(sample any, redactor func(value any) any) {
+	t := reflect.TypeOf(sample)
+	rule := loadRedactionRule(t)
+	rule.redactor = redactor
+	redactionRules.Store(t, rule)
+}
+
+// Redact 返回适合写入日志或诊断输出的值
+// 带有 `cache:"redact"`（或带字段名的 `cache:"email,redact"`）标签或通过 RegisterRedactedFields 注册的字段会被脱敏：
+// 字符串字段替换为 RedactedValue，其他类型的字段置为零值。原值不会被修改；
+// 未导出字段无法被修改，因此不会被脱敏
+func Redact(value any) any {
+	if value == nil {
+		return nil
+	}
+	out := redactValue(reflect.ValueOf(value), 0)
+	if !out.IsValid() || !out.CanInterface() {
+		return value
+	}
+	return out.Interface()
+}
+
+// redactValue 递归复制并脱敏值
+func redactValue(v reflect.Value, depth int) reflect.Value {
+	if !v.IsValid() || depth > maxRedactDepth {
+		return v
+	}
+
+	if rule, ok := redactionRules.Load(v.Type()); ok && rule.(redactionRule).redactor != nil && v.CanInterface() {
+		return reflect.ValueOf(rule.(redactionRule).redactor(v.Interface()))
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		elem := redactValue(v.Elem(), depth+1)
+		if !elem.IsValid() {
+			// 脱敏函数返回nil
+			return reflect.Zero(v.Type())
+		}
+		if elem.Type() != v.Type().Elem() {
+			return elem
+		}
+		ptr := reflect.New(elem.Type())
+		ptr.Elem().Set(elem)
+		return ptr
+
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		return redactValue(v.Elem(), depth+1)
+
+	case reflect.Struct:
+		return redactStruct(v, depth)
+
+	case reflect.Slice:
+		if v.IsNil() || !hasRedactable(v.Type().Elem()) {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			setRedacted(out.Index(i), redactValue(v.Index(i), depth+1))
+		}
+		return out
+
+	case reflect.Array:
+		if !hasRedactable(v.Type().Elem()) {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			setRedacted(out.Index(i), redactValue(v.Index(i), depth+1))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() || !hasRedactable(v.Type().Elem()) {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			elem := redactValue(iter.Value(), depth+1)
+			if elem.IsValid() && elem.Type().AssignableTo(v.Type().Elem()) {
+				out.SetMapIndex(iter.Key(), elem)
+			}
+		}
+		return out
+	}
+
+	return v
+}
+
+// redactStruct 复制结构体并脱敏字段
+func redactStruct(v reflect.Value, depth int) reflect.Value {
+	t := v.Type()
+	rule := loadRedactionRule(t)
+
+	out := reflect.New(t).Elem()
+	out.Set(v)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		target := out.Field(i)
+
+		_, registered := rule.fields[field.Name]
+		if registered || parseCacheTag(field.Tag).redact {
+			if target.Kind() == reflect.String {
+				target.SetString(RedactedValue)
+			} else {
+				target.Set(reflect.Zero(field.Type))
+			}
+			continue
+		}
+		if hasRedactable(field.Type) {
+			setRedacted(target, redactValue(v.Field(i), depth+1))
+		}
+	}
+	return out
+}
+
+// setRedacted 在类型兼容时写入脱敏后的值，脱敏函数返回nil时写入零值
+func setRedacted(target, value reflect.Value) {
+	if !value.IsValid() {
+		target.Set(reflect.Zero(target.Type()))
+		return
+	}
+	if value.Type().AssignableTo(target.Type()) {
+		target.Set(value)
+	}
+}
+
+// hasRedactable 判断类型是否可能包含需要脱敏的数据
+func hasRedactable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		return hasRedactable(t.Elem())
+	case reflect.Interface, reflect.Struct:
+		return true
+	}
+	_, ok := redactionRules.Load(t)
+	return ok
+}
+
+// loadRedactionRule 读取类型的脱敏规则
+func loadRedactionRule(t reflect.Type) redactionRule {
+	if rule, ok := redactionRules.Load(t); ok {
+		return rule.(redactionRule)
+	}
+	return redactionRule{}
+}
+
+// indirectType 返回指针指向的类型
+func indirectType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
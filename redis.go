@@ -1,19 +1,43 @@
 package go_cache
 
 import (
+	"bytes"
 	"context"
-	"reflect"
+	"errors"
+	"fmt"
+	"sync"
 	"time"
 
 	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/scripts"
 	"github.com/muleiwu/go-cache/serializer"
 	"github.com/muleiwu/gsr"
 	"github.com/redis/go-redis/v9"
 )
 
 type Redis struct {
-	conn       *redis.Client
+	conn       redis.UniversalClient
 	serializer serializer.Serializer
+	stats      *StatsCollector
+	compat     RedisCompat
+	loader     loaderConfig
+	signingKey []byte
+	strict     bool
+	schemas    *SchemaRegistry
+	maxOps     int
+	maxBytes   int
+	router     RedisRouter
+
+	blobs         BlobStore // WithRedisBlobOffload 设置的对象存储
+	blobThreshold int
+	blobNamespace string // WithRedisBlobNamespace 设置的对象名称命名空间
+
+	health      *healthHook // WithRedisHealthProbe 开启的健康探测
+	onReconnect func(ctx context.Context)
+
+	featureOverride *RedisFeatures // WithRedisFeatures 指定的结果
+	featureMu       sync.Mutex     // 保护 detected
+	detected        *RedisFeatures // CompatAuto 探测的结果
 }
 
 // RedisOption Redis缓存选项
@@ -26,12 +50,72 @@ func WithRedisSerializer(s serializer.Serializer) RedisOption {
 	}
 }
 
+// WithRedisStats 设置Redis缓存的统计收集器
+// 可用于多个缓存实例共享同一个收集器
+func WithRedisStats(s *StatsCollector) RedisOption {
+	return func(r *Redis) {
+		r.stats = s
+	}
+}
+
+// WithRedisCompat 设置兼容模式
+// 服务端不支持某些命令时，相关方法会退化为兼容实现，保证功能在该服务端上可用
+func WithRedisCompat(mode RedisCompat) RedisOption {
+	return func(r *Redis) {
+		r.compat = mode
+	}
+}
+
+// WithRedisSigning 使用HMAC-SHA256对写入的值签名，读取时校验签名
+// 适用于多个团队共用的Redis实例：没有密钥的写入方写入的值在 Get 时返回 ErrInvalidSignature，
+// 而不是被解码到应用程序的结构体中。签名在序列化器（包括 WithRedisSerializer 设置的）之后进行，
+// 并覆盖值所在的键（哈希字段还覆盖字段名，消息覆盖频道名）：把签名的值复制到其他键同样返回 ErrInvalidSignature；
+// Rename 与 Copy 按目标键重新签名。签名不绑定键时写入的值同样返回 ErrInvalidSignature，需要重新写入
+func WithRedisSigning(key []byte) RedisOption {
+	return func(r *Redis) {
+		r.signingKey = bytes.Clone(key)
+	}
+}
+
+// WithRedisLoaderTimeout 设置 GetSet 等待回调函数的超时时间，超时返回 ErrLoaderTimeout，d <= 0 表示不限制
+func WithRedisLoaderTimeout(d time.Duration) RedisOption {
+	return func(r *Redis) {
+		r.loader.timeout = d
+	}
+}
+
+// WithRedisLoaderHook 设置 GetSet 回调函数失败（返回错误、panic或超时）时的回调
+func WithRedisLoaderHook(hook LoaderFailureHook) RedisOption {
+	return func(r *Redis) {
+		r.loader.onFailure = hook
+	}
+}
+
+// WithRedisStrictMode 开启严格模式
+// ttl <= 0 的写入（默认视为持久化）与设置过期时间返回 ErrInvalidTTL，
+// 解码时类型必须完全一致，否则返回 ErrTypeMismatch
+func WithRedisStrictMode() RedisOption {
+	return func(r *Redis) {
+		r.strict = true
+	}
+}
+
+// WithRedisSchemas 设置键模式注册表，严格模式下的写入与设置过期时间按注册表检查
+func WithRedisSchemas(registry *SchemaRegistry) RedisOption {
+	return func(r *Redis) {
+		r.schemas = registry
+	}
+}
+
 // NewRedis 创建Redis缓存实例
+// conn 可以是单机、哨兵或集群客户端；集群模式下按模式扫描的操作依次扫描每个主节点，
+// 一次操作多个键的命令（如 MGet、事务与Lua脚本）要求这些键使用相同的哈希标签。
 // 默认使用gob序列化器
-func NewRedis(conn *redis.Client, opts ...RedisOption) *Redis {
+func NewRedis(conn redis.UniversalClient, opts ...RedisOption) *Redis {
 	r := &Redis{
 		conn:       conn,
 		serializer: cache_value.GetDefaultSerializer(), // 默认使用gob
+		stats:      NewStatsCollector(),
 	}
 
 	// 应用选项
@@ -39,77 +123,244 @@ func NewRedis(conn *redis.Client, opts ...RedisOption) *Redis {
 		opt(r)
 	}
 
+	if r.maxOps > 0 || r.maxBytes > 0 {
+		conn.AddHook(newThrottleHook(r.maxOps, r.maxBytes))
+	}
+	if r.health != nil {
+		conn.AddHook(r.health)
+		r.health.start(conn, r.onReconnect)
+	}
+	r.detectFeatures()
+
 	return r
 }
 
+// Compat 返回兼容模式
+func (c *Redis) Compat() RedisCompat {
+	return c.compat
+}
+
+// RunScript 在缓存的连接上执行Lua脚本，供需要自定义原子操作的调用方使用
+func (c *Redis) RunScript(ctx context.Context, s *scripts.Script, keys []string, args ...any) *redis.Cmd {
+	return s.Run(ctx, c.conn, keys, args...)
+}
+
+// signer 返回签名绑定到context（如 "key" 与键名）的变换，没有启用签名时返回nil
+func (c *Redis) signer(context ...string) serializer.Transform {
+	if c.signingKey == nil {
+		return nil
+	}
+	return serializer.KeyedHMACSHA256(c.signingKey, context...)
+}
+
+// codecFor 返回读写context所指位置的值使用的序列化器，启用签名时签名绑定context
+func (c *Redis) codecFor(context ...string) serializer.Serializer {
+	if c.signingKey == nil {
+		return c.serializer
+	}
+	return serializer.NewPipeline(c.serializer, c.signer(context...))
+}
+
+// codec 返回读写key的值使用的序列化器
+func (c *Redis) codec(key string) serializer.Serializer {
+	return c.codecFor("key", key)
+}
+
+// fieldsCodec 返回读写哈希key中字段使用的序列化器
+func (c *Redis) fieldsCodec(key string) fieldCodec {
+	return func(field string) serializer.Serializer {
+		return c.codecFor("field", key, field)
+	}
+}
+
+// decode 使用key的序列化器解码，严格模式下使用严格解码
+func (c *Redis) decode(key string, data []byte, obj any) error {
+	return c.decodeWith(c.codec(key), data, obj)
+}
+
+// decodeWith 使用s解码，严格模式下使用严格解码
+func (c *Redis) decodeWith(s serializer.Serializer, data []byte, obj any) error {
+	if c.strict {
+		return serializer.DecodeStrict(s, data, obj)
+	}
+	return s.Decode(data, obj)
+}
+
+// Stats 返回缓存统计快照
+func (c *Redis) Stats() Stats {
+	return c.stats.Snapshot()
+}
+
 func (c *Redis) Exists(ctx context.Context, key string) bool {
-	exists := c.conn.Exists(ctx, key)
+	exists := c.route(ctx, OpExists, key).Exists(ctx, key)
 
 	return exists.Val() != 0
 }
 
 func (c *Redis) Get(ctx context.Context, key string, obj any) error {
-	cmd := c.conn.Get(ctx, key)
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	return c.readValue(ctx, key, c.route(ctx, OpGet, key).Get(ctx, key), obj)
+}
 
+// readValue 解析读取key的命令（GET 或 GETEX）的结果到obj，并记录命中与未命中
+func (c *Redis) readValue(ctx context.Context, key string, cmd *redis.StringCmd, obj any) error {
 	result, err := cmd.Result()
 
 	if err != nil {
-		return err
+		if errors.Is(err, redis.Nil) {
+			c.stats.RecordMiss(key)
+			// 同时满足 errors.Is(err, ErrKeyNotFound) 与 errors.Is(err, redis.Nil)
+			return fmt.Errorf("%w: %w", ErrKeyNotFound, err)
+		}
+		c.stats.RecordError(key)
+		return classifyError(err)
 	}
 
-	err = c.serializer.Decode([]byte(result), obj)
+	data, err := c.resolveBlob(ctx, result)
 	if err != nil {
-		return err
+		if errors.Is(err, ErrKeyNotFound) {
+			// 对象已被清理，视为未命中
+			c.stats.RecordMiss(key)
+			return err
+		}
+		c.stats.RecordError(key)
+		return classifyError(err)
 	}
 
+	err = c.decode(key, data, obj)
+	if err != nil {
+		c.stats.RecordError(key)
+		return serializationError(err)
+	}
+
+	c.stats.RecordHit(key, len(result))
 	return nil
 }
 
 func (c *Redis) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
-	encode, err := c.serializer.Encode(value)
+	if noStore(ctx) {
+		return nil
+	}
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return err
+	}
+	if err := c.schemas.checkSchema(c.strict, key, value, ttl); err != nil {
+		return err
+	}
+	encode, rawSize, err := encodeSized(c.codec(key), value)
 	if err != nil {
 		return err
 	}
 	if ttl <= 0 {
 		ttl = 0
 	}
-	cmd := c.conn.Set(ctx, key, string(encode), ttl)
-	return cmd.Err()
+	if c.blobs != nil {
+		err = c.setBlob(ctx, key, encode, ttl)
+	} else {
+		err = c.route(ctx, OpSet, key).Set(ctx, key, string(encode), ttl).Err()
+	}
+	if err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordSet(key, len(encode))
+	c.stats.RecordPayload(key, len(encode), rawSize)
+	return nil
 }
 
-func (c *Redis) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
-	// 先尝试从缓存获取
-	err := c.Get(ctx, key, obj)
-	if err == nil {
-		// 缓存命中，直接返回
-		return nil
-	}
+// SetWithPriority 按优先级写入缓存
+// Redis的淘汰由服务端的maxmemory-policy决定，优先级不会影响淘汰顺序
+func (c *Redis) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	return c.Set(ctx, key, value, ttl)
+}
 
-	// 缓存未命中，调用回调函数
-	err = fun(key, obj)
+// SetNX 使用 SET NX 在键不存在时写入，返回是否写入
+func (c *Redis) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return false, err
+	}
+	if err := c.schemas.checkSchema(c.strict, key, value, ttl); err != nil {
+		return false, err
+	}
+	encode, rawSize, err := encodeSized(c.codec(key), value)
 	if err != nil {
-		return err
+		return false, err
 	}
+	value, name, err := c.offloadBlob(ctx, key, encode)
+	if err != nil {
+		return false, err
+	}
+	ok, err := c.route(ctx, OpSet, key).SetNX(ctx, key, value, max(ttl, 0)).Result()
+	if name != "" && (err != nil || !ok) {
+		// 没有写入，删除刚写入的对象
+		_ = c.blobs.DeleteBlob(ctx, name)
+	}
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	if ok {
+		c.stats.RecordSet(key, len(encode))
+		c.stats.RecordPayload(key, len(encode), rawSize)
+	}
+	return ok, nil
+}
 
-	// 获取obj指向的实际值并存入缓存
-	// obj是一个指针，我们需要存储它指向的值
-	objValue := reflect.ValueOf(obj)
-	if objValue.Kind() == reflect.Ptr {
-		objValue = objValue.Elem()
+func (c *Redis) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	// 严格模式下在调用回调函数之前检查ttl
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return err
 	}
-	return c.Set(ctx, key, objValue.Interface(), ttl)
+	return c.loader.getSet(ctx, c, key, ttl, obj, fun)
+}
+
+// loaderSettings 返回 GetSet 调用回调函数的方式，包装器沿用该设置
+func (c *Redis) loaderSettings() loaderConfig {
+	return c.loader
 }
 
 func (c *Redis) Del(ctx context.Context, key string) error {
-	return c.conn.Del(ctx, key).Err()
+	var err error
+	if c.blobs != nil {
+		err = c.delBlob(ctx, key)
+	} else {
+		err = c.route(ctx, OpDel, key).Del(ctx, key).Err()
+	}
+	if err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordDelete(key)
+	return nil
 }
 
 func (c *Redis) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
-	cmd := c.conn.ExpireAt(ctx, key, expiresAt)
-	return cmd.Err()
+	ok, err := c.route(ctx, OpExpire, key).PExpireAt(ctx, key, expiresAt).Result()
+	if err != nil {
+		return classifyError(err)
+	}
+	if !ok {
+		return ErrKeyNotFound
+	}
+	return nil
 }
 
 func (c *Redis) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
-	cmd := c.conn.Expire(ctx, key, ttl)
-	return cmd.Err()
+	if err := checkTTL(c.strict, ttl); err != nil {
+		return err
+	}
+	if err := c.schemas.checkExpiry(c.strict, key, ttl); err != nil {
+		return err
+	}
+	// 使用毫秒精度，EXPIRE 会把不足1秒的TTL取整为1秒
+	ok, err := c.route(ctx, OpExpire, key).PExpire(ctx, key, ttl).Result()
+	if err != nil {
+		return classifyError(err)
+	}
+	if !ok {
+		return ErrKeyNotFound
+	}
+	return nil
 }
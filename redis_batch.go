@@ -0,0 +1,128 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// ExistsMulti 在一次往返中检查多个键是否存在
+// 多键 EXISTS 只返回存在的数量，这里通过管道为每个键发送单键 EXISTS；
+// 使用 WithRedisRouter 时按每个键的 OpExists 路由，每个客户端一次往返
+func (c *Redis) ExistsMulti(ctx context.Context, keys []string) (map[string]bool, error) {
+	result := make(map[string]bool, len(keys))
+	if len(keys) == 0 {
+		return result, nil
+	}
+
+	for conn, group := range c.routeKeys(ctx, OpExists, keys) {
+		cmds := make([]*redis.IntCmd, len(group))
+		_, err := conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range group {
+				cmds[i] = pipe.Exists(ctx, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		for i, key := range group {
+			result[key] = cmds[i].Val() != 0
+		}
+	}
+	return result, nil
+}
+
+// MGet 在一次往返中读取多个键
+// 使用管道为每个键发送 GET 而不是 MGET，集群中的键可以位于不同的槽；
+// 所有键都因请求错误失败时返回第一个错误，否则逐个键报告错误
+func (c *Redis) MGet(ctx context.Context, objs map[string]any) (map[string]error, error) {
+	if noCache(ctx) {
+		return nil, ErrCacheBypassed
+	}
+	errs := make(map[string]error)
+	if len(objs) == 0 {
+		return errs, nil
+	}
+
+	keys := make([]string, 0, len(objs))
+	for key := range objs {
+		keys = append(keys, key)
+	}
+	cmds := make(map[string]*redis.StringCmd, len(keys))
+	for conn, group := range c.routeKeys(ctx, OpGet, keys) {
+		_, _ = conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for _, key := range group {
+				cmds[key] = pipe.Get(ctx, key)
+			}
+			return nil
+		})
+	}
+
+	var first error
+	failed := 0
+	for _, key := range keys {
+		result, err := cmds[key].Result()
+		if err != nil {
+			if errors.Is(err, redis.Nil) {
+				c.stats.RecordMiss(key)
+				errs[key] = fmt.Errorf("%w: %w", ErrKeyNotFound, err)
+				continue
+			}
+			c.stats.RecordError(key)
+			errs[key] = classifyError(err)
+			if failed++; first == nil {
+				first = errs[key]
+			}
+			continue
+		}
+
+		data, err := c.resolveBlob(ctx, result)
+		if err != nil {
+			if errors.Is(err, ErrKeyNotFound) {
+				c.stats.RecordMiss(key)
+				errs[key] = err
+				continue
+			}
+			c.stats.RecordError(key)
+			errs[key] = classifyError(err)
+			continue
+		}
+		if err := c.decode(key, data, objs[key]); err != nil {
+			c.stats.RecordError(key)
+			errs[key] = serializationError(fmt.Errorf("key %s: %w", key, err))
+			continue
+		}
+		c.stats.RecordHit(key, len(result))
+	}
+	if failed == len(keys) {
+		return nil, first
+	}
+	return errs, nil
+}
+
+// DelMulti 使用 UNLINK（服务端不支持时为 DEL）删除多个键
+// 使用 WithRedisRouter 时按每个键的 OpDel 路由；集群模式下不同槽的键在管道中逐个删除
+func (c *Redis) DelMulti(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	var first error
+	for conn, group := range c.routeKeys(ctx, OpDel, keys) {
+		if _, err := c.unlink(ctx, conn, group); err != nil {
+			for _, key := range group {
+				c.stats.RecordError(key)
+			}
+			if first == nil {
+				first = err
+			}
+			continue
+		}
+		for _, key := range group {
+			c.stats.RecordDelete(key)
+		}
+	}
+	return first
+}
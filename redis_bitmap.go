@@ -0,0 +1,43 @@
+package go_cache
+
+import "context"
+
+// SetBit 使用 SETBIT 设置key在offset处的位，返回原来的值
+func (c *Redis) SetBit(ctx context.Context, key string, offset int64, value bool) (bool, error) {
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+	bit := 0
+	if value {
+		bit = 1
+	}
+	old, err := c.conn.SetBit(ctx, key, offset, bit).Result()
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	return old == 1, nil
+}
+
+// GetBit 使用 GETBIT 返回key在offset处的位
+func (c *Redis) GetBit(ctx context.Context, key string, offset int64) (bool, error) {
+	if err := checkBitOffset(offset); err != nil {
+		return false, err
+	}
+	bit, err := c.conn.GetBit(ctx, key, offset).Result()
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	return bit == 1, nil
+}
+
+// BitCount 使用 BITCOUNT 返回key中值为1的位数
+func (c *Redis) BitCount(ctx context.Context, key string) (int64, error) {
+	n, err := c.conn.BitCount(ctx, key, nil).Result()
+	if err != nil {
+		c.stats.RecordError(key)
+		return 0, classifyError(err)
+	}
+	return n, nil
+}
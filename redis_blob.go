@@ -0,0 +1,462 @@
+package go_cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/redis/go-redis/v9"
+)
+
+// blobPointerPrefix Redis中保存的对象指针的前缀，序列化器的输出不会以0字节开头
+const blobPointerPrefix = "\x00go_cache:blob:"
+
+// blobSetScript 写入值并返回被覆盖的对象指针，被覆盖的值不是指针时返回nil
+var blobSetScript = scripts.Register("go_cache:blob_set", `
+local old = redis.pcall("GET", KEYS[1])
+if tonumber(ARGV[2]) > 0 then
+	redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+else
+	redis.call("SET", KEYS[1], ARGV[1])
+end
+if type(old) == "string" and string.sub(old, 1, #ARGV[3]) == ARGV[3] then
+	return old
+end
+return false`)
+
+// blobDelScript 删除键并返回被删除的对象指针，被删除的值不是指针时返回nil
+var blobDelScript = scripts.Register("go_cache:blob_del", `
+local old = redis.pcall("GET", KEYS[1])
+redis.call("DEL", KEYS[1])
+if type(old) == "string" and string.sub(old, 1, #ARGV[1]) == ARGV[1] then
+	return old
+end
+return false`)
+
+// blobNamePrefix 缓存写入的对象名称的前缀，SweepBlobs 只检查以该前缀开头的对象
+// 设置了命名空间时对象名称为 "gcblob.<命名空间>.<随机串>"，否则为 "gcblob.<随机串>"
+const blobNamePrefix = "gcblob."
+
+// WithRedisBlobOffload 将序列化后超过threshold字节的值保存到对象存储store，Redis中只保留指向对象的指针
+// Get、MGet、GetSet、GetFields、事务与 GetIfChanged 透明地读取对象；Set、SetNX、Txn、Rename、Copy 覆盖值
+// 以及 Del 删除键时同时删除旧对象。每个对象只被一个键引用，Rename 与 Copy 为目标键复制对象。
+// 键过期，或经由 DelMulti、DelByPattern、SetFields、Patch 等其他方法删除或覆盖时，对象不会立即删除，
+// 需要定期调用 SweepBlobs 清理。多个Redis实例或数据库共用一个对象存储时，每个实例需用
+// WithRedisBlobNamespace 设置不同的命名空间，否则 SweepBlobs 会删除其他实例仍在引用的对象
+func WithRedisBlobOffload(store BlobStore, threshold int) RedisOption {
+	return func(r *Redis) {
+		r.blobs = store
+		r.blobThreshold = threshold
+	}
+}
+
+// WithRedisBlobNamespace 设置写入对象存储的对象名称中的命名空间，SweepBlobs 只清理该命名空间中的对象
+// 命名空间是对象名称的一部分，不能包含对象存储不允许的字符（如 FileBlobStore 不允许 "/"）；
+// 修改命名空间后，之前写入的对象不再被 SweepBlobs 清理
+func WithRedisBlobNamespace(namespace string) RedisOption {
+	return func(r *Redis) {
+		r.blobNamespace = namespace
+	}
+}
+
+// blobPrefix 返回本实例写入的对象名称的前缀
+func (c *Redis) blobPrefix() string {
+	if c.blobNamespace == "" {
+		return blobNamePrefix
+	}
+	return blobNamePrefix + c.blobNamespace + "."
+}
+
+// ownsBlob 判断对象是否由本实例的命名空间写入
+// 随机串不包含 "."，前缀之后仍有 "." 的对象属于以本命名空间开头的其他命名空间
+func (c *Redis) ownsBlob(name string) bool {
+	nonce, ok := strings.CutPrefix(name, c.blobPrefix())
+	return ok && nonce != "" && !strings.Contains(nonce, ".")
+}
+
+// newBlobName 生成随机的对象名称，每次写入使用不同的对象
+func (c *Redis) newBlobName() (string, error) {
+	nonce, err := newLeaseToken()
+	if err != nil {
+		return "", err
+	}
+	return c.blobPrefix() + nonce, nil
+}
+
+// blobName 返回指针指向的对象名称，value 不是指针时返回false
+func blobName(value string) (string, bool) {
+	return strings.CutPrefix(value, blobPointerPrefix)
+}
+
+// offloadBlob 值超过阈值时写入对象存储并返回指针与对象名称，否则原样返回
+func (c *Redis) offloadBlob(ctx context.Context, key string, encode []byte) (string, string, error) {
+	if c.blobs == nil || len(encode) <= c.blobThreshold {
+		return string(encode), "", nil
+	}
+	name, err := c.newBlobName()
+	if err != nil {
+		return "", "", err
+	}
+	if err := c.blobs.PutBlob(ctx, name, bytes.NewReader(encode), int64(len(encode))); err != nil {
+		return "", "", fmt.Errorf("put blob for key %s: %w", key, err)
+	}
+	return blobPointerPrefix + name, name, nil
+}
+
+// resolveBlob 读取值指向的对象，值不是指针时原样返回
+// 对象已被删除时返回 ErrKeyNotFound
+func (c *Redis) resolveBlob(ctx context.Context, value string) ([]byte, error) {
+	name, ok := blobName(value)
+	if !ok || c.blobs == nil {
+		return []byte(value), nil
+	}
+	return readBlob(ctx, c.blobs, name)
+}
+
+// setBlob 写入值并删除被覆盖的对象，写入失败时删除新写入的对象
+func (c *Redis) setBlob(ctx context.Context, key string, encode []byte, ttl time.Duration) error {
+	value, name, err := c.offloadBlob(ctx, key, encode)
+	if err != nil {
+		return err
+	}
+	ms := ttl.Milliseconds()
+	if ttl > 0 && ms == 0 {
+		ms = 1
+	}
+	old, err := blobSetScript.Run(ctx, c.route(ctx, OpSet, key), []string{key}, value, ms, blobPointerPrefix).Text()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		if name != "" {
+			_ = c.blobs.DeleteBlob(ctx, name)
+		}
+		return err
+	}
+	if oldName, ok := blobName(old); ok && oldName != name {
+		// 删除失败的旧对象由 SweepBlobs 清理
+		_ = c.blobs.DeleteBlob(ctx, oldName)
+	}
+	return nil
+}
+
+// delBlob 删除键与键指向的对象
+func (c *Redis) delBlob(ctx context.Context, key string) error {
+	old, err := blobDelScript.Run(ctx, c.route(ctx, OpDel, key), []string{key}, blobPointerPrefix).Text()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if name, ok := blobName(old); ok {
+		_ = c.blobs.DeleteBlob(ctx, name)
+	}
+	return nil
+}
+
+// copyBlob 值是指针时把对象复制为新的对象并返回指向新对象的指针与新对象名称，否则原样返回
+func (c *Redis) copyBlob(ctx context.Context, key, value string) (string, string, error) {
+	name, ok := blobName(value)
+	if !ok || c.blobs == nil {
+		return value, "", nil
+	}
+	data, err := readBlob(ctx, c.blobs, name)
+	if err != nil {
+		return "", "", err
+	}
+	return c.offloadBlob(ctx, key, data)
+}
+
+// isWrongType 判断错误是否为键的类型不是字符串
+func isWrongType(err error) bool {
+	return err != nil && strings.HasPrefix(err.Error(), "WRONGTYPE")
+}
+
+// blobRenameScript 按预期的值重命名键，值为指针时目标键写入新对象的指针并保留剩余有效期
+// 源键不存在时返回{-1}，值已变化时返回{0}，成功时返回{1}与目标键被覆盖的对象指针；源键不是字符串时直接 RENAME
+var blobRenameScript = scripts.Register("go_cache:blob_rename", `
+local v = redis.pcall("GET", KEYS[1])
+if type(v) == "table" then
+	redis.call("RENAME", KEYS[1], KEYS[2])
+	return {1}
+end
+if not v then
+	return {-1}
+end
+if v ~= ARGV[1] then
+	return {0}
+end
+local old = redis.pcall("GET", KEYS[2])
+local ttl = redis.call("PTTL", KEYS[1])
+redis.call("DEL", KEYS[1])
+if ttl > 0 then
+	redis.call("SET", KEYS[2], ARGV[2], "PX", ttl)
+else
+	redis.call("SET", KEYS[2], ARGV[2])
+end
+if type(old) == "string" and string.sub(old, 1, #ARGV[3]) == ARGV[3] then
+	return {1, old}
+end
+return {1}`)
+
+// blobCopyScript 按预期的值把源键复制到目标键，ARGV[2] 为目标键写入的值
+// 返回值与 blobRenameScript 相同
+var blobCopyScript = scripts.Register("go_cache:blob_copy", `
+local v = redis.call("GET", KEYS[1])
+if not v then
+	return {-1}
+end
+if v ~= ARGV[1] then
+	return {0}
+end
+local old = redis.pcall("GET", KEYS[2])
+local ttl = tonumber(ARGV[3])
+if ttl > 0 then
+	redis.call("SET", KEYS[2], ARGV[2], "PX", ttl)
+else
+	redis.call("SET", KEYS[2], ARGV[2])
+end
+if type(old) == "string" and string.sub(old, 1, #ARGV[4]) == ARGV[4] then
+	return {1, old}
+end
+return {1}`)
+
+// moveValue 为目标键准备源键的值后执行script，源键在复制期间被修改时重试
+// 值为指针时为目标键复制对象，启用签名时按目标键重新签名（见 movedValue）；
+// rename 为true时源键的对象在成功后删除；目标键被覆盖的对象同样删除
+func (c *Redis) moveValue(ctx context.Context, script *scripts.Script, key, newKey string, rename bool, args ...any) error {
+	for attempt := 0; attempt < updateMaxRetries; attempt++ {
+		value, err := c.conn.Get(ctx, key).Result()
+		if errors.Is(err, redis.Nil) {
+			return ErrKeyNotFound
+		}
+		if rename && isWrongType(err) && c.signingKey != nil {
+			return c.renameSignedHash(ctx, key, newKey)
+		}
+		if err != nil && !(rename && isWrongType(err)) {
+			return err
+		}
+		next, name, err := c.movedValue(ctx, c.signer("key", key), c.signer("key", newKey), newKey, value)
+		if err != nil {
+			return err
+		}
+		res, err := c.RunScript(ctx, script, []string{key, newKey}, append([]any{value, next}, args...)...).Slice()
+		if err != nil || len(res) == 0 || res[0] != int64(1) {
+			if name != "" {
+				_ = c.blobs.DeleteBlob(ctx, name)
+			}
+			if err != nil {
+				return err
+			}
+			if len(res) > 0 && res[0] == int64(-1) {
+				return ErrKeyNotFound
+			}
+			continue
+		}
+		if c.blobs == nil {
+			return nil
+		}
+		// 删除失败的对象由 SweepBlobs 清理
+		if old, ok := blobName(value); ok && rename {
+			_ = c.blobs.DeleteBlob(ctx, old)
+		}
+		if len(res) > 1 {
+			if old, ok := blobName(fmt.Sprint(res[1])); ok && old != name {
+				_ = c.blobs.DeleteBlob(ctx, old)
+			}
+		}
+		return nil
+	}
+	return ErrTxnConflict
+}
+
+// movedValue 返回把value移动到newKey时写入的值与新对象的名称
+// 值为指针时复制对象；启用签名时用from校验原签名并用to重新签名，签名绑定键名，原样复制的值无法通过校验
+func (c *Redis) movedValue(ctx context.Context, from, to serializer.Transform, newKey, value string) (string, string, error) {
+	if c.signingKey == nil {
+		return c.copyBlob(ctx, newKey, value)
+	}
+	data := []byte(value)
+	if name, ok := blobName(value); ok && c.blobs != nil {
+		var err error
+		if data, err = readBlob(ctx, c.blobs, name); err != nil {
+			return "", "", err
+		}
+	}
+	payload, err := from.Reverse(data)
+	if err != nil {
+		return "", "", serializationError(err)
+	}
+	signed, err := to.Forward(payload)
+	if err != nil {
+		return "", "", serializationError(err)
+	}
+	return c.offloadBlob(ctx, newKey, signed)
+}
+
+// renameSignedHash 按新键重新签名哈希的每个字段后重命名
+// 字段的签名绑定键名，直接 RENAME 后的字段无法通过校验；哈希在此期间被修改时返回 ErrTxnConflict
+func (c *Redis) renameSignedHash(ctx context.Context, key, newKey string) error {
+	var names, replaced []string
+	err := c.conn.Watch(ctx, func(tx *redis.Tx) error {
+		data, err := tx.HGetAll(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			return ErrKeyNotFound
+		}
+		ttl, err := tx.PTTL(ctx, key).Result()
+		if err != nil {
+			return err
+		}
+		fields := make([]any, 0, 2*len(data))
+		for field, value := range data {
+			next, name, err := c.movedValue(ctx, c.signer("field", key, field), c.signer("field", newKey, field), newKey, value)
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				names = append(names, name)
+			}
+			if old, ok := blobName(value); ok && c.blobs != nil {
+				replaced = append(replaced, old)
+			}
+			fields = append(fields, field, next)
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, newKey)
+			pipe.HSet(ctx, newKey, fields...)
+			if ttl > 0 {
+				pipe.PExpire(ctx, newKey, ttl)
+			}
+			pipe.Del(ctx, key)
+			return nil
+		})
+		return err
+	}, key)
+	if err != nil {
+		if c.blobs != nil {
+			c.deleteBlobs(ctx, names)
+		}
+		if errors.Is(err, redis.TxFailedErr) {
+			return ErrTxnConflict
+		}
+		return err
+	}
+	if c.blobs != nil {
+		c.deleteBlobs(ctx, replaced)
+	}
+	return nil
+}
+
+// offloadFields 把超过阈值的字段值保存到对象存储，返回写入的对象名称
+func (c *Redis) offloadFields(ctx context.Context, key string, fields []any) ([]string, error) {
+	if c.blobs == nil {
+		return nil, nil
+	}
+	var names []string
+	for i := 1; i < len(fields); i += 2 {
+		value, name, err := c.offloadBlob(ctx, key, fields[i].([]byte))
+		if err != nil {
+			c.deleteBlobs(ctx, names)
+			return nil, err
+		}
+		if name != "" {
+			fields[i] = []byte(value)
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// deleteBlobs 删除对象，删除失败的对象由 SweepBlobs 清理
+func (c *Redis) deleteBlobs(ctx context.Context, names []string) {
+	for _, name := range names {
+		_ = c.blobs.DeleteBlob(ctx, name)
+	}
+}
+
+// SweepBlobs 删除不再被任何键引用的对象（键已过期、删除或被其他方法覆盖），返回删除的数量
+// 只检查修改时间早于minAge的对象，避免删除刚写入、指针尚未写入Redis的对象；
+// 对象是否被引用由 SCAN 遍历全部键，读取字符串与哈希字段中的指针判断，而不是由对象名称推断；
+// 只检查本实例命名空间中的对象（见 WithRedisBlobNamespace），其他命名空间的对象不受影响。
+// 对象存储需实现 BlobLister，否则返回 ErrNotSupported
+func (c *Redis) SweepBlobs(ctx context.Context, minAge time.Duration) (int, error) {
+	lister, ok := c.blobs.(BlobLister)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	cutoff := time.Now().Add(-minAge)
+
+	// 先列出候选对象再遍历键：之后写入的指针只会指向新创建的对象，不会出现在候选中
+	candidates := make(map[string]bool)
+	err := lister.ListBlobs(ctx, c.blobPrefix(), func(info BlobInfo) error {
+		if c.ownsBlob(info.Name) && info.ModTime.Before(cutoff) {
+			candidates[info.Name] = true
+		}
+		return nil
+	})
+	if err != nil || len(candidates) == 0 {
+		return 0, err
+	}
+
+	mark := func(value string) {
+		if name, ok := blobName(value); ok {
+			delete(candidates, name)
+		}
+	}
+	err = scanCount(ctx, c.conn, "*", 0, func(batch []string) error {
+		gets := make([]*redis.StringCmd, len(batch))
+		_, _ = c.conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range batch {
+				gets[i] = pipe.Get(ctx, key)
+			}
+			return nil
+		})
+		var hashes []string
+		for i, cmd := range gets {
+			// 键在遍历期间删除时忽略；不是字符串时检查哈希字段，其他错误时停止，避免误删
+			switch err := cmd.Err(); {
+			case err == nil:
+				mark(cmd.Val())
+			case errors.Is(err, redis.Nil):
+			case isWrongType(err):
+				hashes = append(hashes, batch[i])
+			default:
+				return classifyError(err)
+			}
+		}
+		if len(hashes) == 0 {
+			return nil
+		}
+		vals := make([]*redis.StringSliceCmd, len(hashes))
+		_, _ = c.conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, key := range hashes {
+				vals[i] = pipe.HVals(ctx, key)
+			}
+			return nil
+		})
+		for _, cmd := range vals {
+			if err := cmd.Err(); err != nil && !isWrongType(err) {
+				return classifyError(err)
+			}
+			for _, v := range cmd.Val() {
+				mark(v)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for name := range candidates {
+		if err := c.blobs.DeleteBlob(ctx, name); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
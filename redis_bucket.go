@@ -0,0 +1,85 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// bucketScript 原子执行令牌桶与漏桶的判定，计算与 bucket.apply 保持一致
+// 使用服务端时间，避免多个实例之间的时钟偏差；状态保存在哈希的 v（令牌数或水位）与 t（毫秒）字段中
+// 返回 {是否允许, 剩余, RetryAfter毫秒, Delay毫秒}
+var bucketScript = scripts.Register("go_cache:bucket", `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local capacity = tonumber(ARGV[1])
+local interval = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call("TIME")
+local now = math.floor(tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000)
+
+local state = redis.call("HMGET", KEYS[1], "v", "t")
+local value = tonumber(state[1])
+local elapsed = 0
+if value then
+	elapsed = math.max(now - tonumber(state[2]), 0) / interval
+end
+
+local allowed, remaining, retry, delay, idle = 0, 0, 0, 0, 0
+if ARGV[4] == "token" then
+	if value then
+		value = math.min(capacity, value + elapsed)
+	else
+		value = capacity
+	end
+	if value >= n then
+		value = value - n
+		allowed = 1
+	else
+		retry = (n - value) * interval
+	end
+	remaining = math.floor(value)
+	idle = (capacity - value) * interval
+else
+	value = math.max(0, (value or 0) - elapsed)
+	if value + n <= capacity then
+		allowed = 1
+		delay = value * interval
+		value = value + n
+	else
+		retry = (value + n - capacity) * interval
+	end
+	remaining = math.floor(capacity - value)
+	idle = value * interval
+end
+
+idle = math.ceil(idle)
+if idle > 0 then
+	redis.call("HMSET", KEYS[1], "v", tostring(value), "t", now)
+	redis.call("PEXPIRE", KEYS[1], idle)
+else
+	redis.call("DEL", KEYS[1])
+end
+return {allowed, remaining, math.ceil(retry), math.ceil(delay)}`)
+
+// takeBucket 在服务端执行令牌桶与漏桶的判定
+func (c *Redis) takeBucket(ctx context.Context, key string, algo bucketAlgo, capacity int, interval time.Duration, n int) (BucketResult, error) {
+	intervalMs := float64(interval) / float64(time.Millisecond)
+	res, err := c.RunScript(ctx, bucketScript, []string{key}, capacity, intervalMs, n, string(algo)).Int64Slice()
+	if err != nil {
+		c.stats.RecordError(key)
+		return BucketResult{}, classifyError(err)
+	}
+	if len(res) != 4 {
+		return BucketResult{}, fmt.Errorf("bucket: unexpected script result %v", res)
+	}
+	return BucketResult{
+		Allowed:    res[0] == 1,
+		Remaining:  int(res[1]),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+		Delay:      time.Duration(res[3]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,287 @@
+package go_cache
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// 托管Redis的IAM认证，生成的 CredentialsFunc 用于 NewRedisCredentials：
+//
+//	creds, err := go_cache.NewRedisCredentials(ctx, go_cache.ElastiCacheIAMAuth("app", "my-cache", "us-east-1", cfg.Credentials))
+//	client := redis.NewClient(&redis.Options{Addr: addr, TLSConfig: &tls.Config{}, CredentialsProviderContext: creds.Provide})
+//
+// 各云厂商的令牌有效期为15分钟到1小时，刷新间隔应小于令牌的有效期
+
+// 各云厂商默认的令牌端点
+const (
+	// DefaultAzureTokenEndpoint Azure实例元数据服务的托管身份令牌端点
+	DefaultAzureTokenEndpoint = "http://169.254.169.254/metadata/identity/oauth2/token"
+	// DefaultGCPTokenEndpoint GCP元数据服务的默认服务账号令牌端点
+	DefaultGCPTokenEndpoint = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+)
+
+// elastiCacheTokenExpiry ElastiCache IAM认证令牌的有效期，服务端允许的最大值
+const elastiCacheTokenExpiry = 900
+
+// azureRedisResource Azure Cache for Redis 的Entra ID令牌资源
+const azureRedisResource = "https://redis.azure.com"
+
+// TokenFunc 获取访问令牌，如从云厂商的元数据服务获取
+type TokenFunc func(ctx context.Context) (string, error)
+
+// ElastiCacheOption ElastiCache IAM认证选项
+type ElastiCacheOption func(*elastiCacheAuth)
+
+// WithElastiCacheServerless 用于ElastiCache Serverless缓存，cacheName 为缓存名称
+func WithElastiCacheServerless() ElastiCacheOption {
+	return func(a *elastiCacheAuth) {
+		a.serverless = true
+	}
+}
+
+// WithElastiCacheClock 设置签名所用的时钟，默认使用系统时间
+func WithElastiCacheClock(clock Clock) ElastiCacheOption {
+	return func(a *elastiCacheAuth) {
+		if clock != nil {
+			a.clock = clock
+		}
+	}
+}
+
+// elastiCacheAuth ElastiCache IAM认证令牌的生成参数
+type elastiCacheAuth struct {
+	userID     string
+	cacheName  string
+	region     string
+	provider   aws.CredentialsProvider
+	serverless bool
+	clock      Clock
+	signer     *v4.Signer
+}
+
+// ElastiCacheIAMAuth 返回使用IAM认证连接ElastiCache（Redis OSS 或 Valkey）的凭据
+// userID 为开启了IAM认证的ElastiCache用户，cacheName 为复制组ID（Serverless 为缓存名称），
+// provider 通常为 aws.Config.Credentials。令牌是以SigV4预签名的 connect 请求，有效期15分钟，
+// 连接需要开启TLS
+func ElastiCacheIAMAuth(userID, cacheName, region string, provider aws.CredentialsProvider, opts ...ElastiCacheOption) CredentialsFunc {
+	a := &elastiCacheAuth{
+		userID:    userID,
+		cacheName: cacheName,
+		region:    region,
+		provider:  provider,
+		clock:     realClock{},
+		signer:    v4.NewSigner(),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return func(ctx context.Context) (string, string, error) {
+		token, err := a.token(ctx)
+		if err != nil {
+			return "", "", err
+		}
+		return a.userID, token, nil
+	}
+}
+
+// token 生成预签名的认证令牌
+func (a *elastiCacheAuth) token(ctx context.Context) (string, error) {
+	creds, err := a.provider.Retrieve(ctx)
+	if err != nil {
+		return "", fmt.Errorf("elasticache auth: %w", err)
+	}
+
+	query := url.Values{
+		"Action":        {"connect"},
+		"User":          {a.userID},
+		"X-Amz-Expires": {fmt.Sprint(elastiCacheTokenExpiry)},
+	}
+	if a.serverless {
+		query.Set("ResourceType", "ServerlessCache")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+a.cacheName+"/?"+query.Encode(), nil)
+	if err != nil {
+		return "", fmt.Errorf("elasticache auth: %w", err)
+	}
+
+	// 空请求体的SHA256
+	const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	signed, _, err := a.signer.PresignHTTP(ctx, creds, req, emptyPayloadHash, "elasticache", a.region, a.clock.Now().UTC())
+	if err != nil {
+		return "", fmt.Errorf("elasticache auth: %w", err)
+	}
+	return strings.TrimPrefix(signed, "http://"), nil
+}
+
+// AzureEntraAuth 返回使用Microsoft Entra ID令牌连接Azure Cache for Redis的凭据
+// 用户名为令牌中身份的对象ID（oid），密码为令牌本身；token 可使用 AzureManagedIdentityToken，
+// 或包装 azidentity 的凭据以资源 "https://redis.azure.com/.default" 获取令牌
+func AzureEntraAuth(token TokenFunc) CredentialsFunc {
+	return func(ctx context.Context) (string, string, error) {
+		t, err := token(ctx)
+		if err != nil {
+			return "", "", fmt.Errorf("azure auth: %w", err)
+		}
+		oid, err := jwtClaim(t, "oid")
+		if err != nil {
+			return "", "", fmt.Errorf("azure auth: %w", err)
+		}
+		return oid, t, nil
+	}
+}
+
+// MemorystoreIAMAuth 返回使用IAM认证连接GCP Memorystore的凭据
+// 服务端只校验访问令牌，用户名为空；token 可使用 GCPMetadataToken，
+// 或包装 golang.org/x/oauth2/google 的令牌源
+func MemorystoreIAMAuth(token TokenFunc) CredentialsFunc {
+	return func(ctx context.Context) (string, string, error) {
+		t, err := token(ctx)
+		if err != nil {
+			return "", "", fmt.Errorf("memorystore auth: %w", err)
+		}
+		return "", t, nil
+	}
+}
+
+// CloudTokenOption 元数据服务令牌选项
+type CloudTokenOption func(*cloudToken)
+
+// WithCloudTokenEndpoint 设置令牌端点，默认为云厂商元数据服务的地址
+func WithCloudTokenEndpoint(endpoint string) CloudTokenOption {
+	return func(c *cloudToken) {
+		if endpoint != "" {
+			c.endpoint = endpoint
+		}
+	}
+}
+
+// WithCloudTokenHTTPClient 设置请求令牌端点的HTTP客户端，默认 http.DefaultClient
+func WithCloudTokenHTTPClient(client *http.Client) CloudTokenOption {
+	return func(c *cloudToken) {
+		if client != nil {
+			c.client = client
+		}
+	}
+}
+
+// cloudToken 从元数据服务获取访问令牌
+type cloudToken struct {
+	endpoint string
+	query    url.Values
+	header   http.Header
+	client   *http.Client
+}
+
+func newCloudToken(endpoint string, query url.Values, header http.Header, opts []CloudTokenOption) *cloudToken {
+	c := &cloudToken{
+		endpoint: endpoint,
+		query:    query,
+		header:   header,
+		client:   http.DefaultClient,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	return c
+}
+
+// fetch 请求令牌端点，返回响应中的 access_token
+func (c *cloudToken) fetch(ctx context.Context) (string, error) {
+	u, err := url.Parse(c.endpoint)
+	if err != nil {
+		return "", err
+	}
+	if len(c.query) > 0 {
+		q := u.Query()
+		for name, values := range c.query {
+			q[name] = values
+		}
+		u.RawQuery = q.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = c.header.Clone()
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("token endpoint: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("token endpoint: empty access_token")
+	}
+	return token.AccessToken, nil
+}
+
+// AzureManagedIdentityToken 返回从Azure实例元数据服务获取托管身份令牌的 TokenFunc
+// clientID 为用户分配的托管身份的客户端ID，为空时使用系统分配的托管身份
+func AzureManagedIdentityToken(clientID string, opts ...CloudTokenOption) TokenFunc {
+	query := url.Values{
+		"api-version": {"2018-02-01"},
+		"resource":    {azureRedisResource},
+	}
+	if clientID != "" {
+		query.Set("client_id", clientID)
+	}
+	c := newCloudToken(DefaultAzureTokenEndpoint, query, http.Header{"Metadata": {"true"}}, opts)
+	return c.fetch
+}
+
+// GCPMetadataToken 返回从GCP元数据服务获取默认服务账号访问令牌的 TokenFunc
+func GCPMetadataToken(opts ...CloudTokenOption) TokenFunc {
+	c := newCloudToken(DefaultGCPTokenEndpoint, nil, http.Header{"Metadata-Flavor": {"Google"}}, opts)
+	return c.fetch
+}
+
+// jwtClaim 读取JWT载荷中的字符串声明，不校验签名
+func jwtClaim(token, name string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", errors.New("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("token payload: %w", err)
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return "", fmt.Errorf("token payload: %w", err)
+	}
+	value, _ := claims[name].(string)
+	if value == "" {
+		return "", fmt.Errorf("token has no %s claim", name)
+	}
+	return value, nil
+}
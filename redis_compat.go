@@ -0,0 +1,186 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisCompat Redis兼容模式，用于适配行为与Redis不完全一致的服务端
+// 服务端缺少某个命令时，相关方法退化为兼容实现（如 UNLINK 退化为 DEL，
+// 带条件的 PEXPIRE 退化为Lua脚本），各模式下可用的命令见 RedisFeatures
+type RedisCompat int
+
+const (
+	// CompatRedis 标准Redis 7 及以上版本（默认）
+	CompatRedis RedisCompat = iota
+
+	// CompatMiniredis miniredis，用于快速单元测试
+	// INFO stats 不提供过期与淘汰计数；键只会在调用 miniredis 的 FastForward 后过期；
+	// 不支持 CLIENT TRACKING，无法使用 RedisTracking
+	CompatMiniredis
+
+	// CompatValkey Valkey，命令与Redis 7.2 一致
+	CompatValkey
+
+	// CompatKeyDB KeyDB，基于Redis 6.2，PEXPIRE 不支持 NX、XX、GT、LT 条件
+	CompatKeyDB
+
+	// CompatDragonfly DragonflyDB，命令与Redis 7 一致
+	CompatDragonfly
+
+	// CompatAuto 创建缓存实例时在后台向服务端探测支持的命令，结果在实例内复用
+	// 探测完成前需要判断的操作等待探测结果；探测失败（如网络错误）时按 CompatRedis 处理，
+	// 并在下一次需要时重新探测
+	CompatAuto
+)
+
+// String 返回兼容模式名称
+func (m RedisCompat) String() string {
+	switch m {
+	case CompatRedis:
+		return "redis"
+	case CompatMiniredis:
+		return "miniredis"
+	case CompatValkey:
+		return "valkey"
+	case CompatKeyDB:
+		return "keydb"
+	case CompatDragonfly:
+		return "dragonfly"
+	case CompatAuto:
+		return "auto"
+	}
+	return "unknown"
+}
+
+// RedisFeatures 服务端支持的、不是所有Redis兼容服务端都提供的命令
+type RedisFeatures struct {
+	GetEx            bool // GETEX（Redis 6.2），不支持时 GetTouch 使用 GET 与 PEXPIRE
+	Unlink           bool // UNLINK（Redis 4.0），不支持时使用 DEL
+	ExpireConditions bool // PEXPIRE 的 NX、XX、GT、LT 条件（Redis 7.0），不支持时使用Lua脚本
+	InfoStats        bool // INFO stats 中的 expired_keys 与 evicted_keys
+}
+
+// features 返回兼容模式下服务端支持的命令，CompatAuto 返回与 CompatRedis 相同的结果
+func (m RedisCompat) features() RedisFeatures {
+	f := RedisFeatures{GetEx: true, Unlink: true, ExpireConditions: true, InfoStats: true}
+	switch m {
+	case CompatMiniredis:
+		f.InfoStats = false
+	case CompatKeyDB:
+		f.ExpireConditions = false
+	}
+	return f
+}
+
+// WithRedisFeatures 直接指定服务端支持的命令，优先于兼容模式
+// 适用于内置的兼容模式与服务端不一致的情况，如较旧版本的Redis
+func WithRedisFeatures(f RedisFeatures) RedisOption {
+	return func(r *Redis) {
+		r.featureOverride = &f
+	}
+}
+
+// Features 返回服务端支持的命令
+// 使用 WithRedisFeatures 时返回指定的结果，CompatAuto 时返回探测的结果（必要时先探测），
+// 其他兼容模式返回该模式的内置结果
+func (c *Redis) Features(ctx context.Context) (RedisFeatures, error) {
+	if c.featureOverride != nil {
+		return *c.featureOverride, nil
+	}
+	if c.compat != CompatAuto {
+		return c.compat.features(), nil
+	}
+
+	c.featureMu.Lock()
+	defer c.featureMu.Unlock()
+	if c.detected != nil {
+		return *c.detected, nil
+	}
+	f, err := DetectRedisFeatures(ctx, c.conn)
+	if err != nil {
+		return CompatRedis.features(), err
+	}
+	c.detected = &f
+	return f, nil
+}
+
+// detectFeatures 在后台探测服务端支持的命令，仅 CompatAuto 且没有使用 WithRedisFeatures 时探测
+// 探测期间 Features 持有 featureMu，之后需要判断的操作会等待这次探测的结果
+func (c *Redis) detectFeatures() {
+	if c.compat != CompatAuto || c.featureOverride != nil {
+		return
+	}
+	c.featureMu.Lock()
+	go func() {
+		defer c.featureMu.Unlock()
+		ctx, cancel := context.WithTimeout(context.Background(), compatProbeTimeout)
+		defer cancel()
+		if f, err := DetectRedisFeatures(ctx, c.conn); err == nil {
+			c.detected = &f
+		}
+	}()
+}
+
+// features 返回服务端支持的命令，探测失败时按 CompatRedis 处理
+func (c *Redis) features(ctx context.Context) RedisFeatures {
+	f, _ := c.Features(ctx)
+	return f
+}
+
+// compatProbeTimeout 创建缓存实例时探测的超时时间
+const compatProbeTimeout = 5 * time.Second
+
+// compatProbeKey 探测命令时使用的键，探测不会写入该键
+const compatProbeKey = "go_cache:compat:probe"
+
+// DetectRedisFeatures 向服务端探测支持的命令
+// 对一个不存在的键执行各个命令，服务端返回未知命令或参数错误时视为不支持；
+// 连接错误等其他错误直接返回
+func DetectRedisFeatures(ctx context.Context, conn redis.UniversalClient) (RedisFeatures, error) {
+	var f RedisFeatures
+	var err error
+	probe := func(args ...any) bool {
+		if err != nil {
+			return false
+		}
+		var supported bool
+		supported, err = probeCommand(conn.Do(ctx, args...).Err())
+		return supported
+	}
+
+	f.GetEx = probe("GETEX", compatProbeKey)
+	f.Unlink = probe("UNLINK", compatProbeKey)
+	f.ExpireConditions = probe("PEXPIRE", compatProbeKey, 1000, "NX")
+	if err != nil {
+		return RedisFeatures{}, err
+	}
+
+	info, err := conn.Info(ctx, "stats").Result()
+	if err != nil {
+		if _, err = probeCommand(err); err != nil {
+			return RedisFeatures{}, err
+		}
+	}
+	fields := parseInfo(info)
+	_, f.InfoStats = fields["expired_keys"]
+	return f, nil
+}
+
+// probeCommand 根据探测命令的错误判断命令是否可用
+// 键不存在的回复（nil 或 "no such key"）说明命令可用；服务端的其他错误回复说明不可用；
+// 不是服务端回复的错误（如连接失败）返回该错误
+func probeCommand(err error) (bool, error) {
+	if err == nil || errors.Is(err, redis.Nil) {
+		return true, nil
+	}
+	var redisErr redis.Error
+	if !errors.As(err, &redisErr) {
+		return false, err
+	}
+	return strings.Contains(strings.ToLower(err.Error()), "no such key"), nil
+}
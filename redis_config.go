@@ -0,0 +1,100 @@
+package go_cache
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultRedisConfigChannel 配置更新通知的默认频道
+const DefaultRedisConfigChannel = "go_cache:config"
+
+// RedisConfigNotifierOption Redis配置通知选项
+type RedisConfigNotifierOption func(*RedisConfigNotifier)
+
+// WithRedisConfigChannel 设置发布更新通知的频道，默认 DefaultRedisConfigChannel
+func WithRedisConfigChannel(channel string) RedisConfigNotifierOption {
+	return func(n *RedisConfigNotifier) {
+		if channel != "" {
+			n.channel = channel
+		}
+	}
+}
+
+// WithRedisConfigKeyspace 同时订阅前缀为prefix的键的键空间通知
+// 不经过 Config.Set 直接修改缓存的写入也能通知到 Watch；
+// 服务端需要开启键空间通知，如 notify-keyspace-events 设置为 "K$g"
+func WithRedisConfigKeyspace(prefix string) RedisConfigNotifierOption {
+	return func(n *RedisConfigNotifier) {
+		n.keyspace = "__keyspace@*__:" + EscapePattern(prefix) + "*"
+	}
+}
+
+// RedisConfigNotifier 通过Redis发布订阅在多个实例之间传递配置更新的通知
+type RedisConfigNotifier struct {
+	client   redis.UniversalClient
+	channel  string
+	keyspace string // 非空时同时订阅的键空间通知模式
+}
+
+// NewRedisConfigNotifier 创建使用client发布与订阅通知的配置通知
+func NewRedisConfigNotifier(client redis.UniversalClient, opts ...RedisConfigNotifierOption) *RedisConfigNotifier {
+	n := &RedisConfigNotifier{
+		client:  client,
+		channel: DefaultRedisConfigChannel,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(n)
+	}
+
+	return n
+}
+
+// Notify 向频道发布key
+func (n *RedisConfigNotifier) Notify(ctx context.Context, key string) error {
+	return classifyError(n.client.Publish(ctx, n.channel, key).Err())
+}
+
+// Subscribe 订阅频道（与键空间通知），在单独的goroutine中调用fn
+func (n *RedisConfigNotifier) Subscribe(fn func(key string)) (func(), error) {
+	ctx := context.Background()
+	pubsub := n.client.Subscribe(ctx, n.channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, classifyError(err)
+	}
+	if n.keyspace != "" {
+		if err := pubsub.PSubscribe(ctx, n.keyspace); err != nil {
+			pubsub.Close()
+			return nil, classifyError(err)
+		}
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range pubsub.Channel() {
+			if msg.Channel == n.channel {
+				fn(msg.Payload)
+				continue
+			}
+			// 键空间通知的频道为 "__keyspace@{db}__:{key}"
+			if _, key, ok := strings.Cut(msg.Channel, "__:"); ok {
+				fn(key)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			pubsub.Close()
+			wg.Wait()
+		})
+	}, nil
+}
@@ -0,0 +1,167 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9/auth"
+)
+
+// CredentialsFunc 获取Redis的用户名与密码，如向云厂商申请短期的IAM令牌
+type CredentialsFunc func(ctx context.Context) (username, password string, err error)
+
+// CredentialsOption 凭据轮换选项
+type CredentialsOption func(*RedisCredentials)
+
+// WithCredentialsRefreshInterval 设置定期获取新凭据的间隔，默认10分钟，应小于令牌的有效期
+func WithCredentialsRefreshInterval(d time.Duration) CredentialsOption {
+	return func(c *RedisCredentials) {
+		if d > 0 {
+			c.interval = d
+		}
+	}
+}
+
+// WithCredentialsTimeout 设置一次获取凭据的超时时间，默认10秒
+func WithCredentialsTimeout(d time.Duration) CredentialsOption {
+	return func(c *RedisCredentials) {
+		if d > 0 {
+			c.timeout = d
+		}
+	}
+}
+
+// WithCredentialsErrorHook 设置后台获取凭据失败时的回调，失败时继续使用上一次的凭据
+func WithCredentialsErrorHook(hook func(err error)) CredentialsOption {
+	return func(c *RedisCredentials) {
+		c.onError = hook
+	}
+}
+
+// RedisCredentials 运行时轮换的Redis凭据，适用于使用短期IAM令牌的托管Redis
+// 凭据在后台按间隔刷新，也可以调用 Rotate 立即刷新；不再使用时调用 Close 停止后台刷新。
+// 有两种接入 go-redis 的方式（redis.Options、UniversalOptions、ClusterOptions 均有对应字段）：
+//   - CredentialsProviderContext 设置为 Provide：新建的连接使用最新的凭据认证，
+//     已认证的连接保持有效直到被关闭，适合旧令牌在已建立的连接上继续有效的服务端
+//   - StreamingCredentialsProvider 设置为本对象：凭据变化时连接池中已有的连接由 go-redis 重新执行 AUTH。
+//     go-redis v9.16 的连接池在空闲连接等待重新认证时可能阻塞，使用该版本时请选择前一种方式
+type RedisCredentials struct {
+	fetch    CredentialsFunc
+	interval time.Duration
+	timeout  time.Duration
+	onError  func(err error)
+
+	mu        sync.Mutex
+	current   auth.Credentials
+	nextID    uint64
+	listeners map[uint64]auth.CredentialsListener
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewRedisCredentials 立即获取一次凭据并启动后台刷新，获取失败时返回错误
+func NewRedisCredentials(ctx context.Context, fetch CredentialsFunc, opts ...CredentialsOption) (*RedisCredentials, error) {
+	c := &RedisCredentials{
+		fetch:     fetch,
+		interval:  10 * time.Minute,
+		timeout:   10 * time.Second,
+		listeners: make(map[uint64]auth.CredentialsListener),
+		stop:      make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if err := c.Rotate(ctx); err != nil {
+		return nil, err
+	}
+
+	c.wg.Add(1)
+	go c.refreshLoop()
+	return c, nil
+}
+
+// Provide 返回当前的凭据，签名与 redis.Options.CredentialsProviderContext 相同
+func (c *RedisCredentials) Provide(ctx context.Context) (username, password string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	username, password = c.current.BasicAuth()
+	return username, password, nil
+}
+
+// Subscribe 返回当前的凭据并在凭据更新时通知listener，由 go-redis 在每个连接建立时调用
+func (c *RedisCredentials) Subscribe(listener auth.CredentialsListener) (auth.Credentials, auth.UnsubscribeFunc, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.nextID++
+	id := c.nextID
+	c.listeners[id] = listener
+	return c.current, func() error {
+		c.mu.Lock()
+		delete(c.listeners, id)
+		c.mu.Unlock()
+		return nil
+	}, nil
+}
+
+// Rotate 立即获取新的凭据，凭据变化时通知订阅者（go-redis 据此重新认证已有的连接）
+// 获取失败时返回错误并继续使用当前的凭据
+func (c *RedisCredentials) Rotate(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	username, password, err := c.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	creds := auth.NewBasicCredentials(username, password)
+
+	c.mu.Lock()
+	changed := c.current == nil || c.current.RawCredentials() != creds.RawCredentials()
+	c.current = creds
+	listeners := make([]auth.CredentialsListener, 0, len(c.listeners))
+	for _, listener := range c.listeners {
+		listeners = append(listeners, listener)
+	}
+	c.mu.Unlock()
+
+	if changed {
+		for _, listener := range listeners {
+			listener.OnNext(creds)
+		}
+	}
+	return nil
+}
+
+// Close 停止后台刷新，已建立的连接继续使用当前的凭据
+func (c *RedisCredentials) Close() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	c.wg.Wait()
+}
+
+// refreshLoop 定期获取新的凭据
+func (c *RedisCredentials) refreshLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if err := c.Rotate(context.Background()); err != nil && c.onError != nil {
+				c.onError(err)
+			}
+		}
+	}
+}
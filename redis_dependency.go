@@ -0,0 +1,45 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// 依赖索引保存为集合，键为父键加前缀，成员为直接依赖它的子键
+
+// addDependents 使用 SADD 加入子键并刷新索引的有效期
+func (c *Redis) addDependents(ctx context.Context, index, child string, ttl time.Duration) error {
+	_, err := c.conn.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, index, child)
+		pipe.PExpire(ctx, index, ttl)
+		return nil
+	})
+	if err != nil {
+		c.stats.RecordError(index)
+		return classifyError(err)
+	}
+	return nil
+}
+
+// popDependents 在一个事务中读取并删除多个索引，并发声明的依赖不会在读取与删除之间丢失
+func (c *Redis) popDependents(ctx context.Context, indexes []string) ([][]string, error) {
+	cmds := make([]*redis.StringSliceCmd, len(indexes))
+	_, err := c.conn.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, index := range indexes {
+			cmds[i] = pipe.SMembers(ctx, index)
+		}
+		c.unlinkCmd(ctx, pipe, indexes...)
+		return nil
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	groups := make([][]string, len(indexes))
+	for i, cmd := range cmds {
+		groups[i] = cmd.Val()
+	}
+	return groups, nil
+}
@@ -0,0 +1,51 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/muleiwu/go-cache/scripts"
+	"github.com/redis/go-redis/v9"
+)
+
+// etagGetScript 按保存的数据计算摘要并与已知的摘要比较，不同时返回摘要与值，相同时只返回摘要，键不存在时返回空表
+// 只访问一个键，Redis集群中不会产生跨槽访问
+var etagGetScript = scripts.Register("go_cache:etag_get", `
+local data = redis.call("GET", KEYS[1])
+if not data then
+	return {}
+end
+local etag = redis.sha1hex(data)
+if etag == ARGV[1] then
+	return {etag}
+end
+return {etag, data}`)
+
+// getIfChanged 在服务端比较内容摘要，内容未变化时不传输值
+func (c *Redis) getIfChanged(ctx context.Context, key, known string, obj any) (string, error) {
+	res, err := c.RunScript(ctx, etagGetScript, []string{key}, known).StringSlice()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		c.stats.RecordError(key)
+		return "", classifyError(err)
+	}
+	switch len(res) {
+	case 0:
+		c.stats.RecordMiss(key)
+		return "", ErrKeyNotFound
+	case 1:
+		c.stats.RecordHit(key, 0)
+		return known, ErrNotModified
+	}
+
+	data, err := c.resolveBlob(ctx, res[1])
+	if err != nil {
+		c.stats.RecordError(key)
+		return "", err
+	}
+	if err := c.decode(key, data, obj); err != nil {
+		c.stats.RecordError(key)
+		return "", serializationError(err)
+	}
+	c.stats.RecordHit(key, len(data))
+	return res[0], nil
+}
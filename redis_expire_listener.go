@@ -0,0 +1,40 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisExpiredPattern 所有数据库过期事件的键事件通知频道
+const redisExpiredPattern = "__keyevent@*__:expired"
+
+// WatchRedisExpiry 订阅Redis的过期键事件通知并转发给listeners，返回取消订阅的函数
+// 服务端需要开启键事件通知，如 notify-keyspace-events 设置为 "Ex"。
+// Redis在键被访问或后台清理时才发出过期事件，事件可能晚于键的过期时间
+func WatchRedisExpiry(client redis.UniversalClient, listeners *ExpiryListeners) (func(), error) {
+	ctx := context.Background()
+	pubsub := client.PSubscribe(ctx, redisExpiredPattern)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, classifyError(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for msg := range pubsub.Channel() {
+			listeners.Notify(msg.Payload)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			pubsub.Close()
+			wg.Wait()
+		})
+	}, nil
+}
@@ -0,0 +1,135 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// persistScript 移除过期时间，区分键不存在（-1）与键没有过期时间（0）
+// PERSIST 在这两种情况下都返回0
+var persistScript = scripts.Register("go_cache:persist", `
+if redis.call("EXISTS", KEYS[1]) == 0 then
+	return -1
+end
+return redis.call("PERSIST", KEYS[1])`)
+
+// Persist 移除键的过期时间，键不存在时返回 ErrKeyNotFound
+func (c *Redis) Persist(ctx context.Context, key string) error {
+	n, err := c.RunScript(ctx, persistScript, []string{key}).Int64()
+	if err != nil {
+		return err
+	}
+	if n < 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// Touch 键存在时将过期时间刷新为ttl，返回键是否存在
+// PEXPIRE 本身只作用于已存在的键，不会创建新键
+func (c *Redis) Touch(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	if ttl <= 0 {
+		err := c.Persist(ctx, key)
+		if errors.Is(err, ErrKeyNotFound) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+	return c.route(ctx, OpExpire, key).PExpire(ctx, key, ttl).Result()
+}
+
+// GetTouch 读取键并刷新过期时间，服务端支持 GETEX 时（见 RedisFeatures）只需一次往返，
+// 否则先 GET 再 Touch
+func (c *Redis) GetTouch(ctx context.Context, key string, ttl time.Duration, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	if !c.features(ctx).GetEx {
+		if err := c.Get(ctx, key, obj); err != nil {
+			return err
+		}
+		_, err := c.Touch(ctx, key, ttl)
+		return err
+	}
+	// GETEX 的过期时间为0时移除过期时间（PERSIST）
+	return c.readValue(ctx, key, c.route(ctx, OpExpire, key).GetEx(ctx, key, max(ttl, 0)), obj)
+}
+
+// expireIfScript 在服务端不支持 PEXPIRE 条件时按条件设置过期时间，与 allowExpire 的判断保持一致
+// ARGV[1] 为毫秒，ARGV[2] 为条件，ARGV[3] 为 "at" 时 ARGV[1] 为Unix毫秒时间戳
+var expireIfScript = scripts.Register("go_cache:expire_if", `
+local current = redis.call("PTTL", KEYS[1])
+if current == -2 then
+	return 0
+end
+local target = tonumber(ARGV[1])
+if ARGV[3] == "at" then
+	local t = redis.call("TIME")
+	target = target - (tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000))
+end
+local cond = ARGV[2]
+local allowed = true
+if cond == "NX" then
+	allowed = current == -1
+elseif cond == "XX" then
+	allowed = current >= 0
+elseif cond == "GT" then
+	allowed = current >= 0 and target > current
+elseif cond == "LT" then
+	allowed = current == -1 or target < current
+end
+if not allowed then
+	return 0
+end
+return redis.call("PEXPIRE", KEYS[1], target)`)
+
+// expireIf 使用script（PEXPIRE 或 PEXPIREAT）按条件设置过期时间
+// 服务端不支持条件时（见 RedisFeatures）使用 expireIfScript
+func (c *Redis) expireIf(ctx context.Context, command, key string, ms int64, cond ExpireCondition) (bool, error) {
+	conn := c.route(ctx, OpExpire, key)
+	if cond != ExpireAlways && !c.features(ctx).ExpireConditions {
+		mode := ""
+		if command == "PEXPIREAT" {
+			mode = "at"
+		}
+		n, err := expireIfScript.Run(ctx, conn, []string{key}, ms, string(cond), mode).Int64()
+		return n == 1, err
+	}
+
+	args := []any{command, key, ms}
+	if cond != ExpireAlways {
+		args = append(args, string(cond))
+	}
+	n, err := conn.Do(ctx, args...).Int64()
+	return n == 1, err
+}
+
+// ExpiresInIf 使用 PEXPIRE 按条件设置剩余有效期
+// 条件需要 Redis 7 及以上版本，服务端不支持时使用Lua脚本
+func (c *Redis) ExpiresInIf(ctx context.Context, key string, ttl time.Duration, cond ExpireCondition) (bool, error) {
+	return c.expireIf(ctx, "PEXPIRE", key, ttl.Milliseconds(), cond)
+}
+
+// ExpiresAtIf 使用 PEXPIREAT 按条件设置过期时间
+// 条件需要 Redis 7 及以上版本，服务端不支持时使用Lua脚本
+func (c *Redis) ExpiresAtIf(ctx context.Context, key string, expiresAt time.Time, cond ExpireCondition) (bool, error) {
+	return c.expireIf(ctx, "PEXPIREAT", key, expiresAt.UnixMilli(), cond)
+}
+
+// TTL 使用 PTTL 返回键的剩余有效期
+func (c *Redis) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ttl, err := c.conn.PTTL(ctx, key).Result()
+	if err != nil {
+		return 0, err
+	}
+	switch {
+	case ttl == -2:
+		return 0, ErrKeyNotFound
+	case ttl < 0:
+		return -1, nil
+	}
+	return ttl, nil
+}
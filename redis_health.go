@@ -0,0 +1,179 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// errNotConnected 健康探测尚未确认连接可用时命令直接返回的错误
+var errNotConnected = fmt.Errorf("%w: redis not connected", ErrUnavailable)
+
+// WithRedisHealthProbe 开启连接健康探测，interval 为连接不可用时探测的间隔，<= 0 时使用1秒
+// 创建缓存时不要求Redis可达：在后台探测（PING）成功之前，以及建立连接失败或命令返回连接错误之后，
+// 所有命令不经过网络直接返回 ErrUnavailable，避免请求在连接超时上堆积；探测成功后恢复，并调用
+// WithRedisOnReconnect 设置的回调。探测通过 AddHook 安装在传入 NewRedis 的连接上，
+// 共用该连接的其他使用方同样受影响。使用完毕后调用 Close 停止探测
+func WithRedisHealthProbe(interval time.Duration) RedisOption {
+	return func(r *Redis) {
+		if interval <= 0 {
+			interval = time.Second
+		}
+		r.health = &healthHook{
+			interval: interval,
+			wake:     make(chan struct{}, 1),
+			stop:     make(chan struct{}),
+		}
+	}
+}
+
+// WithRedisOnReconnect 设置连接恢复可用时的回调，包括创建缓存后第一次连接成功，可用于重新预热缓存
+// 回调在探测的goroutine中执行，执行期间不会再次探测；需要 WithRedisHealthProbe
+func WithRedisOnReconnect(hook func(ctx context.Context)) RedisOption {
+	return func(r *Redis) {
+		r.onReconnect = hook
+	}
+}
+
+// Healthy 返回连接是否可用，没有开启 WithRedisHealthProbe 或调用 Close 后总是返回true
+func (c *Redis) Healthy() bool {
+	return c.health == nil || c.health.allowed(context.Background())
+}
+
+// WaitHealthy 等待连接可用，ctx 结束时返回ctx的错误
+func (c *Redis) WaitHealthy(ctx context.Context) error {
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+	for !c.Healthy() {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+	return nil
+}
+
+// Close 停止健康探测并移除对命令的拦截，不会关闭传入 NewRedis 的连接
+// 探测通过 AddHook 安装在连接上且无法卸载，Close 之后该钩子不再拦截任何命令，
+// 否则连接不可用时关闭的缓存会使共用该连接的其他使用方一直得到 ErrUnavailable
+func (c *Redis) Close() error {
+	if c.health != nil {
+		c.health.close()
+	}
+	return nil
+}
+
+// probeContextKey 标记健康探测发出的命令，这些命令不受连接状态限制
+type probeContextKey struct{}
+
+// healthHook 按连接状态拦截命令的 redis.Hook
+type healthHook struct {
+	interval time.Duration
+	healthy  atomic.Bool
+	wake     chan struct{} // 命令返回连接错误时通知探测的goroutine
+	stop     chan struct{}
+	stopOnce sync.Once
+	closed   atomic.Bool // 已停止探测，不再拦截命令
+	wg       sync.WaitGroup
+}
+
+// start 在后台探测conn，连接恢复时调用onReconnect
+func (h *healthHook) start(conn redis.UniversalClient, onReconnect func(ctx context.Context)) {
+	h.wg.Add(1)
+	go func() {
+		defer h.wg.Done()
+		ctx := context.WithValue(context.Background(), probeContextKey{}, true)
+		ticker := time.NewTicker(h.interval)
+		defer ticker.Stop()
+		for {
+			if !h.healthy.Load() && conn.Ping(ctx).Err() == nil {
+				h.healthy.Store(true)
+				if onReconnect != nil {
+					onReconnect(context.Background())
+				}
+			}
+
+			select {
+			case <-h.stop:
+				return
+			case <-h.wake:
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// close 停止探测，之后所有命令都直接发送
+func (h *healthHook) close() {
+	h.stopOnce.Do(func() {
+		h.closed.Store(true)
+		close(h.stop)
+	})
+	h.wg.Wait()
+}
+
+// observe 命令返回连接错误时标记为不可用并通知探测
+// 命令超时可能只是命令执行缓慢，不改变连接状态；建立连接失败（包括超时）由 DialHook 处理
+func (h *healthHook) observe(err error) {
+	if err == nil || errorKind(err) != ErrUnavailable {
+		return
+	}
+	h.markUnhealthy()
+}
+
+// markUnhealthy 标记为不可用并通知探测
+func (h *healthHook) markUnhealthy() {
+	if h.healthy.CompareAndSwap(true, false) {
+		select {
+		case h.wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// allowed 判断命令是否可以发送
+func (h *healthHook) allowed(ctx context.Context) bool {
+	return h.healthy.Load() || h.closed.Load() || ctx.Value(probeContextKey{}) != nil
+}
+
+func (h *healthHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := next(ctx, network, addr)
+		if err != nil && ctx.Value(probeContextKey{}) == nil {
+			h.markUnhealthy()
+		}
+		return conn, err
+	}
+}
+
+func (h *healthHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if !h.allowed(ctx) {
+			cmd.SetErr(errNotConnected)
+			return errNotConnected
+		}
+		err := next(ctx, cmd)
+		h.observe(err)
+		return err
+	}
+}
+
+func (h *healthHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if !h.allowed(ctx) {
+			for _, cmd := range cmds {
+				cmd.SetErr(errNotConnected)
+			}
+			return errNotConnected
+		}
+		err := next(ctx, cmds)
+		h.observe(err)
+		return err
+	}
+}
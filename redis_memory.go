@@ -0,0 +1,106 @@
+package go_cache
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// largestKeysScanCount SampleLargestKeys 每次SCAN的COUNT提示
+	largestKeysScanCount = 500
+
+	// largestKeysSampleLimit SampleLargestKeys 最多采样的键数量
+	largestKeysSampleLimit = 10000
+)
+
+// KeyUsage 单个键的内存占用
+type KeyUsage struct {
+	Key   string
+	Bytes int64
+}
+
+// MemoryUsage 返回键在Redis中的内存占用（字节），基于 MEMORY USAGE 命令
+// 键不存在时返回 redis.Nil 错误
+func (c *Redis) MemoryUsage(ctx context.Context, key string) (int64, error) {
+	return c.conn.MemoryUsage(ctx, key).Result()
+}
+
+// SampleLargestKeys 使用SCAN采样匹配pattern的键，返回内存占用最大的n个
+// 最多采样 10000 个键，结果按占用从大到小排序；pattern为空时匹配所有键
+func (c *Redis) SampleLargestKeys(ctx context.Context, n int, pattern string) ([]KeyUsage, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	if pattern == "" {
+		pattern = "*"
+	}
+
+	top := &keyUsageHeap{}
+	sampled := 0
+	errDone := errors.New("sample done")
+	conn := c.route(ctx, OpKeys, pattern)
+	err := scanCount(ctx, conn, pattern, largestKeysScanCount, func(keys []string) error {
+		if len(keys) > largestKeysSampleLimit-sampled {
+			keys = keys[:largestKeysSampleLimit-sampled]
+		}
+		sampled += len(keys)
+
+		pipe := conn.Pipeline()
+		for _, key := range keys {
+			pipe.MemoryUsage(ctx, key)
+		}
+		cmds, err := pipe.Exec(ctx)
+		if err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for i, cmd := range cmds {
+			bytes, err := cmd.(*redis.IntCmd).Result()
+			if errors.Is(err, redis.Nil) {
+				// 扫描过程中被删除的键，直接跳过
+				continue
+			}
+			if err != nil {
+				return err
+			}
+			heap.Push(top, KeyUsage{Key: keys[i], Bytes: bytes})
+			if top.Len() > n {
+				heap.Pop(top)
+			}
+		}
+		if sampled >= largestKeysSampleLimit {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDone) {
+		return nil, err
+	}
+
+	result := []KeyUsage(*top)
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Bytes > result[j].Bytes
+	})
+	return result, nil
+}
+
+// keyUsageHeap 按内存占用排序的小顶堆，用于保留占用最大的n个键
+type keyUsageHeap []KeyUsage
+
+func (h keyUsageHeap) Len() int           { return len(h) }
+func (h keyUsageHeap) Less(i, j int) bool { return h[i].Bytes < h[j].Bytes }
+func (h keyUsageHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *keyUsageHeap) Push(x any) {
+	*h = append(*h, x.(KeyUsage))
+}
+
+func (h *keyUsageHeap) Pop() any {
+	old := *h
+	item := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return item
+}
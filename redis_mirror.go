@@ -0,0 +1,362 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muleiwu/gsr"
+	"github.com/redis/go-redis/v9"
+)
+
+// mirrorEntry 从Redis读取的一个键的原始值与剩余有效期，ttl 为0表示没有过期时间
+type mirrorEntry struct {
+	data []byte
+	ttl  time.Duration
+}
+
+// mirrorLoad 读取一批键的原始值与剩余有效期，不存在的键不在结果中
+func (c *Redis) mirrorLoad(ctx context.Context, conn redis.UniversalClient, keys []string) (map[string]mirrorEntry, error) {
+	gets := make([]*redis.StringCmd, len(keys))
+	ttls := make([]*redis.DurationCmd, len(keys))
+	_, err := conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			gets[i] = pipe.Get(ctx, key)
+			ttls[i] = pipe.PTTL(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, classifyError(err)
+	}
+
+	entries := make(map[string]mirrorEntry, len(keys))
+	for i, key := range keys {
+		data, err := gets[i].Bytes()
+		if errors.Is(err, redis.Nil) {
+			// 扫描之后被删除的键
+			continue
+		}
+		if err != nil {
+			// 不是字符串类型的键不是由缓存写入的，跳过
+			continue
+		}
+		entries[key] = mirrorEntry{data: data, ttl: max(ttls[i].Val(), 0)}
+	}
+	return entries, nil
+}
+
+// RedisMirrorOption 热备镜像选项
+type RedisMirrorOption func(*RedisMirror)
+
+// WithMirrorRefreshInterval 设置全量同步的间隔，默认30秒
+func WithMirrorRefreshInterval(d time.Duration) RedisMirrorOption {
+	return func(m *RedisMirror) {
+		if d > 0 {
+			m.interval = d
+		}
+	}
+}
+
+// WithMirrorKeyspaceEvents 同时订阅关键键的键空间通知，键被修改时立即更新镜像
+// 服务端需要开启键空间通知，如 notify-keyspace-events 设置为 "Kgx$"；
+// 通知可能丢失（如订阅连接断开期间），全量同步仍会按间隔执行
+func WithMirrorKeyspaceEvents() RedisMirrorOption {
+	return func(m *RedisMirror) {
+		m.keyspace = true
+	}
+}
+
+// WithMirrorErrorHook 设置后台同步失败时的回调
+func WithMirrorErrorHook(hook func(err error)) RedisMirrorOption {
+	return func(m *RedisMirror) {
+		m.onError = hook
+	}
+}
+
+// WithMirrorDegradation 将读取与由镜像提供的读取记录到降级统计
+func WithMirrorDegradation(tracker *DegradationTracker) RedisMirrorOption {
+	return func(m *RedisMirror) {
+		m.degradation = tracker
+	}
+}
+
+// RedisMirror 关键键的热备镜像
+// 将键名以指定前缀开头的键持续同步到本地内存（按间隔全量同步，可选订阅键空间通知），
+// Redis暂时不可用（IsRetryable 的错误）时由镜像继续提供这些键的读取；其他键与所有写入照常访问Redis。
+// 镜像保存序列化后的原始值，读取时使用Redis缓存的序列化器解码；
+// 不可用期间的写入返回Redis的错误，不会只写入镜像。使用完毕后需要调用 Close
+type RedisMirror struct {
+	remote      *Redis
+	prefixes    []string
+	local       *Memory
+	interval    time.Duration
+	keyspace    bool
+	onError     func(err error)
+	degradation *DegradationTracker
+
+	unhealthy atomic.Bool // 最近一次访问Redis返回了可重试的错误
+
+	pubsub   *redis.PubSub
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewRedisMirror 创建镜像remote中以prefixes开头的键的热备，并在后台立即开始第一次同步
+// 开启 WithMirrorKeyspaceEvents 时订阅失败返回错误
+func NewRedisMirror(remote *Redis, prefixes []string, opts ...RedisMirrorOption) (*RedisMirror, error) {
+	m := &RedisMirror{
+		remote:   remote,
+		prefixes: append([]string(nil), prefixes...),
+		local:    NewMemory(time.Hour, time.Minute),
+		interval: 30 * time.Second,
+		stop:     make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	if m.keyspace && len(m.prefixes) > 0 {
+		patterns := make([]string, len(m.prefixes))
+		for i, prefix := range m.prefixes {
+			patterns[i] = "__keyspace@*__:" + EscapePattern(prefix) + "*"
+		}
+		ctx := context.Background()
+		m.pubsub = remote.conn.PSubscribe(ctx, patterns...)
+		if _, err := m.pubsub.Receive(ctx); err != nil {
+			m.pubsub.Close()
+			return nil, classifyError(err)
+		}
+		m.wg.Add(1)
+		go m.listen()
+	}
+
+	m.wg.Add(1)
+	go m.refreshLoop()
+	return m, nil
+}
+
+// Len 返回镜像中未过期的键数
+func (m *RedisMirror) Len() int {
+	keys, _ := m.local.Keys(context.Background(), "*")
+	return len(keys)
+}
+
+// Sync 立即执行一次全量同步：复制全部关键键，并移除Redis中已不存在的键
+func (m *RedisMirror) Sync(ctx context.Context) error {
+	conn := m.remote.route(ctx, OpKeys, "")
+	for _, prefix := range m.prefixes {
+		pattern := EscapePattern(prefix) + "*"
+		seen := make(map[string]struct{})
+		err := scanCount(ctx, conn, pattern, 0, func(keys []string) error {
+			entries, err := m.remote.mirrorLoad(ctx, conn, keys)
+			if err != nil {
+				return err
+			}
+			for key, entry := range entries {
+				seen[key] = struct{}{}
+				m.store(ctx, key, entry)
+			}
+			return nil
+		})
+		if err != nil {
+			m.report(classifyError(err))
+			return classifyError(err)
+		}
+
+		local, err := m.local.Keys(ctx, pattern)
+		if err != nil {
+			return err
+		}
+		for _, key := range local {
+			if _, ok := seen[key]; !ok {
+				_ = m.local.Del(ctx, key)
+			}
+		}
+	}
+	m.unhealthy.Store(false)
+	return nil
+}
+
+// Close 停止后台同步并取消订阅，镜像中的数据随之丢弃
+func (m *RedisMirror) Close() error {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+		if m.pubsub != nil {
+			m.pubsub.Close()
+		}
+	})
+	m.wg.Wait()
+	m.local.Flush()
+	return nil
+}
+
+func (m *RedisMirror) Exists(ctx context.Context, key string) bool {
+	if m.remote.Exists(ctx, key) {
+		return true
+	}
+	// Exists 不返回错误，Redis不可用期间按镜像判断
+	return m.critical(key) && m.unhealthy.Load() && m.local.Exists(ctx, key)
+}
+
+func (m *RedisMirror) Get(ctx context.Context, key string, obj any) error {
+	err := m.remote.Get(ctx, key, obj)
+	if !m.critical(key) {
+		return err
+	}
+	if err == nil || !IsRetryable(err) {
+		m.unhealthy.Store(false)
+		m.degradation.RecordRequest()
+		return err
+	}
+
+	m.unhealthy.Store(true)
+	var data []byte
+	if m.local.Get(ctx, key, &data) != nil {
+		m.degradation.RecordRequest()
+		return err
+	}
+	if decodeErr := m.remote.decode(key, data, obj); decodeErr != nil {
+		return serializationError(decodeErr)
+	}
+	m.degradation.RecordDegraded(DegradationFallback)
+	return nil
+}
+
+func (m *RedisMirror) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if err := m.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	if m.critical(key) && !noStore(ctx) {
+		if data, err := m.remote.codec(key).Encode(value); err == nil {
+			m.store(ctx, key, mirrorEntry{data: data, ttl: max(ttl, 0)})
+		}
+	}
+	return nil
+}
+
+func (m *RedisMirror) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	// 严格模式下在调用回调函数之前检查ttl
+	if err := checkTTL(m.remote.strict, ttl); err != nil {
+		return err
+	}
+
+	// 先尝试从缓存获取，WithForceRefresh 时直接调用回调函数
+	if !forceRefresh(ctx) && m.Get(ctx, key, obj) == nil {
+		// 缓存命中，直接返回
+		return nil
+	}
+
+	// 缓存未命中，调用回调函数（panic会被转换为错误）
+	if err := m.remote.loader.call(ctx, key, obj, fun); err != nil {
+		return err
+	}
+
+	// 获取obj指向的实际值并存入缓存
+	value, err := pointee(obj)
+	if err != nil {
+		return err
+	}
+	return m.Set(ctx, key, value, ttl)
+}
+
+func (m *RedisMirror) Del(ctx context.Context, key string) error {
+	if err := m.remote.Del(ctx, key); err != nil {
+		return err
+	}
+	return m.local.Del(ctx, key)
+}
+
+func (m *RedisMirror) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	if err := m.remote.ExpiresAt(ctx, key, expiresAt); err != nil {
+		return err
+	}
+	if err := m.local.ExpiresAt(ctx, key, expiresAt); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
+
+func (m *RedisMirror) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	if err := m.remote.ExpiresIn(ctx, key, ttl); err != nil {
+		return err
+	}
+	if err := m.local.ExpiresIn(ctx, key, ttl); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return err
+	}
+	return nil
+}
+
+// critical 判断键是否需要镜像
+func (m *RedisMirror) critical(key string) bool {
+	for _, prefix := range m.prefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// store 将原始值写入镜像
+func (m *RedisMirror) store(ctx context.Context, key string, entry mirrorEntry) {
+	_ = m.local.Set(ctx, key, entry.data, entry.ttl)
+}
+
+// refreshKey 从Redis重新读取一个键，键已不存在时从镜像中移除
+func (m *RedisMirror) refreshKey(ctx context.Context, key string) {
+	entries, err := m.remote.mirrorLoad(ctx, m.remote.route(ctx, OpKeys, key), []string{key})
+	if err != nil {
+		m.report(err)
+		return
+	}
+	if entry, ok := entries[key]; ok {
+		m.store(ctx, key, entry)
+		return
+	}
+	_ = m.local.Del(ctx, key)
+}
+
+// report 记录后台同步的错误
+func (m *RedisMirror) report(err error) {
+	if IsRetryable(err) {
+		m.unhealthy.Store(true)
+	}
+	if m.onError != nil {
+		m.onError(err)
+	}
+}
+
+// refreshLoop 立即同步一次，之后按间隔全量同步
+func (m *RedisMirror) refreshLoop() {
+	defer m.wg.Done()
+
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		_ = m.Sync(context.Background())
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// listen 处理键空间通知，频道为 "__keyspace@<db>__:<key>"
+func (m *RedisMirror) listen() {
+	defer m.wg.Done()
+	for msg := range m.pubsub.Channel() {
+		_, key, ok := strings.Cut(msg.Channel, "__:")
+		if !ok || !m.critical(key) {
+			continue
+		}
+		m.refreshKey(context.Background(), key)
+	}
+}
@@ -0,0 +1,70 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// 验证码的摘要保存在 KEYS[1]，失败次数保存在 KEYS[2]，两者同时过期
+
+// otpVerifyScript 比较摘要，正确时删除验证码并返回0；不正确时用 INCR 计数，
+// 达到上限时删除验证码并返回-2，否则返回剩余次数；验证码不存在时返回-1
+var otpVerifyScript = scripts.Register("go_cache:otp_verify", `
+local hash = redis.call("GET", KEYS[1])
+if not hash then
+	return -1
+end
+if hash == ARGV[1] then
+	redis.call("DEL", KEYS[1], KEYS[2])
+	return 0
+end
+local attempts = redis.call("INCR", KEYS[2])
+if attempts == 1 then
+	local ttl = redis.call("PTTL", KEYS[1])
+	if ttl > 0 then
+		redis.call("PEXPIRE", KEYS[2], ttl)
+	end
+end
+local max = tonumber(ARGV[2])
+if attempts >= max then
+	redis.call("DEL", KEYS[1], KEYS[2])
+	return -2
+end
+return max - attempts`)
+
+// otpAttemptsKey 返回验证码的失败次数键
+func otpAttemptsKey(key string) string {
+	return key + ":attempts"
+}
+
+// storeOTP 使用 SET NX 保存验证码的摘要，已有验证码时返回false
+// 失败次数键与验证码同时过期或被删除，新的验证码从0开始计数
+func (c *Redis) storeOTP(ctx context.Context, key, hash string, ttl time.Duration) (bool, error) {
+	ok, err := c.conn.SetNX(ctx, key, hash, ttl).Result()
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	return ok, nil
+}
+
+// verifyOTP 在服务端原子验证验证码
+func (c *Redis) verifyOTP(ctx context.Context, key, hash string, maxAttempts int) (int, error) {
+	n, err := c.RunScript(ctx, otpVerifyScript, []string{key, otpAttemptsKey(key)}, hash, maxAttempts).Int()
+	if err != nil {
+		c.stats.RecordError(key)
+		return 0, classifyError(err)
+	}
+	return n, nil
+}
+
+// revokeOTP 删除验证码与失败次数
+func (c *Redis) revokeOTP(ctx context.Context, key string) error {
+	if err := c.conn.Del(ctx, key, otpAttemptsKey(key)).Err(); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// setFieldsScript 用结构体的全部字段替换哈希并设置过期时间
+var setFieldsScript = scripts.Register("go_cache:set_fields", `
+redis.call("DEL", KEYS[1])
+if #ARGV > 1 then
+	redis.call("HSET", KEYS[1], unpack(ARGV, 2))
+end
+local ttl = tonumber(ARGV[1])
+if ttl > 0 then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return 1`)
+
+// patchScript 写入变化的字段，键不存在或不是哈希时返回-1
+var patchScript = scripts.Register("go_cache:patch", `
+if redis.call("TYPE", KEYS[1]).ok ~= "hash" then
+	return -1
+end
+if #ARGV > 1 then
+	redis.call("HSET", KEYS[1], unpack(ARGV, 2))
+end
+local ttl = tonumber(ARGV[1])
+if ttl > 0 then
+	redis.call("PEXPIRE", KEYS[1], ttl)
+end
+return (#ARGV - 1) / 2`)
+
+// SetFields 将结构体的每个字段分别序列化后保存为哈希
+func (c *Redis) SetFields(ctx context.Context, key string, value any, ttl time.Duration) error {
+	fields, err := encodeFields(c.fieldsCodec(key), value)
+	if err != nil {
+		return err
+	}
+	size := fieldsSize(fields)
+	names, err := c.offloadFields(ctx, key, fields)
+	if err != nil {
+		return err
+	}
+
+	args := append([]any{max(ttl, 0).Milliseconds()}, fields...)
+	if err := c.RunScript(ctx, setFieldsScript, []string{key}, args...).Err(); err != nil {
+		c.deleteBlobs(ctx, names)
+		c.stats.RecordError(key)
+		return err
+	}
+	c.stats.RecordSet(key, size)
+	return nil
+}
+
+// GetFields 使用 HGETALL 读取哈希并按字段解码
+func (c *Redis) GetFields(ctx context.Context, key string, obj any) error {
+	data, err := c.conn.HGetAll(ctx, key).Result()
+	if err != nil {
+		c.stats.RecordError(key)
+		return err
+	}
+	if len(data) == 0 {
+		c.stats.RecordMiss(key)
+		return ErrKeyNotFound
+	}
+	for field, value := range data {
+		resolved, err := c.resolveBlob(ctx, value)
+		if err != nil {
+			return err
+		}
+		data[field] = string(resolved)
+	}
+
+	if err := decodeFields(c.fieldsCodec(key), data, obj); err != nil {
+		c.stats.RecordError(key)
+		return err
+	}
+
+	size := 0
+	for _, v := range data {
+		size += len(v)
+	}
+	c.stats.RecordHit(key, size)
+	return nil
+}
+
+// Patch 在客户端比较字段，通过Lua脚本只写入变化的字段
+func (c *Redis) Patch(ctx context.Context, key string, old, new any, ttl time.Duration) (int, error) {
+	fields, err := diffFields(c.fieldsCodec(key), old, new)
+	if err != nil {
+		return 0, err
+	}
+	size := fieldsSize(fields)
+	names, err := c.offloadFields(ctx, key, fields)
+	if err != nil {
+		return 0, err
+	}
+
+	args := append([]any{max(ttl, 0).Milliseconds()}, fields...)
+	n, err := c.RunScript(ctx, patchScript, []string{key}, args...).Int()
+	if err != nil || n < 0 {
+		c.deleteBlobs(ctx, names)
+	}
+	if err != nil {
+		c.stats.RecordError(key)
+		return 0, err
+	}
+	if n < 0 {
+		return 0, ErrKeyNotFound
+	}
+	c.stats.RecordSet(key, size)
+	return n, nil
+}
+
+// fieldsSize 返回字段值的总字节数
+func fieldsSize(fields []any) int {
+	size := 0
+	for i := 1; i < len(fields); i += 2 {
+		size += len(fields[i].([]byte))
+	}
+	return size
+}
@@ -0,0 +1,189 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// patternScanCount 按模式扫描时每次SCAN的默认COUNT提示
+const patternScanCount = 500
+
+// Keys 使用SCAN返回匹配模式的所有键，限制见 WithScanOptions
+func (c *Redis) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	err := c.scanLimited(ctx, c.route(ctx, OpKeys, pattern), pattern, func(batch []string) error {
+		keys = append(keys, batch...)
+		return nil
+	})
+	return keys, err
+}
+
+// DelByPattern 使用SCAN与UNLINK删除匹配模式的所有键，返回删除的数量，限制见 WithScanOptions
+// DryRun 时只统计匹配的键数，不删除。使用 WithRedisRouter 时扫描按 OpKeys 路由，删除按 OpDelByPattern 路由
+func (c *Redis) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	dryRun := ScanOptionsFromContext(ctx).DryRun
+	conn := c.route(ctx, OpDelByPattern, pattern)
+	var deleted int64
+	err := c.scanLimited(ctx, c.route(ctx, OpKeys, pattern), pattern, func(batch []string) error {
+		if dryRun {
+			deleted += int64(len(batch))
+			return nil
+		}
+		n, err := c.unlink(ctx, conn, batch)
+		if err != nil {
+			return err
+		}
+		deleted += n
+		for _, key := range batch {
+			c.stats.RecordDelete(key)
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// scanLimited 按context中的 ScanOptions 迭代匹配模式的键
+// 达到上限时先处理上限内的部分，再返回 ErrScanLimitReached
+func (c *Redis) scanLimited(ctx context.Context, conn redis.UniversalClient, pattern string, fn func(batch []string) error) error {
+	limiter := newScanLimiter(ctx)
+	return scanCount(ctx, conn, pattern, limiter.opts.Count, func(batch []string) error {
+		batch, limitErr := limiter.take(batch)
+		if len(batch) > 0 {
+			if err := fn(batch); err != nil {
+				return err
+			}
+		}
+		return limitErr
+	})
+}
+
+// scanCount 在conn上使用COUNT提示count迭代匹配模式的键，每批非空结果调用一次fn，count <= 0 时使用默认值
+// 集群模式下依次扫描每个主节点，fn 不会被并发调用
+func scanCount(ctx context.Context, conn redis.UniversalClient, pattern string, count int, fn func(batch []string) error) error {
+	if count <= 0 {
+		count = patternScanCount
+	}
+	cluster, ok := conn.(*redis.ClusterClient)
+	if !ok {
+		return scanNode(ctx, conn, pattern, count, fn)
+	}
+
+	// ForEachMaster 并发执行，串行调用fn，fn 返回错误后其他节点停止扫描
+	var mu sync.Mutex
+	var stopped bool
+	return cluster.ForEachMaster(ctx, func(ctx context.Context, node *redis.Client) error {
+		return scanNode(ctx, node, pattern, count, func(batch []string) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if stopped {
+				return errScanStopped
+			}
+			if err := fn(batch); err != nil {
+				stopped = true
+				return err
+			}
+			return nil
+		})
+	})
+}
+
+// errScanStopped 集群中其他节点的扫描已经失败
+var errScanStopped = errors.New("scan stopped")
+
+// scanNode 在一个节点上迭代匹配模式的键
+func scanNode(ctx context.Context, conn redis.Cmdable, pattern string, count int, fn func(batch []string) error) error {
+	var cursor uint64
+	for {
+		keys, next, err := conn.Scan(ctx, cursor, pattern, int64(count)).Result()
+		if err != nil {
+			return err
+		}
+		if len(keys) > 0 {
+			if err := fn(keys); err != nil {
+				return err
+			}
+		}
+		cursor = next
+		if cursor == 0 {
+			return nil
+		}
+	}
+}
+
+// unlink 在conn上删除一批键，返回删除的数量，服务端不支持 UNLINK 时使用 DEL
+// 集群模式下一批键可能属于不同的槽，逐个键在管道中删除
+func (c *Redis) unlink(ctx context.Context, conn redis.UniversalClient, keys []string) (int64, error) {
+	if _, ok := conn.(*redis.ClusterClient); !ok {
+		return c.unlinkCmd(ctx, conn, keys...).Result()
+	}
+	cmds := make([]*redis.IntCmd, len(keys))
+	if _, err := conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = c.unlinkCmd(ctx, pipe, key)
+		}
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+	var n int64
+	for _, cmd := range cmds {
+		n += cmd.Val()
+	}
+	return n, nil
+}
+
+// unlinkCmd 在conn（连接或管道）上执行 UNLINK，服务端不支持时执行 DEL
+func (c *Redis) unlinkCmd(ctx context.Context, conn redis.Cmdable, keys ...string) *redis.IntCmd {
+	if c.features(ctx).Unlink {
+		return conn.Unlink(ctx, keys...)
+	}
+	return conn.Del(ctx, keys...)
+}
+
+// SampleTTLs 使用SCAN与PTTL采样最多n个键的剩余TTL，没有过期时间的键返回-1
+func (c *Redis) SampleTTLs(ctx context.Context, n int) ([]time.Duration, error) {
+	ttls := make([]time.Duration, 0, n)
+	errDone := errors.New("sample done")
+
+	conn := c.route(ctx, OpKeys, "*")
+	err := scanCount(ctx, conn, "*", 0, func(batch []string) error {
+		if len(batch) > n-len(ttls) {
+			batch = batch[:n-len(ttls)]
+		}
+		pipe := conn.Pipeline()
+		cmds := make([]*redis.DurationCmd, len(batch))
+		for i, key := range batch {
+			cmds[i] = pipe.PTTL(ctx, key)
+		}
+		if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+			return err
+		}
+		for _, cmd := range cmds {
+			ttl, err := cmd.Result()
+			if err != nil {
+				return err
+			}
+			switch {
+			case ttl == -2:
+				// 扫描过程中被删除的键
+				continue
+			case ttl < 0:
+				ttls = append(ttls, -1)
+			default:
+				ttls = append(ttls, ttl)
+			}
+		}
+		if len(ttls) >= n {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDone) {
+		return nil, err
+	}
+	return ttls, nil
+}
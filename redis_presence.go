@@ -0,0 +1,74 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// 在线成员保存为有序集合，成员为id，分数为过期时间（毫秒）；
+// 脚本使用服务端时间，避免多个实例之间的时钟偏差
+
+// presenceHeartbeatScript 更新成员的过期时间并移除已过期的成员，
+// 索引的有效期为最晚过期的成员的剩余时间
+var presenceHeartbeatScript = scripts.Register("go_cache:presence_heartbeat", `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local t = redis.call("TIME")
+local now = math.floor(tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000)
+redis.call("ZADD", KEYS[1], now + tonumber(ARGV[2]), ARGV[1])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+local last = redis.call("ZRANGE", KEYS[1], -1, -1, "WITHSCORES")
+redis.call("PEXPIRE", KEYS[1], tonumber(last[2]) - now)
+return 1`)
+
+// presenceListScript 移除已过期的成员后返回以ARGV[1]开头的成员
+var presenceListScript = scripts.Register("go_cache:presence_list", `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local t = redis.call("TIME")
+local now = math.floor(tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+local ids = redis.call("ZRANGE", KEYS[1], 0, -1)
+local prefix = ARGV[1]
+if prefix == "" then
+	return ids
+end
+local res = {}
+for _, id in ipairs(ids) do
+	if string.sub(id, 1, #prefix) == prefix then
+		res[#res + 1] = id
+	end
+end
+return res`)
+
+// heartbeatPresence 在服务端记录成员在线
+func (c *Redis) heartbeatPresence(ctx context.Context, key, id string, ttl time.Duration) error {
+	if err := c.RunScript(ctx, presenceHeartbeatScript, []string{key}, id, max(ttl.Milliseconds(), 1)).Err(); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	return nil
+}
+
+// listPresence 在服务端列出在线成员
+func (c *Redis) listPresence(ctx context.Context, key, prefix string) ([]string, error) {
+	ids, err := c.RunScript(ctx, presenceListScript, []string{key}, prefix).StringSlice()
+	if err != nil {
+		c.stats.RecordError(key)
+		return nil, classifyError(err)
+	}
+	return ids, nil
+}
+
+// leavePresence 使用 ZREM 移除成员
+func (c *Redis) leavePresence(ctx context.Context, key, id string) error {
+	if err := c.conn.ZRem(ctx, key, id).Err(); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+)
+
+// Publish 使用 PUBLISH 向频道channel发布msg，msg 使用缓存的序列化器编码
+func (c *Redis) Publish(ctx context.Context, channel string, msg any) error {
+	data, _, err := encodeSized(c.codecFor("channel", channel), msg)
+	if err != nil {
+		return err
+	}
+	if err := c.conn.Publish(ctx, channel, string(data)).Err(); err != nil {
+		c.stats.RecordError(channel)
+		return classifyError(err)
+	}
+	return nil
+}
+
+// Subscribe 使用 SUBSCRIBE 订阅频道channel，收到的消息使用缓存的序列化器解码
+func (c *Redis) Subscribe(ctx context.Context, channel string, handler MessageHandler) (func(), error) {
+	pubsub := c.conn.Subscribe(ctx, channel)
+	if _, err := pubsub.Receive(ctx); err != nil {
+		pubsub.Close()
+		return nil, classifyError(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for m := range pubsub.Channel() {
+			payload := []byte(m.Payload)
+			codec := c.codecFor("channel", m.Channel)
+			handler(&Message{
+				Channel: m.Channel,
+				decode: func(obj any) error {
+					return serializationError(c.decodeWith(codec, payload, obj))
+				},
+			})
+		}
+	}()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			pubsub.Close()
+			wg.Wait()
+		})
+	}
+	context.AfterFunc(ctx, cancel)
+	return cancel, nil
+}
@@ -0,0 +1,74 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// copyScript 复制值并设置过期时间，src 不存在时返回-1
+// 使用 GET/SET 而不是 COPY，兼容 Redis 6.2 之前的版本
+var copyScript = scripts.Register("go_cache:copy", `
+local v = redis.call("GET", KEYS[1])
+if not v then
+	return -1
+end
+local ttl = tonumber(ARGV[1])
+if ttl > 0 then
+	redis.call("SET", KEYS[2], v, "PX", ttl)
+else
+	redis.call("SET", KEYS[2], v)
+end
+return 1`)
+
+// Rename 使用 RENAME 重命名键
+// 启用 WithRedisBlobOffload 时新键指向复制出的新对象，原对象与新键被覆盖的对象随后删除；
+// 启用 WithRedisSigning 时值按新键重新签名
+func (c *Redis) Rename(ctx context.Context, key, newKey string) error {
+	if c.blobs != nil || c.signingKey != nil {
+		if err := c.moveValue(ctx, blobRenameScript, key, newKey, true, blobPointerPrefix); err != nil {
+			if !errors.Is(err, ErrKeyNotFound) {
+				c.stats.RecordError(key)
+			}
+			return err
+		}
+		return nil
+	}
+	if err := c.conn.Rename(ctx, key, newKey).Err(); err != nil {
+		if strings.Contains(err.Error(), "no such key") {
+			return ErrKeyNotFound
+		}
+		c.stats.RecordError(key)
+		return err
+	}
+	return nil
+}
+
+// Copy 在服务端复制键的值
+// 启用 WithRedisBlobOffload 时目标键指向复制出的新对象，两个键的对象互不影响；
+// 启用 WithRedisSigning 时值按目标键重新签名
+func (c *Redis) Copy(ctx context.Context, src, dst string, ttl time.Duration) error {
+	if c.blobs != nil || c.signingKey != nil {
+		if err := c.moveValue(ctx, blobCopyScript, src, dst, false, max(ttl, 0).Milliseconds(), blobPointerPrefix); err != nil {
+			if !errors.Is(err, ErrKeyNotFound) {
+				c.stats.RecordError(dst)
+			}
+			return err
+		}
+		c.stats.RecordSet(dst, 0)
+		return nil
+	}
+	n, err := c.RunScript(ctx, copyScript, []string{src, dst}, max(ttl, 0).Milliseconds()).Int64()
+	if err != nil {
+		c.stats.RecordError(dst)
+		return err
+	}
+	if n < 0 {
+		return ErrKeyNotFound
+	}
+	c.stats.RecordSet(dst, 0)
+	return nil
+}
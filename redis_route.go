@@ -0,0 +1,54 @@
+package go_cache
+
+import (
+	"context"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRouter 返回执行操作op的客户端，返回nil时使用缓存的默认连接
+// key 为操作的键，按模式的操作（OpKeys、OpDelByPattern）为模式
+type RedisRouter func(ctx context.Context, op Operation, key string) redis.UniversalClient
+
+// WithRedisRouter 设置按操作选择客户端的路由，如将读取或大范围的SCAN路由到只读副本
+// 经过路由的操作：Exists、Get（含 GetSet 的读取）、Set、SetNX、Del、设置过期时间的方法、
+// Keys、DelByPattern 与 SampleTTLs、SampleLargestKeys（按 OpKeys 路由），
+// 以及按每个键路由的 ExistsMulti（OpExists）、MGet（OpGet）与 DelMulti（OpDel）；
+// 事务与Lua脚本等其他操作总是使用默认连接。
+// 路由到的客户端由调用方管理，不会安装 WithRedisMaxOpsPerSecond 等选项添加的钩子
+func WithRedisRouter(router RedisRouter) RedisOption {
+	return func(r *Redis) {
+		r.router = router
+	}
+}
+
+// RouteReadsTo 返回将不修改缓存的操作路由到replica、其余操作使用默认连接的路由
+// 副本的复制延迟会使刚写入的值短时间内读取不到
+func RouteReadsTo(replica redis.UniversalClient) RedisRouter {
+	return func(ctx context.Context, op Operation, key string) redis.UniversalClient {
+		if op.IsWrite() {
+			return nil
+		}
+		return replica
+	}
+}
+
+// route 返回执行操作的客户端
+func (c *Redis) route(ctx context.Context, op Operation, key string) redis.UniversalClient {
+	if c.router != nil {
+		if conn := c.router(ctx, op, key); conn != nil {
+			return conn
+		}
+	}
+	return c.conn
+}
+
+// routeKeys 按操作op为每个键选择客户端，返回每个客户端与路由到它的键
+func (c *Redis) routeKeys(ctx context.Context, op Operation, keys []string) map[redis.UniversalClient][]string {
+	groups := make(map[redis.UniversalClient][]string, 1)
+	for _, key := range keys {
+		conn := c.route(ctx, op, key)
+		groups[conn] = append(groups[conn], key)
+	}
+	return groups
+}
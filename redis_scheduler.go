@@ -0,0 +1,117 @@
+package go_cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// 延迟任务保存为有序集合，成员为任务键，分数为到期时间（毫秒）；
+// 负载与投递次数保存在哈希 "{key}:tasks" 的 "p:任务键" 与 "a:任务键" 字段中，
+// 脚本使用服务端时间，避免多个实例之间的时钟偏差
+
+// schedulerAddScript 加入或替换任务
+var schedulerAddScript = scripts.Register("go_cache:scheduler_add", `
+redis.call("ZADD", KEYS[1], ARGV[2], ARGV[1])
+redis.call("HSET", KEYS[2], "p:" .. ARGV[1], ARGV[3])
+redis.call("HDEL", KEYS[2], "a:" .. ARGV[1])
+return 1`)
+
+// schedulerClaimScript 领取最多ARGV[2]个到期的任务，将其分数推迟为可见性超时的截止时间
+// 返回 {任务键, 负载, 投递次数, 截止时间, ...}
+var schedulerClaimScript = scripts.Register("go_cache:scheduler_claim", `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local t = redis.call("TIME")
+local now = math.floor(tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000)
+local deadline = now + tonumber(ARGV[1])
+local ids = redis.call("ZRANGEBYSCORE", KEYS[1], "-inf", now, "LIMIT", 0, tonumber(ARGV[2]))
+local res = {}
+for _, id in ipairs(ids) do
+	redis.call("ZADD", KEYS[1], deadline, id)
+	local attempt = redis.call("HINCRBY", KEYS[2], "a:" .. id, 1)
+	local payload = redis.call("HGET", KEYS[2], "p:" .. id) or ""
+	res[#res + 1] = id
+	res[#res + 1] = payload
+	res[#res + 1] = attempt
+	res[#res + 1] = deadline
+end
+return res`)
+
+// schedulerRemoveScript 移除任务，ARGV[2] 非0时只在任务的分数等于该截止时间时移除，返回是否移除
+var schedulerRemoveScript = scripts.Register("go_cache:scheduler_remove", `
+local score = redis.call("ZSCORE", KEYS[1], ARGV[1])
+if not score then
+	return 0
+end
+if tonumber(ARGV[2]) ~= 0 and tonumber(score) ~= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("ZREM", KEYS[1], ARGV[1])
+redis.call("HDEL", KEYS[2], "p:" .. ARGV[1], "a:" .. ARGV[1])
+return 1`)
+
+// schedulerKeys 返回有序集合与哈希的键，使用哈希标签使两个键在集群中位于同一个槽
+func schedulerKeys(key string) []string {
+	tagged := "{" + key + "}"
+	return []string{tagged, tagged + ":tasks"}
+}
+
+// scheduleTask 在服务端加入或替换任务
+func (c *Redis) scheduleTask(ctx context.Context, key, task string, payload []byte, runAt time.Time) error {
+	if err := c.RunScript(ctx, schedulerAddScript, schedulerKeys(key), task, runAt.UnixMilli(), payload).Err(); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	return nil
+}
+
+// claimTasks 在服务端领取到期的任务
+func (c *Redis) claimTasks(ctx context.Context, key string, visibility time.Duration, limit int) ([]schedulerClaim, error) {
+	res, err := c.RunScript(ctx, schedulerClaimScript, schedulerKeys(key), visibility.Milliseconds(), limit).Slice()
+	if err != nil {
+		c.stats.RecordError(key)
+		return nil, classifyError(err)
+	}
+	if len(res)%4 != 0 {
+		return nil, fmt.Errorf("scheduler: unexpected script result %v", res)
+	}
+
+	claims := make([]schedulerClaim, 0, len(res)/4)
+	for i := 0; i < len(res); i += 4 {
+		id, _ := res[i].(string)
+		payload, _ := res[i+1].(string)
+		attempt, _ := res[i+2].(int64)
+		deadline, _ := res[i+3].(int64)
+		claims = append(claims, schedulerClaim{
+			key:      id,
+			payload:  []byte(payload),
+			attempt:  int(attempt),
+			deadline: deadline,
+		})
+	}
+	return claims, nil
+}
+
+// removeTask 在服务端移除任务
+func (c *Redis) removeTask(ctx context.Context, key, task string, deadline int64) (bool, error) {
+	n, err := c.RunScript(ctx, schedulerRemoveScript, schedulerKeys(key), task, deadline).Int()
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	return n == 1, nil
+}
+
+// countTasks 使用 ZCARD 返回任务数
+func (c *Redis) countTasks(ctx context.Context, key string) (int, error) {
+	n, err := c.conn.ZCard(ctx, schedulerKeys(key)[0]).Result()
+	if err != nil {
+		c.stats.RecordError(key)
+		return 0, classifyError(err)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,92 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+// 信号量保存为有序集合，成员为租约标识，分数为过期时间（毫秒）；
+// 脚本使用服务端时间，避免多个实例之间的时钟偏差
+
+// semaphoreAcquireScript 移除过期租约后在名额未满时加入租约，返回是否获取成功
+var semaphoreAcquireScript = scripts.Register("go_cache:semaphore_acquire", `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local t = redis.call("TIME")
+local now = math.floor(tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000)
+local ttl = tonumber(ARGV[3])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if redis.call("ZCARD", KEYS[1]) >= tonumber(ARGV[2]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], now + ttl, ARGV[1])
+redis.call("PEXPIRE", KEYS[1], ttl)
+return 1`)
+
+// semaphoreRefreshScript 续期未过期的租约，返回租约是否存在
+var semaphoreRefreshScript = scripts.Register("go_cache:semaphore_refresh", `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local t = redis.call("TIME")
+local now = math.floor(tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000)
+local ttl = tonumber(ARGV[2])
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+if not redis.call("ZSCORE", KEYS[1], ARGV[1]) then
+	return 0
+end
+redis.call("ZADD", KEYS[1], now + ttl, ARGV[1])
+redis.call("PEXPIRE", KEYS[1], ttl)
+return 1`)
+
+// semaphoreCountScript 移除过期租约后返回持有者数
+var semaphoreCountScript = scripts.Register("go_cache:semaphore_count", `
+if redis.replicate_commands then
+	redis.replicate_commands()
+end
+local t = redis.call("TIME")
+local now = math.floor(tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000)
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now)
+return redis.call("ZCARD", KEYS[1])`)
+
+// acquireSemaphore 在服务端获取信号量租约
+func (c *Redis) acquireSemaphore(ctx context.Context, key, token string, limit int, ttl time.Duration) (bool, error) {
+	n, err := c.RunScript(ctx, semaphoreAcquireScript, []string{key}, token, limit, ttl.Milliseconds()).Int()
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	return n == 1, nil
+}
+
+// refreshSemaphore 在服务端续期信号量租约
+func (c *Redis) refreshSemaphore(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	n, err := c.RunScript(ctx, semaphoreRefreshScript, []string{key}, token, ttl.Milliseconds()).Int()
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	return n == 1, nil
+}
+
+// releaseSemaphore 使用 ZREM 释放信号量租约
+func (c *Redis) releaseSemaphore(ctx context.Context, key, token string) error {
+	if err := c.conn.ZRem(ctx, key, token).Err(); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	return nil
+}
+
+// countSemaphore 返回信号量未过期的持有者数
+func (c *Redis) countSemaphore(ctx context.Context, key string) (int, error) {
+	n, err := c.RunScript(ctx, semaphoreCountScript, []string{key}).Int()
+	if err != nil {
+		c.stats.RecordError(key)
+		return 0, classifyError(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,44 @@
+package go_cache
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+	"strings"
+)
+
+// StatsContext 返回缓存统计快照，并通过 INFO stats 补充服务端的过期与淘汰计数
+// 服务端计数覆盖整个Redis实例，而不仅是本缓存写入的键；
+// 服务端不提供这些计数时（如 miniredis，见 RedisFeatures），Expired 与 Evicted 为0
+func (c *Redis) StatsContext(ctx context.Context) (Stats, error) {
+	stats := c.Stats()
+	if !c.features(ctx).InfoStats {
+		return stats, nil
+	}
+
+	info, err := c.conn.Info(ctx, "stats").Result()
+	if err != nil {
+		return stats, err
+	}
+
+	fields := parseInfo(info)
+	stats.Expired, _ = strconv.ParseUint(fields["expired_keys"], 10, 64)
+	stats.Evicted, _ = strconv.ParseUint(fields["evicted_keys"], 10, 64)
+	return stats, nil
+}
+
+// parseInfo 解析 INFO 命令返回的 "field:value" 文本
+func parseInfo(info string) map[string]string {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok {
+			fields[name] = value
+		}
+	}
+	return fields
+}
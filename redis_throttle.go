@@ -0,0 +1,211 @@
+package go_cache
+
+import (
+	"context"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// WithRedisMaxOpsPerSecond 限制每秒发送的命令数，n <= 0 表示不限制
+// 流水线与事务中的每条命令分别计数。超出限制的命令在客户端等待，等待期间ctx结束时返回ctx的错误。
+// 限制通过 AddHook 安装在传入 NewRedis 的连接上，共用该连接的其他使用方同样受限，
+// 适合为SCAN批量操作、数据迁移等维护任务单独创建连接，避免其占满共享的Redis实例
+func WithRedisMaxOpsPerSecond(n int) RedisOption {
+	return func(r *Redis) {
+		r.maxOps = n
+	}
+}
+
+// WithRedisMaxBytesPerSecond 限制每秒收发的数据量（字节），n <= 0 表示不限制
+// 命令参数在发送前计入，回复在收到后计入并推迟之后的命令；其他与 WithRedisMaxOpsPerSecond 相同
+func WithRedisMaxBytesPerSecond(n int) RedisOption {
+	return func(r *Redis) {
+		r.maxBytes = n
+	}
+}
+
+// rateLimiter 允许透支的令牌桶
+// 最多积累十分之一秒的额度；一次请求超过剩余额度时透支，之后的请求等待额度恢复
+type rateLimiter struct {
+	rate  float64 // 每秒补充的额度
+	burst float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(perSecond int) *rateLimiter {
+	rate := float64(perSecond)
+	burst := max(rate/10, 1)
+	return &rateLimiter{rate: rate, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// reserve 扣除n的额度，返回需要等待的时间
+func (l *rateLimiter) reserve(n float64) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+	l.tokens -= n
+	if l.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-l.tokens / l.rate * float64(time.Second))
+}
+
+// wait 扣除n的额度并等待额度恢复，ctx结束时返回ctx的错误（已扣除的额度不退还）
+func (l *rateLimiter) wait(ctx context.Context, n float64) error {
+	d := l.reserve(n)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// throttleHook 按命令数与数据量限速的 redis.Hook
+type throttleHook struct {
+	ops   *rateLimiter
+	bytes *rateLimiter
+}
+
+func newThrottleHook(maxOps, maxBytes int) *throttleHook {
+	h := &throttleHook{}
+	if maxOps > 0 {
+		h.ops = newRateLimiter(maxOps)
+	}
+	if maxBytes > 0 {
+		h.bytes = newRateLimiter(maxBytes)
+	}
+	return h
+}
+
+func (h *throttleHook) DialHook(next redis.DialHook) redis.DialHook {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return next(ctx, network, addr)
+	}
+}
+
+func (h *throttleHook) ProcessHook(next redis.ProcessHook) redis.ProcessHook {
+	return func(ctx context.Context, cmd redis.Cmder) error {
+		if err := h.acquire(ctx, []redis.Cmder{cmd}); err != nil {
+			return err
+		}
+		err := next(ctx, cmd)
+		h.charge([]redis.Cmder{cmd})
+		return err
+	}
+}
+
+func (h *throttleHook) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		if err := h.acquire(ctx, cmds); err != nil {
+			return err
+		}
+		err := next(ctx, cmds)
+		h.charge(cmds)
+		return err
+	}
+}
+
+// acquire 发送前等待命令数与参数数据量的额度
+func (h *throttleHook) acquire(ctx context.Context, cmds []redis.Cmder) error {
+	if h.ops != nil {
+		if err := h.ops.wait(ctx, float64(len(cmds))); err != nil {
+			return err
+		}
+	}
+	if h.bytes != nil {
+		n := 0
+		for _, cmd := range cmds {
+			for _, arg := range cmd.Args() {
+				n += argSize(arg)
+			}
+		}
+		return h.bytes.wait(ctx, float64(n))
+	}
+	return nil
+}
+
+// charge 收到回复后扣除回复数据量的额度，不等待
+func (h *throttleHook) charge(cmds []redis.Cmder) {
+	if h.bytes == nil {
+		return
+	}
+	n := 0
+	for _, cmd := range cmds {
+		n += replySize(cmd)
+	}
+	h.bytes.reserve(float64(n))
+}
+
+// argSize 估算命令参数的大小
+func argSize(arg any) int {
+	switch v := arg.(type) {
+	case string:
+		return len(v)
+	case []byte:
+		return len(v)
+	default:
+		return 8
+	}
+}
+
+// replySize 估算常见回复类型的大小，其他类型按0计算
+func replySize(cmd redis.Cmder) int {
+	n := 0
+	switch c := cmd.(type) {
+	case *redis.StringCmd:
+		n = len(c.Val())
+	case *redis.StringSliceCmd:
+		for _, s := range c.Val() {
+			n += len(s)
+		}
+	case *redis.ScanCmd:
+		keys, _ := c.Val()
+		for _, key := range keys {
+			n += len(key)
+		}
+	case *redis.SliceCmd:
+		for _, v := range c.Val() {
+			n += argSize(v)
+		}
+	case *redis.MapStringStringCmd:
+		for k, v := range c.Val() {
+			n += len(k) + len(v)
+		}
+	case *redis.Cmd:
+		n = anySize(c.Val())
+	}
+	return n
+}
+
+// anySize 估算脚本等命令返回的任意回复的大小
+func anySize(v any) int {
+	switch v := v.(type) {
+	case string:
+		return len(v)
+	case []any:
+		n := 0
+		for _, item := range v {
+			n += anySize(item)
+		}
+		return n
+	case nil:
+		return 0
+	default:
+		return 8
+	}
+}
@@ -0,0 +1,71 @@
+package go_cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/muleiwu/go-cache/scripts"
+	"github.com/redis/go-redis/v9"
+)
+
+// 时间序列的桶保存为哈希，字段 c 为次数，s 为总和，min 与 max 为最小值与最大值
+
+// timeSeriesRecordScript 将ARGV[1]聚合到桶中并设置桶的有效期为ARGV[2]毫秒
+var timeSeriesRecordScript = scripts.Register("go_cache:timeseries_record", `
+local v = tonumber(ARGV[1])
+redis.call("HINCRBY", KEYS[1], "c", 1)
+redis.call("HINCRBYFLOAT", KEYS[1], "s", ARGV[1])
+local min = tonumber(redis.call("HGET", KEYS[1], "min"))
+if not min or v < min then
+	redis.call("HSET", KEYS[1], "min", ARGV[1])
+end
+local max = tonumber(redis.call("HGET", KEYS[1], "max"))
+if not max or v > max then
+	redis.call("HSET", KEYS[1], "max", ARGV[1])
+end
+redis.call("PEXPIRE", KEYS[1], ARGV[2])
+return 1`)
+
+// recordTimeSeries 在服务端聚合写入的值
+func (c *Redis) recordTimeSeries(ctx context.Context, key string, value float64, ttl time.Duration) error {
+	v := strconv.FormatFloat(value, 'g', -1, 64)
+	if err := c.RunScript(ctx, timeSeriesRecordScript, []string{key}, v, max(ttl.Milliseconds(), 1)).Err(); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	return nil
+}
+
+// loadTimeSeries 通过管道读取多个桶
+func (c *Redis) loadTimeSeries(ctx context.Context, keys []string) ([]timeSeriesBucket, error) {
+	buckets := make([]timeSeriesBucket, len(keys))
+	if len(keys) == 0 {
+		return buckets, nil
+	}
+
+	cmds := make([]*redis.SliceCmd, len(keys))
+	_, err := c.conn.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.HMGet(ctx, key, "c", "s", "min", "max")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, classifyError(err)
+	}
+
+	for i, cmd := range cmds {
+		fields := cmd.Val()
+		if len(fields) != 4 || fields[0] == nil {
+			continue
+		}
+		values := make([]float64, 4)
+		for j, field := range fields {
+			s, _ := field.(string)
+			values[j], _ = strconv.ParseFloat(s, 64)
+		}
+		buckets[i] = timeSeriesBucket{Count: int64(values[0]), Sum: values[1], Min: values[2], Max: values[3]}
+	}
+	return buckets, nil
+}
@@ -0,0 +1,296 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muleiwu/gsr"
+	"github.com/redis/go-redis/v9"
+)
+
+// redisInvalidateChannel 服务端推送失效消息的频道
+const redisInvalidateChannel = "__redis__:invalidate"
+
+// RedisTrackingOption 客户端缓存选项
+type RedisTrackingOption func(*RedisTracking)
+
+// WithRedisTrackingLocalTTL 设置本地副本的最长保存时间，默认1分钟
+// 失效消息正常送达时本地副本会被及时删除，该值只是兜底
+func WithRedisTrackingLocalTTL(d time.Duration) RedisTrackingOption {
+	return func(t *RedisTracking) {
+		if d > 0 {
+			t.localTTL = d
+		}
+	}
+}
+
+// WithRedisTrackingMaxBytes 设置本地副本的内存上限（字节），默认不限制
+func WithRedisTrackingMaxBytes(n int64) RedisTrackingOption {
+	return func(t *RedisTracking) {
+		t.maxBytes = n
+	}
+}
+
+// WithRedisTrackingCacheOptions 设置创建底层Redis缓存时使用的选项
+func WithRedisTrackingCacheOptions(opts ...RedisOption) RedisTrackingOption {
+	return func(t *RedisTracking) {
+		t.cacheOpts = append(t.cacheOpts, opts...)
+	}
+}
+
+// RedisTracking 基于 Redis 6 客户端缓存（CLIENT TRACKING）的近端缓存
+// 读取过的键在本地保存一份副本，服务端在键被修改时推送失效消息，本地副本随之删除，
+// 在获得本地缓存性能的同时由服务端保证一致性。
+//
+// 失效消息通过 REDIRECT 发送到一条专用的订阅连接，RESP2 与 RESP3 均可使用；
+// 订阅连接断开重连期间可能丢失失效消息，因此重连时会清空本地副本并重建数据连接
+type RedisTracking struct {
+	opts      *redis.Options
+	cacheOpts []RedisOption
+	stats     *StatsCollector
+	localTTL  time.Duration
+	maxBytes  int64
+
+	local  *Memory
+	remote atomic.Pointer[Redis]
+
+	// epoch 每次收到失效消息时递增，读取期间发生失效时不写入本地副本
+	epoch atomic.Uint64
+
+	mu     sync.Mutex // 保护重建数据连接
+	sub    *redis.Client
+	pubsub *redis.PubSub
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewRedisTracking 使用opts连接Redis并开启客户端缓存
+// 内部会创建数据连接池与一条订阅连接，使用完毕后需要调用 Close
+func NewRedisTracking(opts *redis.Options, trackOpts ...RedisTrackingOption) (*RedisTracking, error) {
+	t := &RedisTracking{
+		opts:     opts,
+		stats:    NewStatsCollector(),
+		localTTL: time.Minute,
+	}
+	for _, opt := range trackOpts {
+		opt(t)
+	}
+
+	// 统计由本地副本与底层Redis缓存共享
+	t.cacheOpts = append(t.cacheOpts, WithRedisStats(t.stats))
+	t.local = NewMemory(t.localTTL, t.localTTL, WithMemoryMaxBytes(t.maxBytes))
+
+	subOpts := *opts
+	subOpts.PoolSize = 1
+	subOpts.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		if opts.OnConnect != nil {
+			if err := opts.OnConnect(ctx, cn); err != nil {
+				return err
+			}
+		}
+		id, err := cn.ClientID(ctx).Result()
+		if err != nil {
+			return fmt.Errorf("redis tracking: client id: %w", err)
+		}
+		t.redirect(id)
+		return nil
+	}
+	t.sub = redis.NewClient(&subOpts)
+
+	ctx := context.Background()
+	t.pubsub = t.sub.Subscribe(ctx, redisInvalidateChannel)
+	if _, err := t.pubsub.Receive(ctx); err != nil {
+		t.pubsub.Close()
+		t.sub.Close()
+		return nil, err
+	}
+
+	// 确认数据连接能够开启跟踪
+	if err := t.remote.Load().conn.Ping(ctx).Err(); err != nil {
+		t.Close()
+		return nil, err
+	}
+
+	t.wg.Add(1)
+	go t.listen()
+
+	return t, nil
+}
+
+// redirect 订阅连接（重新）建立后调用，使用新的连接ID重建数据连接并清空本地副本
+func (t *RedisTracking) redirect(id int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.closed {
+		return
+	}
+
+	dataOpts := *t.opts
+	dataOpts.OnConnect = func(ctx context.Context, cn *redis.Conn) error {
+		if t.opts.OnConnect != nil {
+			if err := t.opts.OnConnect(ctx, cn); err != nil {
+				return err
+			}
+		}
+		return cn.Do(ctx, "CLIENT", "TRACKING", "ON", "REDIRECT", id).Err()
+	}
+
+	old := t.remote.Swap(NewRedis(redis.NewClient(&dataOpts), t.cacheOpts...))
+	t.invalidateAll()
+	if old != nil {
+		go old.conn.Close()
+	}
+}
+
+// listen 处理失效消息
+func (t *RedisTracking) listen() {
+	defer t.wg.Done()
+
+	for msg := range t.pubsub.Channel() {
+		t.epoch.Add(1)
+		if len(msg.PayloadSlice) == 0 && msg.Payload == "" {
+			// FLUSHALL / FLUSHDB 等无法确定键的失效
+			t.local.Flush()
+			continue
+		}
+		for _, key := range msg.PayloadSlice {
+			t.local.Del(context.Background(), key)
+		}
+		if msg.Payload != "" {
+			t.local.Del(context.Background(), msg.Payload)
+		}
+	}
+}
+
+// invalidateAll 清空本地副本
+func (t *RedisTracking) invalidateAll() {
+	t.epoch.Add(1)
+	t.local.Flush()
+}
+
+// Close 关闭订阅连接与数据连接
+func (t *RedisTracking) Close() error {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil
+	}
+	t.closed = true
+	t.mu.Unlock()
+
+	err := t.pubsub.Close()
+	t.wg.Wait()
+	return errors.Join(err, t.sub.Close(), t.remote.Load().conn.Close())
+}
+
+// Stats 返回缓存统计快照，本地副本命中也计入命中次数
+func (t *RedisTracking) Stats() Stats {
+	return t.stats.Snapshot()
+}
+
+// LocalLen 返回本地副本的条目数
+func (t *RedisTracking) LocalLen() int {
+	keys, _ := t.local.Keys(context.Background(), "*")
+	return len(keys)
+}
+
+func (t *RedisTracking) Exists(ctx context.Context, key string) bool {
+	if t.local.Exists(ctx, key) {
+		return true
+	}
+	return t.remote.Load().Exists(ctx, key)
+}
+
+func (t *RedisTracking) Get(ctx context.Context, key string, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	remote := t.remote.Load()
+
+	var data []byte
+	if t.local.Get(ctx, key, &data) == nil {
+		if err := remote.decode(key, data, obj); err != nil {
+			t.stats.RecordError(key)
+			return serializationError(err)
+		}
+		t.stats.RecordHit(key, len(data))
+		return nil
+	}
+
+	epoch := t.epoch.Load()
+	value, err := remote.conn.Get(ctx, key).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			t.stats.RecordMiss(key)
+			return fmt.Errorf("%w: %w", ErrKeyNotFound, err)
+		}
+		t.stats.RecordError(key)
+		return classifyError(err)
+	}
+	// 启用 WithRedisBlobOffload 时本地副本保存对象的内容
+	data, err = remote.resolveBlob(ctx, value)
+	if err != nil {
+		t.stats.RecordError(key)
+		return err
+	}
+	if err := remote.decode(key, data, obj); err != nil {
+		t.stats.RecordError(key)
+		return serializationError(err)
+	}
+	t.stats.RecordHit(key, len(data))
+
+	// 读取期间收到过失效消息时，读到的值可能已经过时
+	if t.epoch.Load() == epoch {
+		_ = t.local.Set(ctx, key, data, t.localTTL)
+	}
+	return nil
+}
+
+func (t *RedisTracking) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	t.local.Del(ctx, key)
+	return t.remote.Load().Set(ctx, key, value, ttl)
+}
+
+// SetWithPriority 按优先级写入缓存
+// Redis的淘汰由服务端的maxmemory-policy决定，优先级不会影响淘汰顺序
+func (t *RedisTracking) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	return t.Set(ctx, key, value, ttl)
+}
+
+// SetNX 键不存在时写入，返回是否写入
+func (t *RedisTracking) SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error) {
+	t.local.Del(ctx, key)
+	return t.remote.Load().SetNX(ctx, key, value, ttl)
+}
+
+func (t *RedisTracking) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	return t.remote.Load().loader.getSet(ctx, t, key, ttl, obj, fun)
+}
+
+// loaderSettings 返回 GetSet 调用回调函数的方式，包装器沿用该设置
+func (t *RedisTracking) loaderSettings() loaderConfig {
+	return t.remote.Load().loader
+}
+
+func (t *RedisTracking) Del(ctx context.Context, key string) error {
+	t.local.Del(ctx, key)
+	return t.remote.Load().Del(ctx, key)
+}
+
+func (t *RedisTracking) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	t.local.Del(ctx, key)
+	return t.remote.Load().ExpiresAt(ctx, key, expiresAt)
+}
+
+func (t *RedisTracking) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	t.local.Del(ctx, key)
+	return t.remote.Load().ExpiresIn(ctx, key, ttl)
+}
@@ -0,0 +1,124 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Txn 使用 MULTI/EXEC 原子执行事务中的写操作
+// watch 中的键通过 WATCH 监视，事务函数执行期间被其他客户端修改时返回 ErrTxnConflict。
+// 启用 WithRedisBlobOffload 时超过阈值的值在提交前写入对象存储，被覆盖与删除的对象在提交后删除
+func (c *Redis) Txn(ctx context.Context, fn func(tx Txn) error, watch ...string) error {
+	var ops []txnOp
+	var written, replaced []string
+	err := c.conn.Watch(ctx, func(rtx *redis.Tx) error {
+		buf := &txnBuffer{
+			get: func(ctx context.Context, key string, obj any) error {
+				value, err := rtx.Get(ctx, key).Result()
+				if err != nil {
+					if errors.Is(err, redis.Nil) {
+						return fmt.Errorf("%w: %w", ErrKeyNotFound, err)
+					}
+					return err
+				}
+				data, err := c.resolveBlob(ctx, value)
+				if err != nil {
+					return err
+				}
+				return c.decode(key, data, obj)
+			},
+			encode: func(key string, value any) (any, error) {
+				return c.codec(key).Encode(value)
+			},
+		}
+		if err := fn(buf); err != nil {
+			return err
+		}
+		if len(buf.ops) == 0 {
+			return nil
+		}
+
+		values := make([]any, len(buf.ops))
+		for i, op := range buf.ops {
+			values[i] = op.value
+			if op.kind != OpSet || c.blobs == nil {
+				continue
+			}
+			value, name, err := c.offloadBlob(ctx, op.key, op.value.([]byte))
+			if err != nil {
+				return err
+			}
+			if name != "" {
+				values[i] = value
+				written = append(written, name)
+			}
+		}
+
+		// 启用对象存储时在事务中读取被覆盖与删除的值，提交后删除它们指向的对象
+		var olds []*redis.StringCmd
+		var writes []redis.Cmder
+		_, err := rtx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			for i, op := range buf.ops {
+				if c.blobs != nil && op.kind != OpExpire {
+					olds = append(olds, pipe.Get(ctx, op.key))
+				}
+				switch op.kind {
+				case OpSet:
+					writes = append(writes, pipe.Set(ctx, op.key, values[i], max(op.ttl, 0)))
+				case OpDel:
+					writes = append(writes, pipe.Del(ctx, op.key))
+				case OpExpire:
+					writes = append(writes, pipe.PExpire(ctx, op.key, op.ttl))
+				}
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, redis.TxFailedErr) {
+			// 读取旧值的 GET 在键不是字符串时失败，只以写操作的结果判断事务是否成功
+			err = nil
+			for _, cmd := range writes {
+				if err = cmd.Err(); err != nil {
+					break
+				}
+			}
+		}
+		if err == nil {
+			for _, cmd := range olds {
+				if name, ok := blobName(cmd.Val()); ok {
+					replaced = append(replaced, name)
+				}
+			}
+		}
+		ops = buf.ops
+		return err
+	}, watch...)
+
+	if c.blobs != nil {
+		if err != nil {
+			// 事务没有提交，删除已写入的对象
+			c.deleteBlobs(ctx, written)
+		} else {
+			c.deleteBlobs(ctx, replaced)
+		}
+	}
+
+	if errors.Is(err, redis.TxFailedErr) {
+		return ErrTxnConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, op := range ops {
+		switch op.kind {
+		case OpSet:
+			c.stats.RecordSet(op.key, len(op.value.([]byte)))
+		case OpDel:
+			c.stats.RecordDelete(op.key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,30 @@
+package go_cache
+
+import "context"
+
+// UniqueAdd 使用 PFADD 将ids加入key的HyperLogLog，返回估计值是否变化
+func (c *Redis) UniqueAdd(ctx context.Context, key string, ids ...string) (bool, error) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+	n, err := c.conn.PFAdd(ctx, key, args...).Result()
+	if err != nil {
+		c.stats.RecordError(key)
+		return false, classifyError(err)
+	}
+	return n == 1, nil
+}
+
+// UniqueCount 使用 PFCOUNT 返回keys并集中不重复元素数的估计值
+func (c *Redis) UniqueCount(ctx context.Context, keys ...string) (int64, error) {
+	if len(keys) == 0 {
+		return 0, nil
+	}
+	n, err := c.conn.PFCount(ctx, keys...).Result()
+	if err != nil {
+		c.stats.RecordError(keys[0])
+		return 0, classifyError(err)
+	}
+	return n, nil
+}
@@ -0,0 +1,46 @@
+package go_cache
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// ErrInvalidTarget 读取目标obj不是可写入的非nil指针
+var ErrInvalidTarget = serializer.ErrInvalidTarget
+
+// targetValue 返回obj指向的可写入的值
+// obj为nil、不是指针或是nil指针时返回 ErrInvalidTarget，而不是在赋值时panic
+func targetValue(obj any) (reflect.Value, error) {
+	objValue := reflect.ValueOf(obj)
+	if !objValue.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%w, got nil", ErrInvalidTarget)
+	}
+	if objValue.Kind() != reflect.Ptr {
+		return reflect.Value{}, fmt.Errorf("%w, got %s", ErrInvalidTarget, objValue.Type())
+	}
+	if objValue.IsNil() {
+		return reflect.Value{}, fmt.Errorf("%w, got nil %s", ErrInvalidTarget, objValue.Type())
+	}
+	return objValue.Elem(), nil
+}
+
+// pointee 返回obj指向的值，用于把回调函数写入obj的结果存入缓存
+// obj不是指针时原样返回；obj为nil或nil指针时返回 ErrInvalidTarget
+func pointee(obj any) (any, error) {
+	objValue := reflect.ValueOf(obj)
+	if objValue.IsValid() && objValue.Kind() != reflect.Ptr {
+		return obj, nil
+	}
+	elem, err := targetValue(obj)
+	if err != nil {
+		return nil, err
+	}
+	return elem.Interface(), nil
+}
+
+// valueType 返回值去掉指针后的类型，v为nil时返回nil
+func valueType(v any) reflect.Type {
+	return indirectType(reflect.TypeOf(v))
+}
@@ -0,0 +1,16 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+)
+
+// RenameCache 支持在缓存内部移动与复制键的缓存
+// 键迁移（如修改键的命名规则）时不需要经过应用程序解码再编码
+type RenameCache interface {
+	// Rename 将键重命名为newKey，保留过期时间；newKey 已存在时被覆盖，键不存在时返回 ErrKeyNotFound
+	Rename(ctx context.Context, key, newKey string) error
+	// Copy 将src的值复制到dst并设置过期时间ttl，ttl <= 0 表示不过期；
+	// dst 已存在时被覆盖，src 不存在时返回 ErrKeyNotFound
+	Copy(ctx context.Context, src, dst string, ttl time.Duration) error
+}
@@ -0,0 +1,178 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// requestMemoKey 请求级缓存在context中的键
+type requestMemoKey struct{}
+
+// requestMemo 一次请求内的记忆结果，按 RequestCache 实例区分
+type requestMemo struct {
+	mu      sync.Mutex
+	byCache map[*RequestCache]map[string]memoResult
+}
+
+// memoResult 记忆的读取结果，found 为false表示键不存在
+type memoResult struct {
+	value any
+	found bool
+}
+
+// WithRequestScope 返回带有请求级缓存存储的context，通常在每个HTTP请求开始时调用
+// 没有请求级存储的context中，RequestCache 直接访问底层缓存
+func WithRequestScope(ctx context.Context) context.Context {
+	return context.WithValue(ctx, requestMemoKey{}, &requestMemo{byCache: make(map[*RequestCache]map[string]memoResult)})
+}
+
+// RequestScopeMiddleware 为每个请求的context添加请求级缓存存储
+func RequestScopeMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(WithRequestScope(r.Context())))
+	})
+}
+
+// RequestCache 请求级记忆缓存
+// 在 WithRequestScope 创建的context中，同一个键在一次请求内只访问一次底层缓存，
+// 之后的 Get、GetSet 直接返回记忆的结果（包括键不存在），消除同一请求内的重复查询。
+// 写入、删除与修改过期时间会更新或清除记忆的结果；记忆的值不会被深拷贝，读取方不应修改
+type RequestCache struct {
+	cache gsr.Cacher
+}
+
+// NewRequestCache 创建包装cache的请求级记忆缓存
+func NewRequestCache(cache gsr.Cacher) *RequestCache {
+	return &RequestCache{cache: cache}
+}
+
+// memo 返回context中本实例的记忆结果，没有请求级存储时返回nil
+// 调用方需持有返回的锁
+func (r *RequestCache) memo(ctx context.Context) (*requestMemo, map[string]memoResult) {
+	m, ok := ctx.Value(requestMemoKey{}).(*requestMemo)
+	if !ok {
+		return nil, nil
+	}
+	m.mu.Lock()
+	results, ok := m.byCache[r]
+	if !ok {
+		results = make(map[string]memoResult)
+		m.byCache[r] = results
+	}
+	return m, results
+}
+
+// recall 返回记忆的结果
+func (r *RequestCache) recall(ctx context.Context, key string) (memoResult, bool) {
+	m, results := r.memo(ctx)
+	if m == nil {
+		return memoResult{}, false
+	}
+	defer m.mu.Unlock()
+	res, ok := results[key]
+	return res, ok
+}
+
+// remember 记忆读取或写入的结果
+func (r *RequestCache) remember(ctx context.Context, key string, res memoResult) {
+	m, results := r.memo(ctx)
+	if m == nil {
+		return
+	}
+	defer m.mu.Unlock()
+	results[key] = res
+}
+
+// forget 清除记忆的结果
+func (r *RequestCache) forget(ctx context.Context, key string) {
+	m, results := r.memo(ctx)
+	if m == nil {
+		return
+	}
+	defer m.mu.Unlock()
+	delete(results, key)
+}
+
+func (r *RequestCache) Exists(ctx context.Context, key string) bool {
+	if res, ok := r.recall(ctx, key); ok {
+		return res.found
+	}
+	return r.cache.Exists(ctx, key)
+}
+
+func (r *RequestCache) Get(ctx context.Context, key string, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	if res, ok := r.recall(ctx, key); ok {
+		if !res.found {
+			return ErrKeyNotFound
+		}
+		// 类型不一致时回退到底层缓存
+		if err := assignValue(obj, res.value); err == nil {
+			return nil
+		}
+	}
+
+	err := r.cache.Get(ctx, key, obj)
+	switch {
+	case err == nil:
+		if value, err := pointee(obj); err == nil {
+			r.remember(ctx, key, memoResult{value: value, found: true})
+		}
+	case errors.Is(err, ErrKeyNotFound) && !errors.Is(err, ErrCacheBypassed):
+		r.remember(ctx, key, memoResult{})
+	}
+	return err
+}
+
+func (r *RequestCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	if err := r.cache.Set(ctx, key, value, ttl); err != nil {
+		r.forget(ctx, key)
+		return err
+	}
+	r.remember(ctx, key, memoResult{value: value, found: true})
+	return nil
+}
+
+// GetSet 记忆中没有结果时调用底层缓存的 GetSet，保留其回调函数的超时与panic处理
+func (r *RequestCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	if !forceRefresh(ctx) {
+		if res, ok := r.recall(ctx, key); ok && res.found && assignValue(obj, res.value) == nil {
+			return nil
+		}
+	}
+
+	if err := r.cache.GetSet(ctx, key, ttl, obj, fun); err != nil {
+		return err
+	}
+	value, err := pointee(obj)
+	if err != nil {
+		return err
+	}
+	r.remember(ctx, key, memoResult{value: value, found: true})
+	return nil
+}
+
+func (r *RequestCache) Del(ctx context.Context, key string) error {
+	r.forget(ctx, key)
+	return r.cache.Del(ctx, key)
+}
+
+func (r *RequestCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	r.forget(ctx, key)
+	return r.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (r *RequestCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	r.forget(ctx, key)
+	return r.cache.ExpiresIn(ctx, key, ttl)
+}
@@ -0,0 +1,170 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ErrNoRoute 键没有匹配的前缀，且没有设置默认后端
+var ErrNoRoute = errors.New("no cache backend for key")
+
+// RoutedCache 按键前缀把操作分派到不同后端的缓存
+// 例如会话保存在Redis、功能开关保存在内存、大对象保存在本地文件，应用程序只使用一个缓存实例。
+// 多个前缀匹配时使用最长的前缀，没有匹配时使用默认后端
+type RoutedCache struct {
+	routes   map[string]gsr.Cacher
+	fallback gsr.Cacher
+}
+
+// NewRouted 创建按前缀分派的缓存，routes 的键为键前缀；fallback 为默认后端，为nil时没有匹配前缀的操作返回 ErrNoRoute
+func NewRouted(fallback gsr.Cacher, routes map[string]gsr.Cacher) *RoutedCache {
+	copied := make(map[string]gsr.Cacher, len(routes))
+	for prefix, cache := range routes {
+		copied[prefix] = cache
+	}
+	return &RoutedCache{routes: copied, fallback: fallback}
+}
+
+// Backend 返回键所在的后端，没有匹配的后端时返回nil
+func (r *RoutedCache) Backend(key string) gsr.Cacher {
+	backend, longest := r.fallback, -1
+	for prefix, cache := range r.routes {
+		if strings.HasPrefix(key, prefix) && len(prefix) > longest {
+			backend, longest = cache, len(prefix)
+		}
+	}
+	return backend
+}
+
+// backend 返回键所在的后端，没有匹配的后端时返回 ErrNoRoute
+func (r *RoutedCache) backend(key string) (gsr.Cacher, error) {
+	if backend := r.Backend(key); backend != nil {
+		return backend, nil
+	}
+	return nil, fmt.Errorf("%w: %q", ErrNoRoute, key)
+}
+
+// backends 返回全部不重复的后端
+func (r *RoutedCache) backends() []gsr.Cacher {
+	var backends []gsr.Cacher
+	seen := make(map[gsr.Cacher]bool)
+	add := func(cache gsr.Cacher) {
+		if cache != nil && !seen[cache] {
+			seen[cache] = true
+			backends = append(backends, cache)
+		}
+	}
+	add(r.fallback)
+	for _, cache := range r.routes {
+		add(cache)
+	}
+	return backends
+}
+
+func (r *RoutedCache) Exists(ctx context.Context, key string) bool {
+	backend, err := r.backend(key)
+	return err == nil && backend.Exists(ctx, key)
+}
+
+func (r *RoutedCache) Get(ctx context.Context, key string, obj any) error {
+	backend, err := r.backend(key)
+	if err != nil {
+		return err
+	}
+	return backend.Get(ctx, key, obj)
+}
+
+func (r *RoutedCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	backend, err := r.backend(key)
+	if err != nil {
+		return err
+	}
+	return backend.Set(ctx, key, value, ttl)
+}
+
+func (r *RoutedCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	backend, err := r.backend(key)
+	if err != nil {
+		return err
+	}
+	return backend.GetSet(ctx, key, ttl, obj, fun)
+}
+
+func (r *RoutedCache) Del(ctx context.Context, key string) error {
+	backend, err := r.backend(key)
+	if err != nil {
+		return err
+	}
+	return backend.Del(ctx, key)
+}
+
+func (r *RoutedCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	backend, err := r.backend(key)
+	if err != nil {
+		return err
+	}
+	return backend.ExpiresAt(ctx, key, expiresAt)
+}
+
+func (r *RoutedCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	backend, err := r.backend(key)
+	if err != nil {
+		return err
+	}
+	return backend.ExpiresIn(ctx, key, ttl)
+}
+
+// Keys 在每个后端上按模式列出键，只返回按前缀应由该后端保存的键
+// 所有后端都需实现 PatternCache
+func (r *RoutedCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	var keys []string
+	for _, backend := range r.backends() {
+		pc, ok := backend.(PatternCache)
+		if !ok {
+			return nil, ErrNotSupported
+		}
+		found, err := pc.Keys(ctx, pattern)
+		for _, key := range found {
+			if r.Backend(key) == backend {
+				keys = append(keys, key)
+			}
+		}
+		if err != nil {
+			return keys, err
+		}
+	}
+	return keys, nil
+}
+
+// DelByPattern 在每个后端上按模式删除键，返回删除的总数
+// 所有后端都需实现 PatternCache
+func (r *RoutedCache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	var total int64
+	for _, backend := range r.backends() {
+		pc, ok := backend.(PatternCache)
+		if !ok {
+			return total, ErrNotSupported
+		}
+		n, err := pc.DelByPattern(ctx, pattern)
+		total += n
+		if err != nil {
+			return total, err
+		}
+	}
+	return total, nil
+}
+
+// Capabilities 返回支持的能力，所有后端都实现 PatternCache 时才支持按模式操作
+func (r *RoutedCache) Capabilities() []Capability {
+	for _, backend := range r.backends() {
+		if !Supports(backend, CapabilityPattern) {
+			return nil
+		}
+	}
+	return []Capability{CapabilityPattern}
+}
@@ -0,0 +1,349 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultSchedulerKeyPrefix 延迟任务在缓存中的默认键前缀
+const DefaultSchedulerKeyPrefix = "schedule:"
+
+// schedulerEntry 缓存中保存的一个延迟任务
+// Due 为任务可以被领取的时间（Unix毫秒），领取后推迟为可见性超时的截止时间
+type schedulerEntry struct {
+	Payload  []byte
+	Due      int64
+	Attempts int
+}
+
+// schedulerState 缓存中保存的全部延迟任务，任务键到任务
+type schedulerState struct {
+	Tasks map[string]schedulerEntry
+}
+
+// schedulerClaim 领取到的任务
+type schedulerClaim struct {
+	key      string
+	payload  []byte
+	attempt  int
+	deadline int64 // 可见性超时的截止时间（Unix毫秒），确认时用于判断任务是否被重新调度
+}
+
+// schedulerBackend 由能够在服务端原子操作延迟任务的缓存实现（如Redis使用有序集合与Lua脚本）
+type schedulerBackend interface {
+	scheduleTask(ctx context.Context, key, task string, payload []byte, runAt time.Time) error
+	claimTasks(ctx context.Context, key string, visibility time.Duration, limit int) ([]schedulerClaim, error)
+	removeTask(ctx context.Context, key, task string, deadline int64) (bool, error)
+	countTasks(ctx context.Context, key string) (int, error)
+}
+
+// ScheduledTask 投递给处理函数的延迟任务
+type ScheduledTask struct {
+	Key     string
+	Attempt int // 第几次投递，从1开始；处理失败或超时后重新投递时递增
+
+	payload    []byte
+	serializer serializer.Serializer
+}
+
+// Decode 将任务的负载解码到obj
+func (t *ScheduledTask) Decode(obj any) error {
+	if err := t.serializer.Decode(t.payload, obj); err != nil {
+		return serializationError(fmt.Errorf("scheduled task %s: %w", t.Key, err))
+	}
+	return nil
+}
+
+// TaskHandler 延迟任务的处理函数，返回nil时任务被确认并移除
+type TaskHandler func(ctx context.Context, task *ScheduledTask) error
+
+// SchedulerOption 延迟任务选项
+type SchedulerOption func(*Scheduler)
+
+// WithSchedulerPollInterval 设置 Run 轮询到期任务的间隔，默认1秒
+func WithSchedulerPollInterval(d time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		if d > 0 {
+			s.pollInterval = d
+		}
+	}
+}
+
+// WithSchedulerVisibilityTimeout 设置可见性超时，默认30秒
+// 任务被领取后在该时间内对其他轮询者不可见，处理函数失败或进程崩溃未确认时，超时后重新投递
+func WithSchedulerVisibilityTimeout(d time.Duration) SchedulerOption {
+	return func(s *Scheduler) {
+		if d > 0 {
+			s.visibility = d
+		}
+	}
+}
+
+// WithSchedulerBatchSize 设置每次领取的最大任务数，默认10
+func WithSchedulerBatchSize(n int) SchedulerOption {
+	return func(s *Scheduler) {
+		if n > 0 {
+			s.batchSize = n
+		}
+	}
+}
+
+// WithSchedulerSerializer 设置任务负载的序列化器
+func WithSchedulerSerializer(ser serializer.Serializer) SchedulerOption {
+	return func(s *Scheduler) {
+		s.serializer = ser
+	}
+}
+
+// WithSchedulerKeyPrefix 设置延迟任务在缓存中的键前缀，默认 DefaultSchedulerKeyPrefix
+func WithSchedulerKeyPrefix(prefix string) SchedulerOption {
+	return func(s *Scheduler) {
+		s.prefix = prefix
+	}
+}
+
+// WithSchedulerClock 设置判断任务到期所用的时钟，默认使用系统时间
+// Redis缓存在服务端使用Redis的时间，不受该选项影响
+func WithSchedulerClock(clock Clock) SchedulerOption {
+	return func(s *Scheduler) {
+		if clock != nil {
+			s.clock = clock
+		}
+	}
+}
+
+// WithSchedulerErrorHook 设置处理函数返回错误或领取任务失败时的回调，领取失败时key为空
+func WithSchedulerErrorHook(hook LoaderFailureHook) SchedulerOption {
+	return func(s *Scheduler) {
+		s.onError = hook
+	}
+}
+
+// Scheduler 基于缓存的延迟任务，在指定时间将任务投递给处理函数
+// 投递语义为至少一次：任务被领取后在可见性超时内没有确认时会重新投递，
+// 处理函数需要能够安全地重复执行。适合不需要完整消息队列的轻量场景。
+// Redis缓存使用有序集合（分数为到期时间）与Lua脚本在服务端原子执行，
+// 实现 TxnCache 的缓存在事务中执行，其他缓存只在本实例内串行
+type Scheduler struct {
+	cache        gsr.Cacher
+	key          string
+	pollInterval time.Duration
+	visibility   time.Duration
+	batchSize    int
+	serializer   serializer.Serializer
+	prefix       string
+	clock        Clock
+	onError      LoaderFailureHook
+
+	mu sync.Mutex // 底层缓存不支持事务时保护状态的读-改-写
+}
+
+// NewScheduler 创建名为name的延迟任务，默认使用gob序列化器
+func NewScheduler(cache gsr.Cacher, name string, opts ...SchedulerOption) *Scheduler {
+	s := &Scheduler{
+		cache:        cache,
+		pollInterval: time.Second,
+		visibility:   30 * time.Second,
+		batchSize:    10,
+		serializer:   cache_value.GetDefaultSerializer(), // 默认使用gob
+		prefix:       DefaultSchedulerKeyPrefix,
+		clock:        realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.key = s.prefix + name
+	return s
+}
+
+// Schedule 调度任务key在runAt执行，payload 为投递给处理函数的负载
+// 相同key的任务已存在时替换其负载与执行时间（包括已被领取、正在处理的任务）；runAt 已过去时立即到期
+func (s *Scheduler) Schedule(ctx context.Context, key string, payload any, runAt time.Time) error {
+	data, err := s.serializer.Encode(payload)
+	if err != nil {
+		return serializationError(err)
+	}
+	if b, ok := s.cache.(schedulerBackend); ok {
+		return b.scheduleTask(ctx, s.key, key, data, runAt)
+	}
+	return s.update(ctx, func(tasks map[string]schedulerEntry, now int64) bool {
+		tasks[key] = schedulerEntry{Payload: data, Due: runAt.UnixMilli()}
+		return true
+	})
+}
+
+// Cancel 取消任务key，返回任务是否存在
+func (s *Scheduler) Cancel(ctx context.Context, key string) (bool, error) {
+	return s.remove(ctx, key, 0)
+}
+
+// Len 返回尚未确认的任务数，包括未到期与正在处理的任务
+func (s *Scheduler) Len(ctx context.Context) (int, error) {
+	if b, ok := s.cache.(schedulerBackend); ok {
+		return b.countTasks(ctx, s.key)
+	}
+	state, err := s.load(ctx, s.cache.Get)
+	if err != nil {
+		return 0, err
+	}
+	return len(state.Tasks), nil
+}
+
+// Poll 领取一批到期的任务并依次交给handler处理，返回领取到的任务数
+// handler 返回nil的任务被确认并移除，返回错误的任务在可见性超时后重新投递
+func (s *Scheduler) Poll(ctx context.Context, handler TaskHandler) (int, error) {
+	claims, err := s.claim(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, c := range claims {
+		task := &ScheduledTask{
+			Key:        c.key,
+			Attempt:    c.attempt,
+			payload:    c.payload,
+			serializer: s.serializer,
+		}
+		if err := handler(ctx, task); err != nil {
+			s.reportError(c.key, err)
+			continue
+		}
+		// 处理期间任务被重新调度时不移除
+		if _, err := s.remove(ctx, c.key, c.deadline); err != nil {
+			s.reportError(c.key, err)
+		}
+	}
+	return len(claims), nil
+}
+
+// Run 按轮询间隔调用 Poll，直到ctx结束并返回ctx的错误
+// 领取到一整批任务时立即再次轮询；领取失败时调用错误回调后继续轮询
+func (s *Scheduler) Run(ctx context.Context, handler TaskHandler) error {
+	ticker := time.NewTicker(s.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := s.Poll(ctx, handler)
+		if err != nil {
+			s.reportError("", err)
+		}
+		if err == nil && n >= s.batchSize {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// claim 领取到期的任务
+func (s *Scheduler) claim(ctx context.Context) ([]schedulerClaim, error) {
+	if b, ok := s.cache.(schedulerBackend); ok {
+		return b.claimTasks(ctx, s.key, s.visibility, s.batchSize)
+	}
+
+	var claims []schedulerClaim
+	err := s.update(ctx, func(tasks map[string]schedulerEntry, now int64) bool {
+		// 事务冲突时会重新执行，每次都要重新领取
+		claims = claims[:0]
+		for key, entry := range tasks {
+			if entry.Due <= now {
+				claims = append(claims, schedulerClaim{key: key, deadline: entry.Due})
+			}
+		}
+		sort.Slice(claims, func(i, j int) bool {
+			if claims[i].deadline != claims[j].deadline {
+				return claims[i].deadline < claims[j].deadline
+			}
+			return claims[i].key < claims[j].key
+		})
+		claims = claims[:min(len(claims), s.batchSize)]
+
+		deadline := now + s.visibility.Milliseconds()
+		for i, c := range claims {
+			entry := tasks[c.key]
+			entry.Due = deadline
+			entry.Attempts++
+			tasks[c.key] = entry
+			claims[i] = schedulerClaim{key: c.key, payload: entry.Payload, attempt: entry.Attempts, deadline: deadline}
+		}
+		return len(claims) > 0
+	})
+	if err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// remove 移除任务，deadline 非0时只在任务仍处于该次领取时移除
+func (s *Scheduler) remove(ctx context.Context, key string, deadline int64) (bool, error) {
+	if b, ok := s.cache.(schedulerBackend); ok {
+		return b.removeTask(ctx, s.key, key, deadline)
+	}
+
+	var removed bool
+	err := s.update(ctx, func(tasks map[string]schedulerEntry, now int64) bool {
+		entry, ok := tasks[key]
+		if removed = ok && (deadline == 0 || entry.Due == deadline); removed {
+			delete(tasks, key)
+		}
+		return removed
+	})
+	return removed, err
+}
+
+// reportError 调用错误回调
+func (s *Scheduler) reportError(key string, err error) {
+	if s.onError != nil {
+		s.onError(key, err)
+	}
+}
+
+// load 读取全部任务，不存在时返回空状态
+func (s *Scheduler) load(ctx context.Context, get func(context.Context, string, any) error) (schedulerState, error) {
+	var state schedulerState
+	if err := get(ctx, s.key, &state); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return schedulerState{}, err
+	}
+	return state, nil
+}
+
+// update 读取全部任务后执行fn，fn 返回false时不写回；没有任务时删除键
+func (s *Scheduler) update(ctx context.Context, fn func(tasks map[string]schedulerEntry, now int64) bool) error {
+	return update(ctx, s.cache, &s.mu, s.key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+		state, err := s.load(ctx, get)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// 内存缓存可能返回与缓存共享的映射，修改前需要复制
+		tasks := make(map[string]schedulerEntry, len(state.Tasks))
+		for key, entry := range state.Tasks {
+			tasks[key] = entry
+		}
+		if !fn(tasks, s.clock.Now().UnixMilli()) {
+			return nil, 0, errNoUpdate
+		}
+
+		if len(tasks) == 0 {
+			return nil, 0, nil
+		}
+		return schedulerState{Tasks: tasks}, 0, nil
+	})
+}
@@ -0,0 +1,173 @@
+package go_cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSchemaViolation 严格模式下写入的键没有注册的模式，或值类型、TTL不符合键的模式
+var ErrSchemaViolation = errors.New("key schema violation")
+
+// ErrDuplicateSchema 注册的前缀已被其他模式使用
+var ErrDuplicateSchema = errors.New("duplicate key schema")
+
+// KeySchema 一类缓存键的模式
+type KeySchema struct {
+	Prefix      string        // 键前缀，如 "user:"，不能为空
+	Type        reflect.Type  // 值的类型，如 reflect.TypeFor[User]()，nil 表示不限制
+	MinTTL      time.Duration // 最短有效期，0 表示不限制
+	MaxTTL      time.Duration // 最长有效期，0 表示不限制
+	Owner       string        // 负责的团队或服务
+	Description string        // 用途说明
+}
+
+// schemaJSON 清单中一个模式的JSON格式
+type schemaJSON struct {
+	Prefix      string `json:"prefix"`
+	Type        string `json:"type,omitempty"`
+	MinTTL      string `json:"min_ttl,omitempty"`
+	MaxTTL      string `json:"max_ttl,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// SchemaRegistry 缓存键模式的注册表
+// 通过 WithRedisSchemas、WithMemorySchemas 设置到缓存后，严格模式下的写入（Set、SetNX、GetSet）
+// 与 ExpiresIn 按最长匹配的前缀检查模式，没有匹配的模式或不符合模式时返回 ErrSchemaViolation；
+// 非严格模式下注册表只用于生成清单。可以在多个缓存实例之间共享，并发安全
+type SchemaRegistry struct {
+	mu      sync.RWMutex
+	schemas map[string]KeySchema
+}
+
+// NewSchemaRegistry 创建空的键模式注册表
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{schemas: make(map[string]KeySchema)}
+}
+
+// Register 注册键模式，前缀为空或已被注册时返回错误
+func (r *SchemaRegistry) Register(schema KeySchema) error {
+	if schema.Prefix == "" {
+		return fmt.Errorf("%w: empty prefix", ErrSchemaViolation)
+	}
+	if schema.MaxTTL > 0 && schema.MinTTL > schema.MaxTTL {
+		return fmt.Errorf("%w: %q min ttl %v exceeds max ttl %v", ErrSchemaViolation, schema.Prefix, schema.MinTTL, schema.MaxTTL)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if existing, ok := r.schemas[schema.Prefix]; ok {
+		return fmt.Errorf("%w: %q already registered by %q", ErrDuplicateSchema, schema.Prefix, existing.Owner)
+	}
+	r.schemas[schema.Prefix] = schema
+	return nil
+}
+
+// MustRegister 注册键模式，失败时panic，用于包级变量的初始化
+func (r *SchemaRegistry) MustRegister(schema KeySchema) {
+	if err := r.Register(schema); err != nil {
+		panic(err)
+	}
+}
+
+// Lookup 返回键所属的模式，多个前缀匹配时使用最长的前缀
+func (r *SchemaRegistry) Lookup(key string) (KeySchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var found KeySchema
+	ok := false
+	for prefix, schema := range r.schemas {
+		if strings.HasPrefix(key, prefix) && len(prefix) > len(found.Prefix) {
+			found, ok = schema, true
+		}
+	}
+	return found, ok
+}
+
+// Validate 检查写入是否符合键的模式，ttl <= 0 表示不设置过期时间
+func (r *SchemaRegistry) Validate(key string, value any, ttl time.Duration) error {
+	schema, ok := r.Lookup(key)
+	if !ok {
+		return fmt.Errorf("%w: no schema registered for key %q", ErrSchemaViolation, key)
+	}
+	if schema.Type != nil {
+		if typ := reflect.TypeOf(value); typ != schema.Type {
+			return fmt.Errorf("%w: key %q expects %v, got %v", ErrSchemaViolation, key, schema.Type, typ)
+		}
+	}
+	return schema.checkTTL(key, ttl)
+}
+
+// checkTTL 检查ttl是否在模式的范围内
+func (s KeySchema) checkTTL(key string, ttl time.Duration) error {
+	if s.MaxTTL > 0 && (ttl <= 0 || ttl > s.MaxTTL) {
+		return fmt.Errorf("%w: key %q ttl %v exceeds max ttl %v", ErrSchemaViolation, key, ttl, s.MaxTTL)
+	}
+	if s.MinTTL > 0 && ttl > 0 && ttl < s.MinTTL {
+		return fmt.Errorf("%w: key %q ttl %v below min ttl %v", ErrSchemaViolation, key, ttl, s.MinTTL)
+	}
+	return nil
+}
+
+// Inventory 返回按前缀排序的全部模式
+func (r *SchemaRegistry) Inventory() []KeySchema {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	schemas := make([]KeySchema, 0, len(r.schemas))
+	for _, schema := range r.schemas {
+		schemas = append(schemas, schema)
+	}
+	sort.Slice(schemas, func(i, j int) bool {
+		return schemas[i].Prefix < schemas[j].Prefix
+	})
+	return schemas
+}
+
+// WriteInventory 将全部模式以JSON数组写入w，用于生成文档与审计
+// 类型使用Go的类型名（如 "main.User"），有效期使用 time.Duration 的字符串格式（如 "1h0m0s"）
+func (r *SchemaRegistry) WriteInventory(w io.Writer) error {
+	schemas := r.Inventory()
+	out := make([]schemaJSON, len(schemas))
+	for i, s := range schemas {
+		out[i] = schemaJSON{Prefix: s.Prefix, Owner: s.Owner, Description: s.Description}
+		if s.Type != nil {
+			out[i].Type = s.Type.String()
+		}
+		if s.MinTTL > 0 {
+			out[i].MinTTL = s.MinTTL.String()
+		}
+		if s.MaxTTL > 0 {
+			out[i].MaxTTL = s.MaxTTL.String()
+		}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+// checkSchema 严格模式下检查写入是否符合注册表，r 为nil时不检查
+func (r *SchemaRegistry) checkSchema(strict bool, key string, value any, ttl time.Duration) error {
+	if r == nil || !strict {
+		return nil
+	}
+	return r.Validate(key, value, ttl)
+}
+
+// checkExpiry 严格模式下检查设置的过期时间是否符合键的模式，r 为nil时不检查
+func (r *SchemaRegistry) checkExpiry(strict bool, key string, ttl time.Duration) error {
+	if r == nil || !strict {
+		return nil
+	}
+	schema, ok := r.Lookup(key)
+	if !ok {
+		return fmt.Errorf("%w: no schema registered for key %q", ErrSchemaViolation, key)
+	}
+	return schema.checkTTL(key, ttl)
+}
@@ -0,0 +1,162 @@
+// Package scripts 管理缓存使用的Redis Lua脚本
+//
+// 脚本在注册表中以名称登记，执行时优先使用 EVALSHA，
+// 服务端没有缓存脚本（NOSCRIPT）时回退为 EVAL 并由服务端重新缓存。
+// CAS、分布式锁、分批删除、限流等需要原子操作的功能都通过本包执行脚本：
+//
+//	var incrIfExists = scripts.Register("incr_if_exists", `
+//		if redis.call("EXISTS", KEYS[1]) == 1 then
+//			return redis.call("INCR", KEYS[1])
+//		end
+//		return nil`)
+//
+//	n, err := incrIfExists.Run(ctx, client, []string{"counter"}).Int64()
+package scripts
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Script 可在Redis上执行的Lua脚本
+type Script struct {
+	name string
+	src  string
+	hash string
+}
+
+// New 创建未注册的脚本
+func New(src string) *Script {
+	h := sha1.Sum([]byte(src))
+	return &Script{src: src, hash: hex.EncodeToString(h[:])}
+}
+
+// Name 返回注册名称，未注册的脚本返回空字符串
+func (s *Script) Name() string {
+	return s.name
+}
+
+// Source 返回脚本源码
+func (s *Script) Source() string {
+	return s.src
+}
+
+// Hash 返回脚本的SHA1，即 EVALSHA 使用的摘要
+func (s *Script) Hash() string {
+	return s.hash
+}
+
+// Load 使用 SCRIPT LOAD 将脚本缓存到服务端
+func (s *Script) Load(ctx context.Context, c redis.Scripter) error {
+	return c.ScriptLoad(ctx, s.src).Err()
+}
+
+// Run 执行脚本
+// 优先使用 EVALSHA，服务端返回 NOSCRIPT 时回退为 EVAL
+func (s *Script) Run(ctx context.Context, c redis.Scripter, keys []string, args ...any) *redis.Cmd {
+	cmd := c.EvalSha(ctx, s.hash, keys, args...)
+	if redis.HasErrorPrefix(cmd.Err(), "NOSCRIPT") {
+		return c.Eval(ctx, s.src, keys, args...)
+	}
+	return cmd
+}
+
+// Registry 脚本注册表，并发安全
+type Registry struct {
+	mu      sync.RWMutex
+	scripts map[string]*Script
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{scripts: make(map[string]*Script)}
+}
+
+// Register 注册脚本并返回
+// 以相同名称重复注册相同源码时返回已注册的脚本，源码不同时panic
+func (r *Registry) Register(name, src string) *Script {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if s, ok := r.scripts[name]; ok {
+		if s.src != src {
+			panic(fmt.Sprintf("scripts: script %q already registered with different source", name))
+		}
+		return s
+	}
+
+	s := New(src)
+	s.name = name
+	r.scripts[name] = s
+	return s
+}
+
+// Get 返回已注册的脚本
+func (r *Registry) Get(name string) (*Script, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	s, ok := r.scripts[name]
+	return s, ok
+}
+
+// Names 返回已注册的脚本名称，按字典序排列
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.scripts))
+	for name := range r.scripts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Load 将所有已注册的脚本缓存到服务端，可在启动时调用以避免首次执行时回退为 EVAL
+func (r *Registry) Load(ctx context.Context, c redis.Scripter) error {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name, s := range r.scripts {
+		if err := s.Load(ctx, c); err != nil {
+			return fmt.Errorf("scripts: load %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Run 执行已注册的脚本
+func (r *Registry) Run(ctx context.Context, c redis.Scripter, name string, keys []string, args ...any) *redis.Cmd {
+	s, ok := r.Get(name)
+	if !ok {
+		cmd := redis.NewCmd(ctx)
+		cmd.SetErr(fmt.Errorf("scripts: script %q not registered", name))
+		return cmd
+	}
+	return s.Run(ctx, c, keys, args...)
+}
+
+// Default 默认注册表，本库内置的脚本都注册在这里
+var Default = NewRegistry()
+
+// Register 在默认注册表中注册脚本
+func Register(name, src string) *Script {
+	return Default.Register(name, src)
+}
+
+// Get 返回默认注册表中的脚本
+func Get(name string) (*Script, bool) {
+	return Default.Get(name)
+}
+
+// Load 将默认注册表中的所有脚本缓存到服务端
+func Load(ctx context.Context, c redis.Scripter) error {
+	return Default.Load(ctx, c)
+}
@@ -0,0 +1,271 @@
+package go_cache
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultSemaphoreKeyPrefix 信号量在缓存中的默认键前缀
+const DefaultSemaphoreKeyPrefix = "semaphore:"
+
+// ErrLeaseLost 租约已过期或已释放，持有者应停止受保护的工作
+var ErrLeaseLost = errors.New("semaphore lease lost")
+
+// semaphoreState 缓存中保存的信号量持有者，租约标识到过期时间（Unix毫秒）
+type semaphoreState struct {
+	Holders map[string]int64
+}
+
+// semaphoreBackend 由能够在服务端原子操作信号量的缓存实现（如Redis使用有序集合与Lua脚本）
+type semaphoreBackend interface {
+	acquireSemaphore(ctx context.Context, key, token string, limit int, ttl time.Duration) (bool, error)
+	refreshSemaphore(ctx context.Context, key, token string, ttl time.Duration) (bool, error)
+	releaseSemaphore(ctx context.Context, key, token string) error
+	countSemaphore(ctx context.Context, key string) (int, error)
+}
+
+// SemaphoreOption 信号量选项
+type SemaphoreOption func(*Semaphore)
+
+// WithSemaphoreRetryInterval 设置 Acquire 等待时重试的间隔，默认50毫秒
+func WithSemaphoreRetryInterval(d time.Duration) SemaphoreOption {
+	return func(s *Semaphore) {
+		if d > 0 {
+			s.retryInterval = d
+		}
+	}
+}
+
+// WithSemaphoreKeyPrefix 设置信号量在缓存中的键前缀，默认 DefaultSemaphoreKeyPrefix
+func WithSemaphoreKeyPrefix(prefix string) SemaphoreOption {
+	return func(s *Semaphore) {
+		s.prefix = prefix
+	}
+}
+
+// WithSemaphoreClock 设置判断租约过期所用的时钟，默认使用系统时间
+// Redis缓存在服务端使用Redis的时间，不受该选项影响
+func WithSemaphoreClock(clock Clock) SemaphoreOption {
+	return func(s *Semaphore) {
+		if clock != nil {
+			s.clock = clock
+		}
+	}
+}
+
+// Semaphore 基于缓存的分布式计数信号量，限制多个实例上同时执行的任务数
+// 每次获取得到一个有效期为ttl的租约，持有者崩溃未释放时租约过期后名额自动回收；
+// 任务执行时间可能超过ttl时需要定期调用 SemaphoreLease.Refresh 续期。
+// Redis缓存在服务端用Lua脚本原子执行，实现 TxnCache 的缓存在事务中执行，
+// 其他缓存只在本实例内串行
+type Semaphore struct {
+	cache         gsr.Cacher
+	key           string
+	limit         int
+	ttl           time.Duration
+	retryInterval time.Duration
+	prefix        string
+	clock         Clock
+
+	mu sync.Mutex // 底层缓存不支持事务时保护状态的读-改-写
+}
+
+// NewSemaphore 创建名为name、最多limit个持有者、租约有效期为ttl的信号量
+func NewSemaphore(cache gsr.Cacher, name string, limit int, ttl time.Duration, opts ...SemaphoreOption) *Semaphore {
+	s := &Semaphore{
+		cache:         cache,
+		limit:         max(limit, 1),
+		ttl:           max(ttl, time.Millisecond),
+		retryInterval: 50 * time.Millisecond,
+		prefix:        DefaultSemaphoreKeyPrefix,
+		clock:         realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.key = s.prefix + name
+	return s
+}
+
+// SemaphoreLease 信号量的一个租约
+type SemaphoreLease struct {
+	sem   *Semaphore
+	token string
+}
+
+// Token 返回租约标识
+func (l *SemaphoreLease) Token() string {
+	return l.token
+}
+
+// Refresh 将租约的有效期重新设置为ttl，租约已过期或已释放时返回 ErrLeaseLost
+func (l *SemaphoreLease) Refresh(ctx context.Context) error {
+	return l.sem.refresh(ctx, l.token)
+}
+
+// Release 释放租约，租约已过期时忽略
+func (l *SemaphoreLease) Release(ctx context.Context) error {
+	return l.sem.release(ctx, l.token)
+}
+
+// TryAcquire 尝试获取租约，名额已满时返回nil与nil错误
+func (s *Semaphore) TryAcquire(ctx context.Context) (*SemaphoreLease, error) {
+	token, err := newLeaseToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var acquired bool
+	if b, ok := s.cache.(semaphoreBackend); ok {
+		acquired, err = b.acquireSemaphore(ctx, s.key, token, s.limit, s.ttl)
+	} else {
+		err = s.update(ctx, func(holders map[string]int64, now int64) bool {
+			// 事务冲突时会重新执行，每次都要重新判断
+			if acquired = len(holders) < s.limit; acquired {
+				holders[token] = now + s.ttl.Milliseconds()
+			}
+			return acquired
+		})
+	}
+	if err != nil || !acquired {
+		return nil, err
+	}
+	return &SemaphoreLease{sem: s, token: token}, nil
+}
+
+// Acquire 获取租约，名额已满时每隔重试间隔重试，直到获取成功或ctx结束
+func (s *Semaphore) Acquire(ctx context.Context) (*SemaphoreLease, error) {
+	ticker := time.NewTicker(s.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		lease, err := s.TryAcquire(ctx)
+		if err != nil || lease != nil {
+			return lease, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Count 返回当前未过期的持有者数
+func (s *Semaphore) Count(ctx context.Context) (int, error) {
+	if b, ok := s.cache.(semaphoreBackend); ok {
+		return b.countSemaphore(ctx, s.key)
+	}
+	state, err := s.load(ctx, s.cache.Get)
+	if err != nil {
+		return 0, err
+	}
+	now := s.clock.Now().UnixMilli()
+	n := 0
+	for _, expiresAt := range state.Holders {
+		if expiresAt > now {
+			n++
+		}
+	}
+	return n, nil
+}
+
+// refresh 续期租约
+func (s *Semaphore) refresh(ctx context.Context, token string) error {
+	var found bool
+	var err error
+	if b, ok := s.cache.(semaphoreBackend); ok {
+		found, err = b.refreshSemaphore(ctx, s.key, token, s.ttl)
+	} else {
+		err = s.update(ctx, func(holders map[string]int64, now int64) bool {
+			if _, found = holders[token]; found {
+				holders[token] = now + s.ttl.Milliseconds()
+			}
+			return found
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if !found {
+		return ErrLeaseLost
+	}
+	return nil
+}
+
+// release 释放租约
+func (s *Semaphore) release(ctx context.Context, token string) error {
+	if b, ok := s.cache.(semaphoreBackend); ok {
+		return b.releaseSemaphore(ctx, s.key, token)
+	}
+	return s.update(ctx, func(holders map[string]int64, now int64) bool {
+		if _, ok := holders[token]; !ok {
+			return false
+		}
+		delete(holders, token)
+		return true
+	})
+}
+
+// load 读取持有者，不存在时返回空状态
+func (s *Semaphore) load(ctx context.Context, get func(context.Context, string, any) error) (semaphoreState, error) {
+	var state semaphoreState
+	if err := get(ctx, s.key, &state); err != nil && !errors.Is(err, ErrKeyNotFound) {
+		return semaphoreState{}, err
+	}
+	if state.Holders == nil {
+		state.Holders = make(map[string]int64)
+	}
+	return state, nil
+}
+
+// update 移除过期的持有者后执行fn，fn 返回false且没有移除持有者时不写回
+// 键的有效期为最晚过期的租约的剩余时间，所有租约过期后键随之过期
+func (s *Semaphore) update(ctx context.Context, fn func(holders map[string]int64, now int64) bool) error {
+	return update(ctx, s.cache, &s.mu, s.key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+		state, err := s.load(ctx, get)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// 内存缓存可能返回与缓存共享的映射，修改前需要复制
+		now := s.clock.Now().UnixMilli()
+		holders := make(map[string]int64, len(state.Holders))
+		for token, expiresAt := range state.Holders {
+			if expiresAt > now {
+				holders[token] = expiresAt
+			}
+		}
+		expired := len(holders) != len(state.Holders)
+		if !fn(holders, now) && !expired {
+			return nil, 0, errNoUpdate
+		}
+
+		if len(holders) == 0 {
+			return nil, 0, nil
+		}
+		var latest int64
+		for _, expiresAt := range holders {
+			latest = max(latest, expiresAt)
+		}
+		return semaphoreState{Holders: holders}, time.Duration(latest-now) * time.Millisecond, nil
+	})
+}
+
+// newLeaseToken 生成随机的租约标识
+func newLeaseToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
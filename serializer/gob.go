@@ -5,6 +5,7 @@ import (
 	"encoding/gob"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 )
 
@@ -41,7 +42,9 @@ func (g *GobSerializer) Name() string {
 }
 
 // Encode 使用gob序列化缓存值
-func (g *GobSerializer) Encode(value interface{}) ([]byte, error) {
+func (g *GobSerializer) Encode(value interface{}) (_ []byte, err error) {
+	defer recoverPanic("gob encode error", &err)
+
 	// 特殊处理：检查是否为nil指针、nil切片、nil map
 	if value != nil {
 		valueReflect := reflect.ValueOf(value)
@@ -79,14 +82,26 @@ func (g *GobSerializer) Encode(value interface{}) ([]byte, error) {
 
 // Decode 使用gob反序列化
 func (g *GobSerializer) Decode(data []byte, obj any) error {
-	if obj == nil {
-		return fmt.Errorf("obj cannot be nil")
+	return g.decode(data, obj, false)
+}
+
+// DecodeStrict 使用gob反序列化，写入 T 时不能读取到 *T
+func (g *GobSerializer) DecodeStrict(data []byte, obj any) error {
+	return g.decode(data, obj, true)
+}
+
+// decode 反序列化，strict 为true时类型必须完全一致
+func (g *GobSerializer) decode(data []byte, obj any, strict bool) (err error) {
+	defer recoverPanic("gob decode error", &err)
+
+	// 检查obj必须是非nil指针
+	if _, err := checkTarget(obj); err != nil {
+		return err
 	}
 
-	// 检查obj必须是指针
-	objValue := reflect.ValueOf(obj)
-	if objValue.Kind() != reflect.Ptr {
-		return fmt.Errorf("obj must be a pointer")
+	// 检查消息长度，损坏的长度前缀会让gob按声明的长度分配内存
+	if err := checkGobFraming(data); err != nil {
+		return err
 	}
 
 	buf := bytes.NewBuffer(data)
@@ -95,17 +110,53 @@ func (g *GobSerializer) Decode(data []byte, obj any) error {
 	// 解码到临时变量
 	var value interface{}
 	if err := dec.Decode(&value); err != nil {
-		return fmt.Errorf("gob decode error: %w", err)
+		return fmt.Errorf("gob decode error: %w: %w", ErrCorruptData, err)
 	}
 
 	// 检查是否为nilValueMarker
 	if _, ok := value.(*nilValueMarker); ok {
 		// 这是一个nil值
-		return assignValue(obj, nil)
+		return assignValue(obj, nil, strict)
 	}
 
 	// 将value赋给obj
-	return assignValue(obj, value)
+	return assignValue(obj, value, strict)
+}
+
+// Recognize 判断data是否是gob编码的缓存值
+// 值的类型没有在当前进程中注册时仍视为gob编码的值
+func (g *GobSerializer) Recognize(data []byte) (ok bool) {
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+	if checkGobFraming(data) != nil {
+		return false
+	}
+	var value interface{}
+	err := gob.NewDecoder(bytes.NewReader(data)).Decode(&value)
+	return err == nil || strings.Contains(err.Error(), "not registered for interface")
+}
+
+// checkGobFraming 检查gob流中每个消息声明的长度不超过剩余的数据
+// gob会先按声明的长度（最大1GB）分配缓冲区再读取，截断或损坏的数据因此可能导致大量内存分配
+func checkGobFraming(data []byte) error {
+	if len(data) == 0 {
+		return fmt.Errorf("gob decode error: %w: empty data", ErrCorruptData)
+	}
+	for len(data) > 0 {
+		n, size, ok := decodeGobUint(data)
+		if !ok {
+			return fmt.Errorf("gob decode error: %w: invalid message length", ErrCorruptData)
+		}
+		data = data[size:]
+		if n > uint64(len(data)) {
+			return fmt.Errorf("gob decode error: %w: message of %d bytes truncated to %d", ErrCorruptData, n, len(data))
+		}
+		data = data[n:]
+	}
+	return nil
 }
 
 // registerTypeIfNeeded 安全地注册类型
@@ -133,11 +184,12 @@ func registerTypeIfNeeded(value interface{}) {
 	gob.Register(value)
 }
 
-// assignValue 使用反射将值赋给目标对象
-func assignValue(obj any, value interface{}) error {
-	objValue := reflect.ValueOf(obj)
-	objElem := objValue.Elem()
-
+// assignValue 使用反射将值赋给目标对象，strict 为true时不调整指针层级
+func assignValue(obj any, value interface{}, strict bool) error {
+	objElem, err := checkTarget(obj)
+	if err != nil {
+		return err
+	}
 	if !objElem.CanSet() {
 		return fmt.Errorf("obj cannot be set")
 	}
@@ -154,7 +206,7 @@ func assignValue(obj any, value interface{}) error {
 			objElem.Set(reflect.Zero(objElem.Type()))
 			return nil
 		}
-		return fmt.Errorf("cannot assign nil to non-pointer type %s", objElem.Type())
+		return fmt.Errorf("%w: cannot assign nil to non-pointer type %s", ErrTypeMismatch, objElem.Type())
 	}
 
 	valueReflect := reflect.ValueOf(value)
@@ -162,9 +214,17 @@ func assignValue(obj any, value interface{}) error {
 		return fmt.Errorf("invalid value")
 	}
 
+	// gob 不保留指针层级：写入 *T 时解码得到 T，这里按目标类型重新取地址
+	if !strict && objElem.Kind() == reflect.Ptr && objElem.Type().Elem() == valueReflect.Type() {
+		ptr := reflect.New(valueReflect.Type())
+		ptr.Elem().Set(valueReflect)
+		objElem.Set(ptr)
+		return nil
+	}
+
 	// 类型必须匹配
 	if objElem.Type() != valueReflect.Type() {
-		return fmt.Errorf("type mismatch: expected %s, got %s", objElem.Type(), valueReflect.Type())
+		return fmt.Errorf("%w: expected %s, got %s", ErrTypeMismatch, objElem.Type(), valueReflect.Type())
 	}
 
 	objElem.Set(valueReflect)
@@ -1,6 +1,7 @@
 package serializer
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"reflect"
@@ -29,8 +30,16 @@ type jsonWrapper struct {
 	Value    interface{} `json:"value,omitempty"`
 }
 
+// jsonRawWrapper 解码时使用的 jsonWrapper，保留value的原始数据
+type jsonRawWrapper struct {
+	IsNil bool            `json:"is_nil"`
+	Value json.RawMessage `json:"value"`
+}
+
 // Encode 使用JSON序列化缓存值
-func (j *JsonSerializer) Encode(value interface{}) ([]byte, error) {
+func (j *JsonSerializer) Encode(value interface{}) (_ []byte, err error) {
+	defer recoverPanic("json encode error", &err)
+
 	// 检查是否为nil
 	wrapper := jsonWrapper{
 		IsNil: value == nil,
@@ -59,24 +68,32 @@ func (j *JsonSerializer) Encode(value interface{}) ([]byte, error) {
 
 // Decode 使用JSON反序列化
 func (j *JsonSerializer) Decode(data []byte, obj any) error {
-	if obj == nil {
-		return fmt.Errorf("obj cannot be nil")
-	}
+	return j.decode(data, obj, false)
+}
+
+// DecodeStrict 使用JSON反序列化，值中存在obj没有的字段或类型不符时返回 ErrTypeMismatch，
+// 出错时obj保持不变
+func (j *JsonSerializer) DecodeStrict(data []byte, obj any) error {
+	return j.decode(data, obj, true)
+}
+
+// decode 反序列化，strict 为true时不允许未知字段
+func (j *JsonSerializer) decode(data []byte, obj any, strict bool) (err error) {
+	defer recoverPanic("json decode error", &err)
 
-	// 检查obj必须是指针
-	objValue := reflect.ValueOf(obj)
-	if objValue.Kind() != reflect.Ptr {
-		return fmt.Errorf("obj must be a pointer")
+	// 检查obj必须是非nil指针
+	objElem, err := checkTarget(obj)
+	if err != nil {
+		return err
 	}
 
-	var wrapper jsonWrapper
+	var wrapper jsonRawWrapper
 	if err := json.Unmarshal(data, &wrapper); err != nil {
-		return fmt.Errorf("json decode error: %w", err)
+		return fmt.Errorf("json decode error: %w: %w", ErrCorruptData, err)
 	}
 
 	// 如果是nil值
 	if wrapper.IsNil {
-		objElem := objValue.Elem()
 		if !objElem.CanSet() {
 			return fmt.Errorf("obj cannot be set")
 		}
@@ -91,20 +108,46 @@ func (j *JsonSerializer) Decode(data []byte, obj any) error {
 			objElem.Set(reflect.Zero(objElem.Type()))
 			return nil
 		}
-		return fmt.Errorf("cannot assign nil to non-pointer type %s", objElem.Type())
+		return fmt.Errorf("%w: cannot assign nil to non-pointer type %s", ErrTypeMismatch, objElem.Type())
 	}
 
-	// 非nil值，直接反序列化到obj
-	// 将wrapper.Value重新序列化然后反序列化到obj
-	// 这样可以处理类型转换
-	valueData, err := json.Marshal(wrapper.Value)
-	if err != nil {
-		return fmt.Errorf("json re-encode error: %w", err)
+	// 非nil值一定带有value，缺少时（如数据为 null 或 {}）说明不是由 Encode 写入的
+	if wrapper.Value == nil {
+		return fmt.Errorf("json decode error: %w: missing value", ErrCorruptData)
 	}
 
-	if err := json.Unmarshal(valueData, obj); err != nil {
+	if strict {
+		return decodeJSONStrict(wrapper.Value, objElem)
+	}
+
+	// 非nil值，直接反序列化到obj
+	if err := json.Unmarshal(wrapper.Value, obj); err != nil {
 		return fmt.Errorf("json decode to obj error: %w", err)
 	}
 
 	return nil
 }
+
+// Recognize 判断data是否是JSON编码的缓存值，即由 Encode 写入的包装对象
+func (j *JsonSerializer) Recognize(data []byte) bool {
+	var wrapper jsonRawWrapper
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return false
+	}
+	return wrapper.IsNil || wrapper.Value != nil
+}
+
+// decodeJSONStrict 不允许未知字段地解码到临时对象，成功后再写入objElem
+func decodeJSONStrict(data []byte, objElem reflect.Value) error {
+	if !objElem.CanSet() {
+		return fmt.Errorf("obj cannot be set")
+	}
+	tmp := reflect.New(objElem.Type())
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(tmp.Interface()); err != nil {
+		return fmt.Errorf("json decode to obj error: %w: %w", ErrTypeMismatch, err)
+	}
+	objElem.Set(tmp.Elem())
+	return nil
+}
@@ -0,0 +1,155 @@
+package serializer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDecodeLimit 待解码的数据超过了解码限制
+var ErrDecodeLimit = errors.New("decode limit exceeded")
+
+// Limits 解码限制，字段为0表示不限制
+// 用于防止损坏或恶意构造的缓存值在 Get 时分配大量内存
+type Limits struct {
+	MaxSize       int // 待解码数据的最大字节数
+	MaxDepth      int // JSON的最大嵌套深度，只对 JsonSerializer 生效
+	MaxGobMessage int // 单个gob消息的最大字节数，只对 GobSerializer 生效
+}
+
+// LimitedSerializer 解码前检查限制的序列化器
+// 与 NewPipeline 一起使用时应作为最内层的序列化器，并使用 GzipLimit 限制解压后的大小：
+//
+//	NewPipeline(WithLimits(NewGob(), limits), GzipLimit(gzip.BestSpeed, limits.MaxSize))
+type LimitedSerializer struct {
+	base   Serializer
+	limits Limits
+}
+
+// WithLimits 为base增加解码限制
+func WithLimits(base Serializer, limits Limits) *LimitedSerializer {
+	return &LimitedSerializer{base: base, limits: limits}
+}
+
+// Name 返回被包装的序列化器名称，解码限制不改变数据格式
+func (l *LimitedSerializer) Name() string {
+	return l.base.Name()
+}
+
+// Encode 使用被包装的序列化器序列化，编码不受限制
+func (l *LimitedSerializer) Encode(value interface{}) ([]byte, error) {
+	return l.base.Encode(value)
+}
+
+// Decode 检查限制后反序列化
+func (l *LimitedSerializer) Decode(data []byte, obj any) error {
+	if err := l.check(data); err != nil {
+		return err
+	}
+	return l.base.Decode(data, obj)
+}
+
+// DecodeStrict 检查限制后严格反序列化
+func (l *LimitedSerializer) DecodeStrict(data []byte, obj any) error {
+	if err := l.check(data); err != nil {
+		return err
+	}
+	return DecodeStrict(l.base, data, obj)
+}
+
+// Recognize 判断data是否由被包装的序列化器写入，超过限制的数据无法读取，返回false
+func (l *LimitedSerializer) Recognize(data []byte) bool {
+	if l.check(data) != nil {
+		return false
+	}
+	return Recognize(l.base, data)
+}
+
+// check 检查数据是否超过限制
+func (l *LimitedSerializer) check(data []byte) error {
+	if l.limits.MaxSize > 0 && len(data) > l.limits.MaxSize {
+		return fmt.Errorf("%w: size %d, limit %d", ErrDecodeLimit, len(data), l.limits.MaxSize)
+	}
+
+	switch l.base.(type) {
+	case *JsonSerializer:
+		if l.limits.MaxDepth > 0 {
+			// 外层的 jsonWrapper 占用一层
+			if depth := jsonDepth(data); depth > l.limits.MaxDepth+1 {
+				return fmt.Errorf("%w: json depth %d, limit %d", ErrDecodeLimit, depth-1, l.limits.MaxDepth)
+			}
+		}
+	case *GobSerializer:
+		if l.limits.MaxGobMessage > 0 {
+			return checkGobMessages(data, l.limits.MaxGobMessage)
+		}
+	}
+	return nil
+}
+
+// jsonDepth 返回JSON数据的最大嵌套深度，忽略字符串中的括号
+func jsonDepth(data []byte) int {
+	depth, maxDepth := 0, 0
+	inString, escaped := false, false
+	for _, b := range data {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case b == '\\':
+				escaped = true
+			case b == '"':
+				inString = false
+			}
+			continue
+		}
+		switch b {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			maxDepth = max(maxDepth, depth)
+		case '}', ']':
+			depth--
+		}
+	}
+	return maxDepth
+}
+
+// checkGobMessages 检查gob流中每个消息声明的长度
+// gob流由若干 (长度, 消息) 组成，长度使用gob的无符号整数编码：
+// 小于128时为单个字节，否则第一个字节为后续大端字节数的相反数
+func checkGobMessages(data []byte, limit int) error {
+	for len(data) > 0 {
+		n, size, ok := decodeGobUint(data)
+		if !ok {
+			// 格式错误交给gob报告
+			return nil
+		}
+		if n > uint64(limit) {
+			return fmt.Errorf("%w: gob message %d bytes, limit %d", ErrDecodeLimit, n, limit)
+		}
+		data = data[size:]
+		if n > uint64(len(data)) {
+			return nil
+		}
+		data = data[n:]
+	}
+	return nil
+}
+
+// decodeGobUint 解码gob无符号整数，返回值与占用的字节数
+func decodeGobUint(data []byte) (uint64, int, bool) {
+	b := data[0]
+	if b <= 0x7f {
+		return uint64(b), 1, true
+	}
+	n := -int(int8(b))
+	if n > 8 || len(data) < n+1 {
+		return 0, 0, false
+	}
+	var x uint64
+	for _, c := range data[1 : n+1] {
+		x = x<<8 | uint64(c)
+	}
+	return x, n + 1, true
+}
@@ -0,0 +1,99 @@
+package serializer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// SizedEncoder 编码时同时返回变换前大小的序列化器，用于统计压缩率
+type SizedEncoder interface {
+	// EncodeSized 序列化value，raw 为应用变换之前的字节数
+	EncodeSized(value interface{}) (data []byte, raw int, err error)
+}
+
+// PipelineSerializer 在序列化器之后依次应用变换的序列化器
+// 编码时按顺序执行每个变换的 Forward，解码时按相反顺序执行 Reverse，
+// 例如 NewPipeline(NewGob(), Gzip(gzip.BestSpeed), aesTransform) 先压缩再加密
+type PipelineSerializer struct {
+	base       Serializer
+	transforms []Transform
+	name       string
+}
+
+// NewPipeline 创建由base与transforms组成的序列化器
+func NewPipeline(base Serializer, transforms ...Transform) *PipelineSerializer {
+	names := []string{base.Name()}
+	for _, t := range transforms {
+		names = append(names, t.Name())
+	}
+	return &PipelineSerializer{
+		base:       base,
+		transforms: transforms,
+		name:       strings.Join(names, "+"),
+	}
+}
+
+// Name 返回序列化器名称，由各部分名称以+连接，如 "gob+gzip+aes-gcm"
+func (p *PipelineSerializer) Name() string {
+	return p.name
+}
+
+// Encode 序列化后依次应用变换
+func (p *PipelineSerializer) Encode(value interface{}) ([]byte, error) {
+	data, _, err := p.EncodeSized(value)
+	return data, err
+}
+
+// EncodeSized 序列化后依次应用变换，同时返回变换前的字节数
+func (p *PipelineSerializer) EncodeSized(value interface{}) ([]byte, int, error) {
+	data, err := p.base.Encode(value)
+	if err != nil {
+		return nil, 0, err
+	}
+	raw := len(data)
+	for _, t := range p.transforms {
+		if data, err = t.Forward(data); err != nil {
+			return nil, 0, fmt.Errorf("%s encode error: %w", t.Name(), err)
+		}
+	}
+	return data, raw, nil
+}
+
+// Decode 按相反顺序还原变换后反序列化
+func (p *PipelineSerializer) Decode(data []byte, obj any) error {
+	data, err := p.reverse(data)
+	if err != nil {
+		return err
+	}
+	return p.base.Decode(data, obj)
+}
+
+// DecodeStrict 按相反顺序还原变换后严格反序列化
+func (p *PipelineSerializer) DecodeStrict(data []byte, obj any) error {
+	data, err := p.reverse(data)
+	if err != nil {
+		return err
+	}
+	return DecodeStrict(p.base, data, obj)
+}
+
+// Recognize 还原变换后判断是否由base写入，任何变换无法还原时返回false
+func (p *PipelineSerializer) Recognize(data []byte) bool {
+	data, err := p.reverse(data)
+	if err != nil {
+		return false
+	}
+	return Recognize(p.base, data)
+}
+
+// reverse 按相反顺序还原变换
+func (p *PipelineSerializer) reverse(data []byte) ([]byte, error) {
+	var err error
+	for i := len(p.transforms) - 1; i >= 0; i-- {
+		t := p.transforms[i]
+		if data, err = t.Reverse(data); err != nil {
+			return nil, fmt.Errorf("%s decode error: %w", t.Name(), err)
+		}
+	}
+	return data, nil
+}
@@ -1,5 +1,20 @@
 package serializer
 
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// ErrInvalidTarget Decode 的目标obj不是可写入的非nil指针
+var ErrInvalidTarget = errors.New("obj must be a non-nil pointer")
+
+// ErrCorruptData 待解码的数据已损坏或被截断
+var ErrCorruptData = errors.New("corrupt data")
+
+// ErrTypeMismatch 待解码的值与obj的类型不一致
+var ErrTypeMismatch = errors.New("type mismatch")
+
 // Serializer 序列化器接口
 // 定义了缓存值的编码和解码方法
 type Serializer interface {
@@ -13,3 +28,57 @@ type Serializer interface {
 	// Name 返回序列化器的名称
 	Name() string
 }
+
+// StrictDecoder 支持严格解码的序列化器
+// 严格解码不做任何兼容处理，值与obj的类型不完全一致时返回 ErrTypeMismatch
+type StrictDecoder interface {
+	DecodeStrict(data []byte, obj any) error
+}
+
+// DecodeStrict 使用s严格解码，s未实现 StrictDecoder 时退化为 Decode
+func DecodeStrict(s Serializer, data []byte, obj any) error {
+	if sd, ok := s.(StrictDecoder); ok {
+		return sd.DecodeStrict(data, obj)
+	}
+	return s.Decode(data, obj)
+}
+
+// Recognizer 能够在不知道值类型的情况下判断数据格式的序列化器
+type Recognizer interface {
+	// Recognize 判断data是否是该序列化器的编码结果
+	Recognize(data []byte) bool
+}
+
+// Recognize 判断data是否由s写入
+// s未实现 Recognizer 时解码到 any，只有数据损坏、签名或头部不一致时视为不是由s写入
+func Recognize(s Serializer, data []byte) bool {
+	if r, ok := s.(Recognizer); ok {
+		return r.Recognize(data)
+	}
+	var value any
+	err := s.Decode(data, &value)
+	return !errors.Is(err, ErrCorruptData) && !errors.Is(err, ErrInvalidSignature) && !errors.Is(err, ErrHeaderMismatch)
+}
+
+// checkTarget 检查Decode的目标obj，返回obj指向的值
+func checkTarget(obj any) (reflect.Value, error) {
+	objValue := reflect.ValueOf(obj)
+	if !objValue.IsValid() {
+		return reflect.Value{}, fmt.Errorf("%w, got nil", ErrInvalidTarget)
+	}
+	if objValue.Kind() != reflect.Ptr {
+		return reflect.Value{}, fmt.Errorf("%w, got %s", ErrInvalidTarget, objValue.Type())
+	}
+	if objValue.IsNil() {
+		return reflect.Value{}, fmt.Errorf("%w, got nil %s", ErrInvalidTarget, objValue.Type())
+	}
+	return objValue.Elem(), nil
+}
+
+// recoverPanic 把编解码过程中的panic转换为错误，需要在带有命名返回值err的函数中defer调用
+// 标准库的编码器在遇到少见的类型（如自定义的 GobEncoder 实现）时仍可能panic
+func recoverPanic(op string, err *error) {
+	if r := recover(); r != nil {
+		*err = fmt.Errorf("%s: panic: %v", op, r)
+	}
+}
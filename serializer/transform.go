@@ -0,0 +1,187 @@
+package serializer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidSignature 签名校验失败，数据被篡改或使用了不同的密钥
+	ErrInvalidSignature = errors.New("invalid signature")
+	// ErrHeaderMismatch 数据头部与 Annotate 设置的头部不一致
+	ErrHeaderMismatch = errors.New("header mismatch")
+)
+
+// Transform 对序列化结果进行的可逆变换
+// Forward 在编码时调用，Reverse 在解码时调用，Reverse(Forward(data)) 必须返回data
+type Transform interface {
+	// Name 返回变换的名称
+	Name() string
+	// Forward 编码时变换数据
+	Forward(data []byte) ([]byte, error)
+	// Reverse 解码时还原数据
+	Reverse(data []byte) ([]byte, error)
+}
+
+// transformFunc 由函数组成的变换
+type transformFunc struct {
+	name    string
+	forward func([]byte) ([]byte, error)
+	reverse func([]byte) ([]byte, error)
+}
+
+func (t *transformFunc) Name() string                        { return t.name }
+func (t *transformFunc) Forward(data []byte) ([]byte, error) { return t.forward(data) }
+func (t *transformFunc) Reverse(data []byte) ([]byte, error) { return t.reverse(data) }
+
+// NewTransform 使用一对函数创建变换
+func NewTransform(name string, forward, reverse func([]byte) ([]byte, error)) Transform {
+	return &transformFunc{name: name, forward: forward, reverse: reverse}
+}
+
+// Gzip 返回使用gzip压缩的变换，level 为 compress/gzip 的压缩级别
+// 解压后的大小不受限制，读取不可信的数据时应使用 GzipLimit
+func Gzip(level int) Transform {
+	return GzipLimit(level, 0)
+}
+
+// GzipLimit 返回使用gzip压缩的变换，解压后超过maxSize字节时返回 ErrDecodeLimit，
+// 用于防止解压炸弹；maxSize <= 0 表示不限制
+func GzipLimit(level int, maxSize int) Transform {
+	return NewTransform("gzip",
+		func(data []byte) ([]byte, error) {
+			var buf bytes.Buffer
+			w, err := gzip.NewWriterLevel(&buf, level)
+			if err != nil {
+				return nil, err
+			}
+			if _, err := w.Write(data); err != nil {
+				return nil, err
+			}
+			if err := w.Close(); err != nil {
+				return nil, err
+			}
+			return buf.Bytes(), nil
+		},
+		func(data []byte) ([]byte, error) {
+			r, err := gzip.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("gzip: %w", err)
+			}
+			defer r.Close()
+			if maxSize <= 0 {
+				return io.ReadAll(r)
+			}
+
+			plain, err := io.ReadAll(io.LimitReader(r, int64(maxSize)+1))
+			if err != nil {
+				return nil, err
+			}
+			if len(plain) > maxSize {
+				return nil, fmt.Errorf("%w: decompressed size exceeds %d", ErrDecodeLimit, maxSize)
+			}
+			return plain, nil
+		})
+}
+
+// AESGCM 返回使用AES-GCM加密的变换，key 长度必须为16、24或32字节
+// 每次加密使用随机nonce，nonce 保存在密文之前
+func AESGCM(key []byte) (Transform, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTransform("aes-gcm",
+		func(data []byte) ([]byte, error) {
+			nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(data)+aead.Overhead())
+			if _, err := rand.Read(nonce); err != nil {
+				return nil, err
+			}
+			return aead.Seal(nonce, nonce, data, nil), nil
+		},
+		func(data []byte) ([]byte, error) {
+			if len(data) < aead.NonceSize() {
+				return nil, errors.New("aes-gcm: ciphertext too short")
+			}
+			nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+			plain, err := aead.Open(nil, nonce, ciphertext, nil)
+			if err != nil {
+				return nil, fmt.Errorf("aes-gcm: %w", err)
+			}
+			return plain, nil
+		}), nil
+}
+
+// HMACSHA256 返回使用HMAC-SHA256签名的变换，签名追加在数据之后
+// 解码时签名不匹配返回 ErrInvalidSignature
+func HMACSHA256(key []byte) Transform {
+	return KeyedHMACSHA256(key)
+}
+
+// KeyedHMACSHA256 返回签名同时覆盖context的HMAC-SHA256变换，签名追加在数据之后
+// context 的每一部分以长度开头写入签名，用于把数据绑定到缓存键等位置：
+// 以不同的context解码时签名不匹配，返回 ErrInvalidSignature
+func KeyedHMACSHA256(key []byte, context ...string) Transform {
+	var prefix []byte
+	for _, part := range context {
+		prefix = binary.AppendUvarint(prefix, uint64(len(part)))
+		prefix = append(prefix, part...)
+	}
+	sign := func(data []byte) []byte {
+		mac := hmac.New(sha256.New, key)
+		mac.Write(prefix)
+		mac.Write(data)
+		return mac.Sum(nil)
+	}
+
+	return NewTransform("hmac-sha256",
+		func(data []byte) ([]byte, error) {
+			out := make([]byte, 0, len(data)+sha256.Size)
+			out = append(out, data...)
+			return append(out, sign(data)...), nil
+		},
+		func(data []byte) ([]byte, error) {
+			if len(data) < sha256.Size {
+				return nil, ErrInvalidSignature
+			}
+			payload, sig := data[:len(data)-sha256.Size], data[len(data)-sha256.Size:]
+			if !hmac.Equal(sig, sign(payload)) {
+				return nil, ErrInvalidSignature
+			}
+			return payload, nil
+		})
+}
+
+// Annotate 返回在数据之前添加固定头部的变换，用于标记数据格式或版本
+// 解码时头部不一致返回 ErrHeaderMismatch，可据此识别旧格式的数据
+func Annotate(header []byte) Transform {
+	header = bytes.Clone(header)
+
+	return NewTransform("annotate",
+		func(data []byte) ([]byte, error) {
+			out := make([]byte, 0, len(header)+len(data))
+			out = append(out, header...)
+			return append(out, data...), nil
+		},
+		func(data []byte) ([]byte, error) {
+			payload, ok := bytes.CutPrefix(data, header)
+			if !ok {
+				return nil, ErrHeaderMismatch
+			}
+			return payload, nil
+		})
+}
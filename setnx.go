@@ -0,0 +1,13 @@
+package go_cache
+
+import (
+	"context"
+	"time"
+)
+
+// SetNXCache 支持仅在键不存在时写入的缓存，可用于分布式锁与去重
+type SetNXCache interface {
+	// SetNX 键不存在（或已过期）时写入并返回true，键已存在时不做修改并返回false；
+	// ttl <= 0 表示不过期
+	SetNX(ctx context.Context, key string, value any, ttl time.Duration) (bool, error)
+}
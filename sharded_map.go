@@ -0,0 +1,177 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"maps"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// ShardedMapOption 分片映射选项
+type ShardedMapOption func(*ShardedMap)
+
+// WithShardedMapTTL 设置分片的过期时间，每次写入分片时刷新，默认不过期
+func WithShardedMapTTL(d time.Duration) ShardedMapOption {
+	return func(m *ShardedMap) {
+		m.ttl = d
+	}
+}
+
+// WithShardedMapSerializer 设置字段值的序列化器
+func WithShardedMapSerializer(s serializer.Serializer) ShardedMapOption {
+	return func(m *ShardedMap) {
+		m.serializer = s
+	}
+}
+
+// ShardedMap 把一个逻辑上的大映射按字段哈希拆分到多个缓存键中保存
+// 读写单个字段时只需要读写字段所在的分片，适用于按用户保存特性开关等字段很多的映射。
+// 分片键为 "{name}:shard:{i}"，每个分片保存字段名到序列化数据的映射；
+// 底层缓存实现 TxnCache 时分片的读-改-写在事务中执行，否则只在本实例内串行，
+// 多个进程同时写入同一个分片可能丢失更新
+type ShardedMap struct {
+	cache      gsr.Cacher
+	name       string
+	shards     int
+	ttl        time.Duration
+	serializer serializer.Serializer
+
+	mu sync.Mutex // 底层缓存不支持事务时保护分片的读-改-写
+}
+
+// NewShardedMap 创建名为name、分为shards个分片的映射，shards < 1 时使用1个分片
+// 分片数决定字段所在的键，已有数据时不能修改；默认使用gob序列化字段值
+func NewShardedMap(cache gsr.Cacher, name string, shards int, opts ...ShardedMapOption) *ShardedMap {
+	m := &ShardedMap{
+		cache:      cache,
+		name:       name,
+		shards:     max(shards, 1),
+		serializer: cache_value.GetDefaultSerializer(), // 默认使用gob
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// ShardKey 返回第i个分片的缓存键
+func (m *ShardedMap) ShardKey(i int) string {
+	return m.name + ":shard:" + strconv.Itoa(i)
+}
+
+// FieldKey 返回字段所在分片的缓存键
+func (m *ShardedMap) FieldKey(field string) string {
+	return m.ShardKey(int(crc32.ChecksumIEEE([]byte(field)) % uint32(m.shards)))
+}
+
+// GetField 读取字段的值到obj，字段不存在时返回 ErrKeyNotFound
+func (m *ShardedMap) GetField(ctx context.Context, field string, obj any) error {
+	shard, err := m.load(ctx, m.cache.Get, m.FieldKey(field))
+	if err != nil {
+		return err
+	}
+	data, ok := shard[field]
+	if !ok {
+		return ErrKeyNotFound
+	}
+	if err := m.serializer.Decode(data, obj); err != nil {
+		return serializationError(fmt.Errorf("sharded map: decode field %s: %w", field, err))
+	}
+	return nil
+}
+
+// SetField 写入字段的值，只读写字段所在的分片
+func (m *ShardedMap) SetField(ctx context.Context, field string, value any) error {
+	data, err := m.serializer.Encode(value)
+	if err != nil {
+		return serializationError(fmt.Errorf("sharded map: encode field %s: %w", field, err))
+	}
+	return m.update(ctx, m.FieldKey(field), func(shard map[string][]byte) bool {
+		shard[field] = data
+		return true
+	})
+}
+
+// DelField 删除字段，字段不存在时忽略
+func (m *ShardedMap) DelField(ctx context.Context, field string) error {
+	return m.update(ctx, m.FieldKey(field), func(shard map[string][]byte) bool {
+		if _, ok := shard[field]; !ok {
+			return false
+		}
+		delete(shard, field)
+		return true
+	})
+}
+
+// Fields 返回所有字段名，按名称排序，需要读取全部分片
+func (m *ShardedMap) Fields(ctx context.Context) ([]string, error) {
+	var fields []string
+	for i := 0; i < m.shards; i++ {
+		shard, err := m.load(ctx, m.cache.Get, m.ShardKey(i))
+		if err != nil {
+			return nil, err
+		}
+		for field := range shard {
+			fields = append(fields, field)
+		}
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// Clear 删除全部分片
+func (m *ShardedMap) Clear(ctx context.Context) error {
+	var errs []error
+	for i := 0; i < m.shards; i++ {
+		if err := m.cache.Del(ctx, m.ShardKey(i)); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// load 读取分片，分片不存在时返回空映射
+func (m *ShardedMap) load(ctx context.Context, get func(context.Context, string, any) error, key string) (map[string][]byte, error) {
+	var shard map[string][]byte
+	if err := get(ctx, key, &shard); err != nil {
+		if errors.Is(err, ErrKeyNotFound) {
+			return map[string][]byte{}, nil
+		}
+		return nil, err
+	}
+	if shard == nil {
+		return map[string][]byte{}, nil
+	}
+	// 内存缓存可能返回与缓存共享的映射，修改前需要复制
+	return maps.Clone(shard), nil
+}
+
+// update 对分片执行读-改-写，fn 返回false表示分片没有变化，不需要写回
+// 分片变为空时删除分片键
+func (m *ShardedMap) update(ctx context.Context, key string, fn func(shard map[string][]byte) bool) error {
+	return update(ctx, m.cache, &m.mu, key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+		shard, err := m.load(ctx, get, key)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !fn(shard) {
+			return nil, 0, errNoUpdate
+		}
+		if len(shard) == 0 {
+			return nil, 0, nil
+		}
+		return shard, m.ttl, nil
+	})
+}
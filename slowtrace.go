@@ -0,0 +1,164 @@
+package go_cache
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// SlowOp 一次慢操作或超大写入的记录
+type SlowOp struct {
+	Op       Operation
+	Key      string
+	Duration time.Duration
+	Size     int64  // 写入值的近似大小，只对写入有效
+	Err      error  // 操作返回的错误
+	Stack    []byte // 发起调用的goroutine的调用栈
+}
+
+// SlowOpHook 接收慢操作记录的回调
+type SlowOpHook func(ctx context.Context, op SlowOp)
+
+// SlowOpLogger 返回使用logger以Warn级别记录慢操作的回调
+func SlowOpLogger(logger *slog.Logger) SlowOpHook {
+	return func(ctx context.Context, op SlowOp) {
+		logger.WarnContext(ctx, "slow cache operation",
+			slog.String("op", string(op.Op)),
+			slog.String("key", op.Key),
+			slog.Duration("duration", op.Duration),
+			slog.Int64("size", op.Size),
+			slog.Any("error", op.Err),
+			slog.String("stack", string(op.Stack)),
+		)
+	}
+}
+
+// SlowTraceOption 慢操作追踪选项
+type SlowTraceOption func(*SlowTracer)
+
+// WithSlowTraceMaxSize 设置写入值大小的上限（字节），超过时同样记录调用栈，n <= 0 表示不检查
+func WithSlowTraceMaxSize(n int64) SlowTraceOption {
+	return func(s *SlowTracer) {
+		s.maxSize = n
+	}
+}
+
+// WithSlowTraceSizer 设置计算写入值大小的方法，默认使用 EstimateSize
+func WithSlowTraceSizer(sizer Sizer) SlowTraceOption {
+	return func(s *SlowTracer) {
+		s.sizer = sizer
+	}
+}
+
+// SlowTracer 慢操作追踪缓存
+// 操作耗时超过阈值（或写入的值超过大小上限）时捕获发起调用的goroutine的调用栈并交给回调，
+// 用于定位发起慢操作或超大写入的代码路径。GetSet 的耗时包含回调函数的执行时间
+type SlowTracer struct {
+	cache     gsr.Cacher
+	threshold time.Duration
+	hook      SlowOpHook
+	maxSize   int64
+	sizer     Sizer
+}
+
+// NewSlowTracer 创建慢操作追踪缓存，耗时超过threshold的操作交给hook
+func NewSlowTracer(cache gsr.Cacher, threshold time.Duration, hook SlowOpHook, opts ...SlowTraceOption) *SlowTracer {
+	s := &SlowTracer{
+		cache:     cache,
+		threshold: threshold,
+		hook:      hook,
+		sizer:     EstimateSize,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// trace 检查一次操作，size < 0 表示不检查大小
+func (s *SlowTracer) trace(ctx context.Context, op Operation, key string, start time.Time, size int64, err error) {
+	d := time.Since(start)
+	slow := s.threshold > 0 && d >= s.threshold
+	oversized := s.maxSize > 0 && size > s.maxSize
+	if !slow && !oversized {
+		return
+	}
+	s.hook(ctx, SlowOp{Op: op, Key: key, Duration: d, Size: max(size, 0), Err: err, Stack: debug.Stack()})
+}
+
+// valueSize 需要检查大小时返回值的大小，否则返回-1
+func (s *SlowTracer) valueSize(value any) int64 {
+	if s.maxSize <= 0 {
+		return -1
+	}
+	return s.sizer(value)
+}
+
+func (s *SlowTracer) Exists(ctx context.Context, key string) bool {
+	start := time.Now()
+	exists := s.cache.Exists(ctx, key)
+	s.trace(ctx, OpExists, key, start, -1, nil)
+	return exists
+}
+
+func (s *SlowTracer) Get(ctx context.Context, key string, obj any) error {
+	start := time.Now()
+	err := s.cache.Get(ctx, key, obj)
+	s.trace(ctx, OpGet, key, start, -1, err)
+	return err
+}
+
+func (s *SlowTracer) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	start := time.Now()
+	err := s.cache.Set(ctx, key, value, ttl)
+	s.trace(ctx, OpSet, key, start, s.valueSize(value), err)
+	return err
+}
+
+// SetWithPriority 按优先级写入，底层缓存不支持优先级时退化为 Set
+func (s *SlowTracer) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	start := time.Now()
+	err := setWithPriority(ctx, s.cache, key, value, ttl, priority)
+	s.trace(ctx, OpSet, key, start, s.valueSize(value), err)
+	return err
+}
+
+func (s *SlowTracer) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	start := time.Now()
+	err := s.cache.GetSet(ctx, key, ttl, obj, fun)
+	size := int64(-1)
+	if err == nil && s.maxSize > 0 {
+		if value, perr := pointee(obj); perr == nil {
+			size = s.sizer(value)
+		}
+	}
+	s.trace(ctx, OpGetSet, key, start, size, err)
+	return err
+}
+
+func (s *SlowTracer) Del(ctx context.Context, key string) error {
+	start := time.Now()
+	err := s.cache.Del(ctx, key)
+	s.trace(ctx, OpDel, key, start, -1, err)
+	return err
+}
+
+func (s *SlowTracer) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	start := time.Now()
+	err := s.cache.ExpiresAt(ctx, key, expiresAt)
+	s.trace(ctx, OpExpire, key, start, -1, err)
+	return err
+}
+
+func (s *SlowTracer) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	start := time.Now()
+	err := s.cache.ExpiresIn(ctx, key, ttl)
+	s.trace(ctx, OpExpire, key, start, -1, err)
+	return err
+}
@@ -0,0 +1,369 @@
+package go_cache
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/go-cache/cache_value"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// SQLDialect SQL方言，决定占位符与upsert语法
+type SQLDialect int
+
+const (
+	// DialectPostgres PostgreSQL
+	DialectPostgres SQLDialect = iota
+	// DialectMySQL MySQL / MariaDB
+	DialectMySQL
+	// DialectSQLite SQLite
+	DialectSQLite
+)
+
+// String 返回方言名称
+func (d SQLDialect) String() string {
+	switch d {
+	case DialectPostgres:
+		return "postgres"
+	case DialectMySQL:
+		return "mysql"
+	case DialectSQLite:
+		return "sqlite"
+	}
+	return "unknown"
+}
+
+// DefaultSQLTable 默认的缓存表名
+const DefaultSQLTable = "go_cache"
+
+// SQL 基于数据库表的缓存
+// 表结构为 (cache_key, value, expires_at)，expires_at 为Unix毫秒，NULL表示不过期；
+// 过期条目在读取时被忽略，并由后台定期清理。可通过 CreateTable 创建表
+type SQL struct {
+	db         *sql.DB
+	dialect    SQLDialect
+	table      string
+	serializer serializer.Serializer
+	stats      *StatsCollector
+	clock      Clock
+	loader     loaderConfig
+
+	cleanupInterval time.Duration
+	stop            chan struct{}
+	stopOnce        sync.Once
+	wg              sync.WaitGroup
+
+	// 按方言生成的语句
+	getSQL, existsSQL, setSQL, delSQL, expireSQL, cleanupSQL string
+}
+
+// SQLOption SQL缓存选项
+type SQLOption func(*SQL)
+
+// WithSQLDialect 设置SQL方言，默认 DialectPostgres
+func WithSQLDialect(d SQLDialect) SQLOption {
+	return func(s *SQL) {
+		s.dialect = d
+	}
+}
+
+// WithSQLTable 设置缓存表名，默认 DefaultSQLTable
+// 表名会直接拼接到语句中，不能来自不可信的输入
+func WithSQLTable(table string) SQLOption {
+	return func(s *SQL) {
+		if table != "" {
+			s.table = table
+		}
+	}
+}
+
+// WithSQLSerializer 设置SQL缓存的序列化器
+func WithSQLSerializer(ser serializer.Serializer) SQLOption {
+	return func(s *SQL) {
+		s.serializer = ser
+	}
+}
+
+// WithSQLStats 设置SQL缓存的统计收集器
+// 可用于多个缓存实例共享同一个收集器
+func WithSQLStats(stats *StatsCollector) SQLOption {
+	return func(s *SQL) {
+		s.stats = stats
+	}
+}
+
+// WithSQLClock 设置判断过期所用的时钟，默认使用系统时间
+func WithSQLClock(clock Clock) SQLOption {
+	return func(s *SQL) {
+		if clock != nil {
+			s.clock = clock
+		}
+	}
+}
+
+// WithSQLCleanupInterval 设置清理过期条目的间隔，默认1分钟，d <= 0 表示不自动清理
+func WithSQLCleanupInterval(d time.Duration) SQLOption {
+	return func(s *SQL) {
+		s.cleanupInterval = d
+	}
+}
+
+// WithSQLLoaderTimeout 设置 GetSet 等待回调函数的超时时间，超时返回 ErrLoaderTimeout，d <= 0 表示不限制
+func WithSQLLoaderTimeout(d time.Duration) SQLOption {
+	return func(s *SQL) {
+		s.loader.timeout = d
+	}
+}
+
+// WithSQLLoaderHook 设置 GetSet 回调函数失败（返回错误、panic或超时）时的回调
+func WithSQLLoaderHook(hook LoaderFailureHook) SQLOption {
+	return func(s *SQL) {
+		s.loader.onFailure = hook
+	}
+}
+
+// NewSQL 创建SQL缓存实例
+// 默认使用gob序列化器；cleanupInterval > 0 时启动后台清理，使用完毕后需要调用 Close
+func NewSQL(db *sql.DB, opts ...SQLOption) *SQL {
+	s := &SQL{
+		db:              db,
+		dialect:         DialectPostgres,
+		table:           DefaultSQLTable,
+		serializer:      cache_value.GetDefaultSerializer(), // 默认使用gob
+		stats:           NewStatsCollector(),
+		clock:           realClock{},
+		cleanupInterval: time.Minute,
+		stop:            make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	s.buildStatements()
+
+	if s.cleanupInterval > 0 {
+		s.wg.Add(1)
+		go s.cleanupLoop()
+	}
+
+	return s
+}
+
+// buildStatements 按方言生成语句
+func (c *SQL) buildStatements() {
+	notExpired := func(n int) string {
+		return "(expires_at IS NULL OR expires_at > " + c.placeholder(n) + ")"
+	}
+
+	c.getSQL = "SELECT value FROM " + c.table + " WHERE cache_key = " + c.placeholder(1) + " AND " + notExpired(2)
+	c.existsSQL = "SELECT 1 FROM " + c.table + " WHERE cache_key = " + c.placeholder(1) + " AND " + notExpired(2)
+	c.delSQL = "DELETE FROM " + c.table + " WHERE cache_key = " + c.placeholder(1)
+	c.expireSQL = "UPDATE " + c.table + " SET expires_at = " + c.placeholder(1) +
+		" WHERE cache_key = " + c.placeholder(2) + " AND " + notExpired(3)
+	c.cleanupSQL = "DELETE FROM " + c.table + " WHERE expires_at IS NOT NULL AND expires_at <= " + c.placeholder(1)
+
+	insert := "INSERT INTO " + c.table + " (cache_key, value, expires_at) VALUES (" +
+		c.placeholder(1) + ", " + c.placeholder(2) + ", " + c.placeholder(3) + ")"
+	if c.dialect == DialectMySQL {
+		c.setSQL = insert + " ON DUPLICATE KEY UPDATE value = VALUES(value), expires_at = VALUES(expires_at)"
+	} else {
+		c.setSQL = insert + " ON CONFLICT (cache_key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at"
+	}
+}
+
+// placeholder 返回第n个参数的占位符
+// MySQL 与 SQLite 的 ? 按出现顺序绑定，语句中参数须按编号顺序出现
+func (c *SQL) placeholder(n int) string {
+	if c.dialect == DialectPostgres {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// CreateTable 创建缓存表与过期时间索引（已存在时跳过）
+func (c *SQL) CreateTable(ctx context.Context) error {
+	blob := "BLOB"
+	switch c.dialect {
+	case DialectPostgres:
+		blob = "BYTEA"
+	case DialectMySQL:
+		blob = "LONGBLOB"
+	}
+
+	stmts := []string{
+		"CREATE TABLE IF NOT EXISTS " + c.table + " (" +
+			"cache_key VARCHAR(255) NOT NULL PRIMARY KEY, " +
+			"value " + blob + " NOT NULL, " +
+			"expires_at BIGINT NULL)",
+	}
+	index := c.table + "_expires_at_idx"
+	if c.dialect == DialectMySQL {
+		// MySQL 不支持 CREATE INDEX IF NOT EXISTS，在建表语句中声明索引
+		stmts[0] = strings.TrimSuffix(stmts[0], ")") + ", INDEX " + index + " (expires_at))"
+	} else {
+		stmts = append(stmts, "CREATE INDEX IF NOT EXISTS "+index+" ON "+c.table+" (expires_at)")
+	}
+
+	for _, stmt := range stmts {
+		if _, err := c.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("sql cache: create table: %w", err)
+		}
+	}
+	return nil
+}
+
+// Close 停止后台清理，不会关闭数据库连接
+func (c *SQL) Close() error {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+	})
+	c.wg.Wait()
+	return nil
+}
+
+// Stats 返回缓存统计快照
+func (c *SQL) Stats() Stats {
+	return c.stats.Snapshot()
+}
+
+func (c *SQL) Exists(ctx context.Context, key string) bool {
+	var one int
+	err := c.db.QueryRowContext(ctx, c.existsSQL, key, c.now()).Scan(&one)
+	return err == nil
+}
+
+func (c *SQL) Get(ctx context.Context, key string, obj any) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	var data []byte
+	err := c.db.QueryRowContext(ctx, c.getSQL, key, c.now()).Scan(&data)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.stats.RecordMiss(key)
+			return ErrKeyNotFound
+		}
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+
+	if err := c.serializer.Decode(data, obj); err != nil {
+		c.stats.RecordError(key)
+		return serializationError(err)
+	}
+
+	c.stats.RecordHit(key, len(data))
+	return nil
+}
+
+func (c *SQL) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	encode, rawSize, err := encodeSized(c.serializer, value)
+	if err != nil {
+		return err
+	}
+
+	var expiresAt sql.NullInt64
+	if ttl > 0 {
+		expiresAt = sql.NullInt64{Int64: c.clock.Now().Add(ttl).UnixMilli(), Valid: true}
+	}
+
+	if _, err := c.db.ExecContext(ctx, c.setSQL, key, encode, expiresAt); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordSet(key, len(encode))
+	c.stats.RecordPayload(key, len(encode), rawSize)
+	return nil
+}
+
+// SetWithPriority 按优先级写入缓存
+// 数据库没有容量淘汰，优先级不会产生影响
+func (c *SQL) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	return c.Set(ctx, key, value, ttl)
+}
+
+func (c *SQL) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	return c.loader.getSet(ctx, c, key, ttl, obj, fun)
+}
+
+// loaderSettings 返回 GetSet 调用回调函数的方式，包装器沿用该设置
+func (c *SQL) loaderSettings() loaderConfig {
+	return c.loader
+}
+
+func (c *SQL) Del(ctx context.Context, key string) error {
+	if _, err := c.db.ExecContext(ctx, c.delSQL, key); err != nil {
+		c.stats.RecordError(key)
+		return classifyError(err)
+	}
+	c.stats.RecordDelete(key)
+	return nil
+}
+
+// ExpiresAt 设置过期时间，键不存在或已过期时返回 ErrKeyNotFound
+// MySQL 默认按实际修改的行数返回结果，过期时间不变时会误报 ErrKeyNotFound，
+// 需要在DSN中设置 clientFoundRows=true
+func (c *SQL) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	res, err := c.db.ExecContext(ctx, c.expireSQL, expiresAt.UnixMilli(), key, c.now())
+	if err != nil {
+		return classifyError(err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return classifyError(err)
+	}
+	if n == 0 {
+		return ErrKeyNotFound
+	}
+	return nil
+}
+
+// ExpiresIn 设置剩余有效期，键不存在或已过期时返回 ErrKeyNotFound
+func (c *SQL) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return c.ExpiresAt(ctx, key, c.clock.Now().Add(ttl))
+}
+
+// DeleteExpired 删除所有过期条目，返回删除的条目数
+func (c *SQL) DeleteExpired(ctx context.Context) (int64, error) {
+	res, err := c.db.ExecContext(ctx, c.cleanupSQL, c.now())
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// cleanupLoop 定期清理过期条目
+func (c *SQL) cleanupLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.cleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			if _, err := c.DeleteExpired(context.Background()); err != nil {
+				c.stats.RecordError("")
+			}
+		}
+	}
+}
+
+// now 返回当前时间的Unix毫秒
+func (c *SQL) now() int64 {
+	return c.clock.Now().UnixMilli()
+}
@@ -0,0 +1,266 @@
+package go_cache
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	// DefaultNamespaceDelimiters 默认的命名空间分隔符
+	DefaultNamespaceDelimiters = ":"
+
+	// OtherNamespace 命名空间数量超过上限后，新出现的命名空间统一归入此命名空间
+	OtherNamespace = "_other"
+)
+
+// StatsCounters 一组缓存统计计数
+//
+// BytesRead/BytesWritten 的含义取决于后端：Redis 统计序列化后的负载长度，
+// Memory 统计 Sizer 计算的条目大小（默认为 EstimateSize 的反射估算值，
+// 配置 SerializerSizer 后为序列化长度）。不同后端共享同一个收集器时，
+// 只有 Memory 使用 SerializerSizer 才能保证字节数单位一致
+type StatsCounters struct {
+	Hits         uint64 // 命中次数
+	Misses       uint64 // 未命中次数
+	Sets         uint64 // 写入次数
+	Deletes      uint64 // 删除次数
+	Errors       uint64 // 出错次数（不含未命中）
+	BytesRead    uint64 // 命中时读取的字节数
+	BytesWritten uint64 // 写入的字节数
+
+	Payload PayloadStats // 序列化负载大小统计
+}
+
+// HitRate 返回命中率，没有任何读取时返回0
+func (c StatsCounters) HitRate() float64 {
+	total := c.Hits + c.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(c.Hits) / float64(total)
+}
+
+// Stats 缓存统计快照
+// 包含全局计数以及按命名空间（键前缀）划分的计数
+type Stats struct {
+	StatsCounters
+
+	// Namespaces 按命名空间划分的计数
+	// 不包含分隔符的键归入空字符串命名空间
+	Namespaces map[string]StatsCounters
+
+	// 过期与淘汰统计，用于区分自然过期与容量压力导致的淘汰
+	// Memory 统计本实例的条目，Redis 通过 StatsContext 读取服务端 INFO stats（整个实例）
+	Expired uint64 // 因过期被清理的条目数
+	Evicted uint64 // 因容量压力被淘汰的条目数
+
+	// 以下字段仅由内存缓存提供
+	Entries        uint64 // 当前条目数（含尚未被清理的过期条目）
+	MemoryBytes    uint64 // 当前条目的近似内存占用
+	MaxMemoryBytes uint64 // 内存占用上限，0表示不限制
+}
+
+// StatsOption 统计收集器选项
+type StatsOption func(*StatsCollector)
+
+// WithNamespaceDelimiters 设置用于划分命名空间的分隔符
+// 键中第一个出现的任一分隔符之前的部分即为命名空间，例如 "user:123" 的命名空间为 "user"
+func WithNamespaceDelimiters(delimiters ...string) StatsOption {
+	return func(s *StatsCollector) {
+		s.delimiters = s.delimiters[:0]
+		for _, d := range delimiters {
+			if d != "" {
+				s.delimiters = append(s.delimiters, d)
+			}
+		}
+	}
+}
+
+// WithMaxNamespaces 设置最多跟踪的命名空间数量，用于防止高基数键前缀导致内存膨胀
+// 超出上限的命名空间归入 OtherNamespace，n <= 0 表示不限制
+func WithMaxNamespaces(n int) StatsOption {
+	return func(s *StatsCollector) {
+		s.maxNamespaces = n
+	}
+}
+
+// StatsCollector 缓存统计收集器
+// 并发安全，可在多个缓存实例之间共享
+type StatsCollector struct {
+	delimiters    []string
+	maxNamespaces int
+
+	global statsCounters
+
+	mu         sync.RWMutex
+	namespaces map[string]*statsCounters
+}
+
+// statsCounters 原子计数器
+type statsCounters struct {
+	hits         atomic.Uint64
+	misses       atomic.Uint64
+	sets         atomic.Uint64
+	deletes      atomic.Uint64
+	errors       atomic.Uint64
+	bytesRead    atomic.Uint64
+	bytesWritten atomic.Uint64
+	payload      payloadCounters
+}
+
+func (c *statsCounters) snapshot() StatsCounters {
+	return StatsCounters{
+		Hits:         c.hits.Load(),
+		Misses:       c.misses.Load(),
+		Sets:         c.sets.Load(),
+		Deletes:      c.deletes.Load(),
+		Errors:       c.errors.Load(),
+		BytesRead:    c.bytesRead.Load(),
+		BytesWritten: c.bytesWritten.Load(),
+		Payload:      c.payload.snapshot(),
+	}
+}
+
+// NewStatsCollector 创建统计收集器
+// 默认使用 ":" 作为命名空间分隔符，最多跟踪1000个命名空间
+func NewStatsCollector(opts ...StatsOption) *StatsCollector {
+	s := &StatsCollector{
+		delimiters:    []string{DefaultNamespaceDelimiters},
+		maxNamespaces: 1000,
+		namespaces:    make(map[string]*statsCounters),
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	return s
+}
+
+// Namespace 返回键所属的命名空间
+func (s *StatsCollector) Namespace(key string) string {
+	end := -1
+	for _, d := range s.delimiters {
+		if i := strings.Index(key, d); i >= 0 && (end < 0 || i < end) {
+			end = i
+		}
+	}
+	if end < 0 {
+		return ""
+	}
+	return key[:end]
+}
+
+// RecordHit 记录一次命中
+func (s *StatsCollector) RecordHit(key string, bytes int) {
+	s.record(key, func(c *statsCounters) {
+		c.hits.Add(1)
+		if bytes > 0 {
+			c.bytesRead.Add(uint64(bytes))
+		}
+	})
+}
+
+// RecordMiss 记录一次未命中
+func (s *StatsCollector) RecordMiss(key string) {
+	s.record(key, func(c *statsCounters) {
+		c.misses.Add(1)
+	})
+}
+
+// RecordSet 记录一次写入
+func (s *StatsCollector) RecordSet(key string, bytes int) {
+	s.record(key, func(c *statsCounters) {
+		c.sets.Add(1)
+		if bytes > 0 {
+			c.bytesWritten.Add(uint64(bytes))
+		}
+	})
+}
+
+// RecordDelete 记录一次删除
+func (s *StatsCollector) RecordDelete(key string) {
+	s.record(key, func(c *statsCounters) {
+		c.deletes.Add(1)
+	})
+}
+
+// RecordError 记录一次错误
+func (s *StatsCollector) RecordError(key string) {
+	s.record(key, func(c *statsCounters) {
+		c.errors.Add(1)
+	})
+}
+
+// Snapshot 返回当前统计快照
+func (s *StatsCollector) Snapshot() Stats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stats := Stats{
+		StatsCounters: s.global.snapshot(),
+		Namespaces:    make(map[string]StatsCounters, len(s.namespaces)),
+	}
+	for name, c := range s.namespaces {
+		stats.Namespaces[name] = c.snapshot()
+	}
+	return stats
+}
+
+// Reset 清空所有统计
+func (s *StatsCollector) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.global = statsCounters{}
+	s.namespaces = make(map[string]*statsCounters)
+}
+
+// record 同时更新全局计数与命名空间计数
+// 两者在同一读锁内更新，保证 Reset 与 Snapshot 看到一致的计数
+func (s *StatsCollector) record(key string, fn func(c *statsCounters)) {
+	name := s.Namespace(key)
+
+	s.mu.RLock()
+	c := s.lookupNamespaceLocked(name)
+	for c == nil {
+		// 命名空间尚未创建，升级为写锁创建后重新获取读锁
+		s.mu.RUnlock()
+		s.ensureNamespace(name)
+		s.mu.RLock()
+		c = s.lookupNamespaceLocked(name)
+	}
+	defer s.mu.RUnlock()
+
+	fn(&s.global)
+	fn(c)
+}
+
+// lookupNamespaceLocked 查找命名空间计数器，超出数量上限的命名空间使用 OtherNamespace
+func (s *StatsCollector) lookupNamespaceLocked(name string) *statsCounters {
+	if c, ok := s.namespaces[name]; ok {
+		return c
+	}
+	if s.maxNamespaces > 0 && len(s.namespaces) >= s.maxNamespaces {
+		return s.namespaces[OtherNamespace]
+	}
+	return nil
+}
+
+// ensureNamespace 创建命名空间计数器，超出数量上限时创建 OtherNamespace
+func (s *StatsCollector) ensureNamespace(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.namespaces[name]; ok {
+		return
+	}
+	if s.maxNamespaces > 0 && len(s.namespaces) >= s.maxNamespaces {
+		name = OtherNamespace
+		if _, ok := s.namespaces[name]; ok {
+			return
+		}
+	}
+	s.namespaces[name] = &statsCounters{}
+}
@@ -0,0 +1,100 @@
+package go_cache
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// payloadSampleSize 每个命名空间保留的最近负载大小样本数，用于计算分位数
+const payloadSampleSize = 512
+
+// PayloadStats 序列化负载大小统计
+// 由序列化值的后端（Redis、SQL、Embedded、DynamoDB）在写入时记录，
+// 用于发现某次代码变更后开始缓存膨胀的对象
+type PayloadStats struct {
+	Count   uint64  // 记录的写入次数
+	AvgSize float64 // 平均负载大小（字节，写入后端的大小）
+	MaxSize int     // 最大负载大小
+	// 负载大小分位数，基于最近的样本
+	P50, P95, P99 int
+	// CompressionRatio 写入后端的总字节数与变换（压缩等）前总字节数之比，
+	// 小于1表示压缩生效；序列化器没有变换时为1，没有记录时为0
+	CompressionRatio float64
+}
+
+// payloadCounters 负载大小计数
+type payloadCounters struct {
+	mu           sync.Mutex
+	count        uint64
+	encodedTotal uint64
+	rawTotal     uint64
+	max          int
+	samples      []int
+	next         int
+}
+
+// record 记录一次负载大小
+func (p *payloadCounters) record(encoded, raw int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.count++
+	p.encodedTotal += uint64(encoded)
+	p.rawTotal += uint64(raw)
+	p.max = max(p.max, encoded)
+	if len(p.samples) < payloadSampleSize {
+		p.samples = append(p.samples, encoded)
+		return
+	}
+	p.samples[p.next] = encoded
+	p.next = (p.next + 1) % payloadSampleSize
+}
+
+// snapshot 返回负载大小统计
+func (p *payloadCounters) snapshot() PayloadStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.count == 0 {
+		return PayloadStats{}
+	}
+
+	sorted := slices.Clone(p.samples)
+	slices.Sort(sorted)
+	percentile := func(q float64) int {
+		return sorted[int(q*float64(len(sorted)-1))]
+	}
+
+	stats := PayloadStats{
+		Count:   p.count,
+		AvgSize: float64(p.encodedTotal) / float64(p.count),
+		MaxSize: p.max,
+		P50:     percentile(0.50),
+		P95:     percentile(0.95),
+		P99:     percentile(0.99),
+	}
+	if p.rawTotal > 0 {
+		stats.CompressionRatio = float64(p.encodedTotal) / float64(p.rawTotal)
+	}
+	return stats
+}
+
+// RecordPayload 记录一次写入的负载大小
+// encoded 为写入后端的字节数，raw 为变换（压缩、加密等）前的序列化字节数
+func (s *StatsCollector) RecordPayload(key string, encoded, raw int) {
+	s.record(key, func(c *statsCounters) {
+		c.payload.record(encoded, raw)
+	})
+}
+
+// encodeSized 使用s序列化value，同时返回变换前的字节数，错误归类为 ErrSerialization
+func encodeSized(s serializer.Serializer, value any) ([]byte, int, error) {
+	if se, ok := s.(serializer.SizedEncoder); ok {
+		data, raw, err := se.EncodeSized(value)
+		return data, raw, serializationError(err)
+	}
+	data, err := s.Encode(value)
+	return data, len(data), serializationError(err)
+}
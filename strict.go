@@ -0,0 +1,30 @@
+package go_cache
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// ErrInvalidTTL 严格模式下写入或设置过期时间时TTL不是正数，Dedup 的窗口与 Presence 的心跳有效期不是正数时也返回该错误
+var ErrInvalidTTL = errors.New("invalid ttl")
+
+// ErrTypeMismatch 缓存中的值与读取目标obj的类型不一致
+var ErrTypeMismatch = serializer.ErrTypeMismatch
+
+// 严格模式把以下宽松行为变为错误，用于在开发阶段尽早发现误用：
+//   - Set / GetSet 的 ttl <= 0（内存缓存视为永不过期，Redis视为持久化）返回 ErrInvalidTTL，
+//     需要永不过期时先写入再调用 Persist
+//   - ExpiresIn 的 ttl <= 0 返回 ErrInvalidTTL
+//   - 解码时不做兼容处理（如写入 T 读取到 *T、JSON忽略未知字段），类型不一致时返回 ErrTypeMismatch
+//   - 设置了 SchemaRegistry 时，写入与 ExpiresIn 不符合键的模式返回 ErrSchemaViolation
+
+// checkTTL 严格模式下检查ttl是否为正数
+func checkTTL(strict bool, ttl time.Duration) error {
+	if strict && ttl <= 0 {
+		return fmt.Errorf("%w: %v, strict mode requires a positive ttl", ErrInvalidTTL, ttl)
+	}
+	return nil
+}
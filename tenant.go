@@ -0,0 +1,126 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+const (
+	// tenantKeyPrefix 租户键前缀，完整前缀为 "tenant:{id}:"
+	tenantKeyPrefix = "tenant:"
+
+	// tenantDelimiter 租户ID与业务键之间的分隔符
+	tenantDelimiter = ":"
+)
+
+var (
+	// ErrNotSupported 底层缓存不支持该操作
+	ErrNotSupported = errors.New("operation not supported by cache")
+
+	// ErrInvalidTenant 租户ID不合法
+	ErrInvalidTenant = errors.New("invalid tenant id")
+)
+
+// TenantCache 租户隔离缓存
+// 所有操作都只作用于该租户的命名空间，键前缀由本类型统一添加，调用方无法越过租户边界
+type TenantCache struct {
+	cache  gsr.Cacher
+	tenant string
+	prefix string
+}
+
+// ForTenant 返回只作用于指定租户命名空间的缓存
+// 租户ID不能为空，也不能包含分隔符":"，否则一个租户的前缀可能覆盖另一个租户
+func ForTenant(cache gsr.Cacher, tenantID string) (*TenantCache, error) {
+	if tenantID == "" || strings.Contains(tenantID, tenantDelimiter) {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTenant, tenantID)
+	}
+	return &TenantCache{
+		cache:  cache,
+		tenant: tenantID,
+		prefix: tenantKeyPrefix + tenantID + tenantDelimiter,
+	}, nil
+}
+
+// Tenant 返回租户ID
+func (t *TenantCache) Tenant() string {
+	return t.tenant
+}
+
+// Key 返回业务键在底层缓存中的实际键
+func (t *TenantCache) Key(key string) string {
+	return t.prefix + key
+}
+
+func (t *TenantCache) Exists(ctx context.Context, key string) bool {
+	return t.cache.Exists(ctx, t.Key(key))
+}
+
+func (t *TenantCache) Get(ctx context.Context, key string, obj any) error {
+	return t.cache.Get(ctx, t.Key(key), obj)
+}
+
+func (t *TenantCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return t.cache.Set(ctx, t.Key(key), value, ttl)
+}
+
+func (t *TenantCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	// 回调收到的是业务键，而不是带租户前缀的实际键
+	// 使用内层缓存传入的obj，设置了超时时该obj为临时对象
+	return t.cache.GetSet(ctx, t.Key(key), ttl, obj, func(_ string, obj any) error {
+		return fun(key, obj)
+	})
+}
+
+func (t *TenantCache) Del(ctx context.Context, key string) error {
+	return t.cache.Del(ctx, t.Key(key))
+}
+
+func (t *TenantCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return t.cache.ExpiresAt(ctx, t.Key(key), expiresAt)
+}
+
+func (t *TenantCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return t.cache.ExpiresIn(ctx, t.Key(key), ttl)
+}
+
+// Keys 返回该租户下匹配模式的业务键（不含租户前缀）
+// 底层缓存需实现 PatternCache
+func (t *TenantCache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	pc, ok := t.cache.(PatternCache)
+	if !ok {
+		return nil, ErrNotSupported
+	}
+	// 达到 ScanOptions 的上限时同样返回已列出的键
+	keys, err := pc.Keys(ctx, EscapePattern(t.prefix)+pattern)
+	for i, key := range keys {
+		keys[i] = strings.TrimPrefix(key, t.prefix)
+	}
+	return keys, err
+}
+
+// DelByPattern 删除该租户下匹配模式的键，返回删除的数量
+// 底层缓存需实现 PatternCache
+func (t *TenantCache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	pc, ok := t.cache.(PatternCache)
+	if !ok {
+		return 0, ErrNotSupported
+	}
+	return pc.DelByPattern(ctx, EscapePattern(t.prefix)+pattern)
+}
+
+// Clear 删除该租户的所有键，返回删除的数量
+// 底层缓存需实现 PatternCache
+func (t *TenantCache) Clear(ctx context.Context) (int64, error) {
+	return t.DelByPattern(ctx, "*")
+}
+
+// Capabilities 返回支持的能力，底层缓存实现 PatternCache 时才支持按模式操作
+func (t *TenantCache) Capabilities() []Capability {
+	return wrapperCapabilities(t.cache, nil, CapabilityPattern)
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestTinyLFUEstimate 测试TinyLFU的频率估计与衰减
+func TestTinyLFUEstimate(t *testing.T) {
+	lfu := go_cache.NewTinyLFU(100, go_cache.WithTinyLFUSampleSize(50))
+
+	if n := lfu.Estimate("a"); n != 0 {
+		t.Errorf("未访问的键 Estimate() = %d", n)
+	}
+	lfu.Record("a")
+	if n := lfu.Estimate("a"); n != 1 {
+		t.Errorf("访问一次后 Estimate() = %d，期望为1（只记入门卫）", n)
+	}
+	for i := 0; i < 20; i++ {
+		lfu.Record("a")
+	}
+	if n := lfu.Estimate("a"); n != 16 {
+		t.Errorf("计数上限为15，Estimate() = %d", n)
+	}
+	if !lfu.Admit("a", "b") || lfu.Admit("b", "a") {
+		t.Error("访问频率高的键应被接纳")
+	}
+
+	// 记录次数达到衰减周期后计数减半，门卫被清空
+	for i := 0; i < 50; i++ {
+		lfu.Record(fmt.Sprint("other", i%5))
+	}
+	if n := lfu.Estimate("a"); n < 3 || n > 8 {
+		t.Errorf("衰减后 Estimate() = %d，期望约为原来的一半", n)
+	}
+}
+
+// TestMemoryAdmissionScanResistance 测试接纳策略保护热点数据不被一次性扫描冲掉
+func TestMemoryAdmissionScanResistance(t *testing.T) {
+	ctx := context.Background()
+	rec := &evictionRecorder{}
+	cache := go_cache.NewMemory(time.Minute, time.Minute,
+		go_cache.WithMemorySizer(func(value any) int64 { return 10 }),
+		go_cache.WithMemoryMaxBytes(30),
+		go_cache.WithEvictionPolicy(go_cache.NewLRUPolicy),
+		go_cache.WithMemoryAdmission(go_cache.NewTinyLFU(100)),
+		go_cache.WithEvictionCallback(rec.record),
+	)
+
+	var s string
+	for _, key := range []string{"hot1", "hot2", "hot3"} {
+		_ = cache.Set(ctx, key, "v", 0)
+		for i := 0; i < 3; i++ {
+			_ = cache.Get(ctx, key, &s)
+		}
+	}
+	for i := 0; i < 10; i++ {
+		_ = cache.Set(ctx, fmt.Sprint("scan", i), "v", 0)
+	}
+
+	if got := remainingKeys(t, cache); fmt.Sprint(got) != "[hot1 hot2 hot3]" {
+		t.Errorf("扫描后剩余的键为%v，热点数据应保留", got)
+	}
+	events := rec.take()
+	if len(events) != 10 || events[0] != "scan0=v:rejected" {
+		t.Errorf("扫描的键应以 rejected 移除，实际为%v", events)
+	}
+
+	// 多次读取未命中的键积累频率后被接纳
+	for i := 0; i < 5; i++ {
+		_ = cache.Get(ctx, "popular", &s)
+	}
+	_ = cache.Set(ctx, "popular", "v", 0)
+	if !cache.Exists(ctx, "popular") {
+		t.Error("访问频率高的新键应被接纳")
+	}
+	if events := rec.take(); len(events) != 1 || events[0] != "hot1=v:capacity" {
+		t.Errorf("接纳新键时应淘汰LRU选出的键，实际为%v", events)
+	}
+
+	// 覆盖已有的键不经过接纳策略
+	_ = cache.Set(ctx, "hot2", "v2", 0)
+	if err := cache.Get(ctx, "hot2", &s); err != nil || s != "v2" {
+		t.Errorf("覆盖已有的键应成功，Get() = %q, %v", s, err)
+	}
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestAliasedCache 测试读取按别名回退与使用统计
+func TestAliasedCache(t *testing.T) {
+	ctx := context.Background()
+	memory := go_cache.NewMemory(time.Minute, time.Minute)
+	var hooked []string
+	cache := go_cache.NewAliased(memory, []go_cache.KeyAlias{{Old: "user:", New: "user:v2:"}},
+		go_cache.WithAliasHook(func(key, alias string) { hooked = append(hooked, key+"->"+alias) }))
+
+	// 迁移前写入的旧键
+	if err := memory.Set(ctx, "user:1", "old", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var s string
+	if err := cache.Get(ctx, "user:v2:1", &s); err != nil || s != "old" {
+		t.Errorf("Get() = %q, %v，新键未命中时应回退到旧键", s, err)
+	}
+
+	// 新版本写入的新键
+	if err := cache.Set(ctx, "user:v2:2", "new", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if memory.Exists(ctx, "user:2") {
+		t.Error("写入只应作用于给出的键")
+	}
+	if !cache.Exists(ctx, "user:2") {
+		t.Error("Exists() 应按别名回退")
+	}
+
+	// 写入新键时删除旧键中的过时值，旧版本的读取回退到新值
+	if err := memory.Set(ctx, "user:5", "stale", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Set(ctx, "user:v2:5", "fresh", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if memory.Exists(ctx, "user:5") {
+		t.Error("Set() 应删除别名中的过时值")
+	}
+	if err := cache.Get(ctx, "user:5", &s); err != nil || s != "fresh" {
+		t.Errorf("Get() = %q, %v，旧键应回退到新写入的值", s, err)
+	}
+
+	// 两个键都不存在
+	if err := cache.Get(ctx, "user:v2:3", &s); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("Get() error = %v, want ErrKeyNotFound", err)
+	}
+
+	usage := cache.Usage()
+	if len(usage) != 1 || usage[0].Fallbacks != 3 || usage[0].LastUsed.IsZero() {
+		t.Errorf("Usage() = %+v，期望3次回退", usage)
+	}
+	if len(hooked) != 3 || hooked[0] != "user:v2:1->user:1" {
+		t.Errorf("回调 = %v", hooked)
+	}
+
+	// Del 同时删除别名，之后不会回退到旧值
+	if err := cache.Del(ctx, "user:v2:1"); err != nil {
+		t.Fatal(err)
+	}
+	if memory.Exists(ctx, "user:1") || cache.Exists(ctx, "user:v2:1") {
+		t.Error("Del() 应同时删除别名")
+	}
+
+	// GetSet 命中别名时不调用回调函数
+	if err := memory.Set(ctx, "user:4", "old", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	err := cache.GetSet(ctx, "user:v2:4", time.Minute, &s, func(key string, obj any) error {
+		t.Error("命中别名时不应调用回调函数")
+		return nil
+	})
+	if err != nil || s != "old" {
+		t.Errorf("GetSet() = %q, %v", s, err)
+	}
+}
+
+// TestAliasedCacheDirectionAndExpiry 测试别名的方向与失效时间
+func TestAliasedCacheDirectionAndExpiry(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	memory := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewAliased(memory, []go_cache.KeyAlias{{
+		Old: "cfg:", New: "config:", Direction: go_cache.AliasToOld, Until: clock.Now().Add(time.Hour),
+	}}, go_cache.WithAliasClock(clock))
+
+	if err := memory.Set(ctx, "cfg:a", "old", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := memory.Set(ctx, "config:b", "new", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	var s string
+	if err := cache.Get(ctx, "config:a", &s); err != nil {
+		t.Errorf("AliasToOld 时读取新键应回退到旧键，Get() error = %v", err)
+	}
+	if err := cache.Get(ctx, "cfg:b", &s); err == nil {
+		t.Error("AliasToOld 时读取旧键不应回退到新键")
+	}
+
+	clock.Advance(time.Hour)
+	if err := cache.Get(ctx, "config:a", &s); err == nil {
+		t.Error("别名失效后不应回退")
+	}
+
+	cache.AddAlias(go_cache.KeyAlias{Old: "cfg:", New: "config:"})
+	cache.RemoveAlias("cfg:")
+	if len(cache.Usage()) != 0 {
+		t.Errorf("RemoveAlias() 后 Usage() = %+v", cache.Usage())
+	}
+}
@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestArtifactSpecID 测试产物标识与参数顺序无关
+func TestArtifactSpecID(t *testing.T) {
+	a := go_cache.ArtifactSpec{Source: "img/1.png", Params: map[string]string{"w": "200", "fmt": "webp"}}
+	b := go_cache.ArtifactSpec{Source: "img/1.png", Params: map[string]string{"fmt": "webp", "w": "200"}}
+	c := go_cache.ArtifactSpec{Source: "img/1.png", Params: map[string]string{"w": "2", "fmt": "00webp"}}
+	if a.ID() != b.ID() {
+		t.Error("参数相同的产物应有相同的标识")
+	}
+	if a.ID() == c.ID() {
+		t.Error("参数不同的产物应有不同的标识")
+	}
+}
+
+// TestArtifactCache 测试按大小保存到缓存或对象存储
+func TestArtifactCache(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	blobs := go_cache.NewMemoryBlobStore()
+	artifacts := go_cache.NewArtifactCache(r.Cache, blobs, go_cache.WithArtifactInlineLimit(1024))
+
+	small := go_cache.ArtifactSpec{Source: "img/1.png", Params: map[string]string{"w": "32"}}
+	large := go_cache.ArtifactSpec{Source: "img/1.png", Params: map[string]string{"w": "2048"}}
+
+	info, err := artifacts.Put(ctx, small, "image/png", strings.NewReader("tiny"), -1, time.Minute)
+	if err != nil || info.Offloaded || info.Size != 4 {
+		t.Fatalf("Put() = %+v, %v", info, err)
+	}
+	content := bytes.Repeat([]byte("p"), 10000)
+	info, err = artifacts.Put(ctx, large, "image/png", bytes.NewReader(content), -1, time.Minute)
+	if err != nil || !info.Offloaded || info.Size != int64(len(content)) {
+		t.Fatalf("Put() = %+v, %v", info, err)
+	}
+	if blobs.Len() != 1 {
+		t.Errorf("只有超过上限的内容应写入对象存储，Len() = %d", blobs.Len())
+	}
+
+	for spec, want := range map[*go_cache.ArtifactSpec][]byte{&small: []byte("tiny"), &large: content} {
+		rc, info, err := artifacts.Open(ctx, *spec)
+		if err != nil {
+			t.Fatalf("Open() error = %v", err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if !bytes.Equal(got, want) || info.ContentType != "image/png" {
+			t.Errorf("Open() 读取了 %d 字节, %+v", len(got), info)
+		}
+	}
+
+	// 替换时删除旧对象
+	if _, err := artifacts.Put(ctx, large, "image/png", bytes.NewReader(content), int64(len(content)), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if blobs.Len() != 1 {
+		t.Errorf("替换后旧对象应被删除，Len() = %d", blobs.Len())
+	}
+
+	if err := artifacts.Delete(ctx, large); err != nil {
+		t.Fatal(err)
+	}
+	if blobs.Len() != 0 {
+		t.Errorf("Delete 后对象应被删除，Len() = %d", blobs.Len())
+	}
+	if _, _, err := artifacts.Open(ctx, large); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("Open() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+// TestArtifactGetOrCreate 测试生成与清理产物，nil时钟被忽略
+func TestArtifactGetOrCreate(t *testing.T) {
+	ctx := context.Background()
+	blobs := go_cache.NewMemoryBlobStore()
+	memory := go_cache.NewMemory(time.Minute, time.Minute)
+	artifacts := go_cache.NewArtifactCache(memory, blobs, go_cache.WithArtifactInlineLimit(16), go_cache.WithArtifactClock(nil))
+	spec := go_cache.ArtifactSpec{Source: "doc/1", Params: map[string]string{"format": "pdf"}}
+
+	calls := 0
+	generate := func(ctx context.Context, w io.Writer) (string, error) {
+		calls++
+		for i := 0; i < 10; i++ {
+			if _, err := io.WriteString(w, "page "); err != nil {
+				return "", err
+			}
+		}
+		return "application/pdf", nil
+	}
+	for i := 0; i < 2; i++ {
+		rc, info, err := artifacts.GetOrCreate(ctx, spec, time.Minute, generate)
+		if err != nil {
+			t.Fatalf("GetOrCreate() error = %v", err)
+		}
+		got, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(got) != strings.Repeat("page ", 10) || info.ContentType != "application/pdf" || !info.Offloaded {
+			t.Errorf("GetOrCreate() = %q, %+v", got, info)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("产物存在时不应再次生成，calls = %d", calls)
+	}
+
+	// 元数据过期后清理对象
+	if n, err := artifacts.Sweep(ctx, 0); err != nil || n != 0 {
+		t.Errorf("仍被引用的对象不应被清理，Sweep() = %d, %v", n, err)
+	}
+	if err := memory.Del(ctx, "artifact:"+spec.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := artifacts.Sweep(ctx, 0); err != nil || n != 1 || blobs.Len() != 0 {
+		t.Errorf("Sweep() = %d, %v, want 1", n, err)
+	}
+}
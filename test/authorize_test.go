@@ -0,0 +1,61 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+type userIDKey struct{}
+
+// TestAuthorizedCache 测试授权钩子限制只能访问当前用户的键
+func TestAuthorizedCache(t *testing.T) {
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewAuthorized(backend, func(ctx context.Context, op go_cache.Operation, key string) error {
+		userID, _ := ctx.Value(userIDKey{}).(string)
+		if !strings.HasPrefix(key, "user:"+userID+":") {
+			return go_cache.ErrAccessDenied
+		}
+		if op == go_cache.OpDel {
+			return errors.New("delete forbidden")
+		}
+		return nil
+	})
+
+	alice := context.WithValue(context.Background(), userIDKey{}, "alice")
+	bob := context.WithValue(context.Background(), userIDKey{}, "bob")
+
+	if err := cache.Set(alice, "user:alice:profile", "a", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var s string
+	if err := cache.Get(alice, "user:alice:profile", &s); err != nil || s != "a" {
+		t.Errorf("Get() = %q, %v", s, err)
+	}
+	if err := cache.Get(bob, "user:alice:profile", &s); !errors.Is(err, go_cache.ErrAccessDenied) {
+		t.Errorf("跨用户读取应被拒绝，实际为 %v", err)
+	}
+	if cache.Exists(bob, "user:alice:profile") {
+		t.Error("跨用户 Exists 应返回false")
+	}
+	if err := cache.Set(bob, "user:alice:profile", "b", time.Minute); !errors.Is(err, go_cache.ErrAccessDenied) {
+		t.Errorf("跨用户写入应被拒绝，实际为 %v", err)
+	}
+	if err := cache.Del(alice, "user:alice:profile"); err == nil || err.Error() != "delete forbidden" {
+		t.Errorf("应返回钩子的自定义错误，实际为 %v", err)
+	}
+
+	called := false
+	err := cache.GetSet(bob, "user:alice:x", time.Minute, &s, func(key string, obj any) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, go_cache.ErrAccessDenied) || called {
+		t.Errorf("被拒绝的 GetSet 不应调用回调: err=%v called=%v", err, called)
+	}
+}
@@ -0,0 +1,170 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestBatchExistsAndDel 测试批量检查与删除
+func TestBatchExistsAndDel(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]interface {
+		gsr.Cacher
+		go_cache.BatchCache
+	}{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	for name, cache := range backends {
+		t.Run(name, func(t *testing.T) {
+			for _, key := range []string{"batch:a", "batch:b", "batch:c"} {
+				if err := cache.Set(ctx, key, key, time.Minute); err != nil {
+					t.Fatalf("Set() error = %v", err)
+				}
+			}
+
+			exists, err := cache.ExistsMulti(ctx, []string{"batch:a", "batch:b", "batch:missing"})
+			if err != nil {
+				t.Fatalf("ExistsMulti() error = %v", err)
+			}
+			want := map[string]bool{"batch:a": true, "batch:b": true, "batch:missing": false}
+			for key, v := range want {
+				if exists[key] != v {
+					t.Errorf("ExistsMulti()[%s] = %v, want %v", key, exists[key], v)
+				}
+			}
+
+			if err := cache.DelMulti(ctx, "batch:a", "batch:b", "batch:missing"); err != nil {
+				t.Fatalf("DelMulti() error = %v", err)
+			}
+			exists, _ = cache.ExistsMulti(ctx, []string{"batch:a", "batch:b", "batch:c"})
+			if exists["batch:a"] || exists["batch:b"] || !exists["batch:c"] {
+				t.Errorf("DelMulti() 后 ExistsMulti() = %v", exists)
+			}
+
+			if err := cache.DelMulti(ctx); err != nil {
+				t.Errorf("DelMulti() 空参数 error = %v", err)
+			}
+		})
+	}
+}
+
+// plainCache 只暴露 gsr.Cacher 的方法，用于测试通用实现
+type plainCache struct {
+	gsr.Cacher
+}
+
+// TestMGetPartialFailure 测试批量读取中一个键解码失败不影响其他键
+func TestMGetPartialFailure(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	memory := go_cache.NewMemory(time.Minute, time.Minute)
+
+	backends := map[string]struct {
+		cache   gsr.Cacher
+		corrupt func(key string)
+	}{
+		"memory": {memory, func(key string) { _ = memory.Set(ctx, key, 42, time.Minute) }},
+		"redis":  {r.Cache, func(key string) { r.Client.Set(ctx, key, "not gob", time.Minute) }},
+		"generic": {plainCache{memory}, func(key string) {
+			_ = memory.Set(ctx, key, 42, time.Minute)
+		}},
+	}
+
+	for name, b := range backends {
+		t.Run(name, func(t *testing.T) {
+			_ = b.cache.Set(ctx, "mget:a", "A", time.Minute)
+			_ = b.cache.Set(ctx, "mget:b", "B", time.Minute)
+			b.corrupt("mget:bad")
+
+			var a, bv, bad, missing string
+			errs, err := go_cache.MGet(ctx, b.cache, map[string]any{
+				"mget:a": &a, "mget:b": &bv, "mget:bad": &bad, "mget:missing": &missing,
+			})
+			if err != nil {
+				t.Fatalf("MGet() error = %v", err)
+			}
+			if a != "A" || bv != "B" {
+				t.Errorf("成功的键应写入值，a=%q b=%q", a, bv)
+			}
+			if len(errs) != 2 {
+				t.Fatalf("应有2个失败的键，实际为%v", errs)
+			}
+			if !errors.Is(errs["mget:missing"], go_cache.ErrKeyNotFound) {
+				t.Errorf("未命中的键错误应为 ErrKeyNotFound，实际为%v", errs["mget:missing"])
+			}
+			if errs["mget:bad"] == nil || errors.Is(errs["mget:bad"], go_cache.ErrKeyNotFound) {
+				t.Errorf("解码失败的键错误不正确: %v", errs["mget:bad"])
+			}
+			if name == "redis" && !errors.Is(errs["mget:bad"], go_cache.ErrSerialization) {
+				t.Errorf("解码失败的键错误应为 ErrSerialization，实际为%v", errs["mget:bad"])
+			}
+
+			if _, err := go_cache.MGet(go_cache.WithNoCache(ctx), b.cache, map[string]any{"mget:a": &a}); !errors.Is(err, go_cache.ErrCacheBypassed) {
+				t.Errorf("WithNoCache 时应返回 ErrCacheBypassed，实际为%v", err)
+			}
+		})
+	}
+}
+
+// TestGetSetMulti 测试批量 GetSet 只加载未命中与读取失败的键
+func TestGetSetMulti(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	for name, cache := range backends {
+		t.Run(name, func(t *testing.T) {
+			_ = cache.Set(ctx, "gsm:hit", "cached", time.Minute)
+			if name == "redis" {
+				r.Client.Set(ctx, "gsm:bad", "not gob", time.Minute)
+			}
+
+			var loaded []string
+			var hit, miss, bad, fail string
+			errs := go_cache.GetSetMulti(ctx, cache, time.Minute, map[string]any{
+				"gsm:hit": &hit, "gsm:miss": &miss, "gsm:bad": &bad, "gsm:fail": &fail,
+			}, func(key string, obj any) error {
+				loaded = append(loaded, key)
+				if key == "gsm:fail" {
+					return errors.New("load failed")
+				}
+				*obj.(*string) = "loaded:" + key
+				return nil
+			})
+
+			if len(errs) != 1 || errs["gsm:fail"] == nil {
+				t.Fatalf("只有 gsm:fail 应失败，实际为%v", errs)
+			}
+			if hit != "cached" || miss != "loaded:gsm:miss" || bad != "loaded:gsm:bad" {
+				t.Errorf("值不正确: hit=%q miss=%q bad=%q", hit, miss, bad)
+			}
+			sort.Strings(loaded)
+			if fmt.Sprint(loaded) != "[gsm:bad gsm:fail gsm:miss]" {
+				t.Errorf("加载的键不正确: %v", loaded)
+			}
+
+			var stored string
+			if err := cache.Get(ctx, "gsm:bad", &stored); err != nil || stored != "loaded:gsm:bad" {
+				t.Errorf("重新加载的值应写入缓存，Get() = %q, %v", stored, err)
+			}
+			if cache.Exists(ctx, "gsm:fail") {
+				t.Error("加载失败的键不应写入缓存")
+			}
+		})
+	}
+}
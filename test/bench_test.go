@@ -0,0 +1,42 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/muleiwu/go-cache/bench"
+)
+
+// TestBenchRunMemory 测试基准工具在内存缓存上运行并输出表格
+func TestBenchRunMemory(t *testing.T) {
+	workloads := []bench.Workload{
+		{Name: "read-heavy", ReadRatio: 0.9, ValueSize: 16, Keys: 10},
+		{Name: "write-heavy", ReadRatio: 0.1, ValueSize: 16, Keys: 10},
+	}
+
+	results, err := bench.Run(context.Background(), []bench.Target{bench.MemoryTarget()}, workloads, bench.Options{
+		Ops:         200,
+		Concurrency: 3,
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("应返回2个结果，实际返回%d个", len(results))
+	}
+	for _, r := range results {
+		if r.Ops != 200 || r.Errors != 0 || r.OpsPerSec <= 0 {
+			t.Errorf("结果不正确: %+v", r)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := bench.WriteTable(&buf, results); err != nil {
+		t.Fatalf("WriteTable() error = %v", err)
+	}
+	if !strings.Contains(buf.String(), "read-heavy") || !strings.Contains(buf.String(), "memory") {
+		t.Errorf("表格输出缺少内容:\n%s", buf.String())
+	}
+}
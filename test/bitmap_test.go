@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// bitmapBackends 在内存缓存与Redis上运行位图的测试
+func bitmapBackends() map[string]func(t *testing.T) gsr.Cacher {
+	return map[string]func(t *testing.T) gsr.Cacher{
+		"memory": func(t *testing.T) gsr.Cacher {
+			return go_cache.NewMemory(time.Minute, time.Minute)
+		},
+		"redis": func(t *testing.T) gsr.Cacher {
+			r, _ := newRedisTest(t)
+			return r.Cache
+		},
+	}
+}
+
+// TestBitmapDailyActive 测试按日期记录用户活跃标记
+func TestBitmapDailyActive(t *testing.T) {
+	for name, backend := range bitmapBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			b := backend(t).(go_cache.BitmapCache)
+			key := "active:2026-10-16"
+
+			for _, user := range []int64{0, 7, 8, 1000} {
+				if old, err := b.SetBit(ctx, key, user, true); err != nil || old {
+					t.Fatalf("SetBit(%d) = %v, %v, want false", user, old, err)
+				}
+			}
+			if old, err := b.SetBit(ctx, key, 7, true); err != nil || !old {
+				t.Errorf("SetBit(7) again = %v, %v, want true", old, err)
+			}
+
+			for user, want := range map[int64]bool{0: true, 1: false, 7: true, 8: true, 999: false, 1000: true, 1 << 20: false} {
+				if got, err := b.GetBit(ctx, key, user); err != nil || got != want {
+					t.Errorf("GetBit(%d) = %v, %v, want %v", user, got, err, want)
+				}
+			}
+			if n, err := b.BitCount(ctx, key); err != nil || n != 4 {
+				t.Errorf("BitCount() = %d, %v, want 4", n, err)
+			}
+
+			if old, err := b.SetBit(ctx, key, 8, false); err != nil || !old {
+				t.Errorf("SetBit(8, false) = %v, %v, want true", old, err)
+			}
+			if n, err := b.BitCount(ctx, key); err != nil || n != 3 {
+				t.Errorf("BitCount() after clear = %d, %v, want 3", n, err)
+			}
+			if n, err := b.BitCount(ctx, "active:missing"); err != nil || n != 0 {
+				t.Errorf("BitCount(missing) = %d, %v, want 0", n, err)
+			}
+			if _, err := b.SetBit(ctx, key, -1, true); err == nil {
+				t.Error("SetBit() with negative offset should fail")
+			}
+		})
+	}
+}
+
+// TestBitmapRedisLayout 测试位的顺序与Redis一致
+func TestBitmapRedisLayout(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	if _, err := r.Cache.SetBit(ctx, "bits", 0, true); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.Cache.SetBit(ctx, "bits", 9, true); err != nil {
+		t.Fatal(err)
+	}
+	if raw, err := r.Client.Get(ctx, "bits").Result(); err != nil || raw != "\x80\x40" {
+		t.Errorf("raw = %q, %v, want \"\\x80\\x40\"", raw, err)
+	}
+}
+
+// TestBitmapMemory 测试内存缓存保留过期时间与类型检查
+func TestBitmapMemory(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+
+	if _, err := cache.SetBit(ctx, "daily", 1, true); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.ExpiresIn(ctx, "daily", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.SetBit(ctx, "daily", 100, true); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if n, err := cache.BitCount(ctx, "daily"); err != nil || n != 0 {
+		t.Errorf("BitCount() after expiry = %d, %v, want 0", n, err)
+	}
+
+	if err := cache.Set(ctx, "plain", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.SetBit(ctx, "plain", 0, true); !errors.Is(err, go_cache.ErrTypeMismatch) {
+		t.Errorf("SetBit() error = %v, want ErrTypeMismatch", err)
+	}
+}
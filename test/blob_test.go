@@ -0,0 +1,407 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/testutil"
+)
+
+// testBlobStore 对存储执行写入、读取、列出与删除
+func testBlobStore(t *testing.T, store go_cache.BlobStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("x"), 1000)
+	for _, name := range []string{"a.1", "a.2", "b.1"} {
+		if err := store.PutBlob(ctx, name, bytes.NewReader(data), int64(len(data))); err != nil {
+			t.Fatalf("PutBlob(%s) error = %v", name, err)
+		}
+	}
+
+	r, err := store.GetBlob(ctx, "a.1")
+	if err != nil {
+		t.Fatalf("GetBlob() error = %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if !bytes.Equal(got, data) {
+		t.Errorf("GetBlob() 读取了 %d 字节，want %d", len(got), len(data))
+	}
+	if _, err := store.GetBlob(ctx, "missing"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("不存在的对象 GetBlob() error = %v, want ErrKeyNotFound", err)
+	}
+
+	var names []string
+	err = store.(go_cache.BlobLister).ListBlobs(ctx, "a.", func(info go_cache.BlobInfo) error {
+		if info.Size != int64(len(data)) || info.ModTime.IsZero() {
+			t.Errorf("ListBlobs() info = %+v", info)
+		}
+		names = append(names, info.Name)
+		return nil
+	})
+	if err != nil || len(names) != 2 || names[0] != "a.1" || names[1] != "a.2" {
+		t.Errorf("ListBlobs() = %v, %v", names, err)
+	}
+
+	if err := store.DeleteBlob(ctx, "a.1"); err != nil {
+		t.Fatalf("DeleteBlob() error = %v", err)
+	}
+	if err := store.DeleteBlob(ctx, "a.1"); err != nil {
+		t.Errorf("删除不存在的对象 DeleteBlob() error = %v", err)
+	}
+	if _, err := store.GetBlob(ctx, "a.1"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("删除后 GetBlob() error = %v", err)
+	}
+}
+
+// TestMemoryBlobStore 测试内存对象存储
+func TestMemoryBlobStore(t *testing.T) {
+	testBlobStore(t, go_cache.NewMemoryBlobStore())
+}
+
+// TestFileBlobStore 测试文件对象存储
+func TestFileBlobStore(t *testing.T) {
+	store, err := go_cache.NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBlobStore(t, store)
+
+	if err := store.PutBlob(context.Background(), "../escape", strings.NewReader("x"), 1); err == nil {
+		t.Error("包含路径分隔符的名称应返回错误")
+	}
+}
+
+// fakeS3 只实现对象读写、删除与 ListObjectsV2 的S3服务
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AKID/") {
+		http.Error(w, "missing signature", http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/bucket/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case r.Method == http.MethodGet && key == "" && r.URL.Query().Get("list-type") == "2":
+		type content struct {
+			Key          string
+			Size         int
+			LastModified time.Time
+		}
+		var result struct {
+			XMLName  xml.Name `xml:"ListBucketResult"`
+			Contents []content
+		}
+		prefix := r.URL.Query().Get("prefix")
+		for name, data := range s.objects {
+			if strings.HasPrefix(name, prefix) {
+				result.Contents = append(result.Contents, content{name, len(data), time.Now().Add(-time.Hour)})
+			}
+		}
+		sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+		_ = xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		s.objects[key], _ = io.ReadAll(r.Body)
+	case r.Method == http.MethodGet:
+		data, ok := s.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(s.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unsupported", http.StatusBadRequest)
+	}
+}
+
+// TestS3BlobStore 测试S3对象存储的请求与签名
+func TestS3BlobStore(t *testing.T) {
+	fake := &fakeS3{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	creds := aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}, nil
+	})
+	store, err := go_cache.NewS3BlobStore(go_cache.S3BlobConfig{
+		Endpoint:    server.URL,
+		Bucket:      "bucket",
+		Prefix:      "cache/",
+		Credentials: creds,
+		PathStyle:   true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	testBlobStore(t, store)
+
+	fake.mu.Lock()
+	_, ok := fake.objects["cache/b.1"]
+	fake.mu.Unlock()
+	if !ok {
+		t.Error("对象键应包含前缀")
+	}
+}
+
+// TestRedisBlobOffload 测试大值保存到对象存储
+func TestRedisBlobOffload(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	blobs := go_cache.NewMemoryBlobStore()
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisBlobOffload(blobs, 1024))
+
+	large := strings.Repeat("payload ", 1000)
+	if err := cache.Set(ctx, "doc:1", large, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cache.Set(ctx, "doc:small", "small", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if blobs.Len() != 1 {
+		t.Fatalf("只有超过阈值的值应写入对象存储，Len() = %d", blobs.Len())
+	}
+	if raw, _ := r.Client.Get(ctx, "doc:1").Result(); len(raw) > 1024 {
+		t.Errorf("Redis中应只保存指针，大小 = %d", len(raw))
+	}
+
+	var s string
+	if err := cache.Get(ctx, "doc:1", &s); err != nil || s != large {
+		t.Fatalf("Get() 读取了 %d 字节, %v", len(s), err)
+	}
+	got := map[string]any{"doc:1": new(string), "doc:small": new(string)}
+	if errs, err := cache.MGet(ctx, got); err != nil || errs["doc:1"] != nil || *got["doc:1"].(*string) != large {
+		t.Errorf("MGet() = %v, %v", errs, err)
+	}
+
+	// 覆盖时删除旧对象
+	if err := cache.Set(ctx, "doc:1", large+"v2", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if blobs.Len() != 1 {
+		t.Errorf("覆盖后旧对象应被删除，Len() = %d", blobs.Len())
+	}
+	if ok, err := cache.SetNX(ctx, "doc:1", large, time.Minute); ok || err != nil {
+		t.Fatalf("SetNX() = %v, %v", ok, err)
+	}
+	if blobs.Len() != 1 {
+		t.Errorf("SetNX 没有写入时应删除新对象，Len() = %d", blobs.Len())
+	}
+
+	if err := cache.Del(ctx, "doc:1"); err != nil {
+		t.Fatal(err)
+	}
+	if blobs.Len() != 0 {
+		t.Errorf("Del 后对象应被删除，Len() = %d", blobs.Len())
+	}
+	if err := cache.Get(ctx, "doc:1", &s); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("Get() error = %v, want ErrKeyNotFound", err)
+	}
+}
+
+// TestRedisSweepBlobs 测试清理过期键的对象
+func TestRedisSweepBlobs(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	blobs := go_cache.NewMemoryBlobStore()
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisBlobOffload(blobs, 16))
+
+	value := strings.Repeat("x", 100)
+	for _, key := range []string{"a", "b", "c"} {
+		if err := cache.Set(ctx, key, value, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := cache.ExpiresIn(ctx, "a", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	r.FastForward(2 * time.Second)
+	// 不经由 Del 删除，对象成为孤儿
+	r.Client.Del(ctx, "b")
+
+	if n, err := cache.SweepBlobs(ctx, time.Hour); err != nil || n != 0 {
+		t.Errorf("新写入的对象不应被清理，SweepBlobs() = %d, %v", n, err)
+	}
+	if n, err := cache.SweepBlobs(ctx, 0); err != nil || n != 2 {
+		t.Errorf("SweepBlobs() = %d, %v, want 2", n, err)
+	}
+	var s string
+	if blobs.Len() != 1 || cache.Get(ctx, "c", &s) != nil || s != value {
+		t.Errorf("仍被引用的对象不应被清理，Len() = %d", blobs.Len())
+	}
+}
+
+// TestRedisSweepBlobsNamespace 测试共用对象存储的实例只清理自己命名空间中的对象
+func TestRedisSweepBlobsNamespace(t *testing.T) {
+	ctx := context.Background()
+	blobs := go_cache.NewMemoryBlobStore()
+	value := strings.Repeat("x", 100)
+
+	newCache := func(namespace string) (*go_cache.Redis, *testutil.Redis) {
+		r, _ := newRedisTest(t)
+		cache := go_cache.NewRedis(r.Client, go_cache.WithRedisBlobOffload(blobs, 16), go_cache.WithRedisBlobNamespace(namespace))
+		if err := cache.Set(ctx, "k", value, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		return cache, r
+	}
+	plain, _ := newCache("")
+	db0, r0 := newCache("db0")
+	nested, _ := newCache("db0.replica")
+
+	// 每个实例的键仍引用自己的对象，清理时不能删除其他实例的对象
+	for name, cache := range map[string]*go_cache.Redis{"plain": plain, "db0": db0, "nested": nested} {
+		if n, err := cache.SweepBlobs(ctx, 0); err != nil || n != 0 {
+			t.Errorf("%s: SweepBlobs() = %d, %v，不应删除其他命名空间的对象", name, n, err)
+		}
+	}
+	if blobs.Len() != 3 {
+		t.Fatalf("Len() = %d, want 3", blobs.Len())
+	}
+
+	// 孤儿对象只由所属命名空间的实例清理
+	r0.Client.Del(ctx, "k")
+	if n, err := nested.SweepBlobs(ctx, 0); err != nil || n != 0 {
+		t.Errorf("其他命名空间的孤儿对象不应被清理，SweepBlobs() = %d, %v", n, err)
+	}
+	if n, err := db0.SweepBlobs(ctx, 0); err != nil || n != 1 {
+		t.Errorf("SweepBlobs() = %d, %v, want 1", n, err)
+	}
+	var s string
+	if blobs.Len() != 2 || plain.Get(ctx, "k", &s) != nil || nested.Get(ctx, "k", &s) != nil {
+		t.Errorf("其他实例的对象应保留，Len() = %d", blobs.Len())
+	}
+}
+
+// TestRedisBlobOffloadRenameCopy 测试重命名与复制后对象仍可读取且不会被清理
+func TestRedisBlobOffloadRenameCopy(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	blobs := go_cache.NewMemoryBlobStore()
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisBlobOffload(blobs, 16))
+
+	value := strings.Repeat("x", 100)
+	if err := cache.Set(ctx, "old", value, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Rename(ctx, "old", "new"); err != nil {
+		t.Fatalf("Rename() error = %v", err)
+	}
+	if n, err := cache.SweepBlobs(ctx, 0); err != nil || n != 0 {
+		t.Errorf("重命名后的键仍引用对象，SweepBlobs() = %d, %v", n, err)
+	}
+	var s string
+	if err := cache.Get(ctx, "new", &s); err != nil || s != value {
+		t.Fatalf("Rename 后 Get() = %d 字节, %v", len(s), err)
+	}
+	if ttl, err := cache.TTL(ctx, "new"); err != nil || ttl <= 0 {
+		t.Errorf("Rename 应保留有效期，TTL() = %v, %v", ttl, err)
+	}
+	if err := cache.Rename(ctx, "old", "other"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("Rename() error = %v, want ErrKeyNotFound", err)
+	}
+
+	if err := cache.Copy(ctx, "new", "copy", time.Minute); err != nil {
+		t.Fatalf("Copy() error = %v", err)
+	}
+	if err := cache.Set(ctx, "new", value+"v2", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Get(ctx, "copy", &s); err != nil || s != value {
+		t.Errorf("源键被覆盖后复制的键应仍可读取，Get() = %d 字节, %v", len(s), err)
+	}
+	if blobs.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", blobs.Len())
+	}
+	if n, err := cache.SweepBlobs(ctx, 0); err != nil || n != 0 {
+		t.Errorf("SweepBlobs() = %d, %v, want 0", n, err)
+	}
+}
+
+// TestRedisBlobOffloadTxnAndFields 测试事务、哈希字段与条件读取经由对象存储
+func TestRedisBlobOffloadTxnAndFields(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	blobs := go_cache.NewMemoryBlobStore()
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisBlobOffload(blobs, 64))
+
+	value := strings.Repeat("y", 200)
+	err := cache.Txn(ctx, func(tx go_cache.Txn) error {
+		return tx.Set("txn", value, time.Minute)
+	}, "txn")
+	if err != nil {
+		t.Fatalf("Txn() error = %v", err)
+	}
+	if raw, _ := r.Client.Get(ctx, "txn").Result(); len(raw) > 64 {
+		t.Errorf("事务写入的大值应保存到对象存储，Redis中大小 = %d", len(raw))
+	}
+	var s string
+	err = cache.Txn(ctx, func(tx go_cache.Txn) error {
+		if err := tx.Get(ctx, "txn", &s); err != nil {
+			return err
+		}
+		tx.Del("txn")
+		return nil
+	}, "txn")
+	if err != nil || s != value {
+		t.Fatalf("事务中 Get() = %d 字节, %v", len(s), err)
+	}
+	if blobs.Len() != 0 {
+		t.Errorf("事务删除键后对象应被删除，Len() = %d", blobs.Len())
+	}
+
+	type doc struct {
+		Title string
+		Body  string
+	}
+	if err := cache.SetFields(ctx, "doc", doc{Title: "t", Body: value}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if blobs.Len() != 1 {
+		t.Errorf("超过阈值的字段应保存到对象存储，Len() = %d", blobs.Len())
+	}
+	var got doc
+	if err := cache.GetFields(ctx, "doc", &got); err != nil || got.Body != value {
+		t.Errorf("GetFields() = %+v, %v", got, err)
+	}
+	if n, err := cache.SweepBlobs(ctx, 0); err != nil || n != 0 {
+		t.Errorf("哈希字段引用的对象不应被清理，SweepBlobs() = %d, %v", n, err)
+	}
+
+	etags := go_cache.NewETagCache(cache)
+	if err := etags.Set(ctx, "etag", value, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	etag, err := etags.GetIfChanged(ctx, "etag", "", &s)
+	if err != nil || s != value {
+		t.Fatalf("GetIfChanged() = %d 字节, %v", len(s), err)
+	}
+	if _, err := etags.GetIfChanged(ctx, "etag", etag, &s); !errors.Is(err, go_cache.ErrNotModified) {
+		t.Errorf("GetIfChanged() error = %v, want ErrNotModified", err)
+	}
+}
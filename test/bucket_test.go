@@ -0,0 +1,114 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// bucketBackend 创建桶使用的缓存，advance 推进计算补充与流出的时间
+type bucketBackend func(t *testing.T) (cache gsr.Cacher, opts []go_cache.BucketOption, advance func(time.Duration))
+
+// bucketBackends 在内存缓存与Redis上运行桶的测试
+func bucketBackends() map[string]bucketBackend {
+	return map[string]bucketBackend{
+		"memory": func(t *testing.T) (gsr.Cacher, []go_cache.BucketOption, func(time.Duration)) {
+			clock := go_cache.NewFakeClock(time.Time{})
+			cache := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+			return cache, []go_cache.BucketOption{go_cache.WithBucketClock(clock)}, clock.Advance
+		},
+		"redis": func(t *testing.T) (gsr.Cacher, []go_cache.BucketOption, func(time.Duration)) {
+			r, _ := newRedisTest(t)
+			if r.Server == nil {
+				t.Skip("需要 miniredis 控制服务端时间")
+			}
+			now := time.Now()
+			r.Server.SetTime(now)
+			return r.Cache, nil, func(d time.Duration) {
+				now = now.Add(d)
+				r.Server.SetTime(now)
+				r.Server.FastForward(d)
+			}
+		},
+	}
+}
+
+// TestTokenBucket 测试令牌桶的突发、补充与 RetryAfter
+func TestTokenBucket(t *testing.T) {
+	for name, backend := range bucketBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, opts, advance := backend(t)
+			tb := go_cache.NewTokenBucket(cache, 3, time.Second, opts...)
+
+			// 允许容量以内的突发
+			for i := 2; i >= 0; i-- {
+				res, err := tb.Allow(ctx, "api:u1")
+				if err != nil || !res.Allowed || res.Remaining != i {
+					t.Fatalf("Allow() = %+v, %v, want allowed with %d remaining", res, err, i)
+				}
+			}
+
+			res, err := tb.Allow(ctx, "api:u1")
+			if err != nil || res.Allowed || res.RetryAfter != time.Second {
+				t.Fatalf("Allow() on empty bucket = %+v, %v, want denied with 1s retry", res, err)
+			}
+
+			// 其他键不受影响
+			if res, _ := tb.Allow(ctx, "api:u2"); !res.Allowed {
+				t.Error("other key should have its own bucket")
+			}
+
+			advance(time.Second)
+			if res, err := tb.Allow(ctx, "api:u1"); err != nil || !res.Allowed || res.Remaining != 0 {
+				t.Errorf("Allow() after refill = %+v, %v, want allowed with 0 remaining", res, err)
+			}
+
+			advance(10 * time.Second)
+			if res, err := tb.Take(ctx, "api:u1", 3); err != nil || !res.Allowed {
+				t.Errorf("Take(3) after full refill = %+v, %v, want allowed", res, err)
+			}
+		})
+	}
+}
+
+// TestLeakyBucket 测试漏桶的容量、流出与 Delay
+func TestLeakyBucket(t *testing.T) {
+	for name, backend := range bucketBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, opts, advance := backend(t)
+			lb := go_cache.NewLeakyBucket(cache, 2, 100*time.Millisecond, opts...)
+
+			res, err := lb.Allow(ctx, "jobs")
+			if err != nil || !res.Allowed || res.Delay != 0 || res.Remaining != 1 {
+				t.Fatalf("Allow() = %+v, %v, want allowed without delay", res, err)
+			}
+			res, err = lb.Allow(ctx, "jobs")
+			if err != nil || !res.Allowed || res.Delay != 100*time.Millisecond {
+				t.Fatalf("Allow() = %+v, %v, want allowed with 100ms delay", res, err)
+			}
+			res, err = lb.Allow(ctx, "jobs")
+			if err != nil || res.Allowed || res.RetryAfter != 100*time.Millisecond {
+				t.Fatalf("Allow() on full bucket = %+v, %v, want denied with 100ms retry", res, err)
+			}
+
+			advance(100 * time.Millisecond)
+			if res, err := lb.Allow(ctx, "jobs"); err != nil || !res.Allowed {
+				t.Errorf("Allow() after leak = %+v, %v, want allowed", res, err)
+			}
+		})
+	}
+}
+
+// TestBucketExceeded 测试超过容量的请求
+func TestBucketExceeded(t *testing.T) {
+	tb := go_cache.NewTokenBucket(go_cache.NewMemory(time.Minute, time.Minute), 2, time.Second)
+	if _, err := tb.Take(context.Background(), "k", 3); !errors.Is(err, go_cache.ErrBucketExceeded) {
+		t.Errorf("Take(3) error = %v, want ErrBucketExceeded", err)
+	}
+}
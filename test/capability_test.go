@@ -0,0 +1,83 @@
+package test
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestSupportsMemory 测试内存缓存的能力
+func TestSupportsMemory(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+
+	for _, c := range []go_cache.Capability{
+		go_cache.CapabilityBatch,
+		go_cache.CapabilityPattern,
+		go_cache.CapabilityExpiry,
+		go_cache.CapabilityTTL,
+		go_cache.CapabilityPriority,
+	} {
+		if !go_cache.Supports(cache, c) {
+			t.Errorf("Memory should support %s", c)
+		}
+	}
+	if go_cache.Supports(cache, go_cache.Capability("unknown")) {
+		t.Error("unknown capability should not be supported")
+	}
+}
+
+// TestSupportsRedis 测试Redis缓存的能力
+func TestSupportsRedis(t *testing.T) {
+	r, _ := newRedisTest(t)
+
+	for _, c := range []go_cache.Capability{
+		go_cache.CapabilityBatch,
+		go_cache.CapabilityPattern,
+		go_cache.CapabilityTxn,
+		go_cache.CapabilityRename,
+	} {
+		if !go_cache.Supports(r.Cache, c) {
+			t.Errorf("Redis should support %s", c)
+		}
+	}
+}
+
+// TestSupportsNone 测试空缓存只支持按优先级写入
+func TestSupportsNone(t *testing.T) {
+	caps := go_cache.Capabilities(go_cache.NewCacheNone())
+	if !slices.Equal(caps, []go_cache.Capability{go_cache.CapabilityPriority}) {
+		t.Errorf("None capabilities = %v, want [priority]", caps)
+	}
+}
+
+// TestCapabilitiesSorted 测试返回的能力按名称排序
+func TestCapabilitiesSorted(t *testing.T) {
+	caps := go_cache.Capabilities(go_cache.NewMemory(time.Minute, time.Minute))
+	if len(caps) == 0 {
+		t.Fatal("Memory should report capabilities")
+	}
+	if !slices.IsSorted(caps) {
+		t.Errorf("Capabilities() = %v, want sorted", caps)
+	}
+}
+
+// TestSupportsWrapper 测试包装类型的能力取决于底层缓存
+func TestSupportsWrapper(t *testing.T) {
+	tenant, err := go_cache.ForTenant(go_cache.NewMemory(time.Minute, time.Minute), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !go_cache.Supports(tenant, go_cache.CapabilityPattern) {
+		t.Error("tenant over Memory should support pattern")
+	}
+
+	tenant, err = go_cache.ForTenant(go_cache.NewCacheNone(), "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if go_cache.Supports(tenant, go_cache.CapabilityPattern) {
+		t.Error("tenant over None should not support pattern")
+	}
+}
@@ -0,0 +1,47 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestMemoryFakeClock 测试使用可推进时钟时的过期判断
+func TestMemoryFakeClock(t *testing.T) {
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := go_cache.NewMemory(time.Minute, time.Millisecond, go_cache.WithMemoryClock(clock))
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "session", "v", time.Hour)
+	_ = cache.Set(ctx, "forever", "v", 0)
+
+	// 真实时间流逝不影响过期判断
+	time.Sleep(5 * time.Millisecond)
+	if !cache.Exists(ctx, "session") {
+		t.Fatal("时钟未推进时键不应过期")
+	}
+
+	clock.Advance(59 * time.Minute)
+	ttls, _ := cache.SampleTTLs(ctx, 10)
+	for _, ttl := range ttls {
+		if ttl != -1 && ttl != time.Minute {
+			t.Errorf("剩余TTL应为1分钟，实际为%s", ttl)
+		}
+	}
+
+	clock.Advance(time.Minute)
+	var s string
+	if err := cache.Get(ctx, "session", &s); err == nil {
+		t.Error("时钟推进超过TTL后键应过期")
+	}
+	if keys, _ := cache.Keys(ctx, "*"); len(keys) != 1 || keys[0] != "forever" {
+		t.Errorf("Keys() 不应包含过期键，实际为%v", keys)
+	}
+
+	cache.DeleteExpired()
+	if stats := cache.Stats(); stats.Entries != 1 || stats.Expired != 1 {
+		t.Errorf("DeleteExpired 后统计不正确: entries=%d expired=%d", stats.Entries, stats.Expired)
+	}
+}
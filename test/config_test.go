@@ -0,0 +1,149 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// appConfig 测试用的配置文档
+type appConfig struct {
+	Name    string
+	Workers int
+}
+
+// waitUpdate 等待通道收到更新
+func waitUpdate(t *testing.T, ch <-chan go_cache.ConfigUpdate) go_cache.ConfigUpdate {
+	t.Helper()
+	select {
+	case u, ok := <-ch:
+		if !ok {
+			t.Fatal("watch channel closed")
+		}
+		return u
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for config update")
+	}
+	return go_cache.ConfigUpdate{}
+}
+
+// TestConfigVersion 测试写入时递增版本号
+func TestConfigVersion(t *testing.T) {
+	ctx := context.Background()
+	cfg := go_cache.NewConfig(go_cache.NewMemory(time.Minute, time.Minute))
+	defer cfg.Close()
+
+	var got appConfig
+	if _, err := cfg.Get(ctx, "app", &got); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("Get() on missing config error = %v, want ErrKeyNotFound", err)
+	}
+
+	for want := int64(1); want <= 3; want++ {
+		version, err := cfg.Set(ctx, "app", appConfig{Name: "api", Workers: int(want)})
+		if err != nil || version != want {
+			t.Fatalf("Set() = %d, %v, want %d, nil", version, err, want)
+		}
+	}
+
+	version, err := cfg.Get(ctx, "app", &got)
+	if err != nil || version != 3 || got.Workers != 3 {
+		t.Errorf("Get() = %d, %+v, %v, want version 3 with 3 workers", version, got, err)
+	}
+}
+
+// TestConfigWatch 测试本进程内的更新通知
+func TestConfigWatch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cfg := go_cache.NewConfig(go_cache.NewMemory(time.Minute, time.Minute))
+	defer cfg.Close()
+
+	if _, err := cfg.Set(ctx, "app", appConfig{Workers: 1}); err != nil {
+		t.Fatal(err)
+	}
+	ch, err := cfg.Watch(ctx, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := cfg.Watch(ctx, "other")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := cfg.Set(ctx, "app", appConfig{Workers: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if u := waitUpdate(t, ch); u.Key != "app" || u.Version != 2 {
+		t.Errorf("update = %+v, want app version 2", u)
+	}
+	select {
+	case u := <-other:
+		t.Errorf("watcher of other key got %+v", u)
+	default:
+	}
+
+	// ctx 取消后通道被关闭
+	cancel()
+	select {
+	case _, ok := <-ch:
+		if ok {
+			t.Error("watch channel should be closed after cancel")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("watch channel not closed after cancel")
+	}
+}
+
+// TestConfigWatchCoalesce 测试未读取的更新合并为最新的一次
+func TestConfigWatchCoalesce(t *testing.T) {
+	ctx := context.Background()
+	cfg := go_cache.NewConfig(go_cache.NewMemory(time.Minute, time.Minute))
+
+	ch, err := cfg.Watch(ctx, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 5; i++ {
+		if _, err := cfg.Set(ctx, "app", appConfig{Workers: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if u := waitUpdate(t, ch); u.Version != 5 {
+		t.Errorf("update version = %d, want 5", u.Version)
+	}
+
+	cfg.Close()
+	if _, ok := <-ch; ok {
+		t.Error("watch channel should be closed after Close")
+	}
+}
+
+// TestConfigRedisNotifier 测试通过Redis发布订阅通知其他实例
+func TestConfigRedisNotifier(t *testing.T) {
+	r, _ := newRedisTest(t)
+	ctx := context.Background()
+
+	writer := go_cache.NewConfig(r.Cache, go_cache.WithConfigNotifier(go_cache.NewRedisConfigNotifier(r.Client)))
+	defer writer.Close()
+	reader := go_cache.NewConfig(r.Cache, go_cache.WithConfigNotifier(go_cache.NewRedisConfigNotifier(r.Client)))
+	defer reader.Close()
+
+	ch, err := reader.Watch(ctx, "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := writer.Set(ctx, "app", appConfig{Name: "api", Workers: 8}); err != nil {
+		t.Fatal(err)
+	}
+
+	if u := waitUpdate(t, ch); u.Version != 1 {
+		t.Errorf("update version = %d, want 1", u.Version)
+	}
+	var got appConfig
+	if _, err := reader.Get(ctx, "app", &got); err != nil || got.Workers != 8 {
+		t.Errorf("Get() = %+v, %v, want 8 workers", got, err)
+	}
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/gocachetest"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/go-cache/testcache"
+	"github.com/muleiwu/go-cache/testutil"
+	"github.com/muleiwu/gsr"
+)
+
+// TestConformance 对内置后端运行一致性测试
+func TestConformance(t *testing.T) {
+	backends := []struct {
+		name    string
+		factory gocachetest.Factory
+	}{
+		{
+			name: "memory",
+			factory: func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+				clock := go_cache.NewFakeClock(time.Time{})
+				return go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)), clock.Advance
+			},
+		},
+		{
+			name: "memory/realtime",
+			factory: func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+				return go_cache.NewMemory(time.Minute, time.Minute), nil
+			},
+		},
+		{
+			name: "redis",
+			factory: func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+				r, _ := newRedisTest(t)
+				return r.Cache, r.FastForward
+			},
+		},
+		{
+			name: "redis/json",
+			factory: func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+				r, _ := testutil.NewRedis(t, testutil.WithBackend(testutil.BackendMiniredis),
+					testutil.WithCacheOptions(go_cache.WithRedisSerializer(serializer.NewJson())))
+				return r.Cache, r.FastForward
+			},
+		},
+		{
+			name: "testcache",
+			factory: func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+				c := testcache.New()
+				return c, c.Advance
+			},
+		},
+		{
+			name: "tiered",
+			factory: func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+				clock := go_cache.NewFakeClock(time.Time{})
+				l1 := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+				l2 := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+				return go_cache.NewTiered(l1, l2, go_cache.WithTieredClock(clock), go_cache.WithTieredReadYourWrites(time.Second)), clock.Advance
+			},
+		},
+	}
+
+	for _, b := range backends {
+		t.Run(b.name, func(t *testing.T) {
+			gocachetest.RunConformance(t, b.factory)
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestDebouncedWriterCoalesces 测试多次写入合并为一次
+func TestDebouncedWriterCoalesces(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	w := go_cache.NewDebouncedWriter(r.Cache, time.Hour)
+	defer w.Close()
+
+	for i := 0; i < 100; i++ {
+		if err := w.Set(ctx, "counter", i, time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+
+	// 刷新前可以读到最新值，底层缓存中还没有
+	var got int
+	if err := w.Get(ctx, "counter", &got); err != nil || got != 99 {
+		t.Errorf("Get() = %d, %v", got, err)
+	}
+	if r.Cache.Exists(ctx, "counter") {
+		t.Error("刷新前底层缓存不应存在该键")
+	}
+	if w.Pending() != 1 {
+		t.Errorf("Pending() = %d, want 1", w.Pending())
+	}
+
+	if err := w.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if sets := r.Cache.Stats().Sets; sets != 1 {
+		t.Errorf("底层写入次数 = %d, want 1", sets)
+	}
+	if err := r.Cache.Get(ctx, "counter", &got); err != nil || got != 99 {
+		t.Errorf("底层 Get() = %d, %v", got, err)
+	}
+}
+
+// TestDebouncedWriterBackgroundFlush 测试后台定期刷新与 Close 刷新剩余写入
+func TestDebouncedWriterBackgroundFlush(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	w := go_cache.NewDebouncedWriter(cache, 10*time.Millisecond)
+
+	_ = w.Set(ctx, "a", "1", 0)
+	deadline := time.Now().Add(2 * time.Second)
+	for !cache.Exists(ctx, "a") && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if !cache.Exists(ctx, "a") {
+		t.Fatal("后台刷新未写入")
+	}
+
+	_ = w.Set(ctx, "b", "2", 0)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if !cache.Exists(ctx, "b") {
+		t.Error("Close 未刷新剩余写入")
+	}
+}
+
+// TestDebouncedWriterDelAndExpire 测试删除丢弃未刷新的值，修改过期时间前先写入
+func TestDebouncedWriterDelAndExpire(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	w := go_cache.NewDebouncedWriter(cache, time.Hour, go_cache.WithDebounceMaxPending(10))
+	defer w.Close()
+
+	_ = w.Set(ctx, "del", "v", 0)
+	if err := w.Del(ctx, "del"); err != nil {
+		t.Fatalf("Del() error = %v", err)
+	}
+	_ = w.Flush(ctx)
+	if w.Exists(ctx, "del") {
+		t.Error("删除后不应存在")
+	}
+
+	_ = w.Set(ctx, "exp", "v", 0)
+	if err := w.ExpiresIn(ctx, "exp", time.Second); err != nil {
+		t.Fatalf("ExpiresIn() error = %v", err)
+	}
+	if ttl, err := cache.TTL(ctx, "exp"); err != nil || ttl <= 0 || ttl > time.Second {
+		t.Errorf("TTL() = %v, %v", ttl, err)
+	}
+
+	// 超过上限时立即刷新
+	for i := 0; i < 10; i++ {
+		_ = w.Set(ctx, string(rune('a'+i)), i, 0)
+	}
+	if w.Pending() != 0 || !cache.Exists(ctx, "a") {
+		t.Errorf("超过上限后 Pending() = %d", w.Pending())
+	}
+}
+
+// TestDebouncedWriterExpiry 测试未刷新的值按 Set 时的ttl过期，过期的写入刷新时删除底层的旧值
+func TestDebouncedWriterExpiry(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	w := go_cache.NewDebouncedWriter(cache, time.Hour)
+	defer w.Close()
+
+	_ = cache.Set(ctx, "k", "old", 0)
+	_ = w.Set(ctx, "k", "new", 20*time.Millisecond)
+	var got string
+	if err := w.Get(ctx, "k", &got); err != nil || got != "new" {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+
+	time.Sleep(40 * time.Millisecond)
+	if err := w.Get(ctx, "k", &got); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("过期后 Get() = %q, %v，期望 ErrKeyNotFound", got, err)
+	}
+	if w.Exists(ctx, "k") {
+		t.Error("过期后 Exists() 应返回false")
+	}
+	if err := w.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if cache.Exists(ctx, "k") {
+		t.Error("刷新过期的写入应删除底层缓存中的旧值")
+	}
+}
+
+// TestDebouncedWriterSetAfterClose 测试 Close 之后的写入直接写入底层缓存
+func TestDebouncedWriterSetAfterClose(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	w := go_cache.NewDebouncedWriter(cache, time.Hour)
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if err := w.Set(ctx, "late", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if w.Pending() != 0 {
+		t.Errorf("Close 之后不应缓冲写入，Pending() = %d", w.Pending())
+	}
+	var got string
+	if err := cache.Get(ctx, "late", &got); err != nil || got != "v" {
+		t.Errorf("底层 Get() = %q, %v", got, err)
+	}
+}
@@ -0,0 +1,88 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestDedupMemory 测试窗口内重复的事件被识别，窗口结束后重新计算
+func TestDedupMemory(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+
+	if first, err := go_cache.Dedup(ctx, cache, "evt-1", time.Minute); err != nil || !first {
+		t.Fatalf("Dedup() = %v, %v, want first seen", first, err)
+	}
+	clock.Advance(30 * time.Second)
+	if first, err := go_cache.Dedup(ctx, cache, "evt-1", time.Minute); err != nil || first {
+		t.Fatalf("Dedup() repeat = %v, %v, want duplicate", first, err)
+	}
+
+	// 重复出现不延长窗口
+	clock.Advance(31 * time.Second)
+	if first, err := go_cache.Dedup(ctx, cache, "evt-1", time.Minute); err != nil || !first {
+		t.Errorf("Dedup() after window = %v, %v, want first seen", first, err)
+	}
+}
+
+// TestDedupRedis 测试基于 SET NX PX 的去重
+func TestDedupRedis(t *testing.T) {
+	r, _ := newRedisTest(t)
+	ctx := context.Background()
+
+	if first, err := go_cache.Dedup(ctx, r.Cache, "evt-1", time.Minute); err != nil || !first {
+		t.Fatalf("Dedup() = %v, %v, want first seen", first, err)
+	}
+	if first, err := go_cache.Dedup(ctx, r.Cache, "evt-1", time.Minute); err != nil || first {
+		t.Fatalf("Dedup() repeat = %v, %v, want duplicate", first, err)
+	}
+	if ttl := r.Client.PTTL(ctx, "evt-1").Val(); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("PTTL = %v, want within window", ttl)
+	}
+
+	r.FastForward(time.Minute)
+	if first, err := go_cache.Dedup(ctx, r.Cache, "evt-1", time.Minute); err != nil || !first {
+		t.Errorf("Dedup() after window = %v, %v, want first seen", first, err)
+	}
+}
+
+// TestDedupConcurrent 测试并发的相同事件只有一个被视为第一次出现
+func TestDedupConcurrent(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+
+	var firsts atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if first, err := go_cache.Dedup(ctx, cache, "evt", time.Minute); err == nil && first {
+				firsts.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if firsts.Load() != 1 {
+		t.Errorf("first seen %d times, want 1", firsts.Load())
+	}
+}
+
+// TestDedupErrors 测试无效窗口与不支持的缓存
+func TestDedupErrors(t *testing.T) {
+	ctx := context.Background()
+	if _, err := go_cache.Dedup(ctx, go_cache.NewMemory(time.Minute, time.Minute), "evt", 0); !errors.Is(err, go_cache.ErrInvalidTTL) {
+		t.Errorf("Dedup() with zero window error = %v, want ErrInvalidTTL", err)
+	}
+	if _, err := go_cache.Dedup(ctx, go_cache.NewCacheNone(), "evt", time.Minute); !errors.Is(err, go_cache.ErrNotSupported) {
+		t.Errorf("Dedup() on None error = %v, want ErrNotSupported", err)
+	}
+}
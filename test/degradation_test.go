@@ -0,0 +1,144 @@
+package test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestDegradationTrackerWindows 测试按滚动窗口统计降级比例
+func TestDegradationTrackerWindows(t *testing.T) {
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	tracker := go_cache.NewDegradationTracker(
+		go_cache.WithDegradationResolution(time.Second),
+		go_cache.WithDegradationRetention(10*time.Second),
+		go_cache.WithDegradationClock(clock),
+	)
+
+	for i := 0; i < 8; i++ {
+		tracker.RecordRequest()
+	}
+	tracker.RecordDegraded(go_cache.DegradationCircuitOpen)
+	tracker.RecordDegraded(go_cache.DegradationFallback)
+
+	clock.Advance(5 * time.Second)
+	for i := 0; i < 5; i++ {
+		tracker.RecordDegraded(go_cache.DegradationFallback)
+	}
+	tracker.RecordRequest()
+
+	report := tracker.Report(time.Second)
+	if report.Requests != 6 || report.Degraded != 5 || report.Reasons[go_cache.DegradationFallback] != 5 {
+		t.Errorf("最近1秒的统计不正确: %+v", report)
+	}
+	report = tracker.Report(10 * time.Second)
+	if report.Requests != 16 || report.Degraded != 7 || report.Reasons[go_cache.DegradationCircuitOpen] != 1 {
+		t.Errorf("最近10秒的统计不正确: %+v", report)
+	}
+	if ratio := report.Ratio(); ratio < 0.43 || ratio > 0.44 {
+		t.Errorf("Ratio() = %v，期望为7/16", ratio)
+	}
+
+	// 超过保留时长的统计被丢弃
+	clock.Advance(8 * time.Second)
+	if report := tracker.Report(time.Hour); report.Requests != 6 || report.Window != 10*time.Second {
+		t.Errorf("超过保留时长后的统计不正确: %+v", report)
+	}
+	clock.Advance(10 * time.Second)
+	if report := tracker.Report(time.Hour); report.Requests != 0 || report.Ratio() != 0 {
+		t.Errorf("全部过期后的统计不正确: %+v", report)
+	}
+
+	// nil 上调用不做统计
+	var none *go_cache.DegradationTracker
+	none.RecordDegraded(go_cache.DegradationFallback)
+	if none.Report(time.Minute).Requests != 0 || none.SLOBreached() {
+		t.Error("nil 降级统计不应有数据")
+	}
+}
+
+// TestDegradationTrackerSLO 测试超过SLO与恢复时调用回调
+func TestDegradationTrackerSLO(t *testing.T) {
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var mu sync.Mutex
+	var events []bool
+	tracker := go_cache.NewDegradationTracker(
+		go_cache.WithDegradationResolution(time.Second),
+		go_cache.WithDegradationClock(clock),
+		go_cache.WithDegradationSLO(go_cache.DegradationSLO{Window: 5 * time.Second, MaxRatio: 0.1, MinRequests: 10},
+			func(report go_cache.DegradationReport, breached bool) {
+				mu.Lock()
+				events = append(events, breached)
+				mu.Unlock()
+			}),
+	)
+
+	// 请求数不足时不判断
+	for i := 0; i < 5; i++ {
+		tracker.RecordDegraded(go_cache.DegradationShedRead)
+		clock.Advance(time.Second)
+	}
+	if tracker.SLOBreached() {
+		t.Fatal("请求数少于 MinRequests 时不应判断为超标")
+	}
+
+	for i := 0; i < 10; i++ {
+		tracker.RecordDegraded(go_cache.DegradationShedRead)
+	}
+	clock.Advance(time.Second)
+	tracker.RecordRequest()
+	if !tracker.SLOBreached() {
+		t.Fatal("降级比例超过阈值后应判断为超标")
+	}
+
+	// 降级的请求移出窗口后恢复
+	clock.Advance(5 * time.Second)
+	for i := 0; i < 20; i++ {
+		tracker.RecordRequest()
+	}
+	clock.Advance(time.Second)
+	tracker.RecordRequest()
+	if tracker.SLOBreached() {
+		t.Fatal("窗口内没有降级后应恢复")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 2 || !events[0] || events[1] {
+		t.Errorf("SLO回调不正确: %v", events)
+	}
+}
+
+// TestLoadShedderDegradation 测试负载保护跳过的读写记录到降级统计
+func TestLoadShedderDegradation(t *testing.T) {
+	backend := newSlowCache()
+	tracker := go_cache.NewDegradationTracker()
+	cache := go_cache.NewLoadShedder(backend, 5*time.Millisecond,
+		go_cache.WithLoadShedWindow(10),
+		go_cache.WithLoadShedMinSamples(5),
+		go_cache.WithLoadShedProbeInterval(time.Hour),
+		go_cache.WithLoadShedDegradation(tracker),
+	)
+	ctx := context.Background()
+
+	backend.delay.Store(int64(10 * time.Millisecond))
+	var s string
+	reads := 0
+	for ; reads < 10 && !cache.Shedding(); reads++ {
+		_ = cache.Get(ctx, "k", &s)
+	}
+	if !cache.Shedding() {
+		t.Fatal("p99超过阈值后应进入负载保护")
+	}
+	_ = cache.Get(ctx, "k", &s)
+	_ = cache.Set(ctx, "k", "v", time.Minute)
+
+	report := tracker.Report(time.Minute)
+	if report.Requests != uint64(reads+2) || report.Degraded != 2 ||
+		report.Reasons[go_cache.DegradationShedRead] != 1 || report.Reasons[go_cache.DegradationShedWrite] != 1 {
+		t.Errorf("降级统计不正确: %+v", report)
+	}
+}
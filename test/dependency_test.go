@@ -0,0 +1,129 @@
+package test
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestDependencyGraph 测试父键更新或删除时依赖它的键被传递地删除
+func TestDependencyGraph(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			cache := go_cache.NewDependencyGraph(backend)
+			for _, key := range []string{"user:1", "team:1", "user:1:view", "team:1:page", "unrelated"} {
+				_ = cache.Set(ctx, key, key, time.Minute)
+			}
+			// team:1:page 依赖 user:1:view，user:1:view 依赖 user:1 与 team:1
+			_ = cache.DependsOn(ctx, "user:1:view", "user:1", "team:1")
+			_ = cache.DependsOn(ctx, "team:1:page", "user:1:view")
+			// 环不会导致死循环
+			_ = cache.DependsOn(ctx, "user:1", "team:1:page")
+
+			if err := cache.Set(ctx, "user:1", "updated", time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			for key, want := range map[string]bool{
+				"user:1": true, "team:1": true, "user:1:view": false, "team:1:page": false, "unrelated": true,
+			} {
+				if got := backend.Exists(ctx, key); got != want {
+					t.Errorf("更新 user:1 后 Exists(%s) = %v，期望为%v", key, got, want)
+				}
+			}
+
+			// 失效的键的依赖索引随之删除，没有重新声明依赖时更新 user:1:view 不再影响 team:1:page
+			_ = cache.Set(ctx, "team:1:page", "v2", time.Minute)
+			_ = cache.Set(ctx, "user:1:view", "v2", time.Minute)
+			if !backend.Exists(ctx, "team:1:page") {
+				t.Error("没有重新声明依赖的键不应被删除")
+			}
+
+			// user:1:view 对 team:1 的依赖仍然保留
+			_ = cache.Del(ctx, "team:1")
+			if backend.Exists(ctx, "team:1") || backend.Exists(ctx, "user:1:view") {
+				t.Error("删除父键后父键与子键都应被删除")
+			}
+			invalidated, err := cache.Invalidate(ctx, "team:1")
+			if err != nil || len(invalidated) != 0 {
+				t.Errorf("依赖已失效，Invalidate() = %v, %v", invalidated, err)
+			}
+
+			_ = cache.Set(ctx, "a:child", "v", time.Minute)
+			_ = cache.Set(ctx, "a:grandchild", "v", time.Minute)
+			_ = cache.DependsOn(ctx, "a:child", "a")
+			_ = cache.DependsOn(ctx, "a:grandchild", "a:child")
+			invalidated, err = cache.Invalidate(ctx, "a")
+			sort.Strings(invalidated)
+			if err != nil || len(invalidated) != 2 || invalidated[0] != "a:child" || invalidated[1] != "a:grandchild" {
+				t.Errorf("Invalidate() = %v, %v", invalidated, err)
+			}
+		})
+	}
+}
+
+// TestDependencyGraphGetSet 测试父键过期后经由 GetSet 重新加载时子键失效
+func TestDependencyGraphGetSet(t *testing.T) {
+	ctx := context.Background()
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewDependencyGraph(backend)
+
+	_ = cache.Set(ctx, "child", "v", time.Minute)
+	_ = cache.DependsOn(ctx, "child", "parent")
+
+	load := func(key string, obj any) error {
+		*obj.(*string) = "loaded"
+		return nil
+	}
+	var s string
+	if err := cache.GetSet(ctx, "parent", time.Minute, &s, load); err != nil {
+		t.Fatalf("GetSet() error = %v", err)
+	}
+	if backend.Exists(ctx, "child") {
+		t.Error("父键重新加载后子键应失效")
+	}
+
+	// 命中时不使子键失效
+	_ = cache.Set(ctx, "child", "v", time.Minute)
+	_ = cache.DependsOn(ctx, "child", "parent")
+	if err := cache.GetSet(ctx, "parent", time.Minute, &s, load); err != nil {
+		t.Fatalf("GetSet() error = %v", err)
+	}
+	if !backend.Exists(ctx, "child") {
+		t.Error("父键命中时子键不应失效")
+	}
+}
+
+// TestDependencyGraphRedisIndex 测试Redis的依赖索引由多个实例共享并带有有效期
+func TestDependencyGraphRedisIndex(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	a := go_cache.NewDependencyGraph(r.Cache, go_cache.WithDependencyIndexTTL(time.Hour))
+	b := go_cache.NewDependencyGraph(r.Cache)
+
+	_ = r.Cache.Set(ctx, "child", "v", time.Minute)
+	if err := a.DependsOn(ctx, "child", "parent"); err != nil {
+		t.Fatalf("DependsOn() error = %v", err)
+	}
+	if ttl := r.Client.TTL(ctx, "deps:parent").Val(); ttl <= 0 || ttl > time.Hour {
+		t.Errorf("依赖索引的有效期不正确: %v", ttl)
+	}
+
+	// 另一个实例删除父键时使用共享的索引
+	_ = b.Del(ctx, "parent")
+	if r.Cache.Exists(ctx, "child") || r.Client.Exists(ctx, "deps:parent").Val() != 0 {
+		t.Error("另一个实例删除父键后子键与索引应被删除")
+	}
+}
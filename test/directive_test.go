@@ -0,0 +1,68 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestContextDirectives 测试context中的缓存控制指令
+func TestContextDirectives(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+		"tiered": go_cache.NewTiered(go_cache.NewMemory(time.Minute, time.Minute), go_cache.NewMemory(time.Minute, time.Minute)),
+	}
+
+	for name, cache := range backends {
+		t.Run(name, func(t *testing.T) {
+			loads := 0
+			loader := func(key string, obj any) error {
+				loads++
+				*obj.(*string) = "fresh"
+				return nil
+			}
+
+			_ = cache.Set(ctx, "k", "cached", time.Minute)
+
+			// WithNoCache 跳过读取
+			var v string
+			if err := cache.Get(go_cache.WithNoCache(ctx), "k", &v); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("WithNoCache Get() error = %v, want ErrKeyNotFound", err)
+			}
+
+			// WithNoStore 跳过写入
+			if err := cache.Set(go_cache.WithNoStore(ctx), "k", "ignored", time.Minute); err != nil {
+				t.Fatalf("WithNoStore Set() error = %v", err)
+			}
+			if err := cache.Get(ctx, "k", &v); err != nil || v != "cached" {
+				t.Errorf("WithNoStore 后 Get() = %q, %v", v, err)
+			}
+
+			// WithForceRefresh 调用回调函数并写入结果
+			if err := cache.GetSet(go_cache.WithForceRefresh(ctx), "k", time.Minute, &v, loader); err != nil || v != "fresh" || loads != 1 {
+				t.Fatalf("WithForceRefresh GetSet() = %q, loads %d, %v", v, loads, err)
+			}
+			if err := cache.Get(ctx, "k", &v); err != nil || v != "fresh" {
+				t.Errorf("刷新后 Get() = %q, %v", v, err)
+			}
+
+			// 同时使用 WithNoCache 与 WithNoStore：调用回调函数但不写入
+			_ = cache.Set(ctx, "k", "cached", time.Minute)
+			both := go_cache.WithNoStore(go_cache.WithNoCache(ctx))
+			if err := cache.GetSet(both, "k", time.Minute, &v, loader); err != nil || v != "fresh" || loads != 2 {
+				t.Fatalf("WithNoCache+WithNoStore GetSet() = %q, loads %d, %v", v, loads, err)
+			}
+			if err := cache.Get(ctx, "k", &v); err != nil || v != "cached" {
+				t.Errorf("未写入时 Get() = %q, %v", v, err)
+			}
+		})
+	}
+}
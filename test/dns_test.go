@@ -0,0 +1,163 @@
+package test
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// fakeDNS 只应答A记录查询的UDP DNS服务器，records 的键为不带末尾点号的域名
+type fakeDNS struct {
+	conn    net.PacketConn
+	records map[string]net.IP
+	ttl     uint32
+	queries atomic.Int32
+}
+
+func newFakeDNS(t *testing.T, records map[string]net.IP, ttl uint32) *fakeDNS {
+	t.Helper()
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	s := &fakeDNS{conn: conn, records: records, ttl: ttl}
+	go s.serve()
+	return s
+}
+
+func (s *fakeDNS) serve() {
+	buf := make([]byte, 512)
+	for {
+		n, addr, err := s.conn.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if resp := s.answer(buf[:n]); resp != nil {
+			s.conn.WriteTo(resp, addr)
+		}
+	}
+}
+
+// answer 构造对查询的响应，问题部分原样返回
+func (s *fakeDNS) answer(query []byte) []byte {
+	// 读取问题中的域名
+	var labels []string
+	off := 12
+	for off < len(query) && query[off] != 0 {
+		l := int(query[off])
+		labels = append(labels, string(query[off+1:off+1+l]))
+		off += 1 + l
+	}
+	end := off + 5
+	if end > len(query) {
+		return nil
+	}
+	qtype := binary.BigEndian.Uint16(query[off+1:])
+	name := strings.Join(labels, ".")
+	if qtype == 1 {
+		s.queries.Add(1)
+	}
+
+	resp := append([]byte(nil), query[:end]...)
+	resp[2] = 0x81 // QR、RD
+	resp[3] = 0x80 // RA
+	binary.BigEndian.PutUint16(resp[6:], 0)
+	binary.BigEndian.PutUint16(resp[8:], 0)
+	binary.BigEndian.PutUint16(resp[10:], 0)
+
+	ttl := binary.BigEndian.AppendUint32(nil, s.ttl)
+	ip, ok := s.records[name]
+	switch {
+	case !ok:
+		// NXDOMAIN，权威部分为SOA，否定缓存时间为TTL
+		resp[3] |= 3
+		binary.BigEndian.PutUint16(resp[8:], 1)
+		resp = append(resp, 0xC0, 0x0C, 0, 6, 0, 1)
+		resp = append(resp, ttl...)
+		resp = append(resp, 0, 22, 0, 0)
+		for i := 0; i < 4; i++ {
+			resp = append(resp, 0, 0, 0, 1)
+		}
+		resp = append(resp, ttl...)
+	case qtype == 1:
+		binary.BigEndian.PutUint16(resp[6:], 1)
+		resp = append(resp, 0xC0, 0x0C, 0, 1, 0, 1)
+		resp = append(resp, ttl...)
+		resp = append(resp, 0, 4)
+		resp = append(resp, ip.To4()...)
+	}
+	return resp
+}
+
+// dial 忽略系统配置的服务器，连接到假服务器
+func (s *fakeDNS) dial(ctx context.Context, network, address string) (net.Conn, error) {
+	var d net.Dialer
+	return d.DialContext(ctx, "udp", s.conn.LocalAddr().String())
+}
+
+// TestDNSResolver 测试按记录的TTL缓存解析结果
+func TestDNSResolver(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeDNS(t, map[string]net.IP{"api.example.test": net.ParseIP("192.0.2.10")}, 30)
+	r, _ := newRedisTest(t)
+	resolver := go_cache.NewDNSResolver(r.Cache, go_cache.WithDNSDial(server.dial))
+
+	for i := 0; i < 3; i++ {
+		hosts, err := resolver.LookupHost(ctx, "api.example.test")
+		if err != nil || len(hosts) != 1 || hosts[0] != "192.0.2.10" {
+			t.Fatalf("LookupHost() = %v, %v", hosts, err)
+		}
+	}
+	if n := server.queries.Load(); n != 1 {
+		t.Errorf("缓存有效期内服务器收到 %d 次查询，want 1", n)
+	}
+	if ttl, err := r.Cache.TTL(ctx, "dns:api.example.test"); err != nil || ttl <= 0 || ttl > 30*time.Second {
+		t.Errorf("缓存有效期应为记录的TTL，TTL() = %v, %v", ttl, err)
+	}
+
+	r.FastForward(31 * time.Second)
+	if _, err := resolver.LookupHost(ctx, "api.example.test"); err != nil {
+		t.Fatal(err)
+	}
+	if n := server.queries.Load(); n != 2 {
+		t.Errorf("过期后应重新查询，服务器收到 %d 次查询", n)
+	}
+
+	if addrs, err := resolver.LookupIPAddr(ctx, "192.0.2.1"); err != nil || len(addrs) != 1 {
+		t.Errorf("IP地址字面量 LookupIPAddr() = %v, %v", addrs, err)
+	}
+}
+
+// TestDNSResolverNegative 测试缓存不存在的域名
+func TestDNSResolverNegative(t *testing.T) {
+	ctx := context.Background()
+	server := newFakeDNS(t, nil, 5)
+	r, _ := newRedisTest(t)
+	resolver := go_cache.NewDNSResolver(r.Cache, go_cache.WithDNSDial(server.dial))
+
+	for i := 0; i < 2; i++ {
+		_, err := resolver.LookupHost(ctx, "missing.example.test")
+		var dnsErr *net.DNSError
+		if !errors.As(err, &dnsErr) || !dnsErr.IsNotFound {
+			t.Fatalf("LookupHost() error = %v, want not found", err)
+		}
+	}
+	before := server.queries.Load()
+	if _, err := resolver.LookupHost(ctx, "missing.example.test"); err == nil {
+		t.Fatal("应返回错误")
+	}
+	if server.queries.Load() != before {
+		t.Error("否定缓存有效期内不应查询服务器")
+	}
+	if ttl, err := r.Cache.TTL(ctx, "dns:missing.example.test"); err != nil || ttl > 5*time.Second {
+		t.Errorf("否定缓存的有效期应来自SOA记录，TTL() = %v, %v", ttl, err)
+	}
+}
@@ -0,0 +1,237 @@
+package test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/testutil"
+	"github.com/muleiwu/gsr"
+)
+
+// TestOpenBuiltinDrivers 测试通过驱动名称与dsn创建内置缓存
+func TestOpenBuiltinDrivers(t *testing.T) {
+	r, cleanup := testutil.NewRedis(t, testutil.WithBackend(testutil.BackendMiniredis))
+	defer cleanup()
+
+	tests := []struct {
+		name    string
+		driver  string
+		dsn     string
+		wantErr bool
+	}{
+		{name: "memory默认参数", driver: "memory", dsn: "memory://"},
+		{name: "memory自定义参数", driver: "memory", dsn: "memory://?default_expiration=1m&cleanup_interval=0s&max_bytes=1024"},
+		{name: "memory非法参数", driver: "memory", dsn: "memory://?max_bytes=lots", wantErr: true},
+		{name: "redis", driver: "redis", dsn: "redis://" + r.Server.Addr() + "/0?serializer=json"},
+		{name: "redis未知序列化器", driver: "redis", dsn: "redis://" + r.Server.Addr() + "/0?serializer=xml", wantErr: true},
+		{name: "none", driver: "none"},
+		{name: "none pass-through", driver: "none", dsn: "none://?mode=passthrough"},
+		{name: "none非法模式", driver: "none", dsn: "none://?mode=off", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := go_cache.Open(tt.driver, tt.dsn)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Open() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil || tt.driver == "none" {
+				return
+			}
+
+			ctx := context.Background()
+			if err := cache.Set(ctx, "k", "v", time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			var s string
+			if err := cache.Get(ctx, "k", &s); err != nil || s != "v" {
+				t.Errorf("Get() = %q, %v", s, err)
+			}
+		})
+	}
+}
+
+// TestRegisterDriver 测试注册外部驱动
+func TestRegisterDriver(t *testing.T) {
+	var gotDSN string
+	go_cache.Register("test-driver", func(dsn string) (gsr.Cacher, error) {
+		gotDSN = dsn
+		return go_cache.NewNone(), nil
+	})
+
+	if _, err := go_cache.Open("test-driver", "custom://x"); err != nil || gotDSN != "custom://x" {
+		t.Errorf("Open() error = %v, dsn = %q", err, gotDSN)
+	}
+	if !slices.Contains(go_cache.Drivers(), "test-driver") {
+		t.Errorf("Drivers() 应包含 test-driver: %v", go_cache.Drivers())
+	}
+
+	if _, err := go_cache.Open("missing", ""); !errors.Is(err, go_cache.ErrUnknownDriver) {
+		t.Errorf("未注册的驱动应返回 ErrUnknownDriver，实际为 %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("重复注册应panic")
+		}
+	}()
+	go_cache.Register("test-driver", func(dsn string) (gsr.Cacher, error) { return nil, nil })
+}
+
+// writeTestCerts 在dir中生成自签名的CA与由其签发的证书（同时用于服务端与客户端），返回文件路径
+func writeTestCerts(t *testing.T, dir string) (caFile, certFile, keyFile string) {
+	t.Helper()
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caTmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "go-cache test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTmpl, caTmpl, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCert, _ := x509.ParseCertificate(caDER)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, caCert, &key.PublicKey, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(name, typ string, der []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	return write("ca.pem", "CERTIFICATE", caDER), write("cert.pem", "CERTIFICATE", der), write("key.pem", "EC PRIVATE KEY", keyDER)
+}
+
+// TestOpenRedisTLS 测试redis驱动的TLS参数：CA、客户端证书、服务端名称与跳过校验
+func TestOpenRedisTLS(t *testing.T) {
+	caFile, certFile, keyFile := writeTestCerts(t, t.TempDir())
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caPEM, _ := os.ReadFile(caFile)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AppendCertsFromPEM(caPEM)
+
+	// 服务端要求客户端证书
+	server, err := miniredis.RunTLS(&tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	base := "redis://" + server.Addr() + "/0"
+	clientCert := "&tls_cert=" + certFile + "&tls_key=" + keyFile
+
+	tests := []struct {
+		name        string
+		dsn         string
+		wantOpenErr bool
+		wantErr     bool
+	}{
+		{name: "CA与客户端证书", dsn: base + "?tls_server_name=localhost&tls_ca=" + caFile + clientCert},
+		{name: "rediss与CA", dsn: "rediss://" + server.Addr() + "/0?tls_server_name=localhost&tls_ca=" + caFile + clientCert},
+		{name: "跳过校验", dsn: base + "?skip_verify=true" + clientCert},
+		{name: "缺少CA时校验失败", dsn: base + "?tls_server_name=localhost" + clientCert, wantErr: true},
+		{name: "缺少客户端证书", dsn: base + "?tls_server_name=localhost&tls_ca=" + caFile, wantErr: true},
+		{name: "不使用TLS", dsn: base, wantErr: true},
+		{name: "只有证书没有私钥", dsn: base + "?tls_cert=" + certFile, wantOpenErr: true},
+		{name: "CA文件不存在", dsn: base + "?tls_ca=" + caFile + ".missing", wantOpenErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, err := go_cache.Open("redis", tt.dsn)
+			if (err != nil) != tt.wantOpenErr {
+				t.Fatalf("Open() error = %v, wantOpenErr %v", err, tt.wantOpenErr)
+			}
+			if err != nil {
+				return
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			defer cancel()
+			if err := cache.Set(ctx, "k", "v", time.Minute); (err != nil) != tt.wantErr {
+				t.Errorf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestOpenRedisOptions 测试连接池、超时参数的透传与集群模式
+func TestOpenRedisOptions(t *testing.T) {
+	r, cleanup := testutil.NewRedis(t, testutil.WithBackend(testutil.BackendMiniredis))
+	defer cleanup()
+	ctx := context.Background()
+
+	if _, err := go_cache.Open("redis", "redis://"+r.Server.Addr()+"/0?pool_size=lots"); err == nil {
+		t.Error("非法的 pool_size 应返回错误")
+	}
+
+	for _, dsn := range []string{
+		"redis://" + r.Server.Addr() + "/0?pool_size=4&min_idle_conns=1&read_timeout=2s&write_timeout=2s&dial_timeout=1s",
+		"redis://" + r.Server.Addr() + "?cluster=true&pool_size=4&read_timeout=2s",
+	} {
+		cache, err := go_cache.Open("redis", dsn)
+		if err != nil {
+			t.Fatalf("Open(%s) error = %v", dsn, err)
+		}
+		if err := cache.Set(ctx, "opts:k", "v", time.Minute); err != nil {
+			t.Fatalf("Open(%s) Set() error = %v", dsn, err)
+		}
+		keys, err := cache.(go_cache.PatternCache).Keys(ctx, "opts:*")
+		if err != nil || len(keys) != 1 {
+			t.Errorf("Open(%s) Keys() = %v, %v", dsn, keys, err)
+		}
+		if n, err := cache.(go_cache.PatternCache).DelByPattern(ctx, "opts:*"); err != nil || n != 1 {
+			t.Errorf("Open(%s) DelByPattern() = %d, %v", dsn, n, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/gocachetest"
+	"github.com/muleiwu/gsr"
+)
+
+// fakeDynamoDB 内存中的DynamoDB表，只实现缓存用到的表达式
+// 与真实DynamoDB一致，过期条目不会被立即删除
+type fakeDynamoDB struct {
+	mu    sync.Mutex
+	items map[string]map[string]types.AttributeValue
+}
+
+func newFakeDynamoDB() *fakeDynamoDB {
+	return &fakeDynamoDB{items: make(map[string]map[string]types.AttributeValue)}
+}
+
+// itemID 返回主键对应的字符串
+func itemID(key map[string]types.AttributeValue) string {
+	for name, v := range key {
+		return name + "=" + v.(*types.AttributeValueMemberS).Value
+	}
+	return ""
+}
+
+func (f *fakeDynamoDB) GetItem(ctx context.Context, params *dynamodb.GetItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.GetItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return &dynamodb.GetItemOutput{Item: f.items[itemID(params.Key)]}, nil
+}
+
+// PutItem 支持 SetNX 使用的条件：条目不存在或已过期
+func (f *fakeDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for name, v := range params.Item {
+		if _, ok := v.(*types.AttributeValueMemberS); ok {
+			id := itemID(map[string]types.AttributeValue{name: v})
+			if item, exists := f.items[id]; exists && params.ConditionExpression != nil {
+				names, values := params.ExpressionAttributeNames, params.ExpressionAttributeValues
+				ms, ok := item[names["#ms"]].(*types.AttributeValueMemberN)
+				if !ok || numberLess(values[":now"], ms) {
+					return nil, &types.ConditionalCheckFailedException{}
+				}
+			}
+			f.items[id] = params.Item
+			break
+		}
+	}
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.items, itemID(params.Key))
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+// UpdateItem 支持 "SET #a = :a, ..." 与缓存使用的过期条件
+func (f *fakeDynamoDB) UpdateItem(ctx context.Context, params *dynamodb.UpdateItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.UpdateItemOutput, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	item, ok := f.items[itemID(params.Key)]
+	if !ok {
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+	names, values := params.ExpressionAttributeNames, params.ExpressionAttributeValues
+	if ms, ok := item[names["#ms"]].(*types.AttributeValueMemberN); ok && !numberLess(values[":now"], ms) {
+		return nil, &types.ConditionalCheckFailedException{}
+	}
+
+	updated := make(map[string]types.AttributeValue, len(item))
+	for k, v := range item {
+		updated[k] = v
+	}
+	for _, assign := range strings.Split(strings.TrimPrefix(*params.UpdateExpression, "SET "), ",") {
+		name, value, _ := strings.Cut(assign, "=")
+		updated[names[strings.TrimSpace(name)]] = values[strings.TrimSpace(value)]
+	}
+	f.items[itemID(params.Key)] = updated
+	return &dynamodb.UpdateItemOutput{}, nil
+}
+
+func numberLess(a types.AttributeValue, b *types.AttributeValueMemberN) bool {
+	x, _ := strconv.ParseInt(a.(*types.AttributeValueMemberN).Value, 10, 64)
+	y, _ := strconv.ParseInt(b.Value, 10, 64)
+	return x < y
+}
+
+// TestDynamoDBConformance 对DynamoDB缓存运行一致性测试
+func TestDynamoDBConformance(t *testing.T) {
+	gocachetest.RunConformance(t, func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+		clock := go_cache.NewFakeClock(time.Time{})
+		return go_cache.NewDynamoDB(newFakeDynamoDB(), "cache", go_cache.WithDynamoDBClock(clock)), clock.Advance
+	})
+}
+
+// TestDynamoDBTTLAttribute 测试TTL属性以秒写入并向上取整
+func TestDynamoDBTTLAttribute(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeDynamoDB()
+	clock := go_cache.NewFakeClock(time.Unix(1000, 0))
+	cache := go_cache.NewDynamoDB(client, "cache",
+		go_cache.WithDynamoDBClock(clock),
+		go_cache.WithDynamoDBAttributes("pk", "", "ttl"))
+
+	if err := cache.Set(ctx, "k", "v", 1500*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	item := client.items["pk=k"]
+	if item == nil {
+		t.Fatal("条目应使用自定义的键属性名")
+	}
+	if ttl := item["ttl"].(*types.AttributeValueMemberN).Value; ttl != "1002" {
+		t.Errorf("TTL属性 = %s, want 1002", ttl)
+	}
+	if _, ok := item["value"].(*types.AttributeValueMemberB); !ok {
+		t.Error("值应以二进制属性保存")
+	}
+
+	if err := cache.Set(ctx, "forever", "v", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if _, ok := client.items["pk=forever"]["ttl"]; ok {
+		t.Error("TTL为0时不应写入TTL属性")
+	}
+}
+
+// TestDynamoDBExpiredNotRevived 测试尚未被DynamoDB删除的过期条目不会被ExpiresIn恢复
+func TestDynamoDBExpiredNotRevived(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewDynamoDB(newFakeDynamoDB(), "cache", go_cache.WithDynamoDBClock(clock))
+
+	if err := cache.Set(ctx, "k", "v", time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	clock.Advance(2 * time.Second)
+
+	if err := cache.ExpiresIn(ctx, "k", time.Hour); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("ExpiresIn() error = %v, want ErrKeyNotFound", err)
+	}
+	if stats := cache.Stats(); stats.Hits != 0 {
+		t.Errorf("Hits = %d, want 0", stats.Hits)
+	}
+}
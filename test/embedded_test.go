@@ -0,0 +1,75 @@
+package test
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/gocachetest"
+	"github.com/muleiwu/gsr"
+)
+
+// newEmbeddedTest 在临时目录中创建嵌入式缓存
+func newEmbeddedTest(t *testing.T, path string, opts ...go_cache.EmbeddedOption) *go_cache.Embedded {
+	t.Helper()
+
+	cache, err := go_cache.NewEmbedded(path, opts...)
+	if err != nil {
+		t.Fatalf("NewEmbedded() error = %v", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+	return cache
+}
+
+// TestEmbeddedConformance 对嵌入式缓存运行一致性测试
+func TestEmbeddedConformance(t *testing.T) {
+	gocachetest.RunConformance(t, func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+		clock := go_cache.NewFakeClock(time.Time{})
+		path := filepath.Join(t.TempDir(), "cache.db")
+		return newEmbeddedTest(t, path, go_cache.WithEmbeddedClock(clock)), clock.Advance
+	})
+}
+
+// TestEmbeddedPersistence 测试重新打开后数据仍然存在，且打开时清理过期条目
+func TestEmbeddedPersistence(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cache.db")
+	clock := go_cache.NewFakeClock(time.Time{})
+
+	cache, err := go_cache.NewEmbedded(path, go_cache.WithEmbeddedClock(clock))
+	if err != nil {
+		t.Fatalf("NewEmbedded() error = %v", err)
+	}
+	if err := cache.Set(ctx, "keep", "v", time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cache.Set(ctx, "short", "v", time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	cache = newEmbeddedTest(t, path, go_cache.WithEmbeddedClock(clock))
+
+	var v string
+	if err := cache.Get(ctx, "keep", &v); err != nil || v != "v" {
+		t.Errorf("Get() = %q, %v", v, err)
+	}
+	if n, err := cache.DeleteExpired(); err != nil || n != 0 {
+		t.Errorf("打开时应已清理过期条目，DeleteExpired() = %d, %v", n, err)
+	}
+}
+
+// TestEmbeddedSingleProcess 测试文件被占用时打开超时
+func TestEmbeddedSingleProcess(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cache.db")
+	newEmbeddedTest(t, path)
+
+	if _, err := go_cache.NewEmbedded(path, go_cache.WithEmbeddedOpenTimeout(50*time.Millisecond)); err == nil {
+		t.Error("文件已被打开时 NewEmbedded 应返回错误")
+	}
+}
@@ -0,0 +1,109 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestErrorClassification 测试Redis缓存返回的错误带有分类
+func TestErrorClassification(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	// 未命中
+	var s string
+	err := r.Cache.Get(ctx, "missing", &s)
+	if !errors.Is(err, go_cache.ErrKeyNotFound) || go_cache.IsRetryable(err) {
+		t.Errorf("未命中: err = %v, retryable = %v", err, go_cache.IsRetryable(err))
+	}
+
+	// 无法解码的值
+	if err := r.Client.Set(ctx, "corrupt", "not gob", 0).Err(); err != nil {
+		t.Fatalf("Client.Set() error = %v", err)
+	}
+	err = r.Cache.Get(ctx, "corrupt", &s)
+	if !errors.Is(err, go_cache.ErrSerialization) || go_cache.IsRetryable(err) {
+		t.Errorf("解码失败: err = %v, retryable = %v", err, go_cache.IsRetryable(err))
+	}
+
+	// 无法序列化的值
+	err = r.Cache.Set(ctx, "func", func() {}, time.Minute)
+	if !errors.Is(err, go_cache.ErrSerialization) {
+		t.Errorf("序列化失败: err = %v", err)
+	}
+
+	// 上下文超时
+	expired, cancel := context.WithDeadline(ctx, time.Now().Add(-time.Second))
+	defer cancel()
+	err = r.Cache.Set(expired, "k", "v", time.Minute)
+	if !errors.Is(err, go_cache.ErrTimeout) || !errors.Is(err, context.DeadlineExceeded) || !go_cache.IsRetryable(err) {
+		t.Errorf("超时: err = %v, retryable = %v", err, go_cache.IsRetryable(err))
+	}
+
+	// 服务端错误
+	if err := r.Client.LPush(ctx, "list", "x").Err(); err != nil {
+		t.Fatalf("Client.LPush() error = %v", err)
+	}
+	err = r.Cache.Get(ctx, "list", &s)
+	if !errors.Is(err, go_cache.ErrBackend) || go_cache.IsRetryable(err) {
+		t.Errorf("WRONGTYPE: err = %v, retryable = %v", err, go_cache.IsRetryable(err))
+	}
+}
+
+// TestErrorUnavailable 测试连接失败归类为 ErrUnavailable
+func TestErrorUnavailable(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1})
+	defer client.Close()
+	cache := go_cache.NewRedis(client)
+
+	err = cache.Set(context.Background(), "k", "v", time.Minute)
+	if !errors.Is(err, go_cache.ErrUnavailable) || !go_cache.IsRetryable(err) {
+		t.Errorf("err = %v, retryable = %v", err, go_cache.IsRetryable(err))
+	}
+	var opErr *net.OpError
+	if !errors.As(err, &opErr) {
+		t.Errorf("应能取得原始错误: %v", err)
+	}
+}
+
+// TestIsRetryable 测试未经分类的错误也能判断是否可重试
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{nil, false},
+		{go_cache.ErrKeyNotFound, false},
+		{context.Canceled, false},
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("wrapped: %w", context.DeadlineExceeded), true},
+		{go_cache.ErrLoaderTimeout, true},
+		{redis.ErrPoolTimeout, true},
+		{errors.New("something else"), false},
+		{go_cache.ErrSerialization, false},
+		{go_cache.ErrUnavailable, true},
+	}
+	for _, tt := range tests {
+		if got := go_cache.IsRetryable(tt.err); got != tt.want {
+			t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+
+	if !errors.Is(go_cache.ErrLoaderTimeout, go_cache.ErrTimeout) {
+		t.Error("ErrLoaderTimeout 应满足 errors.Is(err, ErrTimeout)")
+	}
+}
@@ -0,0 +1,127 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestETagCache 测试按内容摘要的条件读取
+func TestETagCache(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			cache := go_cache.NewETagCache(backend)
+			if err := cache.Set(ctx, "report", []string{"a", "b"}, time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			var got []string
+			etag, err := cache.GetIfChanged(ctx, "report", "", &got)
+			if err != nil || etag == "" || len(got) != 2 {
+				t.Fatalf("GetIfChanged() = %q, %v, %v", etag, got, err)
+			}
+
+			var unchanged []string
+			same, err := cache.GetIfChanged(ctx, "report", etag, &unchanged)
+			if !errors.Is(err, go_cache.ErrNotModified) || same != etag || unchanged != nil {
+				t.Errorf("内容未变化时 GetIfChanged() = %q, %v, %v，期望为 ErrNotModified", same, unchanged, err)
+			}
+
+			// 写入相同的内容时摘要不变
+			_ = cache.Set(ctx, "report", []string{"a", "b"}, time.Minute)
+			if _, err := cache.GetIfChanged(ctx, "report", etag, &unchanged); !errors.Is(err, go_cache.ErrNotModified) {
+				t.Errorf("写入相同内容后 GetIfChanged() error = %v，期望为 ErrNotModified", err)
+			}
+
+			_ = cache.Set(ctx, "report", []string{"a", "b", "c"}, time.Minute)
+			changed, err := cache.GetIfChanged(ctx, "report", etag, &got)
+			if err != nil || changed == etag || len(got) != 3 {
+				t.Errorf("内容变化后 GetIfChanged() = %q, %v, %v", changed, got, err)
+			}
+
+			// 绕过包装写入的新值同样得到新的摘要
+			if err := backend.Set(ctx, "report", []string{"x"}, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			bypassed, err := cache.GetIfChanged(ctx, "report", changed, &got)
+			if err != nil || bypassed == changed || len(got) != 1 || got[0] != "x" {
+				t.Errorf("绕过包装写入后 GetIfChanged() = %q, %v, %v", bypassed, got, err)
+			}
+
+			_ = cache.Del(ctx, "report")
+			if _, err := cache.GetIfChanged(ctx, "report", bypassed, &got); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("删除后 GetIfChanged() error = %v，期望为 ErrKeyNotFound", err)
+			}
+
+			// 绕过包装写入的值按内容计算摘要
+			_ = backend.Set(ctx, "raw", "payload", time.Minute)
+			var raw string
+			rawETag, err := cache.GetIfChanged(ctx, "raw", "", &raw)
+			if err != nil || rawETag == "" || raw != "payload" {
+				t.Fatalf("GetIfChanged() = %q, %q, %v", rawETag, raw, err)
+			}
+			if _, err := cache.GetIfChanged(ctx, "raw", rawETag, &raw); !errors.Is(err, go_cache.ErrNotModified) {
+				t.Errorf("没有摘要的值 GetIfChanged() error = %v，期望为 ErrNotModified", err)
+			}
+		})
+	}
+}
+
+// TestETagCacheMapValue 测试map类型的值内容未变化时摘要保持不变
+func TestETagCacheMapValue(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	type report struct {
+		Counts map[string]int
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			cache := go_cache.NewETagCache(backend)
+			value := report{Counts: map[string]int{}}
+			for i := 0; i < 32; i++ {
+				value.Counts[fmt.Sprintf("k%d", i)] = i
+			}
+			if err := cache.Set(ctx, "counts", value, time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			var got report
+			etag, err := cache.GetIfChanged(ctx, "counts", "", &got)
+			if err != nil || len(got.Counts) != 32 {
+				t.Fatalf("GetIfChanged() = %q, %v, %v", etag, got, err)
+			}
+			for i := 0; i < 20; i++ {
+				var unchanged report
+				if same, err := cache.GetIfChanged(ctx, "counts", etag, &unchanged); !errors.Is(err, go_cache.ErrNotModified) {
+					t.Fatalf("内容未变化时 GetIfChanged() = %q, %v，期望为 ErrNotModified", same, err)
+				}
+			}
+
+			value.Counts["k0"] = 100
+			_ = cache.Set(ctx, "counts", value, time.Minute)
+			if changed, err := cache.GetIfChanged(ctx, "counts", etag, &got); err != nil || changed == etag || got.Counts["k0"] != 100 {
+				t.Errorf("内容变化后 GetIfChanged() = %q, %v, %v", changed, got.Counts["k0"], err)
+			}
+		})
+	}
+}
@@ -0,0 +1,248 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// newPolicyMemory 创建最多容纳3个条目的内存缓存
+func newPolicyMemory(policy func() go_cache.EvictionPolicy) *go_cache.Memory {
+	return go_cache.NewMemory(time.Minute, time.Minute,
+		go_cache.WithMemorySizer(func(value any) int64 { return 10 }),
+		go_cache.WithMemoryMaxBytes(30),
+		go_cache.WithEvictionPolicy(policy),
+	)
+}
+
+// remainingKeys 返回缓存中的键，按名称排序
+func remainingKeys(t *testing.T, cache *go_cache.Memory) []string {
+	t.Helper()
+	keys, err := cache.Keys(context.Background(), "*")
+	if err != nil {
+		t.Fatalf("Keys() error = %v", err)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// TestEvictionPolicies 测试内置淘汰策略选出的淘汰键
+func TestEvictionPolicies(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		policy func() go_cache.EvictionPolicy
+		reads  []string
+		want   []string
+	}{
+		// 读取不影响写入顺序，淘汰最早写入的a
+		{"FIFO", go_cache.NewFIFOPolicy, []string{"a"}, []string{"b", "c", "d"}},
+		// a 被读取，最久未使用的是b
+		{"LRU", go_cache.NewLRUPolicy, []string{"a"}, []string{"a", "c", "d"}},
+		// a 读取两次、b 读取一次，访问次数最少的是c
+		{"LFU", go_cache.NewLFUPolicy, []string{"a", "b", "a"}, []string{"a", "b", "d"}},
+		// a、b 被再次访问进入t2，只访问过一次的c先被淘汰
+		{"ARC", go_cache.NewARCPolicy, []string{"b", "a"}, []string{"a", "b", "d"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newPolicyMemory(tt.policy)
+			for _, key := range []string{"a", "b", "c"} {
+				_ = cache.Set(ctx, key, key, 0)
+			}
+			var s string
+			for _, key := range tt.reads {
+				if err := cache.Get(ctx, key, &s); err != nil {
+					t.Fatalf("Get(%s) error = %v", key, err)
+				}
+			}
+			_ = cache.Set(ctx, "d", "d", 0)
+
+			got := remainingKeys(t, cache)
+			if len(got) != len(tt.want) {
+				t.Fatalf("剩余的键为%v，期望为%v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("剩余的键为%v，期望为%v", got, tt.want)
+				}
+			}
+			if stats := cache.Stats(); stats.Evicted != 1 || stats.MemoryBytes != 30 {
+				t.Errorf("统计不正确: evicted=%d bytes=%d", stats.Evicted, stats.MemoryBytes)
+			}
+		})
+	}
+}
+
+// TestEvictionPolicyOverwrite 测试覆盖已有的键保留淘汰策略中的状态，计为一次访问
+func TestEvictionPolicyOverwrite(t *testing.T) {
+	ctx := context.Background()
+	for name, policy := range map[string]func() go_cache.EvictionPolicy{
+		"LFU": go_cache.NewLFUPolicy,
+		"ARC": go_cache.NewARCPolicy,
+	} {
+		t.Run(name, func(t *testing.T) {
+			cache := newPolicyMemory(policy)
+			for _, key := range []string{"a", "b", "c"} {
+				_ = cache.Set(ctx, key, key, 0)
+			}
+			var s string
+			for _, key := range []string{"a", "b", "c"} {
+				_ = cache.Get(ctx, key, &s)
+			}
+			// 覆盖a不重置访问次数（LFU）、不移出t2（ARC），只访问过一次的d先被淘汰
+			_ = cache.Set(ctx, "a", "a2", 0)
+			_ = cache.Set(ctx, "d", "d", 0)
+
+			if got := remainingKeys(t, cache); fmt.Sprint(got) != "[a b c]" {
+				t.Errorf("剩余的键为%v，期望为[a b c]", got)
+			}
+			if stats := cache.Stats(); stats.MemoryBytes != 30 {
+				t.Errorf("MemoryBytes = %d，期望为30", stats.MemoryBytes)
+			}
+		})
+	}
+}
+
+// TestARCPolicyScanResistance 测试一次性扫描不会冲掉ARC中的热点数据
+func TestARCPolicyScanResistance(t *testing.T) {
+	ctx := context.Background()
+	cache := newPolicyMemory(go_cache.NewARCPolicy)
+
+	var s string
+	for _, key := range []string{"hot1", "hot2"} {
+		_ = cache.Set(ctx, key, key, 0)
+		_ = cache.Get(ctx, key, &s)
+	}
+	for _, key := range []string{"scan1", "scan2", "scan3", "scan4", "scan5"} {
+		_ = cache.Set(ctx, key, key, 0)
+	}
+
+	got := remainingKeys(t, cache)
+	if len(got) != 3 || got[0] != "hot1" || got[1] != "hot2" || got[2] != "scan5" {
+		t.Errorf("扫描后剩余的键为%v，热点数据应保留", got)
+	}
+
+	lru := newPolicyMemory(go_cache.NewLRUPolicy)
+	for _, key := range []string{"hot1", "hot2"} {
+		_ = lru.Set(ctx, key, key, 0)
+		_ = lru.Get(ctx, key, &s)
+	}
+	for _, key := range []string{"scan1", "scan2", "scan3"} {
+		_ = lru.Set(ctx, key, key, 0)
+	}
+	if got := remainingKeys(t, lru); got[0] != "scan1" {
+		t.Errorf("LRU 扫描后剩余的键为%v，热点数据应被冲掉", got)
+	}
+}
+
+// TestEvictionPolicyPriority 测试淘汰策略只在同一优先级内选择，低优先级总是先被淘汰
+func TestEvictionPolicyPriority(t *testing.T) {
+	ctx := context.Background()
+	cache := newPolicyMemory(go_cache.NewLRUPolicy)
+
+	_ = cache.SetWithPriority(ctx, "low", "v", 0, go_cache.PriorityLow)
+	_ = cache.Set(ctx, "a", "v", 0)
+	_ = cache.Set(ctx, "b", "v", 0)
+
+	var s string
+	_ = cache.Get(ctx, "a", &s)
+	_ = cache.Set(ctx, "c", "v", 0) // 淘汰 low
+	_ = cache.Set(ctx, "d", "v", 0) // 淘汰同一优先级中最久未使用的b
+
+	got := remainingKeys(t, cache)
+	if len(got) != 3 || got[0] != "a" || got[1] != "c" || got[2] != "d" {
+		t.Errorf("剩余的键为%v，期望为[a c d]", got)
+	}
+}
+
+// TestLFUPolicy 测试LFU策略在访问次数相同时淘汰最久未访问的键，删除的键不会被选中
+func TestLFUPolicy(t *testing.T) {
+	p := go_cache.NewLFUPolicy()
+	for _, key := range []string{"a", "b", "c"} {
+		p.Add(key)
+	}
+	p.Access("a")
+	p.Access("b")
+	p.Access("a")
+	p.Access("missing")
+
+	if key, _ := p.Victim(); key != "c" {
+		t.Fatalf("Victim() = %s，期望为c", key)
+	}
+	p.Remove("c")
+	// a 访问3次，b 访问2次
+	if key, _ := p.Victim(); key != "b" {
+		t.Fatalf("Victim() = %s，期望为b", key)
+	}
+	p.Remove("b")
+	p.Remove("a")
+	if _, ok := p.Victim(); ok {
+		t.Error("没有键时 Victim() 应返回false")
+	}
+}
+
+// TestMemoryCostFunc 测试设置成本后淘汰单位大小成本低的条目，成本低的大条目不被接纳
+func TestMemoryCostFunc(t *testing.T) {
+	ctx := context.Background()
+	var evicted []string
+	cache := go_cache.NewMemory(time.Minute, time.Minute,
+		go_cache.WithMemorySizer(func(value any) int64 { return int64(len(value.(string))) }),
+		go_cache.WithMemoryMaxBytes(100),
+		go_cache.WithMemoryCostFunc(func(key string, value any) int64 {
+			if key[:5] == "flag:" {
+				return 100 // 回源代价高
+			}
+			return 1
+		}),
+		go_cache.WithEvictionCallback(func(key string, value any, reason go_cache.EvictionReason) {
+			evicted = append(evicted, key)
+		}),
+	)
+
+	for _, key := range []string{"flag:a", "flag:b", "flag:c"} {
+		_ = cache.Set(ctx, key, "on", 0)
+	}
+	_ = cache.Set(ctx, "report:1", string(make([]byte, 60)), 0)
+	// 第二个报表放不下，淘汰成本密度最低的报表而不是开关
+	_ = cache.Set(ctx, "report:2", string(make([]byte, 60)), 0)
+
+	got := remainingKeys(t, cache)
+	if len(got) != 4 || got[0] != "flag:a" || got[3] != "report:2" {
+		t.Errorf("剩余的键为%v，开关应全部保留", got)
+	}
+
+	// 成本密度低的大条目挤掉报表后仍然放不下，写入后立即被淘汰而不是挤掉开关
+	_ = cache.Set(ctx, "report:3", string(make([]byte, 95)), 0)
+	if got := remainingKeys(t, cache); len(got) != 3 || got[2] != "flag:c" {
+		t.Errorf("成本低的大条目不应被接纳，剩余的键为%v", got)
+	}
+	if fmt.Sprint(evicted) != "[report:1 report:2 report:3]" {
+		t.Errorf("淘汰的键为%v", evicted)
+	}
+}
+
+// TestGreedyDualPolicyAging 测试贪心对偶策略中长时间未访问的键随淘汰逐渐老化
+func TestGreedyDualPolicyAging(t *testing.T) {
+	p := go_cache.NewGreedyDualPolicy().(go_cache.CostPolicy)
+	p.AddWithCost("old", 10, 1)
+
+	// 每次淘汰都把L提高到被淘汰的键的优先级，新加入的键的优先级最终超过长时间未访问的old
+	for round := 1; round <= 20; round++ {
+		p.AddWithCost(fmt.Sprint("k", round), 1, 1)
+		victim, _ := p.Victim()
+		if victim == "old" {
+			if round < 10 {
+				t.Errorf("成本高的old在第%d轮就被淘汰", round)
+			}
+			return
+		}
+		p.Remove(victim)
+	}
+	t.Error("长时间未访问的old应被淘汰")
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// evictionRecorder 记录移除回调
+type evictionRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *evictionRecorder) record(key string, value any, reason go_cache.EvictionReason) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf("%s=%v:%s", key, value, reason))
+}
+
+func (r *evictionRecorder) take() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	events := r.events
+	r.events = nil
+	return events
+}
+
+// TestMemoryEvictionCallback 测试各种移除原因的回调
+func TestMemoryEvictionCallback(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	rec := &evictionRecorder{}
+	cache := go_cache.NewMemory(time.Minute, 0,
+		go_cache.WithMemoryClock(clock),
+		go_cache.WithMemorySizer(func(value any) int64 { return 10 }),
+		go_cache.WithMemoryMaxBytes(30),
+		go_cache.WithEvictionCallback(rec.record),
+	)
+
+	steps := []struct {
+		name string
+		do   func()
+		want []string
+	}{
+		{"删除", func() {
+			_ = cache.Set(ctx, "a", "1", 0)
+			_ = cache.Del(ctx, "a")
+		}, []string{"a=1:deleted"}},
+		{"覆盖", func() {
+			_ = cache.Set(ctx, "b", "1", 0)
+			_ = cache.Set(ctx, "b", "2", 0)
+		}, []string{"b=1:replaced"}},
+		{"过期", func() {
+			_ = cache.Set(ctx, "f", "1", time.Second)
+			clock.Advance(2 * time.Second)
+			cache.DeleteExpired()
+		}, []string{"f=1:expired"}},
+		{"重命名不触发", func() {
+			_ = cache.Rename(ctx, "b", "b2")
+		}, nil},
+		{"设置过去的过期时间", func() {
+			_ = cache.ExpiresAt(ctx, "b2", clock.Now().Add(-time.Second))
+		}, []string{"b2=2:expired"}},
+		{"淘汰", func() {
+			_ = cache.Set(ctx, "c", "1", 0)
+			_ = cache.Set(ctx, "d", "1", 0)
+			_ = cache.Set(ctx, "e", "1", 0)
+			_ = cache.Set(ctx, "g", "1", 0)
+		}, []string{"c=1:capacity"}},
+	}
+
+	for _, step := range steps {
+		step.do()
+		got := rec.take()
+		if fmt.Sprint(got) != fmt.Sprint(step.want) {
+			t.Errorf("%s: 回调 = %v, want %v", step.name, got, step.want)
+		}
+	}
+}
+
+// TestMemoryEvictionCallbackReentrant 测试回调中可以访问缓存
+func TestMemoryEvictionCallbackReentrant(t *testing.T) {
+	ctx := context.Background()
+	var cache *go_cache.Memory
+	cache = go_cache.NewMemory(time.Minute, 0, go_cache.WithEvictionCallback(func(key string, value any, reason go_cache.EvictionReason) {
+		_ = cache.Set(ctx, "evicted:"+key, reason.String(), 0)
+	}))
+
+	_ = cache.Set(ctx, "k", "v", 0)
+	_ = cache.Del(ctx, "k")
+
+	var got string
+	if err := cache.Get(ctx, "evicted:k", &got); err != nil || got != "deleted" {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+}
+
+// TestMemoryEvictionCallbackJanitor 测试janitor清理过期条目时触发回调
+func TestMemoryEvictionCallbackJanitor(t *testing.T) {
+	ctx := context.Background()
+	rec := &evictionRecorder{}
+	cache := go_cache.NewMemory(time.Minute, 10*time.Millisecond, go_cache.WithEvictionCallback(rec.record))
+
+	_ = cache.Set(ctx, "k", "v", 20*time.Millisecond)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		rec.mu.Lock()
+		n := len(rec.events)
+		rec.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := rec.take(); fmt.Sprint(got) != "[k=v:expired]" {
+		t.Errorf("回调 = %v", got)
+	}
+}
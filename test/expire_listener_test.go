@@ -0,0 +1,213 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// expiredKeys 收集监听器收到的过期键
+type expiredKeys struct {
+	mu   sync.Mutex
+	keys []string
+	ch   chan string
+}
+
+func newExpiredKeys() *expiredKeys {
+	return &expiredKeys{ch: make(chan string, 16)}
+}
+
+func (e *expiredKeys) record(key string) {
+	e.mu.Lock()
+	e.keys = append(e.keys, key)
+	e.mu.Unlock()
+	e.ch <- key
+}
+
+// wait 等待收到n个过期键，返回排序后的全部键
+func (e *expiredKeys) wait(t *testing.T, n int) []string {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		select {
+		case <-e.ch:
+		case <-time.After(2 * time.Second):
+			t.Fatalf("等待过期事件超时，已收到%v", e.snapshot())
+		}
+	}
+	return e.snapshot()
+}
+
+func (e *expiredKeys) snapshot() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	keys := append([]string(nil), e.keys...)
+	sort.Strings(keys)
+	return keys
+}
+
+// TestExpiryListenersMemory 测试内存缓存的过期键按模式分发给监听器
+func TestExpiryListenersMemory(t *testing.T) {
+	listeners := go_cache.NewExpiryListeners()
+	defer listeners.Close()
+
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	cache := go_cache.NewMemory(time.Minute, time.Hour,
+		go_cache.WithMemoryClock(clock),
+		go_cache.WithMemoryExpiryListeners(listeners),
+	)
+	ctx := context.Background()
+
+	sessions := newExpiredKeys()
+	listeners.OnExpire("session:*", sessions.record)
+	all := newExpiredKeys()
+	listeners.OnExpire("*", all.record)
+
+	_ = cache.Set(ctx, "session:1", "v", time.Second)
+	_ = cache.Set(ctx, "session:2", "v", time.Second)
+	_ = cache.Set(ctx, "user:1", "v", time.Second)
+	_ = cache.Set(ctx, "session:3", "v", time.Hour)
+	_ = cache.Set(ctx, "session:4", "v", 0)
+	_ = cache.Del(ctx, "session:4") // 主动删除不是过期
+
+	clock.Advance(2 * time.Second)
+	cache.DeleteExpired()
+
+	if got := sessions.wait(t, 2); len(got) != 2 || got[0] != "session:1" || got[1] != "session:2" {
+		t.Errorf("session:* 监听器收到%v", got)
+	}
+	if got := all.wait(t, 3); len(got) != 3 || got[2] != "user:1" {
+		t.Errorf("* 监听器收到%v", got)
+	}
+}
+
+// TestExpiryListenersCancel 测试取消注册后不再收到事件
+func TestExpiryListenersCancel(t *testing.T) {
+	listeners := go_cache.NewExpiryListeners(go_cache.WithExpiryWorkers(1))
+
+	var mu sync.Mutex
+	var calls int
+	cancel := listeners.OnExpire("a*", func(key string) {
+		mu.Lock()
+		calls++
+		mu.Unlock()
+	})
+
+	listeners.Notify("a1")
+	listeners.Close() // 等待已入队的事件执行完毕
+	cancel()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 1 {
+		t.Fatalf("监听器调用次数应为1，实际为%d", calls)
+	}
+
+	// 关闭后的事件被丢弃
+	listeners.Notify("a2")
+	if listeners.Dropped() != 1 {
+		t.Errorf("Dropped() = %d，期望为1", listeners.Dropped())
+	}
+}
+
+// TestExpiryListenersPanicIsolation 测试一个监听器panic不影响其他监听器
+func TestExpiryListenersPanicIsolation(t *testing.T) {
+	var mu sync.Mutex
+	var panics []error
+	listeners := go_cache.NewExpiryListeners(
+		go_cache.WithExpiryWorkers(1),
+		go_cache.WithExpiryPanicHook(func(key string, err error) {
+			mu.Lock()
+			panics = append(panics, err)
+			mu.Unlock()
+		}),
+	)
+	defer listeners.Close()
+
+	listeners.OnExpire("*", func(key string) { panic("boom") })
+	got := newExpiredKeys()
+	listeners.OnExpire("*", got.record)
+
+	listeners.Notify("k1")
+	listeners.Notify("k2")
+	if keys := got.wait(t, 2); len(keys) != 2 {
+		t.Fatalf("正常的监听器应收到全部事件，实际为%v", keys)
+	}
+
+	listeners.Close()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(panics) != 2 {
+		t.Fatalf("panic回调次数应为2，实际为%d", len(panics))
+	}
+	var perr *go_cache.ExpiryListenerPanicError
+	if !errors.As(panics[0], &perr) || perr.Value != "boom" || perr.Pattern != "*" {
+		t.Errorf("panic错误不正确: %v", panics[0])
+	}
+}
+
+// TestExpiryListenersQueueFull 测试队列已满时丢弃事件
+func TestExpiryListenersQueueFull(t *testing.T) {
+	listeners := go_cache.NewExpiryListeners(go_cache.WithExpiryWorkers(1), go_cache.WithExpiryQueueSize(1))
+	release := make(chan struct{})
+	started := make(chan struct{}, 1)
+	listeners.OnExpire("*", func(key string) {
+		started <- struct{}{}
+		<-release
+	})
+
+	listeners.Notify("k1")
+	<-started // 唯一的goroutine正在执行k1
+	listeners.Notify("k2")
+	listeners.Notify("k3")
+	if listeners.Dropped() != 1 {
+		t.Errorf("Dropped() = %d，期望为1", listeners.Dropped())
+	}
+	close(release)
+	listeners.Close()
+}
+
+// TestExpiryListenersRedis 测试Redis的过期键事件通知转发给监听器
+func TestExpiryListenersRedis(t *testing.T) {
+	r, cleanup := newRedisTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	listeners := go_cache.NewExpiryListeners()
+	defer listeners.Close()
+	got := newExpiredKeys()
+	listeners.OnExpire("session:*", got.record)
+
+	stop, err := go_cache.WatchRedisExpiry(r.Client, listeners)
+	if err != nil {
+		t.Fatalf("WatchRedisExpiry 失败: %v", err)
+	}
+	defer stop()
+
+	if r.Server != nil {
+		// miniredis 不发出键空间通知，直接发布服务端会发布的事件
+		for _, key := range []string{"user:1", "session:1"} {
+			if err := r.Client.Publish(ctx, "__keyevent@0__:expired", key).Err(); err != nil {
+				t.Fatalf("Publish 失败: %v", err)
+			}
+		}
+	} else {
+		if err := r.Client.ConfigSet(ctx, "notify-keyspace-events", "Ex").Err(); err != nil {
+			t.Skipf("无法开启键空间通知: %v", err)
+		}
+		_ = r.Cache.Set(ctx, "user:1", "v", 50*time.Millisecond)
+		_ = r.Cache.Set(ctx, "session:1", "v", 50*time.Millisecond)
+		time.Sleep(100 * time.Millisecond)
+		// 访问过期键使Redis立即删除并发出事件
+		_ = r.Cache.Exists(ctx, "user:1")
+		_ = r.Cache.Exists(ctx, "session:1")
+	}
+
+	if keys := got.wait(t, 1); len(keys) != 1 || keys[0] != "session:1" {
+		t.Errorf("监听器收到%v，期望为[session:1]", keys)
+	}
+}
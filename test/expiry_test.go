@@ -0,0 +1,152 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// expiryBackend 支持 Persist 与 Touch 的缓存，advance 推进缓存时间
+type expiryBackend struct {
+	cache interface {
+		gsr.Cacher
+		go_cache.ExpiryCache
+	}
+	advance func(time.Duration)
+}
+
+func expiryBackends(t *testing.T) map[string]expiryBackend {
+	clock := go_cache.NewFakeClock(time.Time{})
+	r, _ := newRedisTest(t)
+	return map[string]expiryBackend{
+		"memory": {go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)), clock.Advance},
+		"redis":  {r.Cache, r.FastForward},
+	}
+}
+
+// TestPersist 测试移除过期时间
+func TestPersist(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range expiryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := b.cache.Set(ctx, "persist", "v", time.Second); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if err := b.cache.Persist(ctx, "persist"); err != nil {
+				t.Fatalf("Persist() error = %v", err)
+			}
+			b.advance(time.Hour)
+			if !b.cache.Exists(ctx, "persist") {
+				t.Error("Persist 之后键不应过期")
+			}
+
+			// 没有过期时间的键再次 Persist 不是错误
+			if err := b.cache.Persist(ctx, "persist"); err != nil {
+				t.Errorf("Persist() 无过期时间的键 error = %v", err)
+			}
+			if err := b.cache.Persist(ctx, "persist:missing"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("Persist() 不存在的键 error = %v, want ErrKeyNotFound", err)
+			}
+		})
+	}
+}
+
+// TestTouch 测试只在键存在时刷新过期时间
+func TestTouch(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range expiryBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := b.cache.Set(ctx, "touch", "v", time.Second); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			ok, err := b.cache.Touch(ctx, "touch", time.Minute)
+			if err != nil || !ok {
+				t.Fatalf("Touch() = %v, %v, want true", ok, err)
+			}
+			b.advance(30 * time.Second)
+			if !b.cache.Exists(ctx, "touch") {
+				t.Error("Touch 之后键应在新的过期时间之前存在")
+			}
+			b.advance(time.Minute)
+			if b.cache.Exists(ctx, "touch") {
+				t.Error("键应在新的过期时间之后过期")
+			}
+
+			ok, err = b.cache.Touch(ctx, "touch", time.Minute)
+			if err != nil || ok {
+				t.Errorf("Touch() 不存在的键 = %v, %v, want false", ok, err)
+			}
+			if b.cache.Exists(ctx, "touch") {
+				t.Error("Touch 不应创建键")
+			}
+		})
+	}
+}
+
+// TestExpiresIfConditions 测试按条件设置过期时间
+func TestExpiresIfConditions(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	r, _ := newRedisTest(t)
+
+	backends := map[string]interface {
+		gsr.Cacher
+		go_cache.ConditionalExpiryCache
+	}{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)),
+		"redis":  r.Cache,
+	}
+
+	for name, cache := range backends {
+		t.Run(name, func(t *testing.T) {
+			mustSet := func(key string, ttl time.Duration) {
+				t.Helper()
+				if err := cache.Set(ctx, key, "v", ttl); err != nil {
+					t.Fatalf("Set() error = %v", err)
+				}
+			}
+			mustSet("volatile", time.Minute)
+			mustSet("persistent", 0)
+
+			tests := []struct {
+				name string
+				key  string
+				ttl  time.Duration
+				cond go_cache.ExpireCondition
+				want bool
+			}{
+				{"NX 已有过期时间", "volatile", time.Hour, go_cache.ExpireNX, false},
+				{"NX 没有过期时间", "persistent", time.Hour, go_cache.ExpireNX, true},
+				{"XX 已有过期时间", "volatile", 2 * time.Minute, go_cache.ExpireXX, true},
+				{"GT 缩短", "volatile", time.Second, go_cache.ExpireGT, false},
+				{"GT 延长", "volatile", time.Hour, go_cache.ExpireGT, true},
+				{"LT 延长", "volatile", 2 * time.Hour, go_cache.ExpireLT, false},
+				{"LT 缩短", "volatile", 30 * time.Minute, go_cache.ExpireLT, true},
+				{"不存在的键", "missing", time.Hour, go_cache.ExpireAlways, false},
+			}
+			for _, tt := range tests {
+				got, err := cache.ExpiresInIf(ctx, tt.key, tt.ttl, tt.cond)
+				if err != nil || got != tt.want {
+					t.Errorf("%s: ExpiresInIf() = %v, %v, want %v", tt.name, got, err, tt.want)
+				}
+			}
+
+			// 没有过期时间的键视为有效期无限长
+			mustSet("persistent2", 0)
+			if ok, _ := cache.ExpiresAtIf(ctx, "persistent2", time.Now().Add(time.Hour), go_cache.ExpireGT); ok {
+				t.Error("GT 不应作用于没有过期时间的键")
+			}
+			if ok, _ := cache.ExpiresAtIf(ctx, "persistent2", time.Now().Add(-time.Hour), go_cache.ExpireLT); !ok {
+				t.Error("LT 应作用于没有过期时间的键")
+			}
+			if cache.Exists(ctx, "persistent2") {
+				t.Error("过期时间早于当前时间时键应被删除")
+			}
+		})
+	}
+}
@@ -0,0 +1,237 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// flagSource 测试用的开关数据源，记录加载次数
+type flagSource struct {
+	mu    sync.Mutex
+	flags map[string]go_cache.Flag
+	loads atomic.Int32
+}
+
+func (s *flagSource) LoadFlag(ctx context.Context, name string) (go_cache.Flag, error) {
+	s.loads.Add(1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, ok := s.flags[name]
+	if !ok {
+		return go_cache.Flag{}, go_cache.ErrKeyNotFound
+	}
+	return f, nil
+}
+
+func (s *flagSource) set(name string, f go_cache.Flag) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.flags[name] = f
+}
+
+// TestFlagsIsEnabled 测试开关、允许与拒绝列表
+func TestFlagsIsEnabled(t *testing.T) {
+	ctx := context.Background()
+	source := &flagSource{flags: map[string]go_cache.Flag{
+		"on":   {Enabled: true},
+		"off":  {Enabled: false, Allow: []string{"u1"}},
+		"deny": {Enabled: true, Allow: []string{"u1"}, Deny: []string{"u1", "u2"}},
+	}}
+	flags := go_cache.NewFlags(go_cache.NewMemory(time.Minute, time.Minute), source)
+
+	tests := []struct {
+		flag, subject string
+		want          bool
+	}{
+		{"on", "u1", true},
+		{"off", "u1", false},
+		{"deny", "u1", false},
+		{"deny", "u3", true},
+		{"missing", "u1", false},
+	}
+	for _, tt := range tests {
+		if got := flags.IsEnabled(ctx, tt.flag, tt.subject); got != tt.want {
+			t.Errorf("IsEnabled(%q, %q) = %v, want %v", tt.flag, tt.subject, got, tt.want)
+		}
+	}
+
+	// 命中缓存时不再访问数据源
+	loads := source.loads.Load()
+	flags.IsEnabled(ctx, "on", "u1")
+	if source.loads.Load() != loads {
+		t.Error("cached flag should not be loaded again")
+	}
+}
+
+// TestFlagsRollout 测试按比例灰度开启
+func TestFlagsRollout(t *testing.T) {
+	ctx := context.Background()
+	source := &flagSource{flags: map[string]go_cache.Flag{
+		"half": {Enabled: true, Rollout: 50},
+	}}
+	flags := go_cache.NewFlags(go_cache.NewMemory(time.Minute, time.Minute), source)
+
+	enabled := 0
+	for i := 0; i < 1000; i++ {
+		subject := fmt.Sprintf("user-%d", i)
+		on := flags.IsEnabled(ctx, "half", subject)
+		if on != flags.IsEnabled(ctx, "half", subject) {
+			t.Fatalf("IsEnabled(half, %s) is not stable", subject)
+		}
+		if on {
+			enabled++
+		}
+	}
+	if enabled < 400 || enabled > 600 {
+		t.Errorf("enabled for %d of 1000 subjects, want about 500", enabled)
+	}
+}
+
+// TestFlagsVariant 测试按权重分配变体
+func TestFlagsVariant(t *testing.T) {
+	ctx := context.Background()
+	source := &flagSource{flags: map[string]go_cache.Flag{
+		"checkout": {Enabled: true, Variants: []go_cache.FlagVariant{
+			{Name: "control", Weight: 1},
+			{Name: "new", Weight: 3},
+		}},
+		"plain":    {Enabled: true},
+		"disabled": {Variants: []go_cache.FlagVariant{{Name: "a", Weight: 1}}},
+	}}
+	flags := go_cache.NewFlags(go_cache.NewMemory(time.Minute, time.Minute), source)
+
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		counts[flags.Variant(ctx, "checkout", fmt.Sprintf("user-%d", i))]++
+	}
+	if counts["control"] < 150 || counts["control"] > 350 || counts["control"]+counts["new"] != 1000 {
+		t.Errorf("variant counts = %v, want about 250 control and 750 new", counts)
+	}
+
+	if v := flags.Variant(ctx, "plain", "u1"); v != "" {
+		t.Errorf("Variant(plain) = %q, want empty", v)
+	}
+	if v := flags.Variant(ctx, "disabled", "u1"); v != "" {
+		t.Errorf("Variant(disabled) = %q, want empty", v)
+	}
+}
+
+// TestFlagsRefreshAhead 测试临近过期时在后台刷新
+func TestFlagsRefreshAhead(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	source := &flagSource{flags: map[string]go_cache.Flag{"f": {Enabled: true}}}
+	flags := go_cache.NewFlags(
+		go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)),
+		source,
+		go_cache.WithFlagsTTL(30*time.Second),
+		go_cache.WithFlagsRefreshAhead(10*time.Second),
+		go_cache.WithFlagsClock(clock),
+	)
+
+	if !flags.IsEnabled(ctx, "f", "u1") {
+		t.Fatal("flag should be enabled")
+	}
+	source.set("f", go_cache.Flag{Enabled: false})
+
+	// 仍在有效期内且未进入提前刷新窗口，返回缓存中的值
+	clock.Advance(10 * time.Second)
+	if !flags.IsEnabled(ctx, "f", "u1") || source.loads.Load() != 1 {
+		t.Fatal("flag should be served from cache without refresh")
+	}
+
+	// 进入提前刷新窗口，本次返回旧值并在后台刷新
+	clock.Advance(15 * time.Second)
+	if !flags.IsEnabled(ctx, "f", "u1") {
+		t.Error("stale flag should be served while refreshing")
+	}
+	deadline := time.Now().Add(time.Second)
+	for flags.IsEnabled(ctx, "f", "u1") {
+		if time.Now().After(deadline) {
+			t.Fatal("flag was not refreshed in background")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestFlagsFailureHook 测试数据源失败时的回调与 Lookup 错误
+func TestFlagsFailureHook(t *testing.T) {
+	ctx := context.Background()
+	errSource := errors.New("source down")
+	var hooked error
+	flags := go_cache.NewFlags(
+		go_cache.NewMemory(time.Minute, time.Minute),
+		go_cache.FlagSourceFunc(func(ctx context.Context, name string) (go_cache.Flag, error) {
+			return go_cache.Flag{}, errSource
+		}),
+		go_cache.WithFlagsFailureHook(func(key string, err error) { hooked = err }),
+	)
+
+	if flags.IsEnabled(ctx, "f", "u1") {
+		t.Error("flag should be disabled when source fails")
+	}
+	if !errors.Is(hooked, errSource) {
+		t.Errorf("failure hook got %v, want %v", hooked, errSource)
+	}
+	if _, err := flags.Lookup(ctx, "f"); !errors.Is(err, errSource) {
+		t.Errorf("Lookup() error = %v, want %v", err, errSource)
+	}
+}
+
+// TestFlagsConcurrentMiss 测试缓存未命中时并发读取只加载一次，不存在的开关短时间缓存
+func TestFlagsConcurrentMiss(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	gate := make(chan struct{})
+	source := &flagSource{flags: map[string]go_cache.Flag{"f": {Enabled: true}}}
+	flags := go_cache.NewFlags(
+		go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)),
+		go_cache.FlagSourceFunc(func(ctx context.Context, name string) (go_cache.Flag, error) {
+			<-gate
+			return source.LoadFlag(ctx, name)
+		}),
+		go_cache.WithFlagsClock(clock),
+		go_cache.WithFlagsNotFoundTTL(5*time.Second),
+	)
+
+	var wg sync.WaitGroup
+	var enabled atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if flags.IsEnabled(ctx, "f", "u1") {
+				enabled.Add(1)
+			}
+		}()
+	}
+	// 等待第一个读取开始加载后放行
+	time.Sleep(10 * time.Millisecond)
+	close(gate)
+	wg.Wait()
+	if n := source.loads.Load(); n != 1 || enabled.Load() != 20 {
+		t.Errorf("并发未命中时数据源加载 %d 次，开启 %d 次，期望加载1次、开启20次", n, enabled.Load())
+	}
+
+	// 不存在的开关在有效期内不再访问数据源
+	for i := 0; i < 3; i++ {
+		if _, err := flags.Lookup(ctx, "missing"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+			t.Fatalf("Lookup(missing) error = %v, want ErrKeyNotFound", err)
+		}
+	}
+	if n := source.loads.Load(); n != 2 {
+		t.Errorf("不存在的开关应被缓存，数据源加载 %d 次，期望2次", n)
+	}
+	clock.Advance(6 * time.Second)
+	source.set("missing", go_cache.Flag{Enabled: true})
+	if !flags.IsEnabled(ctx, "missing", "u1") || source.loads.Load() != 3 {
+		t.Errorf("不存在的结果过期后应重新加载，数据源加载 %d 次", source.loads.Load())
+	}
+}
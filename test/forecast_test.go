@@ -0,0 +1,75 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestForecastExpiryMemory 测试内存缓存的过期预测
+func TestForecastExpiryMemory(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "soon1", 1, 500*time.Millisecond)
+	_ = cache.Set(ctx, "soon2", 1, 800*time.Millisecond)
+	_ = cache.Set(ctx, "minute", 1, 30*time.Second)
+	_ = cache.Set(ctx, "hour", 1, 30*time.Minute)
+	_ = cache.Set(ctx, "day", 1, 24*time.Hour)
+	_ = cache.Set(ctx, "forever", 1, 0)
+
+	forecast, err := go_cache.ForecastExpiry(ctx, cache, 100)
+	if err != nil {
+		t.Fatalf("ForecastExpiry() error = %v", err)
+	}
+
+	want := []int{2, 1, 0, 1}
+	for i, bucket := range forecast.Buckets {
+		if bucket.Count != want[i] {
+			t.Errorf("时间段 %s 的条目数应为%d，实际为%d", bucket.Within, want[i], bucket.Count)
+		}
+	}
+	if forecast.Sampled != 6 || forecast.Beyond != 1 || forecast.NoExpiry != 1 {
+		t.Errorf("预测结果不正确: %+v", forecast)
+	}
+	if !strings.Contains(forecast.String(), "no expiry") {
+		t.Errorf("文本输出缺少内容:\n%s", forecast)
+	}
+
+	// 采样数量限制
+	limited, _ := go_cache.ForecastExpiry(ctx, cache, 2)
+	if limited.Sampled != 2 {
+		t.Errorf("采样数应为2，实际为%d", limited.Sampled)
+	}
+}
+
+// TestForecastExpiryNotSupported 测试不支持采样的缓存
+func TestForecastExpiryNotSupported(t *testing.T) {
+	_, err := go_cache.ForecastExpiry(context.Background(), go_cache.NewNone(), 10)
+	if !errors.Is(err, go_cache.ErrNotSupported) {
+		t.Errorf("应返回 ErrNotSupported，实际为 %v", err)
+	}
+}
+
+// TestForecastExpiryRedis 测试Redis的过期预测
+func TestForecastExpiryRedis(t *testing.T) {
+	cache, _, cleanup := setupRedisTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_ = cache.Set(ctx, "soon", 1, 500*time.Millisecond)
+	_ = cache.Set(ctx, "hour", 1, 30*time.Minute)
+	_ = cache.Set(ctx, "forever", 1, 0)
+
+	forecast, err := go_cache.ForecastExpiry(ctx, cache, 100)
+	if err != nil {
+		t.Fatalf("ForecastExpiry() error = %v", err)
+	}
+	if forecast.Sampled != 3 || forecast.Buckets[0].Count != 1 || forecast.Buckets[3].Count != 1 || forecast.NoExpiry != 1 {
+		t.Errorf("预测结果不正确: %+v", forecast)
+	}
+}
@@ -0,0 +1,187 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// fuzzRecord 模糊测试使用的结构体，包含未导出字段
+type fuzzRecord struct {
+	Name   string
+	Count  int
+	Tags   []string
+	Attrs  map[string]any
+	Next   *fuzzRecord
+	hidden string
+}
+
+// fuzzTargets 返回一组不同类型的解码目标，包括非法的目标
+func fuzzTargets() []any {
+	var nilRecord *fuzzRecord
+	var nilString *string
+	return []any{
+		new(string),
+		new(int),
+		new([]byte),
+		new(map[string]int),
+		new(fuzzRecord),
+		new(*fuzzRecord),
+		new(any),
+		new([]any),
+		new(time.Time),
+		new(chan int),
+		new(func()),
+		nil,
+		"not a pointer",
+		nilRecord,
+		nilString,
+	}
+}
+
+// fuzzSeeds 返回合法的编码数据作为种子
+func fuzzSeeds(f *testing.F, s serializer.Serializer) {
+	for _, v := range []any{
+		"hello", 42, []byte("raw"), map[string]int{"a": 1}, []string{"x"},
+		fuzzRecord{Name: "n", Count: 1, Tags: []string{"t"}}, &fuzzRecord{Name: "p"},
+		nil, (*fuzzRecord)(nil), time.Unix(0, 0),
+	} {
+		data, err := s.Encode(v)
+		if err != nil {
+			f.Fatalf("Encode(%v) error = %v", v, err)
+		}
+		f.Add(data)
+	}
+	f.Add([]byte{})
+	f.Add([]byte{0xff, 0xff, 0xff})
+}
+
+// FuzzGobDecode 任意输入解码到任意目标都不应panic
+func FuzzGobDecode(f *testing.F) {
+	s := serializer.NewGob()
+	fuzzSeeds(f, s)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, target := range fuzzTargets() {
+			_ = s.Decode(data, target)
+		}
+	})
+}
+
+// FuzzJsonDecode 任意输入解码到任意目标都不应panic
+func FuzzJsonDecode(f *testing.F) {
+	s := serializer.NewJson()
+	fuzzSeeds(f, s)
+	f.Add([]byte(`{"is_nil":false,"value":{"Name":[1,2]}}`))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, target := range fuzzTargets() {
+			_ = s.Decode(data, target)
+		}
+	})
+}
+
+// FuzzEncodeDecode 编码后解码应得到相同的值
+func FuzzEncodeDecode(f *testing.F) {
+	f.Add("name", 1, "tag", true)
+	f.Add("", -1, "", false)
+	f.Fuzz(func(t *testing.T, name string, count int, tag string, withNext bool) {
+		in := fuzzRecord{Name: name, Count: count, Tags: []string{tag}, hidden: name}
+		if withNext {
+			in.Next = &fuzzRecord{Name: tag}
+		}
+
+		serializers := []serializer.Serializer{serializer.NewGob()}
+		if utf8.ValidString(name) && utf8.ValidString(tag) {
+			// JSON会把非法的UTF-8替换为U+FFFD
+			serializers = append(serializers, serializer.NewJson())
+		}
+		for _, s := range serializers {
+			data, err := s.Encode(in)
+			if err != nil {
+				t.Fatalf("%s Encode() error = %v", s.Name(), err)
+			}
+			var out fuzzRecord
+			if err := s.Decode(data, &out); err != nil {
+				t.Fatalf("%s Decode() error = %v", s.Name(), err)
+			}
+			if out.Name != in.Name || out.Count != in.Count || len(out.Tags) != 1 || out.Tags[0] != tag ||
+				(out.Next != nil) != withNext || out.hidden != "" {
+				t.Fatalf("%s round trip = %+v, want %+v", s.Name(), out, in)
+			}
+		}
+	})
+}
+
+// FuzzMemoryAssign 内存缓存把任意类型的值读取到任意目标都不应panic
+func FuzzMemoryAssign(f *testing.F) {
+	f.Add("value", 7, true)
+	f.Fuzz(func(t *testing.T, str string, n int, useStruct bool) {
+		ctx := context.Background()
+		cache := go_cache.NewMemory(time.Minute, time.Minute)
+
+		var value any = str
+		switch {
+		case useStruct:
+			value = fuzzRecord{Name: str, Count: n, hidden: str}
+		case n%2 == 0:
+			value = n
+		}
+		if err := cache.Set(ctx, "k", value, time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+		for _, target := range fuzzTargets() {
+			_ = cache.Get(ctx, "k", target)
+		}
+	})
+}
+
+// TestInvalidTargets 非法的读取目标返回 ErrInvalidTarget 而不是panic
+func TestInvalidTargets(t *testing.T) {
+	ctx := context.Background()
+	var nilRecord *fuzzRecord
+
+	caches := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+	}
+	r, _ := newRedisTest(t)
+	caches["redis"] = r.Cache
+
+	for name, cache := range caches {
+		if err := cache.Set(ctx, "k", fuzzRecord{Name: "n"}, time.Minute); err != nil {
+			t.Fatalf("%s Set() error = %v", name, err)
+		}
+		for _, target := range []any{nil, nilRecord, fuzzRecord{}} {
+			if err := cache.Get(ctx, "k", target); !errors.Is(err, go_cache.ErrInvalidTarget) {
+				t.Errorf("%s Get(%T) error = %v, want ErrInvalidTarget", name, target, err)
+			}
+		}
+
+		// 回调函数成功但目标为nil指针
+		err := cache.GetSet(ctx, "missing", time.Minute, nilRecord, func(key string, obj any) error {
+			return nil
+		})
+		if !errors.Is(err, go_cache.ErrInvalidTarget) {
+			t.Errorf("%s GetSet(nil) error = %v, want ErrInvalidTarget", name, err)
+		}
+	}
+}
+
+// TestRedactInvalidRedactor 脱敏函数返回nil时不应panic
+func TestRedactInvalidRedactor(t *testing.T) {
+	type secret struct{ Value string }
+	type holder struct {
+		Secret *secret
+		ByName map[string]secret
+	}
+	go_cache.RegisterRedactor(secret{}, func(v any) any { return nil })
+
+	out := go_cache.Redact(holder{Secret: &secret{Value: "s"}, ByName: map[string]secret{"a": {Value: "s"}}})
+	if h, ok := out.(holder); !ok || (h.Secret != nil && h.Secret.Value == "s") {
+		t.Errorf("Redact() = %+v", out)
+	}
+}
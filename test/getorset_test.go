@@ -0,0 +1,67 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestGetOrSetInfo 测试 GetOrSet 返回值的来源与剩余有效期
+func TestGetOrSetInfo(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	r, _ := newRedisTest(t)
+
+	backends := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)),
+		"redis":  r.Cache,
+		"none":   go_cache.NewNone(),
+	}
+
+	loader := func(key string, obj any) error {
+		*obj.(*string) = "loaded"
+		return nil
+	}
+
+	for name, cache := range backends {
+		t.Run(name, func(t *testing.T) {
+			var v string
+			info, err := go_cache.GetOrSet(ctx, cache, "getorset", time.Minute, &v, loader)
+			if err != nil || info.Hit || info.Source != go_cache.SourceLoader || info.TTL != time.Minute {
+				t.Fatalf("首次 GetOrSet() = %+v, %v", info, err)
+			}
+
+			info, err = go_cache.GetOrSet(ctx, cache, "getorset", time.Minute, &v, loader)
+			if name == "none" {
+				if info.Hit {
+					t.Errorf("None 不应命中: %+v", info)
+				}
+				return
+			}
+			if err != nil || !info.Hit || info.Source != go_cache.SourceCache || v != "loaded" {
+				t.Fatalf("再次 GetOrSet() = %+v, %q, %v", info, v, err)
+			}
+			if info.TTL <= 0 || info.TTL > time.Minute {
+				t.Errorf("命中时 TTL = %v, want (0, 1m]", info.TTL)
+			}
+		})
+	}
+}
+
+// TestGetOrSetLoaderError 测试回调函数失败时返回错误
+func TestGetOrSetLoaderError(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	boom := errors.New("boom")
+
+	var v string
+	info, err := go_cache.GetOrSet(context.Background(), cache, "k", 0, &v, func(key string, obj any) error {
+		return boom
+	})
+	if !errors.Is(err, boom) || info.Source != go_cache.SourceLoader || info.TTL != -1 {
+		t.Errorf("GetOrSet() = %+v, %v", info, err)
+	}
+}
@@ -0,0 +1,188 @@
+package test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// httpCacheTest 返回使用假时钟的缓存客户端与记录请求次数的源站
+func httpCacheTest(t *testing.T, handler func(w http.ResponseWriter, r *http.Request)) (*http.Client, *go_cache.FakeClock, *atomic.Int32, string) {
+	t.Helper()
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Date", clock.Now().UTC().Format(http.TimeFormat))
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+
+	transport := go_cache.NewCachingTransport(go_cache.NewMemory(time.Hour, time.Minute),
+		go_cache.WithTransportBase(server.Client().Transport),
+		go_cache.WithTransportClock(clock),
+	)
+	return transport.Client(), clock, &requests, server.URL
+}
+
+// fetch 发送请求并返回响应体与缓存状态
+func fetch(t *testing.T, client *http.Client, method, url string, header http.Header) (string, string, int) {
+	t.Helper()
+	req, _ := http.NewRequest(method, url, nil)
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("%s %s error = %v", method, url, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return string(body), resp.Header.Get(go_cache.HTTPCacheStatusHeader), resp.StatusCode
+}
+
+// TestCachingTransportRevalidation 测试新鲜期与 ETag 再验证
+func TestCachingTransportRevalidation(t *testing.T) {
+	client, clock, requests, url := httpCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, "hello")
+	})
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		status  string
+	}{
+		{"首次请求", 0, go_cache.HTTPCacheMiss},
+		{"新鲜期内", 30 * time.Second, go_cache.HTTPCacheHit},
+		{"过期后再验证", time.Minute, go_cache.HTTPCacheRevalidated},
+		{"再验证后重新计算新鲜期", 30 * time.Second, go_cache.HTTPCacheHit},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		body, status, code := fetch(t, client, http.MethodGet, url, nil)
+		if body != "hello" || status != step.status || code != http.StatusOK {
+			t.Errorf("%s: body = %q, status = %s, code = %d", step.name, body, status, code)
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("源站收到 %d 次请求，want 2", n)
+	}
+
+	// 请求的 no-cache 强制再验证
+	if _, status, _ := fetch(t, client, http.MethodGet, url, http.Header{"Cache-Control": {"no-cache"}}); status != go_cache.HTTPCacheRevalidated {
+		t.Errorf("no-cache 请求的状态 = %s", status)
+	}
+}
+
+// TestCachingTransportVary 测试按 Vary 保存多个变体
+func TestCachingTransportVary(t *testing.T) {
+	client, _, requests, url := httpCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Header().Set("Vary", "Accept-Language")
+		io.WriteString(w, "lang="+r.Header.Get("Accept-Language"))
+	})
+
+	for i := 0; i < 2; i++ {
+		for _, lang := range []string{"en", "fr"} {
+			body, _, _ := fetch(t, client, http.MethodGet, url, http.Header{"Accept-Language": {lang}})
+			if body != "lang="+lang {
+				t.Errorf("Accept-Language=%s: body = %q", lang, body)
+			}
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("每个变体只应请求一次源站，收到 %d 次请求", n)
+	}
+}
+
+// TestCachingTransportNotStored 测试不可缓存的响应与失效
+func TestCachingTransportNotStored(t *testing.T) {
+	client, _, requests, url := httpCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/secret":
+			w.Header().Set("Cache-Control", "no-store")
+		case "/item":
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		io.WriteString(w, r.Method)
+	})
+
+	fetch(t, client, http.MethodGet, url+"/secret", nil)
+	if _, status, _ := fetch(t, client, http.MethodGet, url+"/secret", nil); status != go_cache.HTTPCacheMiss {
+		t.Errorf("no-store 的响应不应缓存，status = %s", status)
+	}
+
+	fetch(t, client, http.MethodGet, url+"/item", nil)
+	if _, status, _ := fetch(t, client, http.MethodGet, url+"/item", nil); status != go_cache.HTTPCacheHit {
+		t.Fatalf("status = %s, want HIT", status)
+	}
+	fetch(t, client, http.MethodPost, url+"/item", nil)
+	if _, status, _ := fetch(t, client, http.MethodGet, url+"/item", nil); status != go_cache.HTTPCacheMiss {
+		t.Errorf("POST 后缓存应失效，status = %s", status)
+	}
+
+	before := requests.Load()
+	_, _, code := fetch(t, client, http.MethodGet, url+"/missing", http.Header{"Cache-Control": {"only-if-cached"}})
+	if code != http.StatusGatewayTimeout || requests.Load() != before {
+		t.Errorf("only-if-cached 未命中时应返回504且不请求源站，code = %d", code)
+	}
+}
+
+// TestCachingTransportExpiresWithoutDate 测试没有 Date 的响应以收到响应的时间计算 Expires 的新鲜期
+func TestCachingTransportExpiresWithoutDate(t *testing.T) {
+	var clock *go_cache.FakeClock
+	client, clock, requests, url := httpCacheTest(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header()["Date"] = nil // 阻止服务器自动添加 Date
+		w.Header().Set("Expires", clock.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+		io.WriteString(w, "hello")
+	})
+
+	steps := []struct {
+		name    string
+		advance time.Duration
+		status  string
+	}{
+		{"首次请求", 0, go_cache.HTTPCacheMiss},
+		{"Expires 之前", 30 * time.Second, go_cache.HTTPCacheHit},
+		{"Expires 之后", time.Minute, go_cache.HTTPCacheMiss},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		if body, status, _ := fetch(t, client, http.MethodGet, url, nil); body != "hello" || status != step.status {
+			t.Errorf("%s: body = %q, status = %s, want %s", step.name, body, status, step.status)
+		}
+	}
+	if n := requests.Load(); n != 2 {
+		t.Errorf("源站收到 %d 次请求，want 2", n)
+	}
+}
+
+// TestCachingTransportNilClock 测试nil时钟被忽略
+func TestCachingTransportNilClock(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		io.WriteString(w, "hello")
+	}))
+	t.Cleanup(server.Close)
+
+	client := go_cache.NewCachingTransport(go_cache.NewMemory(time.Hour, time.Minute),
+		go_cache.WithTransportBase(server.Client().Transport),
+		go_cache.WithTransportClock(nil),
+	).Client()
+	for _, want := range []string{go_cache.HTTPCacheMiss, go_cache.HTTPCacheHit} {
+		if body, status, _ := fetch(t, client, http.MethodGet, server.URL, nil); body != "hello" || status != want {
+			t.Errorf("body = %q, status = %s, want %s", body, status, want)
+		}
+	}
+}
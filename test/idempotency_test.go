@@ -0,0 +1,154 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// paymentResult 测试用的接口响应
+type paymentResult struct {
+	ID     string
+	Amount int
+}
+
+// testIdempotencyReplay 测试重试的请求返回保存的结果
+func testIdempotencyReplay(t *testing.T, cache gsr.Cacher) {
+	ctx := context.Background()
+	idem := go_cache.NewIdempotency(cache)
+
+	calls := 0
+	charge := func(key string, obj any) error {
+		calls++
+		*obj.(*paymentResult) = paymentResult{ID: "pay-" + key, Amount: 100}
+		return nil
+	}
+
+	var first paymentResult
+	replayed, err := idem.Do(ctx, "req-1", time.Hour, &first, charge)
+	if err != nil || replayed || first.ID != "pay-req-1" {
+		t.Fatalf("Do() = %v, %+v, %v, want executed result", replayed, first, err)
+	}
+
+	var second paymentResult
+	replayed, err = idem.Do(ctx, "req-1", time.Hour, &second, charge)
+	if err != nil || !replayed || second != first {
+		t.Errorf("Do() retry = %v, %+v, %v, want replayed %+v", replayed, second, err, first)
+	}
+	if calls != 1 {
+		t.Errorf("callback called %d times, want 1", calls)
+	}
+}
+
+// TestIdempotencyMemory 测试基于内存缓存的幂等执行
+func TestIdempotencyMemory(t *testing.T) {
+	testIdempotencyReplay(t, go_cache.NewMemory(time.Minute, time.Minute))
+}
+
+// TestIdempotencyRedis 测试基于Redis缓存的幂等执行
+func TestIdempotencyRedis(t *testing.T) {
+	r, _ := newRedisTest(t)
+	testIdempotencyReplay(t, r.Cache)
+}
+
+// TestIdempotencyFailureRetry 测试失败的请求可以重试
+func TestIdempotencyFailureRetry(t *testing.T) {
+	ctx := context.Background()
+	idem := go_cache.NewIdempotency(go_cache.NewMemory(time.Minute, time.Minute))
+	errDeclined := errors.New("card declined")
+
+	var res paymentResult
+	_, err := idem.Do(ctx, "req", time.Hour, &res, func(string, any) error { return errDeclined })
+	if !errors.Is(err, errDeclined) {
+		t.Fatalf("Do() error = %v, want %v", err, errDeclined)
+	}
+
+	replayed, err := idem.Do(ctx, "req", time.Hour, &res, func(key string, obj any) error {
+		obj.(*paymentResult).Amount = 5
+		return nil
+	})
+	if err != nil || replayed || res.Amount != 5 {
+		t.Errorf("Do() after failure = %v, %+v, %v, want executed again", replayed, res, err)
+	}
+}
+
+// TestIdempotencyInProgress 测试并发的相同请求只执行一次
+func TestIdempotencyInProgress(t *testing.T) {
+	ctx := context.Background()
+	idem := go_cache.NewIdempotency(go_cache.NewMemory(time.Minute, time.Minute))
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var calls atomic.Int32
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		var res paymentResult
+		_, err := idem.Do(ctx, "req", time.Hour, &res, func(string, any) error {
+			calls.Add(1)
+			close(started)
+			<-release
+			return nil
+		})
+		if err != nil {
+			t.Error(err)
+		}
+	}()
+
+	<-started
+	var res paymentResult
+	if _, err := idem.Do(ctx, "req", time.Hour, &res, func(string, any) error {
+		calls.Add(1)
+		return nil
+	}); !errors.Is(err, go_cache.ErrRequestInProgress) {
+		t.Errorf("Do() while running error = %v, want ErrRequestInProgress", err)
+	}
+	close(release)
+	wg.Wait()
+
+	if calls.Load() != 1 {
+		t.Errorf("callback called %d times, want 1", calls.Load())
+	}
+}
+
+// TestIdempotencyLockExpired 测试执行中标记过期后相同请求再次执行
+func TestIdempotencyLockExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+	idem := go_cache.NewIdempotency(cache, go_cache.WithIdempotencyLockTTL(time.Second))
+
+	var inner paymentResult
+	var innerReplayed bool
+	var innerErr error
+	var outer paymentResult
+	_, err := idem.Do(ctx, "req", time.Hour, &outer, func(string, any) error {
+		// 执行超过标记的有效期，重试的请求不再等待
+		clock.Advance(2 * time.Second)
+		innerReplayed, innerErr = idem.Do(ctx, "req", time.Hour, &inner, func(string, any) error { return nil })
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Do() error = %v", err)
+	}
+	if innerErr != nil || innerReplayed {
+		t.Errorf("Do() after marker expired = %v, %v, want executed", innerReplayed, innerErr)
+	}
+}
+
+// TestIdempotencyNotSupported 测试底层缓存不支持 SetNX
+func TestIdempotencyNotSupported(t *testing.T) {
+	idem := go_cache.NewIdempotency(go_cache.NewCacheNone())
+	var res paymentResult
+	if _, err := idem.Do(context.Background(), "req", time.Hour, &res, func(string, any) error { return nil }); !errors.Is(err, go_cache.ErrNotSupported) {
+		t.Errorf("Do() error = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,104 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+type keyHashProfile struct {
+	Name  string
+	Email string
+}
+
+// TestKeyHashingLong 测试只有过长的键被哈希，哈希后的键保留可读前缀
+func TestKeyHashingLong(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	h := go_cache.NewKeyHashing(r.Cache, go_cache.KeyHashLong, go_cache.WithKeyHashingMaxLength(32), go_cache.WithKeyHashingPrefixLength(8))
+
+	if k, hashed := h.Key("user:1"); hashed || k != "user:1" {
+		t.Errorf("Key(short) = %q, %v, want unchanged", k, hashed)
+	}
+	long := "search:" + strings.Repeat("q", 100)
+	k, hashed := h.Key(long)
+	if !hashed || !regexp.MustCompile(`^search:q#[0-9a-f]{32}$`).MatchString(k) {
+		t.Fatalf("Key(long) = %q, %v, want readable prefix and hash", k, hashed)
+	}
+
+	want := keyHashProfile{Name: "alice", Email: "alice@example.com"}
+	if err := h.Set(ctx, long, want, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	var got keyHashProfile
+	if err := h.Get(ctx, long, &got); err != nil || got != want {
+		t.Errorf("Get(long) = %+v, %v, want %+v", got, err, want)
+	}
+	if n, _ := r.Client.Exists(ctx, k).Result(); n != 1 {
+		t.Errorf("hashed key %q should exist in Redis", k)
+	}
+
+	// 未哈希的键原样写入底层缓存
+	if err := h.Set(ctx, "user:1", want, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Cache.Get(ctx, "user:1", &got); err != nil || got != want {
+		t.Errorf("underlying Get(user:1) = %+v, %v", got, err)
+	}
+
+	if err := h.Del(ctx, long); err != nil {
+		t.Fatal(err)
+	}
+	if h.Exists(ctx, long) {
+		t.Error("long key should be deleted")
+	}
+}
+
+// TestKeyHashingAlways 测试所有键都被哈希，且不截断多字节字符
+func TestKeyHashingAlways(t *testing.T) {
+	h := go_cache.NewKeyHashing(go_cache.NewMemory(time.Minute, time.Minute), go_cache.KeyHashAlways, go_cache.WithKeyHashingPrefixLength(4))
+	k, hashed := h.Key("用户:1")
+	if !hashed || !strings.HasPrefix(k, "用#") {
+		t.Errorf("Key() = %q, %v, want prefix 用#", k, hashed)
+	}
+}
+
+// TestKeyHashingCollision 测试哈希后的键中的原始键不一致时返回 ErrKeyCollision
+func TestKeyHashingCollision(t *testing.T) {
+	ctx := context.Background()
+	mem := go_cache.NewMemory(time.Minute, time.Minute)
+	h := go_cache.NewKeyHashing(mem, go_cache.KeyHashAlways)
+
+	if err := h.Set(ctx, "a", "value-a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	// 模拟冲突：把a的值复制到b的哈希键
+	ka, _ := h.Key("a")
+	kb, _ := h.Key("b")
+	if err := mem.Copy(ctx, ka, kb, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	var got string
+	err := h.Get(ctx, "b", &got)
+	if !errors.Is(err, go_cache.ErrKeyCollision) || !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Fatalf("Get() error = %v, want ErrKeyCollision and ErrKeyNotFound", err)
+	}
+
+	// GetSet 按未命中处理并覆盖
+	err = h.GetSet(ctx, "b", time.Minute, &got, func(key string, obj any) error {
+		*obj.(*string) = "value-b"
+		return nil
+	})
+	if err != nil || got != "value-b" {
+		t.Fatalf("GetSet() = %q, %v, want value-b", got, err)
+	}
+	if err := h.Get(ctx, "b", &got); err != nil || got != "value-b" {
+		t.Errorf("Get() after GetSet = %q, %v, want value-b", got, err)
+	}
+}
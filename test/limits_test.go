@@ -0,0 +1,65 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// TestDecodeLimits 测试超过解码限制的数据被拒绝
+func TestDecodeLimits(t *testing.T) {
+	gobSer := serializer.NewGob()
+	jsonSer := serializer.NewJson()
+
+	large, _ := gobSer.Encode(strings.Repeat("x", 10000))
+	nested, _ := jsonSer.Encode([][][][]int{{{{1}}}})
+	shallow, _ := jsonSer.Encode([]int{1, 2, 3})
+	quoted, _ := jsonSer.Encode("[[[[[[")
+
+	tests := []struct {
+		name    string
+		ser     serializer.Serializer
+		data    []byte
+		obj     any
+		wantErr bool
+	}{
+		{"超过MaxSize", serializer.WithLimits(gobSer, serializer.Limits{MaxSize: 100}), large, new(string), true},
+		{"未超过MaxSize", serializer.WithLimits(gobSer, serializer.Limits{MaxSize: 20000}), large, new(string), false},
+		{"超过MaxGobMessage", serializer.WithLimits(gobSer, serializer.Limits{MaxGobMessage: 1000}), large, new(string), true},
+		{"未超过MaxGobMessage", serializer.WithLimits(gobSer, serializer.Limits{MaxGobMessage: 20000}), large, new(string), false},
+		{"超过MaxDepth", serializer.WithLimits(jsonSer, serializer.Limits{MaxDepth: 3}), nested, new([][][][]int), true},
+		{"未超过MaxDepth", serializer.WithLimits(jsonSer, serializer.Limits{MaxDepth: 3}), shallow, new([]int), false},
+		{"字符串中的括号", serializer.WithLimits(jsonSer, serializer.Limits{MaxDepth: 1}), quoted, new(string), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.ser.Decode(tt.data, tt.obj)
+			if tt.wantErr != errors.Is(err, serializer.ErrDecodeLimit) {
+				t.Errorf("Decode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Decode() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestGzipLimit 测试解压后超过上限的数据被拒绝
+func TestGzipLimit(t *testing.T) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, _ = w.Write(make([]byte, 10<<20))
+	_ = w.Close()
+
+	if _, err := serializer.GzipLimit(gzip.BestSpeed, 1<<20).Reverse(buf.Bytes()); !errors.Is(err, serializer.ErrDecodeLimit) {
+		t.Errorf("Reverse() error = %v, want ErrDecodeLimit", err)
+	}
+	if plain, err := serializer.GzipLimit(gzip.BestSpeed, 10<<20).Reverse(buf.Bytes()); err != nil || len(plain) != 10<<20 {
+		t.Errorf("Reverse() len = %d, err = %v", len(plain), err)
+	}
+}
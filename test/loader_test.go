@@ -0,0 +1,167 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// loaderBackends 返回设置了回调函数选项的内存与Redis缓存
+func loaderBackends(t *testing.T, timeout time.Duration, hook go_cache.LoaderFailureHook) map[string]gsr.Cacher {
+	r, _ := newRedisTest(t)
+	return map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute,
+			go_cache.WithMemoryLoaderTimeout(timeout), go_cache.WithMemoryLoaderHook(hook)),
+		"redis": go_cache.NewRedis(r.Client,
+			go_cache.WithRedisLoaderTimeout(timeout), go_cache.WithRedisLoaderHook(hook)),
+	}
+}
+
+// TestGetSetLoaderPanic 测试回调函数panic被转换为错误且不写入缓存
+func TestGetSetLoaderPanic(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	var failures []error
+	hook := func(key string, err error) {
+		mu.Lock()
+		failures = append(failures, err)
+		mu.Unlock()
+	}
+
+	for name, cache := range loaderBackends(t, 0, hook) {
+		t.Run(name, func(t *testing.T) {
+			var v string
+			err := cache.GetSet(ctx, "panic", time.Minute, &v, func(key string, obj any) error {
+				panic("boom")
+			})
+
+			var panicErr *go_cache.LoaderPanicError
+			if !errors.As(err, &panicErr) {
+				t.Fatalf("GetSet() error = %v, want *LoaderPanicError", err)
+			}
+			if panicErr.Key != "panic" || panicErr.Value != "boom" || len(panicErr.Stack) == 0 {
+				t.Errorf("LoaderPanicError = %+v", panicErr)
+			}
+			if cache.Exists(ctx, "panic") {
+				t.Error("panic后不应写入缓存")
+			}
+
+			// 之后的调用不受影响
+			err = cache.GetSet(ctx, "panic", time.Minute, &v, func(key string, obj any) error {
+				*obj.(*string) = "ok"
+				return nil
+			})
+			if err != nil || v != "ok" {
+				t.Errorf("GetSet() = %q, %v", v, err)
+			}
+		})
+	}
+
+	if len(failures) != 2 {
+		t.Errorf("失败回调次数 = %d, want 2", len(failures))
+	}
+}
+
+// TestGetSetLoaderTimeout 测试回调函数超时返回 ErrLoaderTimeout 且不修改obj
+func TestGetSetLoaderTimeout(t *testing.T) {
+	ctx := context.Background()
+	release := make(chan struct{})
+	defer close(release)
+
+	var mu sync.Mutex
+	var failures []error
+	hook := func(key string, err error) {
+		mu.Lock()
+		failures = append(failures, err)
+		mu.Unlock()
+	}
+
+	for name, cache := range loaderBackends(t, 20*time.Millisecond, hook) {
+		t.Run(name, func(t *testing.T) {
+			v := "initial"
+			err := cache.GetSet(ctx, "slow", time.Minute, &v, func(key string, obj any) error {
+				<-release
+				*obj.(*string) = "late"
+				return nil
+			})
+			if !errors.Is(err, go_cache.ErrLoaderTimeout) {
+				t.Fatalf("GetSet() error = %v, want ErrLoaderTimeout", err)
+			}
+			if v != "initial" {
+				t.Errorf("超时后 obj = %q, want initial", v)
+			}
+
+			// 超时时间内返回的回调函数正常写入
+			err = cache.GetSet(ctx, "fast", time.Minute, &v, func(key string, obj any) error {
+				*obj.(*string) = "fast"
+				return nil
+			})
+			if err != nil || v != "fast" {
+				t.Errorf("GetSet() = %q, %v", v, err)
+			}
+			var got string
+			if err := cache.Get(ctx, "fast", &got); err != nil || got != "fast" {
+				t.Errorf("Get() = %q, %v", got, err)
+			}
+		})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failures) != 2 || !errors.Is(failures[0], go_cache.ErrLoaderTimeout) {
+		t.Errorf("失败回调 = %v", failures)
+	}
+}
+
+// TestWrapperGetSetLoader 测试包装器的 GetSet 沿用内层缓存的超时设置并转换panic
+func TestWrapperGetSetLoader(t *testing.T) {
+	ctx := context.Background()
+	inner := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryLoaderTimeout(time.Second))
+	tenant, err := go_cache.ForTenant(inner, "acme")
+	if err != nil {
+		t.Fatal(err)
+	}
+	debounced := go_cache.NewDebouncedWriter(inner, time.Hour)
+	defer debounced.Close()
+
+	wrappers := map[string]gsr.Cacher{
+		"tenant":      tenant,
+		"keyhash":     go_cache.NewKeyHashing(inner, go_cache.KeyHashLong),
+		"keyhash-all": go_cache.NewKeyHashing(inner, go_cache.KeyHashAlways),
+		"debounce":    debounced,
+		"loadshed":    go_cache.NewLoadShedder(inner, time.Second),
+		"none":        go_cache.NewNone(),
+	}
+	for name, cache := range wrappers {
+		t.Run(name, func(t *testing.T) {
+			var v string
+			err := cache.GetSet(ctx, name+":value", time.Minute, &v, func(key string, obj any) error {
+				*obj.(*string) = "loaded"
+				return nil
+			})
+			if err != nil || v != "loaded" {
+				t.Fatalf("GetSet() = %q, %v", v, err)
+			}
+			if name != "none" {
+				var cached string
+				if err := cache.Get(ctx, name+":value", &cached); err != nil || cached != "loaded" {
+					t.Errorf("缓存的值 = %q, %v", cached, err)
+				}
+			}
+
+			err = cache.GetSet(ctx, name+":panic", time.Minute, &v, func(key string, obj any) error {
+				panic("boom")
+			})
+			var panicErr *go_cache.LoaderPanicError
+			if !errors.As(err, &panicErr) {
+				t.Errorf("GetSet() error = %v, want *LoaderPanicError", err)
+			}
+		})
+	}
+}
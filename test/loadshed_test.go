@@ -0,0 +1,117 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// slowCache 可调节延迟的缓存，用于模拟后端延迟抖动
+type slowCache struct {
+	gsr.Cacher
+	delay atomic.Int64
+	calls atomic.Int64
+}
+
+func newSlowCache() *slowCache {
+	return &slowCache{Cacher: go_cache.NewMemory(time.Minute, time.Minute)}
+}
+
+func (s *slowCache) wait() {
+	s.calls.Add(1)
+	time.Sleep(time.Duration(s.delay.Load()))
+}
+
+func (s *slowCache) Get(ctx context.Context, key string, obj any) error {
+	s.wait()
+	return s.Cacher.Get(ctx, key, obj)
+}
+
+func (s *slowCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	s.wait()
+	return s.Cacher.Set(ctx, key, value, ttl)
+}
+
+// TestLoadShedderShedsAndRecovers 测试延迟升高时跳过读写、恢复后回到正常模式
+func TestLoadShedderShedsAndRecovers(t *testing.T) {
+	backend := newSlowCache()
+	cache := go_cache.NewLoadShedder(backend, 5*time.Millisecond,
+		go_cache.WithLoadShedWindow(10),
+		go_cache.WithLoadShedMinSamples(5),
+		go_cache.WithLoadShedProbeInterval(time.Hour),
+	)
+	ctx := context.Background()
+	_ = cache.Set(ctx, "k", "v", time.Minute)
+
+	// 后端变慢，触发负载保护
+	backend.delay.Store(int64(10 * time.Millisecond))
+	var s string
+	for i := 0; i < 10 && !cache.Shedding(); i++ {
+		_ = cache.Get(ctx, "k", &s)
+	}
+	if !cache.Shedding() {
+		t.Fatal("p99超过阈值后应进入负载保护")
+	}
+
+	// 负载保护期间读操作按未命中处理，不访问后端
+	calls := backend.calls.Load()
+	if err := cache.Get(ctx, "k", &s); !errors.Is(err, go_cache.ErrLoadShed) {
+		t.Errorf("负载保护期间 Get 应返回 ErrLoadShed，实际为 %v", err)
+	}
+	// 非关键写入被丢弃，关键写入照常执行
+	_ = cache.Set(ctx, "dropped", "v", time.Minute)
+	_ = cache.Set(go_cache.WithCriticalWrite(ctx), "critical", "v", time.Minute)
+	if got := backend.calls.Load() - calls; got != 1 {
+		t.Errorf("负载保护期间只应有关键写入访问后端，实际访问%d次", got)
+	}
+
+	stats := cache.ShedStats()
+	if stats.ShedReads != 1 || stats.ShedWrites != 1 {
+		t.Errorf("负载保护统计不正确: %+v", stats)
+	}
+
+	// 后端恢复，关键写入作为样本使其退出负载保护
+	backend.delay.Store(0)
+	for i := 0; i < 10 && cache.Shedding(); i++ {
+		_ = cache.Set(go_cache.WithCriticalWrite(ctx), "critical", "v", time.Minute)
+	}
+	if cache.Shedding() {
+		t.Fatal("p99恢复后应退出负载保护")
+	}
+	if err := cache.Get(ctx, "critical", &s); err != nil || s != "v" {
+		t.Errorf("恢复后 Get() = %q, %v", s, err)
+	}
+}
+
+// TestLoadShedderGetSetCallsLoader 测试负载保护期间GetSet直接调用回调
+func TestLoadShedderGetSetCallsLoader(t *testing.T) {
+	backend := newSlowCache()
+	backend.delay.Store(int64(5 * time.Millisecond))
+	cache := go_cache.NewLoadShedder(backend, time.Millisecond,
+		go_cache.WithLoadShedWindow(5),
+		go_cache.WithLoadShedMinSamples(5),
+		go_cache.WithLoadShedProbeInterval(time.Hour),
+	)
+	ctx := context.Background()
+
+	var s string
+	for i := 0; i < 5; i++ {
+		_ = cache.Get(ctx, "k", &s)
+	}
+	if !cache.Shedding() {
+		t.Fatal("应进入负载保护")
+	}
+
+	err := cache.GetSet(ctx, "k", time.Minute, &s, func(key string, obj any) error {
+		*obj.(*string) = "loaded"
+		return nil
+	})
+	if err != nil || s != "loaded" {
+		t.Errorf("GetSet() = %q, %v", s, err)
+	}
+}
@@ -0,0 +1,72 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestMemorySoftValuesCollected 测试内存压力下软引用值被GC回收后视为未命中
+func TestMemorySoftValuesCollected(t *testing.T) {
+	ctx := context.Background()
+	// 堆占用上限为1字节，始终处于内存压力下
+	cache := go_cache.NewMemory(time.Minute, 0, go_cache.WithMemorySoftValues(1024, 1))
+
+	if err := cache.Set(ctx, "large", make([]byte, 4096), 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cache.Set(ctx, "small", "v", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	runtime.GC()
+	runtime.GC()
+
+	var large []byte
+	if err := cache.Get(ctx, "large", &large); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("回收后 Get() error = %v, want ErrKeyNotFound", err)
+	}
+	if cache.Exists(ctx, "large") {
+		t.Error("回收后 Exists() = true")
+	}
+
+	var small string
+	if err := cache.Get(ctx, "small", &small); err != nil || small != "v" {
+		t.Errorf("小值 Get() = %q, %v", small, err)
+	}
+
+	// 回收后回退到回调函数
+	loads := 0
+	err := cache.GetSet(ctx, "large", 0, &large, func(key string, obj any) error {
+		loads++
+		*obj.(*[]byte) = make([]byte, 4096)
+		return nil
+	})
+	if err != nil || loads != 1 || len(large) != 4096 {
+		t.Errorf("GetSet() loads = %d, len = %d, err = %v", loads, len(large), err)
+	}
+
+	cache.DeleteExpired()
+	if stats := cache.Stats(); stats.Entries > 2 {
+		t.Errorf("DeleteExpired 后条目数 = %d", stats.Entries)
+	}
+}
+
+// TestMemorySoftValuesRetained 测试没有内存压力时软引用值不会被回收
+func TestMemorySoftValuesRetained(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, 0, go_cache.WithMemorySoftValues(1024, 1<<62))
+
+	_ = cache.Set(ctx, "large", make([]byte, 4096), 0)
+	runtime.GC()
+	runtime.GC()
+
+	var large []byte
+	if err := cache.Get(ctx, "large", &large); err != nil || len(large) != 4096 {
+		t.Errorf("Get() len = %d, err = %v", len(large), err)
+	}
+}
@@ -184,7 +184,8 @@ func TestMemoryGetSet(t *testing.T) {
 
 // TestMemoryExpiresIn 测试设置相对过期时间
 func TestMemoryExpiresIn(t *testing.T) {
-	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute)
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute, go_cache.WithMemoryClock(clock))
 	ctx := context.Background()
 
 	// 设置一个键，过期时间为10分钟
@@ -204,8 +205,8 @@ func TestMemoryExpiresIn(t *testing.T) {
 		t.Fatalf("ExpiresIn() error = %v", err)
 	}
 
-	// 等待200毫秒后，键应该已过期
-	time.Sleep(200 * time.Millisecond)
+	// 推进200毫秒后，键应该已过期
+	clock.Advance(200 * time.Millisecond)
 
 	// 检查键是否已过期
 	if cache.Exists(ctx, "expire_key") {
@@ -215,7 +216,8 @@ func TestMemoryExpiresIn(t *testing.T) {
 
 // TestMemoryExpiresAt 测试设置绝对过期时间
 func TestMemoryExpiresAt(t *testing.T) {
-	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute)
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute, go_cache.WithMemoryClock(clock))
 	ctx := context.Background()
 
 	// 设置一个键
@@ -230,14 +232,14 @@ func TestMemoryExpiresAt(t *testing.T) {
 	}
 
 	// 设置过期时间为100毫秒后
-	expiresAt := time.Now().Add(100 * time.Millisecond)
+	expiresAt := clock.Now().Add(100 * time.Millisecond)
 	err = cache.ExpiresAt(ctx, "expireat_key", expiresAt)
 	if err != nil {
 		t.Fatalf("ExpiresAt() error = %v", err)
 	}
 
-	// 等待200毫秒后，键应该已过期
-	time.Sleep(200 * time.Millisecond)
+	// 推进200毫秒后，键应该已过期
+	clock.Advance(200 * time.Millisecond)
 
 	// 检查键是否已过期
 	if cache.Exists(ctx, "expireat_key") {
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// TestEstimateSize 测试值大小估算
+func TestEstimateSize(t *testing.T) {
+	type node struct {
+		Name string
+		Next *node
+	}
+	cyclic := &node{Name: "loop"}
+	cyclic.Next = cyclic
+
+	tests := []struct {
+		name  string
+		value any
+		min   int64
+	}{
+		{name: "nil", value: nil, min: 0},
+		{name: "整数", value: 42, min: 8},
+		{name: "字符串", value: strings.Repeat("x", 1000), min: 1000},
+		{name: "字节切片", value: make([]byte, 4096), min: 4096},
+		{name: "map", value: map[string]string{"k": strings.Repeat("v", 500)}, min: 500},
+		{name: "结构体", value: TestUser{Name: strings.Repeat("n", 200)}, min: 200},
+		{name: "循环引用", value: cyclic, min: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := go_cache.EstimateSize(tt.value); got < tt.min {
+				t.Errorf("EstimateSize() = %d, 期望至少 %d", got, tt.min)
+			}
+		})
+	}
+}
+
+// TestMemoryUsageAccounting 测试内存占用统计
+func TestMemoryUsageAccounting(t *testing.T) {
+	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "big", strings.Repeat("x", 10000), time.Minute)
+	_ = cache.Set(ctx, "small", 1, time.Minute)
+
+	stats := cache.Stats()
+	if stats.Entries != 2 {
+		t.Errorf("条目数应为2，实际为%d", stats.Entries)
+	}
+	if stats.MemoryBytes < 10000 {
+		t.Errorf("内存占用应至少为10000字节，实际为%d", stats.MemoryBytes)
+	}
+
+	size, ok := cache.EntrySize("big")
+	if !ok || size < 10000 {
+		t.Errorf("EntrySize(big) = %d, %v", size, ok)
+	}
+
+	// 覆盖写入不应重复计算
+	_ = cache.Set(ctx, "big", "tiny", time.Minute)
+	if got := cache.Stats().MemoryBytes; got >= 10000 {
+		t.Errorf("覆盖写入后内存占用应减少，实际为%d", got)
+	}
+
+	_ = cache.Del(ctx, "big")
+	_ = cache.Del(ctx, "small")
+	stats = cache.Stats()
+	if stats.Entries != 0 || stats.MemoryBytes != 0 {
+		t.Errorf("删除后统计应归零: entries=%d bytes=%d", stats.Entries, stats.MemoryBytes)
+	}
+}
+
+// TestMemoryMaxBytesEviction 测试超出内存上限时淘汰最早写入的条目
+func TestMemoryMaxBytesEviction(t *testing.T) {
+	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute,
+		go_cache.WithMemorySizer(func(value any) int64 { return 100 }),
+		go_cache.WithMemoryMaxBytes(300),
+	)
+	ctx := context.Background()
+
+	for _, key := range []string{"a", "b", "c", "d"} {
+		if err := cache.Set(ctx, key, key, time.Minute); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+
+	if cache.Exists(ctx, "a") {
+		t.Error("最早写入的条目应被淘汰")
+	}
+	for _, key := range []string{"b", "c", "d"} {
+		if !cache.Exists(ctx, key) {
+			t.Errorf("条目 %s 不应被淘汰", key)
+		}
+	}
+
+	stats := cache.Stats()
+	if stats.MemoryBytes != 300 || stats.MaxMemoryBytes != 300 {
+		t.Errorf("内存占用统计不正确: used=%d max=%d", stats.MemoryBytes, stats.MaxMemoryBytes)
+	}
+}
+
+// TestMemoryEntryTooLarge 测试单个条目超过上限
+func TestMemoryEntryTooLarge(t *testing.T) {
+	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute, go_cache.WithMemoryMaxBytes(64))
+	ctx := context.Background()
+
+	err := cache.Set(ctx, "huge", strings.Repeat("x", 1024), time.Minute)
+	if !errors.Is(err, go_cache.ErrEntryTooLarge) {
+		t.Errorf("应返回 ErrEntryTooLarge，实际为 %v", err)
+	}
+	if cache.Exists(ctx, "huge") {
+		t.Error("超限条目不应被写入")
+	}
+}
+
+// TestMemoryUsageAfterExpiry 测试janitor清理过期条目后内存占用同步更新
+func TestMemoryUsageAfterExpiry(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, 10*time.Millisecond)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "short", strings.Repeat("x", 1000), 20*time.Millisecond)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		stats := cache.Stats()
+		if stats.Entries == 0 && stats.MemoryBytes == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("过期清理后统计应归零: entries=%d bytes=%d", stats.Entries, stats.MemoryBytes)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// TestMemoryUsageWithoutJanitor 测试cleanupInterval为0时过期条目的统计与淘汰
+func TestMemoryUsageWithoutJanitor(t *testing.T) {
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(time.Minute, 0,
+		go_cache.WithMemorySizer(func(value any) int64 { return 100 }),
+		go_cache.WithMemoryMaxBytes(300),
+		go_cache.WithMemoryClock(clock),
+	)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "live", "v", time.Minute)
+	_ = cache.Set(ctx, "dead1", "v", time.Millisecond)
+	_ = cache.Set(ctx, "dead2", "v", time.Millisecond)
+	clock.Advance(5 * time.Millisecond)
+
+	// 没有janitor时过期条目仍计入统计
+	if got := cache.Stats().Entries; got != 3 {
+		t.Errorf("清理前条目数应为3，实际为%d", got)
+	}
+
+	// 超出上限时应先清理过期条目，而不是淘汰存活的条目
+	if err := cache.Set(ctx, "new", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !cache.Exists(ctx, "live") || !cache.Exists(ctx, "new") {
+		t.Error("存活条目不应被淘汰")
+	}
+	stats := cache.Stats()
+	if stats.Entries != 2 || stats.MemoryBytes != 200 {
+		t.Errorf("统计不正确: entries=%d bytes=%d", stats.Entries, stats.MemoryBytes)
+	}
+
+	// 手动清理
+	_ = cache.Set(ctx, "dead3", "v", time.Millisecond)
+	clock.Advance(5 * time.Millisecond)
+	cache.DeleteExpired()
+	if got := cache.Stats().Entries; got != 2 {
+		t.Errorf("DeleteExpired 后条目数应为2，实际为%d", got)
+	}
+}
+
+// TestSerializerSizer 测试基于序列化结果的大小计算
+func TestSerializerSizer(t *testing.T) {
+	sizer := go_cache.SerializerSizer(serializer.NewJson())
+	data, _ := serializer.NewJson().Encode("hello")
+	if got := sizer("hello"); got != int64(len(data)) {
+		t.Errorf("SerializerSizer() = %d, want %d", got, len(data))
+	}
+}
+
+// TestMemoryExpiredAndEvictedStats 测试区分过期与容量淘汰的统计
+func TestMemoryExpiredAndEvictedStats(t *testing.T) {
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(time.Minute, 0,
+		go_cache.WithMemorySizer(func(value any) int64 { return 100 }),
+		go_cache.WithMemoryMaxBytes(200),
+		go_cache.WithMemoryClock(clock),
+	)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "expire", "v", time.Millisecond)
+	clock.Advance(5 * time.Millisecond)
+	cache.DeleteExpired()
+
+	_ = cache.Set(ctx, "a", "v", time.Minute)
+	_ = cache.Set(ctx, "b", "v", time.Minute)
+	_ = cache.Set(ctx, "c", "v", time.Minute)
+
+	// 主动删除不计入过期与淘汰
+	_ = cache.Del(ctx, "c")
+
+	stats := cache.Stats()
+	if stats.Expired != 1 {
+		t.Errorf("过期数应为1，实际为%d", stats.Expired)
+	}
+	if stats.Evicted != 1 {
+		t.Errorf("淘汰数应为1，实际为%d", stats.Evicted)
+	}
+}
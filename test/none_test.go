@@ -2,6 +2,7 @@ package test
 
 import (
 	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -57,7 +58,7 @@ func TestNoneSet(t *testing.T) {
 	}
 }
 
-// TestNoneGetSet 测试GetSet总是返回错误
+// TestNoneGetSet 测试GetSet调用回调函数并返回其结果，但不存储
 func TestNoneGetSet(t *testing.T) {
 	cache := go_cache.NewNone()
 	ctx := context.Background()
@@ -67,13 +68,37 @@ func TestNoneGetSet(t *testing.T) {
 
 	err := cache.GetSet(ctx, "test_key", 10*time.Minute, &result, func(key string, obj any) error {
 		callbackCalled = true
+		*obj.(*string) = "loaded"
 		return nil
 	})
 
-	if err == nil {
-		t.Error("None.GetSet() 应该返回错误")
+	if err != nil {
+		t.Errorf("None.GetSet() 不应该返回错误，实际返回: %v", err)
 	}
-	if err.Error() != "not implemented" {
+	if !callbackCalled || result != "loaded" {
+		t.Errorf("None.GetSet() 应该调用回调函数并返回其结果，实际返回: %q", result)
+	}
+
+	// 结果不应该被存储
+	if cache.Exists(ctx, "test_key") {
+		t.Error("None 不应该存储任何数据")
+	}
+}
+
+// TestNoneStrictGetSet 测试 WithNoneStrictGetSet 恢复GetSet返回错误的旧行为
+func TestNoneStrictGetSet(t *testing.T) {
+	cache := go_cache.NewNone(go_cache.WithNoneStrictGetSet())
+	ctx := context.Background()
+
+	var result string
+	callbackCalled := false
+
+	err := cache.GetSet(ctx, "test_key", 10*time.Minute, &result, func(key string, obj any) error {
+		callbackCalled = true
+		return nil
+	})
+
+	if !errors.Is(err, go_cache.ErrNotImplemented) {
 		t.Errorf("None.GetSet() 应该返回 'not implemented' 错误，实际返回: %v", err)
 	}
 
@@ -205,6 +230,58 @@ func TestNoneWithDifferentContexts(t *testing.T) {
 	}
 }
 
+// TestNonePassThrough 测试pass-through模式：Get未命中，GetSet调用回调函数但不保存
+func TestNonePassThrough(t *testing.T) {
+	cache := go_cache.NewNone(go_cache.WithNoneMode(go_cache.NonePassThrough))
+	ctx := context.Background()
+
+	if cache.Mode() != go_cache.NonePassThrough {
+		t.Errorf("Mode() = %v, want passthrough", cache.Mode())
+	}
+
+	var result string
+	if err := cache.Get(ctx, "test_key", &result); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("None.Get() error = %v, want ErrKeyNotFound", err)
+	}
+
+	calls := 0
+	for i := 0; i < 2; i++ {
+		err := cache.GetSet(ctx, "test_key", 10*time.Minute, &result, func(key string, obj any) error {
+			calls++
+			*obj.(*string) = "loaded"
+			return nil
+		})
+		if err != nil || result != "loaded" {
+			t.Fatalf("None.GetSet() = %q, %v", result, err)
+		}
+	}
+	// 结果不会被保存，每次都调用回调函数
+	if calls != 2 {
+		t.Errorf("回调函数调用次数 = %d, want 2", calls)
+	}
+
+	// 回调函数的错误原样返回
+	loadErr := errors.New("load failed")
+	err := cache.GetSet(ctx, "test_key", 10*time.Minute, &result, func(key string, obj any) error {
+		return loadErr
+	})
+	if !errors.Is(err, loadErr) {
+		t.Errorf("None.GetSet() error = %v, want %v", err, loadErr)
+	}
+}
+
+// TestNoneStrictMode 测试strict模式（默认）返回 ErrNotImplemented
+func TestNoneStrictMode(t *testing.T) {
+	cache := go_cache.NewNone(go_cache.WithNoneMode(go_cache.NoneStrict))
+	var result string
+	if err := cache.Get(context.Background(), "test_key", &result); !errors.Is(err, go_cache.ErrNotImplemented) {
+		t.Errorf("None.Get() error = %v, want ErrNotImplemented", err)
+	}
+	if go_cache.NewNone().Mode() != go_cache.NoneStrict {
+		t.Error("默认模式应为 NoneStrict")
+	}
+}
+
 // BenchmarkNoneSet 基准测试：Set操作
 func BenchmarkNoneSet(b *testing.B) {
 	cache := go_cache.NewNone()
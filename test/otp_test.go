@@ -0,0 +1,111 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// otpBackends 返回Redis与内存缓存
+func otpBackends(t *testing.T) map[string]gsr.Cacher {
+	r, _ := newRedisTest(t)
+	return map[string]gsr.Cacher{
+		"redis":  r.Cache,
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+	}
+}
+
+// TestOTPStore 测试验证码的下发、验证与作废
+func TestOTPStore(t *testing.T) {
+	ctx := context.Background()
+	for name, cache := range otpBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			otp := go_cache.NewOTPStore(cache, go_cache.WithOTPMaxAttempts(3))
+
+			if err := otp.StoreOTP(ctx, "13800000000", "123456", time.Minute); err != nil {
+				t.Fatalf("StoreOTP() error = %v", err)
+			}
+			if err := otp.StoreOTP(ctx, "13800000000", "654321", time.Minute); !errors.Is(err, go_cache.ErrOTPPending) {
+				t.Errorf("已有验证码时 StoreOTP() error = %v, want ErrOTPPending", err)
+			}
+			if err := otp.VerifyOTP(ctx, "13800000000", "000000"); !errors.Is(err, go_cache.ErrOTPMismatch) {
+				t.Errorf("VerifyOTP() error = %v, want ErrOTPMismatch", err)
+			}
+			if err := otp.VerifyOTP(ctx, "13800000000", "123456"); err != nil {
+				t.Errorf("VerifyOTP() error = %v", err)
+			}
+			if err := otp.VerifyOTP(ctx, "13800000000", "123456"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("验证成功后验证码应作废，VerifyOTP() error = %v", err)
+			}
+
+			// 失败次数达到上限后作废
+			if err := otp.StoreOTP(ctx, "13800000000", "111111", time.Minute); err != nil {
+				t.Fatalf("验证后应能重新下发，StoreOTP() error = %v", err)
+			}
+			for i, want := range []error{go_cache.ErrOTPMismatch, go_cache.ErrOTPMismatch, go_cache.ErrOTPExhausted, go_cache.ErrKeyNotFound} {
+				if err := otp.VerifyOTP(ctx, "13800000000", "999999"); !errors.Is(err, want) {
+					t.Errorf("第%d次失败 VerifyOTP() error = %v, want %v", i+1, err, want)
+				}
+			}
+			if err := otp.VerifyOTP(ctx, "13800000000", "111111"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("作废后正确的验证码也不应通过，VerifyOTP() error = %v", err)
+			}
+
+			// 新的验证码从0开始计数
+			if err := otp.StoreOTP(ctx, "13800000000", "222222", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if err := otp.VerifyOTP(ctx, "13800000000", "999999"); !errors.Is(err, go_cache.ErrOTPMismatch) {
+				t.Errorf("VerifyOTP() error = %v, want ErrOTPMismatch", err)
+			}
+			if err := otp.RevokeOTP(ctx, "13800000000"); err != nil {
+				t.Fatal(err)
+			}
+			if err := otp.VerifyOTP(ctx, "13800000000", "222222"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("RevokeOTP 后 VerifyOTP() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestOTPStoreConcurrentGuesses 测试并发猜测不会超过次数上限
+func TestOTPStoreConcurrentGuesses(t *testing.T) {
+	ctx := context.Background()
+	for name, cache := range otpBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			otp := go_cache.NewOTPStore(cache, go_cache.WithOTPMaxAttempts(3))
+			if err := otp.StoreOTP(ctx, "session", "424242", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			var mu sync.Mutex
+			counts := map[error]int{}
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					err := otp.VerifyOTP(ctx, "session", "000000")
+					for _, kind := range []error{go_cache.ErrOTPMismatch, go_cache.ErrOTPExhausted, go_cache.ErrKeyNotFound} {
+						if errors.Is(err, kind) {
+							mu.Lock()
+							counts[kind]++
+							mu.Unlock()
+							return
+						}
+					}
+					t.Errorf("VerifyOTP() error = %v", err)
+				}()
+			}
+			wg.Wait()
+			if counts[go_cache.ErrOTPMismatch] != 2 || counts[go_cache.ErrOTPExhausted] != 1 {
+				t.Errorf("并发验证的结果 = %v，应只有2次失败与1次作废", counts)
+			}
+		})
+	}
+}
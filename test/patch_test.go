@@ -0,0 +1,141 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// patchProfile 按字段更新的测试结构体
+type patchProfile struct {
+	Name    string
+	Visits  int
+	Tags    []string `cache:"tags"`
+	Ignored string   `cache:"-"`
+	private int
+}
+
+// TestPatchCache 测试按字段保存与更新
+func TestPatchCache(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]go_cache.PatchCache{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	for name, cache := range backends {
+		t.Run(name, func(t *testing.T) {
+			old := patchProfile{Name: "alice", Visits: 1, Tags: []string{"a"}}
+			if err := cache.SetFields(ctx, "profile", old, time.Minute); err != nil {
+				t.Fatalf("SetFields() error = %v", err)
+			}
+
+			updated := old
+			updated.Visits = 2
+			n, err := cache.Patch(ctx, "profile", old, updated, 0)
+			if err != nil || n != 1 {
+				t.Fatalf("Patch() = %d, %v, want 1 field", n, err)
+			}
+
+			var got patchProfile
+			if err := cache.GetFields(ctx, "profile", &got); err != nil {
+				t.Fatalf("GetFields() error = %v", err)
+			}
+			if got.Name != "alice" || got.Visits != 2 || len(got.Tags) != 1 {
+				t.Errorf("GetFields() = %+v", got)
+			}
+
+			if _, err := cache.Patch(ctx, "missing", old, updated, 0); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("Patch(missing) error = %v, want ErrKeyNotFound", err)
+			}
+			if _, err := cache.Patch(ctx, "profile", old, "not a struct", 0); err == nil {
+				t.Error("Patch() 类型不一致时应返回错误")
+			}
+			if err := cache.GetFields(ctx, "missing", &got); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("GetFields(missing) error = %v, want ErrKeyNotFound", err)
+			}
+		})
+	}
+}
+
+// TestRedisPatchSendsOnlyChangedFields 测试Redis只写入变化的字段并保留过期时间
+func TestRedisPatchSendsOnlyChangedFields(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	old := patchProfile{Name: "bob", Visits: 1, Tags: []string{"x", "y"}}
+	if err := r.Cache.SetFields(ctx, "p", old, time.Minute); err != nil {
+		t.Fatalf("SetFields() error = %v", err)
+	}
+	fields, err := r.Client.HKeys(ctx, "p").Result()
+	if err != nil || len(fields) != 3 {
+		t.Fatalf("HKeys() = %v, %v, want Name Visits tags", fields, err)
+	}
+	before := r.Cache.Stats().BytesWritten
+
+	updated := old
+	updated.Name = "bobby"
+	if _, err := r.Cache.Patch(ctx, "p", old, updated, 0); err != nil {
+		t.Fatalf("Patch() error = %v", err)
+	}
+
+	written := r.Cache.Stats().BytesWritten - before
+	name, _ := r.Client.HGet(ctx, "p", "Name").Bytes()
+	if written != uint64(len(name)) {
+		t.Errorf("Patch 写入 %d 字节, want %d（仅Name字段）", written, len(name))
+	}
+	if ttl := r.Client.PTTL(ctx, "p").Val(); ttl <= 0 {
+		t.Errorf("Patch 后 PTTL = %v, 应保留过期时间", ttl)
+	}
+}
+
+// patchAccount 带有脱敏选项的按字段更新测试结构体
+type patchAccount struct {
+	Name  string
+	Email string `cache:"redact"`
+	Token string `cache:"token,redact"`
+}
+
+// patchDuplicate 两个字段使用相同字段名的结构体
+type patchDuplicate struct {
+	First  string `cache:"name"`
+	Second string `cache:"name"`
+}
+
+// TestPatchCacheTagOptions 测试标签中的 redact 选项不影响字段名，字段名重复时返回错误
+func TestPatchCacheTagOptions(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]go_cache.PatchCache{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	for name, cache := range backends {
+		t.Run(name, func(t *testing.T) {
+			want := patchAccount{Name: "alice", Email: "alice@example.com", Token: "secret"}
+			if err := cache.SetFields(ctx, "account", want, time.Minute); err != nil {
+				t.Fatalf("SetFields() error = %v", err)
+			}
+			var got patchAccount
+			if err := cache.GetFields(ctx, "account", &got); err != nil || got != want {
+				t.Errorf("GetFields() = %+v, %v, want %+v", got, err, want)
+			}
+
+			if err := cache.SetFields(ctx, "dup", patchDuplicate{First: "a", Second: "b"}, time.Minute); err == nil {
+				t.Error("字段名重复时 SetFields() 应返回错误")
+			}
+		})
+	}
+
+	redacted := go_cache.Redact(patchAccount{Name: "alice", Email: "alice@example.com", Token: "secret"}).(patchAccount)
+	if redacted.Email != go_cache.RedactedValue || redacted.Token != go_cache.RedactedValue || redacted.Name != "alice" {
+		t.Errorf("Redact() = %+v", redacted)
+	}
+}
@@ -0,0 +1,56 @@
+package test
+
+import (
+	"compress/gzip"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// TestStatsPayloadPercentiles 测试负载大小的平均值与分位数
+func TestStatsPayloadPercentiles(t *testing.T) {
+	s := go_cache.NewStatsCollector()
+	for i := 1; i <= 100; i++ {
+		s.RecordPayload("user:1", i, i)
+	}
+	s.RecordPayload("order:1", 1000, 4000)
+
+	stats := s.Snapshot()
+	user := stats.Namespaces["user"].Payload
+	if user.Count != 100 || user.AvgSize != 50.5 || user.MaxSize != 100 {
+		t.Errorf("user Payload = %+v", user)
+	}
+	if user.P50 != 50 || user.P95 != 95 || user.P99 != 99 {
+		t.Errorf("user 分位数 = %d/%d/%d, want 50/95/99", user.P50, user.P95, user.P99)
+	}
+	if user.CompressionRatio != 1 {
+		t.Errorf("user CompressionRatio = %v, want 1", user.CompressionRatio)
+	}
+	if order := stats.Namespaces["order"].Payload; order.CompressionRatio != 0.25 {
+		t.Errorf("order CompressionRatio = %v, want 0.25", order.CompressionRatio)
+	}
+	if stats.Payload.Count != 101 || stats.Payload.MaxSize != 1000 {
+		t.Errorf("全局 Payload = %+v", stats.Payload)
+	}
+}
+
+// TestRedisPayloadCompressionRatio 测试Redis写入时记录压缩率
+func TestRedisPayloadCompressionRatio(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisSerializer(
+		serializer.NewPipeline(serializer.NewGob(), serializer.Gzip(gzip.BestCompression))))
+	if err := cache.Set(ctx, "doc:1", strings.Repeat("a", 10000), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	payload := cache.Stats().Namespaces["doc"].Payload
+	if payload.Count != 1 || payload.CompressionRatio <= 0 || payload.CompressionRatio >= 0.1 {
+		t.Errorf("Payload = %+v, want 压缩率 < 0.1", payload)
+	}
+}
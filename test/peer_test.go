@@ -0,0 +1,95 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+type peerArticle struct {
+	ID    string
+	Title string
+}
+
+// newPeerCluster 创建n个节点组成的集群，所有节点共享同一个loader调用计数
+func newPeerCluster(t *testing.T, n int, loads *atomic.Int32) []*go_cache.PeerGroup {
+	t.Helper()
+
+	loader := func(key string, obj any) error {
+		loads.Add(1)
+		*obj.(*peerArticle) = peerArticle{ID: key, Title: "title of " + key}
+		return nil
+	}
+
+	groups := make([]*go_cache.PeerGroup, n)
+	addrs := make([]string, n)
+	for i := range groups {
+		var group *go_cache.PeerGroup
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			group.ServeHTTP(w, r)
+		}))
+		t.Cleanup(srv.Close)
+
+		local := go_cache.NewMemory(time.Minute, time.Minute)
+		group = go_cache.NewPeerGroup("articles", srv.URL, local, loader, peerArticle{})
+		groups[i], addrs[i] = group, srv.URL
+	}
+	for _, g := range groups {
+		g.SetPeers(addrs...)
+	}
+	return groups
+}
+
+// TestPeerGroupLoadsOnce 测试每个键只由负责节点加载一次
+func TestPeerGroupLoadsOnce(t *testing.T) {
+	ctx := context.Background()
+	var loads atomic.Int32
+	groups := newPeerCluster(t, 3, &loads)
+
+	const keys = 20
+	for i := 0; i < keys; i++ {
+		key := fmt.Sprintf("article:%d", i)
+		for _, g := range groups {
+			var a peerArticle
+			if err := g.Get(ctx, key, &a); err != nil {
+				t.Fatalf("Get(%q) error = %v", key, err)
+			}
+			if a.ID != key {
+				t.Fatalf("Get(%q) = %+v", key, a)
+			}
+		}
+	}
+
+	if got := loads.Load(); got != keys {
+		t.Errorf("loader调用次数 = %d, want %d", got, keys)
+	}
+}
+
+// TestPeerGroupOwnerDown 测试负责节点不可用时回退为本地加载
+func TestPeerGroupOwnerDown(t *testing.T) {
+	ctx := context.Background()
+	var loads atomic.Int32
+	groups := newPeerCluster(t, 1, &loads)
+
+	g := groups[0]
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	g.SetPeers(down.URL)
+
+	var a peerArticle
+	if err := g.Get(ctx, "k", &a); err != nil || a.ID != "k" {
+		t.Fatalf("Get() = %+v, %v", a, err)
+	}
+	if err := g.Get(ctx, "k", &a); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got := loads.Load(); got != 1 {
+		t.Errorf("回退加载的值应保存为热点副本，loader调用次数 = %d", got)
+	}
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// TestPipelineSerializer 测试变换按顺序应用并按相反顺序还原
+func TestPipelineSerializer(t *testing.T) {
+	aes, err := serializer.AESGCM(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		t.Fatalf("AESGCM() error = %v", err)
+	}
+	p := serializer.NewPipeline(serializer.NewGob(),
+		serializer.Annotate([]byte("v1:")),
+		serializer.Gzip(gzip.BestSpeed),
+		aes,
+		serializer.HMACSHA256([]byte("secret")),
+	)
+
+	if p.Name() != "gob+annotate+gzip+aes-gcm+hmac-sha256" {
+		t.Errorf("Name() = %q", p.Name())
+	}
+
+	want := TestSerializerUser{ID: 1, Name: "张三", Age: 25}
+	data, err := p.Encode(want)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	var got TestSerializerUser
+	if err := p.Decode(data, &got); err != nil || got != want {
+		t.Fatalf("Decode() = %+v, %v", got, err)
+	}
+
+	// 篡改数据后签名校验失败
+	data[0] ^= 0xff
+	if err := p.Decode(data, &got); !errors.Is(err, serializer.ErrInvalidSignature) {
+		t.Errorf("篡改后 Decode() error = %v, want ErrInvalidSignature", err)
+	}
+}
+
+// TestPipelineAnnotateMismatch 测试头部不一致时解码失败
+func TestPipelineAnnotateMismatch(t *testing.T) {
+	v1 := serializer.NewPipeline(serializer.NewJson(), serializer.Annotate([]byte("v1:")))
+	v2 := serializer.NewPipeline(serializer.NewJson(), serializer.Annotate([]byte("v2:")))
+
+	data, _ := v1.Encode("value")
+	var got string
+	if err := v2.Decode(data, &got); !errors.Is(err, serializer.ErrHeaderMismatch) {
+		t.Errorf("Decode() error = %v, want ErrHeaderMismatch", err)
+	}
+}
+
+// TestPipelineWithRedis 测试在Redis缓存中使用变换链
+func TestPipelineWithRedis(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	p := serializer.NewPipeline(serializer.NewGob(), serializer.Gzip(gzip.DefaultCompression))
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisSerializer(p))
+
+	value := string(bytes.Repeat([]byte("abc"), 1000))
+	if err := cache.Set(ctx, "gz", value, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	raw, _ := r.Client.Get(ctx, "gz").Bytes()
+	if len(raw) >= len(value) {
+		t.Errorf("压缩后 %d 字节, 未小于原始的 %d 字节", len(raw), len(value))
+	}
+
+	var got string
+	if err := cache.Get(ctx, "gz", &got); err != nil || got != value {
+		t.Errorf("Get() error = %v", err)
+	}
+}
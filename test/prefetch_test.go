@@ -0,0 +1,130 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// prefetchSource 记录批量加载调用的数据源
+type prefetchSource struct {
+	mu      sync.Mutex
+	batches []string
+	fail    bool
+}
+
+func (s *prefetchSource) load(ctx context.Context, keys []string) (map[string]any, error) {
+	s.mu.Lock()
+	s.batches = append(s.batches, fmt.Sprint(keys))
+	fail := s.fail
+	s.mu.Unlock()
+	if fail {
+		return nil, errors.New("source down")
+	}
+
+	values := make(map[string]any, len(keys))
+	for _, key := range keys {
+		if key != "user:1:missing" {
+			values[key] = "value of " + key
+		}
+	}
+	return values, nil
+}
+
+func (s *prefetchSource) take() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	batches := s.batches
+	s.batches = nil
+	return batches
+}
+
+// TestPrefetcherHint 测试访问关联的键时在后台批量预取其他键
+func TestPrefetcherHint(t *testing.T) {
+	ctx := context.Background()
+	src := &prefetchSource{}
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewPrefetcher(backend, src.load, time.Minute)
+
+	cache.Hint(ctx, "user:1:profile", "user:1:settings", "user:1:permissions")
+	_ = backend.Set(ctx, "user:1:settings", "cached", time.Minute)
+
+	// 第一次访问资料时预取设置与权限，已缓存的设置不会重新加载
+	var s string
+	err := cache.GetSet(ctx, "user:1:profile", time.Minute, &s, func(key string, obj any) error {
+		*obj.(*string) = "profile"
+		return nil
+	})
+	if err != nil || s != "profile" {
+		t.Fatalf("GetSet() = %q, %v", s, err)
+	}
+	cache.Wait()
+
+	if batches := src.take(); len(batches) != 1 || batches[0] != "[user:1:permissions]" {
+		t.Errorf("应只批量加载一次未缓存的键，实际为%v", batches)
+	}
+	if err := backend.Get(ctx, "user:1:permissions", &s); err != nil || s != "value of user:1:permissions" {
+		t.Errorf("预取的值应写入缓存，Get() = %q, %v", s, err)
+	}
+	if err := backend.Get(ctx, "user:1:settings", &s); err != nil || s != "cached" {
+		t.Errorf("已缓存的值不应被覆盖，Get() = %q, %v", s, err)
+	}
+
+	// 全部已缓存时不再加载；没有关联关系的键不触发预取
+	_ = cache.Get(ctx, "user:1:settings", &s)
+	_ = cache.Get(ctx, "other", &s)
+	cache.Wait()
+	if batches := src.take(); len(batches) != 0 {
+		t.Errorf("不应再加载，实际为%v", batches)
+	}
+
+	cache.Forget("user:1:settings")
+	_ = backend.Del(ctx, "user:1:permissions")
+	_ = cache.Get(ctx, "user:1:settings", &s)
+	cache.Wait()
+	if batches := src.take(); len(batches) != 0 {
+		t.Errorf("Forget 后不应预取，实际为%v", batches)
+	}
+}
+
+// TestPrefetcherPrefetch 测试直接预取、加载函数缺少的键与失败回调
+func TestPrefetcherPrefetch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	src := &prefetchSource{}
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+
+	var mu sync.Mutex
+	var failed []string
+	cache := go_cache.NewPrefetcher(backend, src.load, time.Minute,
+		go_cache.WithPrefetchErrorHook(func(key string, err error) {
+			mu.Lock()
+			failed = append(failed, key)
+			mu.Unlock()
+		}),
+	)
+
+	// 调用方的ctx取消不影响后台预取
+	cache.Prefetch(ctx, "user:1:b", "user:1:a", "user:1:missing")
+	cancel()
+	cache.Wait()
+	if batches := src.take(); len(batches) != 1 || batches[0] != "[user:1:a user:1:b user:1:missing]" {
+		t.Errorf("批量加载的键不正确: %v", batches)
+	}
+	if !backend.Exists(context.Background(), "user:1:a") || backend.Exists(context.Background(), "user:1:missing") {
+		t.Error("只有加载函数返回的键应写入缓存")
+	}
+
+	src.fail = true
+	cache.Prefetch(context.Background(), "user:2:a")
+	cache.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(failed) != 1 || failed[0] != "user:2:a" {
+		t.Errorf("加载失败时应调用错误回调，实际为%v", failed)
+	}
+}
@@ -0,0 +1,92 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// presenceBackends 在内存缓存与Redis上运行在线状态的测试，advance 推进缓存的时间
+func presenceBackends() map[string]func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+	return map[string]func(t *testing.T) (gsr.Cacher, func(time.Duration)){
+		"memory": func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+			clock := go_cache.NewFakeClock(time.Time{})
+			return go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)), clock.Advance
+		},
+		"redis": func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+			r, _ := newRedisTest(t)
+			if r.Server == nil {
+				t.Skip("需要 miniredis 控制服务端时间")
+			}
+			now := time.Now()
+			r.Server.SetTime(now)
+			return r.Cache, func(d time.Duration) {
+				now = now.Add(d)
+				r.Server.SetTime(now)
+				r.Server.FastForward(d)
+			}
+		},
+	}
+}
+
+// TestPresence 测试心跳、按前缀列出、过期与离开
+func TestPresence(t *testing.T) {
+	for name, backend := range presenceBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, advance := backend(t)
+			p := go_cache.NewPresence(cache, "online")
+
+			for _, id := range []string{"worker:2", "worker:1", "user:1"} {
+				if err := p.Heartbeat(ctx, id, 10*time.Second); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if ids, err := p.ListAlive(ctx, ""); err != nil || !slices.Equal(ids, []string{"user:1", "worker:1", "worker:2"}) {
+				t.Errorf("ListAlive(\"\") = %v, %v", ids, err)
+			}
+			if ids, err := p.ListAlive(ctx, "worker:"); err != nil || !slices.Equal(ids, []string{"worker:1", "worker:2"}) {
+				t.Errorf("ListAlive(worker:) = %v, %v", ids, err)
+			}
+
+			// 只有 worker:1 继续心跳
+			advance(6 * time.Second)
+			if err := p.Heartbeat(ctx, "worker:1", 10*time.Second); err != nil {
+				t.Fatal(err)
+			}
+			advance(6 * time.Second)
+			if ids, err := p.ListAlive(ctx, ""); err != nil || !slices.Equal(ids, []string{"worker:1"}) {
+				t.Errorf("ListAlive() after expiry = %v, %v, want [worker:1]", ids, err)
+			}
+
+			if err := p.Leave(ctx, "worker:1"); err != nil {
+				t.Fatal(err)
+			}
+			if ids, err := p.ListAlive(ctx, ""); err != nil || len(ids) != 0 {
+				t.Errorf("ListAlive() after leave = %v, %v, want empty", ids, err)
+			}
+		})
+	}
+}
+
+// TestPresenceErrors 测试无效的ttl与不支持的缓存
+func TestPresenceErrors(t *testing.T) {
+	ctx := context.Background()
+	p := go_cache.NewPresence(go_cache.NewMemory(time.Minute, time.Minute), "online")
+	if err := p.Heartbeat(ctx, "a", 0); !errors.Is(err, go_cache.ErrInvalidTTL) {
+		t.Errorf("Heartbeat() with zero ttl error = %v, want ErrInvalidTTL", err)
+	}
+
+	none := go_cache.NewPresence(go_cache.NewNone(), "online")
+	if err := none.Heartbeat(ctx, "a", time.Second); !errors.Is(err, go_cache.ErrNotSupported) {
+		t.Errorf("Heartbeat() on None error = %v, want ErrNotSupported", err)
+	}
+	if _, err := none.ListAlive(ctx, ""); !errors.Is(err, go_cache.ErrNotSupported) {
+		t.Errorf("ListAlive() on None error = %v, want ErrNotSupported", err)
+	}
+}
@@ -0,0 +1,68 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestMemoryPriorityEviction 测试超出内存上限时优先淘汰低优先级条目
+func TestMemoryPriorityEviction(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute,
+		go_cache.WithMemorySizer(func(value any) int64 { return 100 }),
+		go_cache.WithMemoryMaxBytes(300),
+	)
+	ctx := context.Background()
+
+	_ = cache.SetWithPriority(ctx, "critical", "v", time.Minute, go_cache.PriorityCritical)
+	_ = cache.SetWithPriority(ctx, "high", "v", time.Minute, go_cache.PriorityHigh)
+	_ = cache.SetWithPriority(ctx, "low", "v", time.Minute, go_cache.PriorityLow)
+	_ = cache.Set(ctx, "normal1", "v", time.Minute)
+
+	// 最早写入的是critical，但应先淘汰low
+	if cache.Exists(ctx, "low") {
+		t.Error("低优先级条目应最先被淘汰")
+	}
+
+	_ = cache.Set(ctx, "normal2", "v", time.Minute)
+	if cache.Exists(ctx, "normal1") {
+		t.Error("同优先级中应淘汰最早写入的条目")
+	}
+	for _, key := range []string{"critical", "high", "normal2"} {
+		if !cache.Exists(ctx, key) {
+			t.Errorf("条目 %s 不应被淘汰", key)
+		}
+	}
+}
+
+// TestLoadShedderHighPriorityIsCritical 测试负载保护期间高优先级写入不被丢弃
+func TestLoadShedderHighPriorityIsCritical(t *testing.T) {
+	backend := newSlowCache()
+	backend.delay.Store(int64(5 * time.Millisecond))
+	cache := go_cache.NewLoadShedder(backend, time.Millisecond,
+		go_cache.WithLoadShedWindow(5),
+		go_cache.WithLoadShedMinSamples(5),
+		go_cache.WithLoadShedProbeInterval(time.Hour),
+	)
+	ctx := context.Background()
+
+	var s string
+	for i := 0; i < 5; i++ {
+		_ = cache.Get(ctx, "k", &s)
+	}
+	if !cache.Shedding() {
+		t.Fatal("应进入负载保护")
+	}
+
+	_ = cache.SetWithPriority(ctx, "low", "v", time.Minute, go_cache.PriorityLow)
+	_ = cache.SetWithPriority(ctx, "high", "v", time.Minute, go_cache.PriorityHigh)
+
+	if backend.Exists(ctx, "low") {
+		t.Error("低优先级写入应被丢弃")
+	}
+	if !backend.Exists(ctx, "high") {
+		t.Error("高优先级写入应被执行")
+	}
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+type pubsubEvent struct {
+	ID   int
+	Name string
+}
+
+// pubsubBackends 在内存缓存与Redis上运行发布订阅的测试
+func pubsubBackends() map[string]func(t *testing.T) go_cache.PubSubCache {
+	return map[string]func(t *testing.T) go_cache.PubSubCache{
+		"memory": func(t *testing.T) go_cache.PubSubCache {
+			return go_cache.NewMemory(time.Minute, time.Minute)
+		},
+		"redis": func(t *testing.T) go_cache.PubSubCache {
+			r, _ := newRedisTest(t)
+			return r.Cache
+		},
+	}
+}
+
+// receive 等待一条消息
+func receive(t *testing.T, ch <-chan pubsubEvent) pubsubEvent {
+	t.Helper()
+	select {
+	case ev := <-ch:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for message")
+		return pubsubEvent{}
+	}
+}
+
+// TestPubSub 测试发布的消息按顺序投递给订阅者并解码为原类型
+func TestPubSub(t *testing.T) {
+	for name, backend := range pubsubBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			ps := backend(t)
+
+			ch := make(chan pubsubEvent, 10)
+			cancel, err := ps.Subscribe(ctx, "events", func(msg *go_cache.Message) {
+				if msg.Channel != "events" {
+					t.Errorf("Channel = %q, want events", msg.Channel)
+				}
+				var ev pubsubEvent
+				if err := msg.Decode(&ev); err != nil {
+					t.Error(err)
+					return
+				}
+				ch <- ev
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer cancel()
+
+			if err := ps.Publish(ctx, "other", pubsubEvent{ID: 0}); err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i <= 3; i++ {
+				if err := ps.Publish(ctx, "events", pubsubEvent{ID: i, Name: "created"}); err != nil {
+					t.Fatal(err)
+				}
+			}
+			for i := 1; i <= 3; i++ {
+				if ev := receive(t, ch); ev.ID != i || ev.Name != "created" {
+					t.Errorf("message %d = %+v", i, ev)
+				}
+			}
+
+			cancel()
+			if err := ps.Publish(ctx, "events", pubsubEvent{ID: 4}); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case ev := <-ch:
+				t.Errorf("received %+v after cancel", ev)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+// TestPubSubContextCancel 测试ctx结束时取消订阅
+func TestPubSubContextCancel(t *testing.T) {
+	for name, backend := range pubsubBackends() {
+		t.Run(name, func(t *testing.T) {
+			ps := backend(t)
+			ctx, cancel := context.WithCancel(context.Background())
+
+			ch := make(chan pubsubEvent, 10)
+			if _, err := ps.Subscribe(ctx, "events", func(msg *go_cache.Message) {
+				var ev pubsubEvent
+				if err := msg.Decode(&ev); err == nil {
+					ch <- ev
+				}
+			}); err != nil {
+				t.Fatal(err)
+			}
+			if err := ps.Publish(context.Background(), "events", pubsubEvent{ID: 1}); err != nil {
+				t.Fatal(err)
+			}
+			receive(t, ch)
+
+			cancel()
+			// 取消订阅在单独的goroutine中完成
+			time.Sleep(50 * time.Millisecond)
+			if err := ps.Publish(context.Background(), "events", pubsubEvent{ID: 2}); err != nil {
+				t.Fatal(err)
+			}
+			select {
+			case ev := <-ch:
+				t.Errorf("received %+v after ctx cancel", ev)
+			case <-time.After(50 * time.Millisecond):
+			}
+		})
+	}
+}
+
+// TestSupportsPubSub 测试内存缓存与Redis缓存支持发布订阅
+func TestSupportsPubSub(t *testing.T) {
+	r, _ := newRedisTest(t)
+	if !go_cache.Supports(go_cache.NewMemory(time.Minute, time.Minute), go_cache.CapabilityPubSub) {
+		t.Error("Memory should support pubsub")
+	}
+	if !go_cache.Supports(r.Cache, go_cache.CapabilityPubSub) {
+		t.Error("Redis should support pubsub")
+	}
+	if go_cache.Supports(go_cache.NewCacheNone(), go_cache.CapabilityPubSub) {
+		t.Error("None should not support pubsub")
+	}
+}
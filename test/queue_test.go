@@ -0,0 +1,373 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// blockedListeners 创建一个执行goroutine、队列长度为2的过期监听器，
+// 第一个事件"a"在监听器中阻塞直到调用返回的release，之后的事件进入队列
+func blockedListeners(t *testing.T, policy go_cache.QueuePolicy) (*go_cache.ExpiryListeners, func() []string, func()) {
+	t.Helper()
+	l := go_cache.NewExpiryListeners(
+		go_cache.WithExpiryWorkers(1),
+		go_cache.WithExpiryQueue(go_cache.QueueConfig{Size: 2, Policy: policy}),
+	)
+
+	started, gate := make(chan struct{}), make(chan struct{})
+	var mu sync.Mutex
+	var got []string
+	l.OnExpire("*", func(key string) {
+		if key == "a" {
+			close(started)
+			<-gate
+		}
+		mu.Lock()
+		got = append(got, key)
+		mu.Unlock()
+	})
+
+	l.Notify("a")
+	<-started
+	var once sync.Once
+	release := func() { once.Do(func() { close(gate) }) }
+	t.Cleanup(func() {
+		release()
+		l.Close()
+	})
+	return l, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), got...)
+	}, release
+}
+
+// TestAsyncQueuePolicies 测试队列已满时的丢弃与阻塞策略
+func TestAsyncQueuePolicies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("drop_newest", func(t *testing.T) {
+		l, got, release := blockedListeners(t, go_cache.QueueDropNewest)
+		for _, key := range []string{"b", "c", "d"} {
+			l.Notify(key)
+		}
+		if stats := l.QueueStats(); stats.Depth != 2 || stats.Capacity != 2 || stats.Dropped != 1 || stats.Active != 1 {
+			t.Errorf("QueueStats() = %+v", stats)
+		}
+		release()
+		_ = l.Drain(ctx)
+		if keys := got(); !reflect.DeepEqual(keys, []string{"a", "b", "c"}) {
+			t.Errorf("执行的事件 = %v，期望丢弃最新的d", keys)
+		}
+	})
+
+	t.Run("drop_oldest", func(t *testing.T) {
+		l, got, release := blockedListeners(t, go_cache.QueueDropOldest)
+		for _, key := range []string{"b", "c", "d"} {
+			l.Notify(key)
+		}
+		release()
+		_ = l.Drain(ctx)
+		if keys := got(); !reflect.DeepEqual(keys, []string{"a", "c", "d"}) {
+			t.Errorf("执行的事件 = %v，期望丢弃最早的b", keys)
+		}
+		if l.Dropped() != 1 {
+			t.Errorf("Dropped() = %d，期望为1", l.Dropped())
+		}
+	})
+
+	t.Run("block", func(t *testing.T) {
+		l, got, release := blockedListeners(t, go_cache.QueueBlock)
+		l.Notify("b")
+		l.Notify("c")
+
+		done := make(chan struct{})
+		go func() {
+			l.Notify("d")
+			close(done)
+		}()
+		select {
+		case <-done:
+			t.Fatal("队列已满时 Notify 应阻塞")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		release()
+		<-done
+		_ = l.Drain(ctx)
+		if keys := got(); !reflect.DeepEqual(keys, []string{"a", "b", "c", "d"}) {
+			t.Errorf("执行的事件 = %v，阻塞策略不应丢弃事件", keys)
+		}
+		if stats := l.QueueStats(); stats.Enqueued != 4 || stats.Processed != 4 || stats.Dropped != 0 || stats.Depth != 0 {
+			t.Errorf("QueueStats() = %+v", stats)
+		}
+	})
+}
+
+// TestAsyncQueueDrain 测试 Drain 按ctx超时以及关闭后丢弃新的任务
+func TestAsyncQueueDrain(t *testing.T) {
+	l, _, release := blockedListeners(t, go_cache.QueueDropNewest)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("事件未执行完时 Drain() error = %v，期望为 DeadlineExceeded", err)
+	}
+
+	release()
+	if err := l.Drain(context.Background()); err != nil {
+		t.Errorf("Drain() error = %v", err)
+	}
+
+	l.Close()
+	l.Notify("late")
+	if l.Dropped() != 1 {
+		t.Errorf("关闭后的事件应被丢弃，Dropped() = %d", l.Dropped())
+	}
+}
+
+// TestPrefetcherQueue 测试预取队列已满时丢弃的预取不会残留正在预取的标记
+func TestPrefetcherQueue(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+
+	gate := make(chan struct{})
+	p := go_cache.NewPrefetcher(cache, func(ctx context.Context, keys []string) (map[string]any, error) {
+		<-gate
+		values := make(map[string]any, len(keys))
+		for _, key := range keys {
+			values[key] = key
+		}
+		return values, nil
+	}, time.Minute, go_cache.WithPrefetchQueue(go_cache.QueueConfig{Size: 1}))
+
+	// 4个执行goroutine各取走一个预取并阻塞，第5个进入队列，第6个被丢弃
+	for _, key := range []string{"k1", "k2", "k3", "k4"} {
+		p.Prefetch(ctx, key)
+		deadline := time.Now().Add(2 * time.Second)
+		for p.QueueStats().Depth != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	p.Prefetch(ctx, "k5")
+	p.Prefetch(ctx, "k6")
+	if stats := p.QueueStats(); stats.Dropped != 1 || stats.Depth != 1 {
+		t.Errorf("QueueStats() = %+v", stats)
+	}
+
+	close(gate)
+	p.Wait()
+	p.Prefetch(ctx, "k6")
+	p.Wait()
+	if !cache.Exists(ctx, "k6") {
+		t.Error("被丢弃的预取应允许再次提交")
+	}
+}
+
+// panicCache 检查键是否存在时panic的缓存
+type panicCache struct {
+	plainCache
+}
+
+func (c panicCache) Exists(ctx context.Context, key string) bool {
+	panic("exists: " + key)
+}
+
+// TestWorkerPool 测试全局默认与功能选项的执行配置，以及panic回调
+func TestWorkerPool(t *testing.T) {
+	ctx := context.Background()
+	previous := go_cache.GetDefaultWorkerPool()
+	t.Cleanup(func() { go_cache.SetDefaultWorkerPool(previous) })
+
+	var mu sync.Mutex
+	var panics []*go_cache.WorkerPanicError
+	go_cache.SetDefaultWorkerPool(go_cache.WorkerPool{
+		Size:  1,
+		Queue: go_cache.QueueConfig{Size: 8},
+		PanicHandler: func(err *go_cache.WorkerPanicError) {
+			mu.Lock()
+			panics = append(panics, err)
+			mu.Unlock()
+		},
+	})
+
+	// 全局默认值生效
+	l := go_cache.NewExpiryListeners()
+	defer l.Close()
+	gate := make(chan struct{})
+	l.OnExpire("*", func(key string) { <-gate })
+	for _, key := range []string{"a", "b", "c"} {
+		l.Notify(key)
+	}
+	if stats := l.QueueStats(); stats.Active != 1 || stats.Capacity != 8 {
+		t.Errorf("全局默认值下 QueueStats() = %+v，期望1个执行goroutine、队列长度8", stats)
+	}
+	close(gate)
+	_ = l.Drain(ctx)
+
+	// 功能选项优先于全局默认值
+	override := go_cache.NewExpiryListeners(go_cache.WithExpiryWorkerPool(go_cache.WorkerPool{Queue: go_cache.QueueConfig{Size: 3}}))
+	defer override.Close()
+	if stats := override.QueueStats(); stats.Capacity != 3 {
+		t.Errorf("功能选项下 Capacity = %d，期望为3", stats.Capacity)
+	}
+
+	// panic被恢复并交给全局的panic回调，执行goroutine继续处理后续任务
+	p := go_cache.NewPrefetcher(panicCache{plainCache{go_cache.NewMemory(time.Minute, time.Minute)}},
+		func(ctx context.Context, keys []string) (map[string]any, error) { return nil, nil }, time.Minute)
+	p.Prefetch(ctx, "k1")
+	p.Prefetch(ctx, "k2")
+	p.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(panics) != 2 {
+		t.Fatalf("panic回调调用次数 = %d，期望为2", len(panics))
+	}
+	if panics[0].Feature != "prefetch" || panics[0].Value != "exists: k1" || len(panics[0].Stack) == 0 {
+		t.Errorf("WorkerPanicError = %+v", panics[0])
+	}
+	if stats := p.QueueStats(); stats.Processed != 2 {
+		t.Errorf("QueueStats() = %+v", stats)
+	}
+}
+
+// TestBackgroundRefreshQueue 测试特性开关与物化视图的后台刷新经由队列执行，panic交给panic回调
+func TestBackgroundRefreshQueue(t *testing.T) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	features := map[string]int{}
+	pool := go_cache.WorkerPool{PanicHandler: func(err *go_cache.WorkerPanicError) {
+		mu.Lock()
+		features[err.Feature]++
+		mu.Unlock()
+	}}
+
+	// 特性开关：首次同步加载成功，后台刷新时panic
+	clock := go_cache.NewFakeClock(time.Time{})
+	var loads atomic.Int32
+	flags := go_cache.NewFlags(
+		go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)),
+		go_cache.FlagSourceFunc(func(ctx context.Context, name string) (go_cache.Flag, error) {
+			if loads.Add(1) > 1 {
+				panic("flag source")
+			}
+			return go_cache.Flag{Enabled: true}, nil
+		}),
+		go_cache.WithFlagsTTL(30*time.Second),
+		go_cache.WithFlagsRefreshAhead(10*time.Second),
+		go_cache.WithFlagsClock(clock),
+		go_cache.WithFlagsWorkerPool(pool),
+	)
+	if !flags.IsEnabled(ctx, "f", "u1") {
+		t.Fatal("flag should be enabled")
+	}
+	clock.Advance(25 * time.Second)
+	if !flags.IsEnabled(ctx, "f", "u1") {
+		t.Error("stale flag should be served while refreshing")
+	}
+	waitFor(t, func() bool { return flags.QueueStats().Processed == 1 })
+	if stats := flags.QueueStats(); stats.Enqueued != 1 || stats.Capacity != 1024 {
+		t.Errorf("Flags.QueueStats() = %+v", stats)
+	}
+	// panic之后同一个开关可以再次刷新
+	flags.IsEnabled(ctx, "f", "u1")
+	waitFor(t, func() bool { return flags.QueueStats().Processed == 2 })
+
+	// 物化视图：重新计算panic后，之后的失效仍会触发重新计算
+	graph := go_cache.NewDependencyGraph(go_cache.NewMemory(time.Minute, time.Minute))
+	_ = graph.Set(ctx, "user:1", "alice", time.Minute)
+	var fail atomic.Bool
+	view := go_cache.NewMaterializedView(graph, "users:count", []string{"user:1"}, time.Minute,
+		func(ctx context.Context) (any, error) {
+			if fail.Load() {
+				panic("view loader")
+			}
+			return 1, nil
+		},
+		go_cache.WithViewEager(),
+		go_cache.WithViewWorkerPool(pool))
+	if err := view.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	fail.Store(true)
+	_ = graph.Set(ctx, "user:1", "bob", time.Minute)
+	view.Wait()
+	fail.Store(false)
+	_ = graph.Set(ctx, "user:1", "carol", time.Minute)
+	view.Wait()
+	if !graph.Exists(ctx, view.Key()) {
+		t.Error("panic之后数据源更新时视图应重新计算")
+	}
+	if stats := view.QueueStats(); stats.Processed != 2 {
+		t.Errorf("MaterializedView.QueueStats() = %+v", stats)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if features["flags"] != 2 || features["view"] != 1 {
+		t.Errorf("panic回调的调用 = %v，期望 flags 2次、view 1次", features)
+	}
+}
+
+// TestPrefetcherQueuePriority 测试预取队列已满时先丢弃由 Hint 关联触发的低优先级预取
+func TestPrefetcherQueuePriority(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+
+	gate := make(chan struct{})
+	p := go_cache.NewPrefetcher(cache, func(ctx context.Context, keys []string) (map[string]any, error) {
+		<-gate
+		values := make(map[string]any, len(keys))
+		for _, key := range keys {
+			values[key] = key
+		}
+		return values, nil
+	}, time.Minute, go_cache.WithPrefetchQueue(go_cache.QueueConfig{Size: 1}))
+	p.Hint(ctx, "user", "related")
+	p.Hint(ctx, "order", "other")
+
+	// 4个执行goroutine各取走一个预取并阻塞
+	for _, key := range []string{"k1", "k2", "k3", "k4"} {
+		p.Prefetch(ctx, key)
+		deadline := time.Now().Add(2 * time.Second)
+		for p.QueueStats().Depth != 0 && time.Now().Before(deadline) {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	// 关联触发的预取进入队列，之后显式的预取将其挤出
+	var v string
+	_ = p.Get(ctx, "user", &v)
+	p.Prefetch(ctx, "explicit")
+	// 队列中已是显式的预取，新的关联预取被丢弃
+	_ = p.Get(ctx, "order", &v)
+	if stats := p.QueueStats(); stats.Dropped != 2 || stats.Depth != 1 {
+		t.Errorf("QueueStats() = %+v", stats)
+	}
+
+	close(gate)
+	p.Wait()
+	if !cache.Exists(ctx, "explicit") {
+		t.Error("显式的预取应优先保留")
+	}
+	if cache.Exists(ctx, "related") || cache.Exists(ctx, "other") {
+		t.Error("低优先级的关联预取应被丢弃")
+	}
+
+	// 被丢弃的关联预取不会残留正在预取的标记
+	_ = p.Get(ctx, "user", &v)
+	p.Wait()
+	if !cache.Exists(ctx, "related") {
+		t.Error("被丢弃的关联预取应允许再次提交")
+	}
+}
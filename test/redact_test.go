@@ -0,0 +1,100 @@
+package test
+
+import (
+	"strings"
+	"testing"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+type redactAccount struct {
+	ID       int
+	Email    string `cache:"redact"`
+	Token    string `cache:"redact"`
+	Balance  int    `cache:"redact"`
+	Contacts []redactContact
+}
+
+type redactContact struct {
+	Name  string
+	Phone string
+}
+
+type redactEmail string
+
+// TestRedactStructTags 测试按结构体标签脱敏
+func TestRedactStructTags(t *testing.T) {
+	account := &redactAccount{
+		ID:       1,
+		Email:    "alice@example.com",
+		Token:    "secret",
+		Balance:  100,
+		Contacts: []redactContact{{Name: "bob", Phone: "123"}},
+	}
+
+	out, ok := go_cache.Redact(account).(*redactAccount)
+	if !ok {
+		t.Fatalf("Redact() 应返回相同类型")
+	}
+	if out.ID != 1 || out.Email != go_cache.RedactedValue || out.Token != go_cache.RedactedValue || out.Balance != 0 {
+		t.Errorf("脱敏结果不正确: %+v", out)
+	}
+	if account.Email != "alice@example.com" {
+		t.Error("Redact 不应修改原值")
+	}
+
+	// 注册第三方类型的字段
+	go_cache.RegisterRedactedFields(redactContact{}, "Phone")
+	out = go_cache.Redact(account).(*redactAccount)
+	if out.Contacts[0].Phone != go_cache.RedactedValue || out.Contacts[0].Name != "bob" {
+		t.Errorf("注册字段脱敏不正确: %+v", out.Contacts)
+	}
+	if account.Contacts[0].Phone != "123" {
+		t.Error("Redact 不应修改原切片")
+	}
+}
+
+// TestRedactCustomRedactor 测试自定义脱敏函数
+func TestRedactCustomRedactor(t *testing.T) {
+	go_cache.RegisterRedactor(redactEmail(""), func(value any) any {
+		email := string(value.(redactEmail))
+		if i := strings.Index(email, "@"); i > 0 {
+			return redactEmail("***" + email[i:])
+		}
+		return redactEmail(go_cache.RedactedValue)
+	})
+
+	values := map[string]redactEmail{"a": "alice@example.com"}
+	out := go_cache.Redact(values).(map[string]redactEmail)
+	if out["a"] != "***@example.com" {
+		t.Errorf("自定义脱敏结果不正确: %v", out["a"])
+	}
+
+	if got := go_cache.Redact("plain"); got != "plain" {
+		t.Errorf("普通值不应被修改: %v", got)
+	}
+}
+
+// redactTeam 带有数组字段的结构体
+type redactTeam struct {
+	Name    string
+	Members [2]redactAccount
+}
+
+// TestRedactArray 测试数组中的元素同样被脱敏
+func TestRedactArray(t *testing.T) {
+	team := redactTeam{Name: "core", Members: [2]redactAccount{
+		{ID: 1, Email: "alice@example.com"},
+		{ID: 2, Email: "bob@example.com"},
+	}}
+
+	out := go_cache.Redact(team).(redactTeam)
+	for i, member := range out.Members {
+		if member.Email != go_cache.RedactedValue || member.ID != i+1 {
+			t.Errorf("Members[%d] = %+v", i, member)
+		}
+	}
+	if team.Members[0].Email != "alice@example.com" {
+		t.Error("Redact 不应修改原数组")
+	}
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestElastiCacheIAMAuth 测试ElastiCache IAM认证令牌的预签名格式
+func TestElastiCacheIAMAuth(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC))
+	provider := aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "session"}, nil
+	})
+
+	fetch := go_cache.ElastiCacheIAMAuth("app", "my-cache", "us-east-1", provider, go_cache.WithElastiCacheClock(clock))
+	user, token, err := fetch(ctx)
+	if err != nil {
+		t.Fatalf("ElastiCacheIAMAuth() error = %v", err)
+	}
+	if user != "app" {
+		t.Errorf("用户名 = %q，期望 app", user)
+	}
+	rest, ok := strings.CutPrefix(token, "my-cache/?")
+	if !ok {
+		t.Fatalf("令牌 = %q，应以 my-cache/? 开头且不包含协议", token)
+	}
+	q, err := url.ParseQuery(rest)
+	if err != nil {
+		t.Fatalf("解析令牌 error = %v", err)
+	}
+	want := map[string]string{
+		"Action":               "connect",
+		"User":                 "app",
+		"X-Amz-Algorithm":      "AWS4-HMAC-SHA256",
+		"X-Amz-Credential":     "AKIDEXAMPLE/20240102/us-east-1/elasticache/aws4_request",
+		"X-Amz-Date":           "20240102T030405Z",
+		"X-Amz-Expires":        "900",
+		"X-Amz-SignedHeaders":  "host",
+		"X-Amz-Security-Token": "session",
+	}
+	for name, value := range want {
+		if got := q.Get(name); got != value {
+			t.Errorf("%s = %q，期望 %q", name, got, value)
+		}
+	}
+	if len(q.Get("X-Amz-Signature")) != 64 {
+		t.Errorf("X-Amz-Signature = %q", q.Get("X-Amz-Signature"))
+	}
+	if q.Has("ResourceType") {
+		t.Error("非Serverless缓存不应包含 ResourceType")
+	}
+
+	// 相同时间生成相同的令牌
+	if _, again, _ := fetch(ctx); again != token {
+		t.Error("相同时间与凭据应生成相同的令牌")
+	}
+
+	_, serverless, err := go_cache.ElastiCacheIAMAuth("app", "my-cache", "us-east-1", provider,
+		go_cache.WithElastiCacheClock(clock), go_cache.WithElastiCacheServerless())(ctx)
+	if err != nil || !strings.Contains(serverless, "ResourceType=ServerlessCache") {
+		t.Errorf("Serverless 令牌 = %q, %v", serverless, err)
+	}
+
+	fail := errors.New("no credentials")
+	_, _, err = go_cache.ElastiCacheIAMAuth("app", "my-cache", "us-east-1", aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+		return aws.Credentials{}, fail
+	}))(ctx)
+	if !errors.Is(err, fail) {
+		t.Errorf("获取AWS凭据失败时 error = %v", err)
+	}
+}
+
+// testJWT 生成载荷为payload的未签名JWT
+func testJWT(payload string) string {
+	enc := base64.RawURLEncoding
+	return enc.EncodeToString([]byte(`{"alg":"none"}`)) + "." + enc.EncodeToString([]byte(payload)) + ".sig"
+}
+
+// TestAzureEntraAuth 测试从实例元数据服务获取托管身份令牌并以oid作为用户名
+func TestAzureEntraAuth(t *testing.T) {
+	token := testJWT(`{"oid":"object-id","aud":"https://redis.azure.com"}`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata") != "true" || r.URL.Query().Get("resource") != "https://redis.azure.com" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		if r.URL.Query().Get("client_id") != "client" {
+			http.Error(w, "unknown identity", http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"` + token + `","expires_on":"1700000000"}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	user, password, err := go_cache.AzureEntraAuth(go_cache.AzureManagedIdentityToken("client",
+		go_cache.WithCloudTokenEndpoint(server.URL)))(ctx)
+	if err != nil || user != "object-id" || password != token {
+		t.Errorf("AzureEntraAuth() = %q, %q, %v", user, password, err)
+	}
+
+	if _, _, err := go_cache.AzureEntraAuth(go_cache.AzureManagedIdentityToken("",
+		go_cache.WithCloudTokenEndpoint(server.URL)))(ctx); err == nil {
+		t.Error("令牌端点返回错误状态时应返回错误")
+	}
+
+	for _, bad := range []string{"opaque-token", testJWT(`{"sub":"x"}`)} {
+		if _, _, err := go_cache.AzureEntraAuth(func(ctx context.Context) (string, error) { return bad, nil })(ctx); err == nil {
+			t.Errorf("令牌 %q 没有oid时应返回错误", bad)
+		}
+	}
+}
+
+// TestMemorystoreIAMAuth 测试使用GCP元数据服务的访问令牌认证
+func TestMemorystoreIAMAuth(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing header", http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"access_token":"ya29.token","expires_in":3599,"token_type":"Bearer"}`))
+	}))
+	defer server.Close()
+
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	if r.Server == nil {
+		t.Skip("需要miniredis设置服务端的密码")
+	}
+	r.Server.RequireAuth("ya29.token")
+
+	creds, err := go_cache.NewRedisCredentials(ctx, go_cache.MemorystoreIAMAuth(go_cache.GCPMetadataToken(
+		go_cache.WithCloudTokenEndpoint(server.URL))))
+	if err != nil {
+		t.Fatalf("NewRedisCredentials() error = %v", err)
+	}
+	defer creds.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: r.Server.Addr(), CredentialsProviderContext: creds.Provide})
+	defer client.Close()
+	if err := go_cache.NewRedis(client).Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Errorf("使用访问令牌认证后 Set() error = %v", err)
+	}
+
+	empty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer empty.Close()
+	if _, _, err := go_cache.MemorystoreIAMAuth(go_cache.GCPMetadataToken(go_cache.WithCloudTokenEndpoint(empty.URL)))(ctx); err == nil {
+		t.Error("响应中没有 access_token 时应返回错误")
+	}
+}
@@ -0,0 +1,206 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisCompatProfiles 测试各兼容模式的内置结果与名称
+func TestRedisCompatProfiles(t *testing.T) {
+	ctx := context.Background()
+	client := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1"})
+	defer client.Close()
+
+	tests := []struct {
+		mode go_cache.RedisCompat
+		name string
+		want go_cache.RedisFeatures
+	}{
+		{go_cache.CompatRedis, "redis", go_cache.RedisFeatures{GetEx: true, Unlink: true, ExpireConditions: true, InfoStats: true}},
+		{go_cache.CompatValkey, "valkey", go_cache.RedisFeatures{GetEx: true, Unlink: true, ExpireConditions: true, InfoStats: true}},
+		{go_cache.CompatKeyDB, "keydb", go_cache.RedisFeatures{GetEx: true, Unlink: true, InfoStats: true}},
+		{go_cache.CompatDragonfly, "dragonfly", go_cache.RedisFeatures{GetEx: true, Unlink: true, ExpireConditions: true, InfoStats: true}},
+		{go_cache.CompatMiniredis, "miniredis", go_cache.RedisFeatures{GetEx: true, Unlink: true, ExpireConditions: true}},
+	}
+	for _, tt := range tests {
+		if tt.mode.String() != tt.name {
+			t.Errorf("String() = %s，期望 %s", tt.mode, tt.name)
+		}
+		// 内置结果不需要连接服务端
+		got, err := go_cache.NewRedis(client, go_cache.WithRedisCompat(tt.mode)).Features(ctx)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: Features() = %+v, %v，期望 %+v", tt.name, got, err, tt.want)
+		}
+	}
+
+	override := go_cache.RedisFeatures{Unlink: true}
+	got, _ := go_cache.NewRedis(client, go_cache.WithRedisCompat(go_cache.CompatAuto), go_cache.WithRedisFeatures(override)).Features(ctx)
+	if got != override {
+		t.Errorf("WithRedisFeatures 应优先于兼容模式，Features() = %+v", got)
+	}
+}
+
+// TestRedisCompatAuto 测试向服务端探测支持的命令
+func TestRedisCompatAuto(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisCompat(go_cache.CompatAuto))
+
+	got, err := cache.Features(ctx)
+	if err != nil {
+		t.Fatalf("Features() error = %v", err)
+	}
+	if !got.GetEx || !got.Unlink || !got.ExpireConditions {
+		t.Errorf("Features() = %+v，GETEX、UNLINK 与 PEXPIRE 条件应可用", got)
+	}
+	if r.Server != nil && got.InfoStats {
+		t.Errorf("miniredis 不支持 INFO stats 计数，Features() = %+v", got)
+	}
+	if n, _ := r.Client.Exists(ctx, "go_cache:compat:probe").Result(); n != 0 {
+		t.Error("探测不应写入键")
+	}
+
+	// 创建实例时即在后台探测，不需要等到第一次使用
+	if r.Server != nil {
+		before := r.Server.CommandCount()
+		go_cache.NewRedis(r.Client, go_cache.WithRedisCompat(go_cache.CompatAuto))
+		deadline := time.Now().Add(time.Second)
+		for r.Server.CommandCount() == before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if r.Server.CommandCount() == before {
+			t.Error("CompatAuto 应在 NewRedis 时探测服务端")
+		}
+	}
+
+	// 连接失败时返回错误与 CompatRedis 的结果，之后重新探测
+	down := redis.NewClient(&redis.Options{Addr: "127.0.0.1:1", DialTimeout: 100 * time.Millisecond, MaxRetries: -1})
+	defer down.Close()
+	got, err = go_cache.NewRedis(down, go_cache.WithRedisCompat(go_cache.CompatAuto)).Features(ctx)
+	if err == nil {
+		t.Error("无法连接时 Features() 应返回错误")
+	}
+	if !got.ExpireConditions {
+		t.Errorf("探测失败时应按 CompatRedis 处理，Features() = %+v", got)
+	}
+	if _, err := go_cache.DetectRedisFeatures(ctx, down); err == nil {
+		t.Error("无法连接时 DetectRedisFeatures() 应返回错误")
+	}
+}
+
+// TestRedisCompatFallbacks 测试服务端缺少命令时的兼容实现
+func TestRedisCompatFallbacks(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisFeatures(go_cache.RedisFeatures{}))
+
+	mustSet := func(key string, ttl time.Duration) {
+		t.Helper()
+		if err := cache.Set(ctx, key, "v", ttl); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	mustSet("volatile", time.Minute)
+	mustSet("persistent", 0)
+
+	// 与 TestExpiresIfConditions 的结果一致
+	tests := []struct {
+		name string
+		key  string
+		ttl  time.Duration
+		cond go_cache.ExpireCondition
+		want bool
+	}{
+		{"NX 已有过期时间", "volatile", time.Hour, go_cache.ExpireNX, false},
+		{"NX 没有过期时间", "persistent", time.Hour, go_cache.ExpireNX, true},
+		{"XX 已有过期时间", "volatile", 2 * time.Minute, go_cache.ExpireXX, true},
+		{"GT 缩短", "volatile", time.Second, go_cache.ExpireGT, false},
+		{"GT 延长", "volatile", time.Hour, go_cache.ExpireGT, true},
+		{"LT 延长", "volatile", 2 * time.Hour, go_cache.ExpireLT, false},
+		{"LT 缩短", "volatile", 30 * time.Minute, go_cache.ExpireLT, true},
+		{"不存在的键", "missing", time.Hour, go_cache.ExpireXX, false},
+	}
+	for _, tt := range tests {
+		got, err := cache.ExpiresInIf(ctx, tt.key, tt.ttl, tt.cond)
+		if err != nil || got != tt.want {
+			t.Errorf("%s: ExpiresInIf() = %v, %v, want %v", tt.name, got, err, tt.want)
+		}
+	}
+	if ttl, _ := cache.TTL(ctx, "volatile"); ttl <= 29*time.Minute || ttl > 30*time.Minute {
+		t.Errorf("按条件设置后 TTL() = %v，期望约30分钟", ttl)
+	}
+
+	mustSet("persistent2", 0)
+	if ok, _ := cache.ExpiresAtIf(ctx, "persistent2", time.Now().Add(time.Hour), go_cache.ExpireGT); ok {
+		t.Error("GT 不应作用于没有过期时间的键")
+	}
+	if ok, err := cache.ExpiresAtIf(ctx, "persistent2", time.Now().Add(-time.Hour), go_cache.ExpireLT); !ok || err != nil {
+		t.Errorf("LT 应作用于没有过期时间的键，ExpiresAtIf() = %v, %v", ok, err)
+	}
+	if cache.Exists(ctx, "persistent2") {
+		t.Error("过期时间早于当前时间时键应被删除")
+	}
+
+	// 不支持 UNLINK 时使用 DEL
+	mustSet("del:a", time.Minute)
+	mustSet("del:b", time.Minute)
+	mustSet("del:c", time.Minute)
+	if err := cache.DelMulti(ctx, "del:a"); err != nil || cache.Exists(ctx, "del:a") {
+		t.Errorf("DelMulti() error = %v", err)
+	}
+	if n, err := cache.DelByPattern(ctx, "del:*"); err != nil || n != 2 {
+		t.Errorf("DelByPattern() = %d, %v，期望 2", n, err)
+	}
+}
+
+// TestOpenRedisCompat 测试redis驱动的compat参数
+func TestOpenRedisCompat(t *testing.T) {
+	r, _ := newRedisTest(t)
+	addr := r.Client.Options().Addr
+
+	cache, err := go_cache.Open("redis", "redis://"+addr+"/0?compat=keydb")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if got := cache.(*go_cache.Redis).Compat(); got != go_cache.CompatKeyDB {
+		t.Errorf("Compat() = %s，期望 keydb", got)
+	}
+	if _, err := go_cache.Open("redis", "redis://"+addr+"/0?compat=memcached"); err == nil {
+		t.Error("未知的兼容模式应返回错误")
+	}
+}
+
+// TestRedisGetTouch 测试读取的同时刷新过期时间，包括不支持 GETEX 时的兼容实现
+func TestRedisGetTouch(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	for _, f := range []go_cache.RedisFeatures{{GetEx: true}, {}} {
+		cache := go_cache.NewRedis(r.Client, go_cache.WithRedisFeatures(f))
+		if err := cache.Set(ctx, "touch", "v", time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+
+		var got string
+		if err := cache.GetTouch(ctx, "touch", time.Hour, &got); err != nil || got != "v" {
+			t.Errorf("GetEx=%v: GetTouch() = %q, %v", f.GetEx, got, err)
+		}
+		if ttl, _ := cache.TTL(ctx, "touch"); ttl <= 59*time.Minute {
+			t.Errorf("GetEx=%v: GetTouch 后 TTL() = %v，期望约1小时", f.GetEx, ttl)
+		}
+		if err := cache.GetTouch(ctx, "touch", 0, &got); err != nil {
+			t.Errorf("GetEx=%v: GetTouch() error = %v", f.GetEx, err)
+		}
+		if ttl, _ := cache.TTL(ctx, "touch"); ttl > 0 {
+			t.Errorf("GetEx=%v: ttl=0 时应移除过期时间，TTL() = %v", f.GetEx, ttl)
+		}
+		if err := cache.GetTouch(ctx, "missing", time.Hour, &got); !errors.Is(err, go_cache.ErrKeyNotFound) {
+			t.Errorf("GetEx=%v: 键不存在时 GetTouch() error = %v", f.GetEx, err)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/auth"
+)
+
+// credentialsListener 记录收到的凭据
+type credentialsListener struct {
+	mu        sync.Mutex
+	passwords []string
+}
+
+func (l *credentialsListener) OnNext(creds auth.Credentials) {
+	_, password := creds.BasicAuth()
+	l.mu.Lock()
+	l.passwords = append(l.passwords, password)
+	l.mu.Unlock()
+}
+
+func (l *credentialsListener) OnError(err error) {}
+
+func (l *credentialsListener) received() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]string(nil), l.passwords...)
+}
+
+// TestRedisCredentials 测试凭据轮换后新连接使用新凭据，获取失败时继续使用当前凭据
+func TestRedisCredentials(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	if r.Server == nil {
+		t.Skip("需要miniredis修改服务端的密码")
+	}
+	r.Server.RequireUserAuth("app", "token-1")
+
+	var password atomic.Value
+	password.Store("token-1")
+	fail := errors.New("token service unavailable")
+	var failing atomic.Bool
+	creds, err := go_cache.NewRedisCredentials(ctx, func(ctx context.Context) (string, string, error) {
+		if failing.Load() {
+			return "", "", fail
+		}
+		return "app", password.Load().(string), nil
+	})
+	if err != nil {
+		t.Fatalf("NewRedisCredentials() error = %v", err)
+	}
+	defer creds.Close()
+
+	newClient := func() *redis.Client {
+		client := redis.NewClient(&redis.Options{Addr: r.Server.Addr(), CredentialsProviderContext: creds.Provide})
+		t.Cleanup(func() { _ = client.Close() })
+		return client
+	}
+	cache := go_cache.NewRedis(newClient())
+	if err := cache.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("使用初始凭据 Set() error = %v", err)
+	}
+
+	// 服务端轮换密码后，新建的连接使用新的凭据
+	r.Server.RequireUserAuth("app", "token-2")
+	if err := newClient().Ping(ctx).Err(); err == nil {
+		t.Fatal("轮换前新连接使用旧凭据应认证失败")
+	}
+	password.Store("token-2")
+	if err := creds.Rotate(ctx); err != nil {
+		t.Fatalf("Rotate() error = %v", err)
+	}
+	if err := newClient().Get(ctx, "k").Err(); err != nil {
+		t.Errorf("轮换后新连接 GET error = %v", err)
+	}
+
+	// 获取失败时返回错误并继续使用当前的凭据
+	failing.Store(true)
+	if err := creds.Rotate(ctx); !errors.Is(err, fail) {
+		t.Errorf("获取失败时 Rotate() error = %v", err)
+	}
+	if err := newClient().Get(ctx, "k").Err(); err != nil {
+		t.Errorf("获取失败后新连接应继续使用当前凭据，GET error = %v", err)
+	}
+}
+
+// TestRedisCredentialsSubscribe 测试凭据变化时通知订阅者，没有变化时不通知
+func TestRedisCredentialsSubscribe(t *testing.T) {
+	ctx := context.Background()
+	var password atomic.Value
+	password.Store("token-1")
+	creds, err := go_cache.NewRedisCredentials(ctx, func(ctx context.Context) (string, string, error) {
+		return "app", password.Load().(string), nil
+	})
+	if err != nil {
+		t.Fatalf("NewRedisCredentials() error = %v", err)
+	}
+	defer creds.Close()
+
+	listener := &credentialsListener{}
+	current, unsubscribe, err := creds.Subscribe(listener)
+	if err != nil {
+		t.Fatalf("Subscribe() error = %v", err)
+	}
+	if user, pass := current.BasicAuth(); user != "app" || pass != "token-1" {
+		t.Errorf("Subscribe() 凭据 = %s/%s", user, pass)
+	}
+
+	_ = creds.Rotate(ctx)
+	password.Store("token-2")
+	_ = creds.Rotate(ctx)
+	if got := listener.received(); len(got) != 1 || got[0] != "token-2" {
+		t.Errorf("订阅者收到 = %v，期望只在凭据变化时收到 token-2", got)
+	}
+
+	_ = unsubscribe()
+	password.Store("token-3")
+	_ = creds.Rotate(ctx)
+	if got := listener.received(); len(got) != 1 {
+		t.Errorf("取消订阅后不应再收到通知，收到 = %v", got)
+	}
+}
+
+// TestRedisCredentialsRefresh 测试按间隔在后台刷新凭据
+func TestRedisCredentialsRefresh(t *testing.T) {
+	var fetches atomic.Int32
+	var hooked atomic.Int32
+	creds, err := go_cache.NewRedisCredentials(context.Background(), func(ctx context.Context) (string, string, error) {
+		if fetches.Add(1) > 2 {
+			return "", "", errors.New("expired")
+		}
+		return "app", "token", nil
+	}, go_cache.WithCredentialsRefreshInterval(10*time.Millisecond),
+		go_cache.WithCredentialsErrorHook(func(err error) { hooked.Add(1) }))
+	if err != nil {
+		t.Fatalf("NewRedisCredentials() error = %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for hooked.Load() == 0 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	creds.Close()
+	if hooked.Load() == 0 {
+		t.Errorf("后台刷新失败时应调用错误回调，获取次数 = %d", fetches.Load())
+	}
+
+	_, err = go_cache.NewRedisCredentials(context.Background(), func(ctx context.Context) (string, string, error) {
+		return "", "", errors.New("denied")
+	})
+	if err == nil {
+		t.Error("首次获取凭据失败时 NewRedisCredentials 应返回错误")
+	}
+}
@@ -0,0 +1,122 @@
+package test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisHealthProbe 测试Redis不可达时创建缓存、连接恢复后自动可用
+func TestRedisHealthProbe(t *testing.T) {
+	ctx := context.Background()
+
+	// 先占用一个地址再关闭，创建缓存时该地址不可达
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	var reconnects atomic.Int32
+	cache := go_cache.NewRedis(client,
+		go_cache.WithRedisHealthProbe(10*time.Millisecond),
+		go_cache.WithRedisOnReconnect(func(ctx context.Context) { reconnects.Add(1) }))
+	defer cache.Close()
+
+	if cache.Healthy() {
+		t.Error("探测成功之前不应可用")
+	}
+	start := time.Now()
+	if err := cache.Set(ctx, "k", "v", time.Minute); !go_cache.IsRetryable(err) {
+		t.Errorf("不可用时 Set() error = %v，期望 ErrUnavailable", err)
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("不可用时应直接返回，耗时 %v", elapsed)
+	}
+
+	// Redis启动后探测成功
+	server = miniredis.NewMiniRedis()
+	if err := server.StartAddr(addr); err != nil {
+		t.Skipf("无法在原地址重新启动 miniredis: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 2*time.Second)
+	defer cancel()
+	if err := cache.WaitHealthy(waitCtx); err != nil {
+		t.Fatalf("WaitHealthy() error = %v", err)
+	}
+	if err := cache.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Errorf("连接恢复后 Set() error = %v", err)
+	}
+	if n := reconnects.Load(); n != 1 {
+		t.Errorf("回调次数 = %d，期望 1", n)
+	}
+
+	// 连接断开后命令返回连接错误，之后直接返回
+	server.Close()
+	var s string
+	if err := cache.Get(ctx, "k", &s); !go_cache.IsRetryable(err) {
+		t.Errorf("断开后 Get() error = %v", err)
+	}
+	if cache.Healthy() {
+		t.Error("命令返回连接错误后应标记为不可用")
+	}
+
+	server = miniredis.NewMiniRedis()
+	if err := server.StartAddr(addr); err != nil {
+		t.Skipf("无法在原地址重新启动 miniredis: %v", err)
+	}
+	defer server.Close()
+	if err := cache.WaitHealthy(waitCtx); err != nil {
+		t.Fatalf("WaitHealthy() error = %v", err)
+	}
+	if n := reconnects.Load(); n != 2 {
+		t.Errorf("回调次数 = %d，期望 2", n)
+	}
+}
+
+// TestRedisHealthDefault 测试没有开启探测时的行为
+func TestRedisHealthDefault(t *testing.T) {
+	r, _ := newRedisTest(t)
+	if !r.Cache.Healthy() {
+		t.Error("没有开启探测时 Healthy() 应返回true")
+	}
+	if err := r.Cache.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+}
+
+// TestRedisHealthClose 测试 Close 后探测不再拦截共用连接上的命令
+func TestRedisHealthClose(t *testing.T) {
+	ctx := context.Background()
+
+	server := miniredis.RunT(t)
+	addr := server.Addr()
+	server.Close()
+
+	client := redis.NewClient(&redis.Options{Addr: addr, MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+
+	cache := go_cache.NewRedis(client, go_cache.WithRedisHealthProbe(time.Hour))
+	if err := cache.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	server = miniredis.NewMiniRedis()
+	if err := server.StartAddr(addr); err != nil {
+		t.Skipf("无法在原地址重新启动 miniredis: %v", err)
+	}
+	defer server.Close()
+
+	if err := client.Set(ctx, "k", "v", time.Minute).Err(); err != nil {
+		t.Errorf("Close 后共用连接的 Set() error = %v", err)
+	}
+	if !cache.Healthy() {
+		t.Error("Close 后 Healthy() 应返回true")
+	}
+}
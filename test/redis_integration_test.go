@@ -3,53 +3,30 @@ package test
 import (
 	"context"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/testutil"
 	"github.com/redis/go-redis/v9"
 )
 
-// 测试前检查Redis是否可用
-func setupRedisTest(t *testing.T) (*go_cache.Redis, *redis.Client, func()) {
+// newRedisTest 创建测试用Redis实例
+// 由 testutil 按 REDIS_ADDR、容器、miniredis 的顺序选择Redis实例
+func newRedisTest(tb testing.TB) (*testutil.Redis, func()) {
 	// 检查环境变量，如果设置了SKIP_REDIS_TESTS，则跳过测试
 	if os.Getenv("SKIP_REDIS_TESTS") != "" {
-		t.Skip("跳过Redis集成测试（设置了SKIP_REDIS_TESTS环境变量）")
+		tb.Skip("跳过Redis集成测试（设置了SKIP_REDIS_TESTS环境变量）")
 	}
 
-	// 创建Redis客户端
-	redisAddr := os.Getenv("REDIS_ADDR")
-	if redisAddr == "" {
-		redisAddr = "localhost:6379"
-	}
-
-	rdb := redis.NewClient(&redis.Options{
-		Addr:     redisAddr,
-		Password: "",
-		DB:       15, // 使用DB 15进行测试，避免影响其他数据
-	})
-
-	// 测试连接
-	ctx := context.Background()
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		t.Skipf("Redis不可用，跳过集成测试: %v", err)
-	}
-
-	// 清空测试数据库
-	if err := rdb.FlushDB(ctx).Err(); err != nil {
-		t.Fatalf("清空Redis测试数据库失败: %v", err)
-	}
-
-	cache := go_cache.NewRedis(rdb)
-
-	// 返回清理函数
-	cleanup := func() {
-		// 清理测试数据
-		rdb.FlushDB(ctx)
-		rdb.Close()
-	}
+	return testutil.NewRedis(tb)
+}
 
-	return cache, rdb, cleanup
+// setupRedisTest 创建测试用Redis缓存
+func setupRedisTest(tb testing.TB) (*go_cache.Redis, *redis.Client, func()) {
+	r, cleanup := newRedisTest(tb)
+	return r.Cache, r.Client, cleanup
 }
 
 // TestRedisSetAndGet 测试设置和获取缓存
@@ -220,8 +197,9 @@ func TestRedisGetSet(t *testing.T) {
 
 // TestRedisExpiresIn 测试设置相对过期时间
 func TestRedisExpiresIn(t *testing.T) {
-	cache, _, cleanup := setupRedisTest(t)
+	r, cleanup := newRedisTest(t)
 	defer cleanup()
+	cache := r.Cache
 
 	ctx := context.Background()
 
@@ -242,8 +220,8 @@ func TestRedisExpiresIn(t *testing.T) {
 		t.Fatalf("ExpiresIn() error = %v", err)
 	}
 
-	// 等待1.5秒后，键应该已过期
-	time.Sleep(1500 * time.Millisecond)
+	// 1.5秒后，键应该已过期
+	r.FastForward(1500 * time.Millisecond)
 
 	// 检查键是否已过期
 	if cache.Exists(ctx, "expire_key") {
@@ -253,8 +231,9 @@ func TestRedisExpiresIn(t *testing.T) {
 
 // TestRedisExpiresAt 测试设置绝对过期时间
 func TestRedisExpiresAt(t *testing.T) {
-	cache, _, cleanup := setupRedisTest(t)
+	r, cleanup := newRedisTest(t)
 	defer cleanup()
+	cache := r.Cache
 
 	ctx := context.Background()
 
@@ -276,8 +255,8 @@ func TestRedisExpiresAt(t *testing.T) {
 		t.Fatalf("ExpiresAt() error = %v", err)
 	}
 
-	// 等待1.5秒后，键应该已过期
-	time.Sleep(1500 * time.Millisecond)
+	// 1.5秒后，键应该已过期
+	r.FastForward(1500 * time.Millisecond)
 
 	// 检查键是否已过期
 	if cache.Exists(ctx, "expireat_key") {
@@ -482,7 +461,7 @@ func TestRedisConnectionFailure(t *testing.T) {
 
 // BenchmarkRedisSet 基准测试：设置操作
 func BenchmarkRedisSet(b *testing.B) {
-	cache, _, cleanup := setupRedisTest(&testing.T{})
+	cache, _, cleanup := setupRedisTest(b)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -495,7 +474,7 @@ func BenchmarkRedisSet(b *testing.B) {
 
 // BenchmarkRedisGet 基准测试：获取操作
 func BenchmarkRedisGet(b *testing.B) {
-	cache, _, cleanup := setupRedisTest(&testing.T{})
+	cache, _, cleanup := setupRedisTest(b)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -512,7 +491,7 @@ func BenchmarkRedisGet(b *testing.B) {
 
 // BenchmarkRedisExists 基准测试：检查存在操作
 func BenchmarkRedisExists(b *testing.B) {
-	cache, _, cleanup := setupRedisTest(&testing.T{})
+	cache, _, cleanup := setupRedisTest(b)
 	defer cleanup()
 
 	ctx := context.Background()
@@ -525,3 +504,137 @@ func BenchmarkRedisExists(b *testing.B) {
 		_ = cache.Exists(ctx, "bench_key")
 	}
 }
+
+// TestRedisMemoryUsage 测试查询键的内存占用
+func TestRedisMemoryUsage(t *testing.T) {
+	cache, _, cleanup := setupRedisTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	if err := cache.Set(ctx, "usage_key", "value", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	bytes, err := cache.MemoryUsage(ctx, "usage_key")
+	if err != nil {
+		t.Fatalf("MemoryUsage() error = %v", err)
+	}
+	if bytes <= 0 {
+		t.Errorf("MemoryUsage() 应大于0，实际为%d", bytes)
+	}
+
+	if _, err := cache.MemoryUsage(ctx, "missing_key"); err == nil {
+		t.Error("不存在的键应返回错误")
+	}
+}
+
+// TestRedisSampleLargestKeys 测试采样占用最大的键
+func TestRedisSampleLargestKeys(t *testing.T) {
+	cache, _, cleanup := setupRedisTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	values := map[string]string{
+		"blob:small":  strings.Repeat("s", 10),
+		"blob:medium": strings.Repeat("m", 1000),
+		"blob:large":  strings.Repeat("l", 100000),
+		"other:huge":  strings.Repeat("h", 200000),
+	}
+	for key, value := range values {
+		if err := cache.Set(ctx, key, value, time.Minute); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		n        int
+		pattern  string
+		wantKeys []string
+	}{
+		{name: "取前2个", n: 2, pattern: "blob:*", wantKeys: []string{"blob:large", "blob:medium"}},
+		{name: "n大于匹配数量", n: 10, pattern: "blob:*", wantKeys: []string{"blob:large", "blob:medium", "blob:small"}},
+		{name: "n为0", n: 0, pattern: "blob:*", wantKeys: nil},
+		{name: "空pattern匹配所有键", n: 1, pattern: "", wantKeys: []string{"other:huge"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			top, err := cache.SampleLargestKeys(ctx, tt.n, tt.pattern)
+			if err != nil {
+				t.Fatalf("SampleLargestKeys() error = %v", err)
+			}
+			if len(top) != len(tt.wantKeys) {
+				t.Fatalf("应返回%d个键，实际返回%d个: %+v", len(tt.wantKeys), len(top), top)
+			}
+			for i, want := range tt.wantKeys {
+				if top[i].Key != want {
+					t.Errorf("第%d个键应为 %s，实际为 %s", i, want, top[i].Key)
+				}
+				if top[i].Bytes <= 0 {
+					t.Errorf("键 %s 的内存占用应大于0", top[i].Key)
+				}
+			}
+		})
+	}
+}
+
+// TestRedisStatsContext 测试读取服务端过期与淘汰统计
+func TestRedisStatsContext(t *testing.T) {
+	r, cleanup := newRedisTest(t)
+	defer cleanup()
+	if r.Cache.Compat() == go_cache.CompatMiniredis {
+		t.Skip("miniredis 的 INFO stats 不提供过期计数")
+	}
+	cache := r.Cache
+
+	ctx := context.Background()
+	if err := cache.Set(ctx, "stats_key", "v", 10*time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	before, err := cache.StatsContext(ctx)
+	if err != nil {
+		t.Fatalf("StatsContext() error = %v", err)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	var s string
+	_ = cache.Get(ctx, "stats_key", &s) // 访问触发惰性过期
+
+	after, err := cache.StatsContext(ctx)
+	if err != nil {
+		t.Fatalf("StatsContext() error = %v", err)
+	}
+	if after.Expired <= before.Expired {
+		t.Errorf("过期计数应增加: before=%d after=%d", before.Expired, after.Expired)
+	}
+	if after.Misses != 1 || after.Sets != 1 {
+		t.Errorf("本地统计应保留: %+v", after.StatsCounters)
+	}
+}
+
+// TestRedisKeysAndDelByPattern 测试Redis按模式列出与删除
+func TestRedisKeysAndDelByPattern(t *testing.T) {
+	cache, _, cleanup := setupRedisTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	for _, key := range []string{"user:1", "user:2", "order:1"} {
+		if err := cache.Set(ctx, key, key, time.Minute); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+
+	keys, err := cache.Keys(ctx, "user:*")
+	if err != nil || len(keys) != 2 {
+		t.Errorf("Keys() = %v, %v", keys, err)
+	}
+
+	n, err := cache.DelByPattern(ctx, "user:*")
+	if err != nil || n != 2 {
+		t.Errorf("DelByPattern() = %d, %v", n, err)
+	}
+	if !cache.Exists(ctx, "order:1") {
+		t.Error("不匹配的键不应被删除")
+	}
+}
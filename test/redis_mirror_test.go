@@ -0,0 +1,168 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisMirrorSync 测试全量同步只复制关键键，并移除Redis中已删除的键
+func TestRedisMirrorSync(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	for key, ttl := range map[string]time.Duration{"cfg:a": time.Minute, "cfg:b": 0, "user:1": time.Minute} {
+		if err := r.Cache.Set(ctx, key, "v", ttl); err != nil {
+			t.Fatal(err)
+		}
+	}
+	mirror, err := go_cache.NewRedisMirror(r.Cache, []string{"cfg:"}, go_cache.WithMirrorRefreshInterval(time.Hour))
+	if err != nil {
+		t.Fatalf("NewRedisMirror() error = %v", err)
+	}
+	defer mirror.Close()
+
+	if err := mirror.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if n := mirror.Len(); n != 2 {
+		t.Errorf("Len() = %d，期望只镜像2个关键键", n)
+	}
+
+	if err := r.Cache.Del(ctx, "cfg:a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := mirror.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if n := mirror.Len(); n != 1 {
+		t.Errorf("Len() = %d，Redis中已删除的键应从镜像移除", n)
+	}
+
+	// 通过镜像写入的关键键立即进入镜像
+	if err := mirror.Set(ctx, "cfg:c", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if n := mirror.Len(); n != 2 {
+		t.Errorf("Len() = %d，Set 应同时写入镜像", n)
+	}
+}
+
+// TestRedisMirrorOutage 测试Redis不可用时由镜像提供关键键的读取
+func TestRedisMirrorOutage(t *testing.T) {
+	ctx := context.Background()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1, DialTimeout: 100 * time.Millisecond})
+	defer client.Close()
+	cache := go_cache.NewRedis(client)
+
+	if err := cache.Set(ctx, "cfg:flags", map[string]bool{"beta": true}, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Set(ctx, "user:1", "alice", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	tracker := go_cache.NewDegradationTracker()
+	mirror, err := go_cache.NewRedisMirror(cache, []string{"cfg:"},
+		go_cache.WithMirrorRefreshInterval(time.Hour),
+		go_cache.WithMirrorDegradation(tracker))
+	if err != nil {
+		t.Fatalf("NewRedisMirror() error = %v", err)
+	}
+	defer mirror.Close()
+	if err := mirror.Sync(ctx); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	server.Close()
+
+	var flags map[string]bool
+	if err := mirror.Get(ctx, "cfg:flags", &flags); err != nil || !flags["beta"] {
+		t.Errorf("Get() = %v, %v，Redis不可用时应读取镜像", flags, err)
+	}
+	if !mirror.Exists(ctx, "cfg:flags") {
+		t.Error("Redis不可用时 Exists() 应按镜像判断")
+	}
+
+	var name string
+	if err := mirror.Get(ctx, "user:1", &name); !go_cache.IsRetryable(err) {
+		t.Errorf("非关键键不应由镜像提供，Get() error = %v", err)
+	}
+	if err := mirror.Set(ctx, "cfg:flags", map[string]bool{}, time.Minute); !go_cache.IsRetryable(err) {
+		t.Errorf("不可用期间的写入应返回Redis的错误，Set() error = %v", err)
+	}
+	if err := mirror.Sync(ctx); !go_cache.IsRetryable(err) {
+		t.Errorf("Sync() error = %v，期望可重试的错误", err)
+	}
+
+	report := tracker.Report(time.Minute)
+	if report.Reasons[go_cache.DegradationFallback] != 1 {
+		t.Errorf("由镜像提供的读取应记录为降级，Report() = %+v", report)
+	}
+}
+
+// TestRedisMirrorKeyspaceEvents 测试收到键空间通知时更新镜像
+func TestRedisMirrorKeyspaceEvents(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	if r.Server == nil {
+		// 真实Redis需要开启键空间通知
+		if err := r.Client.ConfigSet(ctx, "notify-keyspace-events", "Kgx$").Err(); err != nil {
+			t.Skipf("无法开启键空间通知: %v", err)
+		}
+	}
+
+	errs := make(chan error, 8)
+	mirror, err := go_cache.NewRedisMirror(r.Cache, []string{"cfg:"},
+		go_cache.WithMirrorRefreshInterval(time.Hour),
+		go_cache.WithMirrorKeyspaceEvents(),
+		go_cache.WithMirrorErrorHook(func(err error) { errs <- err }))
+	if err != nil {
+		t.Fatalf("NewRedisMirror() error = %v", err)
+	}
+	defer mirror.Close()
+	if err := mirror.Sync(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	// 直接写入Redis，不经过镜像
+	if err := r.Cache.Set(ctx, "cfg:live", "v1", 0); err != nil {
+		t.Fatal(err)
+	}
+	if r.Server != nil {
+		// miniredis 不发送键空间通知，手动发布
+		r.Server.Publish("__keyspace@0__:cfg:live", "set")
+	}
+	waitFor(t, func() bool { return mirror.Len() == 1 })
+
+	if err := r.Client.Del(ctx, "cfg:live").Err(); err != nil {
+		t.Fatal(err)
+	}
+	if r.Server != nil {
+		r.Server.Publish("__keyspace@0__:cfg:live", "del")
+	}
+	waitFor(t, func() bool { return mirror.Len() == 0 })
+
+	select {
+	case err := <-errs:
+		t.Errorf("不应报告错误: %v", err)
+	default:
+	}
+}
+
+// waitFor 在1秒内等待条件成立
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
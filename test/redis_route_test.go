@@ -0,0 +1,152 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisRouter 测试按操作将命令路由到不同的客户端
+func TestRedisRouter(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	replicaServer := miniredis.RunT(t)
+	replica := redis.NewClient(&redis.Options{Addr: replicaServer.Addr()})
+	defer replica.Close()
+
+	var mu sync.Mutex
+	routed := map[go_cache.Operation][]string{}
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisRouter(func(ctx context.Context, op go_cache.Operation, key string) redis.UniversalClient {
+		mu.Lock()
+		routed[op] = append(routed[op], key)
+		mu.Unlock()
+		if op == go_cache.OpKeys {
+			return replica
+		}
+		return nil
+	}))
+
+	// 写入使用默认连接
+	if err := cache.Set(ctx, "user:1", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !cache.Exists(ctx, "user:1") {
+		t.Error("返回nil时应使用默认连接")
+	}
+
+	// 扫描路由到副本，副本上只有 user:2
+	replicaCache := go_cache.NewRedis(replica)
+	if err := replicaCache.Set(ctx, "user:2", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := cache.Keys(ctx, "user:*")
+	if err != nil || len(keys) != 1 || keys[0] != "user:2" {
+		t.Errorf("Keys() = %v, %v，应在副本上扫描", keys, err)
+	}
+
+	// DelByPattern 在副本上扫描、在默认连接上删除
+	if err := cache.Set(ctx, "user:2", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if n, err := cache.DelByPattern(ctx, "user:*"); err != nil || n != 1 {
+		t.Errorf("DelByPattern() = %d, %v", n, err)
+	}
+	if cache.Exists(ctx, "user:2") || !cache.Exists(ctx, "user:1") {
+		t.Error("DelByPattern 应删除默认连接上副本扫描到的键")
+	}
+	if !replicaCache.Exists(ctx, "user:2") {
+		t.Error("DelByPattern 不应在副本上删除")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, op := range []go_cache.Operation{go_cache.OpSet, go_cache.OpExists, go_cache.OpKeys, go_cache.OpDelByPattern} {
+		if len(routed[op]) == 0 {
+			t.Errorf("%s 应经过路由", op)
+		}
+	}
+	if got := routed[go_cache.OpKeys]; got[0] != "user:*" {
+		t.Errorf("按模式的操作应以模式作为key，实际为 %v", got)
+	}
+}
+
+// TestRouteReadsTo 测试将读取路由到副本
+func TestRouteReadsTo(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	replicaServer := miniredis.RunT(t)
+	replica := redis.NewClient(&redis.Options{Addr: replicaServer.Addr()})
+	defer replica.Close()
+
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisRouter(go_cache.RouteReadsTo(replica)))
+	if err := cache.Set(ctx, "k", "primary", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	var s string
+	if err := cache.Get(ctx, "k", &s); err == nil {
+		t.Errorf("读取应路由到副本，副本上没有该键，Get() = %q", s)
+	}
+
+	if err := go_cache.NewRedis(replica).Set(ctx, "k", "replica", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Get(ctx, "k", &s); err != nil || s != "replica" {
+		t.Errorf("Get() = %q, %v，期望读取副本的值", s, err)
+	}
+
+	// 写入与设置过期时间使用默认连接
+	if err := cache.ExpiresIn(ctx, "k", time.Hour); err != nil {
+		t.Errorf("ExpiresIn() error = %v", err)
+	}
+	if ttl := r.Client.PTTL(ctx, "k").Val(); ttl <= 59*time.Minute {
+		t.Errorf("默认连接上的 PTTL = %v，期望约1小时", ttl)
+	}
+}
+
+// TestRedisRouterBatch 测试批量操作按每个键路由
+func TestRedisRouterBatch(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	archiveServer := miniredis.RunT(t)
+	archive := redis.NewClient(&redis.Options{Addr: archiveServer.Addr()})
+	defer archive.Close()
+
+	cache := go_cache.NewRedis(r.Client, go_cache.WithRedisRouter(func(ctx context.Context, op go_cache.Operation, key string) redis.UniversalClient {
+		if strings.HasPrefix(key, "archive:") {
+			return archive
+		}
+		return nil
+	}))
+	for _, key := range []string{"live:1", "archive:1"} {
+		if err := cache.Set(ctx, key, key, time.Minute); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+	if archiveServer.Exists("live:1") || !archiveServer.Exists("archive:1") {
+		t.Fatal("archive: 前缀的键应写入归档实例")
+	}
+
+	exists, err := cache.ExistsMulti(ctx, []string{"live:1", "archive:1", "archive:2"})
+	if err != nil || !exists["live:1"] || !exists["archive:1"] || exists["archive:2"] {
+		t.Errorf("ExistsMulti() = %v, %v", exists, err)
+	}
+
+	var live, archived string
+	errs, err := cache.MGet(ctx, map[string]any{"live:1": &live, "archive:1": &archived})
+	if err != nil || errs["live:1"] != nil || errs["archive:1"] != nil || live != "live:1" || archived != "archive:1" {
+		t.Errorf("MGet() = %v, %v, %q, %q", errs, err, live, archived)
+	}
+
+	if err := cache.DelMulti(ctx, "live:1", "archive:1"); err != nil {
+		t.Fatalf("DelMulti() error = %v", err)
+	}
+	if r.Client.Exists(ctx, "live:1").Val() != 0 || archiveServer.Exists("archive:1") {
+		t.Error("DelMulti 应在各自路由到的实例上删除")
+	}
+}
@@ -0,0 +1,64 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+)
+
+// TestRedisMaxOpsPerSecond 测试超出命令数限制的命令在客户端等待
+func TestRedisMaxOpsPerSecond(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	client := redis.NewClient(&redis.Options{Addr: r.Client.Options().Addr})
+	defer client.Close()
+
+	// 每秒50条、最多积累5条：20条命令至少需要等待15条的额度，约300毫秒
+	cache := go_cache.NewRedis(client, go_cache.WithRedisMaxOpsPerSecond(50))
+	start := time.Now()
+	for i := 0; i < 20; i++ {
+		if err := cache.Set(ctx, "throttled", i, time.Minute); err != nil {
+			t.Fatalf("Set() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 250*time.Millisecond {
+		t.Errorf("20条命令耗时 %v，期望被限速到约300毫秒", elapsed)
+	}
+
+	// 等待额度时ctx结束返回ctx的错误
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+	defer cancel()
+	var err error
+	for i := 0; i < 20 && err == nil; i++ {
+		err = cache.Set(waitCtx, "throttled", i, time.Minute)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("等待额度时ctx超时 error = %v，期望为 DeadlineExceeded", err)
+	}
+}
+
+// TestRedisMaxBytesPerSecond 测试回复的数据量推迟之后的命令
+func TestRedisMaxBytesPerSecond(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	_ = r.Client.Set(ctx, "blob", strings.Repeat("x", 20000), 0).Err()
+
+	client := redis.NewClient(&redis.Options{Addr: r.Client.Options().Addr})
+	defer client.Close()
+	go_cache.NewRedis(client, go_cache.WithRedisMaxBytesPerSecond(100000))
+
+	// 积累的额度为10KB，读取20KB的回复透支约0.1秒的额度，下一条命令需要等待
+	if err := client.Get(ctx, "blob").Err(); err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	start := time.Now()
+	_ = client.Exists(ctx, "blob").Err()
+	if elapsed := time.Since(start); elapsed < 75*time.Millisecond {
+		t.Errorf("透支额度后的命令耗时 %v，期望等待约100毫秒", elapsed)
+	}
+}
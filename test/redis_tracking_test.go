@@ -0,0 +1,78 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/testutil"
+)
+
+// newTrackingTest 创建开启客户端缓存的Redis缓存
+// miniredis 不支持 CLIENT TRACKING，需要真实的Redis实例
+func newTrackingTest(t *testing.T) (*go_cache.RedisTracking, *testutil.Redis) {
+	t.Helper()
+
+	r, _ := newRedisTest(t)
+	if r.Backend == testutil.BackendMiniredis {
+		t.Skip("miniredis 不支持 CLIENT TRACKING")
+	}
+
+	tracking, err := go_cache.NewRedisTracking(r.Client.Options())
+	if err != nil {
+		t.Fatalf("NewRedisTracking() error = %v", err)
+	}
+	t.Cleanup(func() { tracking.Close() })
+	return tracking, r
+}
+
+// TestRedisTrackingInvalidation 测试其他客户端修改键后本地副本失效
+func TestRedisTrackingInvalidation(t *testing.T) {
+	ctx := context.Background()
+	tracking, r := newTrackingTest(t)
+
+	if err := r.Cache.Set(ctx, "k", "v1", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	var v string
+	if err := tracking.Get(ctx, "k", &v); err != nil || v != "v1" {
+		t.Fatalf("Get() = %q, %v", v, err)
+	}
+	if tracking.LocalLen() != 1 {
+		t.Fatalf("读取后应保存本地副本，LocalLen() = %d", tracking.LocalLen())
+	}
+
+	// 其他客户端修改键，等待失效消息
+	if err := r.Cache.Set(ctx, "k", "v2", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for tracking.LocalLen() != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := tracking.Get(ctx, "k", &v); err != nil || v != "v2" {
+		t.Errorf("失效后 Get() = %q, %v, want v2", v, err)
+	}
+}
+
+// TestRedisTrackingLocalHit 测试本地副本命中不访问Redis
+func TestRedisTrackingLocalHit(t *testing.T) {
+	ctx := context.Background()
+	tracking, _ := newTrackingTest(t)
+
+	if err := tracking.Set(ctx, "k", 42, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	var n int
+	for i := 0; i < 3; i++ {
+		if err := tracking.Get(ctx, "k", &n); err != nil || n != 42 {
+			t.Fatalf("Get() = %d, %v", n, err)
+		}
+	}
+	if stats := tracking.Stats(); stats.Hits != 3 {
+		t.Errorf("Hits = %d, want 3", stats.Hits)
+	}
+}
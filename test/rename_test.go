@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+type renameBackend struct {
+	cache interface {
+		gsr.Cacher
+		go_cache.RenameCache
+	}
+	advance func(time.Duration)
+}
+
+func renameBackends(t *testing.T) map[string]renameBackend {
+	clock := go_cache.NewFakeClock(time.Time{})
+	r, _ := newRedisTest(t)
+	return map[string]renameBackend{
+		"memory": {go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)), clock.Advance},
+		"redis":  {r.Cache, r.FastForward},
+	}
+}
+
+// TestRename 测试重命名键并保留过期时间
+func TestRename(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range renameBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := b.cache.Set(ctx, "user:v1:1", "alice", time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			if err := b.cache.Set(ctx, "user:v2:1", "stale", 0); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			if err := b.cache.Rename(ctx, "user:v1:1", "user:v2:1"); err != nil {
+				t.Fatalf("Rename() error = %v", err)
+			}
+			if b.cache.Exists(ctx, "user:v1:1") {
+				t.Error("重命名后旧键不应存在")
+			}
+			var v string
+			if err := b.cache.Get(ctx, "user:v2:1", &v); err != nil || v != "alice" {
+				t.Errorf("Get() = %q, %v, want alice", v, err)
+			}
+
+			b.advance(2 * time.Minute)
+			if b.cache.Exists(ctx, "user:v2:1") {
+				t.Error("重命名应保留原有的过期时间")
+			}
+
+			if err := b.cache.Rename(ctx, "user:missing", "x"); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("Rename() 不存在的键 error = %v, want ErrKeyNotFound", err)
+			}
+		})
+	}
+}
+
+// TestCopy 测试复制键并设置新的过期时间
+func TestCopy(t *testing.T) {
+	ctx := context.Background()
+	for name, b := range renameBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := b.cache.Set(ctx, "src", 42, time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			if err := b.cache.Copy(ctx, "src", "dst:short", time.Second); err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if err := b.cache.Copy(ctx, "src", "dst:forever", 0); err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+
+			var n int
+			if err := b.cache.Get(ctx, "dst:short", &n); err != nil || n != 42 {
+				t.Errorf("Get(dst:short) = %d, %v", n, err)
+			}
+
+			b.advance(2 * time.Minute)
+			if b.cache.Exists(ctx, "dst:short") || b.cache.Exists(ctx, "src") {
+				t.Error("src 与 dst:short 应已过期")
+			}
+			if err := b.cache.Get(ctx, "dst:forever", &n); err != nil || n != 42 {
+				t.Errorf("Get(dst:forever) = %d, %v", n, err)
+			}
+
+			if err := b.cache.Copy(ctx, "src", "dst", time.Minute); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("Copy() 不存在的键 error = %v, want ErrKeyNotFound", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestRequestCacheMemoizes 测试同一请求内重复读取只访问一次底层缓存
+func TestRequestCacheMemoizes(t *testing.T) {
+	r, _ := newRedisTest(t)
+	cache := go_cache.NewRequestCache(r.Cache)
+	_ = r.Cache.Set(context.Background(), "user", "alice", time.Minute)
+
+	ctx := go_cache.WithRequestScope(context.Background())
+	for i := 0; i < 5; i++ {
+		var v string
+		if err := cache.Get(ctx, "user", &v); err != nil || v != "alice" {
+			t.Fatalf("Get() = %q, %v", v, err)
+		}
+		if err := cache.Get(ctx, "missing", &v); !errors.Is(err, go_cache.ErrKeyNotFound) {
+			t.Fatalf("Get(missing) error = %v", err)
+		}
+	}
+	stats := r.Cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 1 {
+		t.Errorf("底层 Hits = %d, Misses = %d, want 1, 1", stats.Hits, stats.Misses)
+	}
+
+	// 新的请求重新访问底层缓存
+	var v string
+	_ = cache.Get(go_cache.WithRequestScope(context.Background()), "user", &v)
+	if hits := r.Cache.Stats().Hits; hits != 2 {
+		t.Errorf("新请求后 Hits = %d, want 2", hits)
+	}
+
+	// 没有请求级存储时直接访问底层缓存
+	_ = cache.Get(context.Background(), "user", &v)
+	_ = cache.Get(context.Background(), "user", &v)
+	if hits := r.Cache.Stats().Hits; hits != 4 {
+		t.Errorf("无请求级存储时 Hits = %d, want 4", hits)
+	}
+}
+
+// TestRequestCacheWrites 测试写入与删除更新记忆的结果
+func TestRequestCacheWrites(t *testing.T) {
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewRequestCache(backend)
+	ctx := go_cache.WithRequestScope(context.Background())
+
+	var v string
+	if err := cache.Get(ctx, "k", &v); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Fatalf("Get() error = %v", err)
+	}
+	_ = cache.Set(ctx, "k", "v1", time.Minute)
+	if err := cache.Get(ctx, "k", &v); err != nil || v != "v1" {
+		t.Errorf("写入后 Get() = %q, %v", v, err)
+	}
+
+	loads := 0
+	loader := func(key string, obj any) error {
+		loads++
+		*obj.(*string) = "loaded"
+		return nil
+	}
+	_ = cache.Del(ctx, "k")
+	for i := 0; i < 3; i++ {
+		if err := cache.GetSet(ctx, "k", time.Minute, &v, loader); err != nil || v != "loaded" {
+			t.Fatalf("GetSet() = %q, %v", v, err)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("回调次数 = %d, want 1", loads)
+	}
+	if backend.Stats().Hits != 0 {
+		t.Errorf("GetSet 记忆命中后不应访问底层缓存, Hits = %d", backend.Stats().Hits)
+	}
+}
+
+// TestRequestScopeMiddleware 测试中间件为每个请求创建独立的存储
+func TestRequestScopeMiddleware(t *testing.T) {
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewRequestCache(backend)
+	_ = backend.Set(context.Background(), "k", "v", time.Minute)
+
+	handler := go_cache.RequestScopeMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v string
+		_ = cache.Get(r.Context(), "k", &v)
+		_ = cache.Get(r.Context(), "k", &v)
+	}))
+
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if hits := backend.Stats().Hits; hits != 2 {
+		t.Errorf("Hits = %d, want 2（每个请求一次）", hits)
+	}
+}
@@ -0,0 +1,82 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestRoutedCache 测试按前缀分派到不同后端
+func TestRoutedCache(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	flags := go_cache.NewMemory(time.Minute, time.Minute)
+	fallback := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewRouted(fallback, map[string]gsr.Cacher{
+		"session:": r.Cache,
+		"flag:":    flags,
+	})
+
+	for _, key := range []string{"session:1", "flag:beta", "other:1"} {
+		if err := cache.Set(ctx, key, "v", time.Minute); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+	if !r.Cache.Exists(ctx, "session:1") || !flags.Exists(ctx, "flag:beta") || !fallback.Exists(ctx, "other:1") {
+		t.Error("键应写入前缀对应的后端")
+	}
+	if flags.Exists(ctx, "session:1") || fallback.Exists(ctx, "flag:beta") {
+		t.Error("键不应写入其他后端")
+	}
+	if cache.Backend("session:1") != r.Cache {
+		t.Error("Backend() 应返回前缀对应的后端")
+	}
+
+	var s string
+	if err := cache.Get(ctx, "flag:beta", &s); err != nil || s != "v" {
+		t.Errorf("Get() = %q, %v", s, err)
+	}
+
+	keys, err := cache.Keys(ctx, "*")
+	sort.Strings(keys)
+	if err != nil || len(keys) != 3 || keys[0] != "flag:beta" {
+		t.Errorf("Keys() = %v, %v", keys, err)
+	}
+	if !go_cache.Supports(cache, go_cache.CapabilityPattern) {
+		t.Error("所有后端都支持时应支持按模式操作")
+	}
+
+	if err := cache.Del(ctx, "session:1"); err != nil || r.Cache.Exists(ctx, "session:1") {
+		t.Errorf("Del() error = %v", err)
+	}
+	if n, err := cache.DelByPattern(ctx, "*"); err != nil || n != 2 {
+		t.Errorf("DelByPattern() = %d, %v", n, err)
+	}
+}
+
+// TestRoutedCacheNoFallback 测试没有默认后端时的未匹配键
+func TestRoutedCacheNoFallback(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewRouted(nil, map[string]gsr.Cacher{
+		"a:":   go_cache.NewMemory(time.Minute, time.Minute),
+		"a:b:": go_cache.NewMemory(time.Minute, time.Minute),
+	})
+
+	if err := cache.Set(ctx, "x", "v", time.Minute); !errors.Is(err, go_cache.ErrNoRoute) {
+		t.Errorf("Set() error = %v, want ErrNoRoute", err)
+	}
+	if cache.Exists(ctx, "x") {
+		t.Error("没有后端的键 Exists() 应返回false")
+	}
+	if err := cache.Set(ctx, "a:b:1", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if cache.Backend("a:b:1").Exists(ctx, "a:b:1") == cache.Backend("a:1").Exists(ctx, "a:b:1") {
+		t.Error("应使用最长的前缀")
+	}
+}
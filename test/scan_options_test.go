@@ -0,0 +1,87 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/testcache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestScanOptions 测试按模式操作的键数上限与预览模式
+func TestScanOptions(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	tenant, _ := go_cache.ForTenant(go_cache.NewMemory(time.Minute, time.Minute), "acme")
+	backends := map[string]gsr.Cacher{
+		"memory":    go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":     r.Cache,
+		"testcache": testcache.New(),
+		"tenant":    tenant,
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			pc := backend.(go_cache.PatternCache)
+			for i := 0; i < 10; i++ {
+				_ = backend.Set(ctx, fmt.Sprintf("session:%d", i), i, time.Minute)
+			}
+			_ = backend.Set(ctx, "user:1", 1, time.Minute)
+
+			// 预览：返回将被删除的数量，不删除任何键
+			preview := go_cache.WithScanOptions(ctx, go_cache.ScanOptions{DryRun: true, Count: 2})
+			n, err := pc.DelByPattern(preview, "session:*")
+			if err != nil || n != 10 {
+				t.Errorf("DryRun DelByPattern() = %d, %v，期望为10", n, err)
+			}
+			if keys, _ := pc.Keys(ctx, "session:*"); len(keys) != 10 {
+				t.Errorf("DryRun 后剩余 %d 个键，期望不删除", len(keys))
+			}
+
+			// 键数上限：返回上限内的部分结果与 ErrScanLimitReached
+			limited := go_cache.WithScanOptions(ctx, go_cache.ScanOptions{MaxKeys: 4, Count: 3})
+			keys, err := pc.Keys(limited, "session:*")
+			if !errors.Is(err, go_cache.ErrScanLimitReached) || len(keys) != 4 {
+				t.Errorf("MaxKeys Keys() = %v, %v，期望4个键与 ErrScanLimitReached", keys, err)
+			}
+			n, err = pc.DelByPattern(limited, "session:*")
+			if !errors.Is(err, go_cache.ErrScanLimitReached) || n != 4 {
+				t.Errorf("MaxKeys DelByPattern() = %d, %v，期望删除4个键", n, err)
+			}
+			if keys, _ := pc.Keys(ctx, "session:*"); len(keys) != 6 {
+				t.Errorf("MaxKeys 删除后剩余 %d 个键，期望为6", len(keys))
+			}
+
+			// 匹配的键数不超过上限时不返回错误
+			keys, err = pc.Keys(limited, "user:*")
+			if err != nil || len(keys) != 1 {
+				t.Errorf("未达到上限时 Keys() = %v, %v", keys, err)
+			}
+		})
+	}
+}
+
+// TestScanOptionsMaxDuration 测试超过最长执行时间时停止扫描
+func TestScanOptionsMaxDuration(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	for i := 0; i < 50; i++ {
+		_ = r.Cache.Set(ctx, fmt.Sprintf("k%d", i), i, time.Minute)
+	}
+
+	// 上限极短，处理第一批键之前即已超过最长执行时间
+	scanCtx := go_cache.WithScanOptions(ctx, go_cache.ScanOptions{Count: 1, MaxDuration: time.Nanosecond})
+	time.Sleep(time.Millisecond)
+	n, err := r.Cache.DelByPattern(scanCtx, "k*")
+	if !errors.Is(err, go_cache.ErrScanLimitReached) {
+		t.Errorf("DelByPattern() error = %v，期望为 ErrScanLimitReached", err)
+	}
+	if n >= 50 {
+		t.Errorf("超过最长执行时间后不应删除全部键，删除了 %d 个", n)
+	}
+}
@@ -0,0 +1,210 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// schedulerBackend 创建延迟任务使用的缓存，now 返回判断到期所用的当前时间，advance 推进该时间
+type schedulerBackend func(t *testing.T) (cache gsr.Cacher, opts []go_cache.SchedulerOption, now func() time.Time, advance func(time.Duration))
+
+// schedulerBackends 在内存缓存与Redis上运行延迟任务的测试
+func schedulerBackends() map[string]schedulerBackend {
+	return map[string]schedulerBackend{
+		"memory": func(t *testing.T) (gsr.Cacher, []go_cache.SchedulerOption, func() time.Time, func(time.Duration)) {
+			clock := go_cache.NewFakeClock(time.Unix(1700000000, 0))
+			cache := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+			return cache, []go_cache.SchedulerOption{go_cache.WithSchedulerClock(clock)}, clock.Now, clock.Advance
+		},
+		"redis": func(t *testing.T) (gsr.Cacher, []go_cache.SchedulerOption, func() time.Time, func(time.Duration)) {
+			r, _ := newRedisTest(t)
+			if r.Server == nil {
+				t.Skip("需要 miniredis 控制服务端时间")
+			}
+			now := time.Unix(1700000000, 0)
+			r.Server.SetTime(now)
+			return r.Cache, nil, func() time.Time { return now }, func(d time.Duration) {
+				now = now.Add(d)
+				r.Server.SetTime(now)
+				r.Server.FastForward(d)
+			}
+		},
+	}
+}
+
+// TestSchedulerDelivery 测试任务到期后投递并在处理成功后移除
+func TestSchedulerDelivery(t *testing.T) {
+	for name, backend := range schedulerBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, opts, now, advance := backend(t)
+			s := go_cache.NewScheduler(cache, "emails", opts...)
+
+			if err := s.Schedule(ctx, "welcome:1", "hello", now().Add(time.Minute)); err != nil {
+				t.Fatal(err)
+			}
+			if err := s.Schedule(ctx, "welcome:2", "later", now().Add(time.Hour)); err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			handler := func(ctx context.Context, task *go_cache.ScheduledTask) error {
+				var payload string
+				if err := task.Decode(&payload); err != nil {
+					return err
+				}
+				if task.Attempt != 1 {
+					t.Errorf("Attempt = %d, want 1", task.Attempt)
+				}
+				got = append(got, task.Key+"="+payload)
+				return nil
+			}
+
+			if n, err := s.Poll(ctx, handler); err != nil || n != 0 {
+				t.Fatalf("Poll() before due = %d, %v, want 0", n, err)
+			}
+			advance(time.Minute)
+			if n, err := s.Poll(ctx, handler); err != nil || n != 1 {
+				t.Fatalf("Poll() after due = %d, %v, want 1", n, err)
+			}
+			if len(got) != 1 || got[0] != "welcome:1=hello" {
+				t.Errorf("delivered = %v, want [welcome:1=hello]", got)
+			}
+			if n, err := s.Len(ctx); err != nil || n != 1 {
+				t.Errorf("Len() = %d, %v, want 1", n, err)
+			}
+
+			if ok, err := s.Cancel(ctx, "welcome:2"); err != nil || !ok {
+				t.Errorf("Cancel() = %v, %v, want true", ok, err)
+			}
+			if ok, err := s.Cancel(ctx, "welcome:2"); err != nil || ok {
+				t.Errorf("Cancel() again = %v, %v, want false", ok, err)
+			}
+			advance(time.Hour)
+			if n, err := s.Poll(ctx, handler); err != nil || n != 0 {
+				t.Errorf("Poll() after cancel = %d, %v, want 0", n, err)
+			}
+		})
+	}
+}
+
+// TestSchedulerVisibilityTimeout 测试处理失败的任务在可见性超时后重新投递
+func TestSchedulerVisibilityTimeout(t *testing.T) {
+	for name, backend := range schedulerBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, opts, now, advance := backend(t)
+			var hookKey string
+			opts = append(opts,
+				go_cache.WithSchedulerVisibilityTimeout(10*time.Second),
+				go_cache.WithSchedulerErrorHook(func(key string, err error) { hookKey = key }))
+			s := go_cache.NewScheduler(cache, "jobs", opts...)
+
+			if err := s.Schedule(ctx, "job", 42, now()); err != nil {
+				t.Fatal(err)
+			}
+
+			var attempts []int
+			fail := errors.New("boom")
+			handler := func(ctx context.Context, task *go_cache.ScheduledTask) error {
+				attempts = append(attempts, task.Attempt)
+				if task.Attempt == 1 {
+					return fail
+				}
+				return nil
+			}
+
+			if n, err := s.Poll(ctx, handler); err != nil || n != 1 {
+				t.Fatalf("Poll() = %d, %v, want 1", n, err)
+			}
+			if hookKey != "job" {
+				t.Errorf("error hook key = %q, want job", hookKey)
+			}
+			// 可见性超时内不会重复投递
+			if n, err := s.Poll(ctx, handler); err != nil || n != 0 {
+				t.Fatalf("Poll() within visibility timeout = %d, %v, want 0", n, err)
+			}
+			advance(10 * time.Second)
+			if n, err := s.Poll(ctx, handler); err != nil || n != 1 {
+				t.Fatalf("Poll() after visibility timeout = %d, %v, want 1", n, err)
+			}
+			if len(attempts) != 2 || attempts[1] != 2 {
+				t.Errorf("attempts = %v, want [1 2]", attempts)
+			}
+			if n, err := s.Len(ctx); err != nil || n != 0 {
+				t.Errorf("Len() = %d, %v, want 0", n, err)
+			}
+		})
+	}
+}
+
+// TestSchedulerRescheduleDuringHandler 测试处理期间重新调度的任务不会被确认移除
+func TestSchedulerRescheduleDuringHandler(t *testing.T) {
+	for name, backend := range schedulerBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, opts, now, advance := backend(t)
+			s := go_cache.NewScheduler(cache, "reminders", opts...)
+
+			if err := s.Schedule(ctx, "remind", "first", now()); err != nil {
+				t.Fatal(err)
+			}
+			var payloads []string
+			handler := func(ctx context.Context, task *go_cache.ScheduledTask) error {
+				var payload string
+				if err := task.Decode(&payload); err != nil {
+					return err
+				}
+				payloads = append(payloads, payload)
+				if payload == "first" {
+					return s.Schedule(ctx, "remind", "second", now().Add(time.Minute))
+				}
+				return nil
+			}
+
+			if _, err := s.Poll(ctx, handler); err != nil {
+				t.Fatal(err)
+			}
+			advance(time.Minute)
+			if _, err := s.Poll(ctx, handler); err != nil {
+				t.Fatal(err)
+			}
+			if len(payloads) != 2 || payloads[1] != "second" {
+				t.Errorf("payloads = %v, want [first second]", payloads)
+			}
+		})
+	}
+}
+
+// TestSchedulerRun 测试 Run 持续投递任务直到ctx结束
+func TestSchedulerRun(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s := go_cache.NewScheduler(go_cache.NewMemory(time.Minute, time.Minute), "run",
+		go_cache.WithSchedulerPollInterval(time.Millisecond), go_cache.WithSchedulerBatchSize(2))
+
+	for _, key := range []string{"a", "b", "c"} {
+		if err := s.Schedule(ctx, key, key, time.Now()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	delivered := 0
+	err := s.Run(ctx, func(ctx context.Context, task *go_cache.ScheduledTask) error {
+		if delivered++; delivered == 3 {
+			cancel()
+		}
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if delivered != 3 {
+		t.Errorf("delivered = %d, want 3", delivered)
+	}
+}
@@ -0,0 +1,152 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+type schemaUser struct {
+	ID   int
+	Name string
+}
+
+// newSchemaRegistry 创建测试用的键模式注册表
+func newSchemaRegistry(t *testing.T) *go_cache.SchemaRegistry {
+	t.Helper()
+	registry := go_cache.NewSchemaRegistry()
+	registry.MustRegister(go_cache.KeySchema{
+		Prefix: "user:", Type: reflect.TypeFor[schemaUser](),
+		MinTTL: time.Minute, MaxTTL: time.Hour, Owner: "accounts", Description: "用户资料",
+	})
+	registry.MustRegister(go_cache.KeySchema{Prefix: "user:session:", Type: reflect.TypeFor[string](), Owner: "auth"})
+	return registry
+}
+
+// TestSchemaRegistry 测试注册与最长前缀匹配
+func TestSchemaRegistry(t *testing.T) {
+	registry := newSchemaRegistry(t)
+
+	if err := registry.Register(go_cache.KeySchema{Prefix: "user:", Owner: "other"}); !errors.Is(err, go_cache.ErrDuplicateSchema) {
+		t.Errorf("重复的前缀 Register() error = %v", err)
+	}
+	if err := registry.Register(go_cache.KeySchema{}); err == nil {
+		t.Error("空前缀应返回错误")
+	}
+	if err := registry.Register(go_cache.KeySchema{Prefix: "x:", MinTTL: time.Hour, MaxTTL: time.Minute}); err == nil {
+		t.Error("MinTTL 大于 MaxTTL 应返回错误")
+	}
+
+	if s, ok := registry.Lookup("user:session:1"); !ok || s.Owner != "auth" {
+		t.Errorf("Lookup() = %+v, %v，应使用最长的前缀", s, ok)
+	}
+	if s, ok := registry.Lookup("user:1"); !ok || s.Owner != "accounts" {
+		t.Errorf("Lookup() = %+v, %v", s, ok)
+	}
+	if _, ok := registry.Lookup("order:1"); ok {
+		t.Error("没有匹配的前缀时 Lookup() 应返回false")
+	}
+
+	tests := []struct {
+		name  string
+		key   string
+		value any
+		ttl   time.Duration
+		ok    bool
+	}{
+		{"符合模式", "user:1", schemaUser{ID: 1}, 10 * time.Minute, true},
+		{"类型不符", "user:1", &schemaUser{ID: 1}, 10 * time.Minute, false},
+		{"超过最长有效期", "user:1", schemaUser{}, 2 * time.Hour, false},
+		{"没有过期时间", "user:1", schemaUser{}, 0, false},
+		{"短于最短有效期", "user:1", schemaUser{}, time.Second, false},
+		{"不限制有效期", "user:session:1", "token", 0, true},
+		{"未注册", "order:1", 1, time.Minute, false},
+	}
+	for _, tt := range tests {
+		err := registry.Validate(tt.key, tt.value, tt.ttl)
+		if (err == nil) != tt.ok || (err != nil && !errors.Is(err, go_cache.ErrSchemaViolation)) {
+			t.Errorf("%s: Validate() error = %v", tt.name, err)
+		}
+	}
+}
+
+// TestSchemaStrictMode 测试严格模式下按注册表检查写入
+func TestSchemaStrictMode(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	registry := newSchemaRegistry(t)
+	caches := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryStrictMode(), go_cache.WithMemorySchemas(registry)),
+		"redis":  go_cache.NewRedis(r.Client, go_cache.WithRedisStrictMode(), go_cache.WithRedisSchemas(registry)),
+	}
+
+	for name, cache := range caches {
+		if err := cache.Set(ctx, "user:1", schemaUser{ID: 1}, 10*time.Minute); err != nil {
+			t.Errorf("%s Set() error = %v", name, err)
+		}
+		if err := cache.Set(ctx, "user:2", "alice", 10*time.Minute); !errors.Is(err, go_cache.ErrSchemaViolation) {
+			t.Errorf("%s 类型不符 Set() error = %v", name, err)
+		}
+		if err := cache.Set(ctx, "order:1", 1, time.Minute); !errors.Is(err, go_cache.ErrSchemaViolation) {
+			t.Errorf("%s 未注册的键 Set() error = %v", name, err)
+		}
+		if cache.Exists(ctx, "user:2") || cache.Exists(ctx, "order:1") {
+			t.Errorf("%s 不符合模式的写入不应生效", name)
+		}
+		if err := cache.ExpiresIn(ctx, "user:1", 2*time.Hour); !errors.Is(err, go_cache.ErrSchemaViolation) {
+			t.Errorf("%s ExpiresIn() error = %v", name, err)
+		}
+
+		// GetSet 写入回调函数的结果时检查
+		var u schemaUser
+		err := cache.GetSet(ctx, "user:3", 2*time.Hour, &u, func(key string, obj any) error {
+			*obj.(*schemaUser) = schemaUser{ID: 3}
+			return nil
+		})
+		if !errors.Is(err, go_cache.ErrSchemaViolation) {
+			t.Errorf("%s GetSet() error = %v", name, err)
+		}
+	}
+
+	// 非严格模式下注册表不影响写入
+	loose := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemorySchemas(registry))
+	if err := loose.Set(ctx, "order:1", 1, 0); err != nil {
+		t.Errorf("非严格模式 Set() error = %v", err)
+	}
+}
+
+// TestSchemaInventory 测试生成键清单
+func TestSchemaInventory(t *testing.T) {
+	registry := newSchemaRegistry(t)
+
+	inventory := registry.Inventory()
+	if len(inventory) != 2 || inventory[0].Prefix != "user:" || inventory[1].Prefix != "user:session:" {
+		t.Fatalf("Inventory() = %+v，应按前缀排序", inventory)
+	}
+
+	var buf bytes.Buffer
+	if err := registry.WriteInventory(&buf); err != nil {
+		t.Fatalf("WriteInventory() error = %v", err)
+	}
+	var out []map[string]string
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("清单不是合法的JSON: %v\n%s", err, buf.String())
+	}
+	want := map[string]string{
+		"prefix": "user:", "type": "test.schemaUser", "min_ttl": "1m0s", "max_ttl": "1h0m0s",
+		"owner": "accounts", "description": "用户资料",
+	}
+	if !reflect.DeepEqual(out[0], want) {
+		t.Errorf("清单条目 = %v，期望 %v", out[0], want)
+	}
+	if _, ok := out[1]["max_ttl"]; ok {
+		t.Errorf("不限制的有效期应省略，清单条目 = %v", out[1])
+	}
+}
@@ -0,0 +1,79 @@
+package test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/muleiwu/go-cache/scripts"
+)
+
+const incrIfExistsSrc = `
+if redis.call("EXISTS", KEYS[1]) == 1 then
+	return redis.call("INCRBY", KEYS[1], ARGV[1])
+end
+return -1`
+
+// TestScriptNoScriptFallback 测试服务端未缓存脚本时回退为EVAL
+func TestScriptNoScriptFallback(t *testing.T) {
+	r, cleanup := newRedisTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	reg := scripts.NewRegistry()
+	s := reg.Register("incr_if_exists", incrIfExistsSrc)
+
+	if n, err := r.Cache.RunScript(ctx, s, []string{"counter"}, 1).Int64(); err != nil || n != -1 {
+		t.Fatalf("RunScript() = %d, %v, want -1", n, err)
+	}
+
+	r.Client.Set(ctx, "counter", 10, 0)
+	if n, err := reg.Run(ctx, r.Client, "incr_if_exists", []string{"counter"}, 5).Int64(); err != nil || n != 15 {
+		t.Fatalf("Run() = %d, %v, want 15", n, err)
+	}
+
+	exists, err := r.Client.ScriptExists(ctx, s.Hash()).Result()
+	if err != nil || !exists[0] {
+		t.Errorf("回退执行后脚本应被服务端缓存: %v, %v", exists, err)
+	}
+}
+
+// TestRegistryLoad 测试预加载脚本
+func TestRegistryLoad(t *testing.T) {
+	r, cleanup := newRedisTest(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	reg := scripts.NewRegistry()
+	a := reg.Register("a", `return 1`)
+	b := reg.Register("b", `return 2`)
+	if err := reg.Load(ctx, r.Client); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	exists, err := r.Client.ScriptExists(ctx, a.Hash(), b.Hash()).Result()
+	if err != nil || !exists[0] || !exists[1] {
+		t.Errorf("ScriptExists() = %v, %v", exists, err)
+	}
+	if names := reg.Names(); len(names) != 2 || names[0] != "a" {
+		t.Errorf("Names() = %v", names)
+	}
+	if err := reg.Run(ctx, r.Client, "missing", nil).Err(); err == nil {
+		t.Error("执行未注册的脚本应返回错误")
+	}
+}
+
+// TestRegistryDuplicate 测试重复注册
+func TestRegistryDuplicate(t *testing.T) {
+	reg := scripts.NewRegistry()
+	first := reg.Register("s", `return 1`)
+	if again := reg.Register("s", `return 1`); again != first {
+		t.Error("相同源码重复注册应返回已注册的脚本")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("以不同源码重复注册应panic")
+		}
+	}()
+	reg.Register("s", `return 2`)
+}
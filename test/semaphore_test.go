@@ -0,0 +1,148 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// semaphoreBackend 创建信号量使用的缓存，advance 推进判断租约过期的时间
+type semaphoreBackend func(t *testing.T) (cache gsr.Cacher, opts []go_cache.SemaphoreOption, advance func(time.Duration))
+
+// semaphoreBackends 在内存缓存与Redis上运行信号量的测试
+func semaphoreBackends() map[string]semaphoreBackend {
+	return map[string]semaphoreBackend{
+		"memory": func(t *testing.T) (gsr.Cacher, []go_cache.SemaphoreOption, func(time.Duration)) {
+			clock := go_cache.NewFakeClock(time.Time{})
+			cache := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+			return cache, []go_cache.SemaphoreOption{go_cache.WithSemaphoreClock(clock)}, clock.Advance
+		},
+		"redis": func(t *testing.T) (gsr.Cacher, []go_cache.SemaphoreOption, func(time.Duration)) {
+			r, _ := newRedisTest(t)
+			if r.Server == nil {
+				t.Skip("需要 miniredis 控制服务端时间")
+			}
+			now := time.Now()
+			r.Server.SetTime(now)
+			return r.Cache, nil, func(d time.Duration) {
+				now = now.Add(d)
+				r.Server.SetTime(now)
+				r.Server.FastForward(d)
+			}
+		},
+	}
+}
+
+// TestSemaphoreLimit 测试名额限制、释放与过期回收
+func TestSemaphoreLimit(t *testing.T) {
+	for name, backend := range semaphoreBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, opts, advance := backend(t)
+			sem := go_cache.NewSemaphore(cache, "reports", 2, 10*time.Second, opts...)
+
+			a, err := sem.TryAcquire(ctx)
+			if err != nil || a == nil {
+				t.Fatalf("TryAcquire() = %v, %v, want lease", a, err)
+			}
+			b, err := sem.TryAcquire(ctx)
+			if err != nil || b == nil {
+				t.Fatalf("TryAcquire() = %v, %v, want lease", b, err)
+			}
+			if c, err := sem.TryAcquire(ctx); err != nil || c != nil {
+				t.Fatalf("TryAcquire() when full = %v, %v, want nil", c, err)
+			}
+			if n, err := sem.Count(ctx); err != nil || n != 2 {
+				t.Errorf("Count() = %d, %v, want 2", n, err)
+			}
+
+			if err := a.Release(ctx); err != nil {
+				t.Fatalf("Release() error = %v", err)
+			}
+			if c, err := sem.TryAcquire(ctx); err != nil || c == nil {
+				t.Fatalf("TryAcquire() after release = %v, %v, want lease", c, err)
+			}
+
+			// 续期的租约保留，未续期的租约过期后名额被回收
+			advance(6 * time.Second)
+			if err := b.Refresh(ctx); err != nil {
+				t.Fatalf("Refresh() error = %v", err)
+			}
+			advance(6 * time.Second)
+			if n, err := sem.Count(ctx); err != nil || n != 1 {
+				t.Errorf("Count() after expiry = %d, %v, want 1", n, err)
+			}
+			if err := a.Refresh(ctx); !errors.Is(err, go_cache.ErrLeaseLost) {
+				t.Errorf("Refresh() on released lease error = %v, want ErrLeaseLost", err)
+			}
+		})
+	}
+}
+
+// TestSemaphoreAcquireWaits 测试 Acquire 等待名额释放与ctx取消
+func TestSemaphoreAcquireWaits(t *testing.T) {
+	ctx := context.Background()
+	sem := go_cache.NewSemaphore(go_cache.NewMemory(time.Minute, time.Minute), "jobs", 1, time.Minute,
+		go_cache.WithSemaphoreRetryInterval(time.Millisecond))
+
+	held, err := sem.Acquire(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	timeout, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := sem.Acquire(timeout); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Acquire() when full error = %v, want DeadlineExceeded", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		held.Release(ctx)
+	}()
+	if lease, err := sem.Acquire(ctx); err != nil || lease == nil {
+		t.Errorf("Acquire() after release = %v, %v, want lease", lease, err)
+	}
+}
+
+// TestSemaphoreConcurrent 测试并发执行的任务数不超过名额
+func TestSemaphoreConcurrent(t *testing.T) {
+	ctx := context.Background()
+	sem := go_cache.NewSemaphore(go_cache.NewMemory(time.Minute, time.Minute), "jobs", 3, time.Minute,
+		go_cache.WithSemaphoreRetryInterval(time.Millisecond))
+
+	var running, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lease, err := sem.Acquire(ctx)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			n := running.Add(1)
+			for {
+				p := peak.Load()
+				if n <= p || peak.CompareAndSwap(p, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			running.Add(-1)
+			lease.Release(ctx)
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 3 {
+		t.Errorf("peak concurrency = %d, want <= 3", peak.Load())
+	}
+}
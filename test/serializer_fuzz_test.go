@@ -0,0 +1,172 @@
+package test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"runtime"
+	"testing"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// corruptSerializers 返回需要处理损坏数据的所有序列化器
+func corruptSerializers(tb testing.TB) []serializer.Serializer {
+	aes, err := serializer.AESGCM(bytes.Repeat([]byte("k"), 32))
+	if err != nil {
+		tb.Fatal(err)
+	}
+	limits := serializer.Limits{MaxSize: 1 << 16, MaxDepth: 16, MaxGobMessage: 1 << 16}
+	return []serializer.Serializer{
+		serializer.NewGob(),
+		serializer.NewJson(),
+		serializer.NewPipeline(serializer.NewGob(), serializer.Gzip(gzip.BestSpeed)),
+		serializer.NewPipeline(serializer.NewJson(), aes),
+		serializer.NewPipeline(serializer.NewGob(), serializer.HMACSHA256([]byte("secret")), serializer.Annotate([]byte("v1:"))),
+		serializer.NewPipeline(serializer.WithLimits(serializer.NewGob(), limits), serializer.GzipLimit(gzip.BestSpeed, limits.MaxSize)),
+		serializer.WithLimits(serializer.NewJson(), limits),
+	}
+}
+
+// corruptSamples 返回用于生成损坏数据的值，包括nil值
+func corruptSamples() []any {
+	return []any{
+		"hello",
+		12345,
+		[]string{"a", "b"},
+		map[string]int{"x": 1},
+		fuzzRecord{Name: "n", Count: 2, Tags: []string{"t"}, Next: &fuzzRecord{Name: "next"}},
+		(*fuzzRecord)(nil),
+		[]int(nil),
+		nil,
+	}
+}
+
+// FuzzDecodeCorrupted 对合法的编码数据截断、翻转字节后解码，只能返回错误，不能panic
+func FuzzDecodeCorrupted(f *testing.F) {
+	f.Add(uint8(0), uint16(0), uint16(0), uint8(0))
+	f.Add(uint8(4), uint16(3), uint16(1), uint8(0xff))
+	f.Add(uint8(5), uint16(100), uint16(0), uint8(0x80))
+	f.Add(uint8(7), uint16(7), uint16(7), uint8(1))
+
+	serializers := corruptSerializers(f)
+	samples := corruptSamples()
+	f.Fuzz(func(t *testing.T, sample uint8, cut uint16, flip uint16, mask uint8) {
+		value := samples[int(sample)%len(samples)]
+		for _, s := range serializers {
+			data, err := s.Encode(value)
+			if err != nil {
+				t.Fatalf("%s Encode(%v) error = %v", s.Name(), value, err)
+			}
+			if len(data) == 0 {
+				continue
+			}
+			data = data[:len(data)-int(cut)%len(data)]
+			if len(data) > 0 {
+				data[int(flip)%len(data)] ^= mask
+			}
+			for _, target := range fuzzTargets() {
+				_ = s.Decode(data, target)
+			}
+		}
+	})
+}
+
+// FuzzDecodeArbitrary 任意字节输入所有序列化器都只能返回错误，不能panic
+func FuzzDecodeArbitrary(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("null"))
+	f.Add([]byte(`{"is_nil":true}`))
+	f.Add([]byte{0xfc, 0x3f, 0xff, 0xff, 0xff})
+
+	serializers := corruptSerializers(f)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, s := range serializers {
+			var record fuzzRecord
+			_ = s.Decode(data, &record)
+			var v any
+			_ = s.Decode(data, &v)
+		}
+	})
+}
+
+// TestDecodeTruncated 合法数据的每一个截断都应返回错误
+func TestDecodeTruncated(t *testing.T) {
+	for _, s := range []serializer.Serializer{serializer.NewGob(), serializer.NewJson()} {
+		for _, value := range corruptSamples() {
+			data, err := s.Encode(value)
+			if err != nil {
+				t.Fatalf("%s Encode(%v) error = %v", s.Name(), value, err)
+			}
+			for n := 0; n < len(data); n++ {
+				var out any
+				err := s.Decode(data[:n], &out)
+				if !errors.Is(err, go_cache.ErrCorruptData) {
+					t.Fatalf("%s Decode(%v 截断到 %d/%d) error = %v, want ErrCorruptData", s.Name(), value, n, len(data), err)
+				}
+			}
+		}
+	}
+}
+
+// TestGobHugeLengthPrefix 声明了巨大长度的gob数据不应分配大量内存
+func TestGobHugeLengthPrefix(t *testing.T) {
+	// 长度前缀声明约1GB的消息，实际只有几个字节
+	data := []byte{0xfc, 0x3f, 0xff, 0xff, 0xff, 0x01, 0x02}
+
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	var out string
+	err := serializer.NewGob().Decode(data, &out)
+	runtime.ReadMemStats(&after)
+
+	if !errors.Is(err, go_cache.ErrCorruptData) {
+		t.Errorf("Decode() error = %v, want ErrCorruptData", err)
+	}
+	if allocated := after.TotalAlloc - before.TotalAlloc; allocated > 1<<20 {
+		t.Errorf("Decode() 分配了 %d 字节", allocated)
+	}
+}
+
+// TestJsonWrapperCorrupt 不是由 Encode 写入的JSON应返回错误而不是静默成功
+func TestJsonWrapperCorrupt(t *testing.T) {
+	s := serializer.NewJson()
+	for _, data := range []string{`null`, `{}`, `{"is_nil":false}`, `[]`, `"text"`, `{"is_nil":"yes"}`} {
+		out := "unchanged"
+		err := s.Decode([]byte(data), &out)
+		if !errors.Is(err, go_cache.ErrCorruptData) {
+			t.Errorf("Decode(%s) error = %v, want ErrCorruptData", data, err)
+		}
+		if out != "unchanged" {
+			t.Errorf("Decode(%s) 修改了obj: %q", data, out)
+		}
+	}
+
+	// nil标记解码到不能为nil的类型
+	data, err := s.Encode(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var n int
+	if err := s.Decode(data, &n); err == nil {
+		t.Error("nil值解码到int应返回错误")
+	}
+}
+
+// TestGobNilMarkerCorrupt nil标记解码到不能为nil的类型应返回错误
+func TestGobNilMarkerCorrupt(t *testing.T) {
+	s := serializer.NewGob()
+	data, err := s.Encode((*fuzzRecord)(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var record fuzzRecord
+	if err := s.Decode(data, &record); err == nil {
+		t.Error("nil标记解码到结构体应返回错误")
+	}
+	var ptr *fuzzRecord = &fuzzRecord{}
+	if err := s.Decode(data, &ptr); err != nil || ptr != nil {
+		t.Errorf("Decode() = %v, %v", ptr, err)
+	}
+}
@@ -7,7 +7,6 @@ import (
 
 	"github.com/muleiwu/go-cache"
 	"github.com/muleiwu/go-cache/serializer"
-	"github.com/redis/go-redis/v9"
 )
 
 // TestUser 测试用户结构体
@@ -184,24 +183,11 @@ func TestJsonSerializer(t *testing.T) {
 
 // TestRedisWithJsonSerializer 测试Redis使用JSON序列化器
 func TestRedisWithJsonSerializer(t *testing.T) {
-	// 尝试连接Redis
-	rdb := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-		DB:   15, // 使用测试专用DB
-	})
-	defer rdb.Close()
+	_, rdb, cleanup := setupRedisTest(t)
+	defer cleanup()
 
 	ctx := context.Background()
 
-	// 测试连接
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		t.Skip("Redis not available, skipping test")
-		return
-	}
-
-	// 清空测试DB
-	defer rdb.FlushDB(ctx)
-
 	// 创建使用JSON序列化器的Redis缓存
 	cache := go_cache.NewRedis(rdb, go_cache.WithRedisSerializer(serializer.NewJson()))
 
@@ -269,22 +255,11 @@ func TestRedisWithJsonSerializer(t *testing.T) {
 
 // TestRedisWithGobSerializer 测试Redis使用Gob序列化器（默认）
 func TestRedisWithGobSerializer(t *testing.T) {
-	// 尝试连接Redis
-	rdb := redis.NewClient(&redis.Options{
-		Addr: "localhost:6379",
-		DB:   15,
-	})
-	defer rdb.Close()
+	_, rdb, cleanup := setupRedisTest(t)
+	defer cleanup()
 
 	ctx := context.Background()
 
-	if err := rdb.Ping(ctx).Err(); err != nil {
-		t.Skip("Redis not available, skipping test")
-		return
-	}
-
-	defer rdb.FlushDB(ctx)
-
 	// 创建使用Gob序列化器的Redis缓存（显式指定）
 	cache := go_cache.NewRedis(rdb, go_cache.WithRedisSerializer(serializer.NewGob()))
 
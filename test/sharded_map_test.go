@@ -0,0 +1,119 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// testShardedMap 测试分片映射的字段读写
+func testShardedMap(t *testing.T, cache gsr.Cacher) {
+	ctx := context.Background()
+	m := go_cache.NewShardedMap(cache, "flags:user:1", 4)
+
+	if err := m.SetField(ctx, "dark_mode", true); err != nil {
+		t.Fatalf("SetField() error = %v", err)
+	}
+	if err := m.SetField(ctx, "beta", false); err != nil {
+		t.Fatalf("SetField() error = %v", err)
+	}
+
+	var on bool
+	if err := m.GetField(ctx, "dark_mode", &on); err != nil || !on {
+		t.Errorf("GetField(dark_mode) = %v, %v, want true, nil", on, err)
+	}
+	if err := m.GetField(ctx, "missing", &on); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("GetField(missing) error = %v, want ErrKeyNotFound", err)
+	}
+
+	fields, err := m.Fields(ctx)
+	if err != nil || !slices.Equal(fields, []string{"beta", "dark_mode"}) {
+		t.Errorf("Fields() = %v, %v, want [beta dark_mode]", fields, err)
+	}
+
+	if err := m.DelField(ctx, "beta"); err != nil {
+		t.Fatalf("DelField() error = %v", err)
+	}
+	if err := m.GetField(ctx, "beta", &on); !errors.Is(err, go_cache.ErrKeyNotFound) {
+		t.Errorf("GetField(beta) after DelField error = %v, want ErrKeyNotFound", err)
+	}
+	if cache.Exists(ctx, m.FieldKey("beta")) && m.FieldKey("beta") != m.FieldKey("dark_mode") {
+		t.Error("empty shard should be deleted")
+	}
+
+	if err := m.Clear(ctx); err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if fields, _ := m.Fields(ctx); len(fields) != 0 {
+		t.Errorf("Fields() after Clear = %v, want none", fields)
+	}
+}
+
+// TestShardedMapMemory 测试基于内存缓存的分片映射
+func TestShardedMapMemory(t *testing.T) {
+	testShardedMap(t, go_cache.NewMemory(time.Minute, time.Minute))
+}
+
+// TestShardedMapRedis 测试基于Redis缓存的分片映射
+func TestShardedMapRedis(t *testing.T) {
+	r, _ := newRedisTest(t)
+	testShardedMap(t, r.Cache)
+}
+
+// TestShardedMapDistribution 测试字段分布到多个分片
+func TestShardedMapDistribution(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	m := go_cache.NewShardedMap(cache, "big", 8)
+
+	for i := 0; i < 100; i++ {
+		if err := m.SetField(ctx, fmt.Sprintf("field-%d", i), i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	used := 0
+	for i := 0; i < 8; i++ {
+		if cache.Exists(ctx, m.ShardKey(i)) {
+			used++
+		}
+	}
+	if used < 2 {
+		t.Errorf("fields stored in %d shards, want spread across shards", used)
+	}
+
+	var v int
+	if err := m.GetField(ctx, "field-42", &v); err != nil || v != 42 {
+		t.Errorf("GetField(field-42) = %d, %v, want 42, nil", v, err)
+	}
+}
+
+// TestShardedMapConcurrent 测试并发写入同一个分片不会丢失更新
+func TestShardedMapConcurrent(t *testing.T) {
+	ctx := context.Background()
+	m := go_cache.NewShardedMap(go_cache.NewMemory(time.Minute, time.Minute), "concurrent", 1)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := m.SetField(ctx, fmt.Sprintf("f%d", i), i); err != nil {
+				t.Error(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	fields, err := m.Fields(ctx)
+	if err != nil || len(fields) != 20 {
+		t.Errorf("Fields() = %d fields, %v, want 20", len(fields), err)
+	}
+}
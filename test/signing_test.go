@@ -0,0 +1,113 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// TestRedisSigning 测试签名的值可以读取，未签名或使用其他密钥签名的值被拒绝
+func TestRedisSigning(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	signed := go_cache.NewRedis(r.Client,
+		go_cache.WithRedisSigning([]byte("team-a")),
+		go_cache.WithRedisSerializer(serializer.NewJson()))
+	other := go_cache.NewRedis(r.Client,
+		go_cache.WithRedisSigning([]byte("team-b")),
+		go_cache.WithRedisSerializer(serializer.NewJson()))
+
+	user := TestSerializerUser{ID: 1, Name: "张三"}
+	if err := signed.Set(ctx, "user", user, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	var got TestSerializerUser
+	if err := signed.Get(ctx, "user", &got); err != nil || got != user {
+		t.Fatalf("Get() = %+v, %v", got, err)
+	}
+
+	if err := other.Get(ctx, "user", &got); !errors.Is(err, go_cache.ErrInvalidSignature) {
+		t.Errorf("其他密钥 Get() error = %v, want ErrInvalidSignature", err)
+	}
+
+	// 没有密钥的写入方直接写入的值
+	r.Client.Set(ctx, "forged", `{"is_nil":false,"value":{"id":666}}`, time.Minute)
+	if err := signed.Get(ctx, "forged", &got); !errors.Is(err, go_cache.ErrInvalidSignature) {
+		t.Errorf("伪造值 Get() error = %v, want ErrInvalidSignature", err)
+	}
+	if stats := signed.Stats(); stats.Errors != 1 {
+		t.Errorf("Errors = %d, want 1", stats.Errors)
+	}
+}
+
+// signedFields 按字段保存的签名测试结构体
+type signedFields struct {
+	Role  string
+	Owner string
+}
+
+// TestRedisSigningKeyBinding 测试签名绑定键名：复制到其他键或字段的值被拒绝，Rename 与 Copy 按新键重新签名
+func TestRedisSigningKeyBinding(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	plain := go_cache.NewRedis(r.Client, go_cache.WithRedisSigning([]byte("team-a")))
+	offload := go_cache.NewRedis(r.Client, go_cache.WithRedisSigning([]byte("team-a")),
+		go_cache.WithRedisBlobOffload(go_cache.NewMemoryBlobStore(), 16))
+
+	for name, signed := range map[string]*go_cache.Redis{"plain": plain, "offload": offload} {
+		t.Run(name, func(t *testing.T) {
+			alice := strings.Repeat("alice", 10)
+			if err := signed.Set(ctx, name+":session:alice", alice, time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+			_ = signed.Set(ctx, name+":session:bob", "bob", time.Minute)
+
+			// 有Redis写权限的一方把alice的值复制到bob的键
+			raw := r.Client.Get(ctx, name+":session:alice").Val()
+			r.Client.Set(ctx, name+":session:bob", raw, time.Minute)
+			var got string
+			if err := signed.Get(ctx, name+":session:bob", &got); !errors.Is(err, go_cache.ErrInvalidSignature) {
+				t.Errorf("复制到其他键的值 Get() = %q, %v, want ErrInvalidSignature", got, err)
+			}
+
+			// Rename 与 Copy 按新键重新签名
+			if err := signed.Copy(ctx, name+":session:alice", name+":session:copy", time.Minute); err != nil {
+				t.Fatalf("Copy() error = %v", err)
+			}
+			if err := signed.Rename(ctx, name+":session:alice", name+":session:renamed"); err != nil {
+				t.Fatalf("Rename() error = %v", err)
+			}
+			for _, key := range []string{name + ":session:copy", name + ":session:renamed"} {
+				if err := signed.Get(ctx, key, &got); err != nil || got != alice {
+					t.Errorf("Get(%s) = %q, %v", key, got, err)
+				}
+			}
+
+			// 哈希字段的签名同时绑定键名与字段名
+			if err := signed.SetFields(ctx, name+":acl", signedFields{Role: "admin", Owner: "alice"}, time.Minute); err != nil {
+				t.Fatalf("SetFields() error = %v", err)
+			}
+			role := r.Client.HGet(ctx, name+":acl", "Role").Val()
+			r.Client.HSet(ctx, name+":acl", "Owner", role)
+			var fields signedFields
+			if err := signed.GetFields(ctx, name+":acl", &fields); !errors.Is(err, go_cache.ErrInvalidSignature) {
+				t.Errorf("复制到其他字段的值 GetFields() = %+v, %v, want ErrInvalidSignature", fields, err)
+			}
+
+			_ = signed.SetFields(ctx, name+":acl", signedFields{Role: "admin", Owner: "alice"}, time.Minute)
+			if err := signed.Rename(ctx, name+":acl", name+":acl2"); err != nil {
+				t.Fatalf("哈希 Rename() error = %v", err)
+			}
+			if err := signed.GetFields(ctx, name+":acl2", &fields); err != nil || fields.Owner != "alice" {
+				t.Errorf("重命名后 GetFields() = %+v, %v", fields, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// slowOpRecorder 记录慢操作
+type slowOpRecorder struct {
+	mu  sync.Mutex
+	ops []go_cache.SlowOp
+}
+
+func (r *slowOpRecorder) hook(ctx context.Context, op go_cache.SlowOp) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ops = append(r.ops, op)
+}
+
+func (r *slowOpRecorder) all() []go_cache.SlowOp {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]go_cache.SlowOp(nil), r.ops...)
+}
+
+// TestSlowTracerCapturesStack 测试慢操作记录调用栈
+func TestSlowTracerCapturesStack(t *testing.T) {
+	ctx := context.Background()
+	rec := &slowOpRecorder{}
+	tracer := go_cache.NewSlowTracer(go_cache.NewMemory(time.Minute, time.Minute), 20*time.Millisecond, rec.hook)
+
+	// 快速操作不记录
+	if err := tracer.Set(ctx, "fast", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	var got string
+	if err := tracer.Get(ctx, "fast", &got); err != nil || got != "v" {
+		t.Fatalf("Get() = %q, %v", got, err)
+	}
+	if ops := rec.all(); len(ops) != 0 {
+		t.Fatalf("快速操作不应被记录: %+v", ops)
+	}
+
+	// 回调函数较慢的 GetSet 被记录
+	err := tracer.GetSet(ctx, "slow", time.Minute, &got, func(key string, obj any) error {
+		time.Sleep(30 * time.Millisecond)
+		*obj.(*string) = "loaded"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetSet() error = %v", err)
+	}
+
+	ops := rec.all()
+	if len(ops) != 1 {
+		t.Fatalf("记录数 = %d, want 1", len(ops))
+	}
+	op := ops[0]
+	if op.Op != go_cache.OpGetSet || op.Key != "slow" || op.Duration < 20*time.Millisecond {
+		t.Errorf("记录 = %+v", op)
+	}
+	if !strings.Contains(string(op.Stack), "TestSlowTracerCapturesStack") {
+		t.Errorf("调用栈中应包含调用方:\n%s", op.Stack)
+	}
+}
+
+// TestSlowTracerOversized 测试超大写入被记录
+func TestSlowTracerOversized(t *testing.T) {
+	ctx := context.Background()
+	rec := &slowOpRecorder{}
+	tracer := go_cache.NewSlowTracer(go_cache.NewMemory(time.Minute, time.Minute), time.Hour, rec.hook,
+		go_cache.WithSlowTraceMaxSize(1024))
+
+	if err := tracer.Set(ctx, "small", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := tracer.Set(ctx, "big", strings.Repeat("x", 4096), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	ops := rec.all()
+	if len(ops) != 1 {
+		t.Fatalf("记录数 = %d, want 1", len(ops))
+	}
+	if ops[0].Op != go_cache.OpSet || ops[0].Key != "big" || ops[0].Size < 4096 {
+		t.Errorf("记录 = %+v", ops[0])
+	}
+	if len(ops[0].Stack) == 0 {
+		t.Error("超大写入应记录调用栈")
+	}
+}
+
+// TestSlowOpLogger 测试使用slog记录慢操作
+func TestSlowOpLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	tracer := go_cache.NewSlowTracer(go_cache.NewMemory(time.Minute, time.Minute), time.Hour,
+		go_cache.SlowOpLogger(logger), go_cache.WithSlowTraceMaxSize(16))
+
+	if err := tracer.Set(context.Background(), "big", strings.Repeat("x", 64), time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	out := buf.String()
+	for _, want := range []string{"slow cache operation", "op=set", "key=big", "stack="} {
+		if !strings.Contains(out, want) {
+			t.Errorf("日志中缺少 %q:\n%s", want, out)
+		}
+	}
+}
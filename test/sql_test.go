@@ -0,0 +1,90 @@
+package test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/gocachetest"
+	"github.com/muleiwu/gsr"
+	_ "modernc.org/sqlite"
+)
+
+// newSQLTest 创建基于临时SQLite文件的SQL缓存
+func newSQLTest(t *testing.T, opts ...go_cache.SQLOption) *go_cache.SQL {
+	t.Helper()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "cache.db"))
+	if err != nil {
+		t.Fatalf("sql.Open() error = %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	opts = append([]go_cache.SQLOption{go_cache.WithSQLDialect(go_cache.DialectSQLite)}, opts...)
+	cache := go_cache.NewSQL(db, opts...)
+	t.Cleanup(func() { cache.Close() })
+
+	if err := cache.CreateTable(context.Background()); err != nil {
+		t.Fatalf("CreateTable() error = %v", err)
+	}
+	return cache
+}
+
+// TestSQLConformance 对SQL缓存运行一致性测试
+func TestSQLConformance(t *testing.T) {
+	gocachetest.RunConformance(t, func(t *testing.T) (gsr.Cacher, func(time.Duration)) {
+		clock := go_cache.NewFakeClock(time.Time{})
+		return newSQLTest(t, go_cache.WithSQLClock(clock), go_cache.WithSQLCleanupInterval(0)), clock.Advance
+	})
+}
+
+// TestSQLDeleteExpired 测试清理过期条目
+func TestSQLDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := newSQLTest(t,
+		go_cache.WithSQLClock(clock),
+		go_cache.WithSQLTable("custom_cache"),
+		go_cache.WithSQLCleanupInterval(0))
+
+	if err := cache.Set(ctx, "short", "v", time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cache.Set(ctx, "long", "v", time.Hour); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := cache.Set(ctx, "forever", "v", 0); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	clock.Advance(time.Minute)
+	n, err := cache.DeleteExpired(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("DeleteExpired() = %d, %v, want 1", n, err)
+	}
+	if !cache.Exists(ctx, "long") || !cache.Exists(ctx, "forever") {
+		t.Error("未过期的条目不应被清理")
+	}
+}
+
+// TestSQLBackgroundCleanup 测试后台定期清理
+func TestSQLBackgroundCleanup(t *testing.T) {
+	ctx := context.Background()
+	cache := newSQLTest(t, go_cache.WithSQLCleanupInterval(10*time.Millisecond))
+
+	if err := cache.Set(ctx, "k", "v", time.Millisecond); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+		if n, _ := cache.DeleteExpired(ctx); n == 0 {
+			return
+		}
+	}
+	t.Error("后台清理未删除过期条目")
+}
@@ -0,0 +1,161 @@
+package test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestStatsCollectorNamespace 测试命名空间划分
+func TestStatsCollectorNamespace(t *testing.T) {
+	tests := []struct {
+		name       string
+		delimiters []string
+		key        string
+		want       string
+	}{
+		{name: "默认分隔符", key: "user:123", want: "user"},
+		{name: "多级键取第一段", key: "order:2024:1", want: "order"},
+		{name: "无分隔符", key: "plain", want: ""},
+		{name: "自定义分隔符", delimiters: []string{"/"}, key: "feed/42", want: "feed"},
+		{name: "多个分隔符取最先出现的", delimiters: []string{":", "."}, key: "a.b:c", want: "a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opts []go_cache.StatsOption
+			if tt.delimiters != nil {
+				opts = append(opts, go_cache.WithNamespaceDelimiters(tt.delimiters...))
+			}
+			s := go_cache.NewStatsCollector(opts...)
+			if got := s.Namespace(tt.key); got != tt.want {
+				t.Errorf("Namespace(%q) = %q, want %q", tt.key, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStatsCollectorMaxNamespaces 测试命名空间数量上限
+func TestStatsCollectorMaxNamespaces(t *testing.T) {
+	s := go_cache.NewStatsCollector(go_cache.WithMaxNamespaces(2))
+
+	s.RecordMiss("a:1")
+	s.RecordMiss("b:1")
+	s.RecordMiss("c:1")
+	s.RecordMiss("d:1")
+
+	stats := s.Snapshot()
+	if stats.Misses != 4 {
+		t.Errorf("全局未命中次数应为4，实际为%d", stats.Misses)
+	}
+	if got := stats.Namespaces[go_cache.OtherNamespace].Misses; got != 2 {
+		t.Errorf("超出上限的命名空间应归入 %s，实际计数为%d", go_cache.OtherNamespace, got)
+	}
+}
+
+// TestMemoryStatsByNamespace 测试内存缓存按命名空间统计
+func TestMemoryStatsByNamespace(t *testing.T) {
+	cache := go_cache.NewMemory(5*time.Minute, 10*time.Minute)
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "user:1", "alice", time.Minute)
+	_ = cache.Set(ctx, "feed:1", "hello", time.Minute)
+
+	var s string
+	_ = cache.Get(ctx, "user:1", &s)
+	_ = cache.Get(ctx, "user:2", &s)
+	_ = cache.Get(ctx, "feed:2", &s)
+	_ = cache.Del(ctx, "feed:1")
+
+	stats := cache.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Sets != 2 || stats.Deletes != 1 {
+		t.Errorf("全局统计不正确: %+v", stats.StatsCounters)
+	}
+
+	user := stats.Namespaces["user"]
+	if user.Hits != 1 || user.Misses != 1 {
+		t.Errorf("user 命名空间统计不正确: %+v", user)
+	}
+	if user.HitRate() != 0.5 {
+		t.Errorf("user 命名空间命中率应为0.5，实际为%v", user.HitRate())
+	}
+
+	feed := stats.Namespaces["feed"]
+	if feed.Hits != 0 || feed.Misses != 1 || feed.Deletes != 1 {
+		t.Errorf("feed 命名空间统计不正确: %+v", feed)
+	}
+}
+
+// TestMemorySharedStatsCollector 测试多个实例共享统计收集器
+func TestMemorySharedStatsCollector(t *testing.T) {
+	collector := go_cache.NewStatsCollector()
+	c1 := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryStats(collector))
+	c2 := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryStats(collector))
+	ctx := context.Background()
+
+	_ = c1.Set(ctx, "k:1", 1, time.Minute)
+	_ = c2.Set(ctx, "k:2", 2, time.Minute)
+
+	if got := collector.Snapshot().Namespaces["k"].Sets; got != 2 {
+		t.Errorf("共享收集器应记录2次写入，实际为%d", got)
+	}
+
+	collector.Reset()
+	if got := collector.Snapshot().Sets; got != 0 {
+		t.Errorf("Reset 后写入次数应为0，实际为%d", got)
+	}
+}
+
+// TestRedisStatsBytes 测试Redis缓存统计读写字节数
+func TestRedisStatsBytes(t *testing.T) {
+	cache, _, cleanup := setupRedisTest(t)
+	defer cleanup()
+
+	ctx := context.Background()
+	_ = cache.Set(ctx, "blob:1", "some payload", time.Minute)
+
+	var s string
+	_ = cache.Get(ctx, "blob:1", &s)
+	_ = cache.Get(ctx, "blob:2", &s)
+
+	blob := cache.Stats().Namespaces["blob"]
+	if blob.Hits != 1 || blob.Misses != 1 {
+		t.Errorf("blob 命名空间统计不正确: %+v", blob)
+	}
+	if blob.BytesWritten == 0 || blob.BytesRead != blob.BytesWritten {
+		t.Errorf("读写字节数应相等且大于0: %+v", blob)
+	}
+}
+
+// TestStatsCollectorConcurrentReset 测试并发记录与Reset时全局计数与命名空间计数保持一致
+func TestStatsCollectorConcurrentReset(t *testing.T) {
+	s := go_cache.NewStatsCollector()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 2000; j++ {
+				s.RecordMiss(fmt.Sprintf("ns%d:%d", j%5, j))
+			}
+		}()
+	}
+	for i := 0; i < 20; i++ {
+		s.Reset()
+	}
+	wg.Wait()
+
+	stats := s.Snapshot()
+	var sum uint64
+	for _, c := range stats.Namespaces {
+		sum += c.Misses
+	}
+	if sum != stats.Misses {
+		t.Errorf("命名空间计数之和(%d)应等于全局计数(%d)", sum, stats.Misses)
+	}
+}
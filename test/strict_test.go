@@ -0,0 +1,138 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// TestStrictModeTTL 测试严格模式下 ttl <= 0 返回 ErrInvalidTTL
+func TestStrictModeTTL(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	caches := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryStrictMode()),
+		"redis":  go_cache.NewRedis(r.Client, go_cache.WithRedisStrictMode()),
+	}
+
+	for name, cache := range caches {
+		for _, ttl := range []time.Duration{0, -time.Second} {
+			if err := cache.Set(ctx, "k", "v", ttl); !errors.Is(err, go_cache.ErrInvalidTTL) {
+				t.Errorf("%s Set(ttl=%v) error = %v, want ErrInvalidTTL", name, ttl, err)
+			}
+		}
+		if cache.Exists(ctx, "k") {
+			t.Errorf("%s 非法TTL的写入不应生效", name)
+		}
+
+		// GetSet 在调用回调函数之前失败
+		called := false
+		var s string
+		err := cache.GetSet(ctx, "k", 0, &s, func(key string, obj any) error {
+			called = true
+			return nil
+		})
+		if !errors.Is(err, go_cache.ErrInvalidTTL) || called {
+			t.Errorf("%s GetSet(ttl=0) error = %v, called = %v", name, err, called)
+		}
+
+		if err := cache.Set(ctx, "k", "v", time.Minute); err != nil {
+			t.Fatalf("%s Set() error = %v", name, err)
+		}
+		if err := cache.ExpiresIn(ctx, "k", -time.Second); !errors.Is(err, go_cache.ErrInvalidTTL) {
+			t.Errorf("%s ExpiresIn(-1s) error = %v, want ErrInvalidTTL", name, err)
+		}
+		if !cache.Exists(ctx, "k") {
+			t.Errorf("%s 非法TTL的 ExpiresIn 不应删除键", name)
+		}
+	}
+}
+
+// TestLenientModeTTL 测试默认模式下 ttl <= 0 视为永不过期
+func TestLenientModeTTL(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	if err := cache.Set(ctx, "k", "v", -time.Second); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if !cache.Exists(ctx, "k") {
+		t.Error("默认模式下负数TTL应视为永不过期")
+	}
+}
+
+// TestStrictModeDecode 测试严格模式下类型不一致返回 ErrTypeMismatch
+func TestStrictModeDecode(t *testing.T) {
+	type user struct {
+		Name  string
+		Email string
+	}
+	type userName struct {
+		Name string
+	}
+
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	lenient := go_cache.NewRedis(r.Client)
+	strict := go_cache.NewRedis(r.Client, go_cache.WithRedisStrictMode())
+	lenientJSON := go_cache.NewRedis(r.Client, go_cache.WithRedisSerializer(serializer.NewJson()))
+	strictJSON := go_cache.NewRedis(r.Client, go_cache.WithRedisSerializer(serializer.NewJson()), go_cache.WithRedisStrictMode())
+
+	// gob：写入 T 读取到 *T
+	if err := lenient.Set(ctx, "user", user{Name: "n"}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	var ptr *user
+	if err := lenient.Get(ctx, "user", &ptr); err != nil || ptr == nil || ptr.Name != "n" {
+		t.Errorf("默认模式 Get(*T) = %v, %v", ptr, err)
+	}
+	ptr = nil
+	if err := strict.Get(ctx, "user", &ptr); !errors.Is(err, go_cache.ErrTypeMismatch) || ptr != nil {
+		t.Errorf("严格模式 Get(*T) = %v, %v, want ErrTypeMismatch", ptr, err)
+	}
+	var u user
+	if err := strict.Get(ctx, "user", &u); err != nil || u.Name != "n" {
+		t.Errorf("严格模式 Get(T) = %v, %v", u, err)
+	}
+
+	// json：读取到缺少字段的类型
+	if err := lenientJSON.Set(ctx, "user.json", user{Name: "n", Email: "e"}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	var name userName
+	if err := lenientJSON.Get(ctx, "user.json", &name); err != nil || name.Name != "n" {
+		t.Errorf("默认模式 Get() = %v, %v", name, err)
+	}
+	name = userName{Name: "unchanged"}
+	if err := strictJSON.Get(ctx, "user.json", &name); !errors.Is(err, go_cache.ErrTypeMismatch) || name.Name != "unchanged" {
+		t.Errorf("严格模式 Get() = %v, %v, want ErrTypeMismatch", name, err)
+	}
+
+	// 签名后的数据同样严格解码
+	signed := go_cache.NewRedis(r.Client, go_cache.WithRedisSigning([]byte("key")), go_cache.WithRedisStrictMode())
+	if err := signed.Set(ctx, "signed", user{Name: "n"}, time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	ptr = nil
+	if err := signed.Get(ctx, "signed", &ptr); !errors.Is(err, go_cache.ErrTypeMismatch) {
+		t.Errorf("签名 Get(*T) error = %v, want ErrTypeMismatch", err)
+	}
+}
+
+// TestStrictModeDriver 测试通过dsn开启严格模式
+func TestStrictModeDriver(t *testing.T) {
+	cache, err := go_cache.Open("memory", "memory://?strict=true")
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	if err := cache.Set(context.Background(), "k", "v", 0); !errors.Is(err, go_cache.ErrInvalidTTL) {
+		t.Errorf("Set(ttl=0) error = %v, want ErrInvalidTTL", err)
+	}
+	if _, err := go_cache.Open("memory", "memory://?strict=maybe"); err == nil {
+		t.Error("非法的strict参数应返回错误")
+	}
+}
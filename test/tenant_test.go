@@ -0,0 +1,140 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestMatchPattern 测试Redis风格的模式匹配
+func TestMatchPattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		s       string
+		want    bool
+	}{
+		{"*", "anything", true},
+		{"user:*", "user:1", true},
+		{"user:*", "order:1", false},
+		{"h?llo", "hello", true},
+		{"h?llo", "hllo", false},
+		{"h[ae]llo", "hallo", true},
+		{"h[ae]llo", "hillo", false},
+		{"h[^e]llo", "hallo", true},
+		{"h[^e]llo", "hello", false},
+		{"h[a-c]llo", "hbllo", true},
+		{`a\*b`, "a*b", true},
+		{`a\*b`, "axb", false},
+		{"*:*:end", "a:b:end", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		if got := go_cache.MatchPattern(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("MatchPattern(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+
+	if !go_cache.MatchPattern(go_cache.EscapePattern("a*[b]?")+"*", "a*[b]?x") {
+		t.Error("转义后的模式应按字面匹配")
+	}
+}
+
+// TestMemoryKeysAndDelByPattern 测试内存缓存按模式列出与删除
+func TestMemoryKeysAndDelByPattern(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	ctx := context.Background()
+
+	for _, key := range []string{"user:1", "user:2", "order:1"} {
+		_ = cache.Set(ctx, key, key, time.Minute)
+	}
+
+	keys, _ := cache.Keys(ctx, "user:*")
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "user:1" || keys[1] != "user:2" {
+		t.Errorf("Keys() = %v", keys)
+	}
+
+	n, err := cache.DelByPattern(ctx, "user:*")
+	if err != nil || n != 2 {
+		t.Errorf("DelByPattern() = %d, %v", n, err)
+	}
+	if !cache.Exists(ctx, "order:1") {
+		t.Error("不匹配的键不应被删除")
+	}
+}
+
+// TestTenantIsolation 测试租户隔离
+func TestTenantIsolation(t *testing.T) {
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+	ctx := context.Background()
+
+	a, err := go_cache.ForTenant(backend, "a")
+	if err != nil {
+		t.Fatalf("ForTenant() error = %v", err)
+	}
+	ab, _ := go_cache.ForTenant(backend, "a*")
+	b, _ := go_cache.ForTenant(backend, "b")
+
+	_ = a.Set(ctx, "profile", "a-profile", time.Minute)
+	_ = a.Set(ctx, "settings", "a-settings", time.Minute)
+	_ = ab.Set(ctx, "profile", "ab-profile", time.Minute)
+	_ = b.Set(ctx, "profile", "b-profile", time.Minute)
+
+	var s string
+	if err := b.Get(ctx, "profile", &s); err != nil || s != "b-profile" {
+		t.Errorf("租户b读取到错误的值: %q, %v", s, err)
+	}
+	if b.Exists(ctx, "settings") {
+		t.Error("租户b不应看到租户a的键")
+	}
+
+	keys, _ := a.Keys(ctx, "*")
+	sort.Strings(keys)
+	if len(keys) != 2 || keys[0] != "profile" || keys[1] != "settings" {
+		t.Errorf("租户a的Keys() = %v", keys)
+	}
+
+	// Clear 只删除本租户的键，模式字符在租户ID中按字面处理
+	n, err := a.Clear(ctx)
+	if err != nil || n != 2 {
+		t.Errorf("Clear() = %d, %v", n, err)
+	}
+	if !ab.Exists(ctx, "profile") || !b.Exists(ctx, "profile") {
+		t.Error("Clear 不应越过租户边界")
+	}
+
+	// GetSet 回调收到业务键
+	err = b.GetSet(ctx, "loaded", time.Minute, &s, func(key string, obj any) error {
+		if key != "loaded" {
+			t.Errorf("回调收到的键应为业务键，实际为 %q", key)
+		}
+		*obj.(*string) = "v"
+		return nil
+	})
+	if err != nil || !backend.Exists(ctx, b.Key("loaded")) {
+		t.Errorf("GetSet() 应写入租户命名空间: %v", err)
+	}
+}
+
+// TestForTenantInvalidID 测试不合法的租户ID
+func TestForTenantInvalidID(t *testing.T) {
+	backend := go_cache.NewMemory(time.Minute, time.Minute)
+	for _, id := range []string{"", "a:b"} {
+		if _, err := go_cache.ForTenant(backend, id); !errors.Is(err, go_cache.ErrInvalidTenant) {
+			t.Errorf("ForTenant(%q) 应返回 ErrInvalidTenant，实际为 %v", id, err)
+		}
+	}
+}
+
+// TestTenantClearNotSupported 测试底层缓存不支持模式操作
+func TestTenantClearNotSupported(t *testing.T) {
+	tenant, _ := go_cache.ForTenant(go_cache.NewNone(), "a")
+	if _, err := tenant.Clear(context.Background()); !errors.Is(err, go_cache.ErrNotSupported) {
+		t.Errorf("应返回 ErrNotSupported，实际为 %v", err)
+	}
+}
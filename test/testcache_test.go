@@ -0,0 +1,100 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/go-cache/testcache"
+)
+
+// TestTestCacheRecordsCalls 测试记录操作与按键统计调用次数
+func TestTestCacheRecordsCalls(t *testing.T) {
+	cache := testcache.New()
+	ctx := context.Background()
+
+	loads := 0
+	loader := func(key string, obj any) error {
+		loads++
+		*obj.(*string) = "loaded"
+		return nil
+	}
+
+	var s string
+	for i := 0; i < 3; i++ {
+		if err := cache.GetSet(ctx, "user:1", time.Minute, &s, loader); err != nil {
+			t.Fatalf("GetSet() error = %v", err)
+		}
+	}
+
+	if loads != 1 {
+		t.Errorf("回调应只执行1次，实际为%d", loads)
+	}
+	if got := cache.CallCount(go_cache.OpGetSet, "user:1"); got != 3 {
+		t.Errorf("GetSet 调用次数应为3，实际为%d", got)
+	}
+	calls := cache.Calls()
+	if calls[0].Hit || !calls[1].Hit {
+		t.Errorf("命中记录不正确: %+v", calls)
+	}
+}
+
+// TestTestCacheAdvance 测试推进时钟后条目立即过期
+func TestTestCacheAdvance(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     time.Duration
+		advance time.Duration
+		exists  bool
+	}{
+		{name: "未到期", ttl: time.Minute, advance: 59 * time.Second, exists: true},
+		{name: "刚好到期", ttl: time.Minute, advance: time.Minute, exists: false},
+		{name: "不过期", ttl: 0, advance: 24 * time.Hour, exists: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := testcache.New()
+			_ = cache.Seed("k", "v", tt.ttl)
+			cache.Advance(tt.advance)
+
+			if got := cache.Exists(context.Background(), "k"); got != tt.exists {
+				t.Errorf("Exists() = %v, want %v", got, tt.exists)
+			}
+			if got := len(cache.Snapshot()); got != map[bool]int{true: 1, false: 0}[tt.exists] {
+				t.Errorf("Snapshot 条目数不正确: %d", got)
+			}
+		})
+	}
+}
+
+// TestTestCacheSnapshotAndErrors 测试导出内容、序列化往返与错误注入
+func TestTestCacheSnapshotAndErrors(t *testing.T) {
+	cache := testcache.New(testcache.WithSerializer(serializer.NewJson()))
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "b", 2, time.Minute)
+	_ = cache.Set(ctx, "a", 1, 0)
+
+	if keys := cache.SortedKeys(); len(keys) != 2 || keys[0] != "a" {
+		t.Errorf("SortedKeys() = %v", keys)
+	}
+	if data, ok := cache.Snapshot()["b"].Value.([]byte); !ok || len(data) == 0 {
+		t.Errorf("配置序列化器后应保存序列化结果: %+v", cache.Snapshot()["b"])
+	}
+
+	boom := errors.New("boom")
+	cache.InjectError(go_cache.OpGet, "", boom)
+	var n int
+	if err := cache.Get(ctx, "a", &n); !errors.Is(err, boom) {
+		t.Errorf("应返回注入的错误，实际为 %v", err)
+	}
+
+	cache.InjectError(go_cache.OpGet, "", nil)
+	if err := cache.Get(ctx, "a", &n); err != nil || n != 1 {
+		t.Errorf("取消注入后 Get() = %d, %v", n, err)
+	}
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/go-cache/testutil"
+)
+
+// TestTestutilMiniredis 测试使用miniredis创建独立实例与推进过期时间
+func TestTestutilMiniredis(t *testing.T) {
+	r, cleanup := testutil.NewRedis(t, testutil.WithBackend(testutil.BackendMiniredis))
+	defer cleanup()
+
+	if r.Backend != testutil.BackendMiniredis || r.Server == nil {
+		t.Fatalf("应使用miniredis，实际为%q", r.Backend)
+	}
+
+	ctx := context.Background()
+	if err := r.Cache.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	r.FastForward(time.Minute)
+	if r.Cache.Exists(ctx, "k") {
+		t.Error("推进时间后键应过期")
+	}
+
+	// 每次调用都是独立实例
+	other, _ := testutil.NewRedis(t, testutil.WithBackend(testutil.BackendMiniredis),
+		testutil.WithCacheOptions(go_cache.WithRedisSerializer(serializer.NewJson())))
+	_ = r.Cache.Set(ctx, "shared", 1, 0)
+	if other.Cache.Exists(ctx, "shared") {
+		t.Error("miniredis 实例之间不应共享数据")
+	}
+}
+
+// TestRedisMiniredisFeatureSet 测试兼容模式下Redis缓存的完整功能在miniredis上可用
+func TestRedisMiniredisFeatureSet(t *testing.T) {
+	r, cleanup := testutil.NewRedis(t, testutil.WithBackend(testutil.BackendMiniredis))
+	defer cleanup()
+
+	cache := r.Cache
+	ctx := context.Background()
+	if cache.Compat() != go_cache.CompatMiniredis {
+		t.Fatalf("miniredis 应默认开启兼容模式，实际为%s", cache.Compat())
+	}
+
+	for _, key := range []string{"user:1", "user:2", "feed:1"} {
+		if err := cache.Set(ctx, key, key, time.Minute); err != nil {
+			t.Fatalf("Set(%s) error = %v", key, err)
+		}
+	}
+
+	if keys, err := cache.Keys(ctx, "user:*"); err != nil || len(keys) != 2 {
+		t.Errorf("Keys() = %v, %v", keys, err)
+	}
+	if _, err := cache.MemoryUsage(ctx, "user:1"); err != nil {
+		t.Errorf("MemoryUsage() error = %v", err)
+	}
+	if top, err := cache.SampleLargestKeys(ctx, 2, ""); err != nil || len(top) != 2 {
+		t.Errorf("SampleLargestKeys() = %v, %v", top, err)
+	}
+	if forecast, err := go_cache.ForecastExpiry(ctx, cache, 10); err != nil || forecast.Buckets[1].Count != 3 {
+		t.Errorf("ForecastExpiry() = %+v, %v", forecast, err)
+	}
+	if stats, err := cache.StatsContext(ctx); err != nil || stats.Sets != 3 {
+		t.Errorf("StatsContext() = %+v, %v", stats.StatsCounters, err)
+	}
+	if n, err := cache.DelByPattern(ctx, "user:*"); err != nil || n != 2 {
+		t.Errorf("DelByPattern() = %d, %v", n, err)
+	}
+
+	r.FastForward(time.Minute)
+	if cache.Exists(ctx, "feed:1") {
+		t.Error("推进时间后键应过期")
+	}
+}
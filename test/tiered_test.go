@@ -0,0 +1,299 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// laggyReplica 模拟复制延迟的L2：写入尚未同步到副本，读取仍返回旧值
+type laggyReplica struct {
+	*go_cache.Memory
+}
+
+func (r *laggyReplica) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return nil
+}
+
+func newLaggyTiered(opts ...go_cache.TieredOption) (*go_cache.Tiered, *go_cache.Memory) {
+	l1 := go_cache.NewMemory(time.Minute, time.Minute)
+	l2 := &laggyReplica{Memory: go_cache.NewMemory(time.Minute, time.Minute)}
+	_ = l2.Memory.Set(context.Background(), "k", "old", time.Minute)
+	return go_cache.NewTiered(l1, l2, opts...), l1
+}
+
+// TestTieredReadThrough 测试L1未命中时从L2读取并回填L1
+func TestTieredReadThrough(t *testing.T) {
+	l1 := go_cache.NewMemory(time.Minute, time.Minute)
+	l2 := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewTiered(l1, l2)
+	ctx := context.Background()
+
+	_ = l2.Set(ctx, "k", "v", time.Minute)
+
+	var s string
+	if err := cache.Get(ctx, "k", &s); err != nil || s != "v" {
+		t.Fatalf("Get() = %q, %v", s, err)
+	}
+	if !l1.Exists(ctx, "k") {
+		t.Error("L2命中后应回填L1")
+	}
+
+	_ = cache.Del(ctx, "k")
+	if l1.Exists(ctx, "k") || l2.Exists(ctx, "k") {
+		t.Error("删除应同时作用于两级缓存")
+	}
+}
+
+// TestTieredReadYourWrites 测试写入后即使L1被失效且L2复制延迟，仍能读到新值
+func TestTieredReadYourWrites(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []go_cache.TieredOption
+		want string
+	}{
+		{name: "未开启时读到旧值", want: "old"},
+		{name: "开启后读到新值", opts: []go_cache.TieredOption{go_cache.WithTieredReadYourWrites(time.Minute)}, want: "new"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, l1 := newLaggyTiered(tt.opts...)
+			ctx := context.Background()
+
+			_ = cache.Set(ctx, "k", "new", time.Minute)
+			// 模拟其他节点发出的失效通知先于复制到达
+			_ = l1.Del(ctx, "k")
+
+			var s string
+			if err := cache.Get(ctx, "k", &s); err != nil || s != tt.want {
+				t.Errorf("Get() = %q, %v, want %q", s, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestTieredReadYourWritesDelete 测试删除后的读取不会看到复制延迟中的旧值
+func TestTieredReadYourWritesDelete(t *testing.T) {
+	l1 := go_cache.NewMemory(time.Minute, time.Minute)
+	l2 := go_cache.NewMemory(time.Minute, time.Minute)
+	cache := go_cache.NewTiered(l1, l2, go_cache.WithTieredReadYourWrites(time.Minute))
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "k", "v", time.Minute)
+	_ = cache.Del(ctx, "k")
+	// 模拟旧值从副本重新同步回来
+	_ = l2.Set(ctx, "k", "v", time.Minute)
+
+	if cache.Exists(ctx, "k") {
+		t.Error("删除后的固定期内不应看到旧值")
+	}
+}
+
+// TestTieredReadYourWritesSession 测试会话级固定只对同一会话生效，且按时长过期
+func TestTieredReadYourWritesSession(t *testing.T) {
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache, l1 := newLaggyTiered(go_cache.WithTieredReadYourWrites(50*time.Millisecond), go_cache.WithTieredClock(clock))
+	ctx := context.Background()
+	session := go_cache.WithSessionToken(ctx, "alice")
+
+	_ = cache.Set(session, "k", "new", time.Minute)
+	_ = l1.Del(ctx, "k")
+
+	var s string
+	if _ = cache.Get(session, "k", &s); s != "new" {
+		t.Errorf("同一会话应读到新值，实际为%q", s)
+	}
+	_ = l1.Del(ctx, "k")
+	if _ = cache.Get(go_cache.WithSessionToken(ctx, "bob"), "k", &s); s != "old" {
+		t.Errorf("其他会话不受固定影响，应读到旧值，实际为%q", s)
+	}
+
+	clock.Advance(60 * time.Millisecond)
+	_ = l1.Del(ctx, "k")
+	if _ = cache.Get(session, "k", &s); s != "old" {
+		t.Errorf("固定过期后应读到副本的值，实际为%q", s)
+	}
+}
+
+// TestTieredReadYourWritesSessionOverridesProcess 测试会话内的写入会更新进程级固定条目
+func TestTieredReadYourWritesSessionOverridesProcess(t *testing.T) {
+	cache, l1 := newLaggyTiered(go_cache.WithTieredReadYourWrites(time.Minute))
+	ctx := context.Background()
+
+	_ = cache.Set(ctx, "k", "v1", time.Minute)
+	_ = cache.Set(go_cache.WithSessionToken(ctx, "alice"), "k", "v2", time.Minute)
+
+	var s string
+	if err := cache.Get(ctx, "k", &s); err != nil || s != "v2" {
+		t.Errorf("没有会话的读取 Get() = %q, %v, want v2", s, err)
+	}
+	_ = l1.Del(ctx, "k")
+	if err := cache.Get(ctx, "k", &s); err != nil || s != "v2" {
+		t.Errorf("L1失效后没有会话的读取 Get() = %q, %v, want v2", s, err)
+	}
+}
+
+// TestTieredExpiresInNonPositive 测试有效期不大于0时不把该有效期传给L1，而是删除L1中的键
+func TestTieredExpiresInNonPositive(t *testing.T) {
+	for _, ttl := range []time.Duration{0, -1} {
+		l1 := go_cache.NewMemory(time.Minute, time.Minute)
+		l2 := go_cache.NewMemory(time.Minute, time.Minute)
+		cache := go_cache.NewTiered(l1, l2)
+		ctx := context.Background()
+
+		_ = cache.Set(ctx, "k", "v", time.Minute)
+		if err := cache.ExpiresIn(ctx, "k", ttl); err != nil {
+			t.Fatalf("ExpiresIn(%v) error = %v", ttl, err)
+		}
+		if l1.Exists(ctx, "k") {
+			t.Errorf("ExpiresIn(%v) 后L1中不应保留该键", ttl)
+		}
+		if !cache.Exists(ctx, "k") {
+			t.Errorf("ExpiresIn(%v) 后仍应能从L2读取", ttl)
+		}
+	}
+}
+
+// stallingCache 读取时一直阻塞到ctx结束的缓存，记录读取时context的剩余时间
+type stallingCache struct {
+	*go_cache.Memory
+	budget time.Duration
+}
+
+func (s *stallingCache) Get(ctx context.Context, key string, obj any) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		s.budget = time.Until(deadline)
+	}
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// TestTieredBudget 测试L2只能使用分配的时间，超时后回调函数仍有时间执行
+func TestTieredBudget(t *testing.T) {
+	l2 := &stallingCache{Memory: go_cache.NewMemory(time.Minute, time.Minute)}
+	cache := go_cache.NewTiered(go_cache.NewMemory(time.Minute, time.Minute), l2,
+		go_cache.WithTieredBudget(go_cache.TieredBudget{L1: 0.1, L2: 0.6, Loader: 0.3}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	var got string
+	err := cache.GetSet(ctx, "k", time.Minute, &got, func(key string, obj any) error {
+		if ctx.Err() != nil {
+			t.Error("loader should run before the caller's deadline")
+		}
+		*obj.(*string) = "loaded"
+		return nil
+	})
+	if err != nil || got != "loaded" {
+		t.Fatalf("GetSet() = %q, %v, want loaded", got, err)
+	}
+	// L2 可以使用L1没有用完的时间，截止到剩余时间的70%
+	if l2.budget <= 0 || l2.budget > 360*time.Millisecond {
+		t.Errorf("L2 budget = %v, want at most 70%% of 500ms", l2.budget)
+	}
+}
+
+// TestTieredBudgetLoaderTimeout 测试回调函数超过剩余时间时返回 ErrLoaderTimeout
+func TestTieredBudgetLoaderTimeout(t *testing.T) {
+	cache := go_cache.NewTiered(go_cache.NewMemory(time.Minute, time.Minute), go_cache.NewMemory(time.Minute, time.Minute),
+		go_cache.WithTieredBudget(go_cache.TieredBudget{L1: 0.1, L2: 0.6, Loader: 0.3}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	var got string
+	err := cache.GetSet(ctx, "k", time.Minute, &got, func(key string, obj any) error {
+		time.Sleep(200 * time.Millisecond)
+		return nil
+	})
+	if !errors.Is(err, go_cache.ErrLoaderTimeout) {
+		t.Errorf("GetSet() error = %v, want ErrLoaderTimeout", err)
+	}
+
+	// 没有截止时间时不限制
+	err = cache.GetSet(context.Background(), "k", time.Minute, &got, func(key string, obj any) error {
+		time.Sleep(10 * time.Millisecond)
+		*obj.(*string) = "slow"
+		return nil
+	})
+	if err != nil || got != "slow" {
+		t.Errorf("GetSet() without deadline = %q, %v, want slow", got, err)
+	}
+}
+
+// blockingCache 读取时阻塞到ctx结束的缓存，读取返回的错误发送到 returned
+type blockingCache struct {
+	*go_cache.Memory
+	returned chan error
+}
+
+func newBlockingCache() *blockingCache {
+	return &blockingCache{Memory: go_cache.NewMemory(time.Minute, time.Minute), returned: make(chan error, 1)}
+}
+
+func (b *blockingCache) Get(ctx context.Context, key string, obj any) error {
+	<-ctx.Done()
+	b.returned <- ctx.Err()
+	return ctx.Err()
+}
+
+// TestTieredRaceReads 测试并行读取使用先成功的结果并取消另一个读取
+func TestTieredRaceReads(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("L1阻塞时使用L2的结果", func(t *testing.T) {
+		l1 := newBlockingCache()
+		l2 := go_cache.NewMemory(time.Minute, time.Minute)
+		_ = l2.Set(ctx, "k", "from-l2", time.Minute)
+		cache := go_cache.NewTiered(l1, l2, go_cache.WithTieredRaceReads())
+
+		var got string
+		if err := cache.Get(ctx, "k", &got); err != nil || got != "from-l2" {
+			t.Fatalf("Get() = %q, %v, want from-l2", got, err)
+		}
+		select {
+		case err := <-l1.returned:
+			if !errors.Is(err, context.Canceled) {
+				t.Errorf("L1 read error = %v, want context.Canceled", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("L1 read was not cancelled")
+		}
+		// 回填L1
+		var backfilled string
+		if err := l1.Memory.Get(ctx, "k", &backfilled); err != nil || backfilled != "from-l2" {
+			t.Errorf("L1 after backfill = %q, %v, want from-l2", backfilled, err)
+		}
+	})
+
+	t.Run("L2阻塞时使用L1的结果", func(t *testing.T) {
+		l1 := go_cache.NewMemory(time.Minute, time.Minute)
+		l2 := newBlockingCache()
+		_ = l1.Set(ctx, "k", "from-l1", time.Minute)
+		cache := go_cache.NewTiered(l1, l2, go_cache.WithTieredRaceReads())
+
+		var got string
+		if err := cache.Get(ctx, "k", &got); err != nil || got != "from-l1" {
+			t.Fatalf("Get() = %q, %v, want from-l1", got, err)
+		}
+		select {
+		case <-l2.returned:
+		case <-time.After(time.Second):
+			t.Fatal("L2 read was not cancelled")
+		}
+	})
+
+	t.Run("两级都未命中", func(t *testing.T) {
+		cache := go_cache.NewTiered(go_cache.NewMemory(time.Minute, time.Minute), go_cache.NewMemory(time.Minute, time.Minute),
+			go_cache.WithTieredRaceReads())
+		var got string
+		if err := cache.Get(ctx, "missing", &got); !errors.Is(err, go_cache.ErrKeyNotFound) {
+			t.Errorf("Get() error = %v, want ErrKeyNotFound", err)
+		}
+	})
+}
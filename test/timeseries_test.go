@@ -0,0 +1,156 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// timeSeriesBackends 在内存缓存与Redis上运行时间序列的测试，advance 推进缓存与时间序列的时间
+func timeSeriesBackends() map[string]func(t *testing.T, clock *go_cache.FakeClock) (gsr.Cacher, func(time.Duration)) {
+	return map[string]func(t *testing.T, clock *go_cache.FakeClock) (gsr.Cacher, func(time.Duration)){
+		"memory": func(t *testing.T, clock *go_cache.FakeClock) (gsr.Cacher, func(time.Duration)) {
+			return go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock)), clock.Advance
+		},
+		"redis": func(t *testing.T, clock *go_cache.FakeClock) (gsr.Cacher, func(time.Duration)) {
+			r, _ := newRedisTest(t)
+			return r.Cache, func(d time.Duration) {
+				clock.Advance(d)
+				r.FastForward(d)
+			}
+		},
+	}
+}
+
+// TestTimeSeriesQuery 测试按分辨率聚合与按步长降采样
+func TestTimeSeriesQuery(t *testing.T) {
+	for name, backend := range timeSeriesBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+			clock := go_cache.NewFakeClock(start)
+			cache, _ := backend(t, clock)
+			ts := go_cache.NewTimeSeries(cache, go_cache.WithTimeSeriesClock(clock))
+
+			samples := map[time.Duration][]float64{
+				0:                {10, 20},
+				30 * time.Second: {30},
+				time.Minute:      {5},
+				3 * time.Minute:  {40, 2},
+			}
+			for offset, values := range samples {
+				for _, v := range values {
+					if err := ts.RecordAt(ctx, "latency", v, start.Add(offset)); err != nil {
+						t.Fatal(err)
+					}
+				}
+			}
+
+			points, err := ts.Query(ctx, "latency", start, start.Add(5*time.Minute), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := []go_cache.TimeSeriesPoint{
+				{Time: start, Count: 3, Sum: 60, Min: 10, Max: 30},
+				{Time: start.Add(time.Minute), Count: 1, Sum: 5, Min: 5, Max: 5},
+				{Time: start.Add(3 * time.Minute), Count: 2, Sum: 42, Min: 2, Max: 40},
+			}
+			assertPoints(t, points, want)
+			if avg := points[0].Avg(); avg != 20 {
+				t.Errorf("Avg() = %v, want 20", avg)
+			}
+
+			// 按2分钟降采样
+			points, err = ts.Query(ctx, "latency", start, start.Add(5*time.Minute), 2*time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertPoints(t, points, []go_cache.TimeSeriesPoint{
+				{Time: start, Count: 4, Sum: 65, Min: 5, Max: 30},
+				{Time: start.Add(2 * time.Minute), Count: 2, Sum: 42, Min: 2, Max: 40},
+			})
+
+			// 查询范围不包含to所在的桶
+			points, err = ts.Query(ctx, "latency", start, start.Add(time.Minute), 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(points) != 1 || points[0].Count != 3 {
+				t.Errorf("Query([0, 1m)) = %+v, want one point with count 3", points)
+			}
+		})
+	}
+}
+
+// TestTimeSeriesRetention 测试桶超过保留时长后过期
+func TestTimeSeriesRetention(t *testing.T) {
+	for name, backend := range timeSeriesBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			start := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+			clock := go_cache.NewFakeClock(start)
+			cache, advance := backend(t, clock)
+			ts := go_cache.NewTimeSeries(cache, go_cache.WithTimeSeriesClock(clock),
+				go_cache.WithTimeSeriesResolution(time.Minute), go_cache.WithTimeSeriesRetention(time.Hour))
+
+			if err := ts.Record(ctx, "requests", 1); err != nil {
+				t.Fatal(err)
+			}
+			advance(time.Hour)
+			if err := ts.Record(ctx, "requests", 1); err != nil {
+				t.Fatal(err)
+			}
+			if points, err := ts.Query(ctx, "requests", start, clock.Now().Add(time.Minute), time.Hour); err != nil || len(points) != 2 {
+				t.Fatalf("Query() = %+v, %v, want 2 points", points, err)
+			}
+
+			advance(time.Minute)
+			points, err := ts.Query(ctx, "requests", start, clock.Now(), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(points) != 1 || !points[0].Time.Equal(start.Add(time.Hour)) {
+				t.Errorf("Query() after retention = %+v, want only the recent bucket", points)
+			}
+
+			// 已超过保留时长的写入被忽略
+			if err := ts.RecordAt(ctx, "requests", 1, start); err != nil {
+				t.Fatal(err)
+			}
+			if points, _ := ts.Query(ctx, "requests", start, start.Add(time.Minute), 0); len(points) != 0 {
+				t.Errorf("Query() of expired bucket = %+v, want empty", points)
+			}
+		})
+	}
+}
+
+// TestTimeSeriesInvalid 测试无效的值与过大的查询范围
+func TestTimeSeriesInvalid(t *testing.T) {
+	ctx := context.Background()
+	ts := go_cache.NewTimeSeries(go_cache.NewMemory(time.Minute, time.Minute), go_cache.WithTimeSeriesResolution(time.Second))
+	if err := ts.Record(ctx, "cpu", math.NaN()); !errors.Is(err, go_cache.ErrInvalidSample) {
+		t.Errorf("Record(NaN) error = %v, want ErrInvalidSample", err)
+	}
+	now := time.Now()
+	if _, err := ts.Query(ctx, "cpu", now.Add(-365*24*time.Hour), now, time.Hour); err == nil {
+		t.Error("Query() spanning too many buckets should fail")
+	}
+}
+
+func assertPoints(t *testing.T, got, want []go_cache.TimeSeriesPoint) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("points = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if !got[i].Time.Equal(want[i].Time) || got[i].Count != want[i].Count || got[i].Sum != want[i].Sum ||
+			got[i].Min != want[i].Min || got[i].Max != want[i].Max {
+			t.Errorf("point %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
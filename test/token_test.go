@@ -0,0 +1,184 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestTokenCache 测试令牌的缓存与过期
+func TestTokenCache(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var fetches atomic.Int32
+	fetch := func(ctx context.Context, client string, scopes []string) (go_cache.Token, error) {
+		n := fetches.Add(1)
+		return go_cache.Token{AccessToken: fmt.Sprintf("%s-%d", client, n), ExpiresAt: clock.Now().Add(time.Hour)}, nil
+	}
+	tokens := go_cache.NewTokenCache(go_cache.NewMemory(time.Hour, time.Minute), fetch, go_cache.WithTokenClock(clock))
+
+	first, err := tokens.Token(ctx, "billing", "read", "write")
+	if err != nil || first.AccessToken != "billing-1" {
+		t.Fatalf("Token() = %+v, %v", first, err)
+	}
+	if tok, _ := tokens.Token(ctx, "billing", "write", "read"); tok.AccessToken != first.AccessToken {
+		t.Errorf("权限范围顺序不同时应使用同一个令牌，Token() = %s", tok.AccessToken)
+	}
+	if tok, _ := tokens.Token(ctx, "billing", "read"); tok.AccessToken == first.AccessToken {
+		t.Error("不同的权限范围应使用不同的令牌")
+	}
+
+	clock.Advance(2 * time.Hour)
+	if tok, _ := tokens.Token(ctx, "billing", "read", "write"); tok.AccessToken == first.AccessToken {
+		t.Error("过期的令牌不应被使用")
+	}
+
+	if err := tokens.Invalidate(ctx, "billing", "read"); err != nil {
+		t.Fatal(err)
+	}
+	before := fetches.Load()
+	if _, err := tokens.Token(ctx, "billing", "read"); err != nil || fetches.Load() != before+1 {
+		t.Errorf("Invalidate 后应获取新令牌，fetches = %d", fetches.Load())
+	}
+}
+
+// TestTokenCacheSingleflight 测试并发获取合并为一次
+func TestTokenCacheSingleflight(t *testing.T) {
+	var fetches atomic.Int32
+	release := make(chan struct{})
+	fetch := func(ctx context.Context, client string, scopes []string) (go_cache.Token, error) {
+		fetches.Add(1)
+		<-release
+		return go_cache.Token{AccessToken: "t", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	tokens := go_cache.NewTokenCache(go_cache.NewMemory(time.Hour, time.Minute), fetch)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if tok, err := tokens.Token(context.Background(), "svc"); err != nil || tok.AccessToken != "t" {
+				t.Errorf("Token() = %+v, %v", tok, err)
+			}
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("并发获取应合并为一次，fetches = %d", n)
+	}
+}
+
+// TestTokenCacheRefreshAhead 测试过期前的后台刷新
+func TestTokenCacheRefreshAhead(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var fetches atomic.Int32
+	var fail atomic.Bool
+	refreshed := make(chan struct{}, 10)
+	fetch := func(ctx context.Context, client string, scopes []string) (go_cache.Token, error) {
+		defer func() { refreshed <- struct{}{} }()
+		if fail.Load() {
+			return go_cache.Token{}, errors.New("authorization server down")
+		}
+		n := fetches.Add(1)
+		return go_cache.Token{AccessToken: fmt.Sprint(n), ExpiresAt: clock.Now().Add(10 * time.Minute)}, nil
+	}
+	var hookErr atomic.Value
+	tokens := go_cache.NewTokenCache(go_cache.NewMemory(time.Hour, time.Minute), fetch,
+		go_cache.WithTokenClock(clock),
+		go_cache.WithTokenRefreshAhead(2*time.Minute),
+		go_cache.WithTokenRefreshHook(func(client string, scopes []string, err error) { hookErr.Store(err) }),
+	)
+
+	if _, err := tokens.Token(ctx, "svc"); err != nil {
+		t.Fatal(err)
+	}
+	<-refreshed
+
+	// 进入提前刷新的时间窗口：返回缓存的令牌，后台获取新令牌
+	clock.Advance(9 * time.Minute)
+	if tok, _ := tokens.Token(ctx, "svc"); tok.AccessToken != "1" {
+		t.Errorf("提前刷新时应返回缓存的令牌，Token() = %s", tok.AccessToken)
+	}
+	<-refreshed
+	waitFor(t, func() bool {
+		tok, _ := tokens.Token(ctx, "svc")
+		return tok.AccessToken == "2"
+	})
+
+	// 后台刷新失败时继续使用缓存的令牌并调用回调
+	fail.Store(true)
+	clock.Advance(9 * time.Minute)
+	if tok, err := tokens.Token(ctx, "svc"); err != nil || tok.AccessToken != "2" {
+		t.Errorf("Token() = %+v, %v", tok, err)
+	}
+	<-refreshed
+	waitFor(t, func() bool { return hookErr.Load() != nil })
+}
+
+// TestTokenCacheKeyAmbiguity 测试客户端与权限范围中的分隔符不会使不同的组合共用令牌
+func TestTokenCacheKeyAmbiguity(t *testing.T) {
+	ctx := context.Background()
+	fetch := func(ctx context.Context, client string, scopes []string) (go_cache.Token, error) {
+		return go_cache.Token{AccessToken: fmt.Sprintf("%q%q", client, scopes), ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	tokens := go_cache.NewTokenCache(go_cache.NewMemory(time.Hour, time.Minute), fetch, go_cache.WithTokenClock(nil))
+
+	pairs := [][2][]string{
+		{{"a:b", "c"}, {"a", "b:c"}},
+		{{"svc", "read write"}, {"svc", "read", "write"}},
+	}
+	for _, pair := range pairs {
+		first, err := tokens.Token(ctx, pair[0][0], pair[0][1:]...)
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		second, err := tokens.Token(ctx, pair[1][0], pair[1][1:]...)
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if first.AccessToken == second.AccessToken {
+			t.Errorf("%q 与 %q 不应共用令牌 %s", pair[0], pair[1], first.AccessToken)
+		}
+	}
+}
+
+// TestTokenCacheFetchPanic 测试获取令牌的函数panic时返回错误，之后的调用重新获取
+func TestTokenCacheFetchPanic(t *testing.T) {
+	ctx := context.Background()
+	var fetches atomic.Int32
+	fetch := func(ctx context.Context, client string, scopes []string) (go_cache.Token, error) {
+		if fetches.Add(1) == 1 {
+			panic("boom")
+		}
+		return go_cache.Token{AccessToken: "t", ExpiresAt: time.Now().Add(time.Hour)}, nil
+	}
+	tokens := go_cache.NewTokenCache(go_cache.NewMemory(time.Hour, time.Minute), fetch)
+
+	var panicErr *go_cache.LoaderPanicError
+	if _, err := tokens.Token(ctx, "svc"); !errors.As(err, &panicErr) || panicErr.Value != "boom" {
+		t.Fatalf("Token() error = %v, want *LoaderPanicError", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if tok, err := tokens.Token(ctx, "svc"); err != nil || tok.AccessToken != "t" {
+			t.Errorf("panic 之后 Token() = %+v, %v", tok, err)
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("panic 之后的获取不应一直等待")
+	}
+}
@@ -0,0 +1,99 @@
+package test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+)
+
+// TestTTLPolicy 测试按前缀计算有效期
+func TestTTLPolicy(t *testing.T) {
+	cache := go_cache.NewTTLPolicyCache(go_cache.NewMemory(time.Minute, time.Minute), map[string]go_cache.TTLPolicy{
+		"session:":       {Default: 30 * time.Minute, Max: time.Hour},
+		"session:admin:": {Default: 5 * time.Minute},
+		"feed:":          {Default: time.Minute, Jitter: 0.5},
+	})
+
+	tests := []struct {
+		name string
+		key  string
+		ttl  time.Duration
+		want time.Duration
+	}{
+		{"使用默认值", "session:1", 0, 30 * time.Minute},
+		{"保留调用方的ttl", "session:1", 10 * time.Minute, 10 * time.Minute},
+		{"按上限截断", "session:1", 2 * time.Hour, time.Hour},
+		{"最长前缀", "session:admin:1", 0, 5 * time.Minute},
+		{"没有匹配的策略", "user:1", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := cache.TTL(tt.key, tt.ttl); got != tt.want {
+			t.Errorf("%s: TTL() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	seen := map[time.Duration]bool{}
+	for i := 0; i < 100; i++ {
+		ttl := cache.TTL("feed:1", 0)
+		if ttl < 30*time.Second || ttl > time.Minute {
+			t.Fatalf("抖动后的 TTL() = %v，应在 [30s, 1m] 内", ttl)
+		}
+		seen[ttl] = true
+	}
+	if len(seen) < 2 {
+		t.Error("抖动应产生不同的有效期")
+	}
+}
+
+// TestTTLPolicyCache 测试写入与滑动过期
+func TestTTLPolicyCache(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+	cache := go_cache.NewTTLPolicyCache(r.Cache, map[string]go_cache.TTLPolicy{
+		"session:": {Default: 30 * time.Minute, Sliding: true},
+		"config:":  {Default: time.Hour},
+	})
+
+	if err := cache.Set(ctx, "config:a", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := r.Cache.TTL(ctx, "config:a"); ttl <= 59*time.Minute || ttl > time.Hour {
+		t.Errorf("ttl=0 时应使用策略的默认值，TTL() = %v", ttl)
+	}
+
+	var s string
+	err := cache.GetSet(ctx, "session:1", 0, &s, func(key string, obj any) error {
+		*obj.(*string) = "alice"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GetSet() error = %v", err)
+	}
+	if ttl, _ := r.Cache.TTL(ctx, "session:1"); ttl <= 29*time.Minute {
+		t.Errorf("GetSet 写入时应使用策略的默认值，TTL() = %v", ttl)
+	}
+
+	// 命中时重新设置有效期
+	if err := r.Cache.ExpiresIn(ctx, "session:1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Get(ctx, "session:1", &s); err != nil || s != "alice" {
+		t.Fatalf("Get() = %q, %v", s, err)
+	}
+	if ttl, _ := r.Cache.TTL(ctx, "session:1"); ttl <= 29*time.Minute {
+		t.Errorf("滑动过期的键命中后应重新设置有效期，TTL() = %v", ttl)
+	}
+
+	// 不滑动的键命中后有效期不变
+	if err := r.Cache.ExpiresIn(ctx, "config:a", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.Get(ctx, "config:a", &s); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := r.Cache.TTL(ctx, "config:a"); ttl > time.Minute {
+		t.Errorf("没有开启滑动过期时 TTL() = %v", ttl)
+	}
+}
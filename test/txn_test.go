@@ -0,0 +1,135 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// txnBackend 支持事务的缓存
+type txnBackend interface {
+	gsr.Cacher
+	go_cache.TxnCache
+}
+
+func txnBackends(t *testing.T) map[string]txnBackend {
+	r, _ := newRedisTest(t)
+	return map[string]txnBackend{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+}
+
+// TestTxnCommit 测试事务中的写操作一起生效
+func TestTxnCommit(t *testing.T) {
+	ctx := context.Background()
+	for name, cache := range txnBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := cache.Set(ctx, "txn:old", "v", time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			err := cache.Txn(ctx, func(tx go_cache.Txn) error {
+				if err := tx.Set("txn:a", 1, time.Minute); err != nil {
+					return err
+				}
+				if err := tx.Set("txn:b", 2, 0); err != nil {
+					return err
+				}
+				tx.Del("txn:old")
+				tx.ExpiresIn("txn:missing", time.Minute)
+
+				// 提交前写操作不可见
+				if cache.Exists(ctx, "txn:a") {
+					t.Error("提交前写操作不应生效")
+				}
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("Txn() error = %v", err)
+			}
+
+			var a, b int
+			if err := cache.Get(ctx, "txn:a", &a); err != nil || a != 1 {
+				t.Errorf("Get(txn:a) = %d, %v", a, err)
+			}
+			if err := cache.Get(ctx, "txn:b", &b); err != nil || b != 2 {
+				t.Errorf("Get(txn:b) = %d, %v", b, err)
+			}
+			if cache.Exists(ctx, "txn:old") {
+				t.Error("txn:old 应被删除")
+			}
+		})
+	}
+}
+
+// TestTxnRollback 测试事务函数返回错误时写操作被丢弃
+func TestTxnRollback(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+	for name, cache := range txnBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			err := cache.Txn(ctx, func(tx go_cache.Txn) error {
+				_ = tx.Set("txn:rollback", 1, time.Minute)
+				return boom
+			})
+			if !errors.Is(err, boom) {
+				t.Fatalf("Txn() error = %v, want %v", err, boom)
+			}
+			if cache.Exists(ctx, "txn:rollback") {
+				t.Error("事务失败时写操作不应生效")
+			}
+		})
+	}
+}
+
+// TestTxnWatchConflict 测试监视的键被修改时事务中止
+func TestTxnWatchConflict(t *testing.T) {
+	ctx := context.Background()
+	for name, cache := range txnBackends(t) {
+		t.Run(name, func(t *testing.T) {
+			if err := cache.Set(ctx, "txn:balance", 10, time.Minute); err != nil {
+				t.Fatalf("Set() error = %v", err)
+			}
+
+			err := cache.Txn(ctx, func(tx go_cache.Txn) error {
+				var balance int
+				if err := tx.Get(ctx, "txn:balance", &balance); err != nil {
+					return err
+				}
+				// 其他客户端在提交前修改了监视的键
+				if err := cache.Set(ctx, "txn:balance", 100, time.Minute); err != nil {
+					return err
+				}
+				return tx.Set("txn:balance", balance-1, time.Minute)
+			}, "txn:balance")
+			if !errors.Is(err, go_cache.ErrTxnConflict) {
+				t.Fatalf("Txn() error = %v, want ErrTxnConflict", err)
+			}
+
+			var balance int
+			if err := cache.Get(ctx, "txn:balance", &balance); err != nil || balance != 100 {
+				t.Errorf("Get() = %d, %v, want 100", balance, err)
+			}
+
+			// 没有冲突时读-改-写成功
+			err = cache.Txn(ctx, func(tx go_cache.Txn) error {
+				var balance int
+				if err := tx.Get(ctx, "txn:balance", &balance); err != nil {
+					return err
+				}
+				return tx.Set("txn:balance", balance-1, time.Minute)
+			}, "txn:balance")
+			if err != nil {
+				t.Fatalf("Txn() error = %v", err)
+			}
+			if err := cache.Get(ctx, "txn:balance", &balance); err != nil || balance != 99 {
+				t.Errorf("Get() = %d, %v, want 99", balance, err)
+			}
+		})
+	}
+}
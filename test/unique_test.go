@@ -0,0 +1,125 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// uniqueBackends 在内存缓存与Redis上运行不重复计数的测试
+// union 为false时 PFCOUNT 多个键返回各键计数之和而不是并集的计数（miniredis的实现）
+func uniqueBackends() map[string]func(t *testing.T) (cache gsr.Cacher, union bool) {
+	return map[string]func(t *testing.T) (gsr.Cacher, bool){
+		"memory": func(t *testing.T) (gsr.Cacher, bool) {
+			return go_cache.NewMemory(time.Minute, time.Minute), true
+		},
+		"redis": func(t *testing.T) (gsr.Cacher, bool) {
+			r, _ := newRedisTest(t)
+			return r.Cache, r.Server == nil
+		},
+	}
+}
+
+// TestUniqueSmall 测试元素较少时的计数与返回值
+func TestUniqueSmall(t *testing.T) {
+	for name, backend := range uniqueBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, union := backend(t)
+			u := cache.(go_cache.UniqueCache)
+
+			if changed, err := u.UniqueAdd(ctx, "visitors:mon", "alice", "bob"); err != nil || !changed {
+				t.Fatalf("UniqueAdd() = %v, %v, want true", changed, err)
+			}
+			if changed, err := u.UniqueAdd(ctx, "visitors:mon", "alice"); err != nil || changed {
+				t.Errorf("UniqueAdd() duplicate = %v, %v, want false", changed, err)
+			}
+			if _, err := u.UniqueAdd(ctx, "visitors:tue", "bob", "carol"); err != nil {
+				t.Fatal(err)
+			}
+
+			if n, err := u.UniqueCount(ctx, "visitors:mon"); err != nil || n != 2 {
+				t.Errorf("UniqueCount(mon) = %d, %v, want 2", n, err)
+			}
+			if n, err := u.UniqueCount(ctx, "visitors:mon", "visitors:tue", "visitors:missing"); err != nil || (union && n != 3) {
+				t.Errorf("UniqueCount(mon, tue) = %d, %v, want 3", n, err)
+			}
+			if n, err := u.UniqueCount(ctx, "visitors:missing"); err != nil || n != 0 {
+				t.Errorf("UniqueCount(missing) = %d, %v, want 0", n, err)
+			}
+		})
+	}
+}
+
+// TestUniqueLarge 测试元素较多时估计值的误差
+func TestUniqueLarge(t *testing.T) {
+	for name, backend := range uniqueBackends() {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			cache, _ := backend(t)
+			u := cache.(go_cache.UniqueCache)
+
+			const total = 20000
+			ids := make([]string, 0, 1000)
+			for i := 0; i < total; i++ {
+				ids = append(ids, fmt.Sprintf("user:%d", i))
+				if len(ids) == cap(ids) {
+					if _, err := u.UniqueAdd(ctx, fmt.Sprintf("part:%d", i%2), ids...); err != nil {
+						t.Fatal(err)
+					}
+					ids = ids[:0]
+				}
+			}
+
+			n, err := u.UniqueCount(ctx, "part:0", "part:1")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := math.Abs(float64(n-total)) / total; diff > 0.03 {
+				t.Errorf("UniqueCount() = %d, want %d within 3%%", n, total)
+			}
+		})
+	}
+}
+
+// TestUniqueMemoryKeepsTTL 测试内存缓存写入时保留原有的过期时间
+func TestUniqueMemoryKeepsTTL(t *testing.T) {
+	ctx := context.Background()
+	clock := go_cache.NewFakeClock(time.Time{})
+	cache := go_cache.NewMemory(time.Minute, time.Minute, go_cache.WithMemoryClock(clock))
+
+	if _, err := cache.UniqueAdd(ctx, "daily", "a"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.ExpiresIn(ctx, "daily", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.UniqueAdd(ctx, "daily", "b"); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Hour)
+	if n, err := cache.UniqueCount(ctx, "daily"); err != nil || n != 0 {
+		t.Errorf("UniqueCount() after expiry = %d, %v, want 0", n, err)
+	}
+}
+
+// TestUniqueMemoryTypeMismatch 测试键保存其他类型的值时返回 ErrTypeMismatch
+func TestUniqueMemoryTypeMismatch(t *testing.T) {
+	ctx := context.Background()
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	if err := cache.Set(ctx, "plain", "value", 0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.UniqueAdd(ctx, "plain", "a"); !errors.Is(err, go_cache.ErrTypeMismatch) {
+		t.Errorf("UniqueAdd() error = %v, want ErrTypeMismatch", err)
+	}
+	if _, err := cache.UniqueCount(ctx, "plain"); !errors.Is(err, go_cache.ErrTypeMismatch) {
+		t.Errorf("UniqueCount() error = %v, want ErrTypeMismatch", err)
+	}
+}
@@ -0,0 +1,143 @@
+package test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+)
+
+// TestSerializerRecognize 测试不知道值类型时判断数据格式
+func TestSerializerRecognize(t *testing.T) {
+	gobData, _ := serializer.NewGob().Encode(TestSerializerUser{ID: 1})
+	jsonData, _ := serializer.NewJson().Encode(TestSerializerUser{ID: 1})
+	gzipped := serializer.NewPipeline(serializer.NewGob(), serializer.Gzip(gzip.BestSpeed))
+	gzipData, _ := gzipped.Encode("v")
+
+	tests := []struct {
+		name string
+		s    serializer.Serializer
+		data []byte
+		want bool
+	}{
+		{"gob", serializer.NewGob(), gobData, true},
+		{"gob 读取json", serializer.NewGob(), jsonData, false},
+		{"json", serializer.NewJson(), jsonData, true},
+		{"json 读取gob", serializer.NewJson(), gobData, false},
+		{"json 其他程序写入的对象", serializer.NewJson(), []byte(`{"id":1}`), false},
+		{"pipeline", gzipped, gzipData, true},
+		{"pipeline 读取未压缩的值", gzipped, gobData, false},
+		{"限制大小", serializer.WithLimits(serializer.NewJson(), serializer.Limits{MaxSize: 8}), jsonData, false},
+	}
+	for _, tt := range tests {
+		if got := serializer.Recognize(tt.s, tt.data); got != tt.want {
+			t.Errorf("%s: Recognize() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestVerify 测试启动时的往返检查与键空间抽样
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	if err := go_cache.Verify(ctx, r.Cache); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if keys, _ := r.Cache.Keys(ctx, go_cache.DefaultVerifyCanaryKey+"*"); len(keys) != 0 {
+		t.Errorf("Verify 应删除探测键，剩余%v", keys)
+	}
+	if err := go_cache.Verify(ctx, go_cache.NewMemory(time.Minute, time.Minute)); err != nil {
+		t.Errorf("Memory: Verify() error = %v", err)
+	}
+
+	// 之前的部署使用JSON写入的值
+	jsonCache := go_cache.NewRedis(r.Client, go_cache.WithRedisSerializer(serializer.NewJson()))
+	for _, key := range []string{"app:1", "app:2"} {
+		if err := jsonCache.Set(ctx, key, "v", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Cache.Set(ctx, "app:3", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	err := go_cache.Verify(ctx, r.Cache, go_cache.WithVerifyPattern("app:*"))
+	var mismatch *go_cache.SerializerMismatchError
+	if !errors.As(err, &mismatch) || !errors.Is(err, go_cache.ErrSerializerMismatch) {
+		t.Fatalf("Verify() error = %v，期望 SerializerMismatchError", err)
+	}
+	if mismatch.Sampled != 3 || len(mismatch.Keys) != 2 || mismatch.Serializer != "gob" {
+		t.Errorf("SerializerMismatchError = %+v", mismatch)
+	}
+
+	if err := go_cache.Verify(ctx, r.Cache, go_cache.WithVerifySample(0)); err != nil {
+		t.Errorf("不检查键空间时 Verify() error = %v", err)
+	}
+	if err := go_cache.Verify(ctx, r.Cache, go_cache.WithVerifyPattern("app:*"), go_cache.WithVerifySample(1)); err != nil && !errors.Is(err, go_cache.ErrSerializerMismatch) {
+		t.Errorf("Verify() error = %v", err)
+	}
+
+	// 租户只检查自己的键
+	tenant, _ := go_cache.ForTenant(r.Cache, "acme")
+	if err := go_cache.Verify(ctx, tenant); err != nil {
+		t.Errorf("TenantCache: Verify() error = %v", err)
+	}
+	if err := jsonCache.Set(ctx, tenant.Key("k"), "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := go_cache.Verify(ctx, tenant); !errors.Is(err, go_cache.ErrSerializerMismatch) {
+		t.Errorf("TenantCache: Verify() error = %v，期望 ErrSerializerMismatch", err)
+	}
+}
+
+// TestVerifySigningKey 测试签名密钥变更后的检查
+func TestVerifySigningKey(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	old := go_cache.NewRedis(r.Client, go_cache.WithRedisSigning([]byte("old")))
+	if err := old.Set(ctx, "k", "v", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	current := go_cache.NewRedis(r.Client, go_cache.WithRedisSigning([]byte("new")))
+	if err := go_cache.Verify(ctx, current, go_cache.WithVerifySample(1)); !errors.Is(err, go_cache.ErrSerializerMismatch) {
+		t.Errorf("Verify() error = %v，旧密钥签名的值应被报告", err)
+	}
+}
+
+// canaryRecorder 记录写入的键
+type canaryRecorder struct {
+	*go_cache.Memory
+	keys []string
+}
+
+func (c *canaryRecorder) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	c.keys = append(c.keys, key)
+	return c.Memory.Set(ctx, key, value, ttl)
+}
+
+// TestVerifyCanaryKeyUnique 测试每次检查使用不同的探测键，同时启动的实例不会互相干扰
+func TestVerifyCanaryKeyUnique(t *testing.T) {
+	ctx := context.Background()
+	cache := &canaryRecorder{Memory: go_cache.NewMemory(time.Minute, time.Minute)}
+
+	for i := 0; i < 2; i++ {
+		if err := go_cache.Verify(ctx, cache); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+	if len(cache.keys) != 2 || cache.keys[0] == cache.keys[1] {
+		t.Fatalf("探测键为%v，每次检查应不同", cache.keys)
+	}
+	for _, key := range cache.keys {
+		if !strings.HasPrefix(key, go_cache.DefaultVerifyCanaryKey+":") {
+			t.Errorf("探测键 %q 应以 DefaultVerifyCanaryKey 为前缀", key)
+		}
+	}
+}
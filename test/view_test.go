@@ -0,0 +1,128 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestMaterializedViewLazy 测试数据源更新后视图在下一次 Get 时重新计算
+func TestMaterializedViewLazy(t *testing.T) {
+	ctx := context.Background()
+	r, _ := newRedisTest(t)
+
+	backends := map[string]gsr.Cacher{
+		"memory": go_cache.NewMemory(time.Minute, time.Minute),
+		"redis":  r.Cache,
+	}
+
+	for name, backend := range backends {
+		t.Run(name, func(t *testing.T) {
+			graph := go_cache.NewDependencyGraph(backend)
+			_ = graph.Set(ctx, "order:1", 10, time.Minute)
+			_ = graph.Set(ctx, "order:2", 20, time.Minute)
+
+			var loads atomic.Int32
+			view := go_cache.NewMaterializedView(graph, "orders:total", []string{"order:1", "order:2"}, time.Minute,
+				func(ctx context.Context) (any, error) {
+					loads.Add(1)
+					var a, b int
+					if err := graph.Get(ctx, "order:1", &a); err != nil {
+						return nil, err
+					}
+					if err := graph.Get(ctx, "order:2", &b); err != nil {
+						return nil, err
+					}
+					return a + b, nil
+				})
+
+			var total int
+			for i := 0; i < 2; i++ {
+				if err := view.Get(ctx, &total); err != nil || total != 30 {
+					t.Fatalf("Get() = %d, %v，期望为30", total, err)
+				}
+			}
+			if loads.Load() != 1 {
+				t.Errorf("命中时不应重新计算，计算次数 = %d", loads.Load())
+			}
+
+			_ = graph.Set(ctx, "order:2", 25, time.Minute)
+			if backend.Exists(ctx, view.Key()) {
+				t.Error("数据源更新后视图应被删除")
+			}
+			if err := view.Get(ctx, &total); err != nil || total != 35 {
+				t.Errorf("数据源更新后 Get() = %d, %v，期望为35", total, err)
+			}
+
+			// 重新计算时再次声明依赖
+			_ = graph.Del(ctx, "order:1")
+			if backend.Exists(ctx, view.Key()) {
+				t.Error("再次计算后数据源删除时视图应被删除")
+			}
+			if err := view.Get(ctx, &total); !errors.Is(err, go_cache.ErrKeyNotFound) {
+				t.Errorf("数据源不存在时 Get() error = %v，期望为 ErrKeyNotFound", err)
+			}
+		})
+	}
+}
+
+// TestMaterializedViewEager 测试数据源更新后视图立即在后台重新计算
+func TestMaterializedViewEager(t *testing.T) {
+	ctx := context.Background()
+	graph := go_cache.NewDependencyGraph(go_cache.NewMemory(time.Minute, time.Minute))
+	_ = graph.Set(ctx, "user:1", "alice", time.Minute)
+
+	var loads atomic.Int32
+	loadErr := errors.New("source unavailable")
+	var fail atomic.Bool
+	var hooked atomic.Int32
+	view := go_cache.NewMaterializedView(graph, "users:count", []string{"user:1"}, time.Minute,
+		func(ctx context.Context) (any, error) {
+			if fail.Load() {
+				return nil, loadErr
+			}
+			return int(loads.Add(1)), nil
+		},
+		go_cache.WithViewEager(),
+		go_cache.WithViewErrorHook(func(key string, err error) {
+			if key == "users:count" && errors.Is(err, loadErr) {
+				hooked.Add(1)
+			}
+		}))
+
+	if err := view.Refresh(ctx); err != nil {
+		t.Fatalf("Refresh() error = %v", err)
+	}
+
+	_ = graph.Set(ctx, "user:1", "bob", time.Minute)
+	view.Wait()
+	var got int
+	if err := graph.Get(ctx, view.Key(), &got); err != nil || got != 2 {
+		t.Errorf("数据源更新后视图 = %d, %v，期望已重新计算为2", got, err)
+	}
+
+	fail.Store(true)
+	_ = graph.Set(ctx, "user:1", "carol", time.Minute)
+	view.Wait()
+	if hooked.Load() != 1 {
+		t.Errorf("重新计算失败时应调用错误回调，调用次数 = %d", hooked.Load())
+	}
+	if graph.Exists(ctx, view.Key()) {
+		t.Error("重新计算失败时视图应保持失效")
+	}
+
+	// 主动失效不触发重新计算
+	fail.Store(false)
+	_ = view.Refresh(ctx)
+	before := loads.Load()
+	_ = view.Invalidate(ctx)
+	view.Wait()
+	if loads.Load() != before || graph.Exists(ctx, view.Key()) {
+		t.Error("Invalidate 后应在下一次 Get 时重新计算")
+	}
+}
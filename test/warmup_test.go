@@ -0,0 +1,136 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/gsr"
+)
+
+// TestWarmerReady 测试全部预热任务结束后 Ready 关闭
+func TestWarmerReady(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	ctx := context.Background()
+	w := go_cache.NewWarmer(cache, go_cache.WithWarmupConcurrency(2))
+
+	release := make(chan struct{})
+	_ = w.Add("users", func(ctx context.Context, cache gsr.Cacher) error {
+		return cache.Set(ctx, "user:1", "alice", time.Minute)
+	})
+	_ = w.Add("config", func(ctx context.Context, cache gsr.Cacher) error {
+		<-release
+		return cache.Set(ctx, "config", "v1", time.Minute)
+	})
+	if err := w.Add("users", nil); err == nil {
+		t.Error("重复的任务名应返回错误")
+	}
+
+	if p := w.Progress(); p.Total != 2 || p.Completed != 0 || p.Done() {
+		t.Errorf("开始前的进度不正确: %+v", p)
+	}
+	w.Start(ctx)
+
+	// 等待 users 结束，config 仍在执行
+	deadline := time.Now().Add(2 * time.Second)
+	for w.Progress().Completed != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	p := w.Progress()
+	if p.Completed != 1 || len(p.Pending) != 1 || p.Pending[0] != "config" {
+		t.Fatalf("进度不正确: %+v", p)
+	}
+	select {
+	case <-w.Ready():
+		t.Fatal("预热任务未全部结束时不应就绪")
+	default:
+	}
+	if err := w.Add("late", nil); !errors.Is(err, go_cache.ErrWarmupStarted) {
+		t.Errorf("开始后加入任务应返回 ErrWarmupStarted，实际为%v", err)
+	}
+
+	close(release)
+	if err := w.Wait(ctx); err != nil {
+		t.Fatalf("Wait() error = %v", err)
+	}
+	if p := w.Progress(); !p.Done() || p.Failed != 0 || len(p.Pending) != 0 {
+		t.Errorf("结束后的进度不正确: %+v", p)
+	}
+	var v string
+	if err := cache.Get(ctx, "config", &v); err != nil || v != "v1" {
+		t.Errorf("预热的数据应写入缓存，Get() = %q, %v", v, err)
+	}
+}
+
+// TestWarmerFailures 测试失败的任务重试后仍视为结束并报告错误
+func TestWarmerFailures(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+	var hookCalls, attempts atomic.Int32
+	w := go_cache.NewWarmer(cache,
+		go_cache.WithWarmupRetry(2, time.Millisecond),
+		go_cache.WithWarmupErrorHook(func(key string, err error) { hookCalls.Add(1) }),
+	)
+
+	_ = w.Add("flaky", func(ctx context.Context, cache gsr.Cacher) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("temporary")
+		}
+		return nil
+	})
+	_ = w.Add("broken", func(ctx context.Context, cache gsr.Cacher) error {
+		return errors.New("db down")
+	})
+	_ = w.Add("panics", func(ctx context.Context, cache gsr.Cacher) error {
+		panic("boom")
+	})
+
+	err := w.Run(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "warmup broken: db down") {
+		t.Fatalf("Run() 应返回失败任务的错误，实际为%v", err)
+	}
+	var perr *go_cache.LoaderPanicError
+	if !errors.As(err, &perr) {
+		t.Errorf("panic应转换为 LoaderPanicError，实际为%v", err)
+	}
+
+	p := w.Progress()
+	if !p.Done() || p.Failed != 2 || p.Errors["flaky"] != nil {
+		t.Errorf("进度不正确: %+v", p)
+	}
+	// flaky 失败2次，broken 与 panics 各失败3次
+	if hookCalls.Load() != 8 {
+		t.Errorf("错误回调次数应为8，实际为%d", hookCalls.Load())
+	}
+}
+
+// TestWarmerEmptyAndCancel 测试没有任务时立即就绪，ctx结束时未完成的任务以ctx的错误结束
+func TestWarmerEmptyAndCancel(t *testing.T) {
+	cache := go_cache.NewMemory(time.Minute, time.Minute)
+
+	empty := go_cache.NewWarmer(cache)
+	if err := empty.Run(context.Background()); err != nil {
+		t.Fatalf("没有任务时 Run() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := go_cache.NewWarmer(cache)
+	_ = w.Add("slow", func(ctx context.Context, cache gsr.Cacher) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	w.Start(ctx)
+	cancel()
+
+	select {
+	case <-w.Ready():
+	case <-time.After(2 * time.Second):
+		t.Fatal("ctx结束后应就绪")
+	}
+	if err := w.Err(); !errors.Is(err, context.Canceled) {
+		t.Errorf("Err() 应为 context.Canceled，实际为%v", err)
+	}
+}
@@ -0,0 +1,415 @@
+// Package testcache 提供用于单元测试的确定性缓存实现
+//
+// Cache 实现 gsr.Cacher，记录所有操作，使用可推进的时钟判断过期，
+// 并支持按键统计调用次数、注入错误和导出完整内容，适合表驱动测试缓存相关逻辑
+package testcache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// ErrNotFound 键不存在或已过期，与 go_cache.ErrKeyNotFound 相同
+var ErrNotFound = go_cache.ErrKeyNotFound
+
+// Call 一次缓存操作的记录
+type Call struct {
+	Op    go_cache.Operation
+	Key   string        // Keys/DelByPattern 操作记录的是模式
+	TTL   time.Duration // 仅 Set/GetSet/Expire 操作有效
+	Hit   bool          // 仅 Exists/Get/GetSet 操作有效
+	Err   error
+	Value any // 仅 Set 操作有效，为写入的值
+}
+
+// Entry 缓存中的一个条目
+type Entry struct {
+	Value     any       // 写入的值；配置序列化器时为序列化后的 []byte
+	ExpiresAt time.Time // 过期时间，零值表示不过期
+}
+
+// Option 测试缓存选项
+type Option func(*Cache)
+
+// WithClock 使用指定的时钟，便于与其他组件共享同一时钟
+func WithClock(clock *go_cache.FakeClock) Option {
+	return func(c *Cache) {
+		c.clock = clock
+	}
+}
+
+// WithSerializer 写入时使用序列化器编码，读取时解码
+// 用于在测试中覆盖与Redis相同的序列化往返行为，默认直接保存原始值
+func WithSerializer(s serializer.Serializer) Option {
+	return func(c *Cache) {
+		c.serializer = s
+	}
+}
+
+// Cache 确定性测试缓存，并发安全
+type Cache struct {
+	clock      *go_cache.FakeClock
+	serializer serializer.Serializer
+
+	mu      sync.Mutex
+	entries map[string]Entry
+	calls   []Call
+	errs    map[errorKey]error
+}
+
+// errorKey 注入错误的匹配条件，key为空表示匹配所有键
+type errorKey struct {
+	op  go_cache.Operation
+	key string
+}
+
+// New 创建测试缓存，默认时钟从当前时间开始且只能手动推进
+func New(opts ...Option) *Cache {
+	c := &Cache{
+		entries: make(map[string]Entry),
+		errs:    make(map[errorKey]error),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.clock == nil {
+		c.clock = go_cache.NewFakeClock(time.Time{})
+	}
+
+	return c
+}
+
+// Clock 返回缓存使用的时钟
+func (c *Cache) Clock() *go_cache.FakeClock {
+	return c.clock
+}
+
+// Advance 推进时钟，过期的条目立即不可见
+func (c *Cache) Advance(d time.Duration) {
+	c.clock.Advance(d)
+}
+
+// Seed 直接写入条目，不记录操作，用于准备测试数据
+func (c *Cache) Seed(key string, value any, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.storeLocked(key, value, ttl)
+}
+
+// InjectError 使后续匹配的操作返回err，key为空时匹配所有键，err为nil时取消注入
+func (c *Cache) InjectError(op go_cache.Operation, key string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	k := errorKey{op: op, key: key}
+	if err == nil {
+		delete(c.errs, k)
+		return
+	}
+	c.errs[k] = err
+}
+
+// Calls 返回按发生顺序记录的所有操作
+func (c *Cache) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Call(nil), c.calls...)
+}
+
+// CallCount 返回对key执行op的次数，op为空时统计所有操作
+func (c *Cache) CallCount(op go_cache.Operation, key string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	n := 0
+	for _, call := range c.calls {
+		if (op == "" || call.Op == op) && call.Key == key {
+			n++
+		}
+	}
+	return n
+}
+
+// ResetCalls 清空操作记录，不影响缓存内容
+func (c *Cache) ResetCalls() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = nil
+}
+
+// Snapshot 返回所有未过期条目的副本
+func (c *Cache) Snapshot() map[string]Entry {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	snapshot := make(map[string]Entry, len(c.entries))
+	for key, entry := range c.entries {
+		if !expired(entry, now) {
+			snapshot[key] = entry
+		}
+	}
+	return snapshot
+}
+
+// SortedKeys 返回所有未过期的键，按字典序排列
+func (c *Cache) SortedKeys() []string {
+	snapshot := c.Snapshot()
+	keys := make([]string, 0, len(snapshot))
+	for key := range snapshot {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Cache) Exists(ctx context.Context, key string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.injectedLocked(go_cache.OpExists, key); err != nil {
+		c.recordLocked(Call{Op: go_cache.OpExists, Key: key, Err: err})
+		return false
+	}
+	_, ok := c.lookupLocked(key)
+	c.recordLocked(Call{Op: go_cache.OpExists, Key: key, Hit: ok})
+	return ok
+}
+
+func (c *Cache) Get(ctx context.Context, key string, obj any) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.getLocked(key, obj)
+	c.recordLocked(Call{Op: go_cache.OpGet, Key: key, Hit: err == nil, Err: err})
+	return err
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.injectedLocked(go_cache.OpSet, key)
+	if err == nil {
+		err = c.storeLocked(key, value, ttl)
+	}
+	c.recordLocked(Call{Op: go_cache.OpSet, Key: key, TTL: ttl, Err: err, Value: value})
+	return err
+}
+
+func (c *Cache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	c.mu.Lock()
+	if err := c.injectedLocked(go_cache.OpGetSet, key); err != nil {
+		c.recordLocked(Call{Op: go_cache.OpGetSet, Key: key, TTL: ttl, Err: err})
+		c.mu.Unlock()
+		return err
+	}
+	if err := c.getLocked(key, obj); err == nil {
+		c.recordLocked(Call{Op: go_cache.OpGetSet, Key: key, TTL: ttl, Hit: true})
+		c.mu.Unlock()
+		return nil
+	}
+	c.mu.Unlock()
+
+	// 回调在锁外执行，允许回调中访问缓存
+	err := fun(key, obj)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err == nil {
+		objValue := reflect.ValueOf(obj)
+		if objValue.Kind() == reflect.Ptr {
+			objValue = objValue.Elem()
+		}
+		err = c.storeLocked(key, objValue.Interface(), ttl)
+	}
+	c.recordLocked(Call{Op: go_cache.OpGetSet, Key: key, TTL: ttl, Err: err})
+	return err
+}
+
+func (c *Cache) Del(ctx context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.injectedLocked(go_cache.OpDel, key)
+	if err == nil {
+		delete(c.entries, key)
+	}
+	c.recordLocked(Call{Op: go_cache.OpDel, Key: key, Err: err})
+	return err
+}
+
+func (c *Cache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return c.ExpiresIn(ctx, key, expiresAt.Sub(c.clock.Now()))
+}
+
+func (c *Cache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	err := c.injectedLocked(go_cache.OpExpire, key)
+	if err == nil {
+		entry, ok := c.lookupLocked(key)
+		switch {
+		case !ok:
+			err = ErrNotFound
+		case ttl <= 0:
+			delete(c.entries, key)
+		default:
+			entry.ExpiresAt = c.clock.Now().Add(ttl)
+			c.entries[key] = entry
+		}
+	}
+	c.recordLocked(Call{Op: go_cache.OpExpire, Key: key, TTL: ttl, Err: err})
+	return err
+}
+
+// Keys 返回匹配模式的所有未过期的键，按字典序排列
+// 遵守 go_cache.WithScanOptions 的 MaxKeys，超出时返回前 MaxKeys 个键与 ErrScanLimitReached
+func (c *Cache) Keys(ctx context.Context, pattern string) ([]string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.injectedLocked(go_cache.OpKeys, pattern); err != nil {
+		c.recordLocked(Call{Op: go_cache.OpKeys, Key: pattern, Err: err})
+		return nil, err
+	}
+	keys, err := limitKeys(ctx, c.keysLocked(pattern))
+	c.recordLocked(Call{Op: go_cache.OpKeys, Key: pattern, Err: err})
+	return keys, err
+}
+
+// DelByPattern 删除匹配模式的所有键，返回删除的数量
+// 遵守 go_cache.WithScanOptions 的 MaxKeys 与 DryRun
+func (c *Cache) DelByPattern(ctx context.Context, pattern string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.injectedLocked(go_cache.OpDelByPattern, pattern); err != nil {
+		c.recordLocked(Call{Op: go_cache.OpDelByPattern, Key: pattern, Err: err})
+		return 0, err
+	}
+	keys, err := limitKeys(ctx, c.keysLocked(pattern))
+	if !go_cache.ScanOptionsFromContext(ctx).DryRun {
+		for _, key := range keys {
+			delete(c.entries, key)
+		}
+	}
+	c.recordLocked(Call{Op: go_cache.OpDelByPattern, Key: pattern, Err: err})
+	return int64(len(keys)), err
+}
+
+// limitKeys 按context中的 MaxKeys 截断键
+func limitKeys(ctx context.Context, keys []string) ([]string, error) {
+	maxKeys := go_cache.ScanOptionsFromContext(ctx).MaxKeys
+	if maxKeys <= 0 || len(keys) <= maxKeys {
+		return keys, nil
+	}
+	return keys[:maxKeys], fmt.Errorf("%w: more than %d keys", go_cache.ErrScanLimitReached, maxKeys)
+}
+
+func (c *Cache) keysLocked(pattern string) []string {
+	now := c.clock.Now()
+	var keys []string
+	for key, entry := range c.entries {
+		if !expired(entry, now) && go_cache.MatchPattern(pattern, key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (c *Cache) getLocked(key string, obj any) error {
+	if err := c.injectedLocked(go_cache.OpGet, key); err != nil {
+		return err
+	}
+	entry, ok := c.lookupLocked(key)
+	if !ok {
+		return ErrNotFound
+	}
+	if c.serializer != nil {
+		return c.serializer.Decode(entry.Value.([]byte), obj)
+	}
+	return assign(obj, entry.Value)
+}
+
+func (c *Cache) storeLocked(key string, value any, ttl time.Duration) error {
+	if c.serializer != nil {
+		data, err := c.serializer.Encode(value)
+		if err != nil {
+			return err
+		}
+		value = data
+	}
+
+	entry := Entry{Value: value}
+	if ttl > 0 {
+		entry.ExpiresAt = c.clock.Now().Add(ttl)
+	}
+	c.entries[key] = entry
+	return nil
+}
+
+func (c *Cache) lookupLocked(key string) (Entry, bool) {
+	entry, ok := c.entries[key]
+	if !ok {
+		return Entry{}, false
+	}
+	if expired(entry, c.clock.Now()) {
+		delete(c.entries, key)
+		return Entry{}, false
+	}
+	return entry, true
+}
+
+func (c *Cache) injectedLocked(op go_cache.Operation, key string) error {
+	if err, ok := c.errs[errorKey{op: op, key: key}]; ok {
+		return err
+	}
+	return c.errs[errorKey{op: op}]
+}
+
+func (c *Cache) recordLocked(call Call) {
+	c.calls = append(c.calls, call)
+}
+
+func expired(entry Entry, now time.Time) bool {
+	return !entry.ExpiresAt.IsZero() && !now.Before(entry.ExpiresAt)
+}
+
+// assign 将值赋给obj指向的对象，类型必须完全一致
+func assign(obj any, value any) error {
+	objValue := reflect.ValueOf(obj)
+	if obj == nil || objValue.Kind() != reflect.Ptr || objValue.IsNil() {
+		return fmt.Errorf("obj must be a non-nil pointer")
+	}
+
+	objElem := objValue.Elem()
+	if value == nil {
+		switch objElem.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Chan, reflect.Func, reflect.Interface:
+			objElem.Set(reflect.Zero(objElem.Type()))
+			return nil
+		}
+		return fmt.Errorf("cannot assign nil to non-pointer type %s", objElem.Type())
+	}
+
+	valueReflect := reflect.ValueOf(value)
+	if objElem.Type() != valueReflect.Type() {
+		return fmt.Errorf("type mismatch: expected %s, got %s", objElem.Type(), valueReflect.Type())
+	}
+	objElem.Set(valueReflect)
+	return nil
+}
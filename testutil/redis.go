@@ -0,0 +1,250 @@
+// Package testutil 提供测试中使用的Redis实例
+//
+// NewRedis 返回一个已连接、已清空的Redis缓存与清理函数，
+// 不再依赖本地运行的Redis：优先使用 REDIS_ADDR 指定的外部实例，
+// 其次通过 testcontainers-go 启动容器，Docker不可用时回退到进程内的 miniredis
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	go_cache "github.com/muleiwu/go-cache"
+	"github.com/redis/go-redis/v9"
+	"github.com/redis/go-redis/v9/maintnotifications"
+	"github.com/testcontainers/testcontainers-go"
+	tcredis "github.com/testcontainers/testcontainers-go/modules/redis"
+)
+
+// Backend Redis实例的来源
+type Backend string
+
+const (
+	// BackendAuto 按 REDIS_ADDR、容器、miniredis 的顺序选择
+	BackendAuto Backend = ""
+	// BackendExternal 使用 REDIS_ADDR 指定的外部实例（默认 localhost:6379）
+	BackendExternal Backend = "external"
+	// BackendContainer 使用 testcontainers-go 启动的容器
+	BackendContainer Backend = "container"
+	// BackendMiniredis 使用进程内的 miniredis
+	BackendMiniredis Backend = "miniredis"
+)
+
+// BackendEnv 指定Redis实例来源的环境变量，取值为 external、container 或 miniredis
+const BackendEnv = "GO_CACHE_TEST_REDIS"
+
+// DefaultImage 默认的Redis容器镜像
+const DefaultImage = "redis:7-alpine"
+
+// testDB 外部实例与容器使用的数据库编号，避免影响其他数据
+const testDB = 15
+
+// Option NewRedis 选项
+type Option func(*options)
+
+type options struct {
+	backend   Backend
+	image     string
+	cacheOpts []go_cache.RedisOption
+}
+
+// WithBackend 指定Redis实例来源，优先于 BackendEnv 环境变量
+func WithBackend(b Backend) Option {
+	return func(o *options) {
+		o.backend = b
+	}
+}
+
+// WithImage 设置容器镜像，默认 DefaultImage
+func WithImage(image string) Option {
+	return func(o *options) {
+		o.image = image
+	}
+}
+
+// WithCacheOptions 设置创建 go_cache.Redis 时使用的选项
+func WithCacheOptions(opts ...go_cache.RedisOption) Option {
+	return func(o *options) {
+		o.cacheOpts = append(o.cacheOpts, opts...)
+	}
+}
+
+// Redis 测试用Redis实例
+type Redis struct {
+	Cache   *go_cache.Redis
+	Client  *redis.Client
+	Backend Backend
+
+	// Server 仅在使用 miniredis 时不为nil
+	Server *miniredis.Miniredis
+}
+
+// FastForward 让过期时间前进d
+// miniredis 直接推进服务端时间，其他实例只能等待真实时间流逝
+func (r *Redis) FastForward(d time.Duration) {
+	if r.Server != nil {
+		r.Server.FastForward(d)
+		return
+	}
+	time.Sleep(d)
+}
+
+// NewRedis 创建测试用Redis实例，返回的清理函数也会通过 tb.Cleanup 自动调用
+// 外部实例与容器在同一进程内共享，每次调用都会清空测试数据库，因此不能在并行测试中使用；
+// miniredis 每次调用都会启动独立的实例
+func NewRedis(tb testing.TB, opts ...Option) (*Redis, func()) {
+	tb.Helper()
+
+	o := &options{image: DefaultImage}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.backend == BackendAuto {
+		o.backend = Backend(os.Getenv(BackendEnv))
+	}
+
+	var (
+		r       *Redis
+		cleanup func()
+		err     error
+	)
+	switch o.backend {
+	case BackendExternal:
+		r, cleanup, err = newExternal(externalAddr())
+	case BackendContainer:
+		r, cleanup, err = newContainer(o.image)
+	case BackendMiniredis:
+		r, cleanup, err = newMiniredis()
+	case BackendAuto:
+		if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+			r, cleanup, err = newExternal(addr)
+			break
+		}
+		if r, cleanup, err = newContainer(o.image); err != nil {
+			r, cleanup, err = newMiniredis()
+		}
+	default:
+		err = fmt.Errorf("unknown backend %q", o.backend)
+	}
+	if err != nil {
+		tb.Fatalf("testutil: start redis (%s): %v", o.backend, err)
+	}
+
+	// miniredis 默认开启兼容模式，调用方的选项可以覆盖
+	cacheOpts := o.cacheOpts
+	if r.Backend == BackendMiniredis {
+		cacheOpts = append([]go_cache.RedisOption{go_cache.WithRedisCompat(go_cache.CompatMiniredis)}, cacheOpts...)
+	}
+	r.Cache = go_cache.NewRedis(r.Client, cacheOpts...)
+
+	var once sync.Once
+	done := func() { once.Do(cleanup) }
+	tb.Cleanup(done)
+	return r, done
+}
+
+// externalAddr 返回外部实例地址
+func externalAddr() string {
+	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
+		return addr
+	}
+	return "localhost:6379"
+}
+
+// newExternal 连接外部实例并清空测试数据库
+func newExternal(addr string) (*Redis, func(), error) {
+	client := redis.NewClient(clientOptions(addr, testDB))
+	if err := client.FlushDB(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, nil, err
+	}
+	cleanup := func() {
+		client.FlushDB(context.Background())
+		client.Close()
+	}
+	return &Redis{Client: client, Backend: BackendExternal}, cleanup, nil
+}
+
+var (
+	containerOnce sync.Once
+	containerAddr string
+	containerErr  error
+)
+
+// newContainer 连接进程内共享的容器，首次调用时启动
+// 容器在进程退出后由 testcontainers 的回收器清理
+func newContainer(image string) (*Redis, func(), error) {
+	containerOnce.Do(func() {
+		containerAddr, containerErr = startContainer(image)
+	})
+	if containerErr != nil {
+		return nil, nil, containerErr
+	}
+
+	r, cleanup, err := newExternal(containerAddr)
+	if err != nil {
+		return nil, nil, err
+	}
+	r.Backend = BackendContainer
+	return r, cleanup, nil
+}
+
+// startContainer 启动Redis容器并返回地址
+// Docker不可用时 testcontainers 可能直接panic，这里统一转换为错误
+func startContainer(image string) (addr string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("testcontainers: %v", r)
+		}
+	}()
+
+	ctx := context.Background()
+	container, err := tcredis.Run(ctx, image)
+	if err != nil {
+		if container != nil {
+			_ = testcontainers.TerminateContainer(container)
+		}
+		return "", err
+	}
+
+	uri, err := container.ConnectionString(ctx)
+	if err != nil {
+		return "", err
+	}
+	opts, err := redis.ParseURL(uri)
+	if err != nil {
+		return "", err
+	}
+	return opts.Addr, nil
+}
+
+// newMiniredis 启动独立的 miniredis 实例
+func newMiniredis() (*Redis, func(), error) {
+	server, err := miniredis.Run()
+	if err != nil {
+		return nil, nil, err
+	}
+	client := redis.NewClient(clientOptions(server.Addr(), 0))
+	cleanup := func() {
+		client.Close()
+		server.Close()
+	}
+	return &Redis{Client: client, Backend: BackendMiniredis, Server: server}, cleanup, nil
+}
+
+// clientOptions 返回测试客户端选项
+// 关闭维护通知握手，避免不支持该命令的实例（如 miniredis）在每次连接时输出日志
+func clientOptions(addr string, db int) *redis.Options {
+	return &redis.Options{
+		Addr: addr,
+		DB:   db,
+		MaintNotificationsConfig: &maintnotifications.Config{
+			Mode: maintnotifications.ModeDisabled,
+		},
+	}
+}
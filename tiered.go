@@ -0,0 +1,450 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// sessionTokenKey 会话令牌的context键
+type sessionTokenKey struct{}
+
+// WithSessionToken 在context中设置会话令牌
+// 开启读己之写后，携带会话令牌的写入只对同一会话的读取生效
+func WithSessionToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, sessionTokenKey{}, token)
+}
+
+// sessionToken 返回context中的会话令牌
+func sessionToken(ctx context.Context) string {
+	token, _ := ctx.Value(sessionTokenKey{}).(string)
+	return token
+}
+
+// TieredOption 多级缓存选项
+type TieredOption func(*Tiered)
+
+// WithTieredL1TTL 设置L1的最长TTL，默认1分钟
+// 写入时L1使用该值与写入TTL中较小的一个，从L2回填L1时使用该值
+func WithTieredL1TTL(d time.Duration) TieredOption {
+	return func(t *Tiered) {
+		if d > 0 {
+			t.l1TTL = d
+		}
+	}
+}
+
+// WithTieredReadYourWrites 开启读己之写保证，d为本地固定的时长
+// 写入（包括删除）后的d时间内，同一进程的读取直接返回刚写入的值，
+// 不受L2复制延迟或失效通知延迟的影响；context携带会话令牌时只对同一会话生效。
+// d应略大于L2复制与失效通知的最大延迟，d <= 0 表示关闭
+func WithTieredReadYourWrites(d time.Duration) TieredOption {
+	return func(t *Tiered) {
+		t.pinTTL = d
+	}
+}
+
+// WithTieredClock 设置判断读己之写固定条目过期所用的时钟，默认使用系统时间
+func WithTieredClock(clock Clock) TieredOption {
+	return func(t *Tiered) {
+		if clock != nil {
+			t.clock = clock
+		}
+	}
+}
+
+// TieredBudget 调用方context剩余时间在L1、L2与回调函数之间的分配比例，各比例按总和归一化
+type TieredBudget struct {
+	L1     float64
+	L2     float64
+	Loader float64
+}
+
+// WithTieredBudget 按比例把调用方context的剩余时间分配给各层，避免一个慢的层耗尽整个请求的时间
+// 例如 TieredBudget{L1: 0.1, L2: 0.6, Loader: 0.3}：读取L1最多使用剩余时间的10%，
+// 读取L2截止到70%，超时视为未命中；GetSet 的回调函数使用剩余的时间，超时返回 ErrLoaderTimeout。
+// 前一层没有用完的时间顺延给后一层；context没有截止时间时不生效
+func WithTieredBudget(b TieredBudget) TieredOption {
+	return func(t *Tiered) {
+		if b.L1 >= 0 && b.L2 >= 0 && b.Loader >= 0 && b.L1+b.L2+b.Loader > 0 {
+			t.budget = &b
+		}
+	}
+}
+
+// WithTieredRaceReads 开启并行读取：同时读取L1与L2，使用先成功返回的结果并取消另一个读取
+// 适用于L1命中率较低、希望降低尾延迟的场景，代价是每次读取都会访问L2
+func WithTieredRaceReads() TieredOption {
+	return func(t *Tiered) {
+		t.race = true
+	}
+}
+
+// Tiered 两级缓存
+// 读取先查L1（通常为内存缓存），未命中时查L2（通常为Redis）并回填L1；
+// 写入与删除先作用于L2，成功后再作用于L1
+type Tiered struct {
+	l1    gsr.Cacher
+	l2    gsr.Cacher
+	l1TTL time.Duration
+	clock Clock
+
+	// pinTTL 大于0时开启读己之写
+	pinTTL time.Duration
+
+	budget *TieredBudget // 非nil时按比例分配context的剩余时间
+	race   bool          // 同时读取两级缓存
+
+	mu        sync.Mutex
+	pins      map[string]map[string]tieredPin // 键 -> 会话令牌 -> 固定条目，会话令牌为空表示对整个进程生效
+	lastSweep time.Time
+}
+
+// tieredPin 本地固定的最近一次写入
+type tieredPin struct {
+	value     any
+	deleted   bool
+	expiresAt time.Time
+}
+
+// NewTiered 创建两级缓存实例
+func NewTiered(l1, l2 gsr.Cacher, opts ...TieredOption) *Tiered {
+	t := &Tiered{
+		l1:    l1,
+		l2:    l2,
+		l1TTL: time.Minute,
+		clock: realClock{},
+		pins:  make(map[string]map[string]tieredPin),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// L1 返回一级缓存
+func (t *Tiered) L1() gsr.Cacher {
+	return t.l1
+}
+
+// L2 返回二级缓存
+func (t *Tiered) L2() gsr.Cacher {
+	return t.l2
+}
+
+func (t *Tiered) Exists(ctx context.Context, key string) bool {
+	if pin, ok := t.pinned(ctx, key); ok {
+		return !pin.deleted
+	}
+	return t.l1.Exists(ctx, key) || t.l2.Exists(ctx, key)
+}
+
+func (t *Tiered) Get(ctx context.Context, key string, obj any) error {
+	return t.get(ctx, key, obj, t.deadlines(ctx))
+}
+
+// get 按各层的截止时间读取
+func (t *Tiered) get(ctx context.Context, key string, obj any, d tieredDeadlines) error {
+	if noCache(ctx) {
+		return ErrCacheBypassed
+	}
+	if pin, ok := t.pinned(ctx, key); ok {
+		if pin.deleted {
+			return ErrKeyNotFound
+		}
+		// 类型不一致时（例如写入指针、读取值）回退到正常读取
+		if err := assignValue(obj, pin.value); err == nil {
+			return nil
+		}
+	}
+
+	if objValue := reflect.ValueOf(obj); t.race && objValue.Kind() == reflect.Ptr && !objValue.IsNil() {
+		return t.raceGet(ctx, key, objValue, d)
+	}
+
+	l1Ctx, cancel := d.layer(ctx, d.l1)
+	err := t.l1.Get(l1Ctx, key, obj)
+	cancel()
+	if err == nil {
+		return nil
+	}
+
+	l2Ctx, cancel := d.layer(ctx, d.l2)
+	err = t.l2.Get(l2Ctx, key, obj)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	t.backfill(ctx, key, obj)
+	return nil
+}
+
+// raceGet 同时读取两级缓存，使用先成功返回的结果并取消另一个读取
+// 两级缓存分别读取到临时对象，只有成功的结果会复制到obj
+func (t *Tiered) raceGet(ctx context.Context, key string, objValue reflect.Value, d tieredDeadlines) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		l1  bool
+		tmp reflect.Value
+		err error
+	}
+	results := make(chan result, 2)
+	read := func(cache gsr.Cacher, deadline time.Time, l1 bool) {
+		layerCtx, cancel := d.layer(ctx, deadline)
+		defer cancel()
+		tmp := reflect.New(objValue.Elem().Type())
+		results <- result{l1: l1, tmp: tmp, err: cache.Get(layerCtx, key, tmp.Interface())}
+	}
+	go read(t.l1, d.l1, true)
+	go read(t.l2, d.l2, false)
+
+	var err error
+	for range 2 {
+		r := <-results
+		if r.err == nil {
+			cancel()
+			objValue.Elem().Set(r.tmp.Elem())
+			if !r.l1 {
+				t.backfill(context.WithoutCancel(ctx), key, objValue.Interface())
+			}
+			return nil
+		}
+		// 两级都未命中时返回L2的错误，与顺序读取一致
+		if !r.l1 || err == nil {
+			err = r.err
+		}
+	}
+	return err
+}
+
+// backfill 回填L1，失败不影响本次读取
+func (t *Tiered) backfill(ctx context.Context, key string, obj any) {
+	if value, err := pointee(obj); err == nil {
+		_ = t.l1.Set(ctx, key, value, t.l1TTL)
+	}
+}
+
+func (t *Tiered) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	if noStore(ctx) {
+		return nil
+	}
+	if err := t.l2.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	t.pin(ctx, key, tieredPin{value: value}, ttl)
+	return t.l1.Set(ctx, key, value, t.l1TTLFor(ttl))
+}
+
+// SetWithPriority 按优先级写入缓存，两级缓存实现了 PrioritySetter 时会透传优先级
+func (t *Tiered) SetWithPriority(ctx context.Context, key string, value any, ttl time.Duration, priority Priority) error {
+	if noStore(ctx) {
+		return nil
+	}
+	if err := setWithPriority(ctx, t.l2, key, value, ttl, priority); err != nil {
+		return err
+	}
+	t.pin(ctx, key, tieredPin{value: value}, ttl)
+	return setWithPriority(ctx, t.l1, key, value, t.l1TTLFor(ttl), priority)
+}
+
+func (t *Tiered) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	d := t.deadlines(ctx)
+
+	// 先尝试从缓存获取，WithForceRefresh 时直接调用回调函数
+	if !forceRefresh(ctx) && t.get(ctx, key, obj, d) == nil {
+		return nil
+	}
+
+	// 缓存未命中，调用回调函数；分配了时间时等待到调用方的截止时间
+	if d.ok {
+		timeout := max(time.Until(d.loader), time.Nanosecond)
+		err := (loaderConfig{timeout: timeout}).call(ctx, key, obj, fun)
+		if errors.Is(err, context.DeadlineExceeded) {
+			// 超时与ctx到期同时发生，统一返回 ErrLoaderTimeout
+			return fmt.Errorf("%w: key %q after %v", ErrLoaderTimeout, key, timeout)
+		}
+		if err != nil {
+			return err
+		}
+	} else if err := loaderOf(t.l2).call(ctx, key, obj, fun); err != nil {
+		return err
+	}
+
+	// 获取obj指向的实际值并存入缓存
+	value, err := pointee(obj)
+	if err != nil {
+		return err
+	}
+	return t.Set(ctx, key, value, ttl)
+}
+
+func (t *Tiered) Del(ctx context.Context, key string) error {
+	if err := t.l2.Del(ctx, key); err != nil {
+		return err
+	}
+	t.pin(ctx, key, tieredPin{deleted: true}, 0)
+	return t.l1.Del(ctx, key)
+}
+
+func (t *Tiered) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	if err := t.l2.ExpiresAt(ctx, key, expiresAt); err != nil {
+		return err
+	}
+	// 固定的值不再反映新的过期时间，交由两级缓存自身处理
+	t.unpin(key)
+	_ = t.l1.ExpiresAt(ctx, key, expiresAt)
+	return nil
+}
+
+func (t *Tiered) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	if err := t.l2.ExpiresIn(ctx, key, ttl); err != nil {
+		return err
+	}
+	t.unpin(key)
+	if ttl <= 0 {
+		// ttl不大于0时的语义由L2决定（默认有效期或永不过期），L1中删除键，下次读取时从L2回填
+		_ = t.l1.Del(ctx, key)
+		return nil
+	}
+	_ = t.l1.ExpiresIn(ctx, key, min(ttl, t.l1TTL))
+	return nil
+}
+
+// tieredDeadlines 一次操作中各层的截止时间，ok 为false时不限制
+type tieredDeadlines struct {
+	ok     bool
+	l1     time.Time
+	l2     time.Time
+	loader time.Time
+}
+
+// deadlines 按分配比例计算各层的截止时间
+func (t *Tiered) deadlines(ctx context.Context) tieredDeadlines {
+	deadline, ok := ctx.Deadline()
+	if t.budget == nil || !ok {
+		return tieredDeadlines{}
+	}
+	b := t.budget
+	now := time.Now()
+	remaining := float64(deadline.Sub(now))
+	total := b.L1 + b.L2 + b.Loader
+	return tieredDeadlines{
+		ok:     true,
+		l1:     now.Add(time.Duration(remaining * b.L1 / total)),
+		l2:     now.Add(time.Duration(remaining * (b.L1 + b.L2) / total)),
+		loader: deadline,
+	}
+}
+
+// layer 返回截止时间为deadline的context，不限制时返回ctx本身
+func (d tieredDeadlines) layer(ctx context.Context, deadline time.Time) (context.Context, context.CancelFunc) {
+	if !d.ok {
+		return ctx, func() {}
+	}
+	return context.WithDeadline(ctx, deadline)
+}
+
+// l1TTLFor 返回写入L1时使用的TTL
+func (t *Tiered) l1TTLFor(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		return t.l1TTL
+	}
+	return min(ttl, t.l1TTL)
+}
+
+// pin 记录本地固定条目，固定时长不超过写入的TTL（ttl <= 0 表示不限制）
+func (t *Tiered) pin(ctx context.Context, key string, p tieredPin, ttl time.Duration) {
+	if t.pinTTL <= 0 {
+		return
+	}
+
+	now := t.clock.Now()
+	p.expiresAt = now.Add(t.pinTTL)
+	if ttl > 0 && ttl < t.pinTTL {
+		p.expiresAt = now.Add(ttl)
+	}
+	session := sessionToken(ctx)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// 进程级固定条目覆盖同一键的所有会话级固定条目
+	sessions := t.pins[key]
+	if sessions == nil || session == "" {
+		sessions = make(map[string]tieredPin, 1)
+		t.pins[key] = sessions
+	}
+	// 会话内的写入同样更新已有的其他固定条目（包括进程级条目），
+	// 否则没有会话或其他会话的读取会继续得到本进程更早写入的旧值
+	for s := range sessions {
+		sessions[s] = p
+	}
+	sessions[session] = p
+
+	// 每个固定时长清理一次过期的固定条目
+	if now.Sub(t.lastSweep) >= t.pinTTL {
+		for k, sessions := range t.pins {
+			for s, v := range sessions {
+				if !now.Before(v.expiresAt) {
+					delete(sessions, s)
+				}
+			}
+			if len(sessions) == 0 {
+				delete(t.pins, k)
+			}
+		}
+		t.lastSweep = now
+	}
+}
+
+// pinned 返回对当前context可见的固定条目，会话级条目优先于进程级条目
+func (t *Tiered) pinned(ctx context.Context, key string) (tieredPin, bool) {
+	if t.pinTTL <= 0 {
+		return tieredPin{}, false
+	}
+
+	now := t.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sessions := t.pins[key]
+	if session := sessionToken(ctx); session != "" {
+		if p, ok := sessions[session]; ok && now.Before(p.expiresAt) {
+			return p, true
+		}
+	}
+	if p, ok := sessions[""]; ok && now.Before(p.expiresAt) {
+		return p, true
+	}
+	return tieredPin{}, false
+}
+
+// unpin 移除键的所有固定条目
+func (t *Tiered) unpin(key string) {
+	if t.pinTTL <= 0 {
+		return
+	}
+
+	t.mu.Lock()
+	delete(t.pins, key)
+	t.mu.Unlock()
+}
+
+// setWithPriority 缓存实现了 PrioritySetter 时按优先级写入，否则普通写入
+func setWithPriority(ctx context.Context, cache gsr.Cacher, key string, value any, ttl time.Duration, priority Priority) error {
+	if ps, ok := cache.(PrioritySetter); ok {
+		return ps.SetWithPriority(ctx, key, value, ttl, priority)
+	}
+	return cache.Set(ctx, key, value, ttl)
+}
@@ -0,0 +1,239 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// DefaultTimeSeriesKeyPrefix 时间序列在缓存中的默认键前缀
+const DefaultTimeSeriesKeyPrefix = "ts:"
+
+// maxTimeSeriesBuckets 一次查询最多读取的桶数
+const maxTimeSeriesBuckets = 100000
+
+// ErrInvalidSample 写入的值是NaN或无穷大
+var ErrInvalidSample = errors.New("timeseries: invalid sample")
+
+// TimeSeriesPoint 查询结果中一个步长内的聚合值
+type TimeSeriesPoint struct {
+	Time  time.Time // 步长的开始时间
+	Count int64
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+// Avg 返回平均值
+func (p TimeSeriesPoint) Avg() float64 {
+	if p.Count == 0 {
+		return 0
+	}
+	return p.Sum / float64(p.Count)
+}
+
+// timeSeriesBucket 缓存中保存的一个桶的聚合值
+type timeSeriesBucket struct {
+	Count int64
+	Sum   float64
+	Min   float64
+	Max   float64
+}
+
+// add 合并另一个桶
+func (b *timeSeriesBucket) add(other timeSeriesBucket) {
+	if other.Count == 0 {
+		return
+	}
+	if b.Count == 0 {
+		*b = other
+		return
+	}
+	b.Count += other.Count
+	b.Sum += other.Sum
+	b.Min = math.Min(b.Min, other.Min)
+	b.Max = math.Max(b.Max, other.Max)
+}
+
+// timeSeriesBackend 由能够在服务端原子聚合时间序列的缓存实现（如Redis使用哈希与Lua脚本）
+type timeSeriesBackend interface {
+	recordTimeSeries(ctx context.Context, key string, value float64, ttl time.Duration) error
+	loadTimeSeries(ctx context.Context, keys []string) ([]timeSeriesBucket, error)
+}
+
+// TimeSeriesOption 时间序列选项
+type TimeSeriesOption func(*TimeSeries)
+
+// WithTimeSeriesResolution 设置桶的时长，即保存的最小粒度，默认1分钟
+func WithTimeSeriesResolution(d time.Duration) TimeSeriesOption {
+	return func(ts *TimeSeries) {
+		if d >= time.Millisecond {
+			ts.resolution = d
+		}
+	}
+}
+
+// WithTimeSeriesRetention 设置保留时长，默认24小时
+// 每个桶在其结束后保留该时长，之后由缓存的过期自动清理
+func WithTimeSeriesRetention(d time.Duration) TimeSeriesOption {
+	return func(ts *TimeSeries) {
+		if d > 0 {
+			ts.retention = d
+		}
+	}
+}
+
+// WithTimeSeriesKeyPrefix 设置时间序列在缓存中的键前缀，默认 DefaultTimeSeriesKeyPrefix
+func WithTimeSeriesKeyPrefix(prefix string) TimeSeriesOption {
+	return func(ts *TimeSeries) {
+		ts.prefix = prefix
+	}
+}
+
+// WithTimeSeriesClock 设置 Record 使用的时钟，默认使用系统时间
+func WithTimeSeriesClock(clock Clock) TimeSeriesOption {
+	return func(ts *TimeSeries) {
+		if clock != nil {
+			ts.clock = clock
+		}
+	}
+}
+
+// TimeSeries 基于缓存的轻量时间序列，用于少量运维指标而不需要部署时序数据库
+// 写入的值按分辨率聚合到桶中（次数、总和、最小值、最大值），每个桶保存为一个带过期时间的键，
+// 超过保留时长后自动过期；查询时按步长降采样。
+// Redis缓存在服务端用Lua脚本原子聚合，实现 TxnCache 的缓存在事务中执行，
+// 其他缓存只在本实例内串行
+type TimeSeries struct {
+	cache      gsr.Cacher
+	resolution time.Duration
+	retention  time.Duration
+	prefix     string
+	clock      Clock
+
+	mu sync.Mutex // 底层缓存不支持事务时保护桶的读-改-写
+}
+
+// NewTimeSeries 创建时间序列
+func NewTimeSeries(cache gsr.Cacher, opts ...TimeSeriesOption) *TimeSeries {
+	ts := &TimeSeries{
+		cache:      cache,
+		resolution: time.Minute,
+		retention:  24 * time.Hour,
+		prefix:     DefaultTimeSeriesKeyPrefix,
+		clock:      realClock{},
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(ts)
+	}
+
+	return ts
+}
+
+// Record 在当前时间向series写入value
+func (ts *TimeSeries) Record(ctx context.Context, series string, value float64) error {
+	return ts.RecordAt(ctx, series, value, ts.clock.Now())
+}
+
+// RecordAt 在时间t向series写入value，t 所在的桶已超过保留时长时忽略
+// value 为NaN或无穷大时返回 ErrInvalidSample
+func (ts *TimeSeries) RecordAt(ctx context.Context, series string, value float64, t time.Time) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("%w: %v", ErrInvalidSample, value)
+	}
+	start := t.Truncate(ts.resolution)
+	ttl := start.Add(ts.resolution + ts.retention).Sub(ts.clock.Now())
+	if ttl <= 0 {
+		return nil
+	}
+	key := ts.bucketKey(series, start)
+
+	if b, ok := ts.cache.(timeSeriesBackend); ok {
+		return b.recordTimeSeries(ctx, key, value, ttl)
+	}
+	return update(ctx, ts.cache, &ts.mu, key, func(get func(context.Context, string, any) error) (any, time.Duration, error) {
+		var bucket timeSeriesBucket
+		if err := get(ctx, key, &bucket); err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return nil, 0, err
+		}
+		bucket.add(timeSeriesBucket{Count: 1, Sum: value, Min: value, Max: value})
+		return bucket, ttl, nil
+	})
+}
+
+// Query 返回series在[from, to)内按step降采样的聚合值，只包含有数据的步长，按时间排序
+// step 小于分辨率时使用分辨率，否则向上取整为分辨率的整数倍；步长的开始时间按step对齐
+func (ts *TimeSeries) Query(ctx context.Context, series string, from, to time.Time, step time.Duration) ([]TimeSeriesPoint, error) {
+	step = max(step, ts.resolution)
+	if rem := step % ts.resolution; rem != 0 {
+		step += ts.resolution - rem
+	}
+
+	first := from.Truncate(ts.resolution)
+	if first.Before(from) {
+		first = first.Add(ts.resolution)
+	}
+	n := 0
+	if to.After(first) {
+		n = int((to.Sub(first) + ts.resolution - 1) / ts.resolution)
+	}
+	if n > maxTimeSeriesBuckets {
+		return nil, fmt.Errorf("timeseries: query spans %d buckets, limit %d", n, maxTimeSeriesBuckets)
+	}
+
+	starts := make([]time.Time, n)
+	keys := make([]string, n)
+	for i := range starts {
+		starts[i] = first.Add(time.Duration(i) * ts.resolution)
+		keys[i] = ts.bucketKey(series, starts[i])
+	}
+	buckets, err := ts.load(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []TimeSeriesPoint
+	var aggs []timeSeriesBucket
+	for i, bucket := range buckets {
+		if bucket.Count == 0 {
+			continue
+		}
+		at := starts[i].Truncate(step)
+		if len(points) == 0 || !points[len(points)-1].Time.Equal(at) {
+			points = append(points, TimeSeriesPoint{Time: at})
+			aggs = append(aggs, timeSeriesBucket{})
+		}
+		aggs[len(aggs)-1].add(bucket)
+	}
+	for i, agg := range aggs {
+		points[i].Count, points[i].Sum, points[i].Min, points[i].Max = agg.Count, agg.Sum, agg.Min, agg.Max
+	}
+	return points, nil
+}
+
+// load 读取多个桶，不存在的桶为零值
+func (ts *TimeSeries) load(ctx context.Context, keys []string) ([]timeSeriesBucket, error) {
+	if b, ok := ts.cache.(timeSeriesBackend); ok {
+		return b.loadTimeSeries(ctx, keys)
+	}
+	buckets := make([]timeSeriesBucket, len(keys))
+	for i, key := range keys {
+		if err := ts.cache.Get(ctx, key, &buckets[i]); err != nil && !errors.Is(err, ErrKeyNotFound) {
+			return nil, err
+		}
+	}
+	return buckets, nil
+}
+
+// bucketKey 返回series在start开始的桶的键
+func (ts *TimeSeries) bucketKey(series string, start time.Time) string {
+	return ts.prefix + series + ":" + strconv.FormatInt(start.UnixMilli(), 10)
+}
@@ -0,0 +1,207 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ErrInvalidToken 获取令牌的函数返回了已过期或没有过期时间的令牌
+var ErrInvalidToken = errors.New("invalid token")
+
+// Token 访问令牌
+type Token struct {
+	AccessToken string
+	TokenType   string // 如 "Bearer"
+	ExpiresAt   time.Time
+}
+
+// TokenFetcher 向授权服务器获取client在scopes范围内的访问令牌，如 OAuth2 的 client_credentials 流程
+type TokenFetcher func(ctx context.Context, client string, scopes []string) (Token, error)
+
+// TokenRefreshHook 后台提前刷新失败时调用，缓存中的令牌在过期前仍会被使用
+type TokenRefreshHook func(client string, scopes []string, err error)
+
+// TokenCacheOption 令牌缓存选项
+type TokenCacheOption func(*TokenCache)
+
+// WithTokenRefreshAhead 设置提前刷新的时间，默认1分钟：令牌剩余有效期不足d时返回缓存的令牌并在后台刷新
+func WithTokenRefreshAhead(d time.Duration) TokenCacheOption {
+	return func(t *TokenCache) {
+		if d >= 0 {
+			t.refreshAhead = d
+		}
+	}
+}
+
+// WithTokenPrefix 设置缓存键的前缀，默认 "token:"
+func WithTokenPrefix(prefix string) TokenCacheOption {
+	return func(t *TokenCache) {
+		t.prefix = prefix
+	}
+}
+
+// WithTokenClock 设置判断令牌过期所用的时钟，默认使用系统时间
+func WithTokenClock(clock Clock) TokenCacheOption {
+	return func(t *TokenCache) {
+		if clock != nil {
+			t.clock = clock
+		}
+	}
+}
+
+// WithTokenRefreshHook 设置后台提前刷新失败时的回调
+func WithTokenRefreshHook(hook TokenRefreshHook) TokenCacheOption {
+	return func(t *TokenCache) {
+		t.onRefreshError = hook
+	}
+}
+
+// tokenCall 正在进行的令牌获取，同一个键的并发请求等待同一次获取
+type tokenCall struct {
+	done  chan struct{}
+	token Token
+	err   error
+}
+
+// TokenCache 按（客户端，权限范围）缓存访问令牌
+// 令牌保存到过期为止；剩余有效期不足提前刷新时间时返回缓存的令牌并在后台获取新令牌，调用方不会等待授权服务器。
+// 同一进程内对同一个键的并发获取合并为一次；多个实例共享缓存时，一个实例获取的令牌可被其他实例使用
+type TokenCache struct {
+	cache          gsr.Cacher
+	fetch          TokenFetcher
+	refreshAhead   time.Duration
+	prefix         string
+	clock          Clock
+	onRefreshError TokenRefreshHook
+
+	mu       sync.Mutex
+	inflight map[string]*tokenCall
+}
+
+// NewTokenCache 创建令牌缓存，fetch 用于获取新令牌
+func NewTokenCache(cache gsr.Cacher, fetch TokenFetcher, opts ...TokenCacheOption) *TokenCache {
+	t := &TokenCache{
+		cache:        cache,
+		fetch:        fetch,
+		refreshAhead: time.Minute,
+		prefix:       "token:",
+		clock:        realClock{},
+		inflight:     make(map[string]*tokenCall),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(t)
+	}
+
+	return t
+}
+
+// key 返回令牌的缓存键，权限范围的顺序不影响结果
+// 每个部分以长度开头，客户端或权限范围中的分隔符不会使不同的组合得到同一个键
+func (t *TokenCache) key(client string, scopes []string) string {
+	sorted := append([]string(nil), scopes...)
+	sort.Strings(sorted)
+
+	var b strings.Builder
+	b.WriteString(t.prefix)
+	for _, part := range append([]string{client}, sorted...) {
+		b.WriteString(strconv.Itoa(len(part)))
+		b.WriteByte(':')
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// Token 返回client在scopes范围内的有效令牌，缓存中没有或已过期时获取新令牌
+// 获取令牌的函数发生panic时返回 *LoaderPanicError
+func (t *TokenCache) Token(ctx context.Context, client string, scopes ...string) (Token, error) {
+	key := t.key(client, scopes)
+	var token Token
+	if err := t.cache.Get(ctx, key, &token); err == nil {
+		now := t.clock.Now()
+		if now.Before(token.ExpiresAt) {
+			if !now.Before(token.ExpiresAt.Add(-t.refreshAhead)) {
+				t.refreshAsync(ctx, key, client, scopes)
+			}
+			return token, nil
+		}
+	}
+	return t.acquire(ctx, key, client, scopes)
+}
+
+// Invalidate 删除缓存的令牌，用于接口返回401等令牌已失效的情况
+func (t *TokenCache) Invalidate(ctx context.Context, client string, scopes ...string) error {
+	return t.cache.Del(ctx, t.key(client, scopes))
+}
+
+// refreshAsync 在后台获取新令牌，已有同一个键的获取在进行时不重复获取
+func (t *TokenCache) refreshAsync(ctx context.Context, key, client string, scopes []string) {
+	t.mu.Lock()
+	_, running := t.inflight[key]
+	t.mu.Unlock()
+	if running {
+		return
+	}
+	go func() {
+		if _, err := t.acquire(context.WithoutCancel(ctx), key, client, scopes); err != nil && t.onRefreshError != nil {
+			t.onRefreshError(client, scopes, err)
+		}
+	}()
+}
+
+// acquire 获取新令牌并写入缓存，并发的获取合并为一次
+func (t *TokenCache) acquire(ctx context.Context, key, client string, scopes []string) (Token, error) {
+	t.mu.Lock()
+	if call, ok := t.inflight[key]; ok {
+		t.mu.Unlock()
+		select {
+		case <-call.done:
+			return call.token, call.err
+		case <-ctx.Done():
+			return Token{}, ctx.Err()
+		}
+	}
+	call := &tokenCall{done: make(chan struct{})}
+	t.inflight[key] = call
+	t.mu.Unlock()
+
+	call.token, call.err = t.fetchAndStore(ctx, key, client, scopes)
+
+	t.mu.Lock()
+	delete(t.inflight, key)
+	t.mu.Unlock()
+	close(call.done)
+	return call.token, call.err
+}
+
+// fetchAndStore 获取令牌并保存到过期为止
+// fetch 发生panic时返回 *LoaderPanicError，等待同一次获取的调用方不会一直阻塞
+func (t *TokenCache) fetchAndStore(ctx context.Context, key, client string, scopes []string) (_ Token, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &LoaderPanicError{Key: key, Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	token, err := t.fetch(ctx, client, scopes)
+	if err != nil {
+		return Token{}, err
+	}
+	ttl := token.ExpiresAt.Sub(t.clock.Now())
+	if ttl <= 0 {
+		return Token{}, fmt.Errorf("%w: client %s expires at %v", ErrInvalidToken, client, token.ExpiresAt)
+	}
+	// 写入失败时仍返回令牌，下次调用重新获取
+	_ = t.cache.Set(ctx, key, token, ttl)
+	return token, nil
+}
@@ -0,0 +1,122 @@
+package go_cache
+
+import (
+	"context"
+	"math/rand/v2"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// TTLPolicy 一类键的有效期策略
+type TTLPolicy struct {
+	Default time.Duration // 调用方传入的 ttl <= 0 时使用的有效期，0 表示沿用调用方的ttl
+	Max     time.Duration // 有效期上限，超过时截断，0 表示不限制
+	Jitter  float64       // 随机缩短有效期的最大比例（0到1），如0.1表示在 [0.9*ttl, ttl] 内随机，避免同一批键同时过期
+	Sliding bool          // 读取命中时重新设置有效期为 Default（滑动过期）
+}
+
+// TTLPolicyCache 按键前缀统一决定有效期的缓存
+// 写入（Set、GetSet）时按最长匹配的前缀应用策略：ttl <= 0 时使用策略的 Default，
+// 再按 Max 截断并加上随机抖动；没有匹配的策略时ttl原样传给底层缓存。
+// 开启 Sliding 的键在 Get 与 GetSet 命中时重新设置有效期
+type TTLPolicyCache struct {
+	cache    gsr.Cacher
+	policies map[string]TTLPolicy
+}
+
+// NewTTLPolicyCache 创建按前缀应用有效期策略的缓存，policies 的键为键前缀
+func NewTTLPolicyCache(cache gsr.Cacher, policies map[string]TTLPolicy) *TTLPolicyCache {
+	copied := make(map[string]TTLPolicy, len(policies))
+	for prefix, policy := range policies {
+		copied[prefix] = policy
+	}
+	return &TTLPolicyCache{cache: cache, policies: copied}
+}
+
+// Policy 返回键适用的策略，多个前缀匹配时使用最长的前缀
+func (c *TTLPolicyCache) Policy(key string) (TTLPolicy, bool) {
+	var found TTLPolicy
+	longest, ok := -1, false
+	for prefix, policy := range c.policies {
+		if strings.HasPrefix(key, prefix) && len(prefix) > longest {
+			found, longest, ok = policy, len(prefix), true
+		}
+	}
+	return found, ok
+}
+
+// TTL 返回按策略写入键时使用的有效期，ttl 为调用方传入的有效期
+func (c *TTLPolicyCache) TTL(key string, ttl time.Duration) time.Duration {
+	policy, ok := c.Policy(key)
+	if !ok {
+		return ttl
+	}
+	return policy.apply(ttl)
+}
+
+// apply 按策略计算有效期
+func (p TTLPolicy) apply(ttl time.Duration) time.Duration {
+	if ttl <= 0 {
+		ttl = p.Default
+	}
+	if p.Max > 0 && (ttl <= 0 || ttl > p.Max) {
+		ttl = p.Max
+	}
+	if ttl > 0 && p.Jitter > 0 {
+		ttl -= time.Duration(float64(ttl) * min(p.Jitter, 1) * rand.Float64())
+	}
+	return ttl
+}
+
+// slide 滑动过期的键命中后重新设置有效期
+func (c *TTLPolicyCache) slide(ctx context.Context, key string) {
+	if policy, ok := c.Policy(key); ok && policy.Sliding && policy.Default > 0 {
+		_ = c.cache.ExpiresIn(ctx, key, policy.apply(0))
+	}
+}
+
+func (c *TTLPolicyCache) Exists(ctx context.Context, key string) bool {
+	return c.cache.Exists(ctx, key)
+}
+
+func (c *TTLPolicyCache) Get(ctx context.Context, key string, obj any) error {
+	// 底层缓存支持读取的同时刷新过期时间时合并为一次操作
+	if policy, ok := c.Policy(key); ok && policy.Sliding && policy.Default > 0 {
+		if touch, ok := c.cache.(GetTouchCache); ok {
+			return touch.GetTouch(ctx, key, policy.apply(0), obj)
+		}
+	}
+	if err := c.cache.Get(ctx, key, obj); err != nil {
+		return err
+	}
+	c.slide(ctx, key)
+	return nil
+}
+
+func (c *TTLPolicyCache) Set(ctx context.Context, key string, value any, ttl time.Duration) error {
+	return c.cache.Set(ctx, key, value, c.TTL(key, ttl))
+}
+
+func (c *TTLPolicyCache) GetSet(ctx context.Context, key string, ttl time.Duration, obj any, fun gsr.CacheCallback) error {
+	// 先尝试从缓存获取，命中时按策略滑动有效期；WithForceRefresh 时直接交给底层缓存
+	if !forceRefresh(ctx) && c.Get(ctx, key, obj) == nil {
+		// 缓存命中，直接返回
+		return nil
+	}
+	return c.cache.GetSet(ctx, key, c.TTL(key, ttl), obj, fun)
+}
+
+func (c *TTLPolicyCache) Del(ctx context.Context, key string) error {
+	return c.cache.Del(ctx, key)
+}
+
+func (c *TTLPolicyCache) ExpiresAt(ctx context.Context, key string, expiresAt time.Time) error {
+	return c.cache.ExpiresAt(ctx, key, expiresAt)
+}
+
+// ExpiresIn 按策略计算有效期后设置
+func (c *TTLPolicyCache) ExpiresIn(ctx context.Context, key string, ttl time.Duration) error {
+	return c.cache.ExpiresIn(ctx, key, c.TTL(key, ttl))
+}
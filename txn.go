@@ -0,0 +1,125 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// updateMaxRetries update 遇到事务冲突时的最大重试次数
+const updateMaxRetries = 10
+
+// ErrTxnConflict 事务提交前被监视的键发生了变化，事务中的写操作均未执行
+var ErrTxnConflict = errors.New("transaction aborted: watched key changed")
+
+// Txn 事务，写操作在事务函数返回后一起原子执行
+// 事务函数返回错误时所有写操作都会被丢弃
+type Txn interface {
+	// Get 读取键的当前值，读取不属于事务的一部分，配合监视键实现读-改-写
+	Get(ctx context.Context, key string, obj any) error
+	// Set 写入键，序列化失败时立即返回错误
+	Set(key string, value any, ttl time.Duration) error
+	// Del 删除键
+	Del(key string)
+	// ExpiresIn 设置键的剩余有效期，键不存在时忽略
+	ExpiresIn(key string, ttl time.Duration)
+}
+
+// TxnCache 支持事务的缓存
+type TxnCache interface {
+	// Txn 执行事务，watch 中的键在事务函数执行期间被修改时返回 ErrTxnConflict
+	Txn(ctx context.Context, fn func(tx Txn) error, watch ...string) error
+}
+
+// txnOp 事务中缓冲的写操作
+type txnOp struct {
+	kind  Operation
+	key   string
+	value any
+	ttl   time.Duration
+}
+
+// txnBuffer 缓冲写操作的事务实现
+type txnBuffer struct {
+	get    func(ctx context.Context, key string, obj any) error
+	encode func(key string, value any) (any, error)
+	ops    []txnOp
+}
+
+func (t *txnBuffer) Get(ctx context.Context, key string, obj any) error {
+	return t.get(ctx, key, obj)
+}
+
+func (t *txnBuffer) Set(key string, value any, ttl time.Duration) error {
+	if t.encode != nil {
+		encoded, err := t.encode(key, value)
+		if err != nil {
+			return err
+		}
+		value = encoded
+	}
+	t.ops = append(t.ops, txnOp{kind: OpSet, key: key, value: value, ttl: ttl})
+	return nil
+}
+
+func (t *txnBuffer) Del(key string) {
+	t.ops = append(t.ops, txnOp{kind: OpDel, key: key})
+}
+
+func (t *txnBuffer) ExpiresIn(key string, ttl time.Duration) {
+	t.ops = append(t.ops, txnOp{kind: OpExpire, key: key, ttl: ttl})
+}
+
+// errNoUpdate updateFunc 返回该错误表示键不需要修改，update 返回nil
+var errNoUpdate = errors.New("no update")
+
+// updateFunc 根据读取函数计算键的新值，value 为nil时删除键，ttl <= 0 表示不过期
+type updateFunc func(get func(ctx context.Context, key string, obj any) error) (value any, ttl time.Duration, err error)
+
+// update 对key执行读-改-写
+// 缓存实现 TxnCache 时在监视key的事务中执行，冲突时重试 updateMaxRetries 次后返回 ErrTxnConflict；
+// 否则持有mu执行，只在本进程内串行。fn 可能被调用多次，应只通过返回值产生结果
+func update(ctx context.Context, cache gsr.Cacher, mu *sync.Mutex, key string, fn updateFunc) error {
+	tc, ok := cache.(TxnCache)
+	if !ok {
+		mu.Lock()
+		defer mu.Unlock()
+
+		value, ttl, err := fn(cache.Get)
+		if err != nil {
+			return ignoreNoUpdate(err)
+		}
+		if value == nil {
+			return cache.Del(ctx, key)
+		}
+		return cache.Set(ctx, key, value, ttl)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := tc.Txn(ctx, func(tx Txn) error {
+			value, ttl, err := fn(tx.Get)
+			if err != nil {
+				return err
+			}
+			if value == nil {
+				tx.Del(key)
+				return nil
+			}
+			return tx.Set(key, value, ttl)
+		}, key)
+		if !errors.Is(err, ErrTxnConflict) || attempt+1 >= updateMaxRetries {
+			return ignoreNoUpdate(err)
+		}
+	}
+}
+
+// ignoreNoUpdate 将 errNoUpdate 转换为nil
+func ignoreNoUpdate(err error) error {
+	if errors.Is(err, errNoUpdate) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,133 @@
+package go_cache
+
+import (
+	"context"
+	"hash/fnv"
+	"maps"
+	"math"
+	"math/bits"
+	"slices"
+)
+
+// UniqueCache 使用HyperLogLog近似统计不重复元素数的缓存，用于统计独立访客等
+// 写入的键没有过期时间，需要时使用 ExpiresIn 设置
+type UniqueCache interface {
+	// UniqueAdd 将ids加入key的HyperLogLog，返回估计值是否变化；键不存在时创建
+	UniqueAdd(ctx context.Context, key string, ids ...string) (bool, error)
+	// UniqueCount 返回keys并集中不重复元素数的估计值，不存在的键视为空集合
+	UniqueCount(ctx context.Context, keys ...string) (int64, error)
+}
+
+const (
+	hllPrecision = 14 // 与Redis相同，标准误差约0.81%
+	hllRegisters = 1 << hllPrecision
+	hllSparseMax = 256 // 稀疏表示最多保存的哈希数，超过后转换为寄存器
+)
+
+// hyperLogLog 内存缓存使用的HyperLogLog
+// 元素较少时保存元素的哈希（计数精确），超过 hllSparseMax 后转换为寄存器。
+// 保存在缓存中的值不会被修改，写入时复制后替换
+type hyperLogLog struct {
+	sparse    map[uint64]struct{}
+	registers []uint8
+}
+
+// hllHash 计算元素的64位哈希
+func hllHash(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	x := h.Sum64()
+	// FNV的低位分布不够均匀，使用MurmurHash3的finalizer混合
+	x ^= x >> 33
+	x *= 0xff51afd7ed558ccd
+	x ^= x >> 33
+	x *= 0xc4ceb9fe1a85ec53
+	x ^= x >> 33
+	return x
+}
+
+// newHyperLogLog 创建空的HyperLogLog
+func newHyperLogLog() *hyperLogLog {
+	return &hyperLogLog{sparse: make(map[uint64]struct{})}
+}
+
+// clone 复制HyperLogLog，h 为nil时返回空的HyperLogLog
+func (h *hyperLogLog) clone() *hyperLogLog {
+	if h == nil {
+		return newHyperLogLog()
+	}
+	return &hyperLogLog{sparse: maps.Clone(h.sparse), registers: slices.Clone(h.registers)}
+}
+
+// add 加入哈希，返回估计值是否变化
+func (h *hyperLogLog) add(x uint64) bool {
+	if h.registers == nil {
+		if _, ok := h.sparse[x]; ok {
+			return false
+		}
+		if len(h.sparse) < hllSparseMax {
+			h.sparse[x] = struct{}{}
+			return true
+		}
+		h.densify()
+	}
+	return h.addRegister(x)
+}
+
+// addRegister 更新哈希对应的寄存器
+func (h *hyperLogLog) addRegister(x uint64) bool {
+	idx := x >> (64 - hllPrecision)
+	rho := uint8(bits.LeadingZeros64(x<<hllPrecision|1<<(hllPrecision-1)) + 1)
+	if rho > h.registers[idx] {
+		h.registers[idx] = rho
+		return true
+	}
+	return false
+}
+
+// densify 将稀疏表示转换为寄存器
+func (h *hyperLogLog) densify() {
+	h.registers = make([]uint8, hllRegisters)
+	for x := range h.sparse {
+		h.addRegister(x)
+	}
+	h.sparse = nil
+}
+
+// merge 将other合并到h
+func (h *hyperLogLog) merge(other *hyperLogLog) {
+	if other.registers == nil {
+		for x := range other.sparse {
+			h.add(x)
+		}
+		return
+	}
+	if h.registers == nil {
+		h.densify()
+	}
+	for i, r := range other.registers {
+		h.registers[i] = max(h.registers[i], r)
+	}
+}
+
+// count 返回不重复元素数的估计值
+func (h *hyperLogLog) count() int64 {
+	if h.registers == nil {
+		return int64(len(h.sparse))
+	}
+
+	m := float64(hllRegisters)
+	sum, zeros := 0.0, 0
+	for _, r := range h.registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// 估计值较小时使用线性计数修正
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int64(math.Round(estimate))
+}
@@ -0,0 +1,203 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/muleiwu/go-cache/serializer"
+	"github.com/muleiwu/gsr"
+)
+
+// ErrSerializerMismatch 缓存中的值不是由当前配置的序列化器写入的，或写入的值读取后与原值不一致
+var ErrSerializerMismatch = errors.New("serializer mismatch")
+
+// DefaultVerifyCanaryKey Verify 默认使用的探测键前缀
+const DefaultVerifyCanaryKey = "go_cache:verify:canary"
+
+// SerializerMismatchError Verify 在键空间中发现的无法由当前序列化器读取的键
+type SerializerMismatchError struct {
+	Serializer string   // 当前配置的序列化器名称
+	Sampled    int      // 抽样检查的键数
+	Keys       []string // 不是由当前序列化器写入的键
+}
+
+func (e *SerializerMismatchError) Error() string {
+	keys := e.Keys
+	if len(keys) > 5 {
+		keys = keys[:5]
+	}
+	return fmt.Sprintf("%s: %d of %d sampled keys not written by %s serializer: %s",
+		ErrSerializerMismatch, len(e.Keys), e.Sampled, e.Serializer, strings.Join(keys, ", "))
+}
+
+// Unwrap 返回 ErrSerializerMismatch
+func (e *SerializerMismatchError) Unwrap() error {
+	return ErrSerializerMismatch
+}
+
+// verifyConfig 启动检查配置
+type verifyConfig struct {
+	canaryKey string
+	sample    int
+	pattern   string
+}
+
+// VerifyOption 启动检查选项
+type VerifyOption func(*verifyConfig)
+
+// WithVerifyCanaryKey 设置往返检查使用的键前缀，默认 DefaultVerifyCanaryKey，空字符串表示不做往返检查
+// 每次检查在前缀后追加随机后缀，多个实例同时启动时不会覆盖或删除其他实例的探测键
+func WithVerifyCanaryKey(key string) VerifyOption {
+	return func(c *verifyConfig) {
+		c.canaryKey = key
+	}
+}
+
+// WithVerifySample 设置在键空间中抽样检查的键数，默认100，n <= 0 表示不检查键空间
+func WithVerifySample(n int) VerifyOption {
+	return func(c *verifyConfig) {
+		c.sample = n
+	}
+}
+
+// WithVerifyPattern 设置抽样检查的键的模式，默认 "*"
+// 模式应只匹配通过 Set 写入的键；同一实例中计数器、位图等不经过序列化器的值也会被报告为不兼容
+func WithVerifyPattern(pattern string) VerifyOption {
+	return func(c *verifyConfig) {
+		c.pattern = pattern
+	}
+}
+
+// keyspaceVerifier 能够检查已有的值是否由当前序列化器写入的缓存
+type keyspaceVerifier interface {
+	// verifyKeyspace 抽样检查最多n个匹配模式的键，发现不兼容的值时返回 *SerializerMismatchError
+	verifyKeyspace(ctx context.Context, pattern string, n int) error
+}
+
+// verifyCanaryValue 往返检查写入的值，覆盖常见的字段类型
+type verifyCanaryValue struct {
+	Nonce int64
+	Text  string
+	Float float64
+	Time  time.Time
+	Bytes []byte
+	List  []string
+	Attrs map[string]int
+}
+
+// Verify 在启动时检查缓存的序列化器配置，出错时应用程序应拒绝启动，而不是在处理请求时才发现
+// 先写入并读取一个探测键，检查值经过序列化器（及键前缀，如 TenantCache）往返后保持不变；
+// 再对支持的后端（Redis 与基于其上的 TenantCache）抽样检查已有的值是否由当前序列化器写入，
+// 如部署时修改了序列化器或签名密钥而旧值仍在缓存中，此时返回 *SerializerMismatchError。
+// 后端不可用等错误直接返回
+func Verify(ctx context.Context, cache gsr.Cacher, opts ...VerifyOption) error {
+	cfg := verifyConfig{
+		canaryKey: DefaultVerifyCanaryKey,
+		sample:    100,
+		pattern:   "*",
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.canaryKey != "" {
+		suffix, err := newLeaseToken()
+		if err != nil {
+			return err
+		}
+		if err := verifyRoundTrip(ctx, cache, cfg.canaryKey+":"+suffix); err != nil {
+			return err
+		}
+	}
+
+	if kv, ok := cache.(keyspaceVerifier); ok && cfg.sample > 0 {
+		return kv.verifyKeyspace(ctx, cfg.pattern, cfg.sample)
+	}
+	return nil
+}
+
+// verifyRoundTrip 写入并读取探测键，检查读取的值与写入的值一致
+func verifyRoundTrip(ctx context.Context, cache gsr.Cacher, key string) error {
+	want := verifyCanaryValue{
+		Nonce: time.Now().UnixNano(),
+		Text:  "go-cache 校验 ✓",
+		Float: 0.1,
+		Time:  time.Date(2024, 2, 29, 23, 59, 59, 123456789, time.UTC),
+		Bytes: []byte{0, 1, 0xff},
+		List:  []string{"a", ""},
+		Attrs: map[string]int{"one": 1, "max": 1<<31 - 1},
+	}
+	if err := cache.Set(ctx, key, want, time.Minute); err != nil {
+		return err
+	}
+	defer cache.Del(context.WithoutCancel(ctx), key)
+
+	var got verifyCanaryValue
+	if err := cache.Get(ctx, key, &got); err != nil {
+		if errors.Is(err, ErrSerialization) || errors.Is(err, ErrCorruptData) {
+			return fmt.Errorf("%w: canary %q: %w", ErrSerializerMismatch, key, err)
+		}
+		return err
+	}
+	if !got.Time.Equal(want.Time) {
+		return fmt.Errorf("%w: canary %q: time %v read back as %v", ErrSerializerMismatch, key, want.Time, got.Time)
+	}
+	got.Time = want.Time
+	if !reflect.DeepEqual(got, want) {
+		return fmt.Errorf("%w: canary %q: wrote %+v, read back %+v", ErrSerializerMismatch, key, want, got)
+	}
+	return nil
+}
+
+// verifyKeyspace 抽样读取原始值，使用 serializer.Recognize 判断是否由当前序列化器写入
+func (c *Redis) verifyKeyspace(ctx context.Context, pattern string, n int) error {
+	mismatch := &SerializerMismatchError{Serializer: c.codec(pattern).Name()}
+	errDone := errors.New("sample done")
+	conn := c.route(ctx, OpKeys, pattern)
+	err := scanCount(ctx, conn, pattern, 0, func(keys []string) error {
+		if len(keys) > n-mismatch.Sampled {
+			keys = keys[:n-mismatch.Sampled]
+		}
+		entries, err := c.mirrorLoad(ctx, conn, keys)
+		if err != nil {
+			return err
+		}
+		for _, key := range keys {
+			entry, ok := entries[key]
+			if !ok {
+				// 扫描之后被删除的键或不是字符串类型的键
+				continue
+			}
+			mismatch.Sampled++
+			if !serializer.Recognize(c.codec(key), entry.data) {
+				mismatch.Keys = append(mismatch.Keys, key)
+			}
+		}
+		if mismatch.Sampled >= n {
+			return errDone
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDone) {
+		return classifyError(err)
+	}
+	if len(mismatch.Keys) > 0 {
+		return mismatch
+	}
+	return nil
+}
+
+// verifyKeyspace 只检查该租户的键
+func (t *TenantCache) verifyKeyspace(ctx context.Context, pattern string, n int) error {
+	kv, ok := t.cache.(keyspaceVerifier)
+	if !ok {
+		return nil
+	}
+	return kv.verifyKeyspace(ctx, EscapePattern(t.prefix)+pattern, n)
+}
@@ -0,0 +1,185 @@
+package go_cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// ViewLoader 计算物化视图的值，如从多个实体汇总出的计数或报表
+type ViewLoader func(ctx context.Context) (any, error)
+
+// ViewOption 物化视图选项
+type ViewOption func(*MaterializedView)
+
+// WithViewEager 设置数据源失效时立即在后台重新计算，默认在下一次 Get 时重新计算
+func WithViewEager() ViewOption {
+	return func(v *MaterializedView) {
+		v.eager = true
+	}
+}
+
+// WithViewErrorHook 设置后台重新计算失败时的回调
+func WithViewErrorHook(hook LoaderFailureHook) ViewOption {
+	return func(v *MaterializedView) {
+		v.onError = hook
+	}
+}
+
+// WithViewWorkerPool 设置后台重新计算的执行配置，只对 WithViewEager 的视图生效，零值字段不覆盖
+// 同一个视图同时只有一个重新计算在运行或等待，Size 与 Queue 不起作用，主要用于设置 PanicHandler
+func WithViewWorkerPool(pool WorkerPool) ViewOption {
+	return func(v *MaterializedView) {
+		v.pool = v.pool.merge(pool)
+	}
+}
+
+// MaterializedView 缓存的聚合结果，数据源失效时随之失效
+// 视图保存在 DependencyGraph 的缓存中并依赖sources中的键，
+// 经由该 DependencyGraph 写入或删除任一数据源时视图失效：
+// 默认在下一次 Get 时重新计算，WithViewEager 时由失效的实例立即在后台重新计算
+type MaterializedView struct {
+	graph   *DependencyGraph
+	key     string
+	sources []string
+	ttl     time.Duration
+	loader  ViewLoader
+	eager   bool
+	onError LoaderFailureHook
+	pool    WorkerPool
+
+	mu      sync.Mutex
+	running bool // 正在后台重新计算
+	dirty   bool // 重新计算期间数据源再次失效，结束后需要再计算一次
+	wg      sync.WaitGroup
+	jobs    *asyncQueue[context.Context]
+}
+
+// NewMaterializedView 创建保存在key、依赖sources、有效期为ttl的物化视图
+func NewMaterializedView(graph *DependencyGraph, key string, sources []string, ttl time.Duration, loader ViewLoader, opts ...ViewOption) *MaterializedView {
+	v := &MaterializedView{
+		graph:   graph,
+		key:     key,
+		sources: sources,
+		ttl:     ttl,
+		loader:  loader,
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.eager {
+		// 同时只有一个重新计算在运行或等待，队列长度为1即可
+		pool := resolveWorkerPool(WorkerPool{}, v.pool)
+		pool.Size, pool.Queue.Size = 1, 1
+		v.jobs = newAsyncQueue("view", pool, v.recompute, func(context.Context) {
+			v.mu.Lock()
+			v.running, v.dirty = false, false
+			v.mu.Unlock()
+			v.wg.Done()
+		})
+		graph.watch(key, v.refreshInBackground)
+	}
+	return v
+}
+
+// Key 返回视图在缓存中的键
+func (v *MaterializedView) Key() string {
+	return v.key
+}
+
+// Get 读取视图，不存在或已失效时重新计算
+func (v *MaterializedView) Get(ctx context.Context, obj any) error {
+	return v.graph.GetSet(ctx, v.key, v.ttl, obj, func(key string, obj any) error {
+		value, err := v.load(ctx)
+		if err != nil {
+			return err
+		}
+		return assignValue(obj, value)
+	})
+}
+
+// Refresh 立即重新计算视图并写入缓存
+func (v *MaterializedView) Refresh(ctx context.Context) error {
+	value, err := v.load(ctx)
+	if err != nil {
+		return err
+	}
+	return v.graph.Set(ctx, v.key, value, v.ttl)
+}
+
+// Invalidate 使视图失效，下一次 Get 时重新计算
+func (v *MaterializedView) Invalidate(ctx context.Context) error {
+	return v.graph.Del(ctx, v.key)
+}
+
+// Wait 等待后台的重新计算结束
+func (v *MaterializedView) Wait() {
+	v.wg.Wait()
+}
+
+// load 声明对数据源的依赖后计算视图
+// 先声明依赖，计算期间数据源失效时视图同样会被删除
+func (v *MaterializedView) load(ctx context.Context) (any, error) {
+	if err := v.graph.DependsOn(ctx, v.key, v.sources...); err != nil {
+		return nil, err
+	}
+	return v.loader(ctx)
+}
+
+// QueueStats 返回后台重新计算队列的统计快照，没有使用 WithViewEager 时返回零值
+func (v *MaterializedView) QueueStats() QueueStats {
+	if v.jobs == nil {
+		return QueueStats{}
+	}
+	return v.jobs.stats()
+}
+
+// refreshInBackground 视图失效后在后台重新计算
+// 同一时间只有一个重新计算，期间再次失效时结束后再计算一次，避免较早的结果覆盖较新的结果
+func (v *MaterializedView) refreshInBackground(ctx context.Context) {
+	v.mu.Lock()
+	if v.running {
+		v.dirty = true
+		v.mu.Unlock()
+		return
+	}
+	v.running = true
+	v.wg.Add(1)
+	v.mu.Unlock()
+
+	// 重新计算不受ctx取消的影响，ctx 只用于 QueueBlock 策略下等待队列出现空位
+	_ = v.jobs.push(ctx, context.WithoutCancel(ctx))
+}
+
+// recompute 重新计算视图，直到期间没有再次失效
+func (v *MaterializedView) recompute(ctx context.Context) {
+	finished := false
+	defer func() {
+		// 重新计算panic时同样结束，之后的失效可以再次触发
+		if !finished {
+			v.mu.Lock()
+			v.running, v.dirty = false, false
+			v.mu.Unlock()
+		}
+		v.wg.Done()
+	}()
+
+	for {
+		if err := v.Refresh(ctx); err != nil && v.onError != nil {
+			v.onError(v.key, err)
+		}
+
+		v.mu.Lock()
+		if !v.dirty {
+			v.running = false
+			v.mu.Unlock()
+			finished = true
+			return
+		}
+		v.dirty = false
+		v.mu.Unlock()
+	}
+}
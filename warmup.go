@@ -0,0 +1,266 @@
+package go_cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/muleiwu/gsr"
+)
+
+// ErrWarmupStarted 预热已开始，不能再加入预热任务
+var ErrWarmupStarted = errors.New("warmup already started")
+
+// WarmupFunc 预热函数，向cache写入需要预先加载的数据
+type WarmupFunc func(ctx context.Context, cache gsr.Cacher) error
+
+// WarmupProgress 预热进度
+type WarmupProgress struct {
+	Total     int              // 预热任务总数
+	Completed int              // 已结束的任务数，包括失败的任务
+	Failed    int              // 失败的任务数
+	Pending   []string         // 尚未结束的任务名，按名称排序
+	Errors    map[string]error // 失败的任务名到最后一次的错误
+	Elapsed   time.Duration    // 开始预热后经过的时间，结束后为预热的总耗时
+}
+
+// Done 判断全部任务是否已结束
+func (p WarmupProgress) Done() bool {
+	return p.Completed == p.Total
+}
+
+// WarmerOption 预热选项
+type WarmerOption func(*Warmer)
+
+// WithWarmupConcurrency 设置同时执行的预热任务数，默认4
+func WithWarmupConcurrency(n int) WarmerOption {
+	return func(w *Warmer) {
+		if n > 0 {
+			w.concurrency = n
+		}
+	}
+}
+
+// WithWarmupRetry 设置预热任务失败后的重试次数与间隔，默认不重试
+func WithWarmupRetry(retries int, interval time.Duration) WarmerOption {
+	return func(w *Warmer) {
+		w.retries = max(retries, 0)
+		w.retryInterval = max(interval, 0)
+	}
+}
+
+// WithWarmupErrorHook 设置预热任务每次失败时的回调，key 为任务名
+func WithWarmupErrorHook(hook LoaderFailureHook) WarmerOption {
+	return func(w *Warmer) {
+		w.onError = hook
+	}
+}
+
+// warmupTask 一个预热任务
+type warmupTask struct {
+	name string
+	fn   WarmupFunc
+}
+
+// Warmer 缓存预热与就绪信号
+// 服务启动时加入预热任务并调用 Start，在 Ready 关闭之前不将自身标记为就绪，
+// 避免关键缓存为空时大量请求穿透到数据源。
+// 失败的任务在重试次数用完后同样视为结束，Ready 关闭后通过 Err 或 Progress 判断是否全部成功
+type Warmer struct {
+	cache         gsr.Cacher
+	concurrency   int
+	retries       int
+	retryInterval time.Duration
+	onError       LoaderFailureHook
+
+	mu       sync.Mutex
+	tasks    []warmupTask
+	started  time.Time
+	finished time.Time
+	pending  map[string]bool
+	errs     map[string]error
+	ready    chan struct{}
+}
+
+// NewWarmer 创建向cache写入数据的预热
+func NewWarmer(cache gsr.Cacher, opts ...WarmerOption) *Warmer {
+	w := &Warmer{
+		cache:       cache,
+		concurrency: 4,
+		pending:     make(map[string]bool),
+		errs:        make(map[string]error),
+		ready:       make(chan struct{}),
+	}
+
+	// 应用选项
+	for _, opt := range opts {
+		opt(w)
+	}
+
+	return w
+}
+
+// Add 加入名为name的预热任务，需要在 Start 之前调用，之后返回 ErrWarmupStarted
+func (w *Warmer) Add(name string, fn WarmupFunc) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.started.IsZero() {
+		return ErrWarmupStarted
+	}
+	if w.pending[name] {
+		return fmt.Errorf("warmup task %q already added", name)
+	}
+	w.tasks = append(w.tasks, warmupTask{name: name, fn: fn})
+	w.pending[name] = true
+	return nil
+}
+
+// Start 在后台执行全部预热任务，重复调用时忽略
+// ctx 结束时未完成的任务以ctx的错误结束
+func (w *Warmer) Start(ctx context.Context) {
+	w.mu.Lock()
+	if !w.started.IsZero() {
+		w.mu.Unlock()
+		return
+	}
+	w.started = time.Now()
+	tasks := w.tasks
+	w.mu.Unlock()
+
+	go w.run(ctx, tasks)
+}
+
+// Run 执行全部预热任务并等待结束，返回失败任务的错误
+func (w *Warmer) Run(ctx context.Context) error {
+	w.Start(ctx)
+	<-w.ready
+	return w.Err()
+}
+
+// Ready 返回全部预热任务结束后关闭的通道
+func (w *Warmer) Ready() <-chan struct{} {
+	return w.ready
+}
+
+// Wait 等待预热结束或ctx结束，返回失败任务的错误或ctx的错误
+func (w *Warmer) Wait(ctx context.Context) error {
+	select {
+	case <-w.ready:
+		return w.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Err 返回失败任务的错误，按任务名排序后合并；没有失败或尚未结束的任务不计入
+func (w *Warmer) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	names := make([]string, 0, len(w.errs))
+	for name := range w.errs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	errs := make([]error, len(names))
+	for i, name := range names {
+		errs[i] = fmt.Errorf("warmup %s: %w", name, w.errs[name])
+	}
+	return errors.Join(errs...)
+}
+
+// Progress 返回预热进度
+func (w *Warmer) Progress() WarmupProgress {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	p := WarmupProgress{
+		Total:  len(w.tasks),
+		Failed: len(w.errs),
+		Errors: make(map[string]error, len(w.errs)),
+	}
+	for name, err := range w.errs {
+		p.Errors[name] = err
+	}
+	for name := range w.pending {
+		p.Pending = append(p.Pending, name)
+	}
+	sort.Strings(p.Pending)
+	p.Completed = p.Total - len(p.Pending)
+
+	switch {
+	case !w.finished.IsZero():
+		p.Elapsed = w.finished.Sub(w.started)
+	case !w.started.IsZero():
+		p.Elapsed = time.Since(w.started)
+	}
+	return p
+}
+
+// run 以有限的并发执行预热任务，全部结束后关闭就绪通道
+func (w *Warmer) run(ctx context.Context, tasks []warmupTask) {
+	sem := make(chan struct{}, w.concurrency)
+	var wg sync.WaitGroup
+	for _, task := range tasks {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			err := w.runTask(ctx, task)
+
+			w.mu.Lock()
+			delete(w.pending, task.name)
+			if err != nil {
+				w.errs[task.name] = err
+			}
+			w.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	w.mu.Lock()
+	w.finished = time.Now()
+	w.mu.Unlock()
+	close(w.ready)
+}
+
+// runTask 执行一个预热任务，失败时按配置重试
+func (w *Warmer) runTask(ctx context.Context, task warmupTask) error {
+	for attempt := 0; ; attempt++ {
+		err := w.call(ctx, task)
+		if err == nil {
+			return nil
+		}
+		if w.onError != nil {
+			w.onError(task.name, err)
+		}
+		if attempt >= w.retries || ctx.Err() != nil {
+			return err
+		}
+
+		timer := time.NewTimer(w.retryInterval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// call 执行预热函数并将panic转换为错误
+func (w *Warmer) call(ctx context.Context, task warmupTask) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return safeLoad(task.name, nil, func(string, any) error {
+		return task.fn(ctx, w.cache)
+	})
+}
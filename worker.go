@@ -0,0 +1,72 @@
+package go_cache
+
+import (
+	"fmt"
+	"sync"
+)
+
+// WorkerPanicError 后台任务发生panic时传给 WorkerPanicHandler 的错误
+type WorkerPanicError struct {
+	Feature string // 发生panic的功能，如 "expiry"、"prefetch"
+	Value   any    // recover() 得到的值
+	Stack   []byte // 发生panic时的调用栈
+}
+
+func (e *WorkerPanicError) Error() string {
+	return fmt.Sprintf("%s worker panic: %v", e.Feature, e.Value)
+}
+
+// WorkerPanicHandler 后台任务panic时的回调，设置后panic被恢复，执行goroutine继续处理后续任务
+type WorkerPanicHandler func(err *WorkerPanicError)
+
+// WorkerPool 后台任务的执行配置
+// 零值字段表示使用全局默认值（见 SetDefaultWorkerPool），全局默认值也为零值时使用功能自身的默认值；
+// Queue.Policy 的零值 QueueDropNewest 同样视为未设置
+type WorkerPool struct {
+	Size         int                // 同时执行任务的goroutine数上限
+	Queue        QueueConfig        // 等待执行的任务队列
+	PanicHandler WorkerPanicHandler // 任务panic时的回调，为nil时panic照常向上传播使进程退出
+}
+
+var (
+	// defaultWorkerPool 全局默认的后台任务执行配置
+	defaultWorkerPool   WorkerPool
+	defaultWorkerPoolMu sync.RWMutex
+)
+
+// SetDefaultWorkerPool 设置全局默认的后台任务执行配置
+// 只影响之后创建的过期监听器、预取等后台功能，功能自身的选项优先
+func SetDefaultWorkerPool(pool WorkerPool) {
+	defaultWorkerPoolMu.Lock()
+	defer defaultWorkerPoolMu.Unlock()
+	defaultWorkerPool = pool
+}
+
+// GetDefaultWorkerPool 获取全局默认的后台任务执行配置
+func GetDefaultWorkerPool() WorkerPool {
+	defaultWorkerPoolMu.RLock()
+	defer defaultWorkerPoolMu.RUnlock()
+	return defaultWorkerPool
+}
+
+// merge 用other中的非零字段覆盖p
+func (p WorkerPool) merge(other WorkerPool) WorkerPool {
+	if other.Size > 0 {
+		p.Size = other.Size
+	}
+	if other.Queue.Size > 0 {
+		p.Queue.Size = other.Queue.Size
+	}
+	if other.Queue.Policy != QueueDropNewest {
+		p.Queue.Policy = other.Queue.Policy
+	}
+	if other.PanicHandler != nil {
+		p.PanicHandler = other.PanicHandler
+	}
+	return p
+}
+
+// resolveWorkerPool 按功能默认值、全局默认值、功能选项的顺序合并执行配置
+func resolveWorkerPool(defaults, option WorkerPool) WorkerPool {
+	return defaults.merge(GetDefaultWorkerPool()).merge(option)
+}